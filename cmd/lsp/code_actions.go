@@ -0,0 +1,574 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/funvibe/funxy/internal/analyzer"
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/diagnostics"
+	"github.com/funvibe/funxy/internal/modules"
+	"github.com/funvibe/funxy/internal/pipeline"
+	"github.com/funvibe/funxy/internal/token"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+const (
+	codeActionQuickFix = "quickfix"
+	codeActionRewrite  = "refactor.rewrite"
+)
+
+// stubBody is the placeholder body of generated match arms and methods
+const stubBody = `panic("not implemented")`
+
+// codeActionBuilder collects the code actions for one request.
+type codeActionBuilder struct {
+	ctx     *pipeline.PipelineContext
+	uri     string
+	content string
+	src     *sourceText
+	spans   []tokenSpan
+	actions []CodeAction
+	fixed   map[ast.Node]bool
+}
+
+func newCodeActionBuilder(ctx *pipeline.PipelineContext, uri, content string) *codeActionBuilder {
+	return &codeActionBuilder{
+		ctx:     ctx,
+		uri:     uri,
+		content: content,
+		src:     newSourceText(content),
+		spans:   lexTokenSpans(content),
+		fixed:   make(map[ast.Node]bool),
+	}
+}
+
+// addQuickFix offers fixes for one diagnostic.
+func (b *codeActionBuilder) addQuickFix(err *diagnostics.DiagnosticError, diag Diagnostic) {
+	switch err.Code {
+	case diagnostics.ErrA001, diagnostics.ErrA006:
+		b.addMissingImports(err.Token.Lexeme, diag)
+	case diagnostics.ErrA007:
+		b.addMissingArms(err.Token, diag)
+	case diagnostics.ErrA003:
+		if strings.Contains(err.Error(), "is missing required method") {
+			b.addMissingMethods(err.Token, diag)
+		}
+	}
+}
+
+func (b *codeActionBuilder) edit(edits ...TextEdit) *WorkspaceEdit {
+	return &WorkspaceEdit{Changes: map[string][]TextEdit{b.uri: edits}}
+}
+
+// addMissingImports offers an import for every stdlib package that documents
+// a function with the undefined name.
+func (b *codeActionBuilder) addMissingImports(name string, diag Diagnostic) {
+	if name == "" {
+		return
+	}
+	var paths []string
+	for _, pkg := range modules.GetAllDocPackages() {
+		if !strings.HasPrefix(pkg.Path, "lib/") {
+			continue
+		}
+		for _, fn := range pkg.Functions {
+			if fn.Name == name {
+				paths = append(paths, pkg.Path)
+				break
+			}
+		}
+	}
+
+	for _, path := range paths {
+		edit, ok := b.importEdit(path, name)
+		if !ok {
+			continue
+		}
+		b.actions = append(b.actions, CodeAction{
+			Title:       fmt.Sprintf("Import '%s' from \"%s\"", name, path),
+			Kind:        codeActionQuickFix,
+			Diagnostics: []Diagnostic{diag},
+			IsPreferred: len(paths) == 1,
+			Edit:        b.edit(edit),
+		})
+	}
+}
+
+// importEdit extends an existing symbol import of path, or adds a new import
+// after the last import (or the package declaration).
+func (b *codeActionBuilder) importEdit(path, name string) (TextEdit, bool) {
+	prog, ok := b.ctx.AstRoot.(*ast.Program)
+	if !ok {
+		return TextEdit{}, false
+	}
+
+	insertLine := 0
+	for _, stmt := range prog.Statements {
+		switch s := stmt.(type) {
+		case *ast.PackageDeclaration:
+			insertLine = s.Token.Line
+		case *ast.ImportStatement:
+			insertLine = s.Token.Line
+			if s.Path == nil || s.Path.Value != path {
+				continue
+			}
+			if s.ImportAll || len(s.Symbols) == 0 {
+				// The module is already imported whole or under an alias
+				return TextEdit{}, false
+			}
+			last := s.Symbols[len(s.Symbols)-1]
+			pos := Position{Line: last.Token.Line - 1, Character: last.Token.Column - 1 + len(last.Value)}
+			return TextEdit{Range: Range{Start: pos, End: pos}, NewText: ", " + name}, true
+		}
+	}
+
+	pos := Position{Line: insertLine, Character: 0}
+	return TextEdit{Range: Range{Start: pos, End: pos}, NewText: fmt.Sprintf("import \"%s\" (%s)\n", path, name)}, true
+}
+
+// addMissingArms adds an arm for every constructor a match does not cover.
+func (b *codeActionBuilder) addMissingArms(matchTok token.Token, diag Diagnostic) {
+	var match *ast.MatchExpression
+	ast.Inspect(b.ctx.AstRoot, func(node ast.Node) bool {
+		if m, ok := node.(*ast.MatchExpression); ok && m.Token.Line == matchTok.Line && m.Token.Column == matchTok.Column {
+			match = m
+		}
+		return match == nil
+	})
+	if match == nil || b.fixed[match] {
+		return
+	}
+	b.fixed[match] = true
+
+	missing := analyzer.MissingConstructors(match, b.ctx.TypeMap[match.Expression], b.ctx.SymbolTable)
+	if len(missing) == 0 {
+		return
+	}
+	var arms []string
+	for _, ctor := range missing {
+		arms = append(arms, constructorPattern(b.ctx, ctor)+" -> "+stubBody)
+	}
+
+	edit, ok := b.insertBeforeClosingBrace(matchTok, arms)
+	if !ok {
+		return
+	}
+	title := "Add missing match arm"
+	if len(arms) > 1 {
+		title += "s"
+	}
+	b.actions = append(b.actions, CodeAction{
+		Title:       fmt.Sprintf("%s: %s", title, strings.Join(missing, ", ")),
+		Kind:        codeActionQuickFix,
+		Diagnostics: []Diagnostic{diag},
+		IsPreferred: true,
+		Edit:        b.edit(edit),
+	})
+}
+
+// constructorPattern returns a pattern matching any value built with ctor.
+func constructorPattern(ctx *pipeline.PipelineContext, ctor string) string {
+	sym, ok := ctx.SymbolTable.Find(ctor)
+	if !ok {
+		return ctor
+	}
+	fn, ok := asFunc(sym.Type)
+	if !ok || len(fn.Params) == 0 {
+		return ctor
+	}
+	wildcards := make([]string, len(fn.Params))
+	for i := range wildcards {
+		wildcards[i] = "_"
+	}
+	return ctor + "(" + strings.Join(wildcards, ", ") + ")"
+}
+
+// addMissingMethods adds stubs for the required trait methods an instance lacks.
+func (b *codeActionBuilder) addMissingMethods(instTok token.Token, diag Diagnostic) {
+	var inst *ast.InstanceDeclaration
+	ast.Inspect(b.ctx.AstRoot, func(node ast.Node) bool {
+		if i, ok := node.(*ast.InstanceDeclaration); ok && i.Token.Line == instTok.Line && i.Token.Column == instTok.Column {
+			inst = i
+		}
+		return inst == nil
+	})
+	if inst == nil || inst.TraitName == nil || b.fixed[inst] {
+		return
+	}
+	b.fixed[inst] = true
+
+	implemented := make(map[string]bool)
+	for _, method := range inst.Methods {
+		if method.Name != nil {
+			implemented[method.Name.Value] = true
+		} else if method.Operator != "" {
+			implemented["("+method.Operator+")"] = true
+		}
+	}
+
+	var stubs, names []string
+	for _, required := range b.ctx.SymbolTable.GetTraitRequiredMethods(inst.TraitName.Value) {
+		if implemented[required] {
+			continue
+		}
+		stub, ok := b.methodStub(inst, required)
+		if !ok {
+			continue
+		}
+		stubs = append(stubs, stub)
+		names = append(names, required)
+	}
+	if len(stubs) == 0 {
+		return
+	}
+
+	edit, ok := b.insertBeforeClosingBrace(instTok, stubs)
+	if !ok {
+		return
+	}
+	b.actions = append(b.actions, CodeAction{
+		Title:       "Implement missing methods: " + strings.Join(names, ", "),
+		Kind:        codeActionQuickFix,
+		Diagnostics: []Diagnostic{diag},
+		IsPreferred: true,
+		Edit:        b.edit(edit),
+	})
+}
+
+// methodStub renders a method of the instance's trait with the trait's type
+// parameters replaced by the instance arguments.
+func (b *codeActionBuilder) methodStub(inst *ast.InstanceDeclaration, method string) (string, bool) {
+	traitName := inst.TraitName.Value
+	methodType, ok := b.ctx.SymbolTable.GetTraitMethodType(method)
+	if !ok {
+		return "", false
+	}
+	// Substitute under the quantifier, which binds the trait's type parameters
+	generic, ok := asFunc(methodType)
+	if !ok {
+		return "", false
+	}
+	subst := make(typesystem.Subst)
+	if params, ok := b.ctx.SymbolTable.GetTraitTypeParams(traitName); ok {
+		for i, param := range params {
+			if i < len(inst.Args) {
+				subst[param] = typesystem.TCon{Name: typeString(inst.Args[i])}
+			}
+		}
+	}
+	fn, ok := asFunc(prettifiedType(generic.Apply(subst)))
+	if !ok {
+		return "", false
+	}
+
+	paramNames := traitParamNames(b.ctx.AstRoot, traitName, method)
+	params := make([]string, len(fn.Params))
+	for i, p := range fn.Params {
+		name := string(rune('a' + i%26))
+		if i < len(paramNames) && paramNames[i] != "" {
+			name = paramNames[i]
+		}
+		params[i] = name + ": " + p.String()
+	}
+
+	head := "fun " + method
+	if strings.HasPrefix(method, "(") {
+		head = "operator " + method
+	}
+	return fmt.Sprintf("%s(%s) -> %s { %s }", head, strings.Join(params, ", "), fn.ReturnType.String(), stubBody), true
+}
+
+// traitParamNames returns the parameter names of a trait method declared in
+// the current file, if any.
+func traitParamNames(root ast.Node, traitName, method string) []string {
+	var names []string
+	ast.Inspect(root, func(node ast.Node) bool {
+		trait, ok := node.(*ast.TraitDeclaration)
+		if !ok {
+			return names == nil
+		}
+		if trait.Name == nil || trait.Name.Value != traitName {
+			return false
+		}
+		for _, sig := range trait.Signatures {
+			if functionName(sig) != method {
+				continue
+			}
+			names = []string{}
+			for _, p := range sig.Parameters {
+				if p.Name != nil {
+					names = append(names, p.Name.Value)
+				} else {
+					names = append(names, "")
+				}
+			}
+		}
+		return false
+	})
+	return names
+}
+
+// insertBeforeClosingBrace inserts lines before the closing brace of the block
+// that follows the given keyword token, indented like the block's contents.
+func (b *codeActionBuilder) insertBeforeClosingBrace(keyword token.Token, lines []string) (TextEdit, bool) {
+	start := -1
+	for i, span := range b.spans {
+		if span.Tok.Line == keyword.Line && span.Tok.Column == keyword.Column {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		return TextEdit{}, false
+	}
+
+	open, closing := -1, -1
+	depth := 0
+	for i := start + 1; i < len(b.spans) && closing == -1; i++ {
+		switch b.spans[i].Tok.Type {
+		case token.LPAREN, token.LBRACKET:
+			depth++
+		case token.RPAREN, token.RBRACKET:
+			depth--
+		case token.LBRACE, token.PERCENT_LBRACE:
+			if open == -1 && depth == 0 {
+				open = i
+			}
+			depth++
+		case token.RBRACE:
+			depth--
+			if open != -1 && depth == 0 {
+				closing = i
+			}
+		}
+	}
+	if closing == -1 {
+		return TextEdit{}, false
+	}
+
+	outerLine, _ := b.src.position(b.spans[start].Start)
+	outerIndent := leadingWhitespace(getLine(b.content, outerLine))
+	indent := outerIndent + "    "
+	// Follow the indentation of the first item inside the block
+	if first := open + 1; first < closing {
+		for first < closing && b.spans[first].Tok.Type == token.NEWLINE {
+			first++
+		}
+		if line, _ := b.src.position(b.spans[first].Start); first < closing && line != outerLine {
+			indent = leadingWhitespace(getLine(b.content, line))
+		}
+	}
+
+	braceLine, braceChar := b.src.position(b.spans[closing].Start)
+	lineText := getLine(b.content, braceLine)
+	var text strings.Builder
+	if strings.TrimSpace(string([]rune(lineText)[:braceChar])) == "" {
+		// Closing brace on its own line: insert full lines above it
+		for _, line := range lines {
+			text.WriteString(indent + line + "\n")
+		}
+		pos := Position{Line: braceLine, Character: 0}
+		return TextEdit{Range: Range{Start: pos, End: pos}, NewText: text.String()}, true
+	}
+
+	for _, line := range lines {
+		text.WriteString("\n" + indent + line)
+	}
+	text.WriteString("\n" + outerIndent)
+	pos := Position{Line: braceLine, Character: braceChar}
+	return TextEdit{Range: Range{Start: pos, End: pos}, NewText: text.String()}, true
+}
+
+// addDoBlockRewrite offers to turn a tail of `x = f(x)?` bindings in a
+// function body into a do block. The chain must run to the end of the body,
+// since `?` returns from the function while a do block only short-circuits
+// its own value.
+func (b *codeActionBuilder) addDoBlockRewrite(rng Range) {
+	var body *ast.BlockStatement
+	first := -1
+	ast.Inspect(b.ctx.AstRoot, func(node ast.Node) bool {
+		var block *ast.BlockStatement
+		switch n := node.(type) {
+		case *ast.FunctionStatement:
+			block = n.Body
+		case *ast.FunctionLiteral:
+			block = n.Body
+		default:
+			return true
+		}
+		if block == nil {
+			return true
+		}
+		for i, stmt := range block.Statements {
+			if !isTryBinding(stmt) {
+				continue
+			}
+			line := statementStart(stmt).Line - 1
+			endLine := block.RBraceToken.Line - 1
+			if i+1 < len(block.Statements) {
+				endLine = statementStart(block.Statements[i+1]).Line - 2
+			}
+			if rng.Start.Line >= line && rng.Start.Line <= endLine {
+				body, first = block, i
+			}
+		}
+		return true
+	})
+	if body == nil {
+		return
+	}
+	for first > 0 && isTryBinding(body.Statements[first-1]) {
+		first--
+	}
+
+	items, ok := b.doItems(body, first)
+	if !ok {
+		return
+	}
+
+	startTok := statementStart(body.Statements[first])
+	startOff := b.src.offset(startTok.Line, startTok.Column)
+	endOff := b.statementEnd(body, len(body.Statements)-1)
+	startLine, startChar := b.src.position(startOff)
+	endLine, endChar := b.src.position(endOff)
+	indent := leadingWhitespace(getLine(b.content, startLine))
+
+	var text strings.Builder
+	text.WriteString("do {\n")
+	for _, item := range items {
+		text.WriteString(indent + "    " + strings.ReplaceAll(item, "\n", "\n    ") + "\n")
+	}
+	text.WriteString(indent + "}")
+
+	b.actions = append(b.actions, CodeAction{
+		Title: "Convert '?' chain to a do block",
+		Kind:  codeActionRewrite,
+		Edit: b.edit(TextEdit{
+			Range:   Range{Start: Position{Line: startLine, Character: startChar}, End: Position{Line: endLine, Character: endChar}},
+			NewText: text.String(),
+		}),
+	})
+}
+
+// doItems rewrites statements[first:] as do-block items: `x = e?` becomes a
+// bind, `x = e` a let binding, and the final expression is kept as is.
+func (b *codeActionBuilder) doItems(body *ast.BlockStatement, first int) ([]string, bool) {
+	var items []string
+	last := len(body.Statements) - 1
+	for i := first; i <= last; i++ {
+		stmt := body.Statements[i]
+		startTok := statementStart(stmt)
+		start := b.src.offset(startTok.Line, startTok.Column)
+		end := b.statementEnd(body, i)
+		text := b.content[start:end]
+
+		switch s := stmt.(type) {
+		case *ast.ConstantDeclaration:
+			if i == last {
+				return nil, false
+			}
+			items = append(items, text)
+			continue
+		case *ast.ExpressionStatement:
+			assign, isAssign := s.Expression.(*ast.AssignExpression)
+			if !isAssign {
+				if i != last {
+					// A bare expression in the middle of a do block would be sequenced monadically
+					return nil, false
+				}
+				items = append(items, text)
+				continue
+			}
+			ident, ok := assign.Left.(*ast.Identifier)
+			if !ok || assign.AnnotatedType != nil || i == last {
+				return nil, false
+			}
+			value := strings.TrimSpace(text[strings.Index(text, "=")+1:])
+			if isTryBinding(stmt) {
+				items = append(items, ident.Value+" <- "+strings.TrimSpace(strings.TrimSuffix(value, "?")))
+			} else {
+				items = append(items, ident.Value+" :- "+value)
+			}
+		default:
+			return nil, false
+		}
+	}
+	return items, len(items) > 1
+}
+
+// statementEnd returns the offset just past the last token of the i-th
+// statement of a block.
+func (b *codeActionBuilder) statementEnd(body *ast.BlockStatement, i int) int {
+	limitTok := body.RBraceToken
+	if i+1 < len(body.Statements) {
+		limitTok = statementStart(body.Statements[i+1])
+	}
+	limit := b.src.offset(limitTok.Line, limitTok.Column)
+	end := 0
+	for _, span := range b.spans {
+		if span.Start >= limit {
+			break
+		}
+		if span.Tok.Type != token.NEWLINE {
+			end = span.End
+		}
+	}
+	return end
+}
+
+// isTryBinding reports whether stmt has the form `x = expr?`.
+func isTryBinding(stmt ast.Statement) bool {
+	es, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	assign, ok := es.Expression.(*ast.AssignExpression)
+	if !ok {
+		return false
+	}
+	if _, ok := assign.Left.(*ast.Identifier); !ok {
+		return false
+	}
+	postfix, ok := assign.Value.(*ast.PostfixExpression)
+	return ok && postfix.Operator == "?"
+}
+
+// statementStart returns the first token of a statement. Expression statements
+// carry the token of their operator, so the left spine of the expression is followed.
+func statementStart(stmt ast.Statement) token.Token {
+	if es, ok := stmt.(*ast.ExpressionStatement); ok {
+		return expressionStart(es.Expression)
+	}
+	if tp, ok := stmt.(ast.TokenProvider); ok {
+		return tp.GetToken()
+	}
+	return token.Token{}
+}
+
+func expressionStart(expr ast.Expression) token.Token {
+	switch e := expr.(type) {
+	case *ast.AssignExpression:
+		return expressionStart(e.Left)
+	case *ast.InfixExpression:
+		return expressionStart(e.Left)
+	case *ast.CallExpression:
+		return expressionStart(e.Function)
+	case *ast.MemberExpression:
+		return expressionStart(e.Left)
+	case *ast.IndexExpression:
+		return expressionStart(e.Left)
+	case *ast.PostfixExpression:
+		return expressionStart(e.Left)
+	case *ast.AnnotatedExpression:
+		return expressionStart(e.Expression)
+	case *ast.RangeExpression:
+		if e.Start != nil {
+			return expressionStart(e.Start)
+		}
+	}
+	if tp, ok := expr.(ast.TokenProvider); ok {
+		return tp.GetToken()
+	}
+	return token.Token{}
+}
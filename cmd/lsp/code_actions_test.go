@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func requestCodeActions(t *testing.T, code string, pos Position) []CodeAction {
+	t.Helper()
+	uri := "file:///actions.funxy"
+	server, buf := setupServer(t, uri, code)
+
+	if err := server.handleCodeAction(1, CodeActionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Range:        Range{Start: pos, End: pos},
+	}); err != nil {
+		t.Fatalf("handleCodeAction failed: %v", err)
+	}
+
+	var actions []CodeAction
+	if err := json.Unmarshal(decodeResponse(t, buf).Result, &actions); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	return actions
+}
+
+// applyAction applies the edits of the action with the given title prefix.
+func applyAction(t *testing.T, code string, actions []CodeAction, title string) string {
+	t.Helper()
+	for _, action := range actions {
+		if !strings.HasPrefix(action.Title, title) || action.Edit == nil {
+			continue
+		}
+		for _, edits := range action.Edit.Changes {
+			return applyTextEdits(code, edits)
+		}
+	}
+	var titles []string
+	for _, action := range actions {
+		titles = append(titles, action.Title)
+	}
+	t.Fatalf("no action %q among %q", title, titles)
+	return ""
+}
+
+func applyTextEdits(code string, edits []TextEdit) string {
+	sorted := append([]TextEdit{}, edits...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i].Range.Start, sorted[j].Range.Start
+		return a.Line > b.Line || (a.Line == b.Line && a.Character > b.Character)
+	})
+	for _, edit := range sorted {
+		start := offsetAt(code, edit.Range.Start.Line, edit.Range.Start.Character)
+		end := offsetAt(code, edit.Range.End.Line, edit.Range.End.Character)
+		code = code[:start] + edit.NewText + code[end:]
+	}
+	return code
+}
+
+// expectCleanAnalysis checks that a fixed program analyzes without errors.
+func expectCleanAnalysis(t *testing.T, code string) {
+	t.Helper()
+	uri := "file:///fixed.funxy"
+	server, _ := setupServer(t, uri, code)
+	ctx, _ := server.documentContext(uri)
+	if ctx == nil {
+		t.Fatalf("fixed program failed to analyze:\n%s", code)
+	}
+	for _, err := range ctx.Errors {
+		t.Errorf("fixed program has error %v:\n%s", err, code)
+	}
+}
+
+func TestCodeAction_MissingImport(t *testing.T) {
+	code := "xs = sort([3, 1, 2])\n"
+	actions := requestCodeActions(t, code, positionOf(t, code, "sort", 0))
+	fixed := applyAction(t, code, actions, "Import 'sort' from \"lib/list\"")
+	if fixed != "import \"lib/list\" (sort)\nxs = sort([3, 1, 2])\n" {
+		t.Errorf("unexpected result:\n%s", fixed)
+	}
+	expectCleanAnalysis(t, fixed)
+
+	// An existing symbol import of the package is extended instead
+	code = "import \"lib/list\" (head)\nxs = sort([3, 1, 2])\nh = head(xs)\n"
+	actions = requestCodeActions(t, code, positionOf(t, code, "sort", 0))
+	fixed = applyAction(t, code, actions, "Import 'sort'")
+	if !strings.HasPrefix(fixed, "import \"lib/list\" (head, sort)\n") {
+		t.Errorf("expected the import list to be extended, got:\n%s", fixed)
+	}
+	expectCleanAnalysis(t, fixed)
+}
+
+func TestCodeAction_MissingMatchArms(t *testing.T) {
+	code := "type Shape = Circle Float | Square Float | Tri Float Float Float\n" +
+		"fun area(s: Shape) -> Float {\n" +
+		"    match s {\n" +
+		"        Circle(r) -> r * r\n" +
+		"    }\n" +
+		"}\n"
+	actions := requestCodeActions(t, code, positionOf(t, code, "match", 0))
+	fixed := applyAction(t, code, actions, "Add missing match arms: Square, Tri")
+	want := "        Circle(r) -> r * r\n" +
+		"        Square(_) -> panic(\"not implemented\")\n" +
+		"        Tri(_, _, _) -> panic(\"not implemented\")\n" +
+		"    }\n"
+	if !strings.Contains(fixed, want) {
+		t.Errorf("unexpected result:\n%s", fixed)
+	}
+	expectCleanAnalysis(t, fixed)
+}
+
+func TestCodeAction_MissingTraitMethods(t *testing.T) {
+	code := "type alias Point = { x: Int, y: Int }\n" +
+		"trait Describe<t> {\n" +
+		"    fun describe(v: t) -> String\n" +
+		"    fun scaled(v: t, factor: Int) -> t\n" +
+		"}\n" +
+		"instance Describe Point {\n" +
+		"    fun describe(v: Point) -> String { \"point\" }\n" +
+		"}\n"
+	actions := requestCodeActions(t, code, positionOf(t, code, "instance", 0))
+	fixed := applyAction(t, code, actions, "Implement missing methods: scaled")
+	want := "    fun scaled(v: Point, factor: Int) -> Point { panic(\"not implemented\") }\n}\n"
+	if !strings.HasSuffix(fixed, want) {
+		t.Errorf("unexpected result:\n%s", fixed)
+	}
+	expectCleanAnalysis(t, fixed)
+}
+
+func TestCodeAction_TryChainToDoBlock(t *testing.T) {
+	code := "fun half(n: Int) -> Result<String, Int> { if n % 2 == 0 { Ok(n / 2) } else { Fail(\"odd\") } }\n" +
+		"fun quarter(n: Int) -> Result<String, Int> {\n" +
+		"    h = half(n)?\n" +
+		"    q = half(h)?\n" +
+		"    r = q + 0\n" +
+		"    Ok(r)\n" +
+		"}\n"
+	actions := requestCodeActions(t, code, positionOf(t, code, "q = half", 0))
+	fixed := applyAction(t, code, actions, "Convert '?' chain to a do block")
+	want := "fun quarter(n: Int) -> Result<String, Int> {\n" +
+		"    do {\n" +
+		"        h <- half(n)\n" +
+		"        q <- half(h)\n" +
+		"        r :- q + 0\n" +
+		"        Ok(r)\n" +
+		"    }\n" +
+		"}\n"
+	if !strings.HasSuffix(fixed, want) {
+		t.Errorf("unexpected result:\n%s", fixed)
+	}
+	expectCleanAnalysis(t, fixed)
+
+	// Not offered when statements follow that a do block cannot sequence
+	code = "fun quarter(n: Int) -> Result<String, Int> {\n" +
+		"    h = half(n)?\n" +
+		"    print(h)\n" +
+		"    Ok(h)\n" +
+		"}\n" +
+		"fun half(n: Int) -> Result<String, Int> { Ok(n / 2) }\n"
+	for _, action := range requestCodeActions(t, code, positionOf(t, code, "h = half", 0)) {
+		if action.Kind == codeActionRewrite {
+			t.Errorf("unexpected rewrite %q", action.Title)
+		}
+	}
+}
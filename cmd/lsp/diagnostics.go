@@ -10,6 +10,7 @@ import (
 func (s *LanguageServer) publishDiagnostics(uri string, finalCtx *pipeline.PipelineContext) error {
 	// Convert diagnostics to LSP format
 	lspDiagnostics := s.convertDiagnostics(finalCtx.Errors, s.uriToPath(uri))
+	lspDiagnostics = append(lspDiagnostics, s.convertDiagnostics(finalCtx.Warnings, s.uriToPath(uri))...)
 
 	// Send publishDiagnostics notification
 	notification := NotificationMessage{
@@ -46,7 +47,7 @@ func (s *LanguageServer) convertDiagnostics(errors []*diagnostics.DiagnosticErro
 					Character: err.Token.Column + len(err.Token.Lexeme) - 1,
 				},
 			},
-			Severity: SeverityError,
+			Severity: diagnosticSeverity(err.Severity),
 			Code:     string(err.Code),
 			Message:  err.Error(),
 			Source:   "funxy",
@@ -56,3 +57,14 @@ func (s *LanguageServer) convertDiagnostics(errors []*diagnostics.DiagnosticErro
 
 	return result
 }
+
+func diagnosticSeverity(severity diagnostics.Severity) DiagnosticSeverity {
+	switch severity {
+	case diagnostics.SeverityWarning:
+		return SeverityWarning
+	case diagnostics.SeverityInfo:
+		return SeverityInfo
+	default:
+		return SeverityError
+	}
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/prettyprinter"
+	"github.com/funvibe/funxy/internal/token"
+)
+
+// buildDocumentSymbols returns the outline of a program: top-level declarations
+// with their members as children. tokens are used to locate record field names
+// and may be nil.
+func buildDocumentSymbols(prog *ast.Program, tokens []token.Token) []DocumentSymbol {
+	result := []DocumentSymbol{}
+	if prog == nil {
+		return result
+	}
+
+	definedVars := make(map[string]bool)
+	for _, stmt := range prog.Statements {
+		switch s := stmt.(type) {
+		case *ast.FunctionStatement:
+			if sym, ok := functionSymbol(s, false); ok {
+				result = append(result, sym)
+			}
+		case *ast.TypeDeclarationStatement:
+			result = append(result, typeSymbol(s, tokens))
+		case *ast.TraitDeclaration:
+			result = append(result, traitSymbol(s))
+		case *ast.InstanceDeclaration:
+			result = append(result, instanceSymbol(s))
+		case *ast.ConstantDeclaration:
+			if s.Name != nil && strings.HasPrefix(s.Name.Value, "$") {
+				// Compiler-generated declarations (e.g. instance dictionaries)
+				continue
+			}
+			result = append(result, constantSymbols(s)...)
+		case *ast.ExpressionStatement:
+			assign, ok := s.Expression.(*ast.AssignExpression)
+			if !ok {
+				continue
+			}
+			ident, ok := assign.Left.(*ast.Identifier)
+			if !ok || ident.Value == "_" || definedVars[ident.Value] {
+				// Later assignments to the same name are updates, not declarations
+				continue
+			}
+			definedVars[ident.Value] = true
+			sym := DocumentSymbol{
+				Name:           ident.Value,
+				Kind:           SymbolKindVariable,
+				Range:          nodeRange(s),
+				SelectionRange: identRange(ident),
+			}
+			if assign.AnnotatedType != nil {
+				sym.Detail = typeString(assign.AnnotatedType)
+			}
+			result = append(result, sym)
+		}
+	}
+	return result
+}
+
+func functionSymbol(fn *ast.FunctionStatement, isMethod bool) (DocumentSymbol, bool) {
+	name := functionName(fn)
+	if name == "" {
+		return DocumentSymbol{}, false
+	}
+
+	kind := SymbolKindFunction
+	if isMethod || fn.Receiver != nil {
+		kind = SymbolKindMethod
+	}
+	if fn.Operator != "" {
+		kind = SymbolKindOperator
+	}
+
+	selection := nodeRange(fn)
+	if fn.Name != nil {
+		selection = identRange(fn.Name)
+	}
+
+	return DocumentSymbol{
+		Name:           name,
+		Detail:         functionSignature(fn),
+		Kind:           kind,
+		Range:          nodeRange(fn),
+		SelectionRange: selection,
+	}, true
+}
+
+// functionName returns the display name of a function (operator methods are shown in parentheses).
+func functionName(fn *ast.FunctionStatement) string {
+	if fn.Operator != "" {
+		return "(" + fn.Operator + ")"
+	}
+	if fn.Name == nil {
+		return ""
+	}
+	return fn.Name.Value
+}
+
+// functionSignature renders the receiver, parameter list and return type of a function.
+func functionSignature(fn *ast.FunctionStatement) string {
+	var sb strings.Builder
+	if fn.Receiver != nil && fn.Receiver.Type != nil {
+		sb.WriteString("(" + typeString(fn.Receiver.Type) + ") ")
+	}
+	if len(fn.TypeParams) > 0 {
+		sb.WriteString("<")
+		for i, tp := range fn.TypeParams {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(tp.Value)
+		}
+		sb.WriteString(">")
+	}
+	sb.WriteString("(")
+	for i, param := range fn.Parameters {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if param.IsVariadic {
+			sb.WriteString("...")
+		}
+		if param.Name != nil {
+			sb.WriteString(param.Name.Value)
+		}
+		if param.Type != nil {
+			sb.WriteString(": ")
+			sb.WriteString(typeString(param.Type))
+		}
+	}
+	sb.WriteString(")")
+	if fn.ReturnType != nil {
+		sb.WriteString(" -> ")
+		sb.WriteString(typeString(fn.ReturnType))
+	}
+	return sb.String()
+}
+
+func typeSymbol(decl *ast.TypeDeclarationStatement, tokens []token.Token) DocumentSymbol {
+	sym := DocumentSymbol{
+		Name:           decl.Name.Value,
+		Kind:           SymbolKindClass,
+		Range:          nodeRange(decl),
+		SelectionRange: identRange(decl.Name),
+	}
+	if len(decl.TypeParameters) > 0 {
+		params := make([]string, len(decl.TypeParameters))
+		for i, tp := range decl.TypeParameters {
+			params[i] = tp.Value
+		}
+		sym.Detail = "<" + strings.Join(params, ", ") + ">"
+	}
+
+	if decl.IsAlias {
+		record, ok := decl.TargetType.(*ast.RecordType)
+		if !ok {
+			if decl.TargetType != nil {
+				sym.Detail = strings.TrimSpace(sym.Detail + " = " + typeString(decl.TargetType))
+			}
+			return sym
+		}
+		sym.Kind = SymbolKindStruct
+		keys := recordKeyTokens(tokens, record)
+		for _, name := range sortedFieldNames(record.Fields, keys) {
+			field := DocumentSymbol{
+				Name:           name,
+				Detail:         typeString(record.Fields[name]),
+				Kind:           SymbolKindField,
+				Range:          sym.Range,
+				SelectionRange: sym.SelectionRange,
+			}
+			if tok, ok := keys[name]; ok {
+				field.SelectionRange = tokenRange(tok, len(name))
+				field.Range = field.SelectionRange
+			}
+			sym.Children = append(sym.Children, field)
+		}
+		return sym
+	}
+
+	sym.Kind = SymbolKindEnum
+	for _, ctor := range decl.Constructors {
+		if ctor == nil || ctor.Name == nil {
+			continue
+		}
+		child := DocumentSymbol{
+			Name:           ctor.Name.Value,
+			Kind:           SymbolKindConstructor,
+			Range:          nodeRange(ctor),
+			SelectionRange: identRange(ctor.Name),
+		}
+		if len(ctor.Parameters) > 0 {
+			params := make([]string, len(ctor.Parameters))
+			for i, p := range ctor.Parameters {
+				params[i] = typeString(p)
+			}
+			child.Detail = strings.Join(params, " ")
+		}
+		sym.Children = append(sym.Children, child)
+	}
+	return sym
+}
+
+func traitSymbol(decl *ast.TraitDeclaration) DocumentSymbol {
+	sym := DocumentSymbol{
+		Name:           decl.Name.Value,
+		Kind:           SymbolKindInterface,
+		Range:          nodeRange(decl),
+		SelectionRange: identRange(decl.Name),
+	}
+	for _, sig := range decl.Signatures {
+		if child, ok := functionSymbol(sig, true); ok {
+			sym.Children = append(sym.Children, child)
+		}
+	}
+	return sym
+}
+
+func instanceSymbol(decl *ast.InstanceDeclaration) DocumentSymbol {
+	name := instanceName(decl)
+	sym := DocumentSymbol{
+		Name:           name,
+		Kind:           SymbolKindObject,
+		Range:          nodeRange(decl),
+		SelectionRange: nodeRange(decl),
+	}
+	if decl.TraitName != nil {
+		sym.SelectionRange = identRange(decl.TraitName)
+	}
+	for _, method := range decl.Methods {
+		if child, ok := functionSymbol(method, true); ok {
+			sym.Children = append(sym.Children, child)
+		}
+	}
+	return sym
+}
+
+// instanceName renders an instance head, e.g. "Show Int" or "Convert<Int, String>".
+func instanceName(decl *ast.InstanceDeclaration) string {
+	var sb strings.Builder
+	if decl.ModuleName != nil {
+		sb.WriteString(decl.ModuleName.Value)
+		sb.WriteString(".")
+	}
+	if decl.TraitName != nil {
+		sb.WriteString(decl.TraitName.Value)
+	}
+	switch {
+	case len(decl.Args) > 1:
+		args := make([]string, len(decl.Args))
+		for i, arg := range decl.Args {
+			args[i] = typeString(arg)
+		}
+		sb.WriteString("<" + strings.Join(args, ", ") + ">")
+	case len(decl.Args) == 1:
+		sb.WriteString(" ")
+		sb.WriteString(typeString(decl.Args[0]))
+	}
+	return sb.String()
+}
+
+func constantSymbols(decl *ast.ConstantDeclaration) []DocumentSymbol {
+	detail := ""
+	if decl.TypeAnnotation != nil {
+		detail = typeString(decl.TypeAnnotation)
+	}
+	if decl.Name != nil {
+		return []DocumentSymbol{{
+			Name:           decl.Name.Value,
+			Detail:         detail,
+			Kind:           SymbolKindConstant,
+			Range:          nodeRange(decl),
+			SelectionRange: identRange(decl.Name),
+		}}
+	}
+
+	// Destructuring binding: every bound name is a constant
+	var result []DocumentSymbol
+	ast.Inspect(decl.Pattern, func(node ast.Node) bool {
+		if p, ok := node.(*ast.IdentifierPattern); ok && p.Value != "_" {
+			result = append(result, DocumentSymbol{
+				Name:           p.Value,
+				Kind:           SymbolKindConstant,
+				Range:          nodeRange(decl),
+				SelectionRange: tokenRange(p.Token, len(p.Value)),
+			})
+		}
+		return true
+	})
+	return result
+}
+
+// sortedFieldNames orders record fields by source position when known, by name otherwise.
+func sortedFieldNames[V any](fields map[string]V, keys map[string]token.Token) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, aok := keys[names[i]]
+		b, bok := keys[names[j]]
+		if aok && bok && a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if aok && bok && a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// typeString renders a type annotation as source text.
+func typeString(t ast.Type) string {
+	if t == nil {
+		return ""
+	}
+	printer := prettyprinter.NewCodePrinter()
+	t.Accept(printer)
+	return printer.String()
+}
+
+// nodeRange returns the LSP range spanned by node.
+func nodeRange(node ast.Node) Range {
+	start, end, ok := getNodePosition(node)
+	if !ok {
+		return Range{}
+	}
+	return Range{
+		Start: Position{Line: start.Line - 1, Character: start.Column - 1},
+		End:   Position{Line: end.Line - 1, Character: end.Column - 1 + len(end.Lexeme)},
+	}
+}
+
+// identRange returns the LSP range of an identifier.
+func identRange(ident *ast.Identifier) Range {
+	return tokenRange(ident.Token, len(ident.Value))
+}
+
+func tokenRange(tok token.Token, length int) Range {
+	return Range{
+		Start: Position{Line: tok.Line - 1, Character: tok.Column - 1},
+		End:   Position{Line: tok.Line - 1, Character: tok.Column - 1 + length},
+	}
+}
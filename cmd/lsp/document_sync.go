@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// applyContentChanges applies the changes of a didChange notification in order.
+// A change without a range replaces the whole document.
+func applyContentChanges(content string, changes []TextDocumentContentChangeEvent) (string, error) {
+	for _, change := range changes {
+		if change.Range == nil {
+			content = change.Text
+			continue
+		}
+		start, ok := positionOffset(content, change.Range.Start)
+		if !ok {
+			return "", fmt.Errorf("change start %d:%d is outside the document", change.Range.Start.Line, change.Range.Start.Character)
+		}
+		end, ok := positionOffset(content, change.Range.End)
+		if !ok || end < start {
+			return "", fmt.Errorf("change end %d:%d is outside the document", change.Range.End.Line, change.Range.End.Character)
+		}
+		content = content[:start] + change.Text + content[end:]
+	}
+	return content, nil
+}
+
+// positionOffset converts an LSP position to a byte offset. Characters are
+// counted in UTF-16 code units, as the protocol requires; a character past
+// the end of the line clamps to the line end.
+func positionOffset(content string, pos Position) (int, bool) {
+	if pos.Line < 0 || pos.Character < 0 {
+		return 0, false
+	}
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		next := strings.IndexByte(content[offset:], '\n')
+		if next == -1 {
+			return 0, false
+		}
+		offset += next + 1
+	}
+
+	units := 0
+	for offset < len(content) && content[offset] != '\n' && units < pos.Character {
+		r, size := utf8.DecodeRuneInString(content[offset:])
+		if r >= 0x10000 {
+			units += 2
+		} else {
+			units++
+		}
+		offset += size
+	}
+	return offset, true
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func rangeChange(startLine, startChar, endLine, endChar int, text string) TextDocumentContentChangeEvent {
+	return TextDocumentContentChangeEvent{
+		Range: &Range{
+			Start: Position{Line: startLine, Character: startChar},
+			End:   Position{Line: endLine, Character: endChar},
+		},
+		Text: text,
+	}
+}
+
+func documentState(t *testing.T, server *LanguageServer, uri string) (string, int, bool) {
+	t.Helper()
+	server.mu.RLock()
+	doc := server.documents[uri]
+	server.mu.RUnlock()
+	doc.Mu.RLock()
+	defer doc.Mu.RUnlock()
+	return doc.Content, doc.AnalysisID, doc.Context != nil && len(doc.Context.Errors) == 0
+}
+
+func TestApplyContentChanges(t *testing.T) {
+	content := "x = 1\ns = \"😀b\"\n"
+	got, err := applyContentChanges(content, []TextDocumentContentChangeEvent{
+		rangeChange(0, 4, 0, 5, "42"),
+		// The emoji is two UTF-16 code units wide
+		rangeChange(1, 7, 1, 8, "c"),
+		rangeChange(2, 0, 2, 0, "y = x\n"),
+	})
+	if err != nil {
+		t.Fatalf("applyContentChanges failed: %v", err)
+	}
+	want := "x = 42\ns = \"😀c\"\ny = x\n"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	got, _ = applyContentChanges(content, []TextDocumentContentChangeEvent{{Text: "z = 0\n"}})
+	if got != "z = 0\n" {
+		t.Errorf("a change without a range should replace the document, got %q", got)
+	}
+
+	if _, err := applyContentChanges(content, []TextDocumentContentChangeEvent{rangeChange(5, 0, 5, 1, "")}); err == nil {
+		t.Error("expected an error for a change outside the document")
+	}
+}
+
+func TestDidChange_Incremental(t *testing.T) {
+	uri := "file:///sync.funxy"
+	server, buf := setupServer(t, uri, "x = 1\ny = x + \"a\"\n")
+
+	// Replace `"a"` with `1`, fixing the type error
+	if err := server.handleDidChange(DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{rangeChange(1, 8, 1, 11, "1")},
+	}); err != nil {
+		t.Fatalf("handleDidChange failed: %v", err)
+	}
+
+	content, _, clean := documentState(t, server, uri)
+	if content != "x = 1\ny = x + 1\n" {
+		t.Errorf("unexpected content %q", content)
+	}
+	if !clean {
+		t.Error("expected the edited document to analyze without errors")
+	}
+	if !strings.Contains(buf.String(), `"diagnostics":[]`) {
+		t.Errorf("expected empty diagnostics to be published, got %s", buf.String())
+	}
+}
+
+func TestDidChange_Debounced(t *testing.T) {
+	uri := "file:///debounce.funxy"
+	server, buf := setupServer(t, uri, "x = 1\n")
+	server.debounce = 20 * time.Millisecond
+
+	for i, text := range []string{"2", "3", "4"} {
+		if err := server.handleDidChange(DidChangeTextDocumentParams{
+			TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: i + 2},
+			ContentChanges: []TextDocumentContentChangeEvent{rangeChange(0, 4, 0, 5, text)},
+		}); err != nil {
+			t.Fatalf("handleDidChange failed: %v", err)
+		}
+	}
+
+	server.writeMu.Lock()
+	published := buf.Len()
+	server.writeMu.Unlock()
+	if published != 0 {
+		t.Fatal("analysis should wait for the debounce delay")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		server.writeMu.Lock()
+		output := buf.String()
+		server.writeMu.Unlock()
+		if output != "" {
+			if n := strings.Count(output, "publishDiagnostics"); n != 1 {
+				t.Errorf("expected a single analysis for the burst of edits, got %d", n)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("debounced analysis did not run")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	content, id, _ := documentState(t, server, uri)
+	if content != "x = 4\n" || id != 4 {
+		t.Errorf("unexpected state: content %q, analysis %d", content, id)
+	}
+}
+
+func TestModuleCache_ReusesImportsUntilChanged(t *testing.T) {
+	root := writeWorkspace(t, map[string]string{
+		"geo/geo.lang": "package geo (area)\n" +
+			"fun area(w: Int, h: Int) -> Int { w * h }\n",
+		"main.lang": "import \"./geo\" (area)\n" +
+			"a = area(2, 3)\n",
+	})
+	server, _, uri := openWorkspaceFile(t, root, "main.lang")
+	geoDir := filepath.Join(root, "geo")
+
+	cached := server.modules.lookup(geoDir)
+	if cached == nil {
+		t.Fatal("expected the imported module to be cached after analysis")
+	}
+
+	change := func(text string) {
+		if err := server.handleDidChange(DidChangeTextDocumentParams{
+			TextDocument:   VersionedTextDocumentIdentifier{URI: uri},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: text}},
+		}); err != nil {
+			t.Fatalf("handleDidChange failed: %v", err)
+		}
+	}
+
+	change("import \"./geo\" (area)\na = area(4, 5)\n")
+	if _, _, clean := documentState(t, server, uri); !clean {
+		t.Fatal("expected analysis with the cached import to succeed")
+	}
+	if server.modules.lookup(geoDir) != cached {
+		t.Error("unchanged import should be served from the cache")
+	}
+
+	// Editing the imported package on disk invalidates the entry
+	if err := os.WriteFile(filepath.Join(geoDir, "geo.lang"), []byte("package geo (area)\n"+
+		"fun area(w: Int) -> Int { w }\n"), 0644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	change("import \"./geo\" (area)\na = area(4, 5)\n")
+	if _, _, clean := documentState(t, server, uri); clean {
+		t.Error("expected the new signature of the import to be checked")
+	}
+	if server.modules.lookup(geoDir) == cached {
+		t.Error("stale module should have been replaced")
+	}
+}
+
+func TestCancelRequest(t *testing.T) {
+	uri := "file:///cancel.funxy"
+	code := "fun f(x: Int) -> Int { x }\n"
+	server, buf := setupServer(t, uri, code)
+
+	server.beginRequest(7)
+	server.cancelRequest(float64(7)) // JSON numbers decode as float64
+	if err := server.handleReferences(7, ReferenceParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, code, "f", 0),
+	}); err != nil {
+		t.Fatalf("handleReferences failed: %v", err)
+	}
+	server.endRequest(7)
+
+	resp := decodeResponse(t, buf)
+	if resp.Error == nil || resp.Error.Code != errorRequestCancelled {
+		t.Errorf("expected a RequestCancelled error, got %+v", resp)
+	}
+}
+
+func TestCancelledRequestStopsWork(t *testing.T) {
+	uri := "file:///cancel.funxy"
+	code := "fun f(x: Int) -> Int { x }\n"
+	server, _ := setupServer(t, uri, code)
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "lib.lang"), []byte("fun g() -> Int { 1 }\n"), 0644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	server.rootPath = root
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if tokens, _ := server.documentSemanticTokens(ctx, uri); len(tokens) == 0 {
+		t.Fatal("expected semantic tokens before cancelling")
+	}
+	if progs := server.workspacePrograms(ctx); len(progs) != 2 {
+		t.Fatalf("expected the document and the workspace file, got %d programs", len(progs))
+	}
+
+	cancel()
+	if tokens, _ := server.documentSemanticTokens(ctx, uri); len(tokens) != 0 {
+		t.Errorf("expected no semantic tokens once cancelled, got %d", len(tokens))
+	}
+	if progs := server.workspacePrograms(ctx); len(progs) != 1 {
+		t.Errorf("expected only the analyzed document once cancelled, got %d programs", len(progs))
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/funvibe/funxy/internal/diagnostics"
+)
+
+func (s *LanguageServer) handleCodeAction(id interface{}, params CodeActionParams) error {
+	log.Printf("Handling code action request for %s at line %d", params.TextDocument.URI, params.Range.Start.Line)
+
+	finalCtx, content := s.documentContext(params.TextDocument.URI)
+	if finalCtx == nil {
+		return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: []CodeAction{}})
+	}
+
+	b := newCodeActionBuilder(finalCtx, params.TextDocument.URI, content)
+	path := s.uriToPath(params.TextDocument.URI)
+	if wantsCodeActionKind(params.Context.Only, codeActionQuickFix) {
+		for _, err := range finalCtx.Errors {
+			converted := s.convertDiagnostics([]*diagnostics.DiagnosticError{err}, path)
+			if len(converted) == 0 || !rangesOverlap(converted[0].Range, params.Range) {
+				continue
+			}
+			b.addQuickFix(err, converted[0])
+		}
+	}
+	if wantsCodeActionKind(params.Context.Only, codeActionRewrite) {
+		b.addDoBlockRewrite(params.Range)
+	}
+
+	actions := b.actions
+	if actions == nil {
+		actions = []CodeAction{}
+	}
+	return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: actions})
+}
+
+// wantsCodeActionKind applies the client's "only" filter, where a kind also
+// matches its sub-kinds ("refactor" selects "refactor.rewrite").
+func wantsCodeActionKind(only []string, kind string) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, k := range only {
+		if kind == k || strings.HasPrefix(kind, k+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func rangesOverlap(a, b Range) bool {
+	return positionInRange(a.Start, b) || positionInRange(a.End, b) ||
+		positionInRange(b.Start, a) || positionInRange(b.End, a)
+}
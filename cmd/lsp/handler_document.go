@@ -6,9 +6,11 @@ import (
 	"log"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/funvibe/funxy/internal/analyzer"
 	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/lint"
 	"github.com/funvibe/funxy/internal/parser"
 	"github.com/funvibe/funxy/internal/pipeline"
 )
@@ -20,6 +22,7 @@ type DocumentState struct {
 	Mu         sync.RWMutex              // Mutex to protect access to state
 	CancelFunc context.CancelFunc        // Cancel function for the current analysis
 	AnalysisID int                       // ID to track current analysis run
+	pending    *time.Timer               // Debounced analysis waiting to start
 }
 
 func (s *LanguageServer) handleDidOpen(params DidOpenTextDocumentParams) error {
@@ -60,57 +63,85 @@ func (s *LanguageServer) handleDidOpen(params DidOpenTextDocumentParams) error {
 }
 
 func (s *LanguageServer) handleDidChange(params DidChangeTextDocumentParams) error {
-	// For now, assume full content sync (TextDocumentSyncKind.Full)
-	if len(params.ContentChanges) > 0 {
-		uri := params.TextDocument.URI
-		newContent := params.ContentChanges[0].Text
-
-		// Get document state
-		s.mu.RLock()
-		docState, exists := s.documents[uri]
-		s.mu.RUnlock()
-
-		if !exists {
-			return fmt.Errorf("document %s not found", uri)
-		}
+	if len(params.ContentChanges) == 0 {
+		return nil
+	}
+	uri := params.TextDocument.URI
 
-		// Cancel previous analysis
-		docState.Mu.Lock()
-		if docState.CancelFunc != nil {
-			docState.CancelFunc()
-		}
+	// Get document state
+	s.mu.RLock()
+	docState, exists := s.documents[uri]
+	s.mu.RUnlock()
 
-		// Create new context and cancel function for this analysis run
-		ctx, cancel := context.WithCancel(context.Background())
-		docState.CancelFunc = cancel
-		docState.AnalysisID++
-		currentAnalysisID := docState.AnalysisID
+	if !exists {
+		return fmt.Errorf("document %s not found", uri)
+	}
 
-		// Update content
-		docState.Content = newContent
+	docState.Mu.Lock()
+	newContent, err := applyContentChanges(docState.Content, params.ContentChanges)
+	if err != nil {
 		docState.Mu.Unlock()
+		return fmt.Errorf("document %s: %v", uri, err)
+	}
 
-		// Re-analyze the document
-		finalCtx := s.analyzeDocument(newContent, uri, ctx)
-
-		// Check if this analysis was cancelled by a newer change
-		if ctx.Err() != nil {
-			return nil // Don't store or publish diagnostics for cancelled analysis
-		}
+	// Cancel previous analysis, whether it is still waiting or already running
+	if docState.CancelFunc != nil {
+		docState.CancelFunc()
+	}
+	if docState.pending != nil {
+		docState.pending.Stop()
+		docState.pending = nil
+	}
 
-		docState.Mu.Lock()
-		// Only update context if we weren't cancelled (double check)
-		if docState.AnalysisID == currentAnalysisID {
-			docState.Context = finalCtx
-		}
+	// Create new context and cancel function for this analysis run
+	ctx, cancel := context.WithCancel(context.Background())
+	docState.CancelFunc = cancel
+	docState.AnalysisID++
+	currentAnalysisID := docState.AnalysisID
+
+	// Update content
+	docState.Content = newContent
+
+	if s.debounce > 0 {
+		// Re-analyze once the user pauses typing; requests meanwhile use the
+		// previous analysis.
+		docState.pending = time.AfterFunc(s.debounce, func() {
+			if err := s.reanalyze(uri, docState, newContent, ctx, currentAnalysisID); err != nil {
+				log.Printf("Error analyzing %s: %v", uri, err)
+			}
+		})
 		docState.Mu.Unlock()
+		return nil
+	}
+	docState.Mu.Unlock()
 
-		log.Printf("Changed file: %s", uri)
+	return s.reanalyze(uri, docState, newContent, ctx, currentAnalysisID)
+}
 
-		// Publish diagnostics
-		return s.publishDiagnostics(uri, finalCtx)
+// reanalyze analyzes a changed document and publishes its diagnostics, unless
+// a newer change has superseded this analysis run.
+func (s *LanguageServer) reanalyze(uri string, docState *DocumentState, content string, ctx context.Context, analysisID int) error {
+	finalCtx := s.analyzeDocument(content, uri, ctx)
+
+	// Check if this analysis was cancelled by a newer change
+	if ctx.Err() != nil {
+		return nil // Don't store or publish diagnostics for cancelled analysis
 	}
-	return nil
+
+	docState.Mu.Lock()
+	// Only update context if we weren't cancelled (double check)
+	if docState.AnalysisID != analysisID {
+		docState.Mu.Unlock()
+		return nil
+	}
+	docState.Context = finalCtx
+	docState.pending = nil
+	docState.Mu.Unlock()
+
+	log.Printf("Changed file: %s", uri)
+
+	// Publish diagnostics
+	return s.publishDiagnostics(uri, finalCtx)
 }
 
 func (s *LanguageServer) handleDidClose(params DidCloseTextDocumentParams) error {
@@ -123,6 +154,9 @@ func (s *LanguageServer) handleDidClose(params DidCloseTextDocumentParams) error
 		if docState.CancelFunc != nil {
 			docState.CancelFunc()
 		}
+		if docState.pending != nil {
+			docState.pending.Stop()
+		}
 		docState.Mu.Unlock()
 		delete(s.documents, uri)
 	}
@@ -134,7 +168,7 @@ func (s *LanguageServer) handleDidClose(params DidCloseTextDocumentParams) error
 
 func (s *LanguageServer) analyzeDocument(content string, uri string, ctx context.Context) *pipeline.PipelineContext {
 	if pipeCtx, ok := s.analyzeModuleDocument(content, uri, ctx); ok {
-		return pipeCtx
+		return (&lint.Processor{Config: s.lint}).Process(pipeCtx)
 	}
 
 	// Create pipeline context
@@ -147,6 +181,7 @@ func (s *LanguageServer) analyzeDocument(content string, uri string, ctx context
 		&lexer.LexerProcessor{},
 		&parser.ParserProcessor{},
 		&analyzer.SemanticAnalyzerProcessor{},
+		&lint.Processor{Config: s.lint},
 	)
 
 	// Run pipeline
@@ -26,10 +26,14 @@ func (s *LanguageServer) handleFormatting(id interface{}, params DocumentFormatt
 	// Read from cache
 	docState.Mu.RLock()
 	content := docState.Content
-	finalCtx := docState.Context
 	docState.Mu.RUnlock()
 
-	if finalCtx == nil || finalCtx.AstRoot == nil {
+	// Format the source; a document that does not parse is left alone
+	formatted, err := prettyprinter.Format(content)
+	if err != nil || formatted == content {
+		if err != nil {
+			log.Printf("Formatting %s failed: %v", params.TextDocument.URI, err)
+		}
 		return s.sendResponse(ResponseMessage{
 			Jsonrpc: "2.0",
 			ID:      id,
@@ -37,15 +41,6 @@ func (s *LanguageServer) handleFormatting(id interface{}, params DocumentFormatt
 		})
 	}
 
-	// Format the AST
-	printer := prettyprinter.NewCodePrinter()
-	if params.Options.TabSize > 0 {
-		// Set line width based on tab size preference
-		printer.SetLineWidth(params.Options.TabSize * 20) // rough estimate
-	}
-	finalCtx.AstRoot.Accept(printer)
-	formatted := printer.String()
-
 	// Create a text edit that replaces the entire document
 	edit := TextEdit{
 		Range: Range{
@@ -503,6 +503,10 @@ func getKeywordHoverText(word string, node ast.Node) string {
 		if _, ok := node.(*ast.InstanceDeclaration); ok || isContainer {
 			return "Keyword: instance"
 		}
+	case "deriving":
+		if _, ok := node.(*ast.TypeDeclarationStatement); ok || isContainer {
+			return "Keyword: deriving"
+		}
 	case "return":
 		if _, ok := node.(*ast.ReturnStatement); ok || isContainer {
 			return "Keyword: return"
@@ -13,13 +13,41 @@ func (s *LanguageServer) handleInitialize(id interface{}, params InitializeParam
 		s.rootPath = *params.RootPath
 	}
 
+	if params.InitializationOptions != nil {
+		for rule, level := range params.InitializationOptions.Lint {
+			if err := s.lint.Set(rule, level); err != nil {
+				log.Printf("Ignoring lint option: %v", err)
+			}
+		}
+	}
+
 	result := InitializeResult{
 		Capabilities: ServerCapabilities{
-			TextDocumentSync:           1, // Full sync
+			TextDocumentSync: TextDocumentSyncOptions{
+				OpenClose: true,
+				Change:    TextDocumentSyncIncremental,
+			},
 			HoverProvider:              true,
 			DefinitionProvider:         true,
 			CompletionProvider:         nil,   // Disable completion as it's not ready/needed yet
 			DocumentFormattingProvider: false, // Disable formatting
+			ReferencesProvider:         true,
+			RenameProvider:             &RenameOptions{PrepareProvider: true},
+			DocumentSymbolProvider:     true,
+			WorkspaceSymbolProvider:    true,
+			SignatureHelpProvider: &SignatureHelpOptions{
+				TriggerCharacters:   []string{"(", ","},
+				RetriggerCharacters: []string{":"},
+			},
+			InlayHintProvider: true,
+			SemanticTokensProvider: &SemanticTokensOptions{
+				Legend: semanticTokensLegend(),
+				Range:  true,
+				Full:   true,
+			},
+			CodeActionProvider: &CodeActionOptions{
+				CodeActionKinds: []string{codeActionQuickFix, codeActionRewrite},
+			},
 		},
 	}
 
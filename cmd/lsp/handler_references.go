@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"unicode"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/pipeline"
+	"github.com/funvibe/funxy/internal/token"
+)
+
+// errorRequestFailed is the LSP error code for requests that are valid but cannot be executed.
+const errorRequestFailed = -32803
+
+func (s *LanguageServer) handleReferences(id interface{}, params ReferenceParams) error {
+	log.Printf("Handling references request for %s at line %d, char %d", params.TextDocument.URI, params.Position.Line, params.Position.Character)
+
+	docCtx, content := s.documentContext(params.TextDocument.URI)
+	if docCtx == nil {
+		return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: nil})
+	}
+
+	target, _ := resolveReferenceTarget(docCtx, content, params.Position.Line+1, params.Position.Character+1)
+	if target == nil {
+		return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: nil})
+	}
+
+	locations := []Location{}
+	for _, occ := range s.findOccurrences(s.requestContext(id), docCtx, target) {
+		if occ.IsDeclaration && !params.Context.IncludeDeclaration {
+			continue
+		}
+		locations = append(locations, Location{URI: pathToURI(occ.File), Range: occ.toRange()})
+	}
+
+	return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: locations})
+}
+
+func (s *LanguageServer) handlePrepareRename(id interface{}, params PrepareRenameParams) error {
+	docCtx, content := s.documentContext(params.TextDocument.URI)
+	if docCtx == nil {
+		return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: nil})
+	}
+
+	target, occ := resolveReferenceTarget(docCtx, content, params.Position.Line+1, params.Position.Character+1)
+	if target == nil {
+		return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: nil})
+	}
+
+	if msg := s.checkRenameable(s.requestContext(id), docCtx, target); msg != "" {
+		return s.sendRequestFailed(id, msg)
+	}
+
+	return s.sendResponse(ResponseMessage{
+		Jsonrpc: "2.0",
+		ID:      id,
+		Result:  PrepareRenameResult{Range: occ.toRange(), Placeholder: target.Name},
+	})
+}
+
+func (s *LanguageServer) handleRename(id interface{}, params RenameParams) error {
+	log.Printf("Handling rename request for %s at line %d, char %d to %q", params.TextDocument.URI, params.Position.Line, params.Position.Character, params.NewName)
+
+	docCtx, content := s.documentContext(params.TextDocument.URI)
+	if docCtx == nil {
+		return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: nil})
+	}
+
+	target, _ := resolveReferenceTarget(docCtx, content, params.Position.Line+1, params.Position.Character+1)
+	if target == nil {
+		return s.sendRequestFailed(id, "No renameable symbol at this position")
+	}
+
+	if err := validateNewName(target.Name, params.NewName); err != nil {
+		return s.sendRequestFailed(id, err.Error())
+	}
+
+	occurrences := s.findOccurrences(s.requestContext(id), docCtx, target)
+	if !hasDeclaration(occurrences) {
+		return s.sendRequestFailed(id, fmt.Sprintf("Cannot rename '%s': it is not declared in the workspace", target.Name))
+	}
+
+	edit := WorkspaceEdit{Changes: make(map[string][]TextEdit)}
+	for _, occ := range occurrences {
+		uri := pathToURI(occ.File)
+		edit.Changes[uri] = append(edit.Changes[uri], TextEdit{Range: occ.toRange(), NewText: params.NewName})
+	}
+
+	return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: edit})
+}
+
+// documentContext returns the cached analysis and text of an open document.
+func (s *LanguageServer) documentContext(uri string) (*pipeline.PipelineContext, string) {
+	s.mu.RLock()
+	docState, exists := s.documents[uri]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, ""
+	}
+
+	docState.Mu.RLock()
+	defer docState.Mu.RUnlock()
+	if docState.Context == nil || docState.Context.AstRoot == nil {
+		return nil, ""
+	}
+	return docState.Context, docState.Content
+}
+
+// findOccurrences collects occurrences of target. Locals are searched in the cached
+// analysis of the document (their identity is tied to its AST); other symbols are
+// searched across the whole workspace.
+func (s *LanguageServer) findOccurrences(ctx context.Context, docCtx *pipeline.PipelineContext, target *referenceTarget) []symbolOccurrence {
+	docUnit := &analyzedUnit{Ctx: docCtx}
+	if prog, ok := docCtx.AstRoot.(*ast.Program); ok {
+		docUnit.Files = []*ast.Program{programWithFile(prog, docCtx.FilePath)}
+	}
+
+	if target.Kind == targetLocal {
+		return collectOccurrences([]*analyzedUnit{docUnit}, target, s.fileContent)
+	}
+
+	units := s.analyzeWorkspace(ctx)
+	if !unitsContainFile(units, docCtx.FilePath) {
+		units = append(units, docUnit)
+	}
+	return collectOccurrences(units, target, s.fileContent)
+}
+
+// programWithFile returns prog with its File set to path. The cached AST is shared
+// between concurrently handled requests, so a shallow copy is made instead of
+// mutating it.
+func programWithFile(prog *ast.Program, path string) *ast.Program {
+	if prog.File != "" {
+		return prog
+	}
+	named := *prog
+	named.File = path
+	return &named
+}
+
+// checkRenameable returns a reason why target cannot be renamed, or "" if it can.
+func (s *LanguageServer) checkRenameable(ctx context.Context, docCtx *pipeline.PipelineContext, target *referenceTarget) string {
+	if target.Kind == targetLocal {
+		return ""
+	}
+	if !hasDeclaration(s.findOccurrences(ctx, docCtx, target)) {
+		return fmt.Sprintf("Cannot rename '%s': it is not declared in the workspace", target.Name)
+	}
+	return ""
+}
+
+func (s *LanguageServer) sendRequestFailed(id interface{}, message string) error {
+	return s.sendResponse(ResponseMessage{
+		Jsonrpc: "2.0",
+		ID:      id,
+		Error: &Error{
+			Code:    errorRequestFailed,
+			Message: message,
+		},
+	})
+}
+
+func hasDeclaration(occurrences []symbolOccurrence) bool {
+	for _, occ := range occurrences {
+		if occ.IsDeclaration {
+			return true
+		}
+	}
+	return false
+}
+
+func unitsContainFile(units []*analyzedUnit, path string) bool {
+	clean := filepath.Clean(path)
+	for _, unit := range units {
+		for _, file := range unit.Files {
+			if filepath.Clean(file.File) == clean {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateNewName checks that newName is a single identifier that keeps the
+// case class of oldName (uppercase names denote types, constructors and traits).
+func validateNewName(oldName, newName string) error {
+	l := lexer.New(newName)
+	tok := l.NextToken()
+	if tok.Type != token.IDENT_LOWER && tok.Type != token.IDENT_UPPER {
+		return fmt.Errorf("'%s' is not a valid identifier", newName)
+	}
+	if tok.Lexeme != newName || l.NextToken().Type != token.EOF {
+		return fmt.Errorf("'%s' is not a valid identifier", newName)
+	}
+	if unicode.IsUpper(firstRune(oldName)) != unicode.IsUpper(firstRune(newName)) {
+		if unicode.IsUpper(firstRune(oldName)) {
+			return fmt.Errorf("'%s' must start with an uppercase letter", newName)
+		}
+		return fmt.Errorf("'%s' must start with a lowercase letter", newName)
+	}
+	return nil
+}
+
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+
+	"github.com/funvibe/funxy/internal/pipeline"
+)
+
+func (s *LanguageServer) handleSemanticTokensFull(id interface{}, params SemanticTokensParams) error {
+	tokens, ok := s.documentSemanticTokens(s.requestContext(id), params.TextDocument.URI)
+	if !ok {
+		return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: nil})
+	}
+	return s.sendResponse(ResponseMessage{
+		Jsonrpc: "2.0",
+		ID:      id,
+		Result:  SemanticTokens{Data: encodeSemanticTokens(tokens)},
+	})
+}
+
+func (s *LanguageServer) handleSemanticTokensRange(id interface{}, params SemanticTokensRangeParams) error {
+	tokens, ok := s.documentSemanticTokens(s.requestContext(id), params.TextDocument.URI)
+	if !ok {
+		return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: nil})
+	}
+
+	var inRange []semanticToken
+	for _, tok := range tokens {
+		start := Position{Line: tok.Line, Character: tok.Char}
+		end := Position{Line: tok.Line, Character: tok.Char + tok.Length}
+		if positionInRange(start, params.Range) || positionInRange(end, params.Range) {
+			inRange = append(inRange, tok)
+		}
+	}
+	return s.sendResponse(ResponseMessage{
+		Jsonrpc: "2.0",
+		ID:      id,
+		Result:  SemanticTokens{Data: encodeSemanticTokens(inRange)},
+	})
+}
+
+// documentSemanticTokens classifies the tokens of an open document. Documents
+// that failed to analyze still get lexical highlighting.
+func (s *LanguageServer) documentSemanticTokens(reqCtx context.Context, uri string) ([]semanticToken, bool) {
+	s.mu.RLock()
+	docState, exists := s.documents[uri]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, false
+	}
+
+	docState.Mu.RLock()
+	defer docState.Mu.RUnlock()
+	var ctx *pipeline.PipelineContext
+	if docState.Context != nil && docState.Context.AstRoot != nil && docState.Context.SymbolTable != nil {
+		ctx = docState.Context
+	}
+	return buildSemanticTokens(reqCtx, ctx, docState.Content), true
+}
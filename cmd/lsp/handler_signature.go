@@ -0,0 +1,138 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/pipeline"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+func (s *LanguageServer) handleSignatureHelp(id interface{}, params SignatureHelpParams) error {
+	log.Printf("Handling signature help request for %s at line %d, char %d", params.TextDocument.URI, params.Position.Line, params.Position.Character)
+
+	finalCtx, content := s.documentContext(params.TextDocument.URI)
+	if finalCtx == nil {
+		return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: nil})
+	}
+
+	call, ok := findCallContext(content, params.Position.Line, params.Position.Character)
+	if !ok {
+		return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: nil})
+	}
+
+	fn, decl, ok := calleeSignature(finalCtx, call)
+	if !ok {
+		return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: nil})
+	}
+
+	name := call.Callee[strings.LastIndex(call.Callee, ".")+1:]
+	return s.sendResponse(ResponseMessage{
+		Jsonrpc: "2.0",
+		ID:      id,
+		Result: SignatureHelp{
+			Signatures:      []SignatureInformation{buildSignature(name, fn, decl)},
+			ActiveSignature: 0,
+			ActiveParameter: activeParameter(fn, call),
+		},
+	})
+}
+
+func (s *LanguageServer) handleInlayHint(id interface{}, params InlayHintParams) error {
+	finalCtx, _ := s.documentContext(params.TextDocument.URI)
+	if finalCtx == nil {
+		return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: []InlayHint{}})
+	}
+
+	hints := []InlayHint{}
+	for _, hint := range collectInlayHints(finalCtx) {
+		if positionInRange(hint.Position, params.Range) {
+			hints = append(hints, hint)
+		}
+	}
+
+	return s.sendResponse(ResponseMessage{Jsonrpc: "2.0", ID: id, Result: hints})
+}
+
+// collectInlayHints returns type hints for bindings and lambda parameters
+// written without a type annotation.
+func collectInlayHints(ctx *pipeline.PipelineContext) []InlayHint {
+	var hints []InlayHint
+	addHint := func(ident *ast.Identifier, t typesystem.Type) {
+		label := PrettifyType(t)
+		if label == "" {
+			return
+		}
+		hints = append(hints, InlayHint{
+			Position: Position{Line: ident.Token.Line - 1, Character: ident.Token.Column - 1 + len(ident.Value)},
+			Label:    ": " + label,
+			Kind:     InlayHintKindType,
+		})
+	}
+
+	ast.Inspect(ctx.AstRoot, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignExpression:
+			ident, ok := n.Left.(*ast.Identifier)
+			if !ok || n.AnnotatedType != nil || ident.Value == "_" || isFunctionValue(n.Value) {
+				return true
+			}
+			// Only the defining assignment gets a hint, not later updates
+			if sym, ok := ctx.ResolutionMap[ident]; ok && sym.DefinitionNode == ast.Node(ident) {
+				addHint(ident, bindingType(ctx, sym.Type, n.Value))
+			}
+		case *ast.ConstantDeclaration:
+			if n.Name == nil || n.TypeAnnotation != nil || strings.HasPrefix(n.Name.Value, "$") || isFunctionValue(n.Value) {
+				return true
+			}
+			var symType typesystem.Type
+			if sym, ok := ctx.ResolutionMap[n.Name]; ok {
+				symType = sym.Type
+			}
+			addHint(n.Name, bindingType(ctx, symType, n.Value))
+		case *ast.FunctionLiteral:
+			fn, ok := asFunc(ctx.TypeMap[n])
+			if !ok {
+				return true
+			}
+			for i, param := range n.Parameters {
+				if param.Type != nil || param.Name == nil || param.IsIgnored || i >= len(fn.Params) {
+					continue
+				}
+				addHint(param.Name, fn.Params[i])
+			}
+		}
+		return true
+	})
+	return hints
+}
+
+// bindingType prefers the inferred type of the bound value, which has all
+// substitutions applied, over the (possibly generalized) symbol type.
+func bindingType(ctx *pipeline.PipelineContext, symType typesystem.Type, value ast.Expression) typesystem.Type {
+	if t := ctx.TypeMap[value]; t != nil {
+		return t
+	}
+	return symType
+}
+
+// isFunctionValue reports whether a bound value is a lambda: its parameters get
+// their own hints, and the full function type would repeat them.
+func isFunctionValue(value ast.Expression) bool {
+	_, ok := value.(*ast.FunctionLiteral)
+	return ok
+}
+
+func positionInRange(pos Position, r Range) bool {
+	if pos.Line < r.Start.Line || pos.Line > r.End.Line {
+		return false
+	}
+	if pos.Line == r.Start.Line && pos.Character < r.Start.Character {
+		return false
+	}
+	if pos.Line == r.End.Line && pos.Character > r.End.Character {
+		return false
+	}
+	return true
+}
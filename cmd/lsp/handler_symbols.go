@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/funvibe/funxy/internal/ast"
+)
+
+// maxWorkspaceSymbols bounds the number of results returned for a workspace symbol query.
+const maxWorkspaceSymbols = 1000
+
+func (s *LanguageServer) handleDocumentSymbol(id interface{}, params DocumentSymbolParams) error {
+	log.Printf("Handling document symbol request for %s", params.TextDocument.URI)
+
+	s.mu.RLock()
+	docState, exists := s.documents[params.TextDocument.URI]
+	s.mu.RUnlock()
+
+	if !exists {
+		return s.sendResponse(ResponseMessage{
+			Jsonrpc: "2.0",
+			ID:      id,
+			Result:  []DocumentSymbol{},
+		})
+	}
+
+	docState.Mu.RLock()
+	content := docState.Content
+	finalCtx := docState.Context
+	docState.Mu.RUnlock()
+
+	// The outline only needs the syntax tree, so fall back to parsing if analysis is not ready
+	var prog *ast.Program
+	if finalCtx != nil {
+		prog, _ = finalCtx.AstRoot.(*ast.Program)
+	}
+	if prog == nil {
+		prog, _ = parseProgramFromContent(s.uriToPath(params.TextDocument.URI), content, s.requestContext(id))
+	}
+
+	return s.sendResponse(ResponseMessage{
+		Jsonrpc: "2.0",
+		ID:      id,
+		Result:  buildDocumentSymbols(prog, lexTokens(content)),
+	})
+}
+
+func (s *LanguageServer) handleWorkspaceSymbol(id interface{}, params WorkspaceSymbolParams) error {
+	log.Printf("Handling workspace symbol request for %q", params.Query)
+
+	ctx := s.requestContext(id)
+	result := []SymbolInformation{}
+	for _, prog := range s.workspacePrograms(ctx) {
+		uri := pathToURI(prog.File)
+		for _, sym := range buildDocumentSymbols(prog, nil) {
+			result = appendMatchingSymbols(result, uri, "", sym, params.Query)
+		}
+		if len(result) >= maxWorkspaceSymbols {
+			result = result[:maxWorkspaceSymbols]
+			break
+		}
+	}
+
+	return s.sendResponse(ResponseMessage{
+		Jsonrpc: "2.0",
+		ID:      id,
+		Result:  result,
+	})
+}
+
+// workspacePrograms returns the syntax trees of all files in the workspace and of
+// every module loaded while analyzing open documents (dependencies may live outside the root).
+// Once ctx is cancelled, the files not parsed yet are left out.
+func (s *LanguageServer) workspacePrograms(ctx context.Context) []*ast.Program {
+	programs := make(map[string]*ast.Program)
+	openDocs := s.collectOpenDocuments("", "")
+
+	s.mu.RLock()
+	var contexts []*DocumentState
+	for _, doc := range s.documents {
+		contexts = append(contexts, doc)
+	}
+	s.mu.RUnlock()
+
+	for _, doc := range contexts {
+		doc.Mu.RLock()
+		finalCtx := doc.Context
+		doc.Mu.RUnlock()
+		if finalCtx == nil {
+			continue
+		}
+
+		if prog, ok := finalCtx.AstRoot.(*ast.Program); ok && prog != nil && finalCtx.FilePath != "" {
+			programs[filepath.Clean(finalCtx.FilePath)] = prog
+		}
+		loader, ok := finalCtx.Loader.(*lspModuleLoader)
+		if !ok || loader.base == nil {
+			continue
+		}
+		for _, mod := range loader.base.LoadedModules {
+			for _, file := range mod.Files {
+				if file == nil || file.File == "" {
+					continue
+				}
+				path := filepath.Clean(file.File)
+				if _, exists := programs[path]; !exists {
+					programs[path] = file
+				}
+			}
+		}
+	}
+
+	if root := s.workspaceRoot(); root != "" {
+		for _, path := range s.collectWorkspaceFiles(root, openDocs) {
+			if ctx.Err() != nil {
+				break
+			}
+			if _, exists := programs[path]; exists {
+				continue
+			}
+			content, ok := s.fileContent(path)
+			if !ok {
+				continue
+			}
+			if prog, _ := parseProgramFromContent(path, content, ctx); prog != nil {
+				prog.File = path
+				programs[path] = prog
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(programs))
+	for path := range programs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	result := make([]*ast.Program, 0, len(paths))
+	for _, path := range paths {
+		result = append(result, programWithFile(programs[path], path))
+	}
+	return result
+}
+
+// appendMatchingSymbols flattens a document symbol tree into symbol information,
+// keeping the entries whose name matches query.
+func appendMatchingSymbols(result []SymbolInformation, uri, container string, sym DocumentSymbol, query string) []SymbolInformation {
+	if matchesSymbolQuery(sym.Name, query) {
+		result = append(result, SymbolInformation{
+			Name:          sym.Name,
+			Kind:          sym.Kind,
+			Location:      Location{URI: uri, Range: sym.SelectionRange},
+			ContainerName: container,
+		})
+	}
+	for _, child := range sym.Children {
+		result = appendMatchingSymbols(result, uri, sym.Name, child, query)
+	}
+	return result
+}
+
+// matchesSymbolQuery reports whether the characters of query appear in name in order,
+// ignoring case. An empty query matches everything.
+func matchesSymbolQuery(name, query string) bool {
+	queryRunes := []rune(strings.ToLower(query))
+	i := 0
+	for _, r := range strings.ToLower(name) {
+		if i < len(queryRunes) && unicode.ToLower(queryRunes[i]) == r {
+			i++
+		}
+	}
+	return i == len(queryRunes)
+}
@@ -60,6 +60,11 @@ func TestKeywordHover(t *testing.T) {
 			code:     "inst$ance Show Int { }",
 			expected: "Keyword: instance",
 		},
+		{
+			name:     "Deriving Keyword",
+			code:     "type Color = Red | Blue deri$ving (Equal)",
+			expected: "Keyword: deriving",
+		},
 		{
 			name:     "Return Keyword",
 			code:     "fun test() { ret$urn 1 }",
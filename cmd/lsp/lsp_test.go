@@ -482,3 +482,39 @@ func TestLSP_Hover_CallExpression_Parens(t *testing.T) {
 		t.Errorf("Expected hover on '(' NOT to show just return type 'Nil', got: %s", content)
 	}
 }
+
+func TestLSP_Diagnostics_LintWarnings(t *testing.T) {
+	buf := new(bytes.Buffer)
+	server := NewLanguageServer(buf)
+	if err := server.handleInitialize(0, InitializeParams{
+		InitializationOptions: &InitializationOptions{Lint: map[string]string{"unused-parameter": "off"}},
+	}); err != nil {
+		t.Fatalf("handleInitialize failed: %v", err)
+	}
+	buf.Reset()
+
+	code := "fun f(x: Int) -> Int {\n" +
+		"    y = 1\n" +
+		"    0\n" +
+		"}\n" +
+		"print(f(1))\n"
+	if err := server.handleDidOpen(DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: "file:///lint.funxy", LanguageID: "funxy", Version: 1, Text: code},
+	}); err != nil {
+		t.Fatalf("handleDidOpen failed: %v", err)
+	}
+
+	var notification struct {
+		Params PublishDiagnosticsParams `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(parseLSPOutput(t, buf.String())), &notification); err != nil {
+		t.Fatalf("failed to decode notification: %v", err)
+	}
+	diags := notification.Params.Diagnostics
+	if len(diags) != 1 {
+		t.Fatalf("expected only the unused variable warning, got %+v", diags)
+	}
+	if diags[0].Code != "W001" || diags[0].Severity != SeverityWarning || diags[0].Range.Start != (Position{Line: 1, Character: 4}) {
+		t.Errorf("unexpected diagnostic: %+v", diags[0])
+	}
+}
@@ -18,6 +18,8 @@ func main() {
 	log.SetOutput(os.Stderr) // Log to stderr, not stdout (stdout is for LSP protocol)
 
 	server := NewLanguageServer(os.Stdout)
+	server.debounce = analysisDebounce
+	server.concurrent = true
 	server.Start()
 }
 
@@ -29,10 +29,11 @@ func (s *LanguageServer) analyzeModuleDocument(content string, uri string, ctx c
 		return nil, false
 	}
 
-	loader := newLspModuleLoader(s.rootPath)
+	loader := newLspModuleLoader(s.rootPath, s.modules)
 	pipeCtx.Loader = loader
 
 	moduleDir := utils.GetModuleDir(pipeCtx.FilePath)
+	loader.edited, _ = filepath.Abs(moduleDir)
 	openDocs := s.collectOpenDocuments(uri, content)
 
 	// Check if this is a standalone script (no package declaration)
@@ -118,6 +119,10 @@ func (s *LanguageServer) analyzeModuleDocument(content string, uri string, ctx c
 		pipeCtx.Errors = append(pipeCtx.Errors, errors...)
 	}
 
+	if s.modules != nil && ctx.Err() == nil {
+		s.modules.store(loader.base, mod.Dir)
+	}
+
 	return pipeCtx, true
 }
 
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/funvibe/funxy/internal/config"
+	"github.com/funvibe/funxy/internal/modules"
+)
+
+// moduleCache keeps analyzed imported modules between analyses so that editing
+// a file does not re-parse and re-check every package it imports. An entry is
+// reused while the source files of the module and of everything it imports are
+// unchanged on disk.
+type moduleCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedModule // module directory -> analyzed module
+}
+
+type cachedModule struct {
+	mod   *modules.Module
+	stamp uint64
+}
+
+func newModuleCache() *moduleCache {
+	return &moduleCache{entries: make(map[string]*cachedModule)}
+}
+
+// lookup returns the cached module for dir if it is still up to date.
+func (c *moduleCache) lookup(dir string) *modules.Module {
+	c.mu.Lock()
+	entry, ok := c.entries[dir]
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if stamp, ok := moduleStamp(entry.mod); !ok || stamp != entry.stamp {
+		c.mu.Lock()
+		if c.entries[dir] == entry {
+			delete(c.entries, dir)
+		}
+		c.mu.Unlock()
+		return nil
+	}
+	return entry.mod
+}
+
+// store records the fully analyzed modules of loader. The module being edited
+// and modules that depend on it are skipped: their analysis reflects unsaved
+// content.
+func (c *moduleCache) store(loader *modules.Loader, editedDir string) {
+	for key, mod := range loader.LoadedModules {
+		if mod == nil || mod.IsVirtual || isSyntheticModuleKey(key) || !mod.BodiesAnalyzed {
+			continue
+		}
+		if dependsOn(mod, editedDir, make(map[*modules.Module]bool)) {
+			continue
+		}
+		stamp, ok := moduleStamp(mod)
+		if !ok {
+			continue
+		}
+		c.mu.Lock()
+		if existing, ok := c.entries[mod.Dir]; !ok || existing.stamp != stamp {
+			c.entries[mod.Dir] = &cachedModule{mod: mod, stamp: stamp}
+		}
+		c.mu.Unlock()
+	}
+}
+
+// installModule registers a cached module and its imports with a fresh loader.
+func installModule(loader *modules.Loader, mod *modules.Module) {
+	if mod == nil || mod.IsVirtual {
+		return
+	}
+	if _, ok := loader.LoadedModules[mod.Dir]; ok {
+		return
+	}
+	loader.LoadedModules[mod.Dir] = mod
+	loader.ModulesByName[mod.Name] = mod
+	for _, imported := range mod.Imports {
+		installModule(loader, imported)
+	}
+}
+
+func dependsOn(mod *modules.Module, dir string, seen map[*modules.Module]bool) bool {
+	if mod == nil || seen[mod] {
+		return false
+	}
+	seen[mod] = true
+	if mod.Dir == dir {
+		return true
+	}
+	for _, imported := range mod.Imports {
+		if dependsOn(imported, dir, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleStamp fingerprints the source files of mod and its transitive imports.
+func moduleStamp(mod *modules.Module) (uint64, bool) {
+	dirs := make(map[string]bool)
+	collectModuleDirs(mod, dirs)
+
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Strings(sorted)
+
+	h := fnv.New64a()
+	for _, dir := range sorted {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return 0, false
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !isSourceFile(entry.Name()) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return 0, false
+			}
+			fmt.Fprintf(h, "%s\x00%d\x00%d\n", filepath.Join(dir, entry.Name()), info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return h.Sum64(), true
+}
+
+func collectModuleDirs(mod *modules.Module, dirs map[string]bool) {
+	if mod == nil || mod.IsVirtual || mod.Dir == "" || dirs[mod.Dir] {
+		return
+	}
+	dirs[mod.Dir] = true
+	for _, imported := range mod.Imports {
+		collectModuleDirs(imported, dirs)
+	}
+}
+
+// isSyntheticModuleKey reports loader keys of modules that have no directory
+// of their own (virtual packages and bundled modules).
+func isSyntheticModuleKey(key string) bool {
+	return strings.HasPrefix(key, "virtual:") || strings.HasPrefix(key, "bundle:")
+}
+
+func isSourceFile(name string) bool {
+	for _, ext := range config.SourceFileExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
@@ -8,22 +8,48 @@ import (
 )
 
 type lspModuleLoader struct {
-	root string
-	base *modules.Loader
+	root   string
+	base   *modules.Loader
+	cache  *moduleCache // Analyzed imports shared between analyses (may be nil)
+	edited string       // Directory of the module being edited, never served from the cache
 }
 
-func newLspModuleLoader(root string) *lspModuleLoader {
+func newLspModuleLoader(root string, cache *moduleCache) *lspModuleLoader {
 	return &lspModuleLoader{
-		root: root,
-		base: modules.NewLoader(),
+		root:  root,
+		base:  modules.NewLoader(),
+		cache: cache,
 	}
 }
 
 func (l *lspModuleLoader) GetModule(path string) (interface{}, error) {
 	resolved := l.resolvePath(path)
+	if mod := l.cachedModule(resolved); mod != nil {
+		return mod, nil
+	}
 	return l.base.GetModule(resolved)
 }
 
+// cachedModule returns an up-to-date analyzed module for path from the cache.
+func (l *lspModuleLoader) cachedModule(path string) *modules.Module {
+	if l.cache == nil || path == "" || path == "lib" || strings.HasPrefix(path, "lib/") {
+		return nil
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil || absPath == l.edited {
+		return nil
+	}
+	if _, ok := l.base.LoadedModules[absPath]; ok {
+		return nil
+	}
+	mod := l.cache.lookup(absPath)
+	if mod == nil {
+		return nil
+	}
+	installModule(l.base, mod)
+	return mod
+}
+
 func (l *lspModuleLoader) GetModuleByPackageName(name string) interface{} {
 	return l.base.GetModuleByPackageName(name)
 }
@@ -0,0 +1,820 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/modules"
+	"github.com/funvibe/funxy/internal/pipeline"
+	"github.com/funvibe/funxy/internal/symbols"
+	"github.com/funvibe/funxy/internal/token"
+	"github.com/funvibe/funxy/internal/typesystem"
+	"github.com/funvibe/funxy/internal/utils"
+)
+
+// referenceTargetKind classifies the symbol a references/rename request points at.
+type referenceTargetKind int
+
+const (
+	targetLocal  referenceTargetKind = iota // Parameter or local binding, identified by its definition node
+	targetGlobal                            // Top-level declaration, identified by package and name
+	targetMember                            // Record field or extension method, identified by owner type and name
+)
+
+// referenceTarget describes the symbol whose occurrences are searched.
+type referenceTarget struct {
+	Kind           referenceTargetKind
+	Name           string
+	Package        string   // Defining package (targetGlobal); "" for standalone scripts
+	File           string   // Restricts the search to one file (locals and script globals)
+	DefinitionNode ast.Node // Definition identifier (targetLocal)
+	OwnerType      string   // Nominal type owning the member (targetMember)
+}
+
+// symbolOccurrence is a single textual occurrence of a target symbol.
+type symbolOccurrence struct {
+	File          string
+	Line          int // 1-based, as in token.Token
+	Column        int // 1-based, as in token.Token
+	Length        int
+	IsDeclaration bool
+}
+
+func (o symbolOccurrence) toRange() Range {
+	return Range{
+		Start: Position{Line: o.Line - 1, Character: o.Column - 1},
+		End:   Position{Line: o.Line - 1, Character: o.Column - 1 + o.Length},
+	}
+}
+
+func (o symbolOccurrence) contains(line, char int) bool {
+	return o.Line == line && char >= o.Column && char <= o.Column+o.Length
+}
+
+// resolveReferenceTarget determines which symbol is under the cursor.
+// line and char are 1-based. Returns nil if the position does not point at a renameable symbol.
+func resolveReferenceTarget(ctx *pipeline.PipelineContext, content string, line, char int) (*referenceTarget, *symbolOccurrence) {
+	if ctx == nil || ctx.AstRoot == nil {
+		return nil, nil
+	}
+	path := FindNodePath(ctx.AstRoot, line, char)
+	if len(path) == 0 {
+		return nil, nil
+	}
+	node := path[len(path)-1]
+	var parent ast.Node
+	if len(path) >= 2 {
+		parent = path[len(path)-2]
+	}
+
+	switch n := node.(type) {
+	case *ast.Identifier:
+		occ := identOccurrence(ctx.FilePath, n)
+		if !occ.contains(line, char) {
+			return nil, nil
+		}
+		target := classifyIdentifier(ctx, n, parent, path)
+		if target == nil {
+			return nil, nil
+		}
+		occ.IsDeclaration = isDeclarationIdent(n, parent, target)
+		return target, &occ
+	case *ast.IdentifierPattern:
+		sym, ok := ctx.ResolutionMap[n]
+		if !ok {
+			return nil, nil
+		}
+		def := sym.DefinitionNode
+		if def == nil {
+			def = n
+		}
+		occ := symbolOccurrence{File: ctx.FilePath, Line: n.Token.Line, Column: n.Token.Column, Length: len(n.Value), IsDeclaration: true}
+		return &referenceTarget{Kind: targetLocal, Name: n.Value, File: ctx.FilePath, DefinitionNode: def}, &occ
+	case *ast.RecordType, *ast.RecordLiteral, *ast.RecordPattern:
+		word := getWordAtPosition(content, line-1, char-1)
+		if word == "" {
+			return nil, nil
+		}
+		owner := recordOwnerType(ctx, node, parent)
+		if owner == "" {
+			return nil, nil
+		}
+		keys := recordKeyTokens(lexTokens(content), node)
+		tok, ok := keys[word]
+		if !ok {
+			return nil, nil
+		}
+		occ := symbolOccurrence{File: ctx.FilePath, Line: tok.Line, Column: tok.Column, Length: len(word)}
+		if !occ.contains(line, char) {
+			return nil, nil
+		}
+		_, occ.IsDeclaration = node.(*ast.RecordType)
+		return &referenceTarget{Kind: targetMember, Name: word, OwnerType: owner}, &occ
+	}
+
+	return nil, nil
+}
+
+// classifyIdentifier builds a reference target for an identifier found at the cursor.
+func classifyIdentifier(ctx *pipeline.PipelineContext, ident *ast.Identifier, parent ast.Node, path []ast.Node) *referenceTarget {
+	currentPkg := programPackage(ctx.AstRoot)
+
+	switch p := parent.(type) {
+	case *ast.MemberExpression:
+		if p.Member == ident {
+			return memberExpressionTarget(ctx, p)
+		}
+	case *ast.ImportStatement:
+		for _, sym := range append(append([]*ast.Identifier{}, p.Symbols...), p.Exclude...) {
+			if sym == ident {
+				mod := importedModule(ctx, ctx.FilePath, p)
+				if mod == nil {
+					return nil
+				}
+				return &referenceTarget{Kind: targetGlobal, Name: ident.Value, Package: exportOrigin(mod, ident.Value)}
+			}
+		}
+		return nil
+	case *ast.PackageDeclaration:
+		for _, exp := range p.Exports {
+			if exp.Symbol == ident {
+				return globalTarget(ctx, ident.Value, currentPkg)
+			}
+			for _, sym := range exp.Symbols {
+				if sym == ident && exp.ModuleName != nil {
+					return &referenceTarget{Kind: targetGlobal, Name: ident.Value, Package: aliasOrigin(ctx, exp.ModuleName.Value, ident.Value)}
+				}
+			}
+		}
+		return nil
+	case *ast.NamedType:
+		return namedTypeTarget(ctx, p, currentPkg)
+	case *ast.FunctionStatement:
+		if p.Name == ident {
+			if p.Receiver != nil {
+				owner := receiverTypeName(p.Receiver)
+				if owner == "" {
+					return nil
+				}
+				return &referenceTarget{Kind: targetMember, Name: ident.Value, OwnerType: owner}
+			}
+			if len(path) >= 3 {
+				switch container := path[len(path)-3].(type) {
+				case *ast.Program:
+					return globalTarget(ctx, ident.Value, currentPkg)
+				case *ast.TraitDeclaration:
+					return globalTarget(ctx, ident.Value, currentPkg)
+				case *ast.InstanceDeclaration:
+					return traitMethodTarget(ctx, container, ident.Value)
+				}
+			}
+			// Nested function: references resolve to its name node
+			return &referenceTarget{Kind: targetLocal, Name: ident.Value, File: ctx.FilePath, DefinitionNode: ident}
+		}
+	}
+
+	if sym, ok := ctx.ResolutionMap[ident]; ok {
+		return targetFromSymbol(ctx, ident, sym, currentPkg)
+	}
+
+	// Unresolved identifiers: declaration names and constructor/trait references
+	sym, ok := ctx.SymbolTable.Find(ident.Value)
+	if !ok || sym.Kind == symbols.ModuleSymbol {
+		return nil
+	}
+	return targetFromSymbol(ctx, ident, sym, currentPkg)
+}
+
+func targetFromSymbol(ctx *pipeline.PipelineContext, ident *ast.Identifier, sym symbols.Symbol, currentPkg string) *referenceTarget {
+	if sym.Kind == symbols.ModuleSymbol {
+		return nil
+	}
+	if isGlobalSymbol(ctx, sym) {
+		return globalTarget(ctx, ident.Value, symbolPackage(sym, currentPkg))
+	}
+	def := sym.DefinitionNode
+	if def == nil {
+		def = ident
+	}
+	return &referenceTarget{Kind: targetLocal, Name: ident.Value, File: ctx.FilePath, DefinitionNode: def}
+}
+
+func globalTarget(ctx *pipeline.PipelineContext, name, pkg string) *referenceTarget {
+	target := &referenceTarget{Kind: targetGlobal, Name: name, Package: pkg}
+	if pkg == "" {
+		// Standalone scripts have no package, so their globals are file-local
+		target.File = ctx.FilePath
+	}
+	return target
+}
+
+func traitMethodTarget(ctx *pipeline.PipelineContext, inst *ast.InstanceDeclaration, method string) *referenceTarget {
+	traitName := inst.TraitName.Value
+	if inst.ModuleName != nil {
+		return &referenceTarget{Kind: targetGlobal, Name: method, Package: aliasOrigin(ctx, inst.ModuleName.Value, traitName)}
+	}
+	traitSym, ok := ctx.SymbolTable.Find(traitName)
+	if !ok {
+		return nil
+	}
+	return globalTarget(ctx, method, symbolPackage(traitSym, programPackage(ctx.AstRoot)))
+}
+
+func memberExpressionTarget(ctx *pipeline.PipelineContext, member *ast.MemberExpression) *referenceTarget {
+	if left, ok := member.Left.(*ast.Identifier); ok {
+		if alias, isModule := moduleAliasOf(ctx, left); isModule {
+			return &referenceTarget{Kind: targetGlobal, Name: member.Member.Value, Package: aliasOrigin(ctx, alias, member.Member.Value)}
+		}
+	}
+	owner := nominalTypeName(ctx.TypeMap[member.Left])
+	if owner == "" {
+		return nil
+	}
+	return &referenceTarget{Kind: targetMember, Name: member.Member.Value, OwnerType: owner}
+}
+
+func namedTypeTarget(ctx *pipeline.PipelineContext, nt *ast.NamedType, currentPkg string) *referenceTarget {
+	name := nt.Name.Value
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		typeName := name[idx+1:]
+		return &referenceTarget{Kind: targetGlobal, Name: typeName, Package: aliasOrigin(ctx, name[:idx], typeName)}
+	}
+	sym, ok := ctx.SymbolTable.Find(name)
+	if !ok {
+		// Type parameters and unknown types are not renameable
+		return nil
+	}
+	return globalTarget(ctx, name, symbolPackage(sym, currentPkg))
+}
+
+// isGlobalSymbol reports whether sym refers to the module-level binding of its name
+// (as opposed to a local that shadows it).
+func isGlobalSymbol(ctx *pipeline.PipelineContext, sym symbols.Symbol) bool {
+	if ctx.SymbolTable == nil {
+		return false
+	}
+	global, ok := ctx.SymbolTable.Find(sym.Name)
+	if !ok {
+		return false
+	}
+	if sym.DefinitionNode != nil {
+		return global.DefinitionNode == sym.DefinitionNode
+	}
+	return global.OriginModule == sym.OriginModule
+}
+
+func symbolPackage(sym symbols.Symbol, currentPkg string) string {
+	if sym.OriginModule != "" {
+		return sym.OriginModule
+	}
+	return currentPkg
+}
+
+func isDeclarationIdent(ident *ast.Identifier, parent ast.Node, target *referenceTarget) bool {
+	if target.Kind == targetLocal && target.DefinitionNode == ident {
+		return true
+	}
+	switch p := parent.(type) {
+	case *ast.FunctionStatement:
+		return p.Name == ident
+	case *ast.TypeDeclarationStatement:
+		return p.Name == ident
+	case *ast.DataConstructor:
+		return p.Name == ident
+	case *ast.TraitDeclaration:
+		return p.Name == ident
+	case *ast.ConstantDeclaration:
+		return p.Name == ident
+	case *ast.AssignExpression:
+		return p.Left == ident && target.Kind == targetGlobal
+	}
+	return false
+}
+
+// programPackage returns the package name declared by a program ("" for scripts).
+func programPackage(root ast.Node) string {
+	prog, ok := root.(*ast.Program)
+	if !ok {
+		return ""
+	}
+	return extractPackageName(prog)
+}
+
+// moduleAliasOf reports whether ident refers to an imported module and returns its alias.
+func moduleAliasOf(ctx *pipeline.PipelineContext, ident *ast.Identifier) (string, bool) {
+	if sym, ok := ctx.ResolutionMap[ident]; ok {
+		return ident.Value, sym.Kind == symbols.ModuleSymbol
+	}
+	if ctx.SymbolTable == nil {
+		return "", false
+	}
+	sym, ok := ctx.SymbolTable.Find(ident.Value)
+	return ident.Value, ok && sym.Kind == symbols.ModuleSymbol
+}
+
+// aliasOrigin returns the package where name, accessed through a module alias, is originally defined.
+// Re-exported symbols keep pointing at their defining package.
+func aliasOrigin(ctx *pipeline.PipelineContext, alias, name string) string {
+	pkg := alias
+	if ctx.SymbolTable != nil {
+		if p, ok := ctx.SymbolTable.GetPackageNameByAlias(alias); ok {
+			pkg = p
+		}
+	}
+	if loader, ok := ctx.Loader.(*lspModuleLoader); ok {
+		if mod, ok := loader.GetModuleByPackageName(pkg).(*modules.Module); ok && mod != nil {
+			return exportOrigin(mod, name)
+		}
+	}
+	return pkg
+}
+
+// exportOrigin returns the defining package of an exported symbol of mod.
+func exportOrigin(mod *modules.Module, name string) string {
+	if sym, ok := mod.GetExports()[name]; ok && sym.OriginModule != "" {
+		return sym.OriginModule
+	}
+	return mod.Name
+}
+
+// importedModule returns the module referenced by an import statement of file.
+func importedModule(ctx *pipeline.PipelineContext, file string, imp *ast.ImportStatement) *modules.Module {
+	loader, ok := ctx.Loader.(*lspModuleLoader)
+	if !ok || imp.Path == nil {
+		return nil
+	}
+	modInterface, err := loader.GetModule(utils.ResolveImportPath(filepath.Dir(file), imp.Path.Value))
+	if err != nil {
+		return nil
+	}
+	mod, _ := modInterface.(*modules.Module)
+	return mod
+}
+
+// nominalTypeName returns the name of the nominal type constructor of t, if any.
+func nominalTypeName(t typesystem.Type) string {
+	switch typ := t.(type) {
+	case typesystem.TCon:
+		return typ.Name
+	case typesystem.TApp:
+		return nominalTypeName(typ.Constructor)
+	case typesystem.TForall:
+		return nominalTypeName(typ.Type)
+	}
+	return ""
+}
+
+func receiverTypeName(receiver *ast.Parameter) string {
+	if receiver == nil {
+		return ""
+	}
+	if nt, ok := receiver.Type.(*ast.NamedType); ok && nt.Name != nil {
+		return lastSegment(nt.Name.Value)
+	}
+	return ""
+}
+
+// recordOwnerType returns the nominal type a record node belongs to.
+// Record literals are typed structurally, so their owner comes from an enclosing annotation.
+func recordOwnerType(ctx *pipeline.PipelineContext, node, parent ast.Node) string {
+	switch n := node.(type) {
+	case *ast.RecordType:
+		if decl, ok := parent.(*ast.TypeDeclarationStatement); ok && decl.TargetType == n {
+			return decl.Name.Value
+		}
+	case *ast.RecordPattern:
+		if n.TypeName != "" {
+			return n.TypeName
+		}
+	case *ast.RecordLiteral:
+		var annotation ast.Type
+		switch p := parent.(type) {
+		case *ast.AssignExpression:
+			annotation = p.AnnotatedType
+		case *ast.ConstantDeclaration:
+			annotation = p.TypeAnnotation
+		case *ast.AnnotatedExpression:
+			annotation = p.TypeAnnotation
+		}
+		if nt, ok := annotation.(*ast.NamedType); ok && nt.Name != nil {
+			return lastSegment(nt.Name.Value)
+		}
+	}
+	return nominalTypeName(ctx.TypeMap[node])
+}
+
+func lastSegment(name string) string {
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		return name[idx+1:]
+	}
+	return name
+}
+
+// identOccurrence returns the occurrence of the last segment of an identifier.
+// Qualified names (e.g. "m.Point" in type positions) only cover the final name.
+func identOccurrence(file string, ident *ast.Identifier) symbolOccurrence {
+	name := ident.Value
+	col := ident.Token.Column
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		col += idx + 1
+		name = name[idx+1:]
+	}
+	return symbolOccurrence{File: file, Line: ident.Token.Line, Column: col, Length: len(name)}
+}
+
+// lexTokens returns all tokens of content (excluding EOF).
+func lexTokens(content string) []token.Token {
+	l := lexer.New(content)
+	var tokens []token.Token
+	for {
+		tok := l.NextToken()
+		if tok.Type == token.EOF {
+			break
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+// recordKeyTokens locates the field name tokens of a record literal, type or pattern.
+// Field keys are stored in maps on the AST, so their positions are recovered from the token stream.
+func recordKeyTokens(tokens []token.Token, node ast.Node) map[string]token.Token {
+	keys := make(map[string]token.Token)
+	tp, ok := node.(ast.TokenProvider)
+	if !ok {
+		return keys
+	}
+	start := tp.GetToken()
+
+	i := 0
+	for i < len(tokens) && (tokens[i].Line < start.Line || (tokens[i].Line == start.Line && tokens[i].Column < start.Column)) {
+		i++
+	}
+	// Record patterns may start at the type name: skip to the opening brace
+	for i < len(tokens) && tokens[i].Type != token.LBRACE {
+		if tokens[i].Line != start.Line && tokens[i].Type != token.NEWLINE {
+			return keys
+		}
+		i++
+	}
+	if i >= len(tokens) {
+		return keys
+	}
+
+	depth := 0
+	expectKey := false
+	for ; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch tok.Type {
+		case token.LBRACE, token.LPAREN, token.LBRACKET, token.PERCENT_LBRACE:
+			depth++
+			expectKey = depth == 1
+			continue
+		case token.RBRACE, token.RPAREN, token.RBRACKET:
+			depth--
+			if depth == 0 {
+				return keys
+			}
+			continue
+		case token.COMMA:
+			expectKey = depth == 1
+			continue
+		case token.NEWLINE:
+			continue
+		}
+
+		if expectKey && tok.Type == token.IDENT_LOWER {
+			if _, exists := keys[tok.Lexeme]; !exists {
+				keys[tok.Lexeme] = tok
+			}
+		}
+		expectKey = false
+	}
+	return keys
+}
+
+// collectOccurrences finds all occurrences of target in the analyzed units.
+func collectOccurrences(units []*analyzedUnit, target *referenceTarget, contentOf func(string) (string, bool)) []symbolOccurrence {
+	var result []symbolOccurrence
+	seen := make(map[symbolOccurrence]bool)
+	add := func(occ symbolOccurrence) {
+		key := occ
+		key.IsDeclaration = false
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		result = append(result, occ)
+	}
+
+	for _, unit := range units {
+		for _, file := range unit.Files {
+			if target.File != "" && filepath.Clean(file.File) != filepath.Clean(target.File) {
+				continue
+			}
+			c := &occurrenceCollector{
+				unit:      unit,
+				file:      file,
+				target:    target,
+				add:       add,
+				contentOf: contentOf,
+			}
+			c.collect()
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].File != result[j].File {
+			return result[i].File < result[j].File
+		}
+		if result[i].Line != result[j].Line {
+			return result[i].Line < result[j].Line
+		}
+		return result[i].Column < result[j].Column
+	})
+	return result
+}
+
+// occurrenceCollector walks a single file of an analyzed unit looking for a target.
+type occurrenceCollector struct {
+	unit      *analyzedUnit
+	file      *ast.Program
+	target    *referenceTarget
+	add       func(symbolOccurrence)
+	contentOf func(string) (string, bool)
+
+	pkg     string
+	handled map[*ast.Identifier]bool
+	parents map[ast.Node]ast.Node
+	tokens  []token.Token
+	lexed   bool
+}
+
+func (c *occurrenceCollector) ctx() *pipeline.PipelineContext {
+	return c.unit.Ctx
+}
+
+func (c *occurrenceCollector) collect() {
+	c.pkg = extractPackageName(c.file)
+	c.handled = make(map[*ast.Identifier]bool)
+	c.parents = make(map[ast.Node]ast.Node)
+
+	for _, stmt := range c.file.Statements {
+		c.visitTopLevel(stmt)
+	}
+
+	ast.Inspect(c.file, func(node ast.Node) bool {
+		ast.Children(node, func(child ast.Node) {
+			c.parents[child] = node
+		})
+		switch n := node.(type) {
+		case *ast.ImportStatement:
+			c.visitImport(n)
+			return false
+		case *ast.PackageDeclaration:
+			c.visitPackage(n)
+			return false
+		case *ast.MemberExpression:
+			c.visitMember(n)
+		case *ast.NamedType:
+			c.visitNamedType(n)
+		case *ast.FunctionStatement:
+			c.visitFunctionName(n)
+		case *ast.Identifier:
+			c.visitIdentifier(n)
+		case *ast.IdentifierPattern:
+			c.visitIdentifierPattern(n)
+		case *ast.RecordType, *ast.RecordLiteral, *ast.RecordPattern:
+			c.visitRecord(n)
+		}
+		return true
+	})
+}
+
+func (c *occurrenceCollector) emit(ident *ast.Identifier, isDecl bool) {
+	c.handled[ident] = true
+	occ := identOccurrence(c.file.File, ident)
+	occ.IsDeclaration = isDecl
+	c.add(occ)
+}
+
+// visitTopLevel marks declaration names of top-level statements.
+func (c *occurrenceCollector) visitTopLevel(stmt ast.Statement) {
+	if c.target.Kind != targetGlobal || c.pkg != c.target.Package {
+		return
+	}
+	match := func(ident *ast.Identifier) {
+		if ident != nil && ident.Value == c.target.Name {
+			c.emit(ident, true)
+		}
+	}
+	switch s := stmt.(type) {
+	case *ast.FunctionStatement:
+		if s.Receiver == nil {
+			match(s.Name)
+		}
+	case *ast.TypeDeclarationStatement:
+		match(s.Name)
+		for _, ctor := range s.Constructors {
+			match(ctor.Name)
+		}
+	case *ast.TraitDeclaration:
+		match(s.Name)
+		for _, sig := range s.Signatures {
+			match(sig.Name)
+		}
+	case *ast.ConstantDeclaration:
+		match(s.Name)
+	case *ast.ExpressionStatement:
+		if assign, ok := s.Expression.(*ast.AssignExpression); ok {
+			if ident, ok := assign.Left.(*ast.Identifier); ok && ident.Value == c.target.Name {
+				if sym, ok := c.ctx().ResolutionMap[ident]; !ok || isGlobalSymbol(c.ctx(), sym) {
+					c.emit(ident, true)
+				}
+			}
+		}
+	case *ast.InstanceDeclaration:
+		// Instance methods implement trait methods declared elsewhere
+		if s.TraitName == nil {
+			return
+		}
+		for _, method := range s.Methods {
+			if method.Name != nil && method.Name.Value == c.target.Name {
+				if t := traitMethodTarget(c.ctx(), s, method.Name.Value); t != nil && t.Package == c.target.Package {
+					c.emit(method.Name, false)
+				}
+			}
+		}
+	}
+}
+
+func (c *occurrenceCollector) visitImport(imp *ast.ImportStatement) {
+	if c.target.Kind != targetGlobal {
+		return
+	}
+	var mod *modules.Module
+	for _, list := range [][]*ast.Identifier{imp.Symbols, imp.Exclude} {
+		for _, sym := range list {
+			if sym.Value != c.target.Name {
+				continue
+			}
+			if mod == nil {
+				mod = importedModule(c.ctx(), c.file.File, imp)
+			}
+			if mod != nil && exportOrigin(mod, sym.Value) == c.target.Package {
+				c.emit(sym, false)
+			}
+		}
+	}
+}
+
+func (c *occurrenceCollector) visitPackage(pkg *ast.PackageDeclaration) {
+	if c.target.Kind != targetGlobal {
+		return
+	}
+	for _, exp := range pkg.Exports {
+		if exp.Symbol != nil && exp.Symbol.Value == c.target.Name && c.pkg == c.target.Package {
+			c.emit(exp.Symbol, false)
+		}
+		if exp.ModuleName == nil {
+			continue
+		}
+		for _, sym := range exp.Symbols {
+			if sym.Value == c.target.Name && aliasOrigin(c.ctx(), exp.ModuleName.Value, sym.Value) == c.target.Package {
+				c.emit(sym, false)
+			}
+		}
+	}
+}
+
+func (c *occurrenceCollector) visitMember(member *ast.MemberExpression) {
+	if member.Member == nil || member.Member.Value != c.target.Name {
+		return
+	}
+	if left, ok := member.Left.(*ast.Identifier); ok {
+		if alias, isModule := moduleAliasOf(c.ctx(), left); isModule {
+			if c.target.Kind == targetGlobal && aliasOrigin(c.ctx(), alias, member.Member.Value) == c.target.Package {
+				c.emit(member.Member, false)
+			}
+			c.handled[member.Member] = true
+			return
+		}
+	}
+	if c.target.Kind == targetMember && nominalTypeName(c.ctx().TypeMap[member.Left]) == c.target.OwnerType {
+		c.emit(member.Member, false)
+	}
+	// Field accesses are never plain variable references
+	c.handled[member.Member] = true
+}
+
+func (c *occurrenceCollector) visitNamedType(nt *ast.NamedType) {
+	if nt.Name == nil {
+		return
+	}
+	c.handled[nt.Name] = true
+	if c.target.Kind != targetGlobal {
+		return
+	}
+	name := nt.Name.Value
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		typeName := name[idx+1:]
+		if typeName == c.target.Name && aliasOrigin(c.ctx(), name[:idx], typeName) == c.target.Package {
+			c.emit(nt.Name, false)
+		}
+		return
+	}
+	if name != c.target.Name {
+		return
+	}
+	if sym, ok := c.ctx().SymbolTable.Find(name); ok && symbolPackage(sym, c.pkg) == c.target.Package {
+		c.emit(nt.Name, false)
+	}
+}
+
+func (c *occurrenceCollector) visitFunctionName(fn *ast.FunctionStatement) {
+	if fn.Name == nil || c.handled[fn.Name] {
+		return
+	}
+	if fn.Receiver != nil {
+		c.handled[fn.Name] = true
+		if c.target.Kind == targetMember && fn.Name.Value == c.target.Name && receiverTypeName(fn.Receiver) == c.target.OwnerType {
+			c.emit(fn.Name, true)
+		}
+		return
+	}
+	// Top-level, trait and instance method names were handled in visitTopLevel.
+	// Remaining function names are nested functions: only locals can match them.
+	if c.target.Kind == targetLocal && c.target.DefinitionNode == fn.Name {
+		c.emit(fn.Name, true)
+	}
+	c.handled[fn.Name] = true
+}
+
+func (c *occurrenceCollector) visitIdentifier(ident *ast.Identifier) {
+	if c.handled[ident] || ident.Value != c.target.Name {
+		return
+	}
+	ctx := c.ctx()
+	sym, resolved := ctx.ResolutionMap[ident]
+
+	switch c.target.Kind {
+	case targetLocal:
+		if ident == c.target.DefinitionNode {
+			c.emit(ident, true)
+		} else if resolved && sym.DefinitionNode != nil && sym.DefinitionNode == c.target.DefinitionNode {
+			c.emit(ident, false)
+		}
+	case targetGlobal:
+		if !resolved {
+			var ok bool
+			sym, ok = ctx.SymbolTable.Find(ident.Value)
+			if !ok || !isUnresolvedGlobalReference(sym) {
+				return
+			}
+		}
+		if sym.Kind == symbols.ModuleSymbol || !isGlobalSymbol(ctx, sym) {
+			return
+		}
+		if symbolPackage(sym, c.pkg) == c.target.Package {
+			c.emit(ident, false)
+		}
+	}
+}
+
+// isUnresolvedGlobalReference limits name-based fallback to symbols that are not
+// recorded in the resolution map by the analyzer (constructors in patterns, traits).
+func isUnresolvedGlobalReference(sym symbols.Symbol) bool {
+	return sym.Kind == symbols.ConstructorSymbol || sym.Kind == symbols.TraitSymbol || sym.Kind == symbols.TypeSymbol
+}
+
+func (c *occurrenceCollector) visitIdentifierPattern(p *ast.IdentifierPattern) {
+	if c.target.Kind != targetLocal || p.Value != c.target.Name {
+		return
+	}
+	sym, ok := c.ctx().ResolutionMap[p]
+	if ast.Node(p) == c.target.DefinitionNode || (ok && sym.DefinitionNode == c.target.DefinitionNode) {
+		c.add(symbolOccurrence{File: c.file.File, Line: p.Token.Line, Column: p.Token.Column, Length: len(p.Value), IsDeclaration: true})
+	}
+}
+
+func (c *occurrenceCollector) visitRecord(node ast.Node) {
+	if c.target.Kind != targetMember {
+		return
+	}
+	if recordOwnerType(c.ctx(), node, c.parents[node]) != c.target.OwnerType {
+		return
+	}
+	if !c.lexed {
+		c.lexed = true
+		if content, ok := c.contentOf(c.file.File); ok {
+			c.tokens = lexTokens(content)
+		}
+	}
+	if tok, ok := recordKeyTokens(c.tokens, node)[c.target.Name]; ok {
+		_, isDecl := node.(*ast.RecordType)
+		c.add(symbolOccurrence{File: c.file.File, Line: tok.Line, Column: tok.Column, Length: len(c.target.Name), IsDeclaration: isDecl})
+	}
+}
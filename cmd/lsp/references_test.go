@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/funvibe/funxy/internal/ast"
+)
+
+// writeWorkspace creates files under a temporary root and returns the root path.
+func writeWorkspace(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	return root
+}
+
+// openWorkspaceFile opens a workspace file in a server rooted at root.
+func openWorkspaceFile(t *testing.T, root, name string) (*LanguageServer, *bytes.Buffer, string) {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	server := NewLanguageServer(buf)
+	server.rootPath = root
+
+	path := filepath.Join(root, name)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	uri := "file://" + path
+	if err := server.handleDidOpen(DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "funxy", Version: 1, Text: string(content)},
+	}); err != nil {
+		t.Fatalf("handleDidOpen failed: %v", err)
+	}
+	buf.Reset()
+	return server, buf, uri
+}
+
+// positionOf returns the position of the n-th (0-based) occurrence of needle in code.
+func positionOf(t *testing.T, code, needle string, n int) Position {
+	t.Helper()
+	offset := -1
+	for i := 0; i <= n; i++ {
+		idx := strings.Index(code[offset+1:], needle)
+		if idx == -1 {
+			t.Fatalf("occurrence %d of %q not found", n, needle)
+		}
+		offset += idx + 1
+	}
+	line := strings.Count(code[:offset], "\n")
+	col := offset - (strings.LastIndex(code[:offset], "\n") + 1)
+	return Position{Line: line, Character: col}
+}
+
+type lspResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+func decodeResponse(t *testing.T, buf *bytes.Buffer) lspResponse {
+	t.Helper()
+	var resp lspResponse
+	if err := json.Unmarshal([]byte(parseLSPOutput(t, buf.String())), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	buf.Reset()
+	return resp
+}
+
+// editSummary renders workspace edits as sorted "file:line:col" strings.
+func editSummary(root string, edit WorkspaceEdit) []string {
+	var result []string
+	for uri, edits := range edit.Changes {
+		rel, _ := filepath.Rel(root, strings.TrimPrefix(uri, "file://"))
+		for _, e := range edits {
+			result = append(result, rel+":"+strconv.Itoa(e.Range.Start.Line)+":"+strconv.Itoa(e.Range.Start.Character))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestReferences_LocalVariable(t *testing.T) {
+	uri := "file:///refs.funxy"
+	code := "fun f(x: Int) -> Int {\n" +
+		"    y = x + 1\n" +
+		"    y = y * 2\n" +
+		"    y\n" +
+		"}\n" +
+		"y = 10\n"
+	server, buf := setupServer(t, uri, code)
+
+	if err := server.handleReferences(1, ReferenceParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, code, "y", 0),
+		Context:      ReferenceContext{IncludeDeclaration: true},
+	}); err != nil {
+		t.Fatalf("handleReferences failed: %v", err)
+	}
+
+	var locations []Location
+	if err := json.Unmarshal(decodeResponse(t, buf).Result, &locations); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	// Declaration, reassignment, use in reassignment and result; not the top-level y
+	if len(locations) != 4 {
+		t.Fatalf("expected 4 references, got %d: %+v", len(locations), locations)
+	}
+	for _, loc := range locations {
+		if loc.Range.Start.Line == 5 {
+			t.Errorf("shadowed top-level 'y' must not be included: %+v", loc)
+		}
+	}
+}
+
+func TestReferences_DoesNotMutateCachedProgram(t *testing.T) {
+	uri := "file:///refs.funxy"
+	code := "x = 1\nprint(x)\n"
+	server, buf := setupServer(t, uri, code)
+
+	docCtx, _ := server.documentContext(uri)
+	prog, ok := docCtx.AstRoot.(*ast.Program)
+	if !ok {
+		t.Fatalf("expected cached program, got %T", docCtx.AstRoot)
+	}
+	prog.File = ""
+
+	if err := server.handleReferences(1, ReferenceParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, code, "x", 0),
+		Context:      ReferenceContext{IncludeDeclaration: true},
+	}); err != nil {
+		t.Fatalf("handleReferences failed: %v", err)
+	}
+
+	var locations []Location
+	if err := json.Unmarshal(decodeResponse(t, buf).Result, &locations); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(locations) != 2 {
+		t.Fatalf("expected 2 references, got %d: %+v", len(locations), locations)
+	}
+	// Requests run concurrently, so the shared AST must not be written to
+	if prog.File != "" {
+		t.Errorf("cached program was mutated: File = %q", prog.File)
+	}
+}
+
+func TestReferences_ExcludeDeclaration(t *testing.T) {
+	uri := "file:///refs.funxy"
+	code := "fun double(n: Int) -> Int { n * 2 }\n" +
+		"a = double(1)\n" +
+		"b = double(a)\n"
+	server, buf := setupServer(t, uri, code)
+
+	if err := server.handleReferences(1, ReferenceParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, code, "double", 1),
+		Context:      ReferenceContext{IncludeDeclaration: false},
+	}); err != nil {
+		t.Fatalf("handleReferences failed: %v", err)
+	}
+
+	var locations []Location
+	if err := json.Unmarshal(decodeResponse(t, buf).Result, &locations); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(locations) != 2 {
+		t.Fatalf("expected 2 references, got %d: %+v", len(locations), locations)
+	}
+}
+
+func TestRename_AcrossPackageGroup(t *testing.T) {
+	root := writeWorkspace(t, map[string]string{
+		"geo/geo.lang": "package geo (area, Point)\n" +
+			"type alias Point = { x: Int, y: Int }\n" +
+			"fun area(w: Int, h: Int) -> Int { w * h }\n",
+		"geo/util.lang": "package geo\n" +
+			"fun square(s: Int) -> Int { area(s, s) }\n",
+		"facade/facade.lang": "package facade (geo(area))\n" +
+			"import \"../geo\" (area)\n",
+		"main.lang": "import \"./geo\" (area)\n" +
+			"a = area(2, 3)\n",
+		"qualified.lang": "import \"./geo\" as g\n" +
+			"b = g.area(4, 5)\n",
+		"other.lang": "fun area(x: Int) -> Int { x }\n" +
+			"c = area(1)\n",
+	})
+	server, buf, uri := openWorkspaceFile(t, root, "main.lang")
+	code, _ := os.ReadFile(filepath.Join(root, "main.lang"))
+
+	if err := server.handleRename(1, RenameParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, string(code), "area", 1),
+		NewName:      "surface",
+	}); err != nil {
+		t.Fatalf("handleRename failed: %v", err)
+	}
+
+	resp := decodeResponse(t, buf)
+	if resp.Error != nil {
+		t.Fatalf("rename failed: %s", resp.Error.Message)
+	}
+	var edit WorkspaceEdit
+	if err := json.Unmarshal(resp.Result, &edit); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	got := editSummary(root, edit)
+	want := []string{
+		"facade/facade.lang:0:20",
+		"facade/facade.lang:1:17",
+		"geo/geo.lang:0:13",
+		"geo/geo.lang:2:4",
+		"geo/util.lang:1:28",
+		"main.lang:0:16",
+		"main.lang:1:4",
+		"qualified.lang:1:6",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("unexpected edits:\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestRename_RecordField(t *testing.T) {
+	uri := "file:///fields.funxy"
+	code := "type alias Point = { x: Int, y: Int }\n" +
+		"p: Point = { x: 1, y: 2 }\n" +
+		"q = p.x + p.y\n"
+	server, buf := setupServer(t, uri, code)
+
+	if err := server.handleRename(1, RenameParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, code, "x", 2),
+		NewName:      "left",
+	}); err != nil {
+		t.Fatalf("handleRename failed: %v", err)
+	}
+
+	resp := decodeResponse(t, buf)
+	if resp.Error != nil {
+		t.Fatalf("rename failed: %s", resp.Error.Message)
+	}
+	var edit WorkspaceEdit
+	if err := json.Unmarshal(resp.Result, &edit); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	edits := edit.Changes[uri]
+	if len(edits) != 3 {
+		t.Fatalf("expected 3 edits, got %d: %+v", len(edits), edits)
+	}
+}
+
+// Derived instances are generated from the deriving clause and must not be
+// renamed as if the clause mentioned the type.
+func TestRename_TypeWithDerivingClause(t *testing.T) {
+	uri := "file:///deriving.funxy"
+	code := "type Shape = Circle(Int) | Blank deriving (Equal, Show)\n" +
+		"fun same(a: Shape, b: Shape) -> Bool { a == b }\n"
+	server, buf := setupServer(t, uri, code)
+
+	if err := server.handleRename(1, RenameParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, code, "Shape", 1),
+		NewName:      "Figure",
+	}); err != nil {
+		t.Fatalf("handleRename failed: %v", err)
+	}
+
+	resp := decodeResponse(t, buf)
+	if resp.Error != nil {
+		t.Fatalf("rename failed: %s", resp.Error.Message)
+	}
+	var edit WorkspaceEdit
+	if err := json.Unmarshal(resp.Result, &edit); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	got := editSummary("/", edit)
+	want := []string{"deriving.funxy:0:5", "deriving.funxy:1:12", "deriving.funxy:1:22"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("unexpected edits:\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestRename_InvalidNames(t *testing.T) {
+	uri := "file:///rename.funxy"
+	code := "fun double(n: Int) -> Int { n * 2 }\n" +
+		"a = double(1)\n" +
+		"b = print(a)\n"
+	server, buf := setupServer(t, uri, code)
+
+	tests := []struct {
+		name    string
+		pos     Position
+		newName string
+	}{
+		{"keyword", positionOf(t, code, "double", 0), "match"},
+		{"not an identifier", positionOf(t, code, "double", 0), "two words"},
+		{"case change", positionOf(t, code, "double", 0), "Double"},
+		{"builtin", positionOf(t, code, "print", 0), "show"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := server.handleRename(1, RenameParams{
+				TextDocument: TextDocumentIdentifier{URI: uri},
+				Position:     tt.pos,
+				NewName:      tt.newName,
+			}); err != nil {
+				t.Fatalf("handleRename failed: %v", err)
+			}
+			if resp := decodeResponse(t, buf); resp.Error == nil {
+				t.Errorf("expected rename to %q to fail, got %s", tt.newName, resp.Result)
+			}
+		})
+	}
+}
+
+func TestPrepareRename(t *testing.T) {
+	uri := "file:///prepare.funxy"
+	code := "fun double(n: Int) -> Int { n * 2 }\n" +
+		"a = double(1)\n"
+	server, buf := setupServer(t, uri, code)
+
+	if err := server.handlePrepareRename(1, PrepareRenameParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: 1, Character: 6},
+	}); err != nil {
+		t.Fatalf("handlePrepareRename failed: %v", err)
+	}
+
+	var result PrepareRenameResult
+	if err := json.Unmarshal(decodeResponse(t, buf).Result, &result); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if result.Placeholder != "double" {
+		t.Errorf("expected placeholder 'double', got %q", result.Placeholder)
+	}
+	want := Range{Start: Position{Line: 1, Character: 4}, End: Position{Line: 1, Character: 10}}
+	if result.Range != want {
+		t.Errorf("expected range %+v, got %+v", want, result.Range)
+	}
+}
@@ -0,0 +1,607 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/modules"
+	"github.com/funvibe/funxy/internal/pipeline"
+	"github.com/funvibe/funxy/internal/symbols"
+	"github.com/funvibe/funxy/internal/token"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// Semantic token types, in legend order
+const (
+	semNamespace = iota
+	semType
+	semEnum
+	semInterface
+	semStruct
+	semTypeParameter
+	semParameter
+	semVariable
+	semProperty
+	semEnumMember
+	semFunction
+	semMethod
+	semKeyword
+	semString
+	semNumber
+)
+
+// Semantic token modifiers, as bit flags in legend order
+const (
+	modDeclaration = 1 << iota
+	modReadonly
+	modDefaultLibrary
+	modFormat
+	modBinary
+)
+
+var semanticTokenTypes = []string{
+	"namespace", "type", "enum", "interface", "struct", "typeParameter",
+	"parameter", "variable", "property", "enumMember", "function", "method",
+	"keyword", "string", "number",
+}
+
+// "format" and "binary" are custom modifiers for format strings and bits/bytes literals
+var semanticTokenModifiers = []string{
+	"declaration", "readonly", "defaultLibrary", "format", "binary",
+}
+
+func semanticTokensLegend() SemanticTokensLegend {
+	return SemanticTokensLegend{TokenTypes: semanticTokenTypes, TokenModifiers: semanticTokenModifiers}
+}
+
+type semanticClass struct {
+	Type      int
+	Modifiers int
+}
+
+// semanticToken is a single highlighted span on one line (0-based, rune columns).
+type semanticToken struct {
+	Line      int
+	Char      int
+	Length    int
+	Type      int
+	Modifiers int
+}
+
+type tokenPos struct {
+	Line   int
+	Column int
+}
+
+// sourceText maps between lexer positions (1-based line, 1-based rune column)
+// and byte offsets.
+type sourceText struct {
+	content    string
+	lineStarts []int
+}
+
+func newSourceText(content string) *sourceText {
+	starts := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &sourceText{content: content, lineStarts: starts}
+}
+
+func (s *sourceText) offset(line, col int) int {
+	if line < 1 {
+		return 0
+	}
+	if line > len(s.lineStarts) {
+		return len(s.content)
+	}
+	off := s.lineStarts[line-1]
+	for i := 1; i < col && off < len(s.content) && s.content[off] != '\n'; i++ {
+		_, size := utf8.DecodeRuneInString(s.content[off:])
+		off += size
+	}
+	return off
+}
+
+// position returns the 0-based line and rune column of a byte offset.
+func (s *sourceText) position(offset int) (int, int) {
+	line := sort.Search(len(s.lineStarts), func(i int) bool { return s.lineStarts[i] > offset }) - 1
+	return line, utf8.RuneCountInString(s.content[s.lineStarts[line]:offset])
+}
+
+// buildSemanticTokens lexes content and classifies every token, using the
+// analyzer results in ctx (which may be nil) to tell identifiers apart.
+// Lexing stops early once reqCtx is cancelled.
+func buildSemanticTokens(reqCtx context.Context, ctx *pipeline.PipelineContext, content string) []semanticToken {
+	b := &semanticBuilder{reqCtx: reqCtx, ctx: ctx, src: newSourceText(content)}
+	if ctx != nil && ctx.AstRoot != nil {
+		b.classes = classifyIdentifiers(ctx, content)
+	}
+	b.lexSpans(0, false)
+	sort.SliceStable(b.tokens, func(i, j int) bool {
+		if b.tokens[i].Line != b.tokens[j].Line {
+			return b.tokens[i].Line < b.tokens[j].Line
+		}
+		return b.tokens[i].Char < b.tokens[j].Char
+	})
+	return b.tokens
+}
+
+type semanticBuilder struct {
+	reqCtx  context.Context
+	ctx     *pipeline.PipelineContext
+	src     *sourceText
+	classes map[tokenPos]semanticClass
+	tokens  []semanticToken
+}
+
+// lexSpans lexes the source from the given offset. Inside an interpolation
+// (untilBrace) it stops at the unbalanced closing brace and returns its offset.
+func (b *semanticBuilder) lexSpans(from int, untilBrace bool) int {
+	sub := b.src.content[from:]
+	local := b.src
+	if from > 0 {
+		local = newSourceText(sub)
+	}
+	l := lexer.New(sub)
+	depth := 0
+	for {
+		tok := l.NextToken()
+		if tok.Type == token.EOF || b.reqCtx.Err() != nil {
+			return len(b.src.content)
+		}
+		start := from + local.offset(tok.Line, tok.Column)
+		end := from + l.Offset()
+		if untilBrace {
+			switch tok.Type {
+			case token.LBRACE, token.PERCENT_LBRACE:
+				depth++
+			case token.RBRACE:
+				if depth == 0 {
+					return start
+				}
+				depth--
+			}
+		}
+		b.addToken(tok, start, end, !untilBrace)
+	}
+}
+
+// addToken classifies one lexer token. Identifier positions are only
+// meaningful outside interpolations, where they match the AST.
+func (b *semanticBuilder) addToken(tok token.Token, start, end int, byPosition bool) {
+	switch tok.Type {
+	case token.IDENT_LOWER, token.IDENT_UPPER:
+		if byPosition {
+			if class, ok := b.classes[tokenPos{tok.Line, tok.Column}]; ok {
+				b.addSpan(start, end, class)
+				return
+			}
+		}
+		if class, ok := b.classifyName(tok.Lexeme, !byPosition); ok {
+			b.addSpan(start, end, class)
+		}
+	case token.INT, token.FLOAT, token.BIG_INT, token.RATIONAL:
+		b.addSpan(start, end, semanticClass{Type: semNumber})
+	case token.BITS_BIN, token.BITS_HEX, token.BITS_OCT, token.BYTES_HEX, token.BYTES_BIN:
+		b.addSpan(start, end, semanticClass{Type: semNumber, Modifiers: modBinary})
+	case token.BYTES_STRING:
+		b.addSpan(start, end, semanticClass{Type: semString, Modifiers: modBinary})
+	case token.STRING, token.CHAR:
+		b.addSpan(start, end, semanticClass{Type: semString})
+	case token.FORMAT_STRING:
+		b.addSpan(start, end, semanticClass{Type: semString, Modifiers: modFormat})
+	case token.INTERP_STRING:
+		b.addInterpolatedString(start, end)
+	case token.DIRECTIVE:
+		b.addSpan(start, end, semanticClass{Type: semKeyword})
+	default:
+		if _, isKeyword := token.Keywords[tok.Lexeme]; isKeyword && tok.Type != token.IDENT_LOWER {
+			b.addSpan(start, end, semanticClass{Type: semKeyword})
+		}
+	}
+}
+
+// addInterpolatedString emits the literal parts of "...${expr}..." as strings
+// and lexes each embedded expression in place.
+func (b *semanticBuilder) addInterpolatedString(start, end int) {
+	content := b.src.content
+	pieceStart := start
+	for i := start + 1; i < end-1; {
+		switch {
+		case content[i] == '\\':
+			i += 2
+		case content[i] == '$' && content[i+1] == '{':
+			b.addSpan(pieceStart, i, semanticClass{Type: semString})
+			closing := b.lexSpans(i+2, true)
+			if closing >= end {
+				return
+			}
+			i = closing + 1
+			pieceStart = i
+		default:
+			i++
+		}
+	}
+	b.addSpan(pieceStart, end, semanticClass{Type: semString})
+}
+
+// addSpan emits a token for content[start:end], split into one token per line.
+func (b *semanticBuilder) addSpan(start, end int, class semanticClass) {
+	for start < end {
+		lineEnd := strings.IndexByte(b.src.content[start:end], '\n')
+		segEnd := end
+		if lineEnd != -1 {
+			segEnd = start + lineEnd
+		}
+		text := strings.TrimSuffix(b.src.content[start:segEnd], "\r")
+		if text != "" {
+			line, char := b.src.position(start)
+			b.tokens = append(b.tokens, semanticToken{
+				Line:      line,
+				Char:      char,
+				Length:    utf8.RuneCountInString(text),
+				Type:      class.Type,
+				Modifiers: class.Modifiers,
+			})
+		}
+		if lineEnd == -1 {
+			break
+		}
+		start = segEnd + 1
+	}
+}
+
+// classifyName classifies an identifier by name alone. Lowercase names that
+// are not globals are locals when they appear inside an interpolation.
+func (b *semanticBuilder) classifyName(name string, assumeLocal bool) (semanticClass, bool) {
+	if b.ctx != nil && b.ctx.SymbolTable != nil {
+		if sym, ok := b.ctx.SymbolTable.Find(name); ok {
+			return symbolClass(b.ctx, sym), true
+		}
+	}
+	if assumeLocal && !isUpperName(name) {
+		return semanticClass{Type: semVariable}, true
+	}
+	return semanticClass{}, false
+}
+
+// symbolClass maps an analyzer symbol to a token type and modifiers.
+func symbolClass(ctx *pipeline.PipelineContext, sym symbols.Symbol) semanticClass {
+	var class semanticClass
+	switch sym.Kind {
+	case symbols.ModuleSymbol:
+		class.Type = semNamespace
+	case symbols.ConstructorSymbol:
+		class.Type = semEnumMember
+	case symbols.TraitSymbol:
+		class.Type = semInterface
+	case symbols.TypeSymbol:
+		class.Type = typeClass(ctx, sym)
+	default:
+		if _, isType := ctx.SymbolTable.ResolveType(sym.Name); isType && isUpperName(sym.Name) {
+			// Built-in types are also bound as values
+			class.Type = semType
+			break
+		}
+		if _, isFunc := asFunc(sym.Type); isFunc {
+			class.Type = semFunction
+			if sym.IsTraitMethod {
+				class.Type = semMethod
+			} else if _, ok := ctx.SymbolTable.GetTraitForMethod(sym.Name); ok && isGlobalSymbol(ctx, sym) {
+				class.Type = semMethod
+			}
+		} else {
+			class.Type = semVariable
+			if sym.IsConstant {
+				class.Modifiers |= modReadonly
+			}
+		}
+	}
+	if isLibraryOrigin(ctx, sym.OriginModule) {
+		class.Modifiers |= modDefaultLibrary
+	}
+	return class
+}
+
+// isLibraryOrigin reports whether symbols of a module come from the prelude
+// or a built-in lib/* package.
+func isLibraryOrigin(ctx *pipeline.PipelineContext, origin string) bool {
+	if origin == "" {
+		return false
+	}
+	if origin == "prelude" {
+		return true
+	}
+	mod := moduleByAlias(ctx, origin)
+	return mod != nil && mod.IsVirtual
+}
+
+// typeClass distinguishes ADTs (enum) and record aliases (struct) from other types.
+func typeClass(ctx *pipeline.PipelineContext, sym symbols.Symbol) int {
+	if _, ok := sym.UnderlyingType.(typesystem.TRecord); ok {
+		return semStruct
+	}
+	if variants, ok := ctx.SymbolTable.GetVariants(sym.Name); ok && len(variants) > 0 {
+		return semEnum
+	}
+	return semType
+}
+
+// classifyIdentifiers walks the analyzed AST and classifies identifiers by
+// their source position.
+func classifyIdentifiers(ctx *pipeline.PipelineContext, content string) map[tokenPos]semanticClass {
+	c := &identifierClassifier{
+		ctx:      ctx,
+		classes:  make(map[tokenPos]semanticClass),
+		handled:  make(map[*ast.Identifier]bool),
+		typeVars: make(map[string]bool),
+	}
+	var tokens []token.Token
+	lexed := false
+	keyTokens := func(node ast.Node) map[string]token.Token {
+		if !lexed {
+			lexed = true
+			tokens = lexTokens(content)
+		}
+		return recordKeyTokens(tokens, node)
+	}
+
+	ast.Inspect(ctx.AstRoot, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.PackageDeclaration:
+			c.set(n.Name, semanticClass{Type: semNamespace, Modifiers: modDeclaration})
+		case *ast.ImportStatement:
+			c.set(n.Alias, semanticClass{Type: semNamespace, Modifiers: modDeclaration})
+			mod := importedModule(ctx, ctx.FilePath, n)
+			for _, sym := range append(append([]*ast.Identifier{}, n.Symbols...), n.Exclude...) {
+				c.setExported(sym, mod)
+			}
+		case *ast.TypeDeclarationStatement:
+			class := semanticClass{Type: semType, Modifiers: modDeclaration}
+			if len(n.Constructors) > 0 {
+				class.Type = semEnum
+			} else if _, ok := n.TargetType.(*ast.RecordType); ok {
+				class.Type = semStruct
+			}
+			c.set(n.Name, class)
+			c.setTypeParams(n.TypeParameters)
+			for _, ctor := range n.Constructors {
+				c.set(ctor.Name, semanticClass{Type: semEnumMember, Modifiers: modDeclaration})
+			}
+		case *ast.TraitDeclaration:
+			c.set(n.Name, semanticClass{Type: semInterface, Modifiers: modDeclaration})
+			c.setTypeParams(n.TypeParams)
+			for _, sig := range n.Signatures {
+				c.set(sig.Name, semanticClass{Type: semMethod, Modifiers: modDeclaration})
+			}
+		case *ast.InstanceDeclaration:
+			c.set(n.ModuleName, semanticClass{Type: semNamespace})
+			c.set(n.TraitName, semanticClass{Type: semInterface})
+			c.setTypeParams(n.TypeParams)
+			for _, method := range n.Methods {
+				c.set(method.Name, semanticClass{Type: semMethod})
+			}
+		case *ast.FunctionStatement:
+			if n.Receiver != nil {
+				c.set(n.Name, semanticClass{Type: semMethod, Modifiers: modDeclaration})
+			} else {
+				c.set(n.Name, semanticClass{Type: semFunction, Modifiers: modDeclaration})
+			}
+			c.setTypeParams(n.TypeParams)
+			if n.Receiver != nil {
+				c.setParams([]*ast.Parameter{n.Receiver})
+				c.setReceiverUses(n)
+			}
+			c.setParams(n.Parameters)
+		case *ast.FunctionLiteral:
+			c.setParams(n.Parameters)
+		case *ast.ConstantDeclaration:
+			c.set(n.Name, semanticClass{Type: semVariable, Modifiers: modDeclaration | modReadonly})
+		case *ast.AssignExpression:
+			if ident, ok := n.Left.(*ast.Identifier); ok {
+				if sym, ok := ctx.ResolutionMap[ident]; !ok || sym.DefinitionNode == ast.Node(ident) {
+					c.set(ident, semanticClass{Type: semVariable, Modifiers: modDeclaration})
+				}
+			}
+		case *ast.NamedType:
+			c.visitNamedType(n)
+		case *ast.MemberExpression:
+			c.visitMember(n)
+		case *ast.ConstructorPattern:
+			c.set(n.Name, semanticClass{Type: semEnumMember})
+		case *ast.IdentifierPattern:
+			c.classes[tokenPos{n.Token.Line, n.Token.Column}] = semanticClass{Type: semVariable, Modifiers: modDeclaration}
+		case *ast.RecordLiteral, *ast.RecordType, *ast.RecordPattern:
+			for _, tok := range keyTokens(node) {
+				c.classes[tokenPos{tok.Line, tok.Column}] = semanticClass{Type: semProperty}
+			}
+		case *ast.Identifier:
+			c.visitIdentifier(n)
+		}
+		return true
+	})
+	return c.classes
+}
+
+type identifierClassifier struct {
+	ctx      *pipeline.PipelineContext
+	classes  map[tokenPos]semanticClass
+	handled  map[*ast.Identifier]bool
+	typeVars map[string]bool
+}
+
+func (c *identifierClassifier) set(ident *ast.Identifier, class semanticClass) {
+	if ident == nil || c.handled[ident] {
+		return
+	}
+	c.handled[ident] = true
+	c.classes[tokenPos{ident.Token.Line, ident.Token.Column}] = class
+}
+
+func (c *identifierClassifier) setTypeParams(params []*ast.Identifier) {
+	for _, p := range params {
+		c.typeVars[p.Value] = true
+		c.set(p, semanticClass{Type: semTypeParameter, Modifiers: modDeclaration})
+	}
+}
+
+func (c *identifierClassifier) setParams(params []*ast.Parameter) {
+	for _, p := range params {
+		c.set(p.Name, semanticClass{Type: semParameter, Modifiers: modDeclaration})
+	}
+}
+
+// setReceiverUses marks uses of an extension method's receiver, which the
+// analyzer resolves without a definition node.
+func (c *identifierClassifier) setReceiverUses(fn *ast.FunctionStatement) {
+	if fn.Receiver.Name == nil || fn.Body == nil {
+		return
+	}
+	name := fn.Receiver.Name.Value
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Identifier); ok && ident.Value == name {
+			if sym, ok := c.ctx.ResolutionMap[ident]; ok && sym.DefinitionNode == nil && !isGlobalSymbol(c.ctx, sym) {
+				c.set(ident, semanticClass{Type: semParameter})
+			}
+		}
+		return true
+	})
+}
+
+// setExported classifies a name imported from (or accessed through) a module.
+func (c *identifierClassifier) setExported(ident *ast.Identifier, mod *modules.Module) {
+	if ident == nil || mod == nil {
+		return
+	}
+	if sym, ok := mod.GetExports()[ident.Value]; ok {
+		class := symbolClass(c.ctx, sym)
+		if mod.IsVirtual {
+			class.Modifiers |= modDefaultLibrary
+		}
+		c.set(ident, class)
+	}
+}
+
+func isUpperName(name string) bool {
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
+
+func (c *identifierClassifier) visitNamedType(nt *ast.NamedType) {
+	if nt.Name == nil || c.handled[nt.Name] {
+		return
+	}
+	name := nt.Name.Value
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		// Qualified type (m.Point): the identifier token covers only the alias
+		c.set(nt.Name, semanticClass{Type: semNamespace})
+		typeName := name[idx+1:]
+		class := semanticClass{Type: semType}
+		if mod := moduleByAlias(c.ctx, name[:idx]); mod != nil {
+			if sym, ok := mod.GetExports()[typeName]; ok {
+				class = symbolClass(c.ctx, sym)
+			}
+		}
+		c.classes[tokenPos{nt.Name.Token.Line, nt.Name.Token.Column + utf8.RuneCountInString(name[:idx]) + 1}] = class
+		return
+	}
+	if c.typeVars[name] || !isUpperName(name) {
+		c.set(nt.Name, semanticClass{Type: semTypeParameter})
+		return
+	}
+	if sym, ok := c.ctx.SymbolTable.Find(name); ok {
+		c.set(nt.Name, symbolClass(c.ctx, sym))
+		return
+	}
+	c.set(nt.Name, semanticClass{Type: semType})
+}
+
+func (c *identifierClassifier) visitMember(member *ast.MemberExpression) {
+	if member.Member == nil {
+		return
+	}
+	if left, ok := member.Left.(*ast.Identifier); ok {
+		if alias, isModule := moduleAliasOf(c.ctx, left); isModule {
+			c.set(left, semanticClass{Type: semNamespace})
+			c.setExported(member.Member, moduleByAlias(c.ctx, alias))
+			c.set(member.Member, c.memberClass(member))
+			return
+		}
+	}
+	c.set(member.Member, c.memberClass(member))
+}
+
+// memberClass classifies a field access or method call by the member's type.
+func (c *identifierClassifier) memberClass(member *ast.MemberExpression) semanticClass {
+	name := member.Member.Value
+	if isUpperName(name) {
+		return semanticClass{Type: semType}
+	}
+	if owner := nominalTypeName(c.ctx.TypeMap[member.Left]); owner != "" && c.ctx.SymbolTable != nil {
+		if _, ok := c.ctx.SymbolTable.GetExtensionMethod(owner, name); ok {
+			return semanticClass{Type: semMethod}
+		}
+	}
+	if _, isFunc := asFunc(c.ctx.TypeMap[member]); isFunc {
+		return semanticClass{Type: semMethod}
+	}
+	return semanticClass{Type: semProperty}
+}
+
+func (c *identifierClassifier) visitIdentifier(ident *ast.Identifier) {
+	if c.handled[ident] {
+		return
+	}
+	sym, ok := c.ctx.ResolutionMap[ident]
+	if !ok {
+		if c.ctx.SymbolTable == nil {
+			return
+		}
+		if sym, ok = c.ctx.SymbolTable.Find(ident.Value); !ok {
+			return
+		}
+	}
+	class := symbolClass(c.ctx, sym)
+	if sym.Kind == symbols.VariableSymbol && !isGlobalSymbol(c.ctx, sym) {
+		// Locals: parameters keep their kind, function-typed locals stay variables
+		class.Type = semVariable
+		if c.isParameter(sym.DefinitionNode) {
+			class.Type = semParameter
+		}
+	}
+	c.set(ident, class)
+}
+
+// isParameter reports whether a definition node is a parameter name, which
+// was classified when its declaration was visited.
+func (c *identifierClassifier) isParameter(def ast.Node) bool {
+	ident, ok := def.(*ast.Identifier)
+	if !ok {
+		return false
+	}
+	class, ok := c.classes[tokenPos{ident.Token.Line, ident.Token.Column}]
+	return ok && class.Type == semParameter
+}
+
+// encodeSemanticTokens produces the relative encoding of the LSP spec. Tokens
+// must be sorted by position.
+func encodeSemanticTokens(tokens []semanticToken) []uint32 {
+	data := make([]uint32, 0, len(tokens)*5)
+	prevLine, prevChar := 0, 0
+	for _, tok := range tokens {
+		deltaLine := tok.Line - prevLine
+		deltaChar := tok.Char
+		if deltaLine == 0 {
+			deltaChar = tok.Char - prevChar
+		}
+		data = append(data, uint32(deltaLine), uint32(deltaChar), uint32(tok.Length), uint32(tok.Type), uint32(tok.Modifiers))
+		prevLine, prevChar = tok.Line, tok.Char
+	}
+	return data
+}
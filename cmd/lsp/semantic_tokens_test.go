@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// decodeSemanticTokens turns the relative encoding back into "line:char text type[+modifiers]".
+func decodeSemanticTokens(code string, data []uint32) []string {
+	lines := strings.Split(code, "\n")
+	var out []string
+	line, char := 0, 0
+	for i := 0; i+4 < len(data); i += 5 {
+		if data[i] > 0 {
+			line += int(data[i])
+			char = int(data[i+1])
+		} else {
+			char += int(data[i+1])
+		}
+		text := string([]rune(lines[line])[char : char+int(data[i+2])])
+		desc := strconv.Itoa(line) + ":" + strconv.Itoa(char) + " " + text + " " + semanticTokenTypes[data[i+3]]
+		for bit, name := range semanticTokenModifiers {
+			if data[i+4]&(1<<bit) != 0 {
+				desc += "+" + name
+			}
+		}
+		out = append(out, desc)
+	}
+	return out
+}
+
+func requestSemanticTokens(t *testing.T, code string, rng *Range) []string {
+	t.Helper()
+	uri := "file:///tokens.funxy"
+	server, buf := setupServer(t, uri, code)
+
+	var err error
+	if rng == nil {
+		err = server.handleSemanticTokensFull(1, SemanticTokensParams{TextDocument: TextDocumentIdentifier{URI: uri}})
+	} else {
+		err = server.handleSemanticTokensRange(1, SemanticTokensRangeParams{TextDocument: TextDocumentIdentifier{URI: uri}, Range: *rng})
+	}
+	if err != nil {
+		t.Fatalf("semantic tokens request failed: %v", err)
+	}
+
+	var result SemanticTokens
+	if err := json.Unmarshal(decodeResponse(t, buf).Result, &result); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	return decodeSemanticTokens(code, result.Data)
+}
+
+func expectTokens(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	have := make(map[string]bool, len(got))
+	for _, tok := range got {
+		have[tok] = true
+	}
+	for _, w := range want {
+		if !have[w] {
+			t.Errorf("missing token %q in:\n%s", w, strings.Join(got, "\n"))
+		}
+	}
+}
+
+func TestSemanticTokens_ConstructorsAndTypes(t *testing.T) {
+	code := "type Shape = Circle Float | Square Float\n" +
+		"type alias Point = { x: Int, y: Int }\n" +
+		"fun area(s: Shape) -> Float {\n" +
+		"    match s {\n" +
+		"        Circle(r) -> r * r\n" +
+		"        Square(w) -> w * w\n" +
+		"    }\n" +
+		"}\n" +
+		"c = Circle(1.0)\n" +
+		"p: Point = { x: 1, y: 2 }\n"
+	got := requestSemanticTokens(t, code, nil)
+	expectTokens(t, got,
+		"0:0 type keyword",
+		"0:5 Shape enum+declaration",
+		"0:13 Circle enumMember+declaration",
+		"0:20 Float type+defaultLibrary",
+		"1:11 Point struct+declaration",
+		"1:21 x property",
+		"2:4 area function+declaration",
+		"2:9 s parameter+declaration",
+		"2:12 Shape enum",
+		"3:10 s parameter",
+		"4:8 Circle enumMember",
+		"4:15 r variable+declaration",
+		"8:0 c variable+declaration",
+		"8:4 Circle enumMember",
+		"8:11 1.0 number",
+		"9:3 Point struct",
+		"9:13 x property",
+	)
+}
+
+func TestSemanticTokens_TraitMethods(t *testing.T) {
+	code := "trait Describe<t> {\n" +
+		"    fun describe(v: t) -> String\n" +
+		"}\n" +
+		"instance Describe Int {\n" +
+		"    fun describe(v: Int) -> String { \"int\" }\n" +
+		"}\n" +
+		"fun plain(v: Int) -> String { describe(v) }\n" +
+		"limit :- 10\n" +
+		"s = plain(limit)\n"
+	got := requestSemanticTokens(t, code, nil)
+	expectTokens(t, got,
+		"0:6 Describe interface+declaration",
+		"0:15 t typeParameter+declaration",
+		"1:8 describe method+declaration",
+		"1:20 t typeParameter",
+		"3:9 Describe interface",
+		"4:8 describe method",
+		"6:4 plain function+declaration",
+		"6:30 describe method",
+		"6:39 v parameter",
+		"7:0 limit variable+declaration+readonly",
+		"8:4 plain function",
+		"8:10 limit variable+readonly",
+	)
+}
+
+func TestSemanticTokens_LiteralsInsideInterpolation(t *testing.T) {
+	// The ${ and } delimiters are left to the grammar
+	code := "fun label(n: Int) -> String { \"#${n}\" }\n" +
+		"x = 3.14159\n" +
+		"msg = \"pi=${%\".2f\"(x)} mask=${#x\"ff\"} ${label(1)}\"\n"
+	got := requestSemanticTokens(t, code, nil)
+	expectTokens(t, got,
+		"0:30 \"# string",
+		"0:34 n variable",
+		"0:36 \" string",
+		"2:6 \"pi= string",
+		"2:12 %\".2f\" string+format",
+		"2:19 x variable",
+		"2:22  mask= string",
+		"2:30 #x\"ff\" number+binary",
+		"2:40 label function",
+		"2:46 1 number",
+		"2:49 \" string",
+	)
+}
+
+func TestSemanticTokens_Range(t *testing.T) {
+	code := "a = 1\n" +
+		"b = 2\n" +
+		"c = 3\n"
+	rng := Range{Start: Position{Line: 1, Character: 0}, End: Position{Line: 1, Character: 5}}
+	got := requestSemanticTokens(t, code, &rng)
+	want := []string{"1:0 b variable+declaration", "1:4 2 number"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected range tokens:\n got: %v\nwant: %v", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,14 +11,38 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/funvibe/funxy/internal/lint"
 )
 
+// errorRequestCancelled is the LSP error code for requests cancelled by the client.
+const errorRequestCancelled = -32800
+
+// analysisDebounce is how long the server waits after the last edit before
+// re-analyzing a document.
+const analysisDebounce = 150 * time.Millisecond
+
 // Language Server implementation
 type LanguageServer struct {
 	documents map[string]*DocumentState // URI -> document state
 	mu        sync.RWMutex              // Mutex to protect the documents map
 	writer    io.Writer                 // Output stream for JSON-RPC responses
 	rootPath  string                    // Workspace root for resolving imports
+
+	writeMu    sync.Mutex                  // Serializes writes of JSON-RPC messages
+	modules    *moduleCache                // Analyzed imported modules reused between edits
+	debounce   time.Duration               // Delay before re-analysis after a change (0 = analyze synchronously)
+	concurrent bool                        // Handle requests in their own goroutines
+	requestsMu sync.Mutex                  // Mutex to protect the in-flight requests
+	requests   map[string]*inflightRequest // Request ID -> in-flight request
+	lint       *lint.Config                // Levels of the lint rules reported as diagnostics
+}
+
+// inflightRequest tracks a request that can be cancelled with $/cancelRequest.
+type inflightRequest struct {
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func NewLanguageServer(writer io.Writer) *LanguageServer {
@@ -27,6 +52,9 @@ func NewLanguageServer(writer io.Writer) *LanguageServer {
 	return &LanguageServer{
 		documents: make(map[string]*DocumentState),
 		writer:    writer,
+		modules:   newModuleCache(),
+		requests:  make(map[string]*inflightRequest),
+		lint:      lint.DefaultConfig(),
 	}
 }
 
@@ -108,6 +136,17 @@ func (s *LanguageServer) handleMessage(content []byte) error {
 
 	// Check if this is a request (has ID) or notification (no ID)
 	if baseMessage.ID != nil {
+		// Requests run concurrently so that a slow one (e.g. workspace-wide
+		// references) does not block edits or its own cancellation.
+		if s.concurrent && baseMessage.Method != "initialize" && baseMessage.Method != "shutdown" {
+			s.beginRequest(baseMessage.ID)
+			go func() {
+				if err := s.handleRequest(baseMessage, content); err != nil {
+					log.Printf("Error handling request %v: %v", baseMessage.ID, err)
+				}
+			}()
+			return nil
+		}
 		return s.handleRequest(baseMessage, content)
 	} else {
 		return s.handleNotification(baseMessage, content)
@@ -120,6 +159,7 @@ func (s *LanguageServer) handleRequest(baseMessage struct {
 	Method  string      `json:"method"`
 	Params  interface{} `json:"params,omitempty"`
 }, content []byte) error {
+	defer s.endRequest(baseMessage.ID)
 
 	switch baseMessage.Method {
 	case "initialize":
@@ -153,6 +193,76 @@ func (s *LanguageServer) handleRequest(baseMessage struct {
 		}
 		return s.handleCompletion(baseMessage.ID, params)
 
+	case "textDocument/references":
+		var params ReferenceParams
+		if err := json.Unmarshal(content, &RequestMessage{Params: &params}); err != nil {
+			return err
+		}
+		return s.handleReferences(baseMessage.ID, params)
+
+	case "textDocument/prepareRename":
+		var params PrepareRenameParams
+		if err := json.Unmarshal(content, &RequestMessage{Params: &params}); err != nil {
+			return err
+		}
+		return s.handlePrepareRename(baseMessage.ID, params)
+
+	case "textDocument/rename":
+		var params RenameParams
+		if err := json.Unmarshal(content, &RequestMessage{Params: &params}); err != nil {
+			return err
+		}
+		return s.handleRename(baseMessage.ID, params)
+
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if err := json.Unmarshal(content, &RequestMessage{Params: &params}); err != nil {
+			return err
+		}
+		return s.handleDocumentSymbol(baseMessage.ID, params)
+
+	case "workspace/symbol":
+		var params WorkspaceSymbolParams
+		if err := json.Unmarshal(content, &RequestMessage{Params: &params}); err != nil {
+			return err
+		}
+		return s.handleWorkspaceSymbol(baseMessage.ID, params)
+
+	case "textDocument/signatureHelp":
+		var params SignatureHelpParams
+		if err := json.Unmarshal(content, &RequestMessage{Params: &params}); err != nil {
+			return err
+		}
+		return s.handleSignatureHelp(baseMessage.ID, params)
+
+	case "textDocument/inlayHint":
+		var params InlayHintParams
+		if err := json.Unmarshal(content, &RequestMessage{Params: &params}); err != nil {
+			return err
+		}
+		return s.handleInlayHint(baseMessage.ID, params)
+
+	case "textDocument/semanticTokens/full":
+		var params SemanticTokensParams
+		if err := json.Unmarshal(content, &RequestMessage{Params: &params}); err != nil {
+			return err
+		}
+		return s.handleSemanticTokensFull(baseMessage.ID, params)
+
+	case "textDocument/semanticTokens/range":
+		var params SemanticTokensRangeParams
+		if err := json.Unmarshal(content, &RequestMessage{Params: &params}); err != nil {
+			return err
+		}
+		return s.handleSemanticTokensRange(baseMessage.ID, params)
+
+	case "textDocument/codeAction":
+		var params CodeActionParams
+		if err := json.Unmarshal(content, &RequestMessage{Params: &params}); err != nil {
+			return err
+		}
+		return s.handleCodeAction(baseMessage.ID, params)
+
 	case "textDocument/formatting":
 		// Formatting is currently disabled
 		response := ResponseMessage{
@@ -212,6 +322,14 @@ func (s *LanguageServer) handleNotification(baseMessage struct {
 		}
 		return s.handleDidClose(params)
 
+	case "$/cancelRequest":
+		var params CancelParams
+		if err := json.Unmarshal(content, &NotificationMessage{Params: &params}); err != nil {
+			return err
+		}
+		s.cancelRequest(params.ID)
+		return nil
+
 	case "exit":
 		os.Exit(0)
 		return nil
@@ -223,6 +341,10 @@ func (s *LanguageServer) handleNotification(baseMessage struct {
 }
 
 func (s *LanguageServer) sendResponse(response ResponseMessage) error {
+	if response.Error == nil && s.requestContext(response.ID).Err() != nil {
+		response.Result = nil
+		response.Error = &Error{Code: errorRequestCancelled, Message: "Request cancelled"}
+	}
 	return s.sendMessage(response)
 }
 
@@ -239,6 +361,54 @@ func (s *LanguageServer) sendMessage(message interface{}) error {
 	content := string(data)
 	msg := fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(content), content)
 
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 	_, err = fmt.Fprint(s.writer, msg)
 	return err
 }
+
+func requestKey(id interface{}) string {
+	return fmt.Sprint(id)
+}
+
+// beginRequest registers a request so that $/cancelRequest can reach it.
+func (s *LanguageServer) beginRequest(id interface{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.requestsMu.Lock()
+	s.requests[requestKey(id)] = &inflightRequest{ctx: ctx, cancel: cancel}
+	s.requestsMu.Unlock()
+}
+
+func (s *LanguageServer) endRequest(id interface{}) {
+	key := requestKey(id)
+	s.requestsMu.Lock()
+	if req, ok := s.requests[key]; ok {
+		req.cancel()
+		delete(s.requests, key)
+	}
+	s.requestsMu.Unlock()
+}
+
+// requestContext returns the context of an in-flight request. It is cancelled
+// when the client sends $/cancelRequest for it. Handlers whose work grows with
+// the workspace or the document (references, rename, workspace and document
+// symbols, semantic tokens) pass it down and stop early. Hover, completion and
+// the other position queries only read the cached analysis of the document,
+// which runs on didOpen/didChange rather than per request; cancelling them
+// just turns the reply into a RequestCancelled error.
+func (s *LanguageServer) requestContext(id interface{}) context.Context {
+	s.requestsMu.Lock()
+	defer s.requestsMu.Unlock()
+	if req, ok := s.requests[requestKey(id)]; ok {
+		return req.ctx
+	}
+	return context.Background()
+}
+
+func (s *LanguageServer) cancelRequest(id interface{}) {
+	s.requestsMu.Lock()
+	defer s.requestsMu.Unlock()
+	if req, ok := s.requests[requestKey(id)]; ok {
+		req.cancel()
+	}
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/modules"
+	"github.com/funvibe/funxy/internal/pipeline"
+	"github.com/funvibe/funxy/internal/prettyprinter"
+	"github.com/funvibe/funxy/internal/symbols"
+	"github.com/funvibe/funxy/internal/token"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// callContext describes the innermost unfinished call around the cursor.
+type callContext struct {
+	Callee      string // Possibly qualified callee text, e.g. "f", "m.f" or "p.method"
+	CalleeLine  int    // 1-based position of the last callee segment
+	CalleeCol   int
+	ArgIndex    int // Index of the argument the cursor is in
+	NamedFrom   int // Index of the first named argument (name: value), -1 if none
+	CurrentName string
+}
+
+// openParen tracks an unclosed bracket while scanning the source.
+type openParen struct {
+	char      byte
+	offset    int
+	segStarts []int
+}
+
+// findCallContext scans content up to (line, char) (0-based) and returns the
+// innermost call whose argument list contains the cursor. The scan is textual so
+// that it works while the call is still incomplete and does not parse.
+func findCallContext(content string, line, char int) (*callContext, bool) {
+	offset := offsetAt(content, line, char)
+	if offset < 0 {
+		return nil, false
+	}
+
+	var stack []openParen
+	for i := 0; i < offset; i++ {
+		c := content[i]
+		switch {
+		case c == '/' && i+1 < offset && content[i+1] == '/':
+			for i < offset && content[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < offset && content[i+1] == '*':
+			end := strings.Index(content[i+2:offset], "*/")
+			if end == -1 {
+				return nil, false
+			}
+			i += end + 3
+		case c == '"' || c == '\'' || c == '`':
+			i = skipQuoted(content, i, offset)
+			if i >= offset {
+				return nil, false
+			}
+		case c == '(' || c == '[' || c == '{':
+			stack = append(stack, openParen{char: c, offset: i, segStarts: []int{i + 1}})
+		case c == ')' || c == ']' || c == '}':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case c == ',':
+			if len(stack) > 0 {
+				top := &stack[len(stack)-1]
+				top.segStarts = append(top.segStarts, i+1)
+			}
+		}
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		paren := stack[i]
+		if paren.char != '(' {
+			continue
+		}
+		callee, calleeStart := calleeBefore(content, paren.offset)
+		if callee == "" {
+			// Grouping or tuple parentheses: look further out
+			continue
+		}
+
+		// Argument list boundaries at this level: nested brackets close before the cursor
+		// only if they are above this entry in the stack, so segment starts are exact.
+		segments := paren.segStarts
+		ctx := &callContext{Callee: callee, ArgIndex: len(segments) - 1, NamedFrom: -1}
+		for idx, start := range segments {
+			end := offset
+			if idx+1 < len(segments) {
+				end = segments[idx+1] - 1
+			}
+			if i+1 < len(stack) && idx == len(segments)-1 {
+				end = stack[i+1].offset
+			}
+			if name, ok := namedArgument(content[start:end]); ok {
+				if ctx.NamedFrom == -1 {
+					ctx.NamedFrom = idx
+				}
+				if idx == len(segments)-1 {
+					ctx.CurrentName = name
+				}
+			}
+		}
+
+		lastDot := strings.LastIndex(callee, ".")
+		ctx.CalleeLine, ctx.CalleeCol = lineColAt(content, calleeStart+lastDot+1)
+		return ctx, true
+	}
+	return nil, false
+}
+
+// skipQuoted returns the offset of the closing quote of the literal starting at start.
+func skipQuoted(content string, start, limit int) int {
+	quote := content[start]
+	for i := start + 1; i < limit; i++ {
+		if content[i] == '\\' && quote != '`' {
+			i++
+			continue
+		}
+		if content[i] == quote {
+			return i
+		}
+	}
+	return limit
+}
+
+// calleeBefore returns the identifier chain immediately preceding the '(' at offset.
+func calleeBefore(content string, offset int) (string, int) {
+	end := offset
+	start := end
+	for start > 0 && (isIdentifierChar(content[start-1]) || content[start-1] == '.') {
+		start--
+	}
+	callee := strings.Trim(content[start:end], ".")
+	if callee == "" {
+		return "", 0
+	}
+	start = end - len(callee)
+	if content[end-1] == '.' {
+		return "", 0
+	}
+	for _, part := range strings.Split(callee, ".") {
+		if part == "" || (part[0] >= '0' && part[0] <= '9') {
+			return "", 0
+		}
+	}
+	// Keywords followed by parentheses (if, match, ...) are not calls
+	first := strings.Split(callee, ".")[0]
+	if kw := token.LookupIdent(first); kw != token.IDENT_LOWER && kw != token.IDENT_UPPER {
+		return "", 0
+	}
+	return callee, start
+}
+
+// namedArgument reports whether an argument is written as `name: value`.
+func namedArgument(arg string) (string, bool) {
+	arg = strings.TrimLeft(arg, " \t\r\n")
+	i := 0
+	for i < len(arg) && isIdentifierChar(arg[i]) {
+		i++
+	}
+	if i == 0 || arg[0] < 'a' || arg[0] > 'z' {
+		return "", false
+	}
+	name := arg[:i]
+	rest := strings.TrimLeft(arg[i:], " \t")
+	if !strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, ":-") || strings.HasPrefix(rest, "::") {
+		return "", false
+	}
+	return name, true
+}
+
+// offsetAt converts a 0-based line/character position to a byte offset.
+func offsetAt(content string, line, char int) int {
+	offset := 0
+	for l := 0; l < line; l++ {
+		idx := strings.IndexByte(content[offset:], '\n')
+		if idx == -1 {
+			return -1
+		}
+		offset += idx + 1
+	}
+	lineEnd := strings.IndexByte(content[offset:], '\n')
+	if lineEnd == -1 {
+		lineEnd = len(content) - offset
+	}
+	if char > lineEnd {
+		char = lineEnd
+	}
+	return offset + char
+}
+
+// lineColAt converts a byte offset to a 1-based line and column.
+func lineColAt(content string, offset int) (int, int) {
+	line := strings.Count(content[:offset], "\n") + 1
+	col := offset - strings.LastIndex(content[:offset], "\n")
+	return line, col
+}
+
+// calleeSignature resolves the function type and declaration of the callee.
+// Extension method types are returned without their receiver parameter.
+func calleeSignature(ctx *pipeline.PipelineContext, call *callContext) (typesystem.TFunc, *ast.FunctionStatement, bool) {
+	parts := strings.Split(call.Callee, ".")
+	name := parts[len(parts)-1]
+
+	// Prefer the type inferred at the call site (instantiated generics)
+	if ctx.AstRoot != nil {
+		path := FindNodePath(ctx.AstRoot, call.CalleeLine, call.CalleeCol)
+		if len(path) > 0 {
+			if ident, ok := path[len(path)-1].(*ast.Identifier); ok && ident.Value == name {
+				var parent ast.Node
+				if len(path) >= 2 {
+					parent = path[len(path)-2]
+				}
+				if fn, decl, ok := signatureFromNode(ctx, ident, parent); ok {
+					return fn, decl, true
+				}
+			}
+		}
+	}
+
+	if ctx.SymbolTable == nil {
+		return typesystem.TFunc{}, nil, false
+	}
+
+	if len(parts) == 1 {
+		sym, ok := ctx.SymbolTable.Find(name)
+		if !ok {
+			return typesystem.TFunc{}, nil, false
+		}
+		fn, ok := asFunc(sym.Type)
+		return fn, findFunctionDecl(ctx, sym, ""), ok
+	}
+
+	owner := parts[len(parts)-2]
+	if alias, isModule := moduleAliasOf(ctx, &ast.Identifier{Value: owner}); isModule && len(parts) == 2 {
+		if mod := moduleByAlias(ctx, alias); mod != nil {
+			if sym, ok := mod.GetExports()[name]; ok {
+				fn, ok := asFunc(sym.Type)
+				return fn, findFunctionDecl(ctx, sym, mod.Name), ok
+			}
+		}
+	}
+	return typesystem.TFunc{}, nil, false
+}
+
+// signatureFromNode resolves a callee identifier found in the analyzed AST.
+func signatureFromNode(ctx *pipeline.PipelineContext, ident *ast.Identifier, parent ast.Node) (typesystem.TFunc, *ast.FunctionStatement, bool) {
+	if member, ok := parent.(*ast.MemberExpression); ok && member.Member == ident {
+		if left, ok := member.Left.(*ast.Identifier); ok {
+			if alias, isModule := moduleAliasOf(ctx, left); isModule {
+				if mod := moduleByAlias(ctx, alias); mod != nil {
+					if sym, ok := mod.GetExports()[ident.Value]; ok {
+						fn, ok := asFunc(ctx.TypeMap[member])
+						if !ok {
+							fn, ok = asFunc(sym.Type)
+						}
+						return fn, findFunctionDecl(ctx, sym, mod.Name), ok
+					}
+				}
+			}
+		}
+
+		// Extension method: the receiver is passed implicitly
+		typeName := nominalTypeName(ctx.TypeMap[member.Left])
+		if typeName != "" && ctx.SymbolTable != nil {
+			if t, ok := ctx.SymbolTable.GetExtensionMethod(typeName, ident.Value); ok {
+				if fn, ok := asFunc(t); ok && len(fn.Params) > 0 {
+					fn.Params = fn.Params[1:]
+					return fn, findExtensionDecl(ctx, typeName, ident.Value), true
+				}
+			}
+		}
+		fn, ok := asFunc(ctx.TypeMap[member])
+		return fn, nil, ok
+	}
+
+	sym, resolved := ctx.ResolutionMap[ident]
+	if !resolved && ctx.SymbolTable != nil {
+		sym, resolved = ctx.SymbolTable.Find(ident.Value)
+	}
+	fn, ok := asFunc(ctx.TypeMap[ident])
+	if !ok && resolved {
+		fn, ok = asFunc(sym.Type)
+	}
+	if !ok {
+		return typesystem.TFunc{}, nil, false
+	}
+	var decl *ast.FunctionStatement
+	if resolved {
+		decl = findFunctionDecl(ctx, sym, "")
+	}
+	return fn, decl, true
+}
+
+// asFunc unwraps quantifiers and returns t as a function type.
+func asFunc(t typesystem.Type) (typesystem.TFunc, bool) {
+	for {
+		forall, ok := t.(typesystem.TForall)
+		if !ok {
+			break
+		}
+		t = forall.Type
+	}
+	fn, ok := t.(typesystem.TFunc)
+	return fn, ok
+}
+
+func moduleByAlias(ctx *pipeline.PipelineContext, alias string) *modules.Module {
+	loader, ok := ctx.Loader.(*lspModuleLoader)
+	if !ok {
+		return nil
+	}
+	pkg := alias
+	if ctx.SymbolTable != nil {
+		if p, ok := ctx.SymbolTable.GetPackageNameByAlias(alias); ok {
+			pkg = p
+		}
+	}
+	mod, _ := loader.GetModuleByPackageName(pkg).(*modules.Module)
+	return mod
+}
+
+// findFunctionDecl locates the declaration of a function symbol, either through its
+// definition node in the current file or by name among the files of its module.
+func findFunctionDecl(ctx *pipeline.PipelineContext, sym symbols.Symbol, pkg string) *ast.FunctionStatement {
+	var found *ast.FunctionStatement
+	if sym.DefinitionNode != nil && ctx.AstRoot != nil {
+		ast.Inspect(ctx.AstRoot, func(node ast.Node) bool {
+			if found != nil {
+				return false
+			}
+			if fn, ok := node.(*ast.FunctionStatement); ok && ast.Node(fn.Name) == sym.DefinitionNode {
+				found = fn
+				return false
+			}
+			return true
+		})
+		if found != nil {
+			return found
+		}
+	}
+
+	origin := sym.OriginModule
+	if origin == "" {
+		origin = pkg
+	}
+	if origin == "" || origin == "prelude" {
+		return nil
+	}
+	loader, ok := ctx.Loader.(*lspModuleLoader)
+	if !ok {
+		return nil
+	}
+	mod, _ := loader.GetModuleByPackageName(origin).(*modules.Module)
+	if mod == nil {
+		return nil
+	}
+	for _, file := range mod.Files {
+		for _, stmt := range file.Statements {
+			if fn, ok := stmt.(*ast.FunctionStatement); ok && fn.Receiver == nil && fn.Name != nil && fn.Name.Value == sym.Name {
+				return fn
+			}
+		}
+	}
+	return nil
+}
+
+// findExtensionDecl locates an extension method declared in the current file.
+func findExtensionDecl(ctx *pipeline.PipelineContext, typeName, method string) *ast.FunctionStatement {
+	prog, ok := ctx.AstRoot.(*ast.Program)
+	if !ok {
+		return nil
+	}
+	for _, stmt := range prog.Statements {
+		if fn, ok := stmt.(*ast.FunctionStatement); ok && fn.Name != nil && fn.Name.Value == method && receiverTypeName(fn.Receiver) == typeName {
+			return fn
+		}
+	}
+	return nil
+}
+
+// buildSignature renders a function type as a signature with parameter label offsets.
+// Parameter names and defaults are taken from decl when available.
+func buildSignature(name string, fn typesystem.TFunc, decl *ast.FunctionStatement) SignatureInformation {
+	pretty, ok := prettifiedType(fn).(typesystem.TFunc)
+	if !ok {
+		pretty = fn
+	}
+
+	var sb strings.Builder
+	sb.WriteString(name)
+	sb.WriteString("(")
+
+	defaultStart := len(pretty.Params) - pretty.DefaultCount
+	var params []ParameterInformation
+	for i, param := range pretty.Params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		start := sb.Len()
+
+		var declParam *ast.Parameter
+		if decl != nil && i < len(decl.Parameters) {
+			declParam = decl.Parameters[i]
+		}
+		if pretty.IsVariadic && i == len(pretty.Params)-1 {
+			sb.WriteString("...")
+		}
+		if declParam != nil && declParam.Name != nil && !declParam.IsIgnored {
+			sb.WriteString(declParam.Name.Value)
+			sb.WriteString(": ")
+		}
+		sb.WriteString(param.String())
+		if i >= defaultStart {
+			if declParam != nil && declParam.Default != nil {
+				sb.WriteString(" = ")
+				sb.WriteString(expressionString(declParam.Default))
+			} else {
+				sb.WriteString("?")
+			}
+		}
+		params = append(params, ParameterInformation{Label: [2]int{start, sb.Len()}})
+	}
+	sb.WriteString(")")
+	if pretty.ReturnType != nil {
+		sb.WriteString(" -> ")
+		sb.WriteString(pretty.ReturnType.String())
+	}
+
+	return SignatureInformation{Label: sb.String(), Parameters: params}
+}
+
+// activeParameter maps the argument under the cursor to a parameter index.
+// Named arguments (record shorthand) are collected into a single record parameter.
+func activeParameter(fn typesystem.TFunc, call *callContext) int {
+	n := len(fn.Params)
+	if n == 0 {
+		return 0
+	}
+
+	index := call.ArgIndex
+	if call.NamedFrom != -1 && index >= call.NamedFrom {
+		index = call.NamedFrom
+		if call.CurrentName != "" {
+			for i, param := range fn.Params {
+				if recordHasField(param, call.CurrentName) {
+					return i
+				}
+			}
+		}
+	}
+	if index >= n {
+		// Extra arguments belong to the variadic parameter
+		return n - 1
+	}
+	return index
+}
+
+func recordHasField(t typesystem.Type, field string) bool {
+	switch typ := t.(type) {
+	case typesystem.TRecord:
+		_, ok := typ.Fields[field]
+		return ok
+	case typesystem.TCon:
+		if typ.UnderlyingType != nil {
+			return recordHasField(typ.UnderlyingType, field)
+		}
+	}
+	return false
+}
+
+// expressionString renders an expression as source text.
+func expressionString(expr ast.Expression) string {
+	printer := prettyprinter.NewCodePrinter()
+	expr.Accept(printer)
+	return printer.String()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func requestSignatureHelp(t *testing.T, code string, pos Position) *SignatureHelp {
+	t.Helper()
+	uri := "file:///signature.funxy"
+	server, buf := setupServer(t, uri, code)
+
+	if err := server.handleSignatureHelp(1, SignatureHelpParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     pos,
+	}); err != nil {
+		t.Fatalf("handleSignatureHelp failed: %v", err)
+	}
+
+	var help *SignatureHelp
+	if err := json.Unmarshal(decodeResponse(t, buf).Result, &help); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	return help
+}
+
+func activeLabel(help *SignatureHelp) string {
+	sig := help.Signatures[help.ActiveSignature]
+	label := sig.Parameters[help.ActiveParameter].Label
+	return sig.Label[label[0]:label[1]]
+}
+
+func TestSignatureHelp_DefaultsAndActiveParameter(t *testing.T) {
+	code := "fun greet(name: String, greeting: String = \"Hello\") -> String { greeting ++ \", \" ++ name }\n" +
+		"msg = greet(\"Bob\", \"Hi\")\n"
+	help := requestSignatureHelp(t, code, positionOf(t, code, "\"Hi\"", 0))
+	if help == nil {
+		t.Fatal("expected signature help")
+	}
+
+	want := "greet(name: String, greeting: String = \"Hello\") -> String"
+	if help.Signatures[0].Label != want {
+		t.Errorf("expected label %q, got %q", want, help.Signatures[0].Label)
+	}
+	if got := activeLabel(help); got != "greeting: String = \"Hello\"" {
+		t.Errorf("unexpected active parameter %q", got)
+	}
+}
+
+func TestSignatureHelp_IncompleteCall(t *testing.T) {
+	code := "fun add(x: Int, y: Int) -> Int { x + y }\n" +
+		"r = add(1, "
+	help := requestSignatureHelp(t, code, Position{Line: 1, Character: len("r = add(1, ")})
+	if help == nil {
+		t.Fatal("expected signature help for an unfinished call")
+	}
+	if got := activeLabel(help); got != "y: Int" {
+		t.Errorf("unexpected active parameter %q", got)
+	}
+}
+
+func TestSignatureHelp_Variadic(t *testing.T) {
+	code := "fun total(base: Int, ...rest: Int) -> Int { base }\n" +
+		"r = total(1, 2, 3, 4)\n"
+	help := requestSignatureHelp(t, code, positionOf(t, code, "4)", 0))
+	if help == nil {
+		t.Fatal("expected signature help")
+	}
+	if got := activeLabel(help); !strings.HasPrefix(got, "...rest") {
+		t.Errorf("extra arguments should map to the variadic parameter, got %q", got)
+	}
+}
+
+func TestSignatureHelp_NamedArguments(t *testing.T) {
+	code := "type alias Options = { verbose: Bool, depth: Int }\n" +
+		"fun run(cmd: String, opts: Options) -> String { cmd }\n" +
+		"r = run(\"ls\", verbose: true, depth: 2)\n"
+	help := requestSignatureHelp(t, code, positionOf(t, code, "depth: 2", 0))
+	if help == nil {
+		t.Fatal("expected signature help")
+	}
+	if got := activeLabel(help); !strings.HasPrefix(got, "opts") {
+		t.Errorf("named arguments should map to the record parameter, got %q", got)
+	}
+}
+
+func TestSignatureHelp_NotInCall(t *testing.T) {
+	code := "x = (1 + 2)\n"
+	if help := requestSignatureHelp(t, code, Position{Line: 0, Character: 6}); help != nil {
+		t.Errorf("expected no signature help inside parentheses, got %+v", help)
+	}
+}
+
+func TestInlayHint_BindingsAndLambdaParameters(t *testing.T) {
+	uri := "file:///hints.funxy"
+	code := "count = 42\n" +
+		"typed: Int = 1\n" +
+		"count = 43\n" +
+		"limit :- \"max\"\n" +
+		"inc = \\n -> n + 1\n"
+	server, buf := setupServer(t, uri, code)
+
+	if err := server.handleInlayHint(1, InlayHintParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Range:        Range{Start: Position{Line: 0, Character: 0}, End: Position{Line: 10, Character: 0}},
+	}); err != nil {
+		t.Fatalf("handleInlayHint failed: %v", err)
+	}
+
+	var hints []InlayHint
+	if err := json.Unmarshal(decodeResponse(t, buf).Result, &hints); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	var got []string
+	for _, h := range hints {
+		got = append(got, strconv.Itoa(h.Position.Line)+":"+strconv.Itoa(h.Position.Character)+h.Label)
+	}
+	want := []string{"0:5: Int", "3:5: String", "4:8: Int"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("unexpected hints:\n got: %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocumentSymbol_Outline(t *testing.T) {
+	uri := "file:///outline.funxy"
+	code := "type alias Point = { x: Int, y: Int }\n" +
+		"type Shape = Circle Float | Rect Float Float\n" +
+		"trait Describe<t> {\n" +
+		"    fun describe(v: t) -> String\n" +
+		"}\n" +
+		"instance Describe Shape {\n" +
+		"    fun describe(v: Shape) -> String { \"shape\" }\n" +
+		"}\n" +
+		"fun (p: Point) norm() -> Int { p.x + p.y }\n" +
+		"fun area(s: Shape) -> Float {\n" +
+		"    match s {\n" +
+		"        Circle(r) -> r * r\n" +
+		"        Rect(w, h) -> w * h\n" +
+		"    }\n" +
+		"}\n" +
+		"limit :- 10\n" +
+		"counter = 0\n" +
+		"counter = 1\n"
+	server, buf := setupServer(t, uri, code)
+
+	if err := server.handleDocumentSymbol(1, DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: uri}}); err != nil {
+		t.Fatalf("handleDocumentSymbol failed: %v", err)
+	}
+
+	var symbols []DocumentSymbol
+	if err := json.Unmarshal(decodeResponse(t, buf).Result, &symbols); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	var summary []string
+	var walk func(prefix string, syms []DocumentSymbol)
+	walk = func(prefix string, syms []DocumentSymbol) {
+		for _, sym := range syms {
+			summary = append(summary, prefix+sym.Name+":"+strconv.Itoa(int(sym.Kind)))
+			walk(prefix+sym.Name+".", sym.Children)
+		}
+	}
+	walk("", symbols)
+
+	want := []string{
+		"Point:23", "Point.x:8", "Point.y:8",
+		"Shape:10", "Shape.Circle:9", "Shape.Rect:9",
+		"Describe:11", "Describe.describe:6",
+		"Describe Shape:19", "Describe Shape.describe:6",
+		"norm:6",
+		"area:12",
+		"limit:14",
+		"counter:13",
+	}
+	if strings.Join(summary, " ") != strings.Join(want, " ") {
+		t.Errorf("unexpected outline:\n got: %v\nwant: %v", summary, want)
+	}
+
+	// Ranges: the function spans its whole body, the selection covers the name
+	for _, sym := range symbols {
+		if sym.Name != "area" {
+			continue
+		}
+		if sym.Range.Start.Line != 9 || sym.Range.End.Line != 14 {
+			t.Errorf("expected 'area' to span lines 9-14, got %+v", sym.Range)
+		}
+		wantSel := Range{Start: Position{Line: 9, Character: 4}, End: Position{Line: 9, Character: 8}}
+		if sym.SelectionRange != wantSel {
+			t.Errorf("expected selection %+v, got %+v", wantSel, sym.SelectionRange)
+		}
+		if sym.Detail != "(s: Shape) -> Float" {
+			t.Errorf("unexpected detail %q", sym.Detail)
+		}
+	}
+}
+
+// Derived instances are not declarations in the document.
+func TestDocumentSymbol_DerivingClause(t *testing.T) {
+	uri := "file:///deriving.funxy"
+	code := "type Shape = Circle(Int) | Blank deriving (Equal, Show)\n" +
+		"instance Default Shape {\n" +
+		"    fun getDefault() -> Shape { Blank }\n" +
+		"}\n"
+	server, buf := setupServer(t, uri, code)
+
+	if err := server.handleDocumentSymbol(1, DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: uri}}); err != nil {
+		t.Fatalf("handleDocumentSymbol failed: %v", err)
+	}
+
+	var symbols []DocumentSymbol
+	if err := json.Unmarshal(decodeResponse(t, buf).Result, &symbols); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	var names []string
+	for _, sym := range symbols {
+		names = append(names, sym.Name)
+	}
+	want := []string{"Shape", "Default Shape"}
+	if strings.Join(names, ", ") != strings.Join(want, ", ") {
+		t.Errorf("unexpected symbols: got %v, want %v", names, want)
+	}
+}
+
+func TestWorkspaceSymbol_Query(t *testing.T) {
+	root := writeWorkspace(t, map[string]string{
+		"geo/geo.lang": "package geo (area, Point)\n" +
+			"type alias Point = { x: Int, y: Int }\n" +
+			"fun area(w: Int, h: Int) -> Int { w * h }\n",
+		"main.lang": "import \"./geo\" (area)\n" +
+			"fun areaOfSquare(s: Int) -> Int { area(s, s) }\n",
+		"tools/util.lang": "fun unrelated() -> Int { 1 }\n",
+	})
+	server, buf, _ := openWorkspaceFile(t, root, "main.lang")
+
+	if err := server.handleWorkspaceSymbol(1, WorkspaceSymbolParams{Query: "area"}); err != nil {
+		t.Fatalf("handleWorkspaceSymbol failed: %v", err)
+	}
+
+	var symbols []SymbolInformation
+	if err := json.Unmarshal(decodeResponse(t, buf).Result, &symbols); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	var names []string
+	for _, sym := range symbols {
+		names = append(names, sym.Name+"@"+sym.Location.URI[strings.LastIndex(sym.Location.URI, "/")+1:])
+	}
+	want := []string{"area@geo.lang", "areaOfSquare@main.lang"}
+	if strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Errorf("unexpected symbols:\n got: %v\nwant: %v", names, want)
+	}
+}
+
+func TestMatchesSymbolQuery(t *testing.T) {
+	tests := []struct {
+		name, query string
+		want        bool
+	}{
+		{"areaOfSquare", "", true},
+		{"areaOfSquare", "aos", true},
+		{"areaOfSquare", "AREA", true},
+		{"areaOfSquare", "square", true},
+		{"areaOfSquare", "circle", false},
+	}
+	for _, tt := range tests {
+		if got := matchesSymbolQuery(tt.name, tt.query); got != tt.want {
+			t.Errorf("matchesSymbolQuery(%q, %q) = %v, want %v", tt.name, tt.query, got, tt.want)
+		}
+	}
+}
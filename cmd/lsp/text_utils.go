@@ -1,5 +1,10 @@
 package main
 
+import (
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/token"
+)
+
 func getLine(content string, lineIndex int) string {
 	start := 0
 	currentLine := 0
@@ -54,3 +59,35 @@ func getWordAtPosition(content string, line, char int) string {
 func isIdentifierChar(b byte) bool {
 	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || b == '_'
 }
+
+// tokenSpan is a lexer token together with its byte offsets in the source.
+type tokenSpan struct {
+	Tok   token.Token
+	Start int
+	End   int
+}
+
+// lexTokenSpans lexes content, recording where each token starts and ends.
+// Unlike token lexemes, the spans are exact for string literals.
+func lexTokenSpans(content string) []tokenSpan {
+	src := newSourceText(content)
+	l := lexer.New(content)
+	var spans []tokenSpan
+	for {
+		tok := l.NextToken()
+		if tok.Type == token.EOF {
+			return spans
+		}
+		spans = append(spans, tokenSpan{Tok: tok, Start: src.offset(tok.Line, tok.Column), End: l.Offset()})
+	}
+}
+
+// leadingWhitespace returns the indentation of a line.
+func leadingWhitespace(line string) string {
+	for i := 0; i < len(line); i++ {
+		if line[i] != ' ' && line[i] != '\t' {
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -35,6 +35,16 @@ func PrettifyType(t typesystem.Type) string {
 	if t == nil {
 		return ""
 	}
+	return prettifiedType(t).String()
+}
+
+// prettifiedType returns t with its type variables renamed as described in PrettifyType.
+// Use it when parts of a type (e.g. function parameters) are rendered separately
+// and must share the same variable names.
+func prettifiedType(t typesystem.Type) typesystem.Type {
+	if t == nil {
+		return nil
+	}
 
 	// Unwrap TForall to access inner type and variables
 	// We want to rename bound variables too.
@@ -130,6 +140,5 @@ func PrettifyType(t typesystem.Type) string {
 	}
 
 	// 3. Apply substitution
-	newType := current.Apply(subst)
-	return newType.String()
+	return current.Apply(subst)
 }
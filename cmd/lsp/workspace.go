@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/config"
+	"github.com/funvibe/funxy/internal/modules"
+	"github.com/funvibe/funxy/internal/pipeline"
+)
+
+// maxWorkspaceFiles bounds how many source files a workspace-wide operation scans.
+const maxWorkspaceFiles = 5000
+
+// workspaceUnit is a set of files that are analyzed together:
+// either all files of a package directory or a single standalone script.
+type workspaceUnit struct {
+	Path     string // Representative file used to trigger the analysis
+	IsScript bool
+}
+
+// analyzedUnit is the result of analyzing a workspaceUnit.
+type analyzedUnit struct {
+	Ctx    *pipeline.PipelineContext
+	Module *modules.Module // nil for scripts analyzed without a module
+	Files  []*ast.Program
+}
+
+// workspaceRoot returns the directory that workspace-wide operations scan.
+// Without a root provided by the client there is no workspace to scan.
+func (s *LanguageServer) workspaceRoot() string {
+	return s.rootPath
+}
+
+// collectWorkspaceFiles walks root and returns all source files in it, sorted.
+// Open documents that live under root are included even if they are not saved yet.
+func (s *LanguageServer) collectWorkspaceFiles(root string, openDocs map[string]string) []string {
+	var files []string
+	seen := make(map[string]bool)
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(files) >= maxWorkspaceFiles {
+			return filepath.SkipAll
+		}
+		if config.HasSourceExt(path) {
+			files = append(files, filepath.Clean(path))
+			seen[filepath.Clean(path)] = true
+		}
+		return nil
+	})
+
+	cleanRoot := filepath.Clean(root)
+	for path := range openDocs {
+		clean := filepath.Clean(path)
+		if seen[clean] || !config.HasSourceExt(clean) {
+			continue
+		}
+		if clean == cleanRoot || strings.HasPrefix(clean, cleanRoot+string(filepath.Separator)) {
+			files = append(files, clean)
+			seen[clean] = true
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// collectWorkspaceUnits groups the source files of root into analysis units.
+func (s *LanguageServer) collectWorkspaceUnits(root string) []workspaceUnit {
+	openDocs := s.collectOpenDocuments("", "")
+	files := s.collectWorkspaceFiles(root, openDocs)
+
+	var units []workspaceUnit
+	packageDirs := make(map[string]bool)
+	for _, path := range files {
+		content, ok := openDocs[path]
+		if !ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			content = string(data)
+		}
+
+		if !hasPackageDeclaration(content) {
+			units = append(units, workspaceUnit{Path: path, IsScript: true})
+			continue
+		}
+
+		dir := filepath.Dir(path)
+		if packageDirs[dir] {
+			continue
+		}
+		packageDirs[dir] = true
+		units = append(units, workspaceUnit{Path: path})
+	}
+
+	return units
+}
+
+// analyzeWorkspace analyzes every unit of the workspace.
+// Analysis of a unit that fails to produce a module still yields its single file.
+func (s *LanguageServer) analyzeWorkspace(ctx context.Context) []*analyzedUnit {
+	root := s.workspaceRoot()
+	if root == "" {
+		return nil
+	}
+	units := s.collectWorkspaceUnits(root)
+	openDocs := s.collectOpenDocuments("", "")
+
+	var result []*analyzedUnit
+	for _, unit := range units {
+		if ctx.Err() != nil {
+			return result
+		}
+
+		content, ok := openDocs[unit.Path]
+		if !ok {
+			data, err := os.ReadFile(unit.Path)
+			if err != nil {
+				continue
+			}
+			content = string(data)
+		}
+
+		unitCtx := s.analyzeDocument(content, "file://"+unit.Path, ctx)
+		if unitCtx == nil {
+			continue
+		}
+
+		analyzed := &analyzedUnit{Ctx: unitCtx}
+		if mod, ok := unitCtx.Module.(*modules.Module); ok && mod != nil {
+			analyzed.Module = mod
+			for _, file := range mod.Files {
+				if file != nil {
+					analyzed.Files = append(analyzed.Files, file)
+				}
+			}
+		}
+		if len(analyzed.Files) == 0 {
+			if prog, ok := unitCtx.AstRoot.(*ast.Program); ok && prog != nil {
+				analyzed.Files = append(analyzed.Files, programWithFile(prog, unit.Path))
+			}
+		}
+		result = append(result, analyzed)
+	}
+
+	return result
+}
+
+// fileContent returns the text of a file, preferring the open document overlay.
+func (s *LanguageServer) fileContent(path string) (string, bool) {
+	s.mu.RLock()
+	for uri, doc := range s.documents {
+		if filepath.Clean(s.uriToPath(uri)) == filepath.Clean(path) {
+			doc.Mu.RLock()
+			content := doc.Content
+			doc.Mu.RUnlock()
+			s.mu.RUnlock()
+			return content, true
+		}
+	}
+	s.mu.RUnlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// pathToURI converts a file system path to a file:// URI.
+func pathToURI(path string) string {
+	if strings.HasPrefix(path, "file://") {
+		return path
+	}
+	return "file://" + path
+}
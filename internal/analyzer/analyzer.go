@@ -21,6 +21,10 @@ type Analyzer struct {
 	ResolutionMap map[ast.Node]symbols.Symbol       // Stores resolved symbols
 	inferCtx      *InferenceContext                 // Shared inference context for consistent TVar naming
 	TraitDefaults map[string]*ast.FunctionStatement // "TraitName.methodName" -> FunctionStatement
+
+	// Incremental lets a program assign to top-level variables that an
+	// earlier program analyzed with the same symbol table defined (REPL).
+	Incremental bool
 }
 
 const MaxASTDepth = 100
@@ -113,6 +117,7 @@ type walker struct {
 	injectedStmts     []ast.Statement                   // Statements queued for injection (e.g. dictionaries)
 	aborted           bool                              // Flag to abort analysis immediately (e.g. on duplicate import)
 	depth             int                               // Current AST visit depth (guards against stack overflow on deep nesting)
+	incremental       bool                              // Top-level assignments may update existing variables (see Analyzer.Incremental)
 }
 
 // addError adds an error to the walker, deduplicating by position and message
@@ -222,6 +227,7 @@ func (a *Analyzer) AnalyzeNaming(node ast.Node, ctx *pipeline.PipelineContext) [
 		BaseDir:     a.BaseDir,
 		mode:        ModeNaming,
 		inferCtx:    inferCtx,
+		incremental: a.Incremental,
 	}
 	node.Accept(w)
 	return w.getErrors()
@@ -242,6 +248,8 @@ func (a *Analyzer) AnalyzeHeaders(node ast.Node, ctx *pipeline.PipelineContext)
 		a.inferCtx.Context = ctx.Context
 	}
 
+	a.inferCtx.noteReassignedBindings(node)
+
 	// Save previous state to restore after analysis
 	prevTypeMap := a.inferCtx.TypeMap
 	a.inferCtx.TypeMap = typeMap
@@ -473,11 +481,12 @@ func (a *Analyzer) AnalyzeBodies(node ast.Node, ctx *pipeline.PipelineContext) [
 				w.addError(diagnostics.NewError(diagnostics.ErrP008, getNodeToken(node), "analyzer timeout"))
 				return w.getErrors()
 			}
-			w.TypeMap[node] = typ.Apply(a.inferCtx.GlobalSubst)
+			w.TypeMap[node] = a.symbolTable.ReduceAssociatedTypes(typ.Apply(a.inferCtx.GlobalSubst))
 		}
 		// Finalize Instantiations in CallExpressions
 		a.finalizeInstantiations(node, a.inferCtx.GlobalSubst, 0)
 	}
+	reifyTypedBuiltinCalls(a.inferCtx, w.TypeMap, a.symbolTable)
 
 	// Resolve Pending Witnesses (global pass)
 	ResolvePendingWitnesses(a.inferCtx, nil, a.symbolTable, func(n ast.Node, err error) {
@@ -500,6 +509,10 @@ func (a *Analyzer) AnalyzeBodies(node ast.Node, ctx *pipeline.PipelineContext) [
 		w.addError(diagnostics.NewError(diagnostics.ErrA003, getNodeToken(n), err.Error()))
 	})
 
+	ResolvePendingInstanceContexts(a.inferCtx, a.symbolTable, func(n ast.Node, err error) {
+		w.addError(diagnostics.NewError(diagnostics.ErrA003, getNodeToken(n), err.Error()))
+	})
+
 	// Resolve pending witnesses and constraints for standalone expression analysis
 	// This mirrors what AnalyzeBodies does for full programs.
 	if len(a.inferCtx.PendingWitnesses) > 0 || len(a.inferCtx.Constraints) > 0 {
@@ -536,6 +549,95 @@ func ResolvePendingReturnContexts(ctx *InferenceContext, errorHandler func(ast.N
 	ctx.PendingReturnContexts = remaining
 }
 
+// ResolvePendingInstanceContexts checks that the instances builtin trait
+// uses dispatch to have their requirements met, as solving a constraint
+// would: show(Box(f)) needs Show for the type of f when Box has Show Box<t: Show>.
+// Types still holding variables are left to the generic code they flow into.
+func ResolvePendingInstanceContexts(ctx *InferenceContext, table *symbols.SymbolTable, errorHandler func(ast.Node, error)) {
+	for _, pi := range ctx.PendingInstanceContexts {
+		t := table.ReduceAssociatedTypes(pi.Type.Apply(ctx.GlobalSubst))
+		inst, subst, found := table.FindInstance(pi.Trait, []typesystem.Type{t})
+		if !found {
+			continue
+		}
+		for _, req := range inst.Requirements {
+			if len(req.Args) > 0 {
+				continue
+			}
+			reqType := typesystem.Type(typesystem.TVar{Name: req.TypeVar}).Apply(subst)
+			if gap := instanceGap(table, req.Trait, reqType, 0); gap != nil {
+				errorHandler(pi.Node, fmt.Errorf("%s does not implement %s: its instance requires %s for %s", t, pi.Trait, req.Trait, gap))
+				break
+			}
+		}
+	}
+	ctx.PendingInstanceContexts = nil
+}
+
+// instanceGap returns the part of t that has no instance of trait, or nil.
+// Records, tuples and nullable types count structurally, as for deriving.
+func instanceGap(table *symbols.SymbolTable, trait string, t typesystem.Type, depth int) typesystem.Type {
+	if depth > MaxWitnessDepth || isVar(t) || len(t.FreeTypeVariables()) > 0 {
+		return nil
+	}
+	if inst, subst, found := table.FindInstance(trait, []typesystem.Type{t}); found {
+		for _, req := range inst.Requirements {
+			if len(req.Args) > 0 {
+				continue
+			}
+			reqType := typesystem.Type(typesystem.TVar{Name: req.TypeVar}).Apply(subst)
+			if gap := instanceGap(table, req.Trait, reqType, depth+1); gap != nil {
+				return gap
+			}
+		}
+		return nil
+	}
+	if table.IsImplementationExists(trait, []typesystem.Type{t}) {
+		return nil
+	}
+	switch typ := t.(type) {
+	case typesystem.TTuple:
+		if trait != "Default" {
+			return t
+		}
+		for _, el := range typ.Elements {
+			if gap := instanceGap(table, trait, el, depth+1); gap != nil {
+				return gap
+			}
+		}
+		return nil
+	case typesystem.TRecord:
+		if trait == "Order" || trait == "Default" {
+			return t
+		}
+		names := make([]string, 0, len(typ.Fields))
+		for name := range typ.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if gap := instanceGap(table, trait, typ.Fields[name], depth+1); gap != nil {
+				return gap
+			}
+		}
+		return nil
+	case typesystem.TUnion:
+		if trait == "Order" || trait == "Default" {
+			return t
+		}
+		for _, member := range typ.Types {
+			if con, ok := member.(typesystem.TCon); ok && con.Name == "Nil" {
+				continue
+			}
+			if gap := instanceGap(table, trait, member, depth+1); gap != nil {
+				return gap
+			}
+		}
+		return nil
+	}
+	return t
+}
+
 func hasReturnDispatchContext(t typesystem.Type) bool {
 	switch typ := t.(type) {
 	case typesystem.TApp:
@@ -572,7 +674,7 @@ func ResolvePendingWitnesses(ctx *InferenceContext, subst typesystem.Subst, tabl
 					resolved = resolved.Apply(subst)
 				}
 				// Apply global subst
-				resolved = resolved.Apply(ctx.GlobalSubst)
+				resolved = table.ReduceAssociatedTypes(resolved.Apply(ctx.GlobalSubst))
 				resolvedArgs = append(resolvedArgs, resolved)
 			}
 		} else {
@@ -773,6 +875,7 @@ func (a *Analyzer) Analyze(node ast.Node, ctx *pipeline.PipelineContext) []*diag
 	}
 	// Share ResolutionMap with InferenceContext
 	w.inferCtx.ResolutionMap = resolutionMap
+	w.inferCtx.noteReassignedBindings(node)
 
 	node.Accept(w)
 
@@ -0,0 +1,41 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/funvibe/funxy/internal/symbols"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// A unification attempt that fails must not leave the equations it deferred
+// on still-open associated types behind.
+func TestAssociatedTypes_FailedUnificationDropsDeferredEquations(t *testing.T) {
+	table := symbols.NewEmptySymbolTable()
+	table.RegisterAssociatedType("Elem", "Container")
+	ctx := NewInferenceContext()
+	resolver := &ResolverWrapper{Table: table, Ctx: ctx}
+
+	elem := typesystem.TApp{Constructor: typesystem.TCon{Name: "Container.Elem"}, Args: []typesystem.Type{ctx.FreshVar()}}
+	intT := typesystem.TCon{Name: "Int"}
+
+	// The parameters defer Elem<$1> ~ Int, then the return types fail
+	_, err := typesystem.UnifyWithResolver(
+		typesystem.TFunc{Params: []typesystem.Type{elem}, ReturnType: typesystem.TCon{Name: "String"}},
+		typesystem.TFunc{Params: []typesystem.Type{intT}, ReturnType: typesystem.TCon{Name: "Bool"}},
+		resolver,
+	)
+	if err == nil {
+		t.Fatal("expected the unification to fail")
+	}
+	if len(ctx.Constraints) != 0 || ctx.associatedEquations != 0 {
+		t.Errorf("failed unification left %d constraints (%d associated equations)", len(ctx.Constraints), ctx.associatedEquations)
+	}
+
+	// On success the equation is kept
+	if _, err := typesystem.UnifyWithResolver(elem, intT, resolver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ctx.Constraints) != 1 || ctx.associatedEquations != 1 {
+		t.Errorf("expected the deferred equation to be kept, got %d constraints", len(ctx.Constraints))
+	}
+}
@@ -1034,6 +1034,11 @@ func registerPrimitiveInstances(table *symbols.SymbolTable) {
 	uuidCon := typesystem.TCon{Name: "Uuid"}
 	reg(table, "Equal", uuidCon)
 
+	// Zone implements Show, Equal
+	zoneCon := typesystem.TCon{Name: "Zone"}
+	reg(table, "Show", zoneCon)
+	reg(table, "Equal", zoneCon)
+
 	// Reader implements Functor, Applicative, Monad
 	readerCon := typesystem.TCon{Name: "Reader"}
 	reg(table, "Functor", readerCon)
@@ -1088,6 +1093,60 @@ func registerPrimitiveInstances(table *symbols.SymbolTable) {
 	reg(table, "Iter", rangeCharType)
 }
 
+// registerLibraryTrait completes a trait imported from a library package:
+// the package defines the trait and its methods, and this adds the dispatch
+// of the methods and the instances for built-in types. Hash (lib/crypto) and
+// ToJson (lib/json) are not in the prelude, so user code may use their names.
+func registerLibraryTrait(table *symbols.SymbolTable, traitName string) {
+	byFirstArg := []typesystem.DispatchSource{{Kind: typesystem.DispatchArg, Index: 0}}
+	listType := typesystem.TApp{
+		Constructor: typesystem.TCon{Name: config.ListTypeName},
+		Args:        []typesystem.Type{typesystem.TVar{Name: "a"}},
+	}
+	optionType := typesystem.TApp{
+		Constructor: typesystem.TCon{Name: config.OptionTypeName},
+		Args:        []typesystem.Type{typesystem.TVar{Name: "a"}},
+	}
+	// Both traits cover the numbers, Bool, Char, List (and so String),
+	// Option, Nil and tuples
+	instances := []typesystem.Type{
+		typesystem.Int, typesystem.Float, typesystem.BigInt, typesystem.Rational,
+		typesystem.Bool, typesystem.TCon{Name: "Char"}, listType, optionType, typesystem.Nil,
+	}
+	for arity := 2; arity <= 4; arity++ {
+		args := make([]typesystem.Type, arity)
+		for i := 0; i < arity; i++ {
+			args[i] = typesystem.TVar{Name: fmt.Sprintf("t%d", i)}
+		}
+		instances = append(instances, typesystem.TTuple{Elements: args})
+	}
+
+	switch traitName {
+	case "Hash":
+		table.RegisterTraitMethodDispatch("Hash", "hash", byFirstArg)
+		// Map, Result, Bytes and Bits hash too; JSON has no encoding for them
+		instances = append(instances,
+			typesystem.TApp{
+				Constructor: typesystem.TCon{Name: config.ResultTypeName},
+				Args:        []typesystem.Type{typesystem.TVar{Name: "e"}, typesystem.TVar{Name: "a"}},
+			},
+			typesystem.TApp{
+				Constructor: typesystem.TCon{Name: config.MapTypeName},
+				Args:        []typesystem.Type{typesystem.TVar{Name: "k"}, typesystem.TVar{Name: "v"}},
+			},
+			typesystem.TCon{Name: config.BytesTypeName},
+			typesystem.TCon{Name: config.BitsTypeName},
+		)
+	case "ToJson":
+		table.RegisterTraitMethodDispatch("ToJson", "toJson", byFirstArg)
+	default:
+		return
+	}
+	for _, t := range instances {
+		reg(table, traitName, t)
+	}
+}
+
 // reg registers both implementation and evidence for a built-in instance
 func reg(table *symbols.SymbolTable, traitName string, t typesystem.Type) {
 	args := []typesystem.Type{t}
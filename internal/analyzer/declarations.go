@@ -108,7 +108,7 @@ func tagModule(t typesystem.Type, moduleName string, exportedTypes map[string]bo
 		for k, v := range t.Fields {
 			newFields[k] = tagModule(v, moduleName, exportedTypes)
 		}
-		return typesystem.TRecord{Fields: newFields, IsOpen: t.IsOpen}
+		return typesystem.TRecord{Fields: newFields, IsOpen: t.IsOpen, Row: t.Row}
 	case typesystem.TType:
 		return typesystem.TType{Type: tagModule(t.Type, moduleName, exportedTypes)}
 	}
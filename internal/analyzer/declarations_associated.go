@@ -0,0 +1,178 @@
+package analyzer
+
+import (
+	"fmt"
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/diagnostics"
+	"github.com/funvibe/funxy/internal/symbols"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// Associated types are type functions declared by a trait and defined by
+// each of its instances:
+//
+//	trait Container<c> { type Elem; fun first(coll: c) -> Elem<c> }
+//	instance Container IntBag { type Elem = Int ... }
+//
+// Elem takes the trait parameters as arguments, and Elem<IntBag> reduces to
+// Int during inference (see ReduceAssociatedType and SolveConstraints).
+// Associated types are scoped to their trait: internally Elem is the type
+// Container.Elem, which code outside the trait and its instances may also
+// write when several traits in scope declare an Elem.
+
+// associatedTypeKind is the kind of an associated type of a trait with the
+// given parameter kinds: * -> * for Container<c>.
+func associatedTypeKind(paramKinds []typesystem.Kind) typesystem.Kind {
+	if len(paramKinds) == 0 {
+		return typesystem.Star
+	}
+	kinds := append(append([]typesystem.Kind{}, paramKinds...), typesystem.Star)
+	return typesystem.MakeArrow(kinds...)
+}
+
+// nameAssociatedTypes registers the associated types of trait n in the
+// naming pass, so signatures can mention them before the trait.
+func (w *walker) nameAssociatedTypes(n *ast.TraitDeclaration) {
+	paramKinds := make([]typesystem.Kind, len(n.TypeParams))
+	for i := range n.TypeParams {
+		paramKinds[i] = typesystem.Star
+	}
+	kind := associatedTypeKind(paramKinds)
+	for _, at := range n.AssociatedTypes {
+		if at == nil || at.Name == nil {
+			continue
+		}
+		w.symbolTable.RegisterAssociatedType(at.Name.Value, n.Name.Value)
+		w.symbolTable.RegisterKind(symbols.AssociatedTypeName(n.Name.Value, at.Name.Value), kind)
+	}
+}
+
+// declareAssociatedTypes registers the associated types of trait n in table
+// as type constructors over the trait parameters. Inside the trait scope
+// the bare name refers to the trait's own associated type.
+func (w *walker) declareAssociatedTypes(n *ast.TraitDeclaration, params []typesystem.TVar, table, scope *symbols.SymbolTable) {
+	paramKinds := make([]typesystem.Kind, len(params))
+	for i, p := range params {
+		paramKinds[i] = p.Kind()
+	}
+	kind := associatedTypeKind(paramKinds)
+
+	declared := make(map[string]bool)
+	for _, at := range n.AssociatedTypes {
+		if at == nil || at.Name == nil {
+			continue
+		}
+		name := at.Name.Value
+		if declared[name] {
+			w.addError(diagnostics.NewError(
+				diagnostics.ErrA004,
+				at.Name.GetToken(),
+				fmt.Sprintf("associated type %s (already declared in trait %s)", name, n.Name.Value),
+			))
+			continue
+		}
+		declared[name] = true
+
+		if at.Type != nil {
+			w.addError(diagnostics.NewError(
+				diagnostics.ErrA003,
+				at.Type.GetToken(),
+				fmt.Sprintf("associated type %s cannot be defined in trait %s: define it in each instance", name, n.Name.Value),
+			))
+		}
+		qualified := symbols.AssociatedTypeName(n.Name.Value, name)
+		table.RegisterKind(qualified, kind)
+		table.RegisterAssociatedType(name, n.Name.Value)
+		scope.DefineType(name, typesystem.TCon{Name: qualified, KindVal: kind}, w.currentModuleName)
+	}
+}
+
+// defineAssociatedTypes records the associated type definitions of instance
+// n, which is registered for instanceArgs. Type variables of a definition
+// must come from the instance head, as t in
+// instance Container List<t> { type Elem = t }.
+func (w *walker) defineAssociatedTypes(n *ast.InstanceDeclaration, traitName, typeName string, instanceArgs []typesystem.Type, scope *symbols.SymbolTable) {
+	// Inside the instance, Elem is the trait's own associated type
+	declared := make(map[string]bool)
+	for _, name := range w.symbolTable.GetTraitAssociatedTypes(traitName) {
+		declared[name] = true
+		qualified := symbols.AssociatedTypeName(traitName, name)
+		kind, _ := w.symbolTable.GetKind(qualified)
+		scope.DefineType(name, typesystem.TCon{Name: qualified, KindVal: kind}, w.currentModuleName)
+	}
+	headVars := make(map[string]bool)
+	for _, arg := range instanceArgs {
+		for _, tv := range arg.FreeTypeVariables() {
+			headVars[tv.Name] = true
+		}
+	}
+
+	defined := make(map[string]bool)
+	for _, at := range n.AssociatedTypes {
+		if at == nil || at.Name == nil {
+			continue
+		}
+		name := at.Name.Value
+		switch {
+		case !declared[name]:
+			w.addError(diagnostics.NewError(
+				diagnostics.ErrA003,
+				at.Name.GetToken(),
+				fmt.Sprintf("associated type %s is not part of trait %s", name, traitName),
+			))
+			continue
+		case defined[name]:
+			w.addError(diagnostics.NewError(
+				diagnostics.ErrA004,
+				at.Name.GetToken(),
+				fmt.Sprintf("associated type %s (already defined in instance %s for %s)", name, traitName, typeName),
+			))
+			continue
+		case at.Type == nil:
+			w.addError(diagnostics.NewError(
+				diagnostics.ErrA003,
+				at.Name.GetToken(),
+				fmt.Sprintf("associated type %s needs a definition in instance %s for %s: type %s = ...", name, traitName, typeName, name),
+			))
+			continue
+		}
+		defined[name] = true
+
+		t := BuildType(at.Type, scope, &w.errors)
+		for _, tv := range t.FreeTypeVariables() {
+			if !headVars[tv.Name] {
+				w.addError(diagnostics.NewError(
+					diagnostics.ErrA003,
+					at.Type.GetToken(),
+					fmt.Sprintf("type variable %s in associated type %s does not appear in the instance type", tv.Name, name),
+				))
+			}
+		}
+		w.symbolTable.RegisterInstanceAssociatedType(traitName, instanceArgs, name, t)
+	}
+
+	for _, name := range w.symbolTable.GetTraitAssociatedTypes(traitName) {
+		if !defined[name] {
+			w.addError(diagnostics.NewError(
+				diagnostics.ErrA003,
+				n.Token,
+				"instance "+traitName+" for "+typeName+" is missing associated type '"+name+"'",
+			))
+		}
+	}
+}
+
+// hideAssociatedTypes replaces the associated type applications in t with
+// Unknown. A value of type Elem<c> tells nothing about c at runtime, so
+// such an occurrence of c cannot select the instance a method dispatches to.
+func hideAssociatedTypes(t typesystem.Type, table *symbols.SymbolTable) typesystem.Type {
+	if !table.ContainsAssociatedType(t) {
+		return t
+	}
+	return typesystem.ReplaceTApp(t, func(app typesystem.TApp) (typesystem.Type, bool) {
+		if table.IsAssociatedType(app) {
+			return typesystem.TCon{Name: "Unknown"}, true
+		}
+		return nil, false
+	})
+}
@@ -108,6 +108,51 @@ func inferKindFromFunction(n *ast.FunctionStatement, tpName string, table *symbo
 	return kindFromUsage
 }
 
+// kindPolymorphicParams finds the type parameters of a function whose kind
+// nothing in the signature fixes, such as t in take(p: Proxy<t>), and gives
+// them a kind variable instead of *, so that they accept type constructors
+// as well as types. The substitution maps each of them to its new TVar.
+func kindPolymorphicParams(n *ast.FunctionStatement, fnType typesystem.TFunc, table *symbols.SymbolTable) typesystem.Subst {
+	constrained := make(map[string]bool)
+	for _, c := range n.Constraints {
+		constrained[c.TypeVar] = true
+		for _, arg := range c.Args {
+			if nt, ok := arg.(*ast.NamedType); ok {
+				constrained[nt.Name.Value] = true
+			}
+		}
+	}
+
+	// Rename the candidates to variables without a kind, so that the kind
+	// checker infers theirs instead of trusting the default *
+	probe := make(typesystem.Subst)
+	for _, tp := range n.TypeParams {
+		if tp.Kind == nil && !constrained[tp.Value] && inferKindFromFunction(n, tp.Value, table).Equal(typesystem.Star) {
+			probe[tp.Value] = typesystem.TVar{Name: "$kind_" + tp.Value}
+		}
+	}
+	if len(probe) == 0 {
+		return nil
+	}
+	ctx := typesystem.NewKindContext()
+	_, ks, err := typesystem.InferKind(fnType.Apply(probe), ctx)
+	if err != nil {
+		return nil
+	}
+
+	subst := make(typesystem.Subst)
+	for name, tv := range probe {
+		k, ok := ctx.KindVars[tv.(typesystem.TVar).Name]
+		if !ok {
+			continue
+		}
+		if kv, ok := typesystem.ApplyKindSubst(ks, k).(typesystem.KVar); ok {
+			subst[name] = typesystem.TVar{Name: name, KindVal: kv}
+		}
+	}
+	return subst
+}
+
 func (w *walker) VisitFunctionStatement(n *ast.FunctionStatement) {
 	// Skip if function was not properly parsed
 	if n == nil || n.Name == nil {
@@ -272,6 +317,15 @@ func (w *walker) VisitFunctionStatement(n *ast.FunctionStatement) {
 		))
 	}
 
+	if subst := kindPolymorphicParams(n, fnType, w.symbolTable); len(subst) > 0 {
+		if refined, ok := fnType.Apply(subst).(typesystem.TFunc); ok {
+			fnType = refined
+		}
+		for name, tv := range subst {
+			sigScope.DefineType(name, tv, "")
+		}
+	}
+
 	// Wrap in TForall if generics exist
 	var finalFnType typesystem.Type = fnType
 	if len(n.TypeParams) > 0 {
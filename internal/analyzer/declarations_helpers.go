@@ -77,6 +77,9 @@ func (w *walker) importTraitImplementations(importedTable *symbols.SymbolTable,
 
 				// Register the implementation in current table
 				_ = w.symbolTable.RegisterImplementation(actualTraitName, taggedArgs, def.Requirements, def.ConstructorName)
+				for atName, atType := range def.AssociatedTypes {
+					w.symbolTable.RegisterInstanceAssociatedType(actualTraitName, taggedArgs, atName, tagModule(atType, moduleName, exportedTypes))
+				}
 
 				// Import the evidence constant symbol so SolveWitness can find it
 				// We register it as an imported symbol mapping local name to remote name
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/funvibe/funxy/internal/ast"
 	"github.com/funvibe/funxy/internal/diagnostics"
+	"github.com/funvibe/funxy/internal/modules"
 	"github.com/funvibe/funxy/internal/symbols"
 	"github.com/funvibe/funxy/internal/typesystem"
 	"github.com/funvibe/funxy/internal/utils"
@@ -268,6 +269,17 @@ func (w *walker) VisitImportStatement(n *ast.ImportStatement) {
 						}
 					}
 
+					// Import associated types (type Elem)
+					if modSymTable := loadedMod.GetSymbolTable(); modSymTable != nil {
+						for _, atName := range modSymTable.GetTraitAssociatedTypes(symName) {
+							qualified := symbols.AssociatedTypeName(symName, atName)
+							if kind, ok := modSymTable.GetKind(qualified); ok {
+								w.symbolTable.RegisterKind(qualified, kind)
+							}
+							w.symbolTable.RegisterAssociatedType(atName, symName)
+						}
+					}
+
 					// Import trait methods linkage
 					if modSymTable := loadedMod.GetSymbolTable(); modSymTable != nil {
 						methods := modSymTable.GetTraitAllMethods(symName)
@@ -282,6 +294,11 @@ func (w *walker) VisitImportStatement(n *ast.ImportStatement) {
 							}
 						}
 					}
+
+					// Library traits come with instances for built-in types
+					if mod, ok := modInterface.(*modules.Module); ok && mod.IsVirtual {
+						registerLibraryTrait(w.symbolTable, symName)
+					}
 				} else {
 					w.symbolTable.Define(symName, taggedType, origin)
 				}
@@ -156,7 +156,7 @@ func (w *walker) VisitInstanceDeclaration(n *ast.InstanceDeclaration) {
 	var currentArgType typesystem.Type
 
 	for _, argNode := range n.Args {
-		t := BuildType(argNode, targetScope, &w.errors)
+		t := BuildInstanceHead(argNode, targetScope, &w.errors)
 
 		// If BuildType fails (returns nil), it has already reported an error.
 		// We skip this argument to avoid further nil pointer dereferences.
@@ -288,6 +288,10 @@ func (w *walker) VisitInstanceDeclaration(n *ast.InstanceDeclaration) {
 	var typeName string
 	if tCon, ok := targetType.(typesystem.TCon); ok {
 		typeName = tCon.Name
+	} else if lam, ok := targetType.(typesystem.TLambda); ok {
+		// Heads with holes keep them: Pair<_, Int> and Pair<_, String> differ
+		typeName = GetInstanceHeadName(lam)
+		n.AnalyzedHeadName = typeName
 	} else if tApp, ok := targetType.(typesystem.TApp); ok {
 		// Extract constructor name from app
 		if tCon, ok := tApp.Constructor.(typesystem.TCon); ok {
@@ -373,6 +377,10 @@ func (w *walker) VisitInstanceDeclaration(n *ast.InstanceDeclaration) {
 		})
 	}
 
+	// A requirement brings its super traits along, as for function constraints:
+	// Order Box<t: Order> also needs a dictionary for Equal t (for Equal Box<t>).
+	requirements = withSuperTraitRequirements(requirements, w.symbolTable)
+
 	// Check Functional Dependencies consistency
 	if deps, ok := w.symbolTable.GetTraitFunctionalDependencies(traitName); ok && len(deps) > 0 {
 		allImpls := w.symbolTable.GetAllImplementations()[traitName]
@@ -525,6 +533,7 @@ func (w *walker) VisitInstanceDeclaration(n *ast.InstanceDeclaration) {
 			))
 			return
 		}
+		w.defineAssociatedTypes(n, traitName, typeName, instanceArgs, targetScope)
 	}
 
 	// Check that all required methods are implemented
@@ -635,6 +644,32 @@ func (w *walker) VisitInstanceDeclaration(n *ast.InstanceDeclaration) {
 	w.visitInstanceMethods(n, traitName, typeName, instanceArgs, requirements, subst, outer)
 }
 
+// withSuperTraitRequirements adds the super traits of single-parameter
+// requirements (transitively), skipping ones already present.
+func withSuperTraitRequirements(requirements []typesystem.Constraint, table *symbols.SymbolTable) []typesystem.Constraint {
+	seen := make(map[string]bool)
+	for _, req := range requirements {
+		if len(req.Args) == 0 {
+			seen[req.TypeVar+"."+req.Trait] = true
+		}
+	}
+	for i := 0; i < len(requirements); i++ {
+		req := requirements[i]
+		if len(req.Args) > 0 {
+			continue
+		}
+		superTraits, _ := table.GetTraitSuperTraits(req.Trait)
+		for _, superTrait := range superTraits {
+			key := req.TypeVar + "." + superTrait
+			if !seen[key] {
+				seen[key] = true
+				requirements = append(requirements, typesystem.Constraint{TypeVar: req.TypeVar, Trait: superTrait})
+			}
+		}
+	}
+	return requirements
+}
+
 // CollectConstraintsFromType recursively finds constraints in AST Type nodes
 // (e.g. constraints attached to NamedType identifiers during parsing)
 func CollectConstraintsFromType(t ast.Type, requirements *[]typesystem.Constraint) {
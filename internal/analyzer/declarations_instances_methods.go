@@ -112,6 +112,8 @@ func (w *walker) visitInstanceMethods(n *ast.InstanceDeclaration, traitName, typ
 		// We need to specialize the polytype by applying the substitution to quantified variables if they match trait params.
 		// e.g. forall f a. ... with subst {f: Box} -> forall a. ... (where f is replaced by Box)
 		expectedType := specializePolytype(genericSymbol.Type, subst)
+		// Elem<IntBag> in the trait signature is the instance's own type Elem = Int
+		expectedType = w.symbolTable.ReduceAssociatedTypes(expectedType)
 
 		// Inject expected types into AST parameters if missing
 		// This helps VisitFunctionStatement to correctly infer parameter types
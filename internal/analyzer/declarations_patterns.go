@@ -193,6 +193,9 @@ func (w *walker) bindPatternVariablesLooseWithConstFlag(pat ast.Pattern, tok tok
 		for _, fieldPat := range p.Fields {
 			w.bindPatternVariablesLooseWithConstFlag(fieldPat, tok, isConstant)
 		}
+		if p.Rest != nil {
+			w.bindPatternVariablesLooseWithConstFlag(p.Rest, tok, isConstant)
+		}
 
 	case *ast.SpreadPattern:
 		if p.Pattern != nil {
@@ -54,6 +54,7 @@ func RegisterTypeDeclaration(stmt *ast.TypeDeclarationStatement, table *symbols.
 		var kVar typesystem.Kind
 		if tp.Kind != nil {
 			kVar = tp.Kind
+			kindContext.MarkLocal(kVar)
 		} else {
 			kVar = kindContext.FreshKVar()
 		}
@@ -130,14 +131,12 @@ func RegisterTypeDeclaration(stmt *ast.TypeDeclarationStatement, table *symbols.
 	// But 'kind' variable was calculated using old resultKind (Star).
 	// Recalculate kind using resultKind (which might be updated kBody).
 
-	// Apply substitution to paramKinds first
+	// Apply substitution to paramKinds first. A parameter whose kind the body
+	// does not determine (e.g. t in type Proxy<t> = Proxy) keeps its kind
+	// variable, so the type is kind-polymorphic: Proxy<Int> and Proxy<List>.
 	finalKinds := make([]typesystem.Kind, len(paramKinds))
 	for i, k := range paramKinds {
-		resolved := typesystem.ApplyKindSubst(subst, k)
-		if _, isVar := resolved.(typesystem.KVar); isVar {
-			resolved = typesystem.Star
-		}
-		finalKinds[i] = resolved
+		finalKinds[i] = typesystem.ApplyKindSubst(subst, k)
 	}
 
 	// Update resultKind with substitution too
@@ -513,6 +512,9 @@ func (w *walker) VisitTraitDeclaration(n *ast.TraitDeclaration) {
 		outer.RegisterKind(n.Name.Value, traitKind)
 	}
 
+	// Associated types take the trait parameters: type Elem is used as Elem<c>
+	w.declareAssociatedTypes(n, traitTypeVars, outer, w.symbolTable)
+
 	for _, method := range n.Signatures {
 		// Create a temporary scope for method type parameters
 		methodScope := symbols.NewEnclosedSymbolTable(w.symbolTable, symbols.ScopeFunction)
@@ -644,9 +646,9 @@ func (w *walker) VisitTraitDeclaration(n *ast.TraitDeclaration) {
 		for _, typeParam := range typeParamNames {
 			source := typesystem.DispatchSource{Kind: typesystem.DispatchHint, Index: -1}
 
-			// 1. Check arguments
+			// 1. Check arguments (not inside associated types such as Elem<c>)
 			for i, paramType := range methodType.Params {
-				if containsTypeVar(paramType, typeParam) {
+				if containsTypeVar(hideAssociatedTypes(paramType, outer), typeParam) {
 					source = typesystem.DispatchSource{Kind: typesystem.DispatchArg, Index: i}
 					break
 				}
@@ -654,7 +656,7 @@ func (w *walker) VisitTraitDeclaration(n *ast.TraitDeclaration) {
 
 			// 2. Check return type if not found in args
 			if source.Kind == typesystem.DispatchHint {
-				if containsTypeVar(methodType.ReturnType, typeParam) {
+				if containsTypeVar(hideAssociatedTypes(methodType.ReturnType, outer), typeParam) {
 					source = typesystem.DispatchSource{Kind: typesystem.DispatchReturn, Index: -1}
 				}
 			}
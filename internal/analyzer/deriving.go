@@ -0,0 +1,816 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/diagnostics"
+	"github.com/funvibe/funxy/internal/symbols"
+	"github.com/funvibe/funxy/internal/token"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// derivableTraits lists the traits a deriving clause can name, in the order
+// their instances are generated: Equal comes before Order, its super trait.
+var derivableTraits = []string{"Equal", "Order", "Default", "Show", "Hash", "ToJson"}
+
+// libraryTraits maps the derivable traits that are not in the prelude to the
+// package that defines them. They can be derived once they are imported.
+var libraryTraits = map[string]string{"Hash": "lib/crypto", "ToJson": "lib/json"}
+
+// deriveInstances expands the deriving clauses of the program's type
+// declarations into instance declarations. They are kept in the Derived list
+// of their type rather than in the program, so tooling sees only what was
+// written; the instance pass visits them right after their type and both
+// backends evaluate them from there.
+func (w *walker) deriveInstances(program *ast.Program) {
+	var declared map[string]bool
+	for _, stmt := range program.Statements {
+		td, ok := stmt.(*ast.TypeDeclarationStatement)
+		if !ok || td == nil || td.Name == nil || len(td.Deriving) == 0 || td.Derived != nil {
+			continue
+		}
+		if declared == nil {
+			declared = declaredInstances(program)
+		}
+		td.Derived = w.deriveTypeInstances(td, declared)
+	}
+}
+
+// visitDerivedInstances visits the instances derived for stmt, if it is a
+// type declaration with a deriving clause.
+func (w *walker) visitDerivedInstances(stmt ast.Statement) {
+	if td, ok := stmt.(*ast.TypeDeclarationStatement); ok && td != nil {
+		for _, inst := range td.Derived {
+			w.visit(inst)
+		}
+	}
+}
+
+// declaredInstances collects "Trait Type" keys for the instances a program
+// declares or derives. The instance pass registers them in statement order,
+// so a field type may rely on an instance that is not registered yet.
+func declaredInstances(program *ast.Program) map[string]bool {
+	declared := make(map[string]bool)
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *ast.InstanceDeclaration:
+			if s.TraitName == nil || len(s.Args) != 1 {
+				continue
+			}
+			if nt, ok := s.Args[0].(*ast.NamedType); ok && nt.Name != nil {
+				declared[s.TraitName.Value+" "+nt.Name.Value] = true
+			}
+		case *ast.TypeDeclarationStatement:
+			if s.Name == nil {
+				continue
+			}
+			for _, d := range s.Deriving {
+				declared[d.Value+" "+s.Name.Value] = true
+			}
+		}
+	}
+	return declared
+}
+
+func (w *walker) deriveTypeInstances(td *ast.TypeDeclarationStatement, declared map[string]bool) []*ast.InstanceDeclaration {
+	derived := []*ast.InstanceDeclaration{}
+
+	requested := make(map[string]*ast.Identifier)
+	for _, id := range td.Deriving {
+		if !isDerivable(id.Value) {
+			w.addError(diagnostics.NewError(diagnostics.ErrA003, id.Token,
+				fmt.Sprintf("cannot derive %s for %s: only Equal, Order, Default, Show, Hash and ToJson can be derived", id.Value, td.Name.Value)))
+			continue
+		}
+		if pkg, ok := libraryTraits[id.Value]; ok && !w.importsLibraryTrait(id.Value, pkg) {
+			w.addError(diagnostics.NewError(diagnostics.ErrA003, id.Token,
+				fmt.Sprintf("cannot derive %s for %s: import %s from %s", id.Value, td.Name.Value, id.Value, pkg)))
+			continue
+		}
+		if requested[id.Value] != nil {
+			w.addError(diagnostics.NewError(diagnostics.ErrA003, id.Token,
+				fmt.Sprintf("%s is derived twice for %s", id.Value, td.Name.Value)))
+			continue
+		}
+		requested[id.Value] = id
+	}
+
+	var record *ast.RecordType
+	if td.IsAlias {
+		rec, ok := td.TargetType.(*ast.RecordType)
+		if !ok || rec.Row != nil {
+			w.addError(diagnostics.NewError(diagnostics.ErrA003, td.Deriving[0].Token,
+				fmt.Sprintf("cannot derive instances for %s: only algebraic data types and closed record aliases can derive", td.Name.Value)))
+			return derived
+		}
+		record = rec
+	}
+
+	for _, trait := range derivableTraits {
+		id := requested[trait]
+		if id == nil {
+			continue
+		}
+		d := w.newDerivation(td, id.Token, declared, record)
+		if w.checkDerivation(d, trait) {
+			derived = append(derived, d.instance(trait))
+		}
+	}
+	return derived
+}
+
+// importsLibraryTrait reports whether name refers to the trait imported from
+// the library package pkg, rather than to nothing or to a trait of the program.
+func (w *walker) importsLibraryTrait(name, pkg string) bool {
+	sym, ok := w.symbolTable.Find(name)
+	return ok && sym.Kind == symbols.TraitSymbol && "lib/"+sym.OriginModule == pkg
+}
+
+func isDerivable(trait string) bool {
+	for _, t := range derivableTraits {
+		if t == trait {
+			return true
+		}
+	}
+	return false
+}
+
+// derivation generates one derived instance of a type declaration.
+type derivation struct {
+	decl     *ast.TypeDeclarationStatement
+	record   *ast.RecordType      // Target of a record alias, nil for ADTs
+	tok      token.Token          // The trait name in the deriving clause; generated nodes point here
+	scope    *symbols.SymbolTable // Resolves the type parameters
+	params   map[string]bool
+	values   map[string]bool // Type parameters of kind *, which can hold values
+	declared map[string]bool
+}
+
+func (w *walker) newDerivation(td *ast.TypeDeclarationStatement, tok token.Token, declared map[string]bool, record *ast.RecordType) *derivation {
+	d := &derivation{
+		decl:     td,
+		record:   record,
+		tok:      tok,
+		scope:    symbols.NewEnclosedSymbolTable(w.symbolTable, symbols.ScopeFunction),
+		params:   make(map[string]bool),
+		values:   make(map[string]bool),
+		declared: declared,
+	}
+
+	// Parameter kinds come from the kind of the type constructor
+	kind, _ := w.symbolTable.GetKind(td.Name.Value)
+	for _, tp := range td.TypeParameters {
+		var paramKind typesystem.Kind = typesystem.Star
+		if arrow, ok := kind.(typesystem.KArrow); ok {
+			paramKind, kind = arrow.Left, arrow.Right
+		}
+		d.scope.DefineType(tp.Value, typesystem.TVar{Name: tp.Value, KindVal: paramKind}, "")
+		d.scope.RegisterKind(tp.Value, paramKind)
+		d.params[tp.Value] = true
+		if _, isStar := paramKind.(typesystem.KStar); isStar {
+			d.values[tp.Value] = true
+		}
+	}
+	return d
+}
+
+// checkDerivation reports the first field whose type keeps trait from being
+// derived.
+func (w *walker) checkDerivation(d *derivation, trait string) bool {
+	check := func(field string, t ast.Type) bool {
+		gap, reason := d.gap(trait, t)
+		if gap == nil {
+			return true
+		}
+		msg := fmt.Sprintf("cannot derive %s for %s: %s has type %s, ", trait, d.decl.Name.Value, field, d.typeString(t))
+		if gap == t {
+			msg += "which " + reason
+		} else {
+			msg += "and " + d.typeString(gap) + " " + reason
+		}
+		w.addError(diagnostics.NewError(diagnostics.ErrA003, d.tok, msg))
+		return false
+	}
+
+	if d.record != nil {
+		for _, name := range fieldNames(d.record.Fields) {
+			if !check("field "+name, d.record.Fields[name]) {
+				return false
+			}
+		}
+		return true
+	}
+	constructors := d.decl.Constructors
+	if trait == "Default" && len(constructors) > 0 {
+		// Only the first constructor is built
+		constructors = constructors[:1]
+	}
+	for _, c := range constructors {
+		for i, p := range c.Parameters {
+			if !check(fmt.Sprintf("field %d of %s", i+1, c.Name.Value), p) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// gap returns the part of field type t that has no instance of trait,
+// with the reason, or nil when the derived code can rely on one.
+func (d *derivation) gap(trait string, t ast.Type) (ast.Type, string) {
+	missing := "does not implement " + trait
+	switch t := t.(type) {
+	case *ast.NamedType:
+		return d.namedGap(trait, t)
+	case *ast.TupleType:
+		if trait != "Default" && !d.implemented(trait, t) {
+			return t, missing
+		}
+		for _, el := range t.Types {
+			if gap, reason := d.gap(trait, el); gap != nil {
+				return gap, reason
+			}
+		}
+		return nil, ""
+	case *ast.RecordType:
+		// Anonymous records compare and display structurally, but have no order
+		if trait == "Order" || t.Row != nil {
+			return t, missing
+		}
+		for _, name := range fieldNames(t.Fields) {
+			if gap, reason := d.gap(trait, t.Fields[name]); gap != nil {
+				return gap, reason
+			}
+		}
+		return nil, ""
+	case *ast.UnionType:
+		// Nullable types (T?) default to nil and compare and display as nil
+		hasNil := false
+		for _, member := range t.Types {
+			hasNil = hasNil || isNilTypeName(member)
+		}
+		if trait == "Default" && hasNil {
+			return nil, ""
+		}
+		if trait == "Default" || trait == "Order" {
+			return t, missing
+		}
+		for _, member := range t.Types {
+			if isNilTypeName(member) {
+				continue
+			}
+			if gap, reason := d.gap(trait, member); gap != nil {
+				return gap, reason
+			}
+		}
+		return nil, ""
+	}
+	return t, missing
+}
+
+func isNilTypeName(t ast.Type) bool {
+	nt, ok := t.(*ast.NamedType)
+	return ok && nt.Name != nil && nt.Name.Value == "Nil" && len(nt.Args) == 0
+}
+
+func (d *derivation) namedGap(trait string, t *ast.NamedType) (ast.Type, string) {
+	name := t.Name.Value
+	if d.params[name] {
+		if len(t.Args) > 0 {
+			return t, "applies a type parameter to arguments"
+		}
+		if trait == "Default" {
+			return t, "is a type parameter with no known default"
+		}
+		return nil, ""
+	}
+
+	if trait == "Default" {
+		// The default is built with default(T), so T must be a runtime type value
+		if name == d.decl.Name.Value {
+			return t, "is the type being derived"
+		}
+		if !d.declared[trait+" "+name] && !d.implemented(trait, t) {
+			return t, "does not implement Default"
+		}
+		for _, arg := range t.Args {
+			if gap, reason := d.typeValueGap(arg); gap != nil {
+				return gap, reason
+			}
+		}
+		return nil, ""
+	}
+
+	if name != d.decl.Name.Value && !d.declared[trait+" "+name] && !d.implemented(trait, t) {
+		return t, "does not implement " + trait
+	}
+	for _, arg := range t.Args {
+		// Higher-kinded arguments such as Option in App<Option, Int> hold no values
+		var errs []*diagnostics.DiagnosticError
+		if _, isStar := BuildType(arg, d.scope, &errs).Kind().(typesystem.KStar); !isStar {
+			continue
+		}
+		if gap, reason := d.gap(trait, arg); gap != nil {
+			return gap, reason
+		}
+	}
+	return nil, ""
+}
+
+// typeValueGap checks that a type argument of default(T) can be written as
+// a runtime type value, which holds no type parameters.
+func (d *derivation) typeValueGap(t ast.Type) (ast.Type, string) {
+	nt, ok := t.(*ast.NamedType)
+	if !ok {
+		return t, "cannot be passed to default as a type argument"
+	}
+	if d.params[nt.Name.Value] {
+		return t, "is a type parameter with no known default"
+	}
+	for _, arg := range nt.Args {
+		if gap, reason := d.typeValueGap(arg); gap != nil {
+			return gap, reason
+		}
+	}
+	return nil, ""
+}
+
+// implemented reports whether an instance of trait is registered for t. Types
+// that fail to build were already reported with the type declaration.
+func (d *derivation) implemented(trait string, t ast.Type) bool {
+	var errs []*diagnostics.DiagnosticError
+	typ := BuildType(t, d.scope, &errs)
+	if len(errs) > 0 {
+		return true
+	}
+	return d.scope.IsImplementationExists(trait, []typesystem.Type{typ})
+}
+
+func (d *derivation) typeString(t ast.Type) string {
+	var errs []*diagnostics.DiagnosticError
+	return BuildType(t, d.scope, &errs).String()
+}
+
+// fieldNames lists record fields in declaration order, which the parsed map
+// loses; it is recovered from the positions of the field types, as the
+// formatter does. Fields without positions go last, by name.
+func fieldNames(fields map[string]ast.Type) []string {
+	names := make([]string, 0, len(fields))
+	pos := make(map[string]token.Token, len(fields))
+	for name, t := range fields {
+		names = append(names, name)
+		pos[name] = firstToken(t)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := pos[names[i]], pos[names[j]]
+		if (a.Line > 0) != (b.Line > 0) {
+			return a.Line > 0
+		}
+		if a.Line != b.Line || a.Column != b.Column {
+			return tokenBefore(a, b)
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// firstToken returns the earliest positioned token of t.
+func firstToken(t ast.Type) token.Token {
+	var first token.Token
+	ast.Inspect(t, func(n ast.Node) bool {
+		if tp, ok := n.(ast.TokenProvider); ok {
+			if tok := tp.GetToken(); tok.Line > 0 && (first.Line == 0 || tokenBefore(tok, first)) {
+				first = tok
+			}
+		}
+		return true
+	})
+	return first
+}
+
+func tokenBefore(a, b token.Token) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+}
+
+// instance builds the derived instance declaration. Type parameters of kind *
+// carry the trait as a constraint, as in a written instance: Equal Box<t: Equal>.
+func (d *derivation) instance(trait string) *ast.InstanceDeclaration {
+	inst := &ast.InstanceDeclaration{
+		Token:     d.tok,
+		TraitName: d.ident(trait),
+		Args:      []ast.Type{d.selfType()},
+	}
+	for _, tp := range d.decl.TypeParameters {
+		if d.values[tp.Value] {
+			inst.Constraints = append(inst.Constraints, &ast.TypeConstraint{TypeVar: tp.Value, Trait: trait})
+		}
+	}
+
+	a, b := d.ref(d.local("a")), d.ref(d.local("b"))
+	switch trait {
+	case "Equal":
+		inst.Methods = []*ast.FunctionStatement{
+			d.operator("==", d.equalBody()),
+			d.operator("!=", d.not(d.infix(a, "==", b))),
+		}
+	case "Order":
+		// All four operators, since not every backend derives > <= >= from <
+		inst.Methods = []*ast.FunctionStatement{
+			d.operator("<", d.lessBody()),
+			d.operator(">", d.infix(b, "<", a)),
+			d.operator("<=", d.not(d.infix(b, "<", a))),
+			d.operator(">=", d.not(d.infix(a, "<", b))),
+		}
+	case "Default":
+		inst.Methods = []*ast.FunctionStatement{d.method("getDefault", "d", d.selfType(), d.defaultBody())}
+	case "Show":
+		inst.Methods = []*ast.FunctionStatement{d.method("show", "a", d.namedType("String"), d.showBody())}
+	case "Hash":
+		inst.Methods = []*ast.FunctionStatement{d.method("hash", "a", d.namedType("Int"), d.hashBody())}
+	case "ToJson":
+		inst.Methods = []*ast.FunctionStatement{d.method("toJson", "a", d.namedType("String"), d.toJsonBody())}
+	}
+	return inst
+}
+
+// equalBody compares constructors and then their fields pairwise:
+// match (a, b) { (C(x0, x1), C(y0, y1)) -> x0 == y0 && x1 == y1, _ -> false }
+func (d *derivation) equalBody() ast.Expression {
+	if d.record != nil {
+		var conds []ast.Expression
+		for _, name := range fieldNames(d.record.Fields) {
+			conds = append(conds, d.infix(d.member("a", name), "==", d.member("b", name)))
+		}
+		return d.conjunction(conds)
+	}
+
+	var arms []*ast.MatchArm
+	for _, c := range d.decl.Constructors {
+		xs, ys := d.fieldVars("x", c), d.fieldVars("y", c)
+		var conds []ast.Expression
+		for i := range xs {
+			conds = append(conds, d.infix(xs[i], "==", ys[i]))
+		}
+		arms = append(arms, d.pairArm(c, xs, ys, d.conjunction(conds)))
+	}
+	return d.matchPair(arms, &ast.BooleanLiteral{Token: d.tok, Value: false})
+}
+
+// lessBody orders by constructor, in declaration order, and then by fields
+// lexicographically; records compare their fields in declaration order.
+func (d *derivation) lessBody() ast.Expression {
+	if d.record != nil {
+		var xs, ys []ast.Expression
+		for _, name := range fieldNames(d.record.Fields) {
+			xs = append(xs, d.member("a", name))
+			ys = append(ys, d.member("b", name))
+		}
+		return d.lexLess(xs, ys)
+	}
+
+	var arms []*ast.MatchArm
+	for _, c := range d.decl.Constructors {
+		xs, ys := d.fieldVars("x", c), d.fieldVars("y", c)
+		arms = append(arms, d.pairArm(c, xs, ys, d.lexLess(xs, ys)))
+	}
+	return d.matchPair(arms, d.infix(d.constructorIndex(d.ref(d.local("a"))), "<", d.constructorIndex(d.ref(d.local("b")))))
+}
+
+// lexLess builds x0 < y0 || x0 == y0 && (x1 < y1 || ...).
+func (d *derivation) lexLess(xs, ys []ast.Expression) ast.Expression {
+	if len(xs) == 0 {
+		return &ast.BooleanLiteral{Token: d.tok, Value: false}
+	}
+	less := d.infix(xs[0], "<", ys[0])
+	if len(xs) == 1 {
+		return less
+	}
+	rest := d.infix(d.infix(xs[0], "==", ys[0]), "&&", d.lexLess(xs[1:], ys[1:]))
+	return d.infix(less, "||", rest)
+}
+
+// constructorIndex maps a value to the position of its constructor.
+func (d *derivation) constructorIndex(v ast.Expression) ast.Expression {
+	match := &ast.MatchExpression{Token: d.tok, Expression: v}
+	for i, c := range d.decl.Constructors {
+		var wildcards []ast.Pattern
+		for range c.Parameters {
+			wildcards = append(wildcards, &ast.WildcardPattern{Token: d.tok})
+		}
+		match.Arms = append(match.Arms, &ast.MatchArm{
+			Pattern:    &ast.ConstructorPattern{Token: d.tok, Name: d.ident(c.Name.Value), Elements: wildcards},
+			Expression: &ast.IntegerLiteral{Token: d.tok, Value: int64(i)},
+		})
+	}
+	return match
+}
+
+// defaultBody builds the first constructor (or the record) from the
+// defaults of its field types.
+func (d *derivation) defaultBody() ast.Expression {
+	if d.record != nil {
+		return d.defaultValue(d.record)
+	}
+	c := d.decl.Constructors[0]
+	if len(c.Parameters) == 0 {
+		return d.ref(c.Name.Value)
+	}
+	call := &ast.CallExpression{Token: d.tok, Function: d.ref(c.Name.Value)}
+	for _, p := range c.Parameters {
+		call.Arguments = append(call.Arguments, d.defaultValue(p))
+	}
+	return call
+}
+
+func (d *derivation) defaultValue(t ast.Type) ast.Expression {
+	switch t := t.(type) {
+	case *ast.TupleType:
+		tuple := &ast.TupleLiteral{Token: d.tok}
+		for _, el := range t.Types {
+			tuple.Elements = append(tuple.Elements, d.defaultValue(el))
+		}
+		return tuple
+	case *ast.RecordType:
+		record := &ast.RecordLiteral{Token: d.tok, Fields: make(map[string]ast.Expression)}
+		for name, ft := range t.Fields {
+			record.Fields[name] = d.defaultValue(ft)
+		}
+		return record
+	case *ast.UnionType:
+		return &ast.NilLiteral{Token: d.tok}
+	case *ast.NamedType:
+		return d.call("default", d.typeValue(t))
+	}
+	return &ast.NilLiteral{Token: d.tok}
+}
+
+// typeValue writes a type as a runtime type value: Map<String, Int> is Map(String, Int).
+func (d *derivation) typeValue(t *ast.NamedType) ast.Expression {
+	if len(t.Args) == 0 {
+		return d.ref(t.Name.Value)
+	}
+	call := &ast.CallExpression{Token: d.tok, Function: d.ref(t.Name.Value)}
+	for _, arg := range t.Args {
+		call.Arguments = append(call.Arguments, d.typeValue(arg.(*ast.NamedType)))
+	}
+	return call
+}
+
+// showBody renders values in constructor syntax, C(1, "s"), and records as
+// {x: 1, s: "s"} with fields in declaration order.
+func (d *derivation) showBody() ast.Expression {
+	if d.record != nil {
+		parts := []ast.Expression{d.str("{")}
+		for i, name := range fieldNames(d.record.Fields) {
+			if i > 0 {
+				parts = append(parts, d.str(", "))
+			}
+			parts = append(parts, d.str(name+": "), d.showField(d.member("a", name), d.record.Fields[name]))
+		}
+		return d.concat(append(parts, d.str("}")))
+	}
+
+	match := &ast.MatchExpression{Token: d.tok, Expression: d.ref(d.local("a"))}
+	for _, c := range d.decl.Constructors {
+		xs := d.fieldVars("x", c)
+		var body ast.Expression = d.str(c.Name.Value)
+		if len(xs) > 0 {
+			parts := []ast.Expression{d.str(c.Name.Value + "(")}
+			for i, x := range xs {
+				if i > 0 {
+					parts = append(parts, d.str(", "))
+				}
+				parts = append(parts, d.showField(x, c.Parameters[i]))
+			}
+			body = d.concat(append(parts, d.str(")")))
+		}
+		match.Arms = append(match.Arms, &ast.MatchArm{Pattern: d.constructorPattern(c, xs), Expression: body})
+	}
+	return match
+}
+
+// hashBody mixes the constructor index with the field hashes:
+// match a { C(x0, x1) -> (i * 31 + hash(x0)) * 31 + hash(x1) }. Equal values
+// get equal hashes, since derived Equal compares the same fields.
+func (d *derivation) hashBody() ast.Expression {
+	if d.record != nil {
+		var xs []ast.Expression
+		for _, name := range fieldNames(d.record.Fields) {
+			xs = append(xs, d.member("a", name))
+		}
+		return d.mixHashes(&ast.IntegerLiteral{Token: d.tok, Value: 0}, xs)
+	}
+
+	match := &ast.MatchExpression{Token: d.tok, Expression: d.ref(d.local("a"))}
+	for i, c := range d.decl.Constructors {
+		xs := d.fieldVars("x", c)
+		body := d.mixHashes(&ast.IntegerLiteral{Token: d.tok, Value: int64(i)}, xs)
+		match.Arms = append(match.Arms, &ast.MatchArm{Pattern: d.constructorPattern(c, xs), Expression: body})
+	}
+	return match
+}
+
+func (d *derivation) mixHashes(h ast.Expression, xs []ast.Expression) ast.Expression {
+	for _, x := range xs {
+		h = d.infix(d.infix(h, "*", &ast.IntegerLiteral{Token: d.tok, Value: 31}), "+", d.call("hash", x))
+	}
+	return h
+}
+
+// toJsonBody writes records as JSON objects and constructors in the shape
+// jsonEncode uses, {"_type": "C", "_fields": [1, "s"]}, so that jsonDecode
+// reads the text back.
+func (d *derivation) toJsonBody() ast.Expression {
+	if d.record != nil {
+		parts := []ast.Expression{d.str("{")}
+		for i, name := range fieldNames(d.record.Fields) {
+			if i > 0 {
+				parts = append(parts, d.str(","))
+			}
+			parts = append(parts, d.str("\""+name+"\":"), d.call("toJson", d.member("a", name)))
+		}
+		return d.concat(append(parts, d.str("}")))
+	}
+
+	match := &ast.MatchExpression{Token: d.tok, Expression: d.ref(d.local("a"))}
+	for _, c := range d.decl.Constructors {
+		xs := d.fieldVars("x", c)
+		parts := []ast.Expression{d.str("{\"_type\":\"" + c.Name.Value + "\"")}
+		if len(xs) > 0 {
+			parts = append(parts, d.str(",\"_fields\":["))
+			for i, x := range xs {
+				if i > 0 {
+					parts = append(parts, d.str(","))
+				}
+				parts = append(parts, d.call("toJson", x))
+			}
+			parts = append(parts, d.str("]"))
+		}
+		body := d.concat(append(parts, d.str("}")))
+		match.Arms = append(match.Arms, &ast.MatchArm{Pattern: d.constructorPattern(c, xs), Expression: body})
+	}
+	return match
+}
+
+// showField quotes String fields, whose show is the bare text.
+func (d *derivation) showField(x ast.Expression, t ast.Type) ast.Expression {
+	if nt, ok := t.(*ast.NamedType); ok && nt.Name.Value == "String" && len(nt.Args) == 0 {
+		return d.concat([]ast.Expression{d.str("\""), x, d.str("\"")})
+	}
+	return d.call("show", x)
+}
+
+// concat joins parts with ++, merging adjacent string literals.
+func (d *derivation) concat(parts []ast.Expression) ast.Expression {
+	var merged []ast.Expression
+	for _, p := range parts {
+		if s, ok := p.(*ast.StringLiteral); ok && len(merged) > 0 {
+			if last, ok := merged[len(merged)-1].(*ast.StringLiteral); ok {
+				merged[len(merged)-1] = d.str(last.Value + s.Value)
+				continue
+			}
+		}
+		merged = append(merged, p)
+	}
+	result := merged[0]
+	for _, p := range merged[1:] {
+		result = d.infix(result, "++", p)
+	}
+	return result
+}
+
+// --- AST construction helpers ---
+
+func (d *derivation) ident(name string) *ast.Identifier {
+	return &ast.Identifier{Token: d.tok, Value: name}
+}
+
+// local names a generated variable so that it does not shadow a type
+// parameter, which the body would otherwise resolve as a type.
+func (d *derivation) local(name string) string {
+	for d.params[name] {
+		name += "_"
+	}
+	return name
+}
+
+func (d *derivation) ref(name string) ast.Expression {
+	return d.ident(name)
+}
+
+func (d *derivation) str(s string) ast.Expression {
+	return &ast.StringLiteral{Token: d.tok, Value: s}
+}
+
+func (d *derivation) namedType(name string) *ast.NamedType {
+	return &ast.NamedType{Token: d.tok, Name: d.ident(name)}
+}
+
+// selfType is the instance head: T<p1, ..., pn>.
+func (d *derivation) selfType() ast.Type {
+	self := d.namedType(d.decl.Name.Value)
+	for _, tp := range d.decl.TypeParameters {
+		self.Args = append(self.Args, d.namedType(tp.Value))
+	}
+	return self
+}
+
+func (d *derivation) infix(left ast.Expression, op string, right ast.Expression) ast.Expression {
+	return &ast.InfixExpression{Token: d.tok, Left: left, Operator: op, Right: right}
+}
+
+func (d *derivation) not(e ast.Expression) ast.Expression {
+	return &ast.PrefixExpression{Token: d.tok, Operator: "!", Right: e}
+}
+
+func (d *derivation) call(fn string, args ...ast.Expression) ast.Expression {
+	return &ast.CallExpression{Token: d.tok, Function: d.ref(fn), Arguments: args}
+}
+
+func (d *derivation) member(v, field string) ast.Expression {
+	return &ast.MemberExpression{Token: d.tok, Left: d.ref(d.local(v)), Member: d.ident(field)}
+}
+
+// conjunction joins conditions with &&; no conditions hold trivially.
+func (d *derivation) conjunction(conds []ast.Expression) ast.Expression {
+	if len(conds) == 0 {
+		return &ast.BooleanLiteral{Token: d.tok, Value: true}
+	}
+	result := conds[len(conds)-1]
+	for i := len(conds) - 2; i >= 0; i-- {
+		result = d.infix(conds[i], "&&", result)
+	}
+	return result
+}
+
+// fieldVars names the fields of constructor c: x0, x1, ...
+func (d *derivation) fieldVars(prefix string, c *ast.DataConstructor) []ast.Expression {
+	var vars []ast.Expression
+	for i := range c.Parameters {
+		vars = append(vars, d.ref(d.local(fmt.Sprintf("%s%d", prefix, i))))
+	}
+	return vars
+}
+
+func (d *derivation) constructorPattern(c *ast.DataConstructor, vars []ast.Expression) ast.Pattern {
+	pattern := &ast.ConstructorPattern{Token: d.tok, Name: d.ident(c.Name.Value)}
+	for _, v := range vars {
+		pattern.Elements = append(pattern.Elements, &ast.IdentifierPattern{Token: d.tok, Value: v.(*ast.Identifier).Value})
+	}
+	return pattern
+}
+
+func (d *derivation) pairArm(c *ast.DataConstructor, xs, ys []ast.Expression, body ast.Expression) *ast.MatchArm {
+	return &ast.MatchArm{
+		Pattern:    &ast.TuplePattern{Token: d.tok, Elements: []ast.Pattern{d.constructorPattern(c, xs), d.constructorPattern(c, ys)}},
+		Expression: body,
+	}
+}
+
+// matchPair matches (a, b) against arms; values built by different
+// constructors fall through to otherwise.
+func (d *derivation) matchPair(arms []*ast.MatchArm, otherwise ast.Expression) ast.Expression {
+	if len(d.decl.Constructors) > 1 {
+		arms = append(arms, &ast.MatchArm{Pattern: &ast.WildcardPattern{Token: d.tok}, Expression: otherwise})
+	}
+	return &ast.MatchExpression{
+		Token:      d.tok,
+		Expression: &ast.TupleLiteral{Token: d.tok, Elements: []ast.Expression{d.ref(d.local("a")), d.ref(d.local("b"))}},
+		Arms:       arms,
+	}
+}
+
+func (d *derivation) block(body ast.Expression) *ast.BlockStatement {
+	return &ast.BlockStatement{
+		Token:      d.tok,
+		Statements: []ast.Statement{&ast.ExpressionStatement{Token: d.tok, Expression: body}},
+	}
+}
+
+// operator builds operator (op)(a: T, b: T) -> Bool { body }.
+func (d *derivation) operator(op string, body ast.Expression) *ast.FunctionStatement {
+	return &ast.FunctionStatement{
+		Token:    d.tok,
+		Name:     d.ident("(" + op + ")"),
+		Operator: op,
+		Parameters: []*ast.Parameter{
+			{Token: d.tok, Name: d.ident(d.local("a")), Type: d.selfType()},
+			{Token: d.tok, Name: d.ident(d.local("b")), Type: d.selfType()},
+		},
+		ReturnType: d.namedType("Bool"),
+		Body:       d.block(body),
+	}
+}
+
+// method builds fun name(param: T) -> ret { body }.
+func (d *derivation) method(name, param string, ret ast.Type, body ast.Expression) *ast.FunctionStatement {
+	return &ast.FunctionStatement{
+		Token:      d.tok,
+		Name:       d.ident(name),
+		Parameters: []*ast.Parameter{{Token: d.tok, Name: d.ident(d.local(param)), Type: d.selfType()}},
+		ReturnType: ret,
+		Body:       d.block(body),
+	}
+}
@@ -535,19 +535,7 @@ func getMissingPatterns(t typesystem.Type, patterns []ast.Pattern, table *symbol
 
 		variants, ok := table.GetVariants(t.Name)
 		if ok {
-			missing := []string{}
-			covered := make(map[string]bool)
-			for _, p := range patterns {
-				if cp, ok := p.(*ast.ConstructorPattern); ok {
-					covered[cp.Name.Value] = true
-				}
-			}
-			for _, v := range variants {
-				if !covered[v] {
-					missing = append(missing, v)
-				}
-			}
-			if len(missing) > 0 {
+			if missing := uncoveredVariants(variants, patterns); len(missing) > 0 {
 				return fmt.Sprintf("%v", missing)
 			}
 			return "some pattern combinations"
@@ -565,19 +553,7 @@ func getMissingPatterns(t typesystem.Type, patterns []ast.Pattern, table *symbol
 
 			variants, ok := table.GetVariants(constructor.Name)
 			if ok {
-				missing := []string{}
-				covered := make(map[string]bool)
-				for _, p := range patterns {
-					if cp, ok := p.(*ast.ConstructorPattern); ok {
-						covered[cp.Name.Value] = true
-					}
-				}
-				for _, v := range variants {
-					if !covered[v] {
-						missing = append(missing, v)
-					}
-				}
-				if len(missing) > 0 {
+				if missing := uncoveredVariants(variants, patterns); len(missing) > 0 {
 					return fmt.Sprintf("%v", missing)
 				}
 			}
@@ -636,6 +612,54 @@ func getMissingPatterns(t typesystem.Type, patterns []ast.Pattern, table *symbol
 	return "_ (catch-all pattern)"
 }
 
+// MissingConstructors returns the constructors of an ADT match target that no
+// arm matches at the top level. It returns nil for targets that are not ADTs.
+func MissingConstructors(n *ast.MatchExpression, targetType typesystem.Type, table *symbols.SymbolTable) []string {
+	patterns := make([]ast.Pattern, len(n.Arms))
+	for i, c := range n.Arms {
+		patterns[i] = c.Pattern
+	}
+
+	realType := resolveType(targetType)
+	if _, ok := realType.(typesystem.TVar); ok {
+		if deducedType := deduceTypeFromPatterns(patterns, table); deducedType != nil {
+			realType = resolveType(deducedType)
+		}
+	}
+
+	var typeName string
+	switch t := realType.(type) {
+	case typesystem.TCon:
+		typeName = t.Name
+	case typesystem.TApp:
+		if constructor, ok := t.Constructor.(typesystem.TCon); ok {
+			typeName = constructor.Name
+		}
+	}
+	variants, ok := table.GetVariants(typeName)
+	if !ok {
+		return nil
+	}
+	return uncoveredVariants(variants, patterns)
+}
+
+// uncoveredVariants returns the variants that no constructor pattern names.
+func uncoveredVariants(variants []string, patterns []ast.Pattern) []string {
+	missing := []string{}
+	covered := make(map[string]bool)
+	for _, p := range patterns {
+		if cp, ok := p.(*ast.ConstructorPattern); ok {
+			covered[cp.Name.Value] = true
+		}
+	}
+	for _, v := range variants {
+		if !covered[v] {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
 // getMissingListPatterns returns helpful message about missing list patterns
 func getMissingListPatterns(patterns []ast.Pattern) string {
 	hasEmpty := false
@@ -70,9 +70,13 @@ func TypeToAST(t typesystem.Type) ast.Type {
 		for k, v := range typ.Fields {
 			fields[k] = TypeToAST(v)
 		}
-		return &ast.RecordType{
+		rt := &ast.RecordType{
 			Fields: fields,
 		}
+		if row, ok := TypeToAST(typ.Row).(*ast.NamedType); ok {
+			rt.Row = row
+		}
+		return rt
 
 	case typesystem.TUnion:
 		var types []ast.Type
@@ -83,6 +87,14 @@ func TypeToAST(t typesystem.Type) ast.Type {
 			Types: types,
 		}
 
+	case typesystem.TLambda:
+		// Holes print back as '_': Pair<_, String>
+		holes := make(typesystem.Subst, len(typ.Params))
+		for _, p := range typ.Params {
+			holes[p.Name] = typesystem.TCon{Name: "_"}
+		}
+		return TypeToAST(typ.Body.Apply(holes))
+
 	default:
 		// Fallback
 		return &ast.NamedType{
@@ -149,6 +161,9 @@ func (v *implicitGenericVisitor) visit(node interface{}) {
 		for _, f := range n.Fields {
 			v.visit(f)
 		}
+		if n.Row != nil {
+			v.visit(n.Row)
+		}
 
 	case *ast.UnionType:
 		for _, t := range n.Types {
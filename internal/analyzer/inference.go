@@ -42,6 +42,8 @@ type InferenceContext struct {
 	PendingWitnesses []PendingWitness
 	// PendingReturnContexts stores return-dispatch calls that need resolution
 	PendingReturnContexts []PendingReturnContext
+	// PendingInstanceContexts stores builtin trait uses whose instance requirements are checked after inference
+	PendingInstanceContexts []PendingInstanceContext
 	// Constraints stores accumulated type constraints to be solved later
 	Constraints []Constraint
 	// InferredConstraints stores constraints inferred from usage of rigid type variables
@@ -60,6 +62,28 @@ type InferenceContext struct {
 	// BaseCounter tracks the counter start value for this context
 	// Used to distinguish generic parameters (created before) from inference variables (created during this session)
 	BaseCounter int
+	// TypedBuiltinCalls collects calls to builtins such as jsonDecode that
+	// receive a type argument once inference is done
+	TypedBuiltinCalls map[*ast.CallExpression]typeArgSource
+	// ReassignedBindings holds the assignment targets of variables that are
+	// assigned more than once; they are never generalized (value restriction)
+	ReassignedBindings map[*ast.Identifier]bool
+	// MonomorphicBindings holds the declarations of unannotated variables
+	// that were not generalized, so every use shares their type variables
+	MonomorphicBindings map[*ast.Identifier]bool
+	// currentNode is the node being inferred; equations on associated
+	// types deferred while inferring it report their errors there
+	currentNode ast.Node
+	// associatedEquations counts the deferred equations on associated
+	// types (Elem<t> ~ Int) waiting for their arguments to be inferred
+	associatedEquations int
+	// solvingConstraints is set while SolveConstraints runs: equations
+	// on associated types are no longer deferred, but checked
+	solvingConstraints bool
+	// headTraits maps the type variables of instantiated callees to the
+	// traits they must implement, so that unifying f<Int> with a concrete
+	// type can pick the matching instance head of a higher-kinded f
+	headTraits map[string][]string
 	// Context for cancellation
 	Context context.Context
 }
@@ -121,6 +145,15 @@ type PendingReturnContext struct {
 	Method string
 }
 
+// PendingInstanceContext represents a use of a builtin trait at a type whose
+// instance requirements (e.g. t: Show in Show Box<t: Show>) are checked once
+// the type is known.
+type PendingInstanceContext struct {
+	Node  ast.Node
+	Trait string
+	Type  typesystem.Type
+}
+
 // NewInferenceContext creates a new inference context.
 func NewInferenceContext() *InferenceContext {
 	return &InferenceContext{
@@ -241,6 +274,21 @@ func (ctx *InferenceContext) AddMPTCConstraint(typeVarName, traitName string, ar
 	ctx.ActiveConstraints[typeVarName] = append(ctx.ActiveConstraints[typeVarName], c)
 }
 
+// noteHeadTraits records the traits that a callee's constraints require of
+// its type variables, for InstanceHeads
+func (ctx *InferenceContext) noteHeadTraits(constraints []typesystem.Constraint, subst typesystem.Subst) {
+	for _, c := range constraints {
+		tv, ok := typesystem.TVar{Name: c.TypeVar}.Apply(subst).(typesystem.TVar)
+		if !ok {
+			continue
+		}
+		if ctx.headTraits == nil {
+			ctx.headTraits = make(map[string][]string)
+		}
+		ctx.headTraits[tv.Name] = append(ctx.headTraits[tv.Name], c.Trait)
+	}
+}
+
 // AddDeferredConstraint adds a constraint to be solved later
 func (ctx *InferenceContext) AddDeferredConstraint(c Constraint) {
 	// Migration: Ensure Args is populated if Left is set
@@ -298,6 +346,15 @@ func (ctx *InferenceContext) RegisterPendingReturnContext(node *ast.CallExpressi
 	})
 }
 
+// RegisterPendingInstanceContext registers a builtin trait use to check later.
+func (ctx *InferenceContext) RegisterPendingInstanceContext(node ast.Node, trait string, t typesystem.Type) {
+	ctx.PendingInstanceContexts = append(ctx.PendingInstanceContexts, PendingInstanceContext{
+		Node:  node,
+		Trait: trait,
+		Type:  t,
+	})
+}
+
 // findMaxTVarNumber finds the highest tN number in a type
 func findMaxTVarNumber(t typesystem.Type) int {
 	if t == nil {
@@ -523,6 +580,10 @@ func InferWithContext(ctx *InferenceContext, node ast.Node, table *symbols.Symbo
 		return nil, nil, fmt.Errorf("analyzer timeout")
 	}
 
+	prevNode := ctx.currentNode
+	ctx.currentNode = node
+	defer func() { ctx.currentNode = prevNode }()
+
 	// Helper to wrap recursive calls
 	recursiveInfer := func(n ast.Node, t *symbols.SymbolTable) (typesystem.Type, typesystem.Subst, error) {
 		return InferWithContext(ctx, n, t)
@@ -620,6 +681,18 @@ func InferWithContext(ctx *InferenceContext, node ast.Node, table *symbols.Symbo
 		// This ensures that existing mappings in Global are updated by new refinements in subst
 		ctx.GlobalSubst = subst.Compose(ctx.GlobalSubst)
 
+		// Solve the equations on associated types that this node's
+		// inference determined, and reduce the ones in its type
+		if ctx.associatedEquations > 0 {
+			if s := ctx.solveAssociatedTypeEquations(table); len(s) > 0 {
+				subst = s.Compose(subst)
+				ctx.GlobalSubst = s.Compose(ctx.GlobalSubst)
+			}
+		}
+		if table != nil && table.ContainsAssociatedType(resultType) {
+			resultType = table.ReduceAssociatedTypes(resultType.Apply(ctx.GlobalSubst))
+		}
+
 		if ctx.TypeMap != nil {
 			// Always update TypeMap with the latest inferred type
 			// This is important because look-ahead might populate TypeMap with preliminary types,
@@ -651,6 +724,12 @@ func InstantiateWithContext(ctx *InferenceContext, t typesystem.Type) typesystem
 // and returns the substitution map used.
 // It handles both explicit Forall types and legacy implicit generics.
 func InstantiateGenericsWithSubst(ctx *InferenceContext, t typesystem.Type) (typesystem.Type, typesystem.Subst) {
+	return instantiateGenericsExcept(ctx, t, nil)
+}
+
+// instantiateGenericsExcept is InstantiateGenericsWithSubst that leaves the
+// variables named in fixed untouched.
+func instantiateGenericsExcept(ctx *InferenceContext, t typesystem.Type, fixed map[string]bool) (typesystem.Type, typesystem.Subst) {
 	if t == nil {
 		return nil, nil
 	}
@@ -667,6 +746,9 @@ func InstantiateGenericsWithSubst(ctx *InferenceContext, t typesystem.Type) (typ
 
 	subst := typesystem.Subst{}
 	for _, v := range vars {
+		if fixed[v.Name] {
+			continue
+		}
 		// Check if it's a generic parameter (old) or inference variable (new)
 		var num int
 		isGeneric := true
@@ -18,6 +18,7 @@ func inferCallExpression(ctx *InferenceContext, n *ast.CallExpression, table *sy
 	}
 	totalSubst := s1
 	fnType = fnType.Apply(totalSubst)
+	ctx.noteTypedBuiltinCall(n, table)
 
 	// Resolve type aliases (e.g., type Observer = (Int) -> Nil)
 	// Use table to look up alias definitions
@@ -181,6 +182,7 @@ func inferCallWithFuncType(
 
 	// Note: Function types from inferIdentifier are already instantiated.
 	// We don't instantiate again here to keep TypeMap entries consistent.
+	ctx.noteHeadTraits(tFunc.Constraints, totalSubst)
 
 	for i, arg := range n.Arguments {
 		isSpread := false
@@ -380,6 +382,9 @@ func inferCallWithFuncType(
 				resolver := &ResolverWrapper{Table: table, Ctx: ctx}
 				subst, err := typesystem.UnifyAllowExtraWithResolver(paramType, argType, resolver)
 				if err != nil {
+					if name, ok := monomorphicCallee(ctx, n.Function, paramIdx, table); ok {
+						return nil, nil, inferErrorf(arg, "argument %d type mismatch: (%s) vs %s (%s is not polymorphic, so an earlier use fixed this parameter type)", paramIdx+1, paramType, argType, name)
+					}
 					return nil, nil, inferErrorf(arg, "argument %d type mismatch: (%s) vs %s", paramIdx+1, paramType, argType)
 				}
 				totalSubst = subst.Compose(totalSubst)
@@ -441,6 +446,16 @@ func inferCallWithFuncType(
 					if !hasExpectedReturn {
 						ctx.RegisterPendingReturnContext(n, ident.Value)
 					}
+				} else if len(tFunc.Constraints) == 0 {
+					// Builtin trait methods carry no constraint to solve, so the
+					// requirements of the instance they dispatch to are checked later
+					if sym, ok := table.Find(ident.Value); ok && sym.IsTraitMethod {
+						for _, src := range sources {
+							if src.Kind == typesystem.DispatchArg && src.Index < len(tFunc.Params) {
+								ctx.RegisterPendingInstanceContext(n, traitName, tFunc.Params[src.Index].Apply(totalSubst))
+							}
+						}
+					}
 				}
 			}
 		}
@@ -699,3 +714,32 @@ func inferTypeApplicationExpression(ctx *InferenceContext, n *ast.TypeApplicatio
 
 	return nil, nil, inferErrorf(n, "cannot apply types to non-generic type: %s", baseType)
 }
+
+// monomorphicCallee reports whether a call goes through a variable the value
+// restriction kept monomorphic (the result of a call, or a variable reassigned
+// by the closures capturing it) and whose parameter type still holds inference
+// variables. Unlike generic variables, these are not instantiated per use, so a
+// mismatch on such a parameter comes from an earlier use rather than from the
+// definition.
+func monomorphicCallee(ctx *InferenceContext, fn ast.Expression, paramIdx int, table *symbols.SymbolTable) (string, bool) {
+	ident, ok := fn.(*ast.Identifier)
+	if !ok {
+		return "", false
+	}
+	sym, ok := table.Find(ident.Value)
+	if !ok || sym.Kind != symbols.VariableSymbol || sym.Type == nil {
+		return "", false
+	}
+	decl, ok := sym.DefinitionNode.(*ast.Identifier)
+	if !ok || !ctx.MonomorphicBindings[decl] {
+		return "", false
+	}
+	tFunc, ok := sym.Type.(typesystem.TFunc)
+	if !ok || paramIdx >= len(tFunc.Params) {
+		return "", false
+	}
+	if len(tFunc.Params[paramIdx].FreeTypeVariables()) == 0 {
+		return "", false
+	}
+	return ident.Value, true
+}
@@ -578,7 +578,7 @@ func inferBlockStatement(ctx *InferenceContext, n *ast.BlockStatement, table *sy
 			var errs []*diagnostics.DiagnosticError
 			var targetType typesystem.Type
 			if len(id.Args) > 0 {
-				targetType = BuildType(id.Args[0], enclosedTable, &errs)
+				targetType = BuildInstanceHead(id.Args[0], enclosedTable, &errs)
 			}
 			if len(errs) > 0 {
 				return nil, nil, errs[0]
@@ -904,6 +904,13 @@ func inferBlockStatement(ctx *InferenceContext, n *ast.BlockStatement, table *sy
 				return nil, nil, inferErrorf(fs, "return type mismatch in local function %s: expected %s, got %s", fs.Name.Value, skolemRetType, bodyType)
 			}
 			totalSubst = subst.Compose(totalSubst)
+			// Calls look the function up with its predeclared return type variable,
+			// which must resolve to the body type (it may mention captured variables).
+			// With early returns the body type is only the last branch, so the
+			// variable stays open for the union the returns may form.
+			if !hasOwnReturn(fs.Body) {
+				ctx.GlobalSubst = subst.Compose(ctx.GlobalSubst)
+			}
 
 			// Mark tail calls since walker skips inner functions
 			MarkTailCalls(fs.Body, 0)
@@ -1121,3 +1128,19 @@ func inferBreakStatement(ctx *InferenceContext, n *ast.BreakStatement, table *sy
 func inferContinueStatement(ctx *InferenceContext, n *ast.ContinueStatement) (typesystem.Type, typesystem.Subst, error) {
 	return typesystem.Nil, typesystem.Subst{}, nil
 }
+
+// hasOwnReturn reports whether body has a return statement of its own,
+// outside nested functions
+func hasOwnReturn(body *ast.BlockStatement) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.ReturnStatement:
+			found = true
+		case *ast.FunctionLiteral, *ast.FunctionStatement:
+			return false
+		}
+		return !found
+	})
+	return found
+}
@@ -25,6 +25,219 @@ func isNonExpansive(n ast.Node) bool {
 	}
 }
 
+// noteReassignedBindings records the variables of a program that are
+// assigned more than once, by the function that declares them or by closures
+// capturing them. Such a variable holds one type for its whole lifetime, so
+// its binding must not be generalized even when the first value is: with
+// `store = None` generalized to forall a. Option<a>, a closure reading store
+// could return whatever a later `store = Some(x)` put there at any type.
+func (ctx *InferenceContext) noteReassignedBindings(node ast.Node) {
+	if ctx.ReassignedBindings == nil {
+		ctx.ReassignedBindings = make(map[*ast.Identifier]bool)
+	}
+	collectReassignedBindings(node, ctx.ReassignedBindings)
+}
+
+// generalizeBinding generalizes the type of the unannotated variable declared
+// by ident when the value restriction allows it. Variables whose type keeps
+// inference variables shared with other uses (not generalized at all, or
+// mentioning variables free in the environment) are recorded as monomorphic.
+func (ctx *InferenceContext) generalizeBinding(ident *ast.Identifier, value ast.Expression, t typesystem.Type, table *symbols.SymbolTable) typesystem.Type {
+	if !isNonExpansive(value) || ctx.ReassignedBindings[ident] {
+		ctx.noteMonomorphicBinding(ident)
+		return t
+	}
+	before := make(map[string]bool)
+	for _, tv := range t.Apply(ctx.GlobalSubst).FreeTypeVariables() {
+		before[tv.Name] = true
+	}
+	generalized := ctx.Generalize(t, table, ident.Value)
+	for _, tv := range generalized.FreeTypeVariables() {
+		if before[tv.Name] {
+			ctx.noteMonomorphicBinding(ident)
+			break
+		}
+	}
+	return generalized
+}
+
+// noteMonomorphicBinding records that the variable declared by ident was
+// not (fully) generalized: its inference variables are shared by every use
+func (ctx *InferenceContext) noteMonomorphicBinding(ident *ast.Identifier) {
+	if ctx.MonomorphicBindings == nil {
+		ctx.MonomorphicBindings = make(map[*ast.Identifier]bool)
+	}
+	ctx.MonomorphicBindings[ident] = true
+}
+
+// collectReassignedBindings marks the assignment targets of every binding
+// under root that is assigned more than once. Each assignment is resolved to
+// the binding it targets the way the analyzer scopes names: functions open a
+// scope (their parameters shadow outer names), blocks do not, and an
+// assignment to a name with no visible binding declares it in the innermost
+// function. A closure parameter that happens to share a name with an outer
+// variable is thus a different binding, and assigning to it leaves the outer
+// one alone.
+func collectReassignedBindings(root ast.Node, out map[*ast.Identifier]bool) {
+	r := &reassignResolver{}
+	r.walk(root, newAssignScope(nil, true))
+	for _, b := range r.bindings {
+		if b.assigns > 1 {
+			for _, ident := range b.targets {
+				out[ident] = true
+			}
+		}
+	}
+}
+
+// assignedBinding is a variable seen by collectReassignedBindings
+type assignedBinding struct {
+	targets []*ast.Identifier // assignments that target this binding
+	assigns int
+}
+
+// assignScope maps names to bindings. Only function scopes receive the
+// variables declared by assignment; the others hold names bound by a loop or
+// a match arm, which shadow outer names without opening a function scope.
+type assignScope struct {
+	parent   *assignScope
+	function bool
+	names    map[string]*assignedBinding
+}
+
+func newAssignScope(parent *assignScope, function bool) *assignScope {
+	return &assignScope{parent: parent, function: function, names: make(map[string]*assignedBinding)}
+}
+
+func (s *assignScope) lookup(name string) *assignedBinding {
+	for sc := s; sc != nil; sc = sc.parent {
+		if b, ok := sc.names[name]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+func (s *assignScope) functionScope() *assignScope {
+	sc := s
+	for !sc.function {
+		sc = sc.parent
+	}
+	return sc
+}
+
+type reassignResolver struct {
+	bindings []*assignedBinding
+}
+
+// bind introduces a binding that shadows name in scope
+func (r *reassignResolver) bind(scope *assignScope, name string) {
+	if name == "" || name == "_" {
+		return
+	}
+	b := &assignedBinding{}
+	scope.names[name] = b
+	r.bindings = append(r.bindings, b)
+}
+
+func (r *reassignResolver) walk(node ast.Node, scope *assignScope) {
+	switch n := node.(type) {
+	case *ast.FunctionStatement:
+		inner := newAssignScope(scope, true)
+		if n.Receiver != nil && n.Receiver.Name != nil {
+			r.bind(inner, n.Receiver.Name.Value)
+		}
+		r.walkFunction(n.Parameters, n.Body, inner)
+		return
+	case *ast.FunctionLiteral:
+		r.walkFunction(n.Parameters, n.Body, newAssignScope(scope, true))
+		return
+	case *ast.ForExpression:
+		r.walkChild(n.Initializer, scope)
+		r.walkChild(n.Condition, scope)
+		r.walkChild(n.Iterable, scope)
+		inner := scope
+		if n.ItemName != nil || n.ItemPattern != nil {
+			inner = newAssignScope(scope, false)
+			if n.ItemName != nil {
+				r.bind(inner, n.ItemName.Value)
+			}
+			r.bindPattern(n.ItemPattern, inner)
+		}
+		r.walkChild(n.Body, inner)
+		return
+	case *ast.MatchExpression:
+		r.walkChild(n.Expression, scope)
+		for _, arm := range n.Arms {
+			inner := newAssignScope(scope, false)
+			r.bindPattern(arm.Pattern, inner)
+			r.walkChild(arm.Guard, inner)
+			r.walkChild(arm.Expression, inner)
+		}
+		return
+	case *ast.AssignExpression:
+		// The value is evaluated before the target is bound
+		r.walkChild(n.Value, scope)
+		if ident, ok := n.Left.(*ast.Identifier); ok {
+			if ident.Value == "_" {
+				return
+			}
+			b := scope.lookup(ident.Value)
+			if b == nil {
+				r.bind(scope.functionScope(), ident.Value)
+				b = scope.functionScope().names[ident.Value]
+			}
+			b.targets = append(b.targets, ident)
+			b.assigns++
+			return
+		}
+		r.walkChild(n.Left, scope)
+		return
+	}
+	ast.Children(node, func(child ast.Node) {
+		r.walk(child, scope)
+	})
+}
+
+// walkChild walks an optional child node, which may be nil
+func (r *reassignResolver) walkChild(node ast.Node, scope *assignScope) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		r.walk(n, scope)
+		return false
+	})
+}
+
+func (r *reassignResolver) walkFunction(params []*ast.Parameter, body *ast.BlockStatement, scope *assignScope) {
+	for _, p := range params {
+		if p.Default != nil {
+			r.walkChild(p.Default, scope.parent)
+		}
+		if p.Name != nil {
+			r.bind(scope, p.Name.Value)
+		}
+	}
+	r.walkChild(body, scope)
+}
+
+// bindPattern binds the variables of a loop or match pattern in scope
+func (r *reassignResolver) bindPattern(pat ast.Pattern, scope *assignScope) {
+	ast.Inspect(pat, func(n ast.Node) bool {
+		switch p := n.(type) {
+		case *ast.IdentifierPattern:
+			r.bind(scope, p.Value)
+		case *ast.TypePattern:
+			r.bind(scope, p.Name)
+		case *ast.StringPattern:
+			for _, part := range p.Parts {
+				if part.IsCapture {
+					r.bind(scope, part.Value)
+				}
+			}
+		}
+		return true
+	})
+}
+
 // inferAssignExpression checks assignment compatibility and handles symbol definition/update.
 // It also ensures that mutations respect scope rules (preventing mutation of global variables from function scopes).
 func inferAssignExpression(ctx *InferenceContext, n *ast.AssignExpression, table *symbols.SymbolTable, inferFn func(ast.Node, *symbols.SymbolTable) (typesystem.Type, typesystem.Subst, error), typeMap map[ast.Node]typesystem.Type) (typesystem.Type, typesystem.Subst, error) {
@@ -220,8 +433,8 @@ func inferAssignExpression(ctx *InferenceContext, n *ast.AssignExpression, table
 			if sym.IsPending {
 				// No Local Let Generalization: Only generalize if at global scope
 				// UPDATE: Enable local let generalization for non-expansive values (Rank-N support)
-				if n.AnnotatedType == nil && isNonExpansive(n.Value) {
-					declaredType = ctx.Generalize(declaredType, table, ident.Value)
+				if n.AnnotatedType == nil {
+					declaredType = ctx.generalizeBinding(ident, n.Value, declaredType, table)
 				}
 				table.DefineWithNode(ident.Value, declaredType, ctx.CurrentModuleName, ident)
 				if ctx.ResolutionMap != nil {
@@ -286,10 +499,18 @@ func inferAssignExpression(ctx *InferenceContext, n *ast.AssignExpression, table
 				}
 			}
 
+			// Record the reassigned binding so tooling can follow it (references, rename)
+			if ctx.ResolutionMap != nil {
+				ctx.ResolutionMap[ident] = sym
+			}
+
 			// It exists. Unify types.
 			if sym.Type != nil {
 				// Apply current substitutions to existing variable type to ensure we check against refined type
 				currentSymType := sym.Type.Apply(ctx.GlobalSubst).Apply(totalSubst)
+				if _, isForall := currentSymType.(typesystem.TForall); isForall {
+					return nil, nil, inferErrorf(n, "cannot reassign %s: its first value was given the polymorphic type %s; annotate the first assignment with a concrete type", ident.Value, currentSymType)
+				}
 				subst, err := typesystem.UnifyAllowExtraWithResolver(currentSymType, valType, table)
 				if err != nil {
 					return nil, nil, inferErrorf(n, "cannot assign %s to variable %s of type %s", valType, ident.Value, currentSymType)
@@ -306,8 +527,8 @@ func inferAssignExpression(ctx *InferenceContext, n *ast.AssignExpression, table
 			// Use the declared type (annotation type if present, else inferred type)
 			// No Local Let Generalization: Only generalize if at global scope
 			// UPDATE: Enable local let generalization for non-expansive values (Rank-N support)
-			if n.AnnotatedType == nil && isNonExpansive(n.Value) {
-				declaredType = ctx.Generalize(declaredType, table, ident.Value)
+			if n.AnnotatedType == nil {
+				declaredType = ctx.generalizeBinding(ident, n.Value, declaredType, table)
 			}
 			table.DefineWithNode(ident.Value, declaredType, "", ident)
 			if ctx.ResolutionMap != nil {
@@ -74,7 +74,7 @@ func inferAnnotatedExpression(ctx *InferenceContext, n *ast.AnnotatedExpression,
 }
 
 func inferIdentifier(ctx *InferenceContext, n *ast.Identifier, table *symbols.SymbolTable) (typesystem.Type, typesystem.Subst, error) {
-	sym, ok := table.Find(n.Value)
+	sym, scope, ok := table.FindWithScope(n.Value)
 	if !ok {
 		// Find similar names for suggestion
 		suggestions := findSimilarNames(n.Value, table, 2)
@@ -136,7 +136,7 @@ func inferIdentifier(ctx *InferenceContext, n *ast.Identifier, table *symbols.Sy
 
 		// Instantiate generic types to avoid collisions and support polymorphism
 		// Only instantiate GENERIC parameters (from previous scopes), not local inference variables.
-		instType, mapping := InstantiateGenericsWithSubst(ctx, sym.Type)
+		instType, mapping := instantiateGenericsExcept(ctx, sym.Type, enclosingTypeParams(sym.Type, scope))
 		n.TypeVarMapping = mapping // Store the mapping for monomorphization
 
 		if instType != nil {
@@ -149,6 +149,29 @@ func inferIdentifier(ctx *InferenceContext, n *ast.Identifier, table *symbols.Sy
 	}
 }
 
+// enclosingTypeParams returns the variables of t that are type parameters of a
+// generic function enclosing scope, such as f in the type of xs within
+// fun inc<f: Functor>(xs: f<Int>). They stand for the one type the caller
+// chose, so a use of xs must not rename them: the body's constraints on f
+// resolve to the function's own dictionary parameters.
+func enclosingTypeParams(t typesystem.Type, scope *symbols.SymbolTable) map[string]bool {
+	if _, ok := t.(typesystem.TForall); ok || scope == nil {
+		return nil
+	}
+	var fixed map[string]bool
+	for _, v := range t.FreeTypeVariables() {
+		if param, ok := scope.ResolveType(v.Name); ok {
+			if tv, ok := param.(typesystem.TVar); ok && tv.Name == v.Name {
+				if fixed == nil {
+					fixed = make(map[string]bool)
+				}
+				fixed[v.Name] = true
+			}
+		}
+	}
+	return fixed
+}
+
 func inferSpreadExpression(ctx *InferenceContext, n *ast.SpreadExpression, table *symbols.SymbolTable, inferFn func(ast.Node, *symbols.SymbolTable) (typesystem.Type, typesystem.Subst, error)) (typesystem.Type, typesystem.Subst, error) {
 	// SpreadExpression unwraps a tuple.
 	return inferFn(n.Expression, table)
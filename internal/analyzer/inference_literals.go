@@ -96,6 +96,7 @@ func inferLiteral(ctx *InferenceContext, node ast.Node, table *symbols.SymbolTab
 		fieldTypes := make(map[string]typesystem.Type)
 		nominalFieldTypes := make(map[string]typesystem.Type)
 		totalSubst := typesystem.Subst{}
+		var spreadRow typesystem.Type // Row of the spread base: { ...rec, y: 1 } keeps rec's other fields
 
 		// Handle spread expression first: { ...base, key: val }
 		if n.Spread != nil {
@@ -120,6 +121,7 @@ func inferLiteral(ctx *InferenceContext, node ast.Node, table *symbols.SymbolTab
 				for k, v := range rec.Fields {
 					fieldTypes[k] = v
 				}
+				spreadRow = rec.Row
 			} else {
 				return nil, nil, inferErrorf(n.Spread, "spread expression must be a record type, got %s", spreadType)
 			}
@@ -166,6 +168,9 @@ func inferLiteral(ctx *InferenceContext, node ast.Node, table *symbols.SymbolTab
 		// Empty record literal {} is treated as Open to allow it to unify with any record (as a base/default)
 		isOpen := len(finalFields) == 0
 		resultRecord := typesystem.TRecord{Fields: finalFields, IsOpen: isOpen}
+		if spreadRow != nil {
+			resultRecord = typesystem.TRecord{Fields: finalFields, Row: spreadRow.Apply(totalSubst), IsOpen: true}
+		}
 
 		// Nominal type preservation: if there is an expected type for this
 		// record literal that is a nominal type (TCon) whose underlying type
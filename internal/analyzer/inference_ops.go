@@ -324,6 +324,7 @@ func inferInfixExpression(ctx *InferenceContext, n *ast.InfixExpression, table *
 		if traitName, ok := table.GetTraitForOperator(n.Operator); ok {
 			// Check if EITHER type has implementation OR constraint (for lambdas where fresh TVar unifies with constrained type)
 			if eitherHasConstraint(ctx, table, l, r, traitName) {
+				ctx.RegisterPendingInstanceContext(n, traitName, l)
 				// Both operands should have the same type
 				subst, err := typesystem.UnifyWithResolver(l, r, resolver)
 				if err != nil {
@@ -419,6 +420,7 @@ func inferInfixExpression(ctx *InferenceContext, n *ast.InfixExpression, table *
 		// First, check if there's a trait implementation for this operator on the type
 		if traitName, ok := table.GetTraitForOperator(n.Operator); ok {
 			if eitherHasConstraint(ctx, table, l, r, traitName) {
+				ctx.RegisterPendingInstanceContext(n, traitName, l)
 				// Both operands should have the same type
 				subst, err := typesystem.UnifyWithResolver(l, r, resolver)
 				if err != nil {
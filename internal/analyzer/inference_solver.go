@@ -11,6 +11,11 @@ import (
 func (ctx *InferenceContext) SolveConstraints(table *symbols.SymbolTable) []error {
 	var errors []error
 
+	// Equations on associated types are checked from now on, not deferred
+	prevSolving := ctx.solvingConstraints
+	ctx.solvingConstraints = true
+	defer func() { ctx.solvingConstraints = prevSolving }()
+
 	// Iteratively resolve constraints
 	changed := true
 	for changed {
@@ -51,7 +56,13 @@ func (ctx *InferenceContext) SolveConstraints(table *symbols.SymbolTable) []erro
 			resolver := &ResolverWrapper{Table: table, Ctx: ctx}
 			_, err := typesystem.UnifyAllowExtraWithResolver(left, right, resolver)
 			if err != nil {
-				errors = append(errors, inferErrorf(c.Node, "type mismatch: %v", err))
+				if app, ok := openAssociatedType(left, table); ok {
+					errors = append(errors, inferErrorf(c.Node, "ambiguous associated type %s: cannot determine its instance (add type annotation)", app))
+				} else if app, ok := openAssociatedType(right, table); ok {
+					errors = append(errors, inferErrorf(c.Node, "ambiguous associated type %s: cannot determine its instance (add type annotation)", app))
+				} else {
+					errors = append(errors, inferErrorf(c.Node, "type mismatch: %v", err))
+				}
 			}
 
 		} else if c.Kind == ConstraintImplements {
@@ -70,7 +81,7 @@ func (ctx *InferenceContext) SolveConstraints(table *symbols.SymbolTable) []erro
 			var rigidVars []string
 
 			for i, arg := range c.Args {
-				concrete := arg.Apply(ctx.GlobalSubst)
+				concrete := table.ReduceAssociatedTypes(arg.Apply(ctx.GlobalSubst))
 				// Manual lookup if Apply failed to resolve var
 				if tv, ok := concrete.(typesystem.TVar); ok {
 					if val, ok := ctx.GlobalSubst[tv.Name]; ok {
@@ -378,6 +389,61 @@ func (ctx *InferenceContext) SolveConstraints(table *symbols.SymbolTable) []erro
 	return errors
 }
 
+// solveAssociatedTypeEquations solves the deferred equations on associated
+// types that the inferred arguments now reduce, such as Elem<$1> ~ Int once
+// $1 is IntBag, and returns the substitution they add. It runs after each
+// node is inferred, while equations whose applications are still open stay
+// deferred; equations that reduce but do not hold are kept as well, for
+// SolveConstraints to report at the node that deferred them.
+func (ctx *InferenceContext) solveAssociatedTypeEquations(table *symbols.SymbolTable) typesystem.Subst {
+	prevSolving := ctx.solvingConstraints
+	ctx.solvingConstraints = true
+	defer func() { ctx.solvingConstraints = prevSolving }()
+
+	subst := typesystem.Subst{}
+	var remaining []Constraint
+	for _, c := range ctx.Constraints {
+		if c.Kind != ConstraintUnify {
+			remaining = append(remaining, c)
+			continue
+		}
+		left := table.ReduceAssociatedTypes(c.Left.Apply(ctx.GlobalSubst).Apply(subst))
+		right := table.ReduceAssociatedTypes(c.Right.Apply(ctx.GlobalSubst).Apply(subst))
+		if _, open := openAssociatedType(left, table); open {
+			remaining = append(remaining, c)
+			continue
+		}
+		if _, open := openAssociatedType(right, table); open {
+			remaining = append(remaining, c)
+			continue
+		}
+		resolver := &ResolverWrapper{Table: table, Ctx: ctx}
+		s, err := typesystem.UnifyAllowExtraWithResolver(left, right, resolver)
+		if err != nil {
+			remaining = append(remaining, c)
+			continue
+		}
+		subst = s.Compose(subst)
+		ctx.associatedEquations--
+	}
+	ctx.Constraints = remaining
+	return subst
+}
+
+// openAssociatedType returns an associated type application in t whose
+// arguments are not inferred yet, such as Elem<$1>.
+func openAssociatedType(t typesystem.Type, table *symbols.SymbolTable) (typesystem.TApp, bool) {
+	var open typesystem.TApp
+	found := false
+	typesystem.ReplaceTApp(t, func(app typesystem.TApp) (typesystem.Type, bool) {
+		if !found && table.IsAssociatedType(app) && len(app.FreeTypeVariables()) > 0 {
+			open, found = app, true
+		}
+		return nil, false
+	})
+	return open, found
+}
+
 // getKeys returns sorted keys of a substitution map
 func getKeys(s typesystem.Subst) []string {
 	keys := []string{}
@@ -416,7 +482,7 @@ func (ctx *InferenceContext) SolveWitness(node ast.Node, traitName string, args
 	resolvedArgs := make([]typesystem.Type, len(args))
 	hasVar := false
 	for i, arg := range args {
-		resolved := arg.Apply(ctx.GlobalSubst)
+		resolved := table.ReduceAssociatedTypes(arg.Apply(ctx.GlobalSubst))
 		resolvedArgs[i] = resolved
 		if isVar(resolved) {
 			hasVar = true
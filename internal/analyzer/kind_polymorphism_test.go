@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"github.com/funvibe/funxy/internal/diagnostics"
+	"testing"
+)
+
+// Type parameters whose kind the declaration does not fix are kind-polymorphic,
+// and '_' in a type application leaves a hole that makes it a type constructor.
+
+func TestKindPolymorphism_UnconstrainedParameter(t *testing.T) {
+	expectNoAnalyzerErrors(t, `
+type Proxy<t> = Proxy
+a: Proxy<Int> = Proxy
+b: Proxy<List> = Proxy
+c: Proxy<Result> = Proxy
+`)
+	// Field types still fix the kind to *
+	expectAnalyzerErrorContains(t, `
+type Box<t> = Box(t)
+b: Box<List> = Box([])
+`, diagnostics.ErrA003, "expected kind *, got (* -> *)")
+}
+
+func TestKindPolymorphism_FunctionParameter(t *testing.T) {
+	expectNoAnalyzerErrors(t, `
+type Proxy<t> = Proxy
+fun takeProxy(p: Proxy<t>) -> Bool { true }
+p1: Proxy<Int> = Proxy
+p2: Proxy<List> = Proxy
+a = takeProxy(p1)
+b = takeProxy(p2)
+`)
+	// A parameter used as a value type is still of kind *
+	expectAnalyzerErrorContains(t, `
+type Proxy<t> = Proxy
+fun tagged(p: Proxy<t>, x: t) -> Bool { true }
+p: Proxy<List> = Proxy
+a = tagged(p, [1])
+`, diagnostics.ErrA003, "mismatch")
+}
+
+func TestKindPolymorphism_KindVariableAnnotation(t *testing.T) {
+	expectNoAnalyzerErrors(t, `
+type Proxy<t> = Proxy
+type App<f: k -> *, a: k> = App(f<a>)
+a: App<Option, Int> = App(Some(1))
+b: App<Proxy, List> = App(Proxy)
+`)
+	// Both uses of k must agree
+	expectAnalyzerErrorContains(t, `
+type App<f: k -> *, a: k> = App(f<a>)
+a: App<Option, List> = App(None)
+`, diagnostics.ErrA003, "expected kind *, got (* -> *)")
+}
+
+func TestTypeHoles_InstanceOverFirstParameter(t *testing.T) {
+	expectNoAnalyzerErrors(t, `
+type Pair<l, r> = MkPair(l, r)
+trait MapLeft<f> {
+    fun mapLeft(x: f<Int>) -> f<String>
+}
+instance MapLeft<Pair<_, String>> {
+    fun mapLeft(x: Pair<Int, String>) -> Pair<String, String> {
+        match x { MkPair(n, s) -> MkPair("n", s) }
+    }
+}
+p: Pair<String, String> = mapLeft(MkPair(1, "s"))
+`)
+	// The instance method is checked against the reduced signature
+	expectAnalyzerError(t, `
+type Pair<l, r> = MkPair(l, r)
+trait MapLeft<f> {
+    fun mapLeft(x: f<Int>) -> f<String>
+}
+instance MapLeft<Pair<_, String>> {
+    fun mapLeft(x: Pair<String, Int>) -> Pair<String, String> {
+        match x { MkPair(s, n) -> MkPair(s, "n") }
+    }
+}
+`, diagnostics.ErrA003)
+	// No instance for the other position
+	expectAnalyzerError(t, `
+type Pair<l, r> = MkPair(l, r)
+trait MapLeft<f> {
+    fun mapLeft(x: f<Int>) -> f<String>
+}
+instance MapLeft<Pair<_, String>> {
+    fun mapLeft(x: Pair<Int, String>) -> Pair<String, String> {
+        match x { MkPair(n, s) -> MkPair("n", s) }
+    }
+}
+p = mapLeft(MkPair(1, 2))
+`, diagnostics.ErrA003)
+}
+
+func TestTypeHoles_ResolvedByInstanceHead(t *testing.T) {
+	// Pair<1, 2> also fits Pair<Int, _>; the declared head decides
+	expectNoAnalyzerErrors(t, `
+type Pair<l, r> = MkPair(l, r)
+trait MapLeft<f> {
+    fun mapLeft(x: f<Int>) -> f<String>
+}
+instance MapLeft<Pair<_, Int>> {
+    fun mapLeft(x: Pair<Int, Int>) -> Pair<String, Int> {
+        match x { MkPair(n, m) -> MkPair("n", m) }
+    }
+}
+p: Pair<String, Int> = mapLeft(MkPair(1, 2))
+`)
+	// Heads that differ in the fixed argument pick their own instance
+	expectNoAnalyzerErrors(t, `
+type Pair<l, r> = MkPair(l, r)
+trait MapLeft<f> {
+    fun mapLeft(x: f<Int>) -> f<String>
+}
+instance MapLeft<Pair<_, String>> {
+    fun mapLeft(x: Pair<Int, String>) -> Pair<String, String> {
+        match x { MkPair(n, s) -> MkPair("n", s) }
+    }
+}
+instance MapLeft<Pair<_, Int>> {
+    fun mapLeft(x: Pair<Int, Int>) -> Pair<String, Int> {
+        match x { MkPair(n, m) -> MkPair("n", m) }
+    }
+}
+p: Pair<String, String> = mapLeft(MkPair(1, "s"))
+q: Pair<String, Int> = mapLeft(MkPair(1, 2))
+`)
+}
+
+func TestTypeHoles_OverlappingHeads(t *testing.T) {
+	// Both heads match Pair<Int, Int>
+	expectAnalyzerErrorContains(t, `
+type Pair<l, r> = MkPair(l, r)
+trait MapLeft<f> {
+    fun mapLeft(x: f<Int>) -> f<String>
+}
+instance MapLeft<Pair<_, Int>> {
+    fun mapLeft(x: Pair<Int, Int>) -> Pair<String, Int> {
+        match x { MkPair(n, m) -> MkPair("n", m) }
+    }
+}
+instance MapLeft<Pair<Int, _>> {
+    fun mapLeft(x: Pair<Int, Int>) -> Pair<Int, String> {
+        match x { MkPair(n, m) -> MkPair(n, "m") }
+    }
+}
+`, diagnostics.ErrA004, "overlapping instances for trait MapLeft")
+	// A plain partial application abstracts the last argument
+	expectAnalyzerErrorContains(t, `
+type Pair<l, r> = MkPair(l, r)
+trait MapLeft<f> {
+    fun mapLeft(x: f<Int>) -> f<String>
+}
+instance MapLeft<Pair<_, Int>> {
+    fun mapLeft(x: Pair<Int, Int>) -> Pair<String, Int> {
+        match x { MkPair(n, m) -> MkPair("n", m) }
+    }
+}
+instance MapLeft<Pair<Int>> {
+    fun mapLeft(x: Pair<Int, Int>) -> Pair<Int, String> {
+        match x { MkPair(n, m) -> MkPair(n, "m") }
+    }
+}
+`, diagnostics.ErrA004, "overlapping instances for trait MapLeft")
+}
+
+func TestTypeHoles_MiddleParameter(t *testing.T) {
+	expectNoAnalyzerErrors(t, `
+type Tri<a, b, c> = Tri(a, b, c)
+trait Mid<f> {
+    fun mid(x: f<Int>) -> Int
+}
+instance Mid<Tri<String, _, Bool>> {
+    fun mid(x: Tri<String, Int, Bool>) -> Int {
+        match x { Tri(_, n, _) -> n }
+    }
+}
+n: Int = mid(Tri("a", 1, true))
+`)
+}
+
+func TestTypeHoles_OnlyInInstanceHeads(t *testing.T) {
+	expectAnalyzerErrorContains(t, `
+x: _ = 1
+`, diagnostics.ErrA003, "type hole '_' is only allowed in instance heads")
+	expectAnalyzerErrorContains(t, `
+type Pair<l, r> = MkPair(l, r)
+x: Pair<_, String> = MkPair(1, "s")
+`, diagnostics.ErrA003, "type hole '_' is only allowed in instance heads")
+	expectAnalyzerErrorContains(t, `
+type Pair<l, r> = MkPair(l, r)
+fun f(p: Pair<_, Int>) -> Int { 1 }
+`, diagnostics.ErrA003, "type hole '_' is only allowed in instance heads")
+}
@@ -39,12 +39,33 @@ func getHeadConstructorName(t typesystem.Type) string {
 		return "FUNCTION"
 	case typesystem.TVar:
 		return typ.Name
+	case typesystem.TLambda:
+		return GetInstanceHeadName(typ)
 	default:
 		// Best effort for other types
 		return typ.String()
 	}
 }
 
+// GetInstanceHeadName names an instance head with holes by its constructor
+// and arguments, keeping the holes: Pair<_, String>. Instances for
+// Pair<_, Int> and Pair<_, String> thus get separate dictionaries.
+func GetInstanceHeadName(lam typesystem.TLambda) string {
+	holes := make(typesystem.Subst, len(lam.Params))
+	for _, p := range lam.Params {
+		holes[p.Name] = typesystem.TCon{Name: "_"}
+	}
+	app, ok := lam.Body.Apply(holes).(typesystem.TApp)
+	if !ok {
+		return lam.String()
+	}
+	args := make([]string, len(app.Args))
+	for i, arg := range app.Args {
+		args[i] = arg.String()
+	}
+	return getHeadConstructorName(app.Constructor) + "<" + strings.Join(args, ", ") + ">"
+}
+
 // isValueName checks if a name follows value naming convention (starts with lowercase or _)
 // Also allows operator methods like (==), (+), etc.
 func isValueName(name string) bool {
@@ -251,6 +251,8 @@ func inferPattern(ctx *InferenceContext, pat ast.Pattern, expectedType typesyste
 		}
 
 		if tRec, ok := checkType.(typesystem.TRecord); ok {
+			// Fields the record type doesn't list come from its row
+			row := tRec.Row
 			for _, key := range patternKeys {
 				pat := p.Fields[key]
 				if fieldType, ok := tRec.Fields[key]; ok {
@@ -259,6 +261,20 @@ func inferPattern(ctx *InferenceContext, pat ast.Pattern, expectedType typesyste
 						return nil, err
 					}
 					totalSubst = s.Compose(totalSubst)
+				} else if row != nil {
+					ft := ctx.FreshVar()
+					tail := ctx.FreshVar()
+					s, err := typesystem.Unify(row.Apply(totalSubst), typesystem.TRecord{Fields: map[string]typesystem.Type{key: ft}, Row: tail, IsOpen: true})
+					if err != nil {
+						return nil, inferErrorf(p, "record pattern field '%s' not found in type %s", key, tRec)
+					}
+					totalSubst = s.Compose(totalSubst)
+					row = tail
+					s, err = inferPattern(ctx, pat, ft.Apply(totalSubst), table)
+					if err != nil {
+						return nil, err
+					}
+					totalSubst = s.Compose(totalSubst)
 				} else {
 					if tRec.IsOpen {
 						ft := ctx.FreshVar()
@@ -272,6 +288,24 @@ func inferPattern(ctx *InferenceContext, pat ast.Pattern, expectedType typesyste
 					}
 				}
 			}
+			if p.Rest != nil {
+				// The rest is the record without the pattern's fields
+				restFields := make(map[string]typesystem.Type)
+				for k, v := range tRec.Fields {
+					if _, matched := p.Fields[k]; !matched {
+						restFields[k] = v.Apply(totalSubst)
+					}
+				}
+				rest := typesystem.TRecord{Fields: restFields, IsOpen: tRec.IsOpen}
+				if row != nil {
+					rest.Row = row.Apply(totalSubst)
+				}
+				s, err := inferPattern(ctx, p.Rest, rest.Apply(totalSubst), table)
+				if err != nil {
+					return nil, err
+				}
+				totalSubst = s.Compose(totalSubst)
+			}
 			return totalSubst, nil
 		} else if _, ok := expectedType.(typesystem.TVar); ok {
 			fields := make(map[string]typesystem.Type)
@@ -286,12 +320,25 @@ func inferPattern(ctx *InferenceContext, pat ast.Pattern, expectedType typesyste
 				fields[key] = ft.Apply(totalSubst) // Apply accumulated subst
 			}
 			recType := typesystem.TRecord{Fields: fields, IsOpen: true}
+			var restRow typesystem.Type
+			if p.Rest != nil {
+				restRow = ctx.FreshVar()
+				recType.Row = restRow
+			}
 
 			subst, err := typesystem.Unify(expectedType.Apply(totalSubst), recType)
 			if err != nil {
 				return nil, err
 			}
 			totalSubst = subst.Compose(totalSubst)
+			if p.Rest != nil {
+				rest := typesystem.TRecord{Fields: map[string]typesystem.Type{}, Row: restRow, IsOpen: true}
+				s, err := inferPattern(ctx, p.Rest, rest.Apply(totalSubst), table)
+				if err != nil {
+					return nil, err
+				}
+				totalSubst = s.Compose(totalSubst)
+			}
 			return totalSubst, nil
 		}
 
@@ -518,6 +565,9 @@ func (w *walker) VisitRecordPattern(n *ast.RecordPattern) {
 	for _, el := range n.Fields {
 		el.Accept(w)
 	}
+	if n.Rest != nil {
+		n.Rest.Accept(w)
+	}
 }
 
 func (w *walker) VisitSpreadPattern(n *ast.SpreadPattern) {
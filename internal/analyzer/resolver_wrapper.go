@@ -5,7 +5,7 @@ import (
 	"github.com/funvibe/funxy/internal/typesystem"
 )
 
-// ResolverWrapper wraps SymbolTable and InferenceContext to implement typesystem.Resolver and VariableGenerator.
+// ResolverWrapper wraps SymbolTable and InferenceContext to implement typesystem.Resolver, VariableGenerator, AssociatedTypeResolver, DeferredEquationLog and InstanceHeadResolver.
 type ResolverWrapper struct {
 	Table *symbols.SymbolTable
 	Ctx   *InferenceContext
@@ -43,3 +43,101 @@ func (w *ResolverWrapper) FreshTVar() typesystem.TVar {
 	}
 	return w.Ctx.FreshVar()
 }
+
+// IsAssociatedType reports whether app applies an associated type (Elem<c>)
+func (w *ResolverWrapper) IsAssociatedType(app typesystem.TApp) bool {
+	if w.Table == nil {
+		return false
+	}
+	return w.Table.IsAssociatedType(app)
+}
+
+// ReduceAssociatedType delegates to SymbolTable
+func (w *ResolverWrapper) ReduceAssociatedType(app typesystem.TApp) (typesystem.Type, bool) {
+	if w.Table == nil {
+		return nil, false
+	}
+	return w.Table.ReduceAssociatedType(app)
+}
+
+// DeferAssociatedType records an equation on an associated type whose
+// arguments are not inferred yet, to be solved once they are
+func (w *ResolverWrapper) DeferAssociatedType(expected, actual typesystem.Type) bool {
+	if w.Ctx == nil || w.Ctx.solvingConstraints {
+		return false
+	}
+	if len(expected.FreeTypeVariables()) == 0 && len(actual.FreeTypeVariables()) == 0 {
+		return false
+	}
+	if w.fixedByTypeParams(expected) && w.fixedByTypeParams(actual) {
+		// Elem<c> for the c of fun f<c: Container> is abstract: no later
+		// inference decides it, so the equation fails now, where it is made
+		return false
+	}
+	w.Ctx.AddDeferredConstraint(Constraint{
+		Kind:  ConstraintUnify,
+		Left:  expected,
+		Right: actual,
+		Node:  w.Ctx.currentNode,
+	})
+	w.Ctx.associatedEquations++
+	return true
+}
+
+// fixedByTypeParams reports whether every type variable in t is a type
+// parameter of an enclosing generic function rather than one still inferred.
+func (w *ResolverWrapper) fixedByTypeParams(t typesystem.Type) bool {
+	if w.Table == nil {
+		return false
+	}
+	for _, v := range t.FreeTypeVariables() {
+		param, ok := w.Table.ResolveType(v.Name)
+		if tv, isVar := param.(typesystem.TVar); !ok || !isVar || tv.Name != v.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// DeferredMark returns the position in the constraint list that deferred
+// equations are appended after
+func (w *ResolverWrapper) DeferredMark() int {
+	if w.Ctx == nil {
+		return 0
+	}
+	return len(w.Ctx.Constraints)
+}
+
+// RollbackDeferred drops the equations deferred since mark, when the
+// unification attempt that deferred them fails. Unification adds no other
+// constraints, so everything after mark is such an equation.
+func (w *ResolverWrapper) RollbackDeferred(mark int) {
+	if w.Ctx == nil || mark >= len(w.Ctx.Constraints) {
+		return
+	}
+	w.Ctx.associatedEquations -= len(w.Ctx.Constraints) - mark
+	w.Ctx.Constraints = w.Ctx.Constraints[:mark]
+}
+
+// InstanceHeads returns the heads of the instances of the traits required
+// of v, with their type variables renamed to fresh ones
+func (w *ResolverWrapper) InstanceHeads(v typesystem.TVar) []typesystem.Type {
+	if w.Table == nil || w.Ctx == nil {
+		return nil
+	}
+	var heads []typesystem.Type
+	for _, trait := range w.Ctx.headTraits[v.Name] {
+		for _, impl := range w.Table.GetAllImplementations()[trait] {
+			if len(impl.TargetTypes) == 0 {
+				continue
+			}
+			head := impl.TargetTypes[0]
+			fresh := make(typesystem.Subst)
+			for _, hv := range head.FreeTypeVariables() {
+				fresh[hv.Name] = w.Ctx.FreshVarWithKind(hv.Kind())
+			}
+			heads = append(heads, head.Apply(fresh))
+		}
+	}
+	return heads
+}
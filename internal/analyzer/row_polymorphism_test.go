@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"github.com/funvibe/funxy/internal/diagnostics"
+	"testing"
+)
+
+// A row variable names the fields of a record that a signature does not list,
+// so results keep, add or drop fields of the caller's record.
+
+func TestRowPolymorphism_ResultKeepsCallerFields(t *testing.T) {
+	expectNoAnalyzerErrors(t, `
+fun get_x(rec: { x: Int, ...r }) -> { x: Int, ...r } { rec }
+result = get_x({ x: 1, y: "a" })
+s: String = result.y
+`)
+	expectAnalyzerErrorContains(t, `
+fun get_x(rec: { x: Int, ...r }) -> { x: Int, ...r } { rec }
+result = get_x({ x: 1, y: 2 })
+missing = result.z
+`, diagnostics.ErrA003, "no field or extension method 'z'")
+}
+
+func TestRowPolymorphism_ExtendAndRemove(t *testing.T) {
+	expectNoAnalyzerErrors(t, `
+fun withId(rec: { ...r }, id: Int) -> { id: Int, ...r } { { ...rec, id: id } }
+fun withoutId(rec: { id: Int, ...r }) -> { ...r } {
+    match rec { { id: _, ...rest } -> rest }
+}
+u = withId({ name: "ann" }, 1)
+n: Int = u.id
+v = withoutId(u)
+s: String = v.name
+`)
+	expectAnalyzerErrorContains(t, `
+fun withoutId(rec: { id: Int, ...r }) -> { ...r } {
+    match rec { { id: _, ...rest } -> rest }
+}
+v = withoutId({ id: 1, name: "ann" })
+n = v.id
+`, diagnostics.ErrA003, "no field or extension method 'id'")
+}
+
+func TestRowPolymorphism_BodyIsCheckedAgainstRow(t *testing.T) {
+	// The row is rigid in the body: a record that does not come from rec
+	// cannot stand for the caller's fields.
+	expectAnalyzerError(t, `
+fun get_x(rec: { x: Int, ...r }) -> { x: Int, ...r } { { x: 1 } }
+`, diagnostics.ErrA003)
+	expectAnalyzerError(t, `
+fun withId(rec: { ...r }, id: Int) -> { id: Int, ...r } { rec }
+`, diagnostics.ErrA003)
+	expectAnalyzerError(t, `
+fun bump(rec: { x: Int, ...r }) -> { x: Int, ...r } { { ...rec, x: rec.x + 1, extra: 1 } }
+`, diagnostics.ErrA003)
+}
+
+func TestRowPolymorphism_CallerMustProvideFields(t *testing.T) {
+	expectAnalyzerError(t, `
+fun get_x(rec: { x: Int, ...r }) -> { x: Int, ...r } { rec }
+result = get_x({ y: 2 })
+`, diagnostics.ErrA003)
+	expectAnalyzerError(t, `
+fun get_x(rec: { x: Int, ...r }) -> { x: Int, ...r } { rec }
+result = get_x({ x: "a", y: 2 })
+`, diagnostics.ErrA003)
+}
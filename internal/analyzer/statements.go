@@ -93,6 +93,7 @@ func (w *walker) VisitProgram(program *ast.Program) {
 				}
 				w.symbolTable.DefinePendingTrait(s.Name.Value, w.currentModuleName)
 				w.symbolTable.SetDefinitionFile(s.Name.Value, w.currentFile)
+				w.nameAssociatedTypes(s)
 			case *ast.ConstantDeclaration:
 				if s != nil && s.Name != nil {
 					// Check for redefinition (including builtins from prelude)
@@ -108,6 +109,10 @@ func (w *walker) VisitProgram(program *ast.Program) {
 				if s != nil && s.Expression != nil {
 					if assign, ok := s.Expression.(*ast.AssignExpression); ok {
 						if ident, ok := assign.Left.(*ast.Identifier); ok {
+							// An earlier input defined the variable: this is an assignment
+							if w.incremental && w.isGlobalVariable(ident.Value) {
+								continue
+							}
 							// Check for redefinition (including builtins from prelude)
 							if sym, ok := w.symbolTable.Find(ident.Value); ok && !sym.IsPending {
 								w.addError(diagnostics.NewError(diagnostics.ErrA004, ident.GetToken(), ident.Value))
@@ -182,6 +187,8 @@ func (w *walker) VisitProgram(program *ast.Program) {
 					// In ModeInstances (Pass 3), we only checked signatures.
 					w.visit(s)
 				}
+			case *ast.TypeDeclarationStatement:
+				w.visitDerivedInstances(s)
 			case *ast.DirectiveStatement:
 				if s != nil {
 					w.visit(s)
@@ -193,10 +200,13 @@ func (w *walker) VisitProgram(program *ast.Program) {
 
 	if w.mode == ModeInstances {
 		// Pass 3: Instances (only InstanceDeclaration)
+		// Deriving clauses become instance declarations first
+		w.deriveInstances(program)
 		for _, stmt := range program.Statements {
 			if s, ok := stmt.(*ast.InstanceDeclaration); ok {
 				w.visit(s)
 			}
+			w.visitDerivedInstances(stmt)
 		}
 
 		// Flushing of injected statements is now handled by the caller (AnalyzeInstances)
@@ -241,8 +251,8 @@ func (w *walker) VisitProgram(program *ast.Program) {
 			}
 
 		case *ast.TypeDeclarationStatement:
-			// Already registered.
-			continue
+			// Already registered; only its derived instances remain
+			w.visitDerivedInstances(s)
 
 		case *ast.TraitDeclaration:
 			if s != nil {
@@ -264,6 +274,16 @@ func (w *walker) VisitProgram(program *ast.Program) {
 	}
 }
 
+// isGlobalVariable reports whether name is a variable defined at the top
+// level of the program that is not a constant.
+func (w *walker) isGlobalVariable(name string) bool {
+	if !w.symbolTable.IsDefinedLocally(name) {
+		return false
+	}
+	sym, ok := w.symbolTable.Find(name)
+	return ok && !sym.IsPending && !sym.IsConstant && sym.Kind == symbols.VariableSymbol
+}
+
 func (w *walker) analyzeFunctionBody(n *ast.FunctionStatement) {
 	// Create scope for parameters
 	outer := w.symbolTable
@@ -272,14 +292,21 @@ func (w *walker) analyzeFunctionBody(n *ast.FunctionStatement) {
 
 	// Register generic type parameters (from n.TypeParams)
 	rigidSubst := make(typesystem.Subst)
+	rowVars := signatureRowVariables(n)
 	for _, tp := range n.TypeParams {
 		// Use TVar for body analysis to allow unification (legacy behavior compatibility)
 		// We previously used Rigid TCon, but it caused issues with implicit unification in some tests.
 		kind := inferKindFromFunction(n, tp.Value, w.symbolTable)
-		tVar := typesystem.TVar{Name: tp.Value, KindVal: kind}
-		w.symbolTable.DefineType(tp.Value, tVar, "")
+		var paramType typesystem.Type = typesystem.TVar{Name: tp.Value, KindVal: kind}
+		if rowVars[tp.Value] {
+			// Row variables stand for the caller's other fields, which the body
+			// can neither drop nor invent: { x: Int, ...r } -> { x: Int, ...r } must
+			// return every field it was given.
+			paramType = typesystem.TCon{Name: tp.Value, KindVal: kind}
+		}
+		w.symbolTable.DefineType(tp.Value, paramType, "")
 		w.symbolTable.RegisterKind(tp.Value, kind)
-		rigidSubst[tp.Value] = tVar
+		rigidSubst[tp.Value] = paramType
 	}
 
 	// Register Witness Parameters (Dictionaries)
@@ -962,3 +989,28 @@ func (w *walker) VisitMatchExpression(n *ast.MatchExpression) {
 		w.symbolTable = outer
 	}
 }
+
+// signatureRowVariables returns the names used as record row variables
+// ({ x: Int, ...r }) in the receiver, parameter and return types of a function
+func signatureRowVariables(n *ast.FunctionStatement) map[string]bool {
+	rows := make(map[string]bool)
+	collect := func(t ast.Type) {
+		if t == nil {
+			return
+		}
+		ast.Inspect(t, func(node ast.Node) bool {
+			if rt, ok := node.(*ast.RecordType); ok && rt.Row != nil {
+				rows[rt.Row.Name.Value] = true
+			}
+			return true
+		})
+	}
+	if n.Receiver != nil {
+		collect(n.Receiver.Type)
+	}
+	for _, p := range n.Parameters {
+		collect(p.Type)
+	}
+	collect(n.ReturnType)
+	return rows
+}
@@ -0,0 +1,219 @@
+package analyzer
+
+import (
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/config"
+	"github.com/funvibe/funxy/internal/symbols"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// typeArgSource says where a typed builtin finds its type argument
+type typeArgSource int
+
+const (
+	// typeArgFromResult takes the T of the call's Result<String, T>, so
+	// decoders check their input against the type expected at the call site
+	typeArgFromResult typeArgSource = iota
+	// typeArgFromArgument takes the type passed as the call's first argument
+	typeArgFromArgument
+)
+
+// typedBuiltins are builtins that need a type known only to the analyzer.
+// The analyzer hands the type to the builtin as a leading type argument,
+// expanded by reifyDecodeTarget.
+var typedBuiltins = map[string]map[string]typeArgSource{
+	"json":       {"jsonDecode": typeArgFromResult},
+	"yaml":       {"yamlDecode": typeArgFromResult, "yamlRead": typeArgFromResult},
+	"jsonschema": {"jsonSchemaOf": typeArgFromArgument},
+}
+
+// nativeDecodeTypes are decoded by the builtins themselves and are not
+// expanded, even though the symbol table knows their definitions
+var nativeDecodeTypes = map[string]bool{
+	"String":              true,
+	"Json":                true,
+	config.ListTypeName:   true,
+	config.MapTypeName:    true,
+	config.OptionTypeName: true,
+	config.ResultTypeName: true,
+}
+
+// noteTypedBuiltinCall remembers n if it calls a typed builtin
+func (ctx *InferenceContext) noteTypedBuiltinCall(n *ast.CallExpression, table *symbols.SymbolTable) {
+	ident, ok := n.Function.(*ast.Identifier)
+	if !ok {
+		return
+	}
+	sym, ok := table.Find(ident.Value)
+	if !ok {
+		return
+	}
+	if source, ok := typedBuiltins[sym.OriginModule][ident.Value]; ok {
+		if ctx.TypedBuiltinCalls == nil {
+			ctx.TypedBuiltinCalls = make(map[*ast.CallExpression]typeArgSource)
+		}
+		ctx.TypedBuiltinCalls[n] = source
+	}
+}
+
+// reifyTypedBuiltinCalls sets the type argument of each typed builtin call,
+// with named types expanded
+func reifyTypedBuiltinCalls(ctx *InferenceContext, typeMap map[ast.Node]typesystem.Type, table *symbols.SymbolTable) {
+	for call, source := range ctx.TypedBuiltinCalls {
+		var target typesystem.Type
+		switch source {
+		case typeArgFromResult:
+			if app, ok := typeMap[call].(typesystem.TApp); ok && len(app.Args) == 2 {
+				target = app.Args[1]
+			}
+		case typeArgFromArgument:
+			target = typeArgument(call, typeMap, table)
+		}
+		if target == nil {
+			continue
+		}
+		if _, ok := target.(typesystem.TVar); ok {
+			continue
+		}
+		call.TypeArgs = []typesystem.Type{reifyDecodeTarget(target, table, map[string]bool{})}
+	}
+}
+
+// typeArgument returns the type passed as the first argument of call. A
+// named type is kept by name, since its type is that of its definition.
+func typeArgument(call *ast.CallExpression, typeMap map[ast.Node]typesystem.Type, table *symbols.SymbolTable) typesystem.Type {
+	if len(call.Arguments) == 0 {
+		return nil
+	}
+	if ident, ok := call.Arguments[0].(*ast.Identifier); ok {
+		if sym, ok := table.Find(ident.Value); ok && sym.Kind == symbols.TypeSymbol {
+			return typesystem.TCon{Name: ident.Value}
+		}
+	}
+	if t, ok := typeMap[call.Arguments[0]].(typesystem.TType); ok {
+		return t.Type
+	}
+	return nil
+}
+
+// reifyDecodeTarget expands the user-defined types in t, since the symbol
+// table is gone at runtime. An expanded type keeps its name and carries its
+// definition as the UnderlyingType of its TCon:
+//
+//   - a type alias carries the aliased type, with type arguments applied;
+//     a record type with a Default instance carries Default<{ ... }>
+//   - an ADT carries a TUnion of one TFunc per constructor, from the
+//     constructor's parameters to a TCon named after it
+//
+// Each type is expanded once; later and recursive occurrences are left bare
+// and resolved by the decoder through the expanded one.
+func reifyDecodeTarget(t typesystem.Type, table *symbols.SymbolTable, seen map[string]bool) typesystem.Type {
+	switch ty := t.(type) {
+	case typesystem.TCon:
+		return reifyNamedType(ty, nil, table, seen)
+	case typesystem.TApp:
+		args := make([]typesystem.Type, len(ty.Args))
+		for i, arg := range ty.Args {
+			args[i] = reifyDecodeTarget(arg, table, seen)
+		}
+		if con, ok := ty.Constructor.(typesystem.TCon); ok {
+			return reifyNamedType(con, args, table, seen)
+		}
+		return typesystem.TApp{Constructor: ty.Constructor, Args: args}
+	case typesystem.TRecord:
+		fields := make(map[string]typesystem.Type, len(ty.Fields))
+		for name, field := range ty.Fields {
+			fields[name] = reifyDecodeTarget(field, table, seen)
+		}
+		return typesystem.TRecord{Fields: fields, IsOpen: ty.IsOpen}
+	case typesystem.TTuple:
+		elements := make([]typesystem.Type, len(ty.Elements))
+		for i, el := range ty.Elements {
+			elements[i] = reifyDecodeTarget(el, table, seen)
+		}
+		return typesystem.TTuple{Elements: elements}
+	case typesystem.TUnion:
+		types := make([]typesystem.Type, len(ty.Types))
+		for i, member := range ty.Types {
+			types[i] = reifyDecodeTarget(member, table, seen)
+		}
+		return typesystem.TUnion{Types: types}
+	}
+	return t
+}
+
+// reifyNamedType expands con applied to args (nil for a plain TCon)
+func reifyNamedType(con typesystem.TCon, args []typesystem.Type, table *symbols.SymbolTable, seen map[string]bool) typesystem.Type {
+	bare := typesystem.Type(typesystem.TCon{Name: con.Name})
+	if args != nil {
+		bare = typesystem.TApp{Constructor: bare, Args: args}
+	}
+	if nativeDecodeTypes[con.Name] || seen[bare.String()] {
+		return bare
+	}
+
+	subst := typesystem.Subst{}
+	if params, ok := table.GetTypeParams(con.Name); ok {
+		for i, param := range params {
+			if i < len(args) {
+				subst[param] = args[i]
+			}
+		}
+	}
+
+	var def typesystem.Type
+	if alias, ok := table.GetTypeAlias(con.Name); ok {
+		seen[bare.String()] = true
+		def = reifyDecodeTarget(alias.Apply(subst), table, seen)
+		if _, isRecord := def.(typesystem.TRecord); isRecord && table.IsImplementationExists("Default", []typesystem.Type{bare}) {
+			def = typesystem.TApp{Constructor: typesystem.TCon{Name: "Default"}, Args: []typesystem.Type{def}}
+		}
+	} else if variants, ok := table.GetVariants(con.Name); ok {
+		seen[bare.String()] = true
+		ctors := make([]typesystem.Type, 0, len(variants))
+		for _, name := range variants {
+			ctors = append(ctors, reifyConstructor(name, args, table, seen))
+		}
+		def = typesystem.TUnion{Types: ctors}
+	} else {
+		return bare
+	}
+
+	expanded := typesystem.TCon{Name: con.Name, UnderlyingType: def}
+	if args != nil {
+		return typesystem.TApp{Constructor: expanded, Args: args}
+	}
+	return expanded
+}
+
+// reifyConstructor returns the TFunc describing constructor name, with the
+// type arguments of its ADT applied to its parameters
+func reifyConstructor(name string, args []typesystem.Type, table *symbols.SymbolTable, seen map[string]bool) typesystem.Type {
+	ctor := typesystem.TFunc{ReturnType: typesystem.TCon{Name: name}}
+	sym, ok := table.Find(name)
+	if !ok {
+		return ctor
+	}
+	ctorType := sym.Type
+	if forall, ok := ctorType.(typesystem.TForall); ok {
+		ctorType = forall.Type
+	}
+	fn, ok := ctorType.(typesystem.TFunc)
+	if !ok {
+		return ctor
+	}
+
+	// Type parameters are bound through the constructor's own result type
+	subst := typesystem.Subst{}
+	if ret, ok := fn.ReturnType.(typesystem.TApp); ok {
+		for i, arg := range ret.Args {
+			if tv, ok := arg.(typesystem.TVar); ok && i < len(args) {
+				subst[tv.Name] = args[i]
+			}
+		}
+	}
+	for _, param := range fn.Params {
+		ctor.Params = append(ctor.Params, reifyDecodeTarget(param.Apply(subst), table, seen))
+	}
+	return ctor
+}
@@ -20,6 +20,34 @@ func BuildType(t ast.Type, table *symbols.SymbolTable, errs *[]*diagnostics.Diag
 	case *ast.NamedType:
 		name := t.Name.Value
 
+		// Holes make instance heads only (see BuildInstanceHead): elsewhere
+		// Pair<_, String> would be a type constructor where a type is expected.
+		if name == "_" || hasTypeHoles(t) {
+			if errs != nil {
+				*errs = append(*errs, diagnostics.NewError(
+					diagnostics.ErrA003,
+					t.GetToken(),
+					"type hole '_' is only allowed in instance heads, as in instance Functor Pair<_, String>",
+				))
+			}
+			return typesystem.TCon{Name: "Unknown"}
+		}
+
+		// Associated types: Elem<c>, or Container.Elem<c> outside the trait
+		if table != nil {
+			if con, ok := resolveAssociatedType(t, table, errs); ok {
+				if len(t.Args) == 0 || con.Name == "Unknown" {
+					return con
+				}
+				args := []typesystem.Type{}
+				for _, arg := range t.Args {
+					args = append(args, BuildType(arg, table, errs))
+				}
+				checkTypeArgKinds(t, con.KindVal, args, table, errs)
+				return typesystem.TApp{Constructor: con, Args: args}
+			}
+		}
+
 		// 0. Check for qualified type names (e.g., "module.Type")
 		// These should NOT be treated as type variables even if they start with lowercase
 		isQualified := strings.Contains(name, ".")
@@ -106,6 +134,9 @@ func BuildType(t ast.Type, table *symbols.SymbolTable, errs *[]*diagnostics.Diag
 					"SqlDB":    "lib/sql",
 					"SqlTx":    "lib/sql",
 					"Date":     "lib/date",
+					"Zone":     "lib/date",
+					"Duration": "lib/date",
+					"Period":   "lib/date",
 					"Json":     "lib/json",
 				}
 				if pkg, needsImport := requiresImport[name]; needsImport {
@@ -170,28 +201,7 @@ func BuildType(t ast.Type, table *symbols.SymbolTable, errs *[]*diagnostics.Diag
 					}
 
 					// Kind Validation Logic (same as TCon)
-					currentKind := aliasKind
-					for i, arg := range args {
-						arrow, ok := currentKind.(typesystem.KArrow)
-						if !ok {
-							*errs = append(*errs, diagnostics.NewError(
-								diagnostics.ErrA003, // Type Error
-								t.GetToken(),
-								fmt.Sprintf("Type %s has kind %s, cannot be applied to argument %d", name, aliasKind, i+1),
-							))
-							break
-						}
-
-						argKind := GetKind(arg, table)
-						if _, isKVar := argKind.(typesystem.KVar); !isKVar && !arrow.Left.Equal(argKind) {
-							*errs = append(*errs, diagnostics.NewError(
-								diagnostics.ErrA003,
-								t.Args[i].GetToken(),
-								fmt.Sprintf("Type argument mismatch: expected kind %s, got %s", arrow.Left, argKind),
-							))
-						}
-						currentKind = arrow.Right
-					}
+					checkTypeArgKinds(t, aliasKind, args, table, errs)
 
 					// If it has arguments, return TApp instead of substituted TCon
 					// This ensures correct recursive resolution using ResolveTypeAlias
@@ -263,28 +273,7 @@ func BuildType(t ast.Type, table *symbols.SymbolTable, errs *[]*diagnostics.Diag
 					conKind = k
 				}
 
-				currentKind := conKind
-				for i, arg := range args {
-					arrow, ok := currentKind.(typesystem.KArrow)
-					if !ok {
-						*errs = append(*errs, diagnostics.NewError(
-							diagnostics.ErrA003, // Type Error
-							t.GetToken(),
-							fmt.Sprintf("Type %s has kind %s, cannot be applied to argument %d", name, conKind, i+1),
-						))
-						break
-					}
-
-					argKind := GetKind(arg, table)
-					if _, isKVar := argKind.(typesystem.KVar); !isKVar && !arrow.Left.Equal(argKind) {
-						*errs = append(*errs, diagnostics.NewError(
-							diagnostics.ErrA003,
-							t.Args[i].GetToken(),
-							fmt.Sprintf("Type argument mismatch: expected kind %s, got %s", arrow.Left, argKind),
-						))
-					}
-					currentKind = arrow.Right
-				}
+				checkTypeArgKinds(t, conKind, args, table, errs)
 			}
 
 			return typesystem.TApp{Constructor: tBase, Args: args}
@@ -309,6 +298,9 @@ func BuildType(t ast.Type, table *symbols.SymbolTable, errs *[]*diagnostics.Diag
 		for _, k := range keys {
 			fields[k] = BuildType(t.Fields[k], table, errs)
 		}
+		if t.Row != nil {
+			return typesystem.TRecord{Fields: fields, Row: BuildType(t.Row, table, errs), IsOpen: true}
+		}
 		return typesystem.TRecord{Fields: fields}
 
 	case *ast.FunctionType:
@@ -403,6 +395,134 @@ func GetKind(t typesystem.Type, table *symbols.SymbolTable) typesystem.Kind {
 		return typesystem.Star
 	case typesystem.TForall:
 		return typesystem.Star // ? or Kind of body?
+	case typesystem.TLambda:
+		return t.Kind()
 	}
 	return typesystem.Star
 }
+
+// resolveAssociatedType resolves the name of t to an associated type. Inside
+// a trait or one of its instances the bare name is the trait's own associated
+// type; elsewhere it is the one associated type of that name in scope, or it
+// must be qualified with the trait (Container.Elem) when several traits
+// declare it. Ordinary types of the same name take precedence.
+func resolveAssociatedType(t *ast.NamedType, table *symbols.SymbolTable, errs *[]*diagnostics.DiagnosticError) (typesystem.TCon, bool) {
+	name := t.Name.Value
+	if _, ok := table.GetAssociatedTypeTrait(name); ok {
+		kind, _ := table.GetKind(name)
+		return typesystem.TCon{Name: name, KindVal: kind}, true
+	}
+	if strings.Contains(name, ".") || len(name) == 0 || !unicode.IsUpper(rune(name[0])) {
+		return typesystem.TCon{}, false
+	}
+	if sym, ok := table.Find(name); ok && sym.Kind == symbols.TypeSymbol {
+		if con, ok := sym.Type.(typesystem.TCon); ok {
+			if _, isAssociated := table.GetAssociatedTypeTrait(con.Name); isAssociated {
+				return con, true
+			}
+		}
+		return typesystem.TCon{}, false
+	}
+	traits := table.GetAssociatedTypeTraits(name)
+	switch len(traits) {
+	case 0:
+		return typesystem.TCon{}, false
+	case 1:
+		qualified := symbols.AssociatedTypeName(traits[0], name)
+		kind, _ := table.GetKind(qualified)
+		return typesystem.TCon{Name: qualified, KindVal: kind}, true
+	}
+	if errs != nil {
+		qualified := make([]string, len(traits))
+		for i, trait := range traits {
+			qualified[i] = symbols.AssociatedTypeName(trait, name)
+		}
+		*errs = append(*errs, diagnostics.NewError(
+			diagnostics.ErrA003,
+			t.GetToken(),
+			fmt.Sprintf("associated type %s is ambiguous: it is declared by traits %s; write %s", name, strings.Join(traits, ", "), strings.Join(qualified, " or ")),
+		))
+	}
+	return typesystem.TCon{Name: "Unknown"}, true
+}
+
+// checkTypeArgKinds reports type arguments whose kinds do not fit the kind of
+// the type they are applied to. Kind variables are instantiated for each use,
+// so a kind-polymorphic type such as Proxy: k -> * takes Int as well as List.
+func checkTypeArgKinds(t *ast.NamedType, conKind typesystem.Kind, args []typesystem.Type, table *symbols.SymbolTable, errs *[]*diagnostics.DiagnosticError) {
+	subst := make(typesystem.KindSubst)
+	currentKind := typesystem.InstantiateKind(conKind)
+	for i, arg := range args {
+		currentKind = typesystem.ApplyKindSubst(subst, currentKind)
+		arrow, ok := currentKind.(typesystem.KArrow)
+		if !ok {
+			*errs = append(*errs, diagnostics.NewError(
+				diagnostics.ErrA003, // Type Error
+				t.GetToken(),
+				fmt.Sprintf("Type %s has kind %s, cannot be applied to argument %d", t.Name.Value, conKind, i+1),
+			))
+			return
+		}
+
+		argKind := GetKind(arg, table)
+		s, err := typesystem.UnifyKinds(arrow.Left, argKind)
+		if err != nil {
+			*errs = append(*errs, diagnostics.NewError(
+				diagnostics.ErrA003,
+				t.Args[i].GetToken(),
+				fmt.Sprintf("Type argument mismatch: expected kind %s, got %s", arrow.Left, argKind),
+			))
+		} else {
+			for k, v := range s {
+				subst[k] = v
+			}
+		}
+		currentKind = arrow.Right
+	}
+}
+
+// BuildInstanceHead builds an instance head. Unlike BuildType it accepts holes:
+// Pair<_, String> is the type-level function \_0. Pair<_0, String>.
+func BuildInstanceHead(t ast.Type, table *symbols.SymbolTable, errs *[]*diagnostics.DiagnosticError) typesystem.Type {
+	if nt, ok := t.(*ast.NamedType); ok && nt.Name.Value != "_" && hasTypeHoles(nt) && table != nil {
+		return buildTypeLambda(nt, table, errs)
+	}
+	return BuildType(t, table, errs)
+}
+
+// hasTypeHoles reports whether a type application leaves an argument open with '_'.
+func hasTypeHoles(t *ast.NamedType) bool {
+	for _, arg := range t.Args {
+		if nt, ok := arg.(*ast.NamedType); ok && nt.Name.Value == "_" && len(nt.Args) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// buildTypeLambda builds the type-level function for an application with
+// holes: each '_' becomes a parameter, in order, whose kind is the kind the
+// constructor expects at that position.
+func buildTypeLambda(t *ast.NamedType, table *symbols.SymbolTable, errs *[]*diagnostics.DiagnosticError) typesystem.Type {
+	conKind := typesystem.InstantiateKind(GetKind(BuildType(&ast.NamedType{Token: t.Token, Name: t.Name}, table, nil), table))
+
+	scope := symbols.NewEnclosedSymbolTable(table, symbols.ScopeFunction)
+	app := &ast.NamedType{Token: t.Token, Name: t.Name, Args: make([]ast.Type, len(t.Args))}
+	var params []typesystem.TVar
+	for i, arg := range t.Args {
+		var argKind typesystem.Kind = typesystem.Star
+		if arrow, ok := conKind.(typesystem.KArrow); ok {
+			argKind, conKind = arrow.Left, arrow.Right
+		}
+		if nt, ok := arg.(*ast.NamedType); ok && nt.Name.Value == "_" && len(nt.Args) == 0 {
+			param := typesystem.TVar{Name: typesystem.HoleName(len(params)), KindVal: argKind}
+			scope.DefineType(param.Name, param, "")
+			scope.RegisterKind(param.Name, argKind)
+			params = append(params, param)
+			app.Args[i] = &ast.NamedType{Token: nt.Token, Name: &ast.Identifier{Token: nt.Token, Value: param.Name}}
+			continue
+		}
+		app.Args[i] = arg
+	}
+	return typesystem.TLambda{Params: params, Body: BuildType(app, scope, errs)}
+}
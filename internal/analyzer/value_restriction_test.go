@@ -0,0 +1,120 @@
+package analyzer
+
+import (
+	"github.com/funvibe/funxy/internal/diagnostics"
+	"strings"
+	"testing"
+)
+
+// Reassigned variables are never generalized: they hold one type, fixed by
+// their first use, even when closures capture and reassign them.
+
+func TestValueRestriction_ReassignedBindingIsMonomorphic(t *testing.T) {
+	expectNoAnalyzerErrors(t, `
+fun f() {
+    val = None
+    val = Some(1)
+    val
+}
+`)
+	expectNoAnalyzerErrors(t, `
+val = None
+val = Some("a")
+`)
+}
+
+func TestValueRestriction_ClosureReassigningCapturedVariable(t *testing.T) {
+	expectNoAnalyzerErrors(t, `
+fun f() {
+    store = None
+    set = fun(x) { store = Some(x) }
+    set(1)
+    set(2)
+    store
+}
+`)
+	expectAnalyzerErrorContains(t, `
+fun f() {
+    store = None
+    set = fun(x) { store = Some(x) }
+    set(1)
+    set("a")
+}
+`, diagnostics.ErrA003, "set is not polymorphic, so an earlier use fixed this parameter type")
+}
+
+func TestValueRestriction_LocalFunctionReadingReassignedVariable(t *testing.T) {
+	// getter's return type is the type of val, which the reassignment fixes
+	expectAnalyzerErrorContains(t, `
+fun f() {
+    val = None
+    fun getter() { val }
+    val = Some("a")
+    match getter() {
+        Some(x) -> x + 1
+        None -> 0
+    }
+}
+`, diagnostics.ErrA003, "String")
+
+	expectAnalyzerErrorContains(t, `
+fun f() {
+    val = None
+    fun setter(x) { val = Some(x)  x }
+    fun getter() { val }
+    _ = setter("a")
+    match getter() {
+        Some(x) -> x + 1
+        None -> 0
+    }
+}
+`, diagnostics.ErrA003, "String")
+}
+
+func TestValueRestriction_ImmutableBindingsStayPolymorphic(t *testing.T) {
+	expectNoAnalyzerErrors(t, `
+fun f() {
+    ident = fun(x) { x }
+    nothing = None
+    a: Option<Int> = nothing
+    b: Option<String> = nothing
+    (ident(1), ident("s"), a, b)
+}
+`)
+}
+
+func TestValueRestriction_ShadowingParameterIsSeparateBinding(t *testing.T) {
+	// Assigning to the parameter must not make the outer ident monomorphic
+	expectNoAnalyzerErrors(t, `
+ident = fun(x) { x }
+g = fun(ident) {
+    ident = ident + 1
+    ident
+}
+_ = g(1)
+_ = (ident(1), ident("s"))
+`)
+	expectNoAnalyzerErrors(t, `
+fun f() {
+    ident = fun(x) { x }
+    for ident in [1, 2] {
+        ident = ident * 2
+    }
+    match Some(1) {
+        Some(ident) -> { ident = ident + 1 }
+        None -> {}
+    }
+    (ident(1), ident("s"))
+}
+`)
+}
+
+func TestValueRestriction_GenericCalleeHasNoHint(t *testing.T) {
+	e := expectAnalyzerError(t, `
+fun pick(a: t, b: t) -> t { a }
+x = pick(1, "s")
+`, diagnostics.ErrA003)
+	if e != nil && strings.Contains(e.Error(), "not polymorphic") {
+		t.Errorf("unexpected monomorphism hint for a generic function: %s", e.Error())
+	}
+}
@@ -182,6 +182,7 @@ type ForExpression struct {
 	Initializer Statement   // Optional, for traditional for loops (not yet implemented)
 	Condition   Expression  // For 'while' style loops
 	ItemName    *Identifier // For 'for in' loops
+	ItemPattern Pattern     // Destructuring target of a 'for in' loop; ItemName is then synthetic
 	Iterable    Expression  // For 'for in' loops
 	Body        *BlockStatement
 }
@@ -48,10 +48,12 @@ func (tt *TupleType) GetToken() token.Token {
 	return tt.Token
 }
 
-// RecordType represents a record/struct type, e.g. { x: Int, y: Bool }
+// RecordType represents a record/struct type, e.g. { x: Int, y: Bool },
+// or an open one with a row variable for the other fields: { x: Int, ...r }
 type RecordType struct {
 	Token  token.Token // The '{' token
 	Fields map[string]Type
+	Row    *NamedType // Optional row variable after '...'
 }
 
 func (rt *RecordType) Accept(v Visitor)     { v.VisitRecordType(rt) }
@@ -143,6 +145,10 @@ type TypeDeclarationStatement struct {
 	// For an ADT, this holds the various constructors.
 	TargetType   Type
 	Constructors []*DataConstructor
+	Deriving     []*Identifier // Traits named in a 'deriving (Equal, Show)' clause
+
+	// Analyzed Data (populated by Analyzer)
+	Derived []*InstanceDeclaration // Instances generated for Deriving; kept out of Program.Statements
 }
 
 func (tds *TypeDeclarationStatement) Accept(v Visitor)     { v.VisitTypeDeclarationStatement(tds) }
@@ -163,17 +169,27 @@ type FunctionalDependency struct {
 	To   []string // List of type variable names on RHS
 }
 
+// AssociatedType declares a type in a trait or defines it in an instance.
+// trait Container<c> { type Elem }
+// instance Container IntBag { type Elem = Int }
+type AssociatedType struct {
+	Token token.Token // 'type'
+	Name  *Identifier // 'Elem'
+	Type  Type        // 'Int' (nil in a trait)
+}
+
 // TraitDeclaration represents a type class (trait) definition.
 // trait Show<T> { fun show(val: T) -> String }
 // trait Order<T> : Equal<T> { fun compare(a: T, b: T) -> Ordering }
 type TraitDeclaration struct {
-	Token        token.Token            // 'trait'
-	Name         *Identifier            // 'Show'
-	TypeParams   []*Identifier          // ['T']
-	Constraints  []*TypeConstraint      // [t: Numeric]
-	SuperTraits  []Type                 // [Equal<T>] - inherited traits
-	Dependencies []FunctionalDependency // FunDeps: | a -> b
-	Signatures   []*FunctionStatement   // Method signatures
+	Token           token.Token            // 'trait'
+	Name            *Identifier            // 'Show'
+	TypeParams      []*Identifier          // ['T']
+	Constraints     []*TypeConstraint      // [t: Numeric]
+	SuperTraits     []Type                 // [Equal<T>] - inherited traits
+	Dependencies    []FunctionalDependency // FunDeps: | a -> b
+	AssociatedTypes []*AssociatedType      // type Elem
+	Signatures      []*FunctionStatement   // Method signatures
 }
 
 func (td *TraitDeclaration) Accept(v Visitor)     { v.VisitTraitDeclaration(td) }
@@ -199,8 +215,11 @@ type InstanceDeclaration struct {
 	Constraints []*TypeConstraint    // Constraints on the instance (e.g. instance Show a => Show (List a))
 	Methods     []*FunctionStatement // Implementations
 
+	AssociatedTypes []*AssociatedType // type Elem = Int
+
 	// Analyzed Data (populated by Analyzer)
 	AnalyzedRequirements []typesystem.Constraint // Constraints derived from usage/params
+	AnalyzedHeadName     string                  // Target with holes, e.g. "Pair<_, Int>"; keys its methods at runtime
 }
 
 func (id *InstanceDeclaration) Accept(v Visitor)     { v.VisitInstanceDeclaration(id) }
@@ -359,11 +378,13 @@ func (p *ListPattern) GetToken() token.Token {
 	return p.Token
 }
 
-// RecordPattern: { x: p1, y: p2 } or Point { x: p1, y: p2 }
+// RecordPattern: { x: p1, y: p2 } or Point { x: p1, y: p2 }, with an optional
+// rest binding the other fields: { x: p1, ...rest }
 type RecordPattern struct {
 	Token    token.Token // '{' or type name token
 	TypeName string      // Optional type name (e.g., "Point" in Point { x: p1 })
 	Fields   map[string]Pattern
+	Rest     *IdentifierPattern // Optional: the record without Fields
 }
 
 func (p *RecordPattern) Accept(v Visitor)     { v.VisitRecordPattern(p) }
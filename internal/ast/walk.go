@@ -0,0 +1,387 @@
+package ast
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Inspect traverses the AST in depth-first order, starting with node.
+// It calls f(node) for every non-nil node; if f returns false, the
+// children of that node are skipped.
+func Inspect(node Node, f func(Node) bool) {
+	if isNilNode(node) {
+		return
+	}
+	if !f(node) {
+		return
+	}
+	Children(node, func(child Node) {
+		Inspect(child, f)
+	})
+}
+
+// Children calls fn for every direct, non-nil child of node in source order
+// (record fields are visited in sorted key order, since they are stored in maps).
+func Children(node Node, fn func(Node)) {
+	visit := func(n Node) {
+		if !isNilNode(n) {
+			fn(n)
+		}
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		if n.Package != nil {
+			visit(n.Package)
+		}
+		for _, imp := range n.Imports {
+			visit(imp)
+		}
+		for _, stmt := range n.Statements {
+			// Imports are also kept in Statements by the parser; avoid visiting them twice.
+			if imp, ok := stmt.(*ImportStatement); ok && containsImport(n.Imports, imp) {
+				continue
+			}
+			if pkg, ok := stmt.(*PackageDeclaration); ok && pkg == n.Package {
+				continue
+			}
+			visit(stmt)
+		}
+	case *PackageDeclaration:
+		visit(n.Name)
+		for _, exp := range n.Exports {
+			if exp.Symbol != nil {
+				visit(exp.Symbol)
+			}
+			if exp.ModuleName != nil {
+				visit(exp.ModuleName)
+			}
+			for _, sym := range exp.Symbols {
+				visit(sym)
+			}
+		}
+	case *ImportStatement:
+		visit(n.Path)
+		if n.Alias != nil {
+			visit(n.Alias)
+		}
+		for _, sym := range n.Symbols {
+			visit(sym)
+		}
+		for _, sym := range n.Exclude {
+			visit(sym)
+		}
+	case *ConstantDeclaration:
+		if n.Name != nil {
+			visit(n.Name)
+		}
+		if n.Pattern != nil {
+			visit(n.Pattern)
+		}
+		if n.TypeAnnotation != nil {
+			visit(n.TypeAnnotation)
+		}
+		visit(n.Value)
+	case *ExpressionStatement:
+		visit(n.Expression)
+	case *FunctionStatement:
+		if n.Receiver != nil {
+			visitParameter(n.Receiver, visit)
+		}
+		if n.Name != nil {
+			visit(n.Name)
+		}
+		for _, tp := range n.TypeParams {
+			visit(tp)
+		}
+		for _, p := range n.Parameters {
+			visitParameter(p, visit)
+		}
+		if n.ReturnType != nil {
+			visit(n.ReturnType)
+		}
+		if n.Body != nil {
+			visit(n.Body)
+		}
+	case *FunctionLiteral:
+		for _, p := range n.Parameters {
+			visitParameter(p, visit)
+		}
+		if n.ReturnType != nil {
+			visit(n.ReturnType)
+		}
+		if n.Body != nil {
+			visit(n.Body)
+		}
+	case *TraitDeclaration:
+		visit(n.Name)
+		for _, tp := range n.TypeParams {
+			visit(tp)
+		}
+		for _, st := range n.SuperTraits {
+			visit(st)
+		}
+		for _, at := range n.AssociatedTypes {
+			visit(at.Name)
+		}
+		for _, sig := range n.Signatures {
+			visit(sig)
+		}
+	case *InstanceDeclaration:
+		if n.ModuleName != nil {
+			visit(n.ModuleName)
+		}
+		visit(n.TraitName)
+		for _, arg := range n.Args {
+			visit(arg)
+		}
+		for _, at := range n.AssociatedTypes {
+			visit(at.Name)
+			visit(at.Type)
+		}
+		for _, m := range n.Methods {
+			visit(m)
+		}
+	case *TypeDeclarationStatement:
+		visit(n.Name)
+		for _, tp := range n.TypeParameters {
+			visit(tp)
+		}
+		if n.TargetType != nil {
+			visit(n.TargetType)
+		}
+		for _, c := range n.Constructors {
+			visit(c)
+		}
+		for _, d := range n.Deriving {
+			visit(d)
+		}
+	case *DataConstructor:
+		visit(n.Name)
+		for _, p := range n.Parameters {
+			visit(p)
+		}
+	case *BlockStatement:
+		for _, stmt := range n.Statements {
+			visit(stmt)
+		}
+	case *ReturnStatement:
+		visit(n.Value)
+	case *BreakStatement:
+		visit(n.Value)
+	case *IfExpression:
+		visit(n.Condition)
+		if n.Consequence != nil {
+			visit(n.Consequence)
+		}
+		if n.Alternative != nil {
+			visit(n.Alternative)
+		}
+	case *ForExpression:
+		visit(n.Initializer)
+		visit(n.Condition)
+		if n.ItemName != nil {
+			visit(n.ItemName)
+		}
+		visit(n.Iterable)
+		if n.Body != nil {
+			visit(n.Body)
+		}
+	case *MatchExpression:
+		visit(n.Expression)
+		for _, arm := range n.Arms {
+			visit(arm.Pattern)
+			visit(arm.Guard)
+			visit(arm.Expression)
+		}
+	case *PrefixExpression:
+		visit(n.Right)
+	case *InfixExpression:
+		visit(n.Left)
+		visit(n.Right)
+	case *PostfixExpression:
+		visit(n.Left)
+	case *AssignExpression:
+		visit(n.Left)
+		if n.AnnotatedType != nil {
+			visit(n.AnnotatedType)
+		}
+		visit(n.Value)
+	case *PatternAssignExpression:
+		visit(n.Pattern)
+		if n.AnnotatedType != nil {
+			visit(n.AnnotatedType)
+		}
+		visit(n.Value)
+	case *CallExpression:
+		visit(n.Function)
+		for _, arg := range n.Arguments {
+			visit(arg)
+		}
+	case *TypeApplicationExpression:
+		visit(n.Expression)
+		for _, t := range n.TypeArguments {
+			visit(t)
+		}
+	case *IndexExpression:
+		visit(n.Left)
+		visit(n.Index)
+	case *MemberExpression:
+		visit(n.Left)
+		if n.Member != nil {
+			visit(n.Member)
+		}
+	case *AnnotatedExpression:
+		visit(n.Expression)
+		if n.TypeAnnotation != nil {
+			visit(n.TypeAnnotation)
+		}
+	case *SpreadExpression:
+		visit(n.Expression)
+	case *RangeExpression:
+		visit(n.Start)
+		visit(n.Next)
+		visit(n.End)
+	case *TupleLiteral:
+		for _, el := range n.Elements {
+			visit(el)
+		}
+	case *ListLiteral:
+		for _, el := range n.Elements {
+			visit(el)
+		}
+	case *MapLiteral:
+		for _, pair := range n.Pairs {
+			visit(pair.Key)
+			visit(pair.Value)
+		}
+	case *RecordLiteral:
+		visit(n.Spread)
+		for _, key := range sortedKeys(n.Fields) {
+			visit(n.Fields[key])
+		}
+	case *InterpolatedString:
+		for _, part := range n.Parts {
+			visit(part)
+		}
+	case *ListComprehension:
+		visit(n.Output)
+		visitClauses(n.Clauses, visit)
+	case *MapComprehension:
+		visit(n.Key)
+		visit(n.Value)
+		visitClauses(n.Clauses, visit)
+	case *ConstructorPattern:
+		if n.Name != nil {
+			visit(n.Name)
+		}
+		for _, el := range n.Elements {
+			visit(el)
+		}
+	case *TuplePattern:
+		for _, el := range n.Elements {
+			visit(el)
+		}
+	case *ListPattern:
+		for _, el := range n.Elements {
+			visit(el)
+		}
+	case *SpreadPattern:
+		visit(n.Pattern)
+	case *RecordPattern:
+		for _, key := range sortedKeys(n.Fields) {
+			visit(n.Fields[key])
+		}
+		if n.Rest != nil {
+			visit(n.Rest)
+		}
+	case *TypePattern:
+		visit(n.Type)
+	case *NamedType:
+		if n.Name != nil {
+			visit(n.Name)
+		}
+		for _, arg := range n.Args {
+			visit(arg)
+		}
+	case *TupleType:
+		for _, t := range n.Types {
+			visit(t)
+		}
+	case *RecordType:
+		for _, key := range sortedKeys(n.Fields) {
+			visit(n.Fields[key])
+		}
+		if n.Row != nil {
+			visit(n.Row)
+		}
+	case *FunctionType:
+		for _, p := range n.Parameters {
+			visit(p)
+		}
+		visit(n.ReturnType)
+	case *ForallType:
+		for _, v := range n.Vars {
+			visit(v)
+		}
+		visit(n.Type)
+	case *UnionType:
+		for _, t := range n.Types {
+			visit(t)
+		}
+	}
+}
+
+func visitParameter(p *Parameter, visit func(Node)) {
+	if p == nil {
+		return
+	}
+	if p.Name != nil {
+		visit(p.Name)
+	}
+	if p.Type != nil {
+		visit(p.Type)
+	}
+	if p.Default != nil {
+		visit(p.Default)
+	}
+}
+
+func visitClauses(clauses []CompClause, visit func(Node)) {
+	for _, clause := range clauses {
+		switch c := clause.(type) {
+		case *CompGenerator:
+			visit(c.Pattern)
+			visit(c.Iterable)
+		case *CompFilter:
+			visit(c.Condition)
+		}
+	}
+}
+
+func containsImport(imports []*ImportStatement, target *ImportStatement) bool {
+	for _, imp := range imports {
+		if imp == target {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isNilNode reports whether node is nil or a typed nil pointer.
+func isNilNode(node Node) bool {
+	if node == nil {
+		return true
+	}
+	val := reflect.ValueOf(node)
+	return val.Kind() == reflect.Ptr && val.IsNil()
+}
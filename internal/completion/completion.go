@@ -0,0 +1,126 @@
+// Package completion collects the names that can be completed at a position
+// in a program. It is shared by the language server and the REPL.
+package completion
+
+import (
+	"strings"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/modules"
+	"github.com/funvibe/funxy/internal/symbols"
+	"github.com/funvibe/funxy/internal/token"
+)
+
+// Kind is the kind of a completion item.
+type Kind int
+
+const (
+	Keyword Kind = iota
+	Variable
+	Function
+	Type
+	Trait
+	Constructor
+)
+
+// Item is a completion candidate.
+type Item struct {
+	Label         string
+	Kind          Kind
+	Detail        string // type or signature
+	Documentation string // markdown, empty if there is none
+}
+
+// Items returns the keywords, the local names declared before line:char
+// (1-based) in the nodes of path, the symbols of table and the prelude
+// builtins. path holds the nodes enclosing the position, outermost first; it
+// may be nil when there is no surrounding code.
+func Items(path []ast.Node, table *symbols.SymbolTable, line, char int) []Item {
+	var items []Item
+	seen := make(map[string]bool)
+
+	add := func(item Item) {
+		if !seen[item.Label] {
+			items = append(items, item)
+			seen[item.Label] = true
+		}
+	}
+
+	for keyword := range token.Keywords {
+		add(Item{Label: keyword, Kind: Keyword})
+	}
+
+	// 1. Locals from the enclosing functions and blocks
+	for _, node := range path {
+		switch n := node.(type) {
+		case *ast.FunctionStatement:
+			for _, param := range n.Parameters {
+				if param.Name != nil {
+					add(Item{Label: param.Name.Value, Kind: Variable, Detail: "parameter"})
+				}
+			}
+		case *ast.BlockStatement:
+			for _, stmt := range n.Statements {
+				// Only statements before the cursor are in scope
+				stmtToken := stmt.GetToken()
+				if stmtToken.Line > line || (stmtToken.Line == line && stmtToken.Column >= char) {
+					continue
+				}
+				if decl, ok := stmt.(*ast.ConstantDeclaration); ok && decl.Name != nil {
+					add(Item{Label: decl.Name.Value, Kind: Variable, Detail: "local constant"})
+				}
+				if exprStmt, ok := stmt.(*ast.ExpressionStatement); ok {
+					if assign, ok := exprStmt.Expression.(*ast.AssignExpression); ok {
+						if ident, ok := assign.Left.(*ast.Identifier); ok {
+							add(Item{Label: ident.Value, Kind: Variable, Detail: "local variable"})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// 2. Global symbols
+	if table != nil {
+		for name, symbol := range table.All() {
+			kind := Variable
+			switch symbol.Kind {
+			case symbols.VariableSymbol:
+				if symbol.Type != nil && strings.Contains(symbol.Type.String(), "->") {
+					kind = Function
+				}
+			case symbols.TypeSymbol:
+				kind = Type
+			case symbols.TraitSymbol:
+				kind = Trait
+			case symbols.ConstructorSymbol:
+				kind = Constructor
+			}
+			detail := ""
+			if symbol.Type != nil {
+				detail = symbol.Type.String()
+			}
+			add(Item{Label: name, Kind: kind, Detail: detail})
+		}
+	}
+
+	// 3. Builtins from the prelude
+	if prelude := modules.GetDocPackage("prelude"); prelude != nil {
+		for _, fn := range prelude.Functions {
+			add(Item{Label: fn.Name, Kind: Function, Detail: fn.Signature, Documentation: fn.Description})
+		}
+		for _, t := range prelude.Types {
+			add(Item{Label: t.Name, Kind: Type, Detail: t.Signature, Documentation: t.Description})
+		}
+		for _, t := range prelude.Traits {
+			// Trait entries are named like "Show<T>"
+			name := t.Name
+			if idx := strings.Index(name, "<"); idx != -1 {
+				name = name[:idx]
+			}
+			add(Item{Label: name, Kind: Trait, Detail: t.Signature, Documentation: t.Description})
+		}
+	}
+
+	return items
+}
@@ -13,8 +13,30 @@ const (
 	PhaseParser   Phase = "parser"
 	PhaseAnalyzer Phase = "analyzer"
 	PhaseRuntime  Phase = "runtime"
+	PhaseLint     Phase = "lint"
 )
 
+// Severity is the level at which a diagnostic is reported.
+// The zero value is an error, so diagnostics created without a severity stay fatal.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	default:
+		return "error"
+	}
+}
+
 type ErrorCode string
 
 const (
@@ -46,37 +68,54 @@ const (
 
 	// Compilation Errors
 	ErrC001 ErrorCode = "C001" // Compilation error
+
+	// Lint Warnings
+	WarnW001 ErrorCode = "W001" // Unused variable
+	WarnW002 ErrorCode = "W002" // Unused import
+	WarnW003 ErrorCode = "W003" // Unused parameter
+	WarnW004 ErrorCode = "W004" // Shadowed binding
+	WarnW005 ErrorCode = "W005" // Unreachable code
+	WarnW006 ErrorCode = "W006" // Discarded Result value
+	WarnW007 ErrorCode = "W007" // Redundant '?'
 )
 
 var errorTemplates = map[ErrorCode]string{
-	ErrL001: "invalid character: '%s'",
-	ErrP001: "unexpected token: expected '%s', but got '%s'",
-	ErrP002: "expected an identifier on the left side of an assignment",
-	ErrP003: "could not parse '%s' as an integer",
-	ErrP004: "cannot parse expression starting with '%s'",
-	ErrP005: "expected next token to be '%s', but got '%s' instead",
-	ErrP006: "%s",
-	ErrP007: "index assignment is not supported. Use 'lib/list' update/insert functions for immutable list modification",
-	ErrP008: "%s",
-	ErrA001: "undeclared variable: '%s'",
-	ErrA002: "undeclared type: '%s'",
-	ErrA003: "type error: %s",
-	ErrA004: "redefinition of symbol: '%s'",
-	ErrA005: "type mismatch in assignment: expected %s, got %s",
-	ErrA006: "undefined symbol: '%s'",
-	ErrA007: "match expression is not exhaustive. Missing cases: %s",
-	ErrA008: "naming convention: %s",
-	ErrR001: "runtime error: %s",
-	ErrC001: "compilation error: %s",
+	ErrL001:  "invalid character: '%s'",
+	ErrP001:  "unexpected token: expected '%s', but got '%s'",
+	ErrP002:  "expected an identifier on the left side of an assignment",
+	ErrP003:  "could not parse '%s' as an integer",
+	ErrP004:  "cannot parse expression starting with '%s'",
+	ErrP005:  "expected next token to be '%s', but got '%s' instead",
+	ErrP006:  "%s",
+	ErrP007:  "index assignment is not supported. Use 'lib/list' update/insert functions for immutable list modification",
+	ErrP008:  "%s",
+	ErrA001:  "undeclared variable: '%s'",
+	ErrA002:  "undeclared type: '%s'",
+	ErrA003:  "type error: %s",
+	ErrA004:  "redefinition of symbol: '%s'",
+	ErrA005:  "type mismatch in assignment: expected %s, got %s",
+	ErrA006:  "undefined symbol: '%s'",
+	ErrA007:  "match expression is not exhaustive. Missing cases: %s",
+	ErrA008:  "naming convention: %s",
+	ErrR001:  "runtime error: %s",
+	ErrC001:  "compilation error: %s",
+	WarnW001: "unused variable: '%s'",
+	WarnW002: "unused import: '%s'",
+	WarnW003: "unused parameter: '%s'",
+	WarnW004: "'%s' shadows a binding declared at line %d",
+	WarnW005: "unreachable code after '%s'",
+	WarnW006: "discarded value of type %s",
+	WarnW007: "redundant '?': %s",
 }
 
 type DiagnosticError struct {
-	Code  ErrorCode
-	Phase Phase
-	Args  []interface{}
-	Token token.Token
-	File  string
-	Hint  string // Optional hint for fixing the error
+	Code     ErrorCode
+	Phase    Phase
+	Severity Severity
+	Args     []interface{}
+	Token    token.Token
+	File     string
+	Hint     string // Optional hint for fixing the error
 }
 
 func (e *DiagnosticError) Error() string {
@@ -102,9 +141,9 @@ func (e *DiagnosticError) Error() string {
 
 	var result string
 	if e.Token.Line > 0 {
-		result = fmt.Sprintf("%s%serror at %d:%d [%s]: %s", prefix, phaseStr, e.Token.Line, e.Token.Column, e.Code, message)
+		result = fmt.Sprintf("%s%s%s at %d:%d [%s]: %s", prefix, phaseStr, e.Severity, e.Token.Line, e.Token.Column, e.Code, message)
 	} else {
-		result = fmt.Sprintf("%s%serror [%s]: %s", prefix, phaseStr, e.Code, message)
+		result = fmt.Sprintf("%s%s%s [%s]: %s", prefix, phaseStr, e.Severity, e.Code, message)
 	}
 
 	// Hints disabled - they're unstable and break tests
@@ -123,6 +162,17 @@ func NewError(code ErrorCode, tok token.Token, args ...interface{}) *DiagnosticE
 	}
 }
 
+// NewWarning creates a lint diagnostic with the given severity
+func NewWarning(code ErrorCode, severity Severity, tok token.Token, args ...interface{}) *DiagnosticError {
+	return &DiagnosticError{
+		Code:     code,
+		Phase:    PhaseLint,
+		Severity: severity,
+		Token:    tok,
+		Args:     args,
+	}
+}
+
 // NewPhaseError creates an error with phase information
 func NewPhaseError(phase Phase, code ErrorCode, tok token.Token, args ...interface{}) *DiagnosticError {
 	return &DiagnosticError{
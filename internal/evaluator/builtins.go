@@ -559,6 +559,9 @@ func ASTTypeToTypesystem(t ast.Type) typesystem.Type {
 		for k, v := range tt.Fields {
 			fields[k] = ASTTypeToTypesystem(v)
 		}
+		if tt.Row != nil {
+			return typesystem.TRecord{Fields: fields, Row: ASTTypeToTypesystem(tt.Row), IsOpen: true}
+		}
 		return typesystem.TRecord{Fields: fields}
 	default:
 		return typesystem.TCon{Name: "?"}
@@ -674,17 +677,9 @@ func objectToString(obj Object) string {
 }
 
 // StringToList converts a Go string to List<Char>
-// It uses a cache for small ASCII characters to reduce allocations.
+// The text is packed as-is; Chars are only created when elements are read.
 func StringToList(s string) *List {
-	chars := make([]Object, 0, len(s))
-	for _, r := range s {
-		if r >= 0 && r < 256 {
-			chars = append(chars, smallChars[r])
-		} else {
-			chars = append(chars, &Char{Value: int64(r)})
-		}
-	}
-	return newListWithType(chars, "Char")
+	return &List{str: newPackedString(s), ElementType: "Char"}
 }
 
 // stringToList is a deprecated alias for StringToList
@@ -1019,7 +1014,11 @@ func listToString(obj Object) string {
 }
 
 // ListToString converts List<Char> to Go string (exported for VM)
+// Packed strings are returned without copying.
 func ListToString(list *List) string {
+	if list.str != nil {
+		return list.str.s
+	}
 	var sb strings.Builder
 	sb.Grow(list.Len()) // Pre-allocate buffer
 	for _, el := range list.ToSlice() {
@@ -1118,11 +1117,7 @@ func makeFail(err Object) Object {
 
 // makeFailStr creates a Result Fail with string error message
 func makeFailStr(errMsg string) Object {
-	chars := make([]Object, 0, len(errMsg))
-	for _, r := range errMsg {
-		chars = append(chars, &Char{Value: int64(r)})
-	}
-	return makeFail(newList(chars))
+	return makeFail(StringToList(errMsg))
 }
 
 // parseStringToType parses a string into a value of the specified type
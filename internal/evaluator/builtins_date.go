@@ -28,6 +28,15 @@ func DateBuiltins() map[string]*Builtin {
 		"dateOffset":     {Fn: builtinDateOffset, Name: "dateOffset"},
 		"dateWithOffset": {Fn: builtinDateWithOffset, Name: "dateWithOffset"},
 
+		// Named zones
+		"zoneLoad":        {Fn: builtinZoneLoad, Name: "zoneLoad"},
+		"zoneName":        {Fn: builtinZoneName, Name: "zoneName"},
+		"zoneOffsetAt":    {Fn: builtinZoneOffsetAt, Name: "zoneOffsetAt"},
+		"dateNowIn":       {Fn: builtinDateNowIn, Name: "dateNowIn"},
+		"dateNewIn":       {Fn: builtinDateNewIn, Name: "dateNewIn"},
+		"dateToZone":      {Fn: builtinDateToZone, Name: "dateToZone"},
+		"dateAddPeriodIn": {Fn: builtinDateAddPeriodIn, Name: "dateAddPeriodIn"},
+
 		// Formatting
 		"dateFormat": {Fn: builtinDateFormat, Name: "dateFormat"},
 		"dateParse":  {Fn: builtinDateParse, Name: "dateParse"},
@@ -52,6 +61,15 @@ func DateBuiltins() map[string]*Builtin {
 		// Difference
 		"dateDiffDays":    {Fn: builtinDateDiffDays, Name: "dateDiffDays"},
 		"dateDiffSeconds": {Fn: builtinDateDiffSeconds, Name: "dateDiffSeconds"},
+
+		// Durations and periods
+		"durationParse":   {Fn: builtinDurationParse, Name: "durationParse"},
+		"durationFormat":  {Fn: builtinDurationFormat, Name: "durationFormat"},
+		"durationBetween": {Fn: builtinDurationBetween, Name: "durationBetween"},
+		"periodParse":     {Fn: builtinPeriodParse, Name: "periodParse"},
+		"periodFormat":    {Fn: builtinPeriodFormat, Name: "periodFormat"},
+		"dateAddDuration": {Fn: builtinDateAddDuration, Name: "dateAddDuration"},
+		"dateAddPeriod":   {Fn: builtinDateAddPeriod, Name: "dateAddPeriod"},
 	}
 }
 
@@ -70,6 +88,19 @@ func RegisterDateBuiltins(env *Environment) {
 		},
 	}
 	env.Set("Date", &TypeObject{TypeVal: dateType})
+	env.Set("Zone", &TypeObject{TypeVal: typesystem.TCon{Name: "Zone"}})
+	// Duration = { seconds: Int }
+	env.Set("Duration", &TypeObject{TypeVal: typesystem.TRecord{
+		Fields: map[string]typesystem.Type{"seconds": typesystem.Int},
+	}})
+	// Period = { years: Int, months: Int, days: Int }
+	env.Set("Period", &TypeObject{TypeVal: typesystem.TRecord{
+		Fields: map[string]typesystem.Type{
+			"years":  typesystem.Int,
+			"months": typesystem.Int,
+			"days":   typesystem.Int,
+		},
+	}})
 
 	// Functions
 	builtins := DateBuiltins()
@@ -0,0 +1,308 @@
+package evaluator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Durations and periods for lib/date.
+//
+// A Duration { seconds } is an exact amount of elapsed time. A Period
+// { years, months, days } is an amount of calendar time, whose length
+// depends on where it is added: a month after January 31 is February 28 or
+// 29, and a day is 23 or 25 hours across a DST change. Both are written in
+// ISO 8601 as PnYnMnWnDTnHnMnS; a Duration takes weeks, days (of 24 hours)
+// and the time part, a Period the date part.
+
+type period struct {
+	years, months, days int64
+}
+
+func makeDuration(seconds int64) *RecordInstance {
+	return NewRecord(map[string]Object{"seconds": &Integer{Value: seconds}})
+}
+
+func makePeriod(p period) *RecordInstance {
+	return NewRecord(map[string]Object{
+		"years":  &Integer{Value: p.years},
+		"months": &Integer{Value: p.months},
+		"days":   &Integer{Value: p.days},
+	})
+}
+
+func durationFromObject(obj Object) (int64, bool) {
+	rec, ok := obj.(*RecordInstance)
+	if !ok {
+		return 0, false
+	}
+	seconds, ok := rec.Get("seconds").(*Integer)
+	if !ok {
+		return 0, false
+	}
+	return seconds.Value, true
+}
+
+func periodFromObject(obj Object) (period, bool) {
+	rec, ok := obj.(*RecordInstance)
+	if !ok {
+		return period{}, false
+	}
+	years, ok1 := rec.Get("years").(*Integer)
+	months, ok2 := rec.Get("months").(*Integer)
+	days, ok3 := rec.Get("days").(*Integer)
+	if !ok1 || !ok2 || !ok3 {
+		return period{}, false
+	}
+	return period{years.Value, months.Value, days.Value}, true
+}
+
+// isoComponents are the values of an ISO 8601 duration by designator:
+// Y, M, W and D for the date part, h, m and s for the time part
+type isoComponents map[byte]int64
+
+// parseISODuration reads PnYnMnWnDTnHnMnS, with an optional leading sign
+// and signed components. The seconds may have a fraction, written with a
+// point or a comma; it is dropped, since durations count whole seconds.
+func parseISODuration(s string) (isoComponents, error) {
+	sign := int64(1)
+	rest := s
+	if strings.HasPrefix(rest, "-") {
+		sign, rest = -1, rest[1:]
+	} else if strings.HasPrefix(rest, "+") {
+		rest = rest[1:]
+	}
+	if !strings.HasPrefix(rest, "P") {
+		return nil, fmt.Errorf("%q does not start with P", s)
+	}
+	rest = rest[1:]
+
+	components := isoComponents{}
+	order := "YMWD"
+	inTime := false
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return nil, fmt.Errorf("%q has two time parts", s)
+			}
+			inTime, order, rest = true, "HMS", rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("%q has an empty time part", s)
+			}
+			continue
+		}
+		end := 0
+		if end < len(rest) && (rest[end] == '-' || rest[end] == '+') {
+			end++
+		}
+		for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+			end++
+		}
+		whole := rest[:end]
+		if end < len(rest) && (rest[end] == '.' || rest[end] == ',') {
+			end++
+			digits := end
+			for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+				end++
+			}
+			if end == digits {
+				return nil, fmt.Errorf("%q has an invalid number %q", s, rest[:end])
+			}
+		}
+		if end == len(rest) {
+			return nil, fmt.Errorf("%q ends without a designator", s)
+		}
+		n, err := strconv.ParseInt(whole, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q has an invalid number %q", s, rest[:end])
+		}
+		designator := rest[end]
+		if len(whole) < end && !(inTime && designator == 'S') {
+			return nil, fmt.Errorf("%q has a fraction on %c, only seconds can have one", s, designator)
+		}
+		i := strings.IndexByte(order, designator)
+		if i < 0 {
+			return nil, fmt.Errorf("%q has a misplaced or unknown designator %c", s, designator)
+		}
+		order = order[i+1:]
+		if inTime {
+			designator += 'a' - 'A'
+		}
+		components[designator] = sign * n
+		rest = rest[end+1:]
+	}
+	if len(components) == 0 {
+		return nil, fmt.Errorf("%q has no components", s)
+	}
+	return components, nil
+}
+
+// formatISODuration writes the seconds of a duration in hours, minutes and
+// seconds, as in PT26H3M
+func formatISODuration(seconds int64) string {
+	if seconds == 0 {
+		return "PT0S"
+	}
+	var b strings.Builder
+	if seconds < 0 {
+		b.WriteByte('-')
+		seconds = -seconds
+	}
+	b.WriteString("PT")
+	if h := seconds / 3600; h != 0 {
+		fmt.Fprintf(&b, "%dH", h)
+	}
+	if m := seconds % 3600 / 60; m != 0 {
+		fmt.Fprintf(&b, "%dM", m)
+	}
+	if s := seconds % 60; s != 0 {
+		fmt.Fprintf(&b, "%dS", s)
+	}
+	return b.String()
+}
+
+func formatISOPeriod(p period) string {
+	if p == (period{}) {
+		return "P0D"
+	}
+	var b strings.Builder
+	b.WriteByte('P')
+	if p.years != 0 {
+		fmt.Fprintf(&b, "%dY", p.years)
+	}
+	if p.months != 0 {
+		fmt.Fprintf(&b, "%dM", p.months)
+	}
+	if p.days != 0 {
+		fmt.Fprintf(&b, "%dD", p.days)
+	}
+	return b.String()
+}
+
+// durationParse: (String) -> Result<String, Duration>
+func builtinDurationParse(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("durationParse expects 1 argument, got %d", len(args))
+	}
+	str, ok := args[0].(*List)
+	if !ok {
+		return newError("durationParse expects a string, got %s", args[0].Type())
+	}
+	c, err := parseISODuration(listToString(str))
+	if err != nil {
+		return makeFailStr("durationParse: " + err.Error())
+	}
+	if c['Y'] != 0 || c['M'] != 0 {
+		return makeFailStr("durationParse: years and months have no fixed length, use periodParse")
+	}
+	seconds := (c['W']*7+c['D'])*86400 + c['h']*3600 + c['m']*60 + c['s']
+	return makeOk(makeDuration(seconds))
+}
+
+// durationFormat: (Duration) -> String
+func builtinDurationFormat(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("durationFormat expects 1 argument, got %d", len(args))
+	}
+	seconds, ok := durationFromObject(args[0])
+	if !ok {
+		return newError("durationFormat expects a Duration record, got %s", args[0].Type())
+	}
+	return stringToList(formatISODuration(seconds))
+}
+
+// periodParse: (String) -> Result<String, Period>
+func builtinPeriodParse(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("periodParse expects 1 argument, got %d", len(args))
+	}
+	str, ok := args[0].(*List)
+	if !ok {
+		return newError("periodParse expects a string, got %s", args[0].Type())
+	}
+	c, err := parseISODuration(listToString(str))
+	if err != nil {
+		return makeFailStr("periodParse: " + err.Error())
+	}
+	if c['h'] != 0 || c['m'] != 0 || c['s'] != 0 {
+		return makeFailStr("periodParse: a Period has no time part, use durationParse")
+	}
+	return makeOk(makePeriod(period{years: c['Y'], months: c['M'], days: c['W']*7 + c['D']}))
+}
+
+// periodFormat: (Period) -> String
+func builtinPeriodFormat(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("periodFormat expects 1 argument, got %d", len(args))
+	}
+	p, ok := periodFromObject(args[0])
+	if !ok {
+		return newError("periodFormat expects a Period record, got %s", args[0].Type())
+	}
+	return stringToList(formatISOPeriod(p))
+}
+
+// durationBetween: (Date, Date) -> Duration (from the first to the second)
+func builtinDurationBetween(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 {
+		return newError("durationBetween expects 2 arguments, got %d", len(args))
+	}
+	from, ok := args[0].(*RecordInstance)
+	if !ok {
+		return newError("durationBetween expects a Date record, got %s", args[0].Type())
+	}
+	to, ok := args[1].(*RecordInstance)
+	if !ok {
+		return newError("durationBetween expects a Date record, got %s", args[1].Type())
+	}
+	t1, ok := dateToTime(from)
+	if !ok {
+		return newError("durationBetween: invalid Date record")
+	}
+	t2, ok := dateToTime(to)
+	if !ok {
+		return newError("durationBetween: invalid Date record")
+	}
+	return makeDuration(t2.Unix() - t1.Unix())
+}
+
+// dateAddDuration: (Date, Duration) -> Date (preserves offset)
+func builtinDateAddDuration(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 {
+		return newError("dateAddDuration expects 2 arguments, got %d", len(args))
+	}
+	date, ok := args[0].(*RecordInstance)
+	if !ok {
+		return newError("dateAddDuration expects a Date record, got %s", args[0].Type())
+	}
+	seconds, ok := durationFromObject(args[1])
+	if !ok {
+		return newError("dateAddDuration expects a Duration record, got %s", args[1].Type())
+	}
+	t, ok := dateToTime(date)
+	if !ok {
+		return newError("dateAddDuration: invalid Date record")
+	}
+	return makeDateWithOffset(t.Add(time.Duration(seconds)*time.Second), getDateOffset(date))
+}
+
+// dateAddPeriod: (Date, Period) -> Date (preserves offset)
+func builtinDateAddPeriod(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 {
+		return newError("dateAddPeriod expects 2 arguments, got %d", len(args))
+	}
+	date, ok := args[0].(*RecordInstance)
+	if !ok {
+		return newError("dateAddPeriod expects a Date record, got %s", args[0].Type())
+	}
+	p, ok := periodFromObject(args[1])
+	if !ok {
+		return newError("dateAddPeriod expects a Period record, got %s", args[1].Type())
+	}
+	t, ok := dateToTime(date)
+	if !ok {
+		return newError("dateAddPeriod: invalid Date record")
+	}
+	return makeDateWithOffset(t.AddDate(int(p.years), int(p.months), int(p.days)), getDateOffset(date))
+}
@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseISODuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    isoComponents
+		wantErr string
+	}{
+		{"P1Y2M3W4DT5H6M7S", isoComponents{'Y': 1, 'M': 2, 'W': 3, 'D': 4, 'h': 5, 'm': 6, 's': 7}, ""},
+		{"PT1M", isoComponents{'m': 1}, ""},
+		{"P1M", isoComponents{'M': 1}, ""},
+		{"-P1DT2H", isoComponents{'D': -1, 'h': -2}, ""},
+		{"+P1D", isoComponents{'D': 1}, ""},
+		{"P1M-2D", isoComponents{'M': 1, 'D': -2}, ""},
+		{"-P1M-2D", isoComponents{'M': -1, 'D': 2}, ""},
+		{"P0D", isoComponents{'D': 0}, ""},
+		{"", nil, `"" does not start with P`},
+		{"P", nil, `"P" has no components`},
+		{"PT", nil, `"PT" has an empty time part`},
+		{"P1DT1HT1M", nil, `"P1DT1HT1M" has two time parts`},
+		{"P1", nil, `"P1" ends without a designator`},
+		{"PT1.5S", isoComponents{'s': 1}, ""},
+		{"PT0,5S", isoComponents{'s': 0}, ""},
+		{"-PT1M2.75S", isoComponents{'m': -1, 's': -2}, ""},
+		{"PT-1.5S", isoComponents{'s': -1}, ""},
+		{"PT1.S", nil, `"PT1.S" has an invalid number "1."`},
+		{"PT.5S", nil, `"PT.5S" has an invalid number ".5"`},
+		{"PT1.5", nil, `"PT1.5" ends without a designator`},
+		{"PT1.5H", nil, `"PT1.5H" has a fraction on H, only seconds can have one`},
+		{"P1.5D", nil, `"P1.5D" has a fraction on D, only seconds can have one`},
+		{"PD", nil, `"PD" has an invalid number ""`},
+		{"P1D1Y", nil, `"P1D1Y" has a misplaced or unknown designator Y`},
+		{"P1D1D", nil, `"P1D1D" has a misplaced or unknown designator D`},
+		{"P1H", nil, `"P1H" has a misplaced or unknown designator H`},
+		{"PT1D", nil, `"PT1D" has a misplaced or unknown designator D`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseISODuration(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatISODuration(t *testing.T) {
+	tests := map[int64]string{
+		0:      "PT0S",
+		59:     "PT59S",
+		3600:   "PT1H",
+		93784:  "PT26H3M4S",
+		-5400:  "-PT1H30M",
+		120:    "PT2M",
+		-86400: "-PT24H",
+	}
+	for seconds, want := range tests {
+		if got := formatISODuration(seconds); got != want {
+			t.Errorf("formatISODuration(%d) = %q, want %q", seconds, got, want)
+		}
+	}
+	if got := formatISOPeriod(period{years: 1, days: -3}); got != "P1Y-3D" {
+		t.Errorf("formatISOPeriod = %q, want P1Y-3D", got)
+	}
+}
@@ -0,0 +1,195 @@
+package evaluator
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+	// The tz database is embedded so that named zones work on hosts
+	// without one, such as minimal containers running funxy build outputs
+	_ "time/tzdata"
+
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// Zone is an IANA time zone such as Europe/Berlin. Unlike the fixed offset
+// of a Date, a zone knows its offset at every instant, DST included.
+type Zone struct {
+	Location *time.Location
+}
+
+func (z *Zone) Type() ObjectType             { return "Zone" }
+func (z *Zone) Inspect() string              { return z.Location.String() }
+func (z *Zone) RuntimeType() typesystem.Type { return typesystem.TCon{Name: "Zone"} }
+func (z *Zone) Hash() uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(z.Location.String()))
+	return h.Sum32()
+}
+
+// zoneCache keeps loaded zones, since loading parses tz data
+var zoneCache sync.Map
+
+func loadZone(name string) (*Zone, error) {
+	if z, ok := zoneCache.Load(name); ok {
+		return z.(*Zone), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	z := &Zone{Location: loc}
+	zoneCache.Store(name, z)
+	return z, nil
+}
+
+// wallTimeIn returns the instant at which the clocks of loc show the given
+// wall time. A wall time skipped by a DST change is moved forward by the
+// length of the gap; a repeated one takes the offset in effect before the
+// change, i.e. its first occurrence. time.Date leaves both cases unspecified.
+func wallTimeIn(year, month, day, hour, min, sec int, loc *time.Location) time.Time {
+	wall := time.Date(year, time.Month(month), day, hour, min, sec, 0, time.UTC).Unix()
+	offsetAt := func(unix int64) int64 {
+		_, offset := time.Unix(unix, 0).In(loc).Zone()
+		return int64(offset)
+	}
+	// Zones change offset at most once around any wall time
+	before, after := offsetAt(wall-86400), offsetAt(wall+86400)
+	for _, offset := range []int64{before, after} {
+		if offsetAt(wall-offset) == offset {
+			return time.Unix(wall-offset, 0).In(loc)
+		}
+	}
+	return time.Unix(wall-before, 0).In(loc)
+}
+
+// zoneLoad: (String) -> Result<String, Zone>
+func builtinZoneLoad(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("zoneLoad expects 1 argument, got %d", len(args))
+	}
+	name, ok := args[0].(*List)
+	if !ok {
+		return newError("zoneLoad expects a string, got %s", args[0].Type())
+	}
+	z, err := loadZone(listToString(name))
+	if err != nil {
+		return makeFailStr("zoneLoad: unknown time zone " + listToString(name))
+	}
+	return makeOk(z)
+}
+
+// zoneName: (Zone) -> String
+func builtinZoneName(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("zoneName expects 1 argument, got %d", len(args))
+	}
+	z, ok := args[0].(*Zone)
+	if !ok {
+		return newError("zoneName expects a Zone, got %s", args[0].Type())
+	}
+	return stringToList(z.Location.String())
+}
+
+// zoneOffsetAt: (Zone, Date) -> Int (offset in minutes at that instant)
+func builtinZoneOffsetAt(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 {
+		return newError("zoneOffsetAt expects 2 arguments, got %d", len(args))
+	}
+	z, ok := args[0].(*Zone)
+	if !ok {
+		return newError("zoneOffsetAt expects a Zone, got %s", args[0].Type())
+	}
+	date, ok := args[1].(*RecordInstance)
+	if !ok {
+		return newError("zoneOffsetAt expects a Date record, got %s", args[1].Type())
+	}
+	t, ok := dateToTime(date)
+	if !ok {
+		return newError("zoneOffsetAt: invalid Date record")
+	}
+	_, offset := t.In(z.Location).Zone()
+	return &Integer{Value: int64(offset / 60)}
+}
+
+// dateNowIn: (Zone) -> Date
+func builtinDateNowIn(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("dateNowIn expects 1 argument, got %d", len(args))
+	}
+	z, ok := args[0].(*Zone)
+	if !ok {
+		return newError("dateNowIn expects a Zone, got %s", args[0].Type())
+	}
+	return makeDate(time.Now().In(z.Location))
+}
+
+// dateNewIn: (Int, Int, Int, Int, Int, Int, Zone) -> Date
+// The offset is the zone's at that wall time, see wallTimeIn.
+func builtinDateNewIn(e *Evaluator, args ...Object) Object {
+	if len(args) != 7 {
+		return newError("dateNewIn expects 7 arguments, got %d", len(args))
+	}
+	fields := make([]int, 6)
+	for i := 0; i < 6; i++ {
+		n, ok := args[i].(*Integer)
+		if !ok {
+			return newError("dateNewIn expects integer date and time components, got %s", args[i].Type())
+		}
+		fields[i] = int(n.Value)
+	}
+	z, ok := args[6].(*Zone)
+	if !ok {
+		return newError("dateNewIn expects a Zone, got %s", args[6].Type())
+	}
+	return makeDate(wallTimeIn(fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], z.Location))
+}
+
+// dateToZone: (Date, Zone) -> Date (the same instant in the zone)
+func builtinDateToZone(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 {
+		return newError("dateToZone expects 2 arguments, got %d", len(args))
+	}
+	date, ok := args[0].(*RecordInstance)
+	if !ok {
+		return newError("dateToZone expects a Date record, got %s", args[0].Type())
+	}
+	z, ok := args[1].(*Zone)
+	if !ok {
+		return newError("dateToZone expects a Zone, got %s", args[1].Type())
+	}
+	t, ok := dateToTime(date)
+	if !ok {
+		return newError("dateToZone: invalid Date record")
+	}
+	return makeDate(t.In(z.Location))
+}
+
+// dateAddPeriodIn: (Date, Period, Zone) -> Date
+// Adds the period to the wall time in the zone, so that one day after
+// 09:00 is 09:00 again across a DST change; the offset follows the zone.
+func builtinDateAddPeriodIn(e *Evaluator, args ...Object) Object {
+	if len(args) != 3 {
+		return newError("dateAddPeriodIn expects 3 arguments, got %d", len(args))
+	}
+	date, ok := args[0].(*RecordInstance)
+	if !ok {
+		return newError("dateAddPeriodIn expects a Date record, got %s", args[0].Type())
+	}
+	p, ok := periodFromObject(args[1])
+	if !ok {
+		return newError("dateAddPeriodIn expects a Period record, got %s", args[1].Type())
+	}
+	z, ok := args[2].(*Zone)
+	if !ok {
+		return newError("dateAddPeriodIn expects a Zone, got %s", args[2].Type())
+	}
+	t, ok := dateToTime(date)
+	if !ok {
+		return newError("dateAddPeriodIn: invalid Date record")
+	}
+	// Normalise the calendar date first, so that month overflow (January 31
+	// plus a month) is resolved the same way as dateAddPeriod
+	t = t.In(z.Location)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).AddDate(int(p.years), int(p.months), int(p.days))
+	return makeDate(wallTimeIn(day.Year(), int(day.Month()), day.Day(), t.Hour(), t.Minute(), t.Second(), z.Location))
+}
@@ -0,0 +1,559 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/funvibe/funxy/internal/config"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// Typed decoding for jsonDecode, yamlDecode and yamlRead.
+//
+// The analyzer passes the T of a decoder call's Result<String, T> as a
+// leading TypeObject, with user-defined types expanded (see
+// analyzer.reifyDecodeTarget). Parsed data is checked against T: records
+// need their fields, Option fields may be missing, ADTs are read from the
+// {"_type": ..., "_fields": [...]} objects jsonEncode writes, and a missing
+// field of a record type with a Default instance is taken from its default.
+// Where T is unknown, for example a type variable, values are built from the
+// input shape as before. Errors name the offending value by JSON pointer.
+
+// typedDecoder decodes data parsed by encoding/json or yaml.v3
+type typedDecoder struct {
+	e *Evaluator
+	// infer builds a value from the input shape, for untyped parts of T
+	infer func(interface{}) (Object, error)
+	// defs are the expanded definitions of the named types in T
+	defs typeDefs
+	// defaults caches the Default value of record types, nil if none
+	defaults map[string]*RecordInstance
+}
+
+// decodeTarget splits the leading type argument off a decoder's args
+func decodeTarget(args []Object) (typesystem.Type, []Object) {
+	if len(args) > 0 {
+		if t, ok := args[0].(*TypeObject); ok {
+			return t.TypeVal, args[1:]
+		}
+	}
+	return nil, args
+}
+
+// decodeTyped converts data to a value of type t
+func decodeTyped(e *Evaluator, data interface{}, t typesystem.Type, infer func(interface{}) (Object, error)) (Object, error) {
+	d := &typedDecoder{
+		e:        e,
+		infer:    infer,
+		defs:     make(typeDefs),
+		defaults: make(map[string]*RecordInstance),
+	}
+	d.defs.collect(t)
+	return d.decode(data, t, "")
+}
+
+// typeDefs maps the named types of an expanded type to their definitions
+type typeDefs map[string]typesystem.Type
+
+// collect records the expanded named types in t
+func (defs typeDefs) collect(t typesystem.Type) {
+	switch ty := t.(type) {
+	case typesystem.TCon:
+		if ty.UnderlyingType != nil {
+			if _, ok := defs[ty.Name]; !ok {
+				defs[ty.Name] = ty.UnderlyingType
+				defs.collect(ty.UnderlyingType)
+			}
+		}
+	case typesystem.TApp:
+		if con, ok := ty.Constructor.(typesystem.TCon); ok && con.UnderlyingType != nil {
+			key := decodeTypeKey(ty)
+			if _, ok := defs[key]; !ok {
+				defs[key] = con.UnderlyingType
+				defs.collect(con.UnderlyingType)
+			}
+		}
+		for _, arg := range ty.Args {
+			defs.collect(arg)
+		}
+	case typesystem.TRecord:
+		for _, field := range ty.Fields {
+			defs.collect(field)
+		}
+	case typesystem.TTuple:
+		for _, el := range ty.Elements {
+			defs.collect(el)
+		}
+	case typesystem.TUnion:
+		for _, member := range ty.Types {
+			defs.collect(member)
+		}
+	case typesystem.TFunc:
+		for _, param := range ty.Params {
+			defs.collect(param)
+		}
+	}
+}
+
+// decodeTypeKey names an applied type independently of expansion
+func decodeTypeKey(t typesystem.TApp) string {
+	name := t.Constructor.String()
+	if con, ok := t.Constructor.(typesystem.TCon); ok {
+		name = con.Name
+	}
+	args := make([]string, len(t.Args))
+	for i, arg := range t.Args {
+		args[i] = arg.String()
+	}
+	return name + "<" + strings.Join(args, ", ") + ">"
+}
+
+// lookup returns the name and expanded definition of a named type
+func (defs typeDefs) lookup(t typesystem.Type) (string, typesystem.Type, bool) {
+	switch ty := t.(type) {
+	case typesystem.TCon:
+		def, ok := defs[ty.Name]
+		return ty.Name, def, ok
+	case typesystem.TApp:
+		if con, ok := ty.Constructor.(typesystem.TCon); ok {
+			def, ok := defs[decodeTypeKey(ty)]
+			return con.Name, def, ok
+		}
+	}
+	return "", nil, false
+}
+
+func (d *typedDecoder) decode(data interface{}, t typesystem.Type, path string) (Object, error) {
+	if name, def, ok := d.defs.lookup(t); ok {
+		if union, ok := def.(typesystem.TUnion); ok && isConstructorUnion(union) {
+			return d.decodeADT(data, name, union, path)
+		}
+		if rec, ok := def.(typesystem.TRecord); ok {
+			return d.decodeRecord(data, rec, name, false, path)
+		}
+		if app, ok := def.(typesystem.TApp); ok && isDefaultMarker(app) {
+			return d.decodeRecord(data, app.Args[0].(typesystem.TRecord), name, true, path)
+		}
+		return d.decode(data, def, path)
+	}
+
+	switch ty := t.(type) {
+	case typesystem.TCon:
+		return d.decodeScalar(data, ty.Name, path)
+	case typesystem.TApp:
+		con, ok := ty.Constructor.(typesystem.TCon)
+		if !ok {
+			break
+		}
+		switch {
+		case con.Name == config.ListTypeName && len(ty.Args) == 1:
+			if elem, ok := ty.Args[0].(typesystem.TCon); ok && elem.Name == "Char" {
+				return d.decodeScalar(data, "String", path)
+			}
+			return d.decodeList(data, ty.Args[0], path)
+		case con.Name == config.OptionTypeName && len(ty.Args) == 1:
+			if data == nil {
+				return makeNone(), nil
+			}
+			value, err := d.decode(data, ty.Args[0], path)
+			if err != nil {
+				return nil, err
+			}
+			return makeSome(value), nil
+		case con.Name == config.MapTypeName && len(ty.Args) == 2:
+			return d.decodeMap(data, ty.Args[1], path)
+		}
+	case typesystem.TRecord:
+		return d.decodeRecord(data, ty, "", false, path)
+	case typesystem.TTuple:
+		return d.decodeTuple(data, ty, path)
+	case typesystem.TUnion:
+		// Members are tried in order; the first that fits wins
+		for _, member := range ty.Types {
+			if value, err := d.decode(data, member, path); err == nil {
+				return value, nil
+			}
+		}
+		return nil, decodeError(path, decodeTypeName(ty), data)
+	}
+	return d.infer(data)
+}
+
+// decodeScalar decodes a value of a builtin type without type arguments
+func (d *typedDecoder) decodeScalar(data interface{}, name, path string) (Object, error) {
+	switch name {
+	case "Int":
+		if n, ok := decodeInt(data); ok {
+			return &Integer{Value: n}, nil
+		}
+	case "Float":
+		if f, ok := decodeFloat(data); ok {
+			return &Float{Value: f}, nil
+		}
+	case "Bool":
+		if b, ok := data.(bool); ok {
+			return &Boolean{Value: b}, nil
+		}
+	case "String":
+		if s, ok := data.(string); ok {
+			return StringToList(s), nil
+		}
+	case "Char":
+		if s, ok := data.(string); ok && len([]rune(s)) == 1 {
+			return &Char{Value: int64([]rune(s)[0])}, nil
+		}
+	case "Nil":
+		if data == nil {
+			return &Nil{}, nil
+		}
+	case "BigInt":
+		// jsonEncode writes BigInt as a string
+		if s, ok := data.(string); ok {
+			if n, ok := new(big.Int).SetString(s, 10); ok {
+				return &BigInt{Value: n}, nil
+			}
+		} else if n, ok := decodeInt(data); ok {
+			return &BigInt{Value: big.NewInt(n)}, nil
+		}
+	case "Rational":
+		if s, ok := data.(string); ok {
+			if r, ok := new(big.Rat).SetString(s); ok {
+				return &Rational{Value: r}, nil
+			}
+		}
+	case "Json":
+		return goToJsonADT(data), nil
+	default:
+		return d.infer(data)
+	}
+	return nil, decodeError(path, name, data)
+}
+
+func (d *typedDecoder) decodeList(data interface{}, elem typesystem.Type, path string) (Object, error) {
+	items, ok := data.([]interface{})
+	if !ok {
+		return nil, decodeError(path, config.ListTypeName, data)
+	}
+	elements := make([]Object, len(items))
+	for i, item := range items {
+		value, err := d.decode(item, elem, fmt.Sprintf("%s/%d", path, i))
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = value
+	}
+	return newList(elements), nil
+}
+
+func (d *typedDecoder) decodeTuple(data interface{}, t typesystem.TTuple, path string) (Object, error) {
+	items, ok := data.([]interface{})
+	if !ok {
+		return nil, decodeError(path, decodeTypeName(t), data)
+	}
+	if len(items) != len(t.Elements) {
+		return nil, fmt.Errorf("%sexpected %d elements, got %d", decodePathPrefix(path), len(t.Elements), len(items))
+	}
+	elements := make([]Object, len(items))
+	for i, item := range items {
+		value, err := d.decode(item, t.Elements[i], fmt.Sprintf("%s/%d", path, i))
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = value
+	}
+	return &Tuple{Elements: elements}, nil
+}
+
+func (d *typedDecoder) decodeMap(data interface{}, value typesystem.Type, path string) (Object, error) {
+	obj, ok := decodeObject(data)
+	if !ok {
+		return nil, decodeError(path, config.MapTypeName, data)
+	}
+	result := newMap()
+	for _, key := range sortedKeys(obj) {
+		v, err := d.decode(obj[key], value, path+"/"+escapeJSONPointer(key))
+		if err != nil {
+			return nil, err
+		}
+		result = result.put(StringToList(key), v)
+	}
+	return result, nil
+}
+
+// decodeRecord decodes a record of type t, nominal if typeName is set.
+// Missing fields come from the type's Default value if hasDefault is set.
+// Fields of an open record beyond those of t are kept as they are.
+func (d *typedDecoder) decodeRecord(data interface{}, t typesystem.TRecord, typeName string, hasDefault bool, path string) (Object, error) {
+	obj, ok := decodeObject(data)
+	if !ok {
+		expected := typeName
+		if expected == "" {
+			expected = "Record"
+		}
+		return nil, decodeError(path, expected, data)
+	}
+
+	names := make([]string, 0, len(t.Fields))
+	for name := range t.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make(map[string]Object, len(obj))
+	for _, name := range names {
+		fieldPath := path + "/" + escapeJSONPointer(name)
+		raw, present := obj[name]
+		if !present {
+			value, err := d.missingField(t, typeName, hasDefault, name, fieldPath)
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = value
+			continue
+		}
+		value, err := d.decode(raw, t.Fields[name], fieldPath)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = value
+	}
+	if t.IsOpen {
+		for key, raw := range obj {
+			if _, known := t.Fields[key]; known {
+				continue
+			}
+			value, err := d.infer(raw)
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = value
+		}
+	}
+
+	rec := NewRecord(fields)
+	rec.TypeName = typeName
+	return rec, nil
+}
+
+// missingField returns the value of an absent field: the field of the
+// record's Default value if it has one, None for an Option, nil for a
+// nullable type (T?), and an error otherwise
+func (d *typedDecoder) missingField(record typesystem.TRecord, typeName string, hasDefault bool, name, path string) (Object, error) {
+	t := record.Fields[name]
+	if hasDefault {
+		if def := d.recordDefault(typeName, record); def != nil {
+			if value := def.Get(name); value != nil {
+				return value, nil
+			}
+		}
+	}
+	if app, ok := t.(typesystem.TApp); ok {
+		if con, ok := app.Constructor.(typesystem.TCon); ok && con.Name == config.OptionTypeName {
+			return makeNone(), nil
+		}
+	}
+	if isNullableType(t) {
+		return &Nil{}, nil
+	}
+	return nil, fmt.Errorf("%s: missing field", path)
+}
+
+// recordDefault returns the Default value of the record type typeName,
+// defined as t
+func (d *typedDecoder) recordDefault(typeName string, t typesystem.TRecord) *RecordInstance {
+	if def, ok := d.defaults[typeName]; ok {
+		return def
+	}
+	var def *RecordInstance
+	value := d.e.tryDefaultMethod(typeName)
+	if value == nil {
+		value = d.e.GetDefaultForType(t)
+	}
+	def, _ = value.(*RecordInstance)
+	d.defaults[typeName] = def
+	return def
+}
+
+// decodeADT decodes a constructor of the ADT typeName, given as an object
+// with the constructor name in "_type" and its arguments in "_fields"
+func (d *typedDecoder) decodeADT(data interface{}, typeName string, ctors typesystem.TUnion, path string) (Object, error) {
+	obj, ok := decodeObject(data)
+	if !ok {
+		return nil, decodeError(path, typeName, data)
+	}
+	tag, ok := obj["_type"].(string)
+	if !ok {
+		if _, present := obj["_type"]; !present {
+			return nil, fmt.Errorf("%s/_type: missing field", path)
+		}
+		return nil, decodeError(path+"/_type", "String", obj["_type"])
+	}
+
+	for _, member := range ctors.Types {
+		ctor := member.(typesystem.TFunc)
+		if ctor.ReturnType.(typesystem.TCon).Name != tag {
+			continue
+		}
+		var raw []interface{}
+		if rawFields, present := obj["_fields"]; present {
+			if raw, ok = rawFields.([]interface{}); !ok {
+				return nil, decodeError(path+"/_fields", config.ListTypeName, rawFields)
+			}
+		}
+		if len(raw) != len(ctor.Params) {
+			return nil, fmt.Errorf("%s/_fields: %s expects %d fields, got %d", path, tag, len(ctor.Params), len(raw))
+		}
+		fields := make([]Object, len(raw))
+		for i, param := range ctor.Params {
+			value, err := d.decode(raw[i], param, fmt.Sprintf("%s/_fields/%d", path, i))
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = value
+		}
+		return &DataInstance{Name: tag, Fields: fields, TypeName: typeName}, nil
+	}
+	return nil, fmt.Errorf("%s/_type: %q is not a constructor of %s", path, tag, typeName)
+}
+
+// isDefaultMarker reports whether t is the Default<{ ... }> definition of a
+// record type with a Default instance
+func isDefaultMarker(t typesystem.TApp) bool {
+	con, ok := t.Constructor.(typesystem.TCon)
+	if !ok || con.Name != "Default" || len(t.Args) != 1 {
+		return false
+	}
+	_, ok = t.Args[0].(typesystem.TRecord)
+	return ok
+}
+
+// isConstructorUnion reports whether u is an expanded ADT rather than a
+// union type
+func isConstructorUnion(u typesystem.TUnion) bool {
+	for _, member := range u.Types {
+		fn, ok := member.(typesystem.TFunc)
+		if !ok {
+			return false
+		}
+		if _, ok := fn.ReturnType.(typesystem.TCon); !ok {
+			return false
+		}
+	}
+	return len(u.Types) > 0
+}
+
+func decodeInt(data interface{}) (int64, bool) {
+	switch v := data.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v), true
+		}
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v <= math.MaxInt64 {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
+
+func decodeFloat(data interface{}) (float64, bool) {
+	switch v := data.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// decodeObject accepts the objects of both encoding/json and yaml.v3
+func decodeObject(data interface{}) (map[string]interface{}, bool) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			obj[fmt.Sprintf("%v", key)] = value
+		}
+		return obj, true
+	}
+	return nil, false
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// decodeError reports that the value at path is not of the expected type
+func decodeError(path, expected string, data interface{}) error {
+	return fmt.Errorf("%sexpected %s, got %s", decodePathPrefix(path), expected, decodeKind(data))
+}
+
+func decodePathPrefix(path string) string {
+	if path == "" {
+		return ""
+	}
+	return path + ": "
+}
+
+// decodeKind names the JSON kind of a parsed value
+func decodeKind(data interface{}) string {
+	switch data.(type) {
+	case nil:
+		return "Null"
+	case bool:
+		return "Bool"
+	case int, int64, uint64, float64:
+		return "Number"
+	case string:
+		return "String"
+	case []interface{}:
+		return "Array"
+	case map[string]interface{}, map[interface{}]interface{}:
+		return "Object"
+	}
+	return fmt.Sprintf("%T", data)
+}
+
+// decodeTypeName names a type in decode errors
+func decodeTypeName(t typesystem.Type) string {
+	switch ty := t.(type) {
+	case typesystem.TCon:
+		return ty.Name
+	case typesystem.TApp:
+		return decodeTypeName(ty.Constructor)
+	case typesystem.TRecord:
+		return "Record"
+	case typesystem.TTuple:
+		return "Tuple"
+	case typesystem.TUnion:
+		names := make([]string, len(ty.Types))
+		for i, member := range ty.Types {
+			names[i] = decodeTypeName(member)
+		}
+		return strings.Join(names, " | ")
+	}
+	return t.String()
+}
+
+// escapeJSONPointer escapes a key for use in a JSON pointer (RFC 6901)
+func escapeJSONPointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -17,6 +17,8 @@ var (
 		"Bitwise":     {"(&)", "(|)", "(^)", "(<<)", "(>>)"},
 		"Concat":      {"(++)"},
 		"Default":     {"default", "getDefault"}, // Both registered in analyzer
+		"Hash":        {"hash"},
+		"ToJson":      {"toJson"},
 		"Functor":     {"fmap"},
 		"Applicative": {"pure", "(<*>)"},
 		"Monad":       {"(>>=)"},
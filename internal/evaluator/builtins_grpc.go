@@ -2,8 +2,10 @@ package evaluator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"path/filepath"
 	"sync"
 
 	"github.com/jhump/protoreflect/desc"
@@ -45,9 +47,10 @@ func (o *GrpcConnObject) Hash() uint32 {
 
 // GrpcServerObject wraps a grpc.Server
 type GrpcServerObject struct {
-	Server   *grpc.Server
-	Services map[string]Object // service name -> implementation object
-	Eval     *Evaluator        // Evaluator snapshot for callbacks
+	Server       *grpc.Server
+	Services     map[string]Object // service name -> implementation object
+	Eval         *Evaluator        // Evaluator snapshot for callbacks
+	Interceptors []Object          // run around every handler, outermost first
 }
 
 func (o *GrpcServerObject) Type() ObjectType { return "GrpcServer" }
@@ -64,16 +67,40 @@ func (o *GrpcServerObject) Hash() uint32 {
 // GrpcBuiltins returns built-in functions for lib/grpc
 func GrpcBuiltins() map[string]*Builtin {
 	return map[string]*Builtin{
-		"grpcConnect":           {Fn: builtinGrpcConnect, Name: "grpcConnect"},
-		"grpcClose":             {Fn: builtinGrpcClose, Name: "grpcClose"},
-		"grpcLoadProto":         {Fn: builtinGrpcLoadProto, Name: "grpcLoadProto"},
-		"grpcInvoke":            {Fn: builtinGrpcInvoke, Name: "grpcInvoke"},
-		"grpcServer":            {Fn: builtinGrpcServer, Name: "grpcServer"},
-		"grpcRegister":          {Fn: builtinGrpcRegister, Name: "grpcRegister"},
-		"grpcServe":             {Fn: builtinGrpcServe, Name: "grpcServe"},
-		"grpcServeAsync":        {Fn: builtinGrpcServeAsync, Name: "grpcServeAsync"},
-		"grpcStop":              {Fn: builtinGrpcStop, Name: "grpcStop"},
-		"grpcSetMaxConnections": {Fn: builtinGrpcSetMaxConnections, Name: "grpcSetMaxConnections"},
+		"grpcConnect":             {Fn: builtinGrpcConnect, Name: "grpcConnect"},
+		"grpcClose":               {Fn: builtinGrpcClose, Name: "grpcClose"},
+		"grpcLoadProto":           {Fn: builtinGrpcLoadProto, Name: "grpcLoadProto"},
+		"grpcInvoke":              {Fn: builtinGrpcInvoke, Name: "grpcInvoke"},
+		"grpcCall":                {Fn: builtinGrpcCall, Name: "grpcCall"},
+		"grpcInvokeStream":        {Fn: builtinGrpcInvokeStream, Name: "grpcInvokeStream"},
+		"grpcInvokeStreamTimeout": {Fn: builtinGrpcInvokeStreamTimeout, Name: "grpcInvokeStreamTimeout"},
+		"grpcOpenStream":          {Fn: builtinGrpcOpenStream, Name: "grpcOpenStream"},
+		"grpcOpenStreamTimeout":   {Fn: builtinGrpcOpenStreamTimeout, Name: "grpcOpenStreamTimeout"},
+		"grpcSend":                {Fn: builtinGrpcSend, Name: "grpcSend"},
+		"grpcRecv":                {Fn: builtinGrpcRecv, Name: "grpcRecv"},
+		"grpcCloseSend":           {Fn: builtinGrpcCloseSend, Name: "grpcCloseSend"},
+		"grpcCloseAndRecv":        {Fn: builtinGrpcCloseAndRecv, Name: "grpcCloseAndRecv"},
+		"grpcCancel":              {Fn: builtinGrpcCancel, Name: "grpcCancel"},
+		"grpcServer":              {Fn: builtinGrpcServer, Name: "grpcServer"},
+		"grpcRegister":            {Fn: builtinGrpcRegister, Name: "grpcRegister"},
+		"grpcServe":               {Fn: builtinGrpcServe, Name: "grpcServe"},
+		"grpcServeAsync":          {Fn: builtinGrpcServeAsync, Name: "grpcServeAsync"},
+		"grpcStop":                {Fn: builtinGrpcStop, Name: "grpcStop"},
+		"grpcSetMaxConnections":   {Fn: builtinGrpcSetMaxConnections, Name: "grpcSetMaxConnections"},
+		"grpcMetadata":            {Fn: builtinGrpcMetadata, Name: "grpcMetadata"},
+	}
+}
+
+// RegisterGrpcBuiltins registers the gRPC types, the status ADTs and the
+// lib/grpc functions into an environment
+func RegisterGrpcBuiltins(env *Environment) {
+	for _, name := range []string{"GrpcConn", "GrpcServer", "GrpcStream"} {
+		env.Set(name, &TypeObject{TypeVal: typesystem.TCon{Name: name}})
+	}
+	registerGrpcStatusTypes(env)
+
+	for name, fn := range GrpcBuiltins() {
+		env.Set(name, fn)
 	}
 }
 
@@ -85,14 +112,25 @@ func ProtoBuiltins() map[string]*Builtin {
 	}
 }
 
-// grpcConnect(target: String) -> Result<String, GrpcConn>
+// grpcConnect(target: String, options?) -> Result<String, GrpcConn>
 func builtinGrpcConnect(e *Evaluator, args ...Object) Object {
-	if len(args) != 1 {
-		return newError("grpcConnect expects 1 argument")
+	if len(args) != 1 && len(args) != 2 {
+		return newError("grpcConnect expects 1 or 2 arguments")
 	}
 
 	target := listToString(args[0])
-	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	creds := grpc.WithTransportCredentials(insecure.NewCredentials())
+	if len(args) == 2 {
+		rec, ok := args[1].(*RecordInstance)
+		if !ok {
+			return newError("grpcConnect expects an options record, got %s", args[1].Type())
+		}
+		var errObj *Error
+		if creds, errObj = grpcDialOptions(e, "grpcConnect", rec); errObj != nil {
+			return errObj
+		}
+	}
+	conn, err := grpc.NewClient(target, creds)
 	if err != nil {
 		return makeFailStr(err.Error())
 	}
@@ -132,8 +170,14 @@ func builtinGrpcLoadProto(e *Evaluator, args ...Object) Object {
 
 	// Use protoparse to parse the file
 	parser := protoparse.Parser{}
-	// Determine import paths (current directory by default)
+	// Determine import paths (current directory by default). Import paths are
+	// joined with the file name, so an absolute file is resolved from its own
+	// directory, where its imports are looked up too.
 	parser.ImportPaths = []string{"."}
+	if filepath.IsAbs(path) {
+		parser.ImportPaths = []string{filepath.Dir(path)}
+		path = filepath.Base(path)
+	}
 
 	fds, err := parser.ParseFiles(path)
 	if err != nil {
@@ -151,15 +195,53 @@ func builtinGrpcLoadProto(e *Evaluator, args ...Object) Object {
 	return makeOk(&Nil{})
 }
 
-// grpcInvoke(conn: GrpcConn, method: String, request: A) -> Result<String, B>
+// grpcInvoke(conn: GrpcConn, method: String, request: A, options?) -> Result<String, B>
 func builtinGrpcInvoke(e *Evaluator, args ...Object) Object {
-	if len(args) != 3 {
-		return newError("grpcInvoke expects 3 arguments")
+	resp, err := invokeUnary("grpcInvoke", args)
+	if errObj, ok := resp.(*Error); ok {
+		return errObj
+	}
+	var reqErr *grpcRequestError
+	if errors.As(err, &reqErr) {
+		return makeFailStr(reqErr.msg)
+	}
+	if err != nil {
+		return makeFailStr("RPC failed: " + err.Error())
+	}
+	return makeOk(resp)
+}
+
+// grpcCall(conn: GrpcConn, method: String, request: A, options?) -> Result<GrpcError, B>
+// grpcCall is grpcInvoke with failures as GrpcError values.
+func builtinGrpcCall(e *Evaluator, args ...Object) Object {
+	resp, err := invokeUnary("grpcCall", args)
+	if errObj, ok := resp.(*Error); ok {
+		return errObj
+	}
+	if err != nil {
+		return makeFail(grpcErrorObject(err))
+	}
+	return makeOk(resp)
+}
+
+// invokeUnary makes the unary call described by the arguments of grpcInvoke
+// and grpcCall. Invalid arguments are returned as an *Error response.
+func invokeUnary(name string, args []Object) (Object, error) {
+	if len(args) != 3 && len(args) != 4 {
+		return newError("%s expects 3 or 4 arguments", name), nil
 	}
 
 	connObj, ok := args[0].(*GrpcConnObject)
 	if !ok || connObj.Conn == nil {
-		return newError("grpcInvoke expects a valid GrpcConn")
+		return newError("%s expects a valid GrpcConn", name), nil
+	}
+	var optsArg Object
+	if len(args) == 4 {
+		optsArg = args[3]
+	}
+	opts, errObj := parseGrpcCallOptions(name, optsArg)
+	if errObj != nil {
+		return errObj, nil
 	}
 
 	methodPath := listToString(args[1]) // e.g. "package.Service/Method"
@@ -168,20 +250,24 @@ func builtinGrpcInvoke(e *Evaluator, args ...Object) Object {
 	// Find method descriptor
 	md, err := findMethodDescriptor(methodPath)
 	if err != nil {
-		return makeFailStr(err.Error())
+		return nil, &grpcRequestError{msg: err.Error()}
+	}
+	if md.IsClientStreaming() || md.IsServerStreaming() {
+		return nil, &grpcRequestError{msg: fmt.Sprintf("%s is a streaming method, use grpcInvokeStream or grpcOpenStream", methodPath)}
 	}
 
 	// Create request message
 	reqMsg := dynamic.NewMessage(md.GetInputType())
 	if err := objectToDynamicMessage(requestData, reqMsg); err != nil {
-		return makeFailStr("failed to build request: " + err.Error())
+		return nil, &grpcRequestError{msg: "failed to build request: " + err.Error()}
 	}
 
 	// Create response message
 	respMsg := dynamic.NewMessage(md.GetOutputType())
 
 	// Invoke
-	ctx := context.Background()
+	ctx, cancel := opts.context()
+	defer cancel()
 	// Fix method path for grpc.Invoke: it expects "/package.Service/Method"
 	if methodPath[0] != '/' {
 		methodPath = "/" + methodPath
@@ -191,14 +277,12 @@ func builtinGrpcInvoke(e *Evaluator, args ...Object) Object {
 	// We use invoke with dynamic messages. grpc.Invoke expects proto.Message.
 	// dynamic.Message implements it.
 
-	err = connObj.Conn.Invoke(ctx, methodPath, reqMsg, respMsg)
-	if err != nil {
-		return makeFailStr("RPC failed: " + err.Error())
+	if err := connObj.Conn.Invoke(ctx, methodPath, reqMsg, respMsg); err != nil {
+		return nil, err
 	}
 
 	// Convert response back to Object
-	respObj := dynamicMessageToObject(respMsg)
-	return makeOk(respObj)
+	return dynamicMessageToObject(respMsg), nil
 }
 
 // protoEncode(messageName: String, data: A) -> Result<String, Bytes>
@@ -253,9 +337,23 @@ func builtinProtoDecode(e *Evaluator, args ...Object) Object {
 	return makeOk(dynamicMessageToObject(msg))
 }
 
-// grpcServer() -> GrpcServer
+// grpcServer(options?) -> GrpcServer
 func builtinGrpcServer(e *Evaluator, args ...Object) Object {
-	server := grpc.NewServer()
+	if len(args) > 1 {
+		return newError("grpcServer expects at most 1 argument")
+	}
+	conf := &grpcServerConfig{}
+	if len(args) == 1 {
+		var errObj *Error
+		if conf, errObj = parseGrpcServerConfig(e, "grpcServer", args[0]); errObj != nil {
+			return errObj
+		}
+	}
+
+	server := grpc.NewServer(conf.options...)
+	if conf.reflection {
+		registerGrpcReflection(server)
+	}
 	// Clone evaluator to safely run handlers concurrently (similar to http)
 	var serverEval *Evaluator
 	if e.Forker != nil {
@@ -265,9 +363,10 @@ func builtinGrpcServer(e *Evaluator, args ...Object) Object {
 	}
 
 	return &GrpcServerObject{
-		Server:   server,
-		Services: make(map[string]Object),
-		Eval:     serverEval,
+		Server:       server,
+		Services:     make(map[string]Object),
+		Eval:         serverEval,
+		Interceptors: conf.interceptors,
 	}
 }
 
@@ -302,19 +401,29 @@ func builtinGrpcRegister(e *Evaluator, args ...Object) Object {
 
 	// Wrapper for implementation
 	handlerWrapper := &FunxyGrpcHandler{
-		Impl: impl,
-		Eval: serverObj.Eval,
-		SD:   sd,
+		Impl:         impl,
+		Eval:         serverObj.Eval,
+		SD:           sd,
+		Interceptors: serverObj.Interceptors,
 	}
 
 	for _, method := range sd.GetMethods() {
-		if method.IsClientStreaming() || method.IsServerStreaming() {
-			continue // TODO: Streaming support
-		}
-
 		methodName := method.GetName()
 		md := method
 
+		if md.IsClientStreaming() || md.IsServerStreaming() {
+			desc.Streams = append(desc.Streams, grpc.StreamDesc{
+				StreamName:    methodName,
+				ServerStreams: md.IsServerStreaming(),
+				ClientStreams: md.IsClientStreaming(),
+				Handler: func(srv interface{}, stream grpc.ServerStream) error {
+					h := srv.(*FunxyGrpcHandler)
+					return h.HandleStream(md, stream)
+				},
+			})
+			continue
+		}
+
 		desc.Methods = append(desc.Methods, grpc.MethodDesc{
 			MethodName: methodName,
 			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
@@ -457,9 +566,10 @@ func builtinGrpcSetMaxConnections(e *Evaluator, args ...Object) Object {
 }
 
 type FunxyGrpcHandler struct {
-	Impl Object
-	Eval *Evaluator
-	SD   *desc.ServiceDescriptor
+	Impl         Object
+	Eval         *Evaluator
+	SD           *desc.ServiceDescriptor
+	Interceptors []Object
 }
 
 func (h *FunxyGrpcHandler) HandleUnary(ctx context.Context, md *desc.MethodDescriptor, dec func(interface{}) error) (res interface{}, err error) {
@@ -486,7 +596,32 @@ func (h *FunxyGrpcHandler) HandleUnary(ctx context.Context, md *desc.MethodDescr
 	inObj := dynamicMessageToObject(inMsg)
 
 	// 4. Find function in Impl
-	methodName := md.GetName()
+	fn, err := h.lookupMethod(md.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. Call function through the interceptors
+	eval := h.requestEvaluator(ctx)
+	call := grpcCallRecord(ctx, md)
+	result, err := grpcHandlerResult(h.intercept(eval, call, func() Object {
+		return eval.ApplyFunction(fn, []Object{inObj})
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	// 6. Convert result to dynamic message
+	outMsg := dynamic.NewMessage(md.GetOutputType())
+	if err := objectToDynamicMessage(result, outMsg); err != nil {
+		return nil, err
+	}
+
+	return outMsg, nil
+}
+
+// lookupMethod finds the function implementing a method in Impl
+func (h *FunxyGrpcHandler) lookupMethod(methodName string) (Object, error) {
 	var fn Object
 
 	if rec, ok := h.Impl.(*RecordInstance); ok {
@@ -498,28 +633,20 @@ func (h *FunxyGrpcHandler) HandleUnary(ctx context.Context, md *desc.MethodDescr
 	if fn == nil {
 		return nil, fmt.Errorf("method %s not found in implementation", methodName)
 	}
+	return fn, nil
+}
 
-	// 5. Call function
-	var reqEval *Evaluator
+// requestEvaluator returns an evaluator for handling one request. Its context
+// is the call's, which carries the client's metadata and deadline.
+func (h *FunxyGrpcHandler) requestEvaluator(ctx context.Context) *Evaluator {
+	var eval *Evaluator
 	if h.Eval.Forker != nil {
-		reqEval = h.Eval.Fork()
+		eval = h.Eval.Fork()
 	} else {
-		reqEval = h.Eval.Clone()
-	}
-
-	result := reqEval.ApplyFunction(fn, []Object{inObj})
-
-	if isError(result) {
-		return nil, fmt.Errorf("%s", result.(*Error).Message)
-	}
-
-	// 6. Convert result to dynamic message
-	outMsg := dynamic.NewMessage(md.GetOutputType())
-	if err := objectToDynamicMessage(result, outMsg); err != nil {
-		return nil, err
+		eval = h.Eval.Clone()
 	}
-
-	return outMsg, nil
+	eval.Context = ctx
+	return eval
 }
 
 func findServiceDescriptor(name string) *desc.ServiceDescriptor {
@@ -580,6 +707,10 @@ func findMessageDescriptor(name string) (*desc.MessageDescriptor, error) {
 			return msg, nil
 		}
 	}
+	// Messages compiled into funxy, such as the google.rpc error details
+	if msg, err := desc.LoadMessageDescriptor(name); err == nil && msg != nil {
+		return msg, nil
+	}
 	return nil, fmt.Errorf("message type %q not found", name)
 }
 
@@ -0,0 +1,372 @@
+package evaluator
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"sort"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// grpcRequestError is a failure before a call reaches the server, such as an
+// unknown method or a request that does not fit its message type
+type grpcRequestError struct {
+	msg string
+}
+
+func (e *grpcRequestError) Error() string { return e.msg }
+
+// GRPCStatus reports the failure as InvalidArgument to grpcCall
+func (e *grpcRequestError) GRPCStatus() *status.Status {
+	return status.New(codes.InvalidArgument, e.msg)
+}
+
+// grpcDialOptions reads the options record of grpcConnect:
+//
+//	{ tls: Bool, caFile: String, certFile: String, keyFile: String,
+//	  serverName: String, insecureSkipVerify: Bool }
+//
+// TLS is used when tls is true or any other field is given. caFile
+// replaces the system roots, certFile and keyFile are the client certificate
+// for mutual TLS.
+func grpcDialOptions(e *Evaluator, name string, rec *RecordInstance) (grpc.DialOption, *Error) {
+	var caFile, certFile, keyFile, serverName string
+	useTLS, skipVerify := false, false
+
+	textFields := []struct {
+		field  string
+		target *string
+	}{
+		{"caFile", &caFile},
+		{"certFile", &certFile},
+		{"keyFile", &keyFile},
+		{"serverName", &serverName},
+	}
+	for _, s := range textFields {
+		if val := rec.Get(s.field); val != nil {
+			list, ok := val.(*List)
+			if !ok {
+				return nil, newError("%s: option %s must be a String", name, s.field)
+			}
+			*s.target = ListToString(list)
+			useTLS = true
+		}
+	}
+	flags := []struct {
+		field  string
+		target *bool
+	}{
+		{"tls", &useTLS},
+		{"insecureSkipVerify", &skipVerify},
+	}
+	for _, f := range flags {
+		if val := rec.Get(f.field); val != nil {
+			b, ok := val.(*Boolean)
+			if !ok {
+				return nil, newError("%s: option %s must be a Bool", name, f.field)
+			}
+			*f.target = *f.target || b.Value
+		}
+	}
+	useTLS = useTLS || skipVerify
+	if (certFile == "") != (keyFile == "") {
+		return nil, newError("%s: options certFile and keyFile must be given together", name)
+	}
+	if !useTLS {
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	}
+
+	conf := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: skipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if caFile != "" {
+		caPEM, err := readServerFile(e, caFile)
+		if err != nil {
+			return nil, newError("%s: %s", name, err.Error())
+		}
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, newError("%s: no certificates found in %s", name, caFile)
+		}
+	}
+	if certFile != "" {
+		certPEM, err := readServerFile(e, certFile)
+		if err != nil {
+			return nil, newError("%s: %s", name, err.Error())
+		}
+		keyPEM, err := readServerFile(e, keyFile)
+		if err != nil {
+			return nil, newError("%s: %s", name, err.Error())
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, newError("%s: invalid certificate or key: %v", name, err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(conf)), nil
+}
+
+// grpcCallOptions are the per-call options of the client functions:
+//
+//	{ metadata: List<(String, String)>, timeout: Int }
+//
+// timeout is a deadline in milliseconds for the whole call.
+type grpcCallOptions struct {
+	metadata metadata.MD
+	timeout  time.Duration
+}
+
+func parseGrpcCallOptions(name string, arg Object) (*grpcCallOptions, *Error) {
+	opts := &grpcCallOptions{}
+	if arg == nil {
+		return opts, nil
+	}
+	rec, ok := arg.(*RecordInstance)
+	if !ok {
+		return nil, newError("%s expects an options record, got %s", name, arg.Type())
+	}
+	if val := rec.Get("metadata"); val != nil {
+		md, err := grpcMetadataFromList(name, val)
+		if err != nil {
+			return nil, err
+		}
+		opts.metadata = md
+	}
+	if val := rec.Get("timeout"); val != nil {
+		ms, ok := val.(*Integer)
+		if !ok || ms.Value < 0 {
+			return nil, newError("%s: option timeout must be a non-negative Int (milliseconds)", name)
+		}
+		opts.timeout = time.Duration(ms.Value) * time.Millisecond
+	}
+	return opts, nil
+}
+
+// context returns the context of a call made with these options
+func (o *grpcCallOptions) context() (context.Context, context.CancelFunc) {
+	ctx := context.Background()
+	if len(o.metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, o.metadata)
+	}
+	if o.timeout > 0 {
+		return context.WithTimeout(ctx, o.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// grpcMetadataFromList converts a list of (key, value) tuples to metadata
+func grpcMetadataFromList(name string, obj Object) (metadata.MD, *Error) {
+	list, ok := obj.(*List)
+	if !ok {
+		return nil, newError("%s: metadata must be a list of (String, String) tuples", name)
+	}
+	md := metadata.MD{}
+	for _, item := range list.ToSlice() {
+		tuple, ok := item.(*Tuple)
+		if !ok || len(tuple.Elements) != 2 {
+			return nil, newError("%s: metadata must be a list of (String, String) tuples", name)
+		}
+		key, okKey := tuple.Elements[0].(*List)
+		val, okVal := tuple.Elements[1].(*List)
+		if !okKey || !okVal {
+			return nil, newError("%s: metadata must be a list of (String, String) tuples", name)
+		}
+		md.Append(ListToString(key), ListToString(val))
+	}
+	return md, nil
+}
+
+// grpcMetadataToList converts metadata to (key, value) tuples sorted by key
+func grpcMetadataToList(md metadata.MD) *List {
+	keys := make([]string, 0, len(md))
+	for key := range md {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var pairs []Object
+	for _, key := range keys {
+		for _, val := range md[key] {
+			pairs = append(pairs, &Tuple{Elements: []Object{StringToList(key), StringToList(val)}})
+		}
+	}
+	return newList(pairs)
+}
+
+// grpcMetadata() -> List<(String, String)>
+// The metadata sent by the client of the call being handled. Outside of a
+// handler or interceptor the list is empty.
+func builtinGrpcMetadata(e *Evaluator, args ...Object) Object {
+	if len(args) != 0 {
+		return newError("grpcMetadata expects no arguments")
+	}
+	if e.Context == nil {
+		return newList(nil)
+	}
+	md, _ := metadata.FromIncomingContext(e.Context)
+	return grpcMetadataToList(md)
+}
+
+// grpcServerConfig holds the options record of grpcServer:
+//
+//	{ certFile: String, keyFile: String, clientCAFile: String, clientAuth: String,
+//	  reflection: Bool, interceptors: List<(GrpcCall, () -> A) -> A> }
+//
+// The certificate fields are those of httpServe. With reflection the server
+// describes its services to clients such as grpcurl.
+type grpcServerConfig struct {
+	options      []grpc.ServerOption
+	reflection   bool
+	interceptors []Object
+}
+
+func parseGrpcServerConfig(e *Evaluator, name string, arg Object) (*grpcServerConfig, *Error) {
+	rec, ok := arg.(*RecordInstance)
+	if !ok {
+		return nil, newError("%s expects an options record, got %s", name, arg.Type())
+	}
+
+	conf := &grpcServerConfig{}
+	tlsFields := &serverConfig{}
+	if err := tlsFields.parseTLSFields(name, rec); err != nil {
+		return nil, err
+	}
+	tlsConf, err := tlsFields.tlsConfig(e)
+	if err != nil {
+		return nil, newError("%s: %s", name, err.Error())
+	}
+	if tlsConf != nil {
+		conf.options = append(conf.options, grpc.Creds(credentials.NewTLS(tlsConf)))
+	}
+
+	if val := rec.Get("reflection"); val != nil {
+		b, ok := val.(*Boolean)
+		if !ok {
+			return nil, newError("%s: option reflection must be a Bool", name)
+		}
+		conf.reflection = b.Value
+	}
+
+	if val := rec.Get("interceptors"); val != nil {
+		list, ok := val.(*List)
+		if !ok {
+			return nil, newError("%s: option interceptors must be a List of functions", name)
+		}
+		for _, fn := range list.ToSlice() {
+			if e.CaptureHandler != nil {
+				fn = e.CaptureHandler(fn)
+			}
+			if !wsIsCallable(fn) {
+				return nil, newError("%s: interceptors must be functions, got %s", name, fn.Type())
+			}
+			conf.interceptors = append(conf.interceptors, fn)
+		}
+	}
+	return conf, nil
+}
+
+// grpcCallRecord describes the call being handled to interceptors:
+//
+//	{ method: String, metadata: List<(String, String)>, peer: String }
+func grpcCallRecord(ctx context.Context, method *desc.MethodDescriptor) Object {
+	md, _ := metadata.FromIncomingContext(ctx)
+	addr := ""
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr = p.Addr.String()
+	}
+	return NewRecord(map[string]Object{
+		"method":   StringToList(method.GetService().GetFullyQualifiedName() + "/" + method.GetName()),
+		"metadata": grpcMetadataToList(md),
+		"peer":     StringToList(addr),
+	})
+}
+
+// intercept runs the server's interceptors around handler. Each one gets the
+// call and a function running the rest of the chain; the first in the list
+// runs first.
+func (h *FunxyGrpcHandler) intercept(eval *Evaluator, call Object, handler func() Object) Object {
+	var run func(i int) Object
+	run = func(i int) Object {
+		if i == len(h.Interceptors) {
+			return handler()
+		}
+		next := &Builtin{Name: "next", Fn: func(_ *Evaluator, args ...Object) Object {
+			if len(args) != 0 {
+				return newError("next expects no arguments")
+			}
+			return run(i + 1)
+		}}
+		return eval.ApplyFunction(h.Interceptors[i], []Object{call, next})
+	}
+	return run(0)
+}
+
+// registerGrpcReflection adds both versions of the reflection service to
+// server. Descriptors come from the loaded protos first, then from those
+// compiled into funxy.
+func registerGrpcReflection(server *grpc.Server) {
+	opts := reflection.ServerOptions{
+		Services:           server,
+		DescriptorResolver: grpcDescriptorResolver{},
+	}
+	reflectionv1.RegisterServerReflectionServer(server, reflection.NewServerV1(opts))
+	reflectionv1alpha.RegisterServerReflectionServer(server, reflection.NewServer(opts))
+}
+
+// grpcDescriptorResolver looks descriptors up in the protos loaded so far
+type grpcDescriptorResolver struct{}
+
+func (grpcDescriptorResolver) files() *protoregistry.Files {
+	protoRegistryMutex.RLock()
+	defer protoRegistryMutex.RUnlock()
+
+	files := new(protoregistry.Files)
+	seen := map[string]bool{}
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		// A name defined twice keeps its first definition
+		_ = files.RegisterFile(fd)
+	}
+	for _, fd := range protoRegistry {
+		add(fd.UnwrapFile())
+	}
+	return files
+}
+
+func (r grpcDescriptorResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	if fd, err := r.files().FindFileByPath(path); err == nil {
+		return fd, nil
+	}
+	return protoregistry.GlobalFiles.FindFileByPath(path)
+}
+
+func (r grpcDescriptorResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	if d, err := r.files().FindDescriptorByName(name); err == nil {
+		return d, nil
+	}
+	return protoregistry.GlobalFiles.FindDescriptorByName(name)
+}
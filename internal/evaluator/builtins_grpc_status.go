@@ -0,0 +1,151 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/funvibe/funxy/internal/config"
+	"github.com/funvibe/funxy/internal/typesystem"
+
+	// Registers the google.rpc error details, so protoEncode and protoDecode
+	// know them without loading a .proto file
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// Status errors are values of the GrpcError ADT:
+//
+//	type GrpcError = GrpcStatus(GrpcCode, String, List<GrpcDetail>)
+//	type GrpcDetail = { typeName: String, value: Bytes }
+//
+// GrpcCode has a constructor for each gRPC status code but OK. A detail is an
+// encoded protobuf message and the full name of its type.
+
+// grpcCodeNames are the GrpcCode constructors, indexed by status code
+var grpcCodeNames = [...]string{
+	codes.Canceled:           "GrpcCancelled",
+	codes.Unknown:            "GrpcUnknown",
+	codes.InvalidArgument:    "GrpcInvalidArgument",
+	codes.DeadlineExceeded:   "GrpcDeadlineExceeded",
+	codes.NotFound:           "GrpcNotFound",
+	codes.AlreadyExists:      "GrpcAlreadyExists",
+	codes.PermissionDenied:   "GrpcPermissionDenied",
+	codes.ResourceExhausted:  "GrpcResourceExhausted",
+	codes.FailedPrecondition: "GrpcFailedPrecondition",
+	codes.Aborted:            "GrpcAborted",
+	codes.OutOfRange:         "GrpcOutOfRange",
+	codes.Unimplemented:      "GrpcUnimplemented",
+	codes.Internal:           "GrpcInternal",
+	codes.Unavailable:        "GrpcUnavailable",
+	codes.DataLoss:           "GrpcDataLoss",
+	codes.Unauthenticated:    "GrpcUnauthenticated",
+}
+
+const grpcDetailTypePrefix = "type.googleapis.com/"
+
+// registerGrpcStatusTypes adds the GrpcCode and GrpcError ADTs to env
+func registerGrpcStatusTypes(env *Environment) {
+	env.Set("GrpcCode", &TypeObject{TypeVal: typesystem.TCon{Name: "GrpcCode"}})
+	for _, name := range grpcCodeNames[1:] {
+		env.Set(name, &DataInstance{Name: name, Fields: []Object{}, TypeName: "GrpcCode"})
+	}
+	env.Set("GrpcError", &TypeObject{TypeVal: typesystem.TCon{Name: "GrpcError"}})
+	env.Set("GrpcStatus", &Constructor{Name: "GrpcStatus", TypeName: "GrpcError", Arity: 3})
+}
+
+// grpcCodeObject returns the GrpcCode of c. OK, which is not an error, and
+// codes unknown to this version of gRPC become GrpcUnknown.
+func grpcCodeObject(c codes.Code) Object {
+	name := grpcCodeNames[codes.Unknown]
+	if c > codes.OK && int(c) < len(grpcCodeNames) {
+		name = grpcCodeNames[c]
+	}
+	return &DataInstance{Name: name, Fields: []Object{}, TypeName: "GrpcCode"}
+}
+
+// grpcErrorObject converts err, as returned by a call, to a GrpcError
+func grpcErrorObject(err error) Object {
+	st := status.Convert(err)
+	details := []Object{}
+	for _, d := range st.Proto().GetDetails() {
+		details = append(details, NewRecord(map[string]Object{
+			"typeName": StringToList(strings.TrimPrefix(d.GetTypeUrl(), grpcDetailTypePrefix)),
+			"value":    bytesFromSlice(d.GetValue()),
+		}))
+	}
+	return &DataInstance{
+		Name:     "GrpcStatus",
+		Fields:   []Object{grpcCodeObject(st.Code()), StringToList(st.Message()), newList(details)},
+		TypeName: "GrpcError",
+	}
+}
+
+// grpcStatusError converts a GrpcError value back to a status error
+func grpcStatusError(obj *DataInstance) error {
+	if obj.Name != "GrpcStatus" || len(obj.Fields) != 3 {
+		return status.Errorf(codes.Unknown, "invalid GrpcError %s", obj.Inspect())
+	}
+	code := codes.Unknown
+	if c, ok := obj.Fields[0].(*DataInstance); ok {
+		for i, name := range grpcCodeNames {
+			if i > 0 && name == c.Name {
+				code = codes.Code(i)
+			}
+		}
+	}
+
+	st := &spb.Status{Code: int32(code), Message: listToString(obj.Fields[1])}
+	details, ok := obj.Fields[2].(*List)
+	if !ok {
+		return status.Errorf(codes.Unknown, "GrpcStatus details must be a List")
+	}
+	for _, d := range details.ToSlice() {
+		rec, ok := d.(*RecordInstance)
+		if !ok {
+			return status.Errorf(codes.Unknown, "GrpcStatus details must be { typeName: String, value: Bytes } records")
+		}
+		typeName, okName := rec.Get("typeName").(*List)
+		value, okValue := rec.Get("value").(*Bytes)
+		if !okName || !okValue {
+			return status.Errorf(codes.Unknown, "GrpcStatus details must be { typeName: String, value: Bytes } records")
+		}
+		st.Details = append(st.Details, &anypb.Any{
+			TypeUrl: grpcDetailTypePrefix + ListToString(typeName),
+			Value:   value.data,
+		})
+	}
+	return status.FromProto(st).Err()
+}
+
+// grpcHandlerResult interprets the value a handler or interceptor returned.
+// A GrpcError, or a Fail holding one, ends the call with that status, another
+// Fail with status Unknown. Ok is unwrapped, anything else is the response.
+func grpcHandlerResult(result Object) (Object, error) {
+	if err, ok := result.(*Error); ok {
+		return nil, fmt.Errorf("%s", err.Message)
+	}
+	di, ok := result.(*DataInstance)
+	if !ok {
+		return result, nil
+	}
+	isResult := di.TypeName == config.ResultTypeName && len(di.Fields) == 1
+	switch {
+	case di.TypeName == "GrpcError":
+		return nil, grpcStatusError(di)
+	case isResult && di.Name == config.OkCtorName:
+		return di.Fields[0], nil
+	case isResult && di.Name == config.FailCtorName:
+		if reason, ok := di.Fields[0].(*DataInstance); ok && reason.TypeName == "GrpcError" {
+			return nil, grpcStatusError(reason)
+		}
+		if l, ok := di.Fields[0].(*List); ok {
+			return nil, status.Error(codes.Unknown, ListToString(l))
+		}
+		return nil, status.Error(codes.Unknown, di.Fields[0].Inspect())
+	}
+	return result, nil
+}
@@ -0,0 +1,424 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// grpcMsgStream is what client and server streams have in common
+type grpcMsgStream interface {
+	SendMsg(m any) error
+	RecvMsg(m any) error
+}
+
+// GrpcStreamObject is one side of a streaming RPC. Clients get it from
+// grpcInvokeStream or grpcOpenStream, streaming handlers receive it as an
+// argument. Iterating over it receives messages until the other side closes.
+type GrpcStreamObject struct {
+	Method *desc.MethodDescriptor
+	stream grpcMsgStream
+	client grpc.ClientStream  // nil on the server side
+	cancel context.CancelFunc // nil on the server side
+
+	// gRPC allows one sender and one receiver at a time
+	sendMu sync.Mutex
+	recvMu sync.Mutex
+}
+
+func (o *GrpcStreamObject) Type() ObjectType { return "GrpcStream" }
+func (o *GrpcStreamObject) Inspect() string {
+	return fmt.Sprintf("GrpcStream(%s)", o.Method.GetFullyQualifiedName())
+}
+func (o *GrpcStreamObject) RuntimeType() typesystem.Type {
+	return typesystem.TCon{Name: "GrpcStream"}
+}
+func (o *GrpcStreamObject) Hash() uint32 {
+	return 0
+}
+
+// Next receives the next message for for-in loops
+func (o *GrpcStreamObject) Next() Object {
+	msg, err := o.recv()
+	if err != nil {
+		return newError("gRPC stream failed: %s", err.Error())
+	}
+	if msg == nil {
+		return makeNone()
+	}
+	return makeSome(msg)
+}
+
+// messageTypes returns the types of the messages this side sends and receives
+func (o *GrpcStreamObject) messageTypes() (send, recv *desc.MessageDescriptor) {
+	if o.client != nil {
+		return o.Method.GetInputType(), o.Method.GetOutputType()
+	}
+	return o.Method.GetOutputType(), o.Method.GetInputType()
+}
+
+// canSend reports whether this side sends a stream of messages. A client of a
+// server-streaming method sends its only request when the call starts, and a
+// handler of a client-streaming method responds with its return value.
+func (o *GrpcStreamObject) canSend() bool {
+	if o.client != nil {
+		return o.Method.IsClientStreaming()
+	}
+	return o.Method.IsServerStreaming()
+}
+
+func (o *GrpcStreamObject) send(obj Object) error {
+	sendType, _ := o.messageTypes()
+	msg := dynamic.NewMessage(sendType)
+	if err := objectToDynamicMessage(obj, msg); err != nil {
+		return fmt.Errorf("failed to build message: %w", err)
+	}
+
+	o.sendMu.Lock()
+	defer o.sendMu.Unlock()
+	return o.stream.SendMsg(msg)
+}
+
+// recv receives the next message. It returns nil at the end of the stream.
+func (o *GrpcStreamObject) recv() (Object, error) {
+	_, recvType := o.messageTypes()
+	msg := dynamic.NewMessage(recvType)
+
+	o.recvMu.Lock()
+	defer o.recvMu.Unlock()
+	if err := o.stream.RecvMsg(msg); err != nil {
+		// The call is over either way
+		o.release()
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return dynamicMessageToObject(msg), nil
+}
+
+// closeSend tells the server that the client has sent all its messages
+func (o *GrpcStreamObject) closeSend() error {
+	if o.client == nil {
+		return fmt.Errorf("a handler closes its stream by returning")
+	}
+	o.sendMu.Lock()
+	defer o.sendMu.Unlock()
+	return o.client.CloseSend()
+}
+
+// release frees the resources of a finished client call
+func (o *GrpcStreamObject) release() {
+	if o.cancel != nil {
+		o.cancel()
+	}
+}
+
+// openGrpcStream starts a streaming call of md on conn
+func openGrpcStream(conn *grpc.ClientConn, methodPath string, md *desc.MethodDescriptor, opts *grpcCallOptions) (*GrpcStreamObject, error) {
+	ctx, cancel := opts.context()
+
+	streamDesc := &grpc.StreamDesc{
+		StreamName:    md.GetName(),
+		ServerStreams: md.IsServerStreaming(),
+		ClientStreams: md.IsClientStreaming(),
+	}
+	if methodPath[0] != '/' {
+		methodPath = "/" + methodPath
+	}
+
+	cs, err := conn.NewStream(ctx, streamDesc, methodPath)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &GrpcStreamObject{Method: md, stream: cs, client: cs, cancel: cancel}, nil
+}
+
+// grpcStreamArgs checks the connection and method arguments shared by the
+// functions that start streaming calls.
+func grpcStreamArgs(name string, conn Object, method Object) (*GrpcConnObject, string, *Error) {
+	connObj, ok := conn.(*GrpcConnObject)
+	if !ok || connObj.Conn == nil {
+		return nil, "", newError("%s expects a valid GrpcConn", name)
+	}
+	return connObj, listToString(method), nil
+}
+
+// grpcTimeoutOptions returns call options with the deadline of a timeoutMs argument
+func grpcTimeoutOptions(name string, timeout Object) (*grpcCallOptions, *Error) {
+	ms, ok := timeout.(*Integer)
+	if !ok {
+		return nil, newError("%s: timeoutMs must be an Int", name)
+	}
+	return &grpcCallOptions{timeout: time.Duration(ms.Value) * time.Millisecond}, nil
+}
+
+// grpcInvokeStream(conn: GrpcConn, method: String, request: A, options?) -> Result<String, GrpcStream>
+func builtinGrpcInvokeStream(e *Evaluator, args ...Object) Object {
+	if len(args) != 3 && len(args) != 4 {
+		return newError("grpcInvokeStream expects 3 or 4 arguments")
+	}
+	var optsArg Object
+	if len(args) == 4 {
+		optsArg = args[3]
+	}
+	opts, errObj := parseGrpcCallOptions("grpcInvokeStream", optsArg)
+	if errObj != nil {
+		return errObj
+	}
+	return grpcInvokeStream("grpcInvokeStream", args[0], args[1], args[2], opts)
+}
+
+// grpcInvokeStreamTimeout(conn: GrpcConn, method: String, request: A, timeoutMs: Int) -> Result<String, GrpcStream>
+func builtinGrpcInvokeStreamTimeout(e *Evaluator, args ...Object) Object {
+	if len(args) != 4 {
+		return newError("grpcInvokeStreamTimeout expects 4 arguments")
+	}
+	opts, errObj := grpcTimeoutOptions("grpcInvokeStreamTimeout", args[3])
+	if errObj != nil {
+		return errObj
+	}
+	return grpcInvokeStream("grpcInvokeStreamTimeout", args[0], args[1], args[2], opts)
+}
+
+// grpcInvokeStream sends the only request of a server-streaming call and
+// returns the stream of responses.
+func grpcInvokeStream(name string, conn, method, request Object, opts *grpcCallOptions) Object {
+	connObj, methodPath, errObj := grpcStreamArgs(name, conn, method)
+	if errObj != nil {
+		return errObj
+	}
+
+	md, err := findMethodDescriptor(methodPath)
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	if !md.IsServerStreaming() || md.IsClientStreaming() {
+		return makeFailStr(fmt.Sprintf("%s is not a server-streaming method, use grpcInvoke or grpcOpenStream", methodPath))
+	}
+
+	stream, err := openGrpcStream(connObj.Conn, methodPath, md, opts)
+	if err != nil {
+		return makeFailStr("RPC failed: " + err.Error())
+	}
+	if err := stream.send(request); err != nil {
+		stream.release()
+		return makeFailStr("RPC failed: " + err.Error())
+	}
+	if err := stream.closeSend(); err != nil {
+		stream.release()
+		return makeFailStr("RPC failed: " + err.Error())
+	}
+
+	return makeOk(stream)
+}
+
+// grpcOpenStream(conn: GrpcConn, method: String, options?) -> Result<String, GrpcStream>
+func builtinGrpcOpenStream(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 && len(args) != 3 {
+		return newError("grpcOpenStream expects 2 or 3 arguments")
+	}
+	var optsArg Object
+	if len(args) == 3 {
+		optsArg = args[2]
+	}
+	opts, errObj := parseGrpcCallOptions("grpcOpenStream", optsArg)
+	if errObj != nil {
+		return errObj
+	}
+	return grpcOpenStream("grpcOpenStream", args[0], args[1], opts)
+}
+
+// grpcOpenStreamTimeout(conn: GrpcConn, method: String, timeoutMs: Int) -> Result<String, GrpcStream>
+func builtinGrpcOpenStreamTimeout(e *Evaluator, args ...Object) Object {
+	if len(args) != 3 {
+		return newError("grpcOpenStreamTimeout expects 3 arguments")
+	}
+	opts, errObj := grpcTimeoutOptions("grpcOpenStreamTimeout", args[2])
+	if errObj != nil {
+		return errObj
+	}
+	return grpcOpenStream("grpcOpenStreamTimeout", args[0], args[1], opts)
+}
+
+// grpcOpenStream starts a client-streaming or bidirectional call
+func grpcOpenStream(name string, conn, method Object, opts *grpcCallOptions) Object {
+	connObj, methodPath, errObj := grpcStreamArgs(name, conn, method)
+	if errObj != nil {
+		return errObj
+	}
+
+	md, err := findMethodDescriptor(methodPath)
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	if !md.IsClientStreaming() {
+		return makeFailStr(fmt.Sprintf("%s is not a client-streaming method, use grpcInvoke or grpcInvokeStream", methodPath))
+	}
+
+	stream, err := openGrpcStream(connObj.Conn, methodPath, md, opts)
+	if err != nil {
+		return makeFailStr("RPC failed: " + err.Error())
+	}
+	return makeOk(stream)
+}
+
+// grpcSend(stream: GrpcStream, message: A) -> Result<String, Nil>
+func builtinGrpcSend(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 {
+		return newError("grpcSend expects 2 arguments")
+	}
+
+	stream, ok := args[0].(*GrpcStreamObject)
+	if !ok {
+		return newError("grpcSend expects a GrpcStream")
+	}
+	if !stream.canSend() {
+		if stream.client != nil {
+			return makeFailStr(fmt.Sprintf("%s takes a single request, sent by grpcInvokeStream", stream.Method.GetName()))
+		}
+		return makeFailStr(fmt.Sprintf("%s responds with the return value of its handler", stream.Method.GetName()))
+	}
+
+	if err := stream.send(args[1]); err != nil {
+		return makeFailStr(err.Error())
+	}
+	return makeOk(&Nil{})
+}
+
+// grpcRecv(stream: GrpcStream) -> Result<String, Option<B>>
+func builtinGrpcRecv(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("grpcRecv expects 1 argument")
+	}
+
+	stream, ok := args[0].(*GrpcStreamObject)
+	if !ok {
+		return newError("grpcRecv expects a GrpcStream")
+	}
+
+	msg, err := stream.recv()
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	if msg == nil {
+		return makeOk(makeNone())
+	}
+	return makeOk(makeSome(msg))
+}
+
+// grpcCloseSend(stream: GrpcStream) -> Result<String, Nil>
+func builtinGrpcCloseSend(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("grpcCloseSend expects 1 argument")
+	}
+
+	stream, ok := args[0].(*GrpcStreamObject)
+	if !ok {
+		return newError("grpcCloseSend expects a GrpcStream")
+	}
+	if err := stream.closeSend(); err != nil {
+		return makeFailStr(err.Error())
+	}
+	return makeOk(&Nil{})
+}
+
+// grpcCloseAndRecv(stream: GrpcStream) -> Result<String, B>
+func builtinGrpcCloseAndRecv(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("grpcCloseAndRecv expects 1 argument")
+	}
+
+	stream, ok := args[0].(*GrpcStreamObject)
+	if !ok {
+		return newError("grpcCloseAndRecv expects a GrpcStream")
+	}
+	if err := stream.closeSend(); err != nil {
+		return makeFailStr(err.Error())
+	}
+
+	msg, err := stream.recv()
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	if msg == nil {
+		return makeFailStr("stream ended without a response")
+	}
+	return makeOk(msg)
+}
+
+// grpcCancel(stream: GrpcStream) -> Nil
+func builtinGrpcCancel(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("grpcCancel expects 1 argument")
+	}
+
+	stream, ok := args[0].(*GrpcStreamObject)
+	if !ok {
+		return newError("grpcCancel expects a GrpcStream")
+	}
+	stream.release()
+	return &Nil{}
+}
+
+// HandleStream runs the handler of a streaming method through the server's
+// interceptors. Handlers of methods with a single request get it before the
+// stream. Handlers of client-streaming
+// methods respond with their return value, the others send their responses
+// on the stream.
+func (h *FunxyGrpcHandler) HandleStream(md *desc.MethodDescriptor, ss grpc.ServerStream) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = status.Errorf(codes.Internal, "panic in gRPC handler: %v", r)
+		}
+	}()
+
+	if !acquireGrpcConnSlot() {
+		return status.Error(codes.Unavailable, "Too Many Connections")
+	}
+	defer releaseGrpcConnSlot()
+
+	fn, err := h.lookupMethod(md.GetName())
+	if err != nil {
+		return err
+	}
+
+	stream := &GrpcStreamObject{Method: md, stream: ss}
+	args := []Object{stream}
+	if !md.IsClientStreaming() {
+		inMsg := dynamic.NewMessage(md.GetInputType())
+		if err := ss.RecvMsg(inMsg); err != nil {
+			return err
+		}
+		args = []Object{dynamicMessageToObject(inMsg), stream}
+	}
+
+	eval := h.requestEvaluator(ss.Context())
+	call := grpcCallRecord(ss.Context(), md)
+	result, err := grpcHandlerResult(h.intercept(eval, call, func() Object {
+		return eval.ApplyFunction(fn, args)
+	}))
+	if err != nil {
+		return err
+	}
+
+	if md.IsServerStreaming() {
+		return nil
+	}
+	outMsg := dynamic.NewMessage(md.GetOutputType())
+	if err := objectToDynamicMessage(result, outMsg); err != nil {
+		return err
+	}
+	return ss.SendMsg(outMsg)
+}
@@ -0,0 +1,153 @@
+package evaluator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const grpcTestProto = `syntax = "proto3";
+package builtintest;
+service Greeter { rpc SayHello (HelloRequest) returns (HelloReply); }
+message HelloRequest { string name = 1; }
+message HelloReply { string message = 1; }
+`
+
+// startGrpcServer serves builtintest.Greeter with the given grpcServer
+// options and returns its address
+func startGrpcServer(t *testing.T, options Object) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "grpc_builtin_test.proto")
+	if err := os.WriteFile(path, []byte(grpcTestProto), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if res := builtinGrpcLoadProto(New(), StringToList(path)); !isOk(res) {
+		t.Fatalf("grpcLoadProto returned %s", res.Inspect())
+	}
+
+	server, ok := builtinGrpcServer(New(), options).(*GrpcServerObject)
+	if !ok {
+		t.Fatalf("grpcServer failed")
+	}
+	sayHello := &Builtin{Name: "SayHello", Fn: func(e *Evaluator, args ...Object) Object {
+		name := ListToString(args[0].(*RecordInstance).Get("name").(*List))
+		return NewRecord(map[string]Object{"message": StringToList("Hello " + name)})
+	}}
+	impl := NewRecord(map[string]Object{"SayHello": sayHello})
+	if res := builtinGrpcRegister(New(), server, StringToList("builtintest.Greeter"), impl); !isOk(res) {
+		t.Fatalf("grpcRegister returned %s", res.Inspect())
+	}
+
+	addr := "127.0.0.1:" + itoa(freePort(t))
+	if res := builtinGrpcServeAsync(New(), server, StringToList(addr)); !isOk(res) {
+		t.Fatalf("grpcServeAsync returned %s", res.Inspect())
+	}
+	t.Cleanup(func() { builtinGrpcStop(New(), server) })
+	return addr
+}
+
+func isOk(obj Object) bool {
+	di, ok := obj.(*DataInstance)
+	return ok && di.Name == "Ok"
+}
+
+// sayHello connects with the given grpcConnect options and calls SayHello
+func sayHello(t *testing.T, addr string, options map[string]any) Object {
+	t.Helper()
+	conn := builtinGrpcConnect(New(), StringToList(addr), serverConfigRecord(options))
+	if !isOk(conn) {
+		t.Fatalf("grpcConnect returned %s", conn.Inspect())
+	}
+	defer builtinGrpcClose(New(), conn.(*DataInstance).Fields[0])
+	request := NewRecord(map[string]Object{"name": StringToList("tls")})
+	return builtinGrpcInvoke(New(), conn.(*DataInstance).Fields[0], StringToList("builtintest.Greeter/SayHello"), request,
+		serverConfigRecord(map[string]any{"timeout": 5000}))
+}
+
+func TestGrpcMutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startGrpcServer(t, serverConfigRecord(map[string]any{
+		"certFile": pki.certFile, "keyFile": pki.keyFile, "clientCAFile": pki.caFile,
+	}))
+
+	res := sayHello(t, addr, map[string]any{"caFile": pki.caFile, "certFile": pki.clientCertFile, "keyFile": pki.clientKeyFile})
+	if !isOk(res) {
+		t.Fatalf("call with a client certificate returned %s", res.Inspect())
+	}
+	if got := ListToString(res.(*DataInstance).Fields[0].(*RecordInstance).Get("message").(*List)); got != "Hello tls" {
+		t.Errorf("got %q", got)
+	}
+
+	if res := sayHello(t, addr, map[string]any{"caFile": pki.caFile}); isOk(res) {
+		t.Errorf("call without a client certificate succeeded")
+	}
+	if res := sayHello(t, addr, map[string]any{}); isOk(res) {
+		t.Errorf("plain text call to a TLS server succeeded")
+	}
+}
+
+func TestGrpcReflection(t *testing.T) {
+	addr := startGrpcServer(t, serverConfigRecord(map[string]any{"reflection": true}))
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := reflectionv1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ask := func(req *reflectionv1.ServerReflectionRequest) *reflectionv1.ServerReflectionResponse {
+		t.Helper()
+		if err := stream.Send(req); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e := resp.GetErrorResponse(); e != nil {
+			t.Fatalf("reflection error: %s", e.GetErrorMessage())
+		}
+		return resp
+	}
+
+	var services []string
+	list := ask(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_ListServices{},
+	})
+	for _, s := range list.GetListServicesResponse().GetService() {
+		services = append(services, s.GetName())
+	}
+	if !strings.Contains(strings.Join(services, " "), "builtintest.Greeter") {
+		t.Errorf("services %v do not include builtintest.Greeter", services)
+	}
+
+	file := ask(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "builtintest.Greeter"},
+	})
+	protos := file.GetFileDescriptorResponse().GetFileDescriptorProto()
+	if len(protos) == 0 {
+		t.Fatal("no file descriptor for builtintest.Greeter")
+	}
+	fd := &descriptorpb.FileDescriptorProto{}
+	if err := proto.Unmarshal(protos[0], fd); err != nil {
+		t.Fatal(err)
+	}
+	if fd.GetPackage() != "builtintest" || len(fd.GetMessageType()) != 2 {
+		t.Errorf("unexpected descriptor %s", fd.String())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -34,13 +35,13 @@ var (
 	httpServersMu     sync.Mutex
 	httpServerCounter int64 = 0
 
-	sharedListeners   = make(map[int]*sharedListener)
+	sharedListeners   = make(map[string]*sharedListener)
 	sharedListenersMu sync.Mutex
 )
 
 type sharedListener struct {
 	net.Listener
-	port  int
+	addr  string
 	conns chan net.Conn
 	mu    sync.Mutex
 	refs  int
@@ -93,7 +94,7 @@ func (vl *virtualListener) Close() error {
 		vl.sl.refs--
 		if vl.sl.refs <= 0 {
 			vl.sl.Listener.Close() // unblocks acceptLoop
-			delete(sharedListeners, vl.sl.port)
+			delete(sharedListeners, vl.sl.addr)
 		}
 		vl.sl.mu.Unlock()
 	})
@@ -104,24 +105,24 @@ func (vl *virtualListener) Addr() net.Addr {
 	return vl.sl.Listener.Addr()
 }
 
-// getSharedListener returns a shared virtual listener for the given port
-func getSharedListener(port int) (net.Listener, error) {
+// getSharedListener returns a shared virtual listener for the given address
+func getSharedListener(addr string) (net.Listener, error) {
 	sharedListenersMu.Lock()
 	defer sharedListenersMu.Unlock()
 
-	sl, ok := sharedListeners[port]
+	sl, ok := sharedListeners[addr]
 	if !ok {
-		l, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		l, err := net.Listen("tcp", addr)
 		if err != nil {
 			return nil, err
 		}
 		sl = &sharedListener{
 			Listener: l,
-			port:     port,
+			addr:     addr,
 			conns:    make(chan net.Conn),
 			refs:     0,
 		}
-		sharedListeners[port] = sl
+		sharedListeners[addr] = sl
 		go sl.acceptLoop()
 	}
 
@@ -150,6 +151,20 @@ func HttpBuiltins() map[string]*Builtin {
 		"httpServe":             {Fn: builtinHttpServe, Name: "httpServe"},
 		"httpServeAsync":        {Fn: builtinHttpServeAsync, Name: "httpServeAsync"},
 		"httpServerStop":        {Fn: builtinHttpServerStop, Name: "httpServerStop"},
+		"httpStream":            {Fn: builtinHttpStream, Name: "httpStream"},
+		"httpEventStream":       {Fn: builtinHttpEventStream, Name: "httpEventStream"},
+		"httpWrite":             {Fn: builtinHttpWrite, Name: "httpWrite"},
+		"httpFlush":             {Fn: builtinHttpFlush, Name: "httpFlush"},
+		"httpSendEvent":         {Fn: builtinHttpSendEvent, Name: "httpSendEvent"},
+		"httpClosed":            {Fn: builtinHttpClosed, Name: "httpClosed"},
+		"httpRequestStream":     {Fn: builtinHttpRequestStream, Name: "httpRequestStream"},
+		"httpReadChunk":         {Fn: builtinHttpReadChunk, Name: "httpReadChunk"},
+		"httpReadEvent":         {Fn: builtinHttpReadEvent, Name: "httpReadEvent"},
+		"httpEvents":            {Fn: builtinHttpEvents, Name: "httpEvents"},
+		"httpStreamClose":       {Fn: builtinHttpStreamClose, Name: "httpStreamClose"},
+		"httpParseMultipart":    {Fn: builtinHttpParseMultipart, Name: "httpParseMultipart"},
+		"httpReadUpload":        {Fn: builtinHttpReadUpload, Name: "httpReadUpload"},
+		"httpMultipart":         {Fn: builtinHttpMultipart, Name: "httpMultipart"},
 	}
 }
 
@@ -265,23 +280,40 @@ func builtinHttpDelete(e *Evaluator, args ...Object) Object {
 // httpRequest: (String, String, List<(String, String)>, String, Int) -> Result<String, HttpResponse>
 // timeout is in milliseconds, 0 or negative means use global default
 func builtinHttpRequest(e *Evaluator, args ...Object) Object {
+	req, errObj := parseHttpRequestArgs("httpRequest", args)
+	if errObj != nil {
+		return errObj
+	}
+	return doHttpRequestWithTimeout(req.method, req.url, req.headers, req.body, req.timeout)
+}
+
+// httpRequestArgs are the arguments of httpRequest and httpRequestStream
+type httpRequestArgs struct {
+	method  string
+	url     string
+	headers [][2]string
+	body    io.Reader
+	timeout time.Duration
+}
+
+func parseHttpRequestArgs(name string, args []Object) (*httpRequestArgs, *Error) {
 	if len(args) < 3 || len(args) > 5 {
-		return newError("httpRequest expects 3 to 5 arguments, got %d", len(args))
+		return nil, newError("%s expects 3 to 5 arguments, got %d", name, len(args))
 	}
 
 	methodList, ok := args[0].(*List)
 	if !ok {
-		return newError("httpRequest expects a string method, got %s", args[0].Type())
+		return nil, newError("%s expects a string method, got %s", name, args[0].Type())
 	}
 
 	urlList, ok := args[1].(*List)
 	if !ok {
-		return newError("httpRequest expects a string URL, got %s", args[1].Type())
+		return nil, newError("%s expects a string URL, got %s", name, args[1].Type())
 	}
 
 	headersList, ok := args[2].(*List)
 	if !ok {
-		return newError("httpRequest expects a list of headers, got %s", args[2].Type())
+		return nil, newError("%s expects a list of headers, got %s", name, args[2].Type())
 	}
 
 	var bodyReader io.Reader
@@ -290,7 +322,7 @@ func builtinHttpRequest(e *Evaluator, args ...Object) Object {
 		if _, isNil := args[3].(*Nil); !isNil {
 			bodyReader, err = getBodyReader(args[3])
 			if err != nil {
-				return newError("httpRequest: %s", err.Error())
+				return nil, newError("%s: %s", name, err.Error())
 			}
 		}
 	}
@@ -300,24 +332,21 @@ func builtinHttpRequest(e *Evaluator, args ...Object) Object {
 		if t, ok := args[4].(*Integer); ok {
 			timeoutInt = t.Value
 		} else if _, isNil := args[4].(*Nil); !isNil {
-			return newError("httpRequest expects an integer timeout (ms), got %s", args[4].Type())
+			return nil, newError("%s expects an integer timeout (ms), got %s", name, args[4].Type())
 		}
 	}
 
-	method := ListToString(methodList)
-	url := ListToString(urlList)
-
 	// Parse headers
 	var headers [][2]string
 	for _, h := range headersList.ToSlice() {
 		tuple, ok := h.(*Tuple)
 		if !ok || len(tuple.Elements) != 2 {
-			return newError("httpRequest expects headers as list of (String, String) tuples")
+			return nil, newError("%s expects headers as list of (String, String) tuples", name)
 		}
 		keyList, ok1 := tuple.Elements[0].(*List)
 		valList, ok2 := tuple.Elements[1].(*List)
 		if !ok1 || !ok2 {
-			return newError("httpRequest header key and value must be strings")
+			return nil, newError("%s header key and value must be strings", name)
 		}
 		headers = append(headers, [2]string{ListToString(keyList), ListToString(valList)})
 	}
@@ -328,7 +357,13 @@ func builtinHttpRequest(e *Evaluator, args ...Object) Object {
 		timeout = time.Duration(timeoutInt) * time.Millisecond
 	}
 
-	return doHttpRequestWithTimeout(method, url, headers, bodyReader, timeout)
+	return &httpRequestArgs{
+		method:  ListToString(methodList),
+		url:     ListToString(urlList),
+		headers: headers,
+		body:    bodyReader,
+		timeout: timeout,
+	}, nil
 }
 
 // httpSetTimeout: (Int) -> Nil
@@ -475,6 +510,35 @@ func doHttpRequestWithTimeout(method, url string, headers [][2]string, body io.R
 		return makeFail(StringToList("HTTP request blocked: no mock found for " + url))
 	}
 
+	client, req, err := newHttpClientRequest(method, url, headers, body, timeout)
+	if err != nil {
+		return makeFail(StringToList("failed to create request: " + err.Error()))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return makeFail(StringToList("request failed: " + err.Error()))
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return makeFail(StringToList("failed to read response: " + err.Error()))
+	}
+
+	// Build response record
+	response := NewRecord(map[string]Object{
+		"status":  &Integer{Value: int64(resp.StatusCode)},
+		"body":    StringToList(string(respBody)),
+		"headers": httpHeadersToList(resp.Header),
+	})
+
+	return makeOk(response)
+}
+
+// newHttpClientRequest prepares a request and a client for it. A timeout of
+// zero leaves the client without one.
+func newHttpClientRequest(method, url string, headers [][2]string, body io.Reader, timeout time.Duration) (*http.Client, *http.Request, error) {
 	// Handle http+unix:// scheme for Unix socket connections
 	requestURL := url
 	var transport *http.Transport
@@ -489,7 +553,6 @@ func doHttpRequestWithTimeout(method, url string, headers [][2]string, body io.R
 		transport = http.DefaultTransport.(*http.Transport)
 	}
 
-	// Make real HTTP request
 	client := &http.Client{
 		Timeout:   timeout,
 		Transport: transport,
@@ -502,43 +565,27 @@ func doHttpRequestWithTimeout(method, url string, headers [][2]string, body io.R
 
 	req, err := http.NewRequest(method, requestURL, body)
 	if err != nil {
-		return makeFail(StringToList("failed to create request: " + err.Error()))
+		return nil, nil, err
 	}
 
 	// Set headers
 	for _, h := range headers {
 		req.Header.Set(h[0], h[1])
 	}
+	return client, req, nil
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return makeFail(StringToList("request failed: " + err.Error()))
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return makeFail(StringToList("failed to read response: " + err.Error()))
-	}
-
-	// Build response headers
-	var respHeaders []Object
-	for key, values := range resp.Header {
+// httpHeadersToList converts headers to a List<(String, String)>
+func httpHeadersToList(header http.Header) *List {
+	var headers []Object
+	for key, values := range header {
 		for _, val := range values {
-			respHeaders = append(respHeaders, &Tuple{
+			headers = append(headers, &Tuple{
 				Elements: []Object{StringToList(key), StringToList(val)},
 			})
 		}
 	}
-
-	// Build response record
-	response := NewRecord(map[string]Object{
-		"status":  &Integer{Value: int64(resp.StatusCode)},
-		"body":    StringToList(string(respBody)),
-		"headers": newList(respHeaders),
-	})
-
-	return makeOk(response)
+	return newList(headers)
 }
 
 // objectToJson converts an Object to JSON string
@@ -635,196 +682,87 @@ func isStringList(l *List) bool {
 	return ok
 }
 
-// httpServe: (Int, (HttpRequest) -> HttpResponse) -> Result<String, Nil>
+// httpServe: (Int | HttpServerConfig, (HttpRequest) -> HttpResponse) -> Result<String, Nil>
 func builtinHttpServe(e *Evaluator, args ...Object) Object {
-	if len(args) != 2 {
-		return newError("httpServe expects 2 arguments, got %d", len(args))
+	server, l, errObj, err := newHttpServer(e, "httpServe", args)
+	if errObj != nil {
+		return errObj
 	}
-
-	portInt, ok := args[0].(*Integer)
-	if !ok {
-		return newError("httpServe expects an integer port, got %s", args[0].Type())
+	if err != nil {
+		return makeFailStr(err.Error())
 	}
 
-	handler := args[1]
-	if !httpIsCallable(handler) {
-		return newError("httpServe expects a handler function, got %s", args[1].Type())
+	// Start server (blocking)
+	err = serveHttp(server, l)
+	if err != nil && err != http.ErrServerClosed {
+		return makeFail(StringToList(err.Error()))
 	}
 
-	port := int(portInt.Value)
-
-	// Capture handler if CaptureHandler is available
-	if e.CaptureHandler != nil {
-		handler = e.CaptureHandler(handler)
-	}
+	return makeOk(&Nil{})
+}
 
-	// Create a snapshot of the evaluator/VM for the server
-	// This avoids race conditions when the main VM continues execution and modifies globals
-	var serverEval *Evaluator
-	if e.Forker != nil {
-		serverEval = e.Fork()
-	} else {
-		// If Fork is missing, we must ensure we are not in a VM context that requires it.
-		// Clone is safe for pure tree-walk (VMCallHandler is nil), but unsafe if VMCallHandler is present (shared state).
-		if e.VMCallHandler != nil {
-			return newError("httpServe: concurrent execution requires Fork support when VM is active")
-		}
-		serverEval = e.Clone()
+// httpServeAsync: (Int | HttpServerConfig, (HttpRequest) -> HttpResponse) -> Int
+// Starts a non-blocking HTTP server and returns server ID
+func builtinHttpServeAsync(e *Evaluator, args ...Object) Object {
+	server, l, errObj, err := newHttpServer(e, "httpServeAsync", args)
+	if errObj != nil {
+		return errObj
 	}
-
-	// Optimization: Mark server environment as read-only to avoid lock contention during concurrent requests
-	if serverEval.GlobalEnv != nil {
-		serverEval.GlobalEnv.SetReadOnly()
+	if err != nil {
+		// The server ID is not a Result, so a failure to start is an error
+		return newError("httpServeAsync: %s", err.Error())
 	}
 
-	// Create HTTP server
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		// Panic recovery for robust server
-		defer func() {
-			if rec := recover(); rec != nil {
-				// Log panic but don't crash the whole process
-				fmt.Printf("[httpServe] Panic in handler: %v\n", rec)
-				// Stack trace is usually printed by net/http or we could print it here
-				w.WriteHeader(http.StatusInternalServerError)
-			}
-		}()
-
-		if !acquireHttpConnSlot() {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			_, _ = w.Write([]byte("503 Service Unavailable: Too Many Connections"))
-			return
-		}
-		defer releaseHttpConnSlot()
-
-		// Create a fresh evaluator/VM for each request from the snapshot
-		var reqEval *Evaluator
-		if serverEval.Forker != nil {
-			reqEval = serverEval.Fork()
-		} else {
-			reqEval = serverEval.FastFork()
-		}
-		defer reqEval.Release()
-
-		// Build HttpRequest object
-		var headers []Object
-		for key, values := range r.Header {
-			for _, val := range values {
-				headers = append(headers, &Tuple{
-					Elements: []Object{StringToList(key), StringToList(val)},
-				})
-			}
-		}
-
-		bodyBytes, _ := io.ReadAll(r.Body)
-		defer func() { _ = r.Body.Close() }()
-
-		request := NewRecord(map[string]Object{
-			"method":  StringToList(r.Method),
-			"path":    StringToList(r.URL.Path),
-			"query":   StringToList(r.URL.RawQuery),
-			"headers": newList(headers),
-			"body":    StringToList(string(bodyBytes)),
-		})
-
-		// Call handler
-		result := reqEval.ApplyFunction(handler, []Object{request})
-
-		// Parse response
-		if result == nil {
-			w.WriteHeader(500)
-			_, _ = w.Write([]byte("Handler returned nil"))
-			return
-		}
-
-		if result != nil && result.Type() == ERROR_OBJ {
-			w.WriteHeader(500)
-			_, _ = w.Write([]byte(result.(*Error).Message))
-			return
-		}
-
-		respRec, ok := result.(*RecordInstance)
-		if !ok {
-			w.WriteHeader(500)
-			_, _ = w.Write([]byte("Handler must return HttpResponse record"))
-			return
-		}
-
-		// Set response headers
-		if headersObj := respRec.Get("headers"); headersObj != nil {
-			if headersList, ok := headersObj.(*List); ok {
-				for _, h := range headersList.ToSlice() {
-					if tuple, ok := h.(*Tuple); ok && len(tuple.Elements) == 2 {
-						key := ListToString(tuple.Elements[0].(*List))
-						val := ListToString(tuple.Elements[1].(*List))
-						if strings.EqualFold(key, "Set-Cookie") {
-							w.Header().Add(key, val)
-						} else {
-							w.Header().Set(key, val)
-						}
-					}
-				}
-			}
-		}
+	// Generate server ID
+	httpServersMu.Lock()
+	httpServerCounter++
+	serverId := httpServerCounter
 
-		// Set status
-		status := 200
-		if statusObj := respRec.Get("status"); statusObj != nil {
-			if statusInt, ok := statusObj.(*Integer); ok {
-				status = int(statusInt.Value)
-			}
-		}
-		w.WriteHeader(status)
+	// Store server
+	httpServers[serverId] = server
+	httpServersMu.Unlock()
 
-		// Write body
-		if bodyObj := respRec.Get("body"); bodyObj != nil {
-			if bodyList, ok := bodyObj.(*List); ok {
-				_, _ = w.Write([]byte(ListToString(bodyList)))
-			} else if bodyBytes, ok := bodyObj.(*Bytes); ok {
-				_, _ = w.Write(bodyBytes.ToSlice())
-			}
+	// Start server in background (non-blocking)
+	go func() {
+		err := serveHttp(server, l)
+		if err != nil && err != http.ErrServerClosed {
+			// Log error but don't fail - server might have been stopped
 		}
-	})
-
-	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", port),
-		Handler: mux,
-	}
-
-	// Get shared listener
-	l, err := getSharedListener(port)
-	if err != nil {
-		return makeFail(StringToList(err.Error()))
-	}
+		// Clean up when server stops
+		httpServersMu.Lock()
+		delete(httpServers, serverId)
+		httpServersMu.Unlock()
+	}()
 
-	// Start server (blocking)
-	err = server.Serve(l)
-	if err != nil && err != http.ErrServerClosed {
-		return makeFail(StringToList(err.Error()))
-	}
+	// Give server a moment to start
+	time.Sleep(10 * time.Millisecond)
 
-	return makeOk(&Nil{})
+	return &Integer{Value: serverId}
 }
 
-// httpServeAsync: (Int, (HttpRequest) -> HttpResponse) -> Int
-// Starts a non-blocking HTTP server and returns server ID
-func builtinHttpServeAsync(e *Evaluator, args ...Object) Object {
+// newHttpServer validates the arguments of httpServe/httpServeAsync and sets up
+// the server and its listener. Bad arguments are returned as an Error, a
+// failure to listen or to load certificates as an error.
+func newHttpServer(e *Evaluator, name string, args []Object) (*http.Server, net.Listener, *Error, error) {
 	if len(args) != 2 {
-		return newError("httpServeAsync expects 2 arguments, got %d", len(args))
+		return nil, nil, newError("%s expects 2 arguments, got %d", name, len(args)), nil
 	}
 
-	portInt, ok := args[0].(*Integer)
-	if !ok {
-		return newError("httpServeAsync expects an integer port, got %s", args[0].Type())
+	cfg, errObj := parseServerConfig(name, args[0])
+	if errObj != nil {
+		return nil, nil, errObj, nil
 	}
 
 	handler := args[1]
 	// Check for tree-walk Function or VM closure
 	if !httpIsCallable(handler) {
-		return newError("httpServeAsync expects a handler function, got %s", args[1].Type())
+		return nil, nil, newError("%s expects a handler function, got %s", name, args[1].Type()), nil
 	}
 
-	port := int(portInt.Value)
+	tlsConf, err := cfg.tlsConfig(e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
 	// Capture handler if CaptureHandler is available
 	if e.CaptureHandler != nil {
@@ -840,7 +778,7 @@ func builtinHttpServeAsync(e *Evaluator, args ...Object) Object {
 		// If Fork is missing, we must ensure we are not in a VM context that requires it.
 		// Clone is safe for pure tree-walk (VMCallHandler is nil), but unsafe if VMCallHandler is present (shared state).
 		if e.VMCallHandler != nil {
-			return newError("httpServeAsync: concurrent execution requires Fork support when VM is active")
+			return nil, nil, newError("%s: concurrent execution requires Fork support when VM is active", name), nil
 		}
 		serverEval = e.Clone()
 	}
@@ -850,13 +788,32 @@ func builtinHttpServeAsync(e *Evaluator, args ...Object) Object {
 		serverEval.GlobalEnv.SetReadOnly()
 	}
 
-	// Create HTTP server with same handler logic as httpServe
 	mux := http.NewServeMux()
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/", httpRequestHandler(serverEval, handler, name, cfg))
+
+	server := &http.Server{
+		Addr:    cfg.addr(),
+		Handler: mux,
+	}
+	cfg.configureHttp(server, tlsConf)
+
+	// Get shared listener
+	l, err := getSharedListener(cfg.addr())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return server, l, nil, nil
+}
+
+// httpRequestHandler calls handler for each request, on a fresh evaluator/VM
+// forked from serverEval
+func httpRequestHandler(serverEval *Evaluator, handler Object, name string, cfg *serverConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		// Panic recovery for robust server
 		defer func() {
 			if rec := recover(); rec != nil {
-				fmt.Printf("[httpServeAsync] Panic in handler: %v\n", rec)
+				// Log panic but don't crash the whole process
+				fmt.Printf("[%s] Panic in handler: %v\n", name, rec)
 				w.WriteHeader(http.StatusInternalServerError)
 			}
 		}()
@@ -878,24 +835,37 @@ func builtinHttpServeAsync(e *Evaluator, args ...Object) Object {
 		defer reqEval.Release()
 
 		// Build HttpRequest object
-		var headers []Object
-		for key, values := range r.Header {
-			for _, val := range values {
-				headers = append(headers, &Tuple{
-					Elements: []Object{StringToList(key), StringToList(val)},
-				})
-			}
+		defer func() { _ = r.Body.Close() }()
+		if cfg.maxBodySize > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, cfg.maxBodySize)
 		}
 
-		bodyBytes, _ := io.ReadAll(r.Body)
-		defer func() { _ = r.Body.Close() }()
+		// Unless the handler streams it, the body is read before the call
+		var bodyBytes []byte
+		var bodyStream *HttpBodyStreamObject
+		if cfg.streamBody {
+			bodyStream = newHttpBodyStream(r.URL.Path, r.Body, nil)
+		} else {
+			var err error
+			bodyBytes, err = io.ReadAll(r.Body)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				_, _ = w.Write([]byte("413 Request Entity Too Large"))
+				return
+			}
+			bodyStream = newHttpBodyStream(r.URL.Path, io.NopCloser(bytes.NewReader(bodyBytes)), nil)
+		}
+		// Uploads spilled to disk by httpParseMultipart live as long as the request
+		defer bodyStream.removeTempFiles()
 
 		request := NewRecord(map[string]Object{
-			"method":  StringToList(r.Method),
-			"path":    StringToList(r.URL.Path),
-			"query":   StringToList(r.URL.RawQuery),
-			"headers": newList(headers),
-			"body":    StringToList(string(bodyBytes)),
+			"method":     StringToList(r.Method),
+			"path":       StringToList(r.URL.Path),
+			"query":      StringToList(r.URL.RawQuery),
+			"headers":    httpHeadersToList(r.Header),
+			"body":       StringToList(string(bodyBytes)),
+			"bodyStream": bodyStream,
 		})
 
 		// Call handler
@@ -949,50 +919,15 @@ func builtinHttpServeAsync(e *Evaluator, args ...Object) Object {
 
 		// Write body
 		if bodyObj := respRec.Get("body"); bodyObj != nil {
-			if bodyList, ok := bodyObj.(*List); ok {
+			if stream, ok := bodyObj.(*httpStreamBody); ok {
+				stream.serve(reqEval, w, r, cfg.writeTimeout, name)
+			} else if bodyList, ok := bodyObj.(*List); ok {
 				_, _ = w.Write([]byte(ListToString(bodyList)))
 			} else if bodyBytes, ok := bodyObj.(*Bytes); ok {
 				_, _ = w.Write(bodyBytes.ToSlice())
 			}
 		}
-	})
-
-	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", port),
-		Handler: mux,
-	}
-
-	// Generate server ID
-	httpServersMu.Lock()
-	httpServerCounter++
-	serverId := httpServerCounter
-
-	// Store server
-	httpServers[serverId] = server
-	httpServersMu.Unlock()
-
-	// Get shared listener
-	l, err := getSharedListener(port)
-	if err != nil {
-		return makeFail(StringToList(err.Error()))
 	}
-
-	// Start server in background (non-blocking)
-	go func() {
-		err := server.Serve(l)
-		if err != nil && err != http.ErrServerClosed {
-			// Log error but don't fail - server might have been stopped
-		}
-		// Clean up when server stops
-		httpServersMu.Lock()
-		delete(httpServers, serverId)
-		httpServersMu.Unlock()
-	}()
-
-	// Give server a moment to start
-	time.Sleep(10 * time.Millisecond)
-
-	return &Integer{Value: serverId}
 }
 
 // httpServerStop: (Int, Int) -> Nil
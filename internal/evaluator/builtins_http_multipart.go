@@ -0,0 +1,298 @@
+package evaluator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"strings"
+)
+
+// defaultMultipartMaxMemory is how much of a form httpParseMultipart keeps in memory
+const defaultMultipartMaxMemory = 10 << 20
+
+// multipartLimits are the options of httpParseMultipart. Files that no longer
+// fit in maxMemory are written to temporary files.
+type multipartLimits struct {
+	maxMemory   int64
+	maxFileSize int64 // 0 means no limit
+}
+
+func parseMultipartLimits(name string, args []Object) (*multipartLimits, *Error) {
+	limits := &multipartLimits{maxMemory: defaultMultipartMaxMemory}
+	if len(args) == 0 {
+		return limits, nil
+	}
+	rec, ok := args[0].(*RecordInstance)
+	if !ok {
+		return nil, newError("%s expects a limits record, got %s", name, args[0].Type())
+	}
+	for field, target := range map[string]*int64{"maxMemory": &limits.maxMemory, "maxFileSize": &limits.maxFileSize} {
+		if val := rec.Get(field); val != nil {
+			size, ok := val.(*Integer)
+			if !ok || size.Value < 0 {
+				return nil, newError("%s: limit %s must be a non-negative Int (bytes)", name, field)
+			}
+			*target = size.Value
+		}
+	}
+	return limits, nil
+}
+
+// multipartBoundary returns the boundary of a multipart/form-data request
+func multipartBoundary(headers *List) (string, error) {
+	contentType := ""
+	for _, h := range headers.ToSlice() {
+		tuple, ok := h.(*Tuple)
+		if !ok || len(tuple.Elements) != 2 {
+			continue
+		}
+		key, ok1 := tuple.Elements[0].(*List)
+		val, ok2 := tuple.Elements[1].(*List)
+		if ok1 && ok2 && strings.EqualFold(ListToString(key), "Content-Type") {
+			contentType = ListToString(val)
+			break
+		}
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" {
+		return "", fmt.Errorf("not a multipart/form-data request")
+	}
+	if params["boundary"] == "" {
+		return "", fmt.Errorf("multipart request without a boundary")
+	}
+	return params["boundary"], nil
+}
+
+// parseMultipart reads the rest of the body as multipart/form-data. It
+// returns the fields as (name, value) tuples and the files as UploadedFile
+// records.
+func (o *HttpBodyStreamObject) parseMultipart(boundary string, limits *multipartLimits) ([]Object, []Object, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return nil, nil, fmt.Errorf("body has already been read")
+	}
+	defer o.closeLocked()
+
+	reader := multipart.NewReader(o.r, boundary)
+	memory := limits.maxMemory
+	var fields, files []Object
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return fields, files, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid multipart body: %v", err)
+		}
+
+		if part.FileName() == "" {
+			// Form fields always stay in memory, within the same budget
+			var value bytes.Buffer
+			n, err := io.CopyN(&value, part, memory+1)
+			if err != nil && err != io.EOF {
+				return nil, nil, fmt.Errorf("invalid multipart body: %v", err)
+			}
+			if n > memory {
+				return nil, nil, fmt.Errorf("multipart fields exceed maxMemory")
+			}
+			memory -= n
+			fields = append(fields, &Tuple{Elements: []Object{
+				StringToList(part.FormName()), StringToList(value.String()),
+			}})
+			continue
+		}
+
+		data, path, size, err := o.receiveFile(part, &memory, limits.maxFileSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		contentType := part.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		files = append(files, NewRecord(map[string]Object{
+			"field":       StringToList(part.FormName()),
+			"filename":    StringToList(part.FileName()),
+			"contentType": StringToList(contentType),
+			"size":        &Integer{Value: size},
+			"data":        data,
+			"path":        path,
+		}))
+	}
+}
+
+// receiveFile keeps a file part in memory while it fits in the budget and
+// moves it to a temporary file otherwise. It returns the content as
+// Some(Bytes) or the path of the file as Some(String). o.mu must be held.
+func (o *HttpBodyStreamObject) receiveFile(part *multipart.Part, memory *int64, maxFileSize int64) (data, path Object, size int64, err error) {
+	tooLarge := fmt.Errorf("file %q exceeds maxFileSize", part.FileName())
+	limit := int64(-1)
+	if maxFileSize > 0 {
+		limit = maxFileSize
+	}
+
+	var buf bytes.Buffer
+	inMemory := *memory
+	if limit >= 0 && limit < inMemory {
+		inMemory = limit
+	}
+	n, err := io.CopyN(&buf, part, inMemory+1)
+	if err != nil && err != io.EOF {
+		return nil, nil, 0, fmt.Errorf("invalid multipart body: %v", err)
+	}
+	if n <= inMemory {
+		*memory -= n
+		return makeSome(bytesFromSlice(buf.Bytes())), makeNone(), n, nil
+	}
+	if limit >= 0 && n > limit {
+		return nil, nil, 0, tooLarge
+	}
+
+	tmp, err := os.CreateTemp("", "funxy-upload-*")
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("cannot store upload: %v", err)
+	}
+	o.tempFiles = append(o.tempFiles, tmp.Name())
+	defer tmp.Close()
+
+	src := io.MultiReader(&buf, part)
+	if limit >= 0 {
+		src = io.LimitReader(src, limit+1)
+	}
+	n, err = io.Copy(tmp, src)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("cannot store upload: %v", err)
+	}
+	if limit >= 0 && n > limit {
+		return nil, nil, 0, tooLarge
+	}
+	return makeNone(), makeSome(StringToList(tmp.Name())), n, nil
+}
+
+// httpParseMultipart: (HttpRequest, { maxMemory?, maxFileSize? }) -> Result<String, MultipartForm>
+// Parses a multipart/form-data request body into fields and files
+func builtinHttpParseMultipart(e *Evaluator, args ...Object) Object {
+	if len(args) < 1 || len(args) > 2 {
+		return newError("httpParseMultipart expects 1 or 2 arguments, got %d", len(args))
+	}
+	req, ok := args[0].(*RecordInstance)
+	if !ok {
+		return newError("httpParseMultipart expects an HttpRequest, got %s", args[0].Type())
+	}
+	headers, ok1 := req.Get("headers").(*List)
+	stream, ok2 := req.Get("bodyStream").(*HttpBodyStreamObject)
+	if !ok1 || !ok2 {
+		return newError("httpParseMultipart expects a request received by httpServe")
+	}
+	limits, errObj := parseMultipartLimits("httpParseMultipart", args[1:])
+	if errObj != nil {
+		return errObj
+	}
+
+	boundary, err := multipartBoundary(headers)
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	fields, files, err := stream.parseMultipart(boundary, limits)
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	return makeOk(NewRecord(map[string]Object{
+		"fields": newList(fields),
+		"files":  newList(files),
+	}))
+}
+
+// httpReadUpload: (UploadedFile) -> Result<String, Bytes>
+// Returns the content of an uploaded file, wherever it is kept
+func builtinHttpReadUpload(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("httpReadUpload expects 1 argument, got %d", len(args))
+	}
+	file, ok := args[0].(*RecordInstance)
+	if !ok {
+		return newError("httpReadUpload expects an UploadedFile, got %s", args[0].Type())
+	}
+	if data, ok := file.Get("data").(*DataInstance); ok && len(data.Fields) == 1 {
+		return makeOk(data.Fields[0])
+	}
+	if path, ok := file.Get("path").(*DataInstance); ok && len(path.Fields) == 1 {
+		content, err := os.ReadFile(ListToString(path.Fields[0].(*List)))
+		if err != nil {
+			return makeFailStr(err.Error())
+		}
+		return makeOk(bytesFromSlice(content))
+	}
+	return newError("httpReadUpload expects an UploadedFile")
+}
+
+// httpMultipart: (List<(String, String)>, List<MultipartFile>) -> (String, Bytes)
+// Builds a multipart/form-data body; returns its Content-Type and the body
+func builtinHttpMultipart(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 {
+		return newError("httpMultipart expects 2 arguments, got %d", len(args))
+	}
+	fields, ok1 := args[0].(*List)
+	files, ok2 := args[1].(*List)
+	if !ok1 || !ok2 {
+		return newError("httpMultipart expects a list of fields and a list of files")
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, f := range fields.ToSlice() {
+		tuple, ok := f.(*Tuple)
+		if !ok || len(tuple.Elements) != 2 {
+			return newError("httpMultipart expects fields as (String, String) tuples")
+		}
+		name, ok1 := tuple.Elements[0].(*List)
+		value, ok2 := tuple.Elements[1].(*List)
+		if !ok1 || !ok2 {
+			return newError("httpMultipart field name and value must be strings")
+		}
+		_ = w.WriteField(ListToString(name), ListToString(value))
+	}
+	for _, f := range files.ToSlice() {
+		rec, ok := f.(*RecordInstance)
+		if !ok {
+			return newError("httpMultipart expects files as MultipartFile records, got %s", f.Type())
+		}
+		text := func(field string) string {
+			if l, ok := rec.Get(field).(*List); ok {
+				return ListToString(l)
+			}
+			return ""
+		}
+		data, ok := rec.Get("data").(*Bytes)
+		if !ok {
+			return newError("httpMultipart: file data must be Bytes")
+		}
+		contentType := text("contentType")
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			multipartEscape(text("field")), multipartEscape(text("filename"))))
+		header.Set("Content-Type", contentType)
+		part, _ := w.CreatePart(header)
+		_, _ = part.Write(data.ToSlice())
+	}
+	_ = w.Close()
+
+	return &Tuple{Elements: []Object{
+		StringToList(w.FormDataContentType()),
+		bytesFromSlice(body.Bytes()),
+	}}
+}
+
+var multipartQuoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func multipartEscape(s string) string {
+	return multipartQuoteEscaper.Replace(s)
+}
@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHttpParseMultipart_RemovesTempFiles(t *testing.T) {
+	spilled := make(chan string, 1)
+	handler := &Builtin{Name: "handler", Fn: func(e *Evaluator, args ...Object) Object {
+		res := builtinHttpParseMultipart(e, args[0], NewRecord(map[string]Object{"maxMemory": &Integer{Value: 0}}))
+		form := res.(*DataInstance).Fields[0].(*RecordInstance)
+		file := form.Get("files").(*List).get(0).(*RecordInstance)
+		path := ListToString(file.Get("path").(*DataInstance).Fields[0].(*List))
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("upload should be on disk during the request: %v", err)
+		}
+		spilled <- path
+		return NewRecord(map[string]Object{"status": &Integer{Value: 200}, "body": StringToList(""), "headers": newList(nil)})
+	}}
+	port := freePort(t)
+	id := builtinHttpServeAsync(New(), &Integer{Value: int64(port)}, handler)
+	defer builtinHttpServerStop(New(), id)
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, _ := w.CreateFormFile("upload", "data.bin")
+	_, _ = part.Write(bytes.Repeat([]byte{0xff, 0x00}, 1000))
+	_ = w.Close()
+	resp, err := http.Post("http://127.0.0.1:"+itoa(port)+"/", w.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	path := <-spilled
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("temporary upload %s should be removed after the request", path)
+	}
+}
+
+func TestHttpServe_StreamBodyLimit(t *testing.T) {
+	handler := &Builtin{Name: "handler", Fn: func(e *Evaluator, args ...Object) Object {
+		stream := args[0].(*RecordInstance).Get("bodyStream")
+		var msg string
+		for {
+			res := builtinHttpReadChunk(e, stream).(*DataInstance)
+			if res.Name == "Fail" {
+				msg = ListToString(res.Fields[0].(*List))
+				break
+			}
+			if res.Fields[0].(*DataInstance).Name == "None" {
+				msg = "complete"
+				break
+			}
+		}
+		return NewRecord(map[string]Object{"status": &Integer{Value: 200}, "body": StringToList(msg), "headers": newList(nil)})
+	}}
+	port := freePort(t)
+	id := builtinHttpServeAsync(New(), serverConfigRecord(map[string]any{"port": port, "streamBody": true, "maxBodySize": 10}), handler)
+	defer builtinHttpServerStop(New(), id)
+
+	for payload, want := range map[string]string{"short": "complete", strings.Repeat("x", 100): "too large"} {
+		resp, err := http.Post("http://127.0.0.1:"+itoa(port)+"/", "text/plain", strings.NewReader(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(got), want) {
+			t.Errorf("body of %d bytes: got %q, want %q", len(payload), got, want)
+		}
+	}
+}
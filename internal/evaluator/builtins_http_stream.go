@@ -0,0 +1,592 @@
+package evaluator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// httpStreamBody is the body of a response built by httpStream: the function
+// that writes it once the status and headers have been sent.
+type httpStreamBody struct {
+	producer Object
+}
+
+func (b *httpStreamBody) Type() ObjectType { return "HttpStreamBody" }
+func (b *httpStreamBody) Inspect() string  { return "<stream>" }
+func (b *httpStreamBody) RuntimeType() typesystem.Type {
+	return typesystem.TCon{Name: "HttpStreamBody"}
+}
+func (b *httpStreamBody) Hash() uint32 {
+	return 0
+}
+
+// serve sends the headers right away and calls the producer with a writer.
+// The response ends when the producer returns.
+func (b *httpStreamBody) serve(e *Evaluator, w http.ResponseWriter, r *http.Request, writeTimeout time.Duration, name string) {
+	writer := &HttpWriterObject{
+		w:            w,
+		rc:           http.NewResponseController(w),
+		ctx:          r.Context(),
+		writeTimeout: writeTimeout,
+	}
+	// The server's write timeout covers the whole response, so it is replaced
+	// by a deadline for each write
+	_ = writer.rc.SetWriteDeadline(time.Time{})
+	_ = writer.rc.Flush()
+
+	result := e.ApplyFunction(b.producer, []Object{writer})
+	writer.close()
+	if err, ok := result.(*Error); ok {
+		fmt.Printf("[%s] Error in stream producer: %s\n", name, err.Message)
+	}
+}
+
+// HttpWriterObject is passed to the producer of a streamed response. Writes go
+// straight to the client and fail once it has disconnected.
+type HttpWriterObject struct {
+	w            http.ResponseWriter
+	rc           *http.ResponseController
+	ctx          context.Context // done when the client disconnects
+	writeTimeout time.Duration
+
+	mu     sync.Mutex
+	closed bool // the producer has returned
+}
+
+func (o *HttpWriterObject) Type() ObjectType { return "HttpWriter" }
+func (o *HttpWriterObject) Inspect() string  { return "HttpWriter" }
+func (o *HttpWriterObject) RuntimeType() typesystem.Type {
+	return typesystem.TCon{Name: "HttpWriter"}
+}
+func (o *HttpWriterObject) Hash() uint32 {
+	return 0
+}
+
+func (o *HttpWriterObject) write(data []byte, flush bool) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return fmt.Errorf("response is finished")
+	}
+	if o.ctx.Err() != nil {
+		return fmt.Errorf("client disconnected")
+	}
+	if o.writeTimeout > 0 {
+		_ = o.rc.SetWriteDeadline(time.Now().Add(o.writeTimeout))
+	}
+	if _, err := o.w.Write(data); err != nil {
+		return fmt.Errorf("write failed: %v", err)
+	}
+	if flush {
+		if err := o.rc.Flush(); err != nil {
+			return fmt.Errorf("flush failed: %v", err)
+		}
+	}
+	return nil
+}
+
+func (o *HttpWriterObject) isClosed() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.closed || o.ctx.Err() != nil
+}
+
+func (o *HttpWriterObject) close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+}
+
+// formatSseEvent renders an event record as a Server-Sent Events frame.
+// Multi-line data is sent as several data: lines.
+func formatSseEvent(rec *RecordInstance) (string, error) {
+	var sb strings.Builder
+	for _, field := range []string{"id", "event"} {
+		val := rec.Get(field)
+		if val == nil {
+			continue
+		}
+		list, ok := val.(*List)
+		if !ok {
+			return "", fmt.Errorf("event field %s must be a String", field)
+		}
+		text := ListToString(list)
+		if strings.ContainsAny(text, "\r\n") {
+			return "", fmt.Errorf("event field %s must not contain line breaks", field)
+		}
+		if text != "" {
+			sb.WriteString(field + ": " + text + "\n")
+		}
+	}
+	if val := rec.Get("retry"); val != nil {
+		retry, ok := val.(*Integer)
+		if !ok {
+			return "", fmt.Errorf("event field retry must be an Int")
+		}
+		if retry.Value > 0 {
+			sb.WriteString("retry: " + strconv.FormatInt(retry.Value, 10) + "\n")
+		}
+	}
+	data, ok := rec.Get("data").(*List)
+	if !ok {
+		return "", fmt.Errorf("event field data must be a String")
+	}
+	text := strings.ReplaceAll(ListToString(data), "\r\n", "\n")
+	for _, line := range strings.Split(text, "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
+
+// HttpBodyStreamObject is the body of a response from httpRequestStream, or of
+// a request on the server, read as it arrives. Iterating over it yields the
+// chunks as Bytes.
+type HttpBodyStreamObject struct {
+	url    string
+	body   io.ReadCloser
+	r      *bufio.Reader
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	lastID    string // the SSE last event ID carries over to later events
+	closed    bool
+	tempFiles []string // uploads spilled to disk, removed with the request
+}
+
+func (o *HttpBodyStreamObject) Type() ObjectType { return "HttpBodyStream" }
+func (o *HttpBodyStreamObject) Inspect() string {
+	return fmt.Sprintf("HttpBodyStream(%s)", o.url)
+}
+func (o *HttpBodyStreamObject) RuntimeType() typesystem.Type {
+	return typesystem.TCon{Name: "HttpBodyStream"}
+}
+func (o *HttpBodyStreamObject) Hash() uint32 {
+	return 0
+}
+
+// Next reads the next chunk for for-in loops
+func (o *HttpBodyStreamObject) Next() Object {
+	chunk, err := o.readChunk()
+	if err != nil {
+		return newError("HTTP stream failed: %s", err.Error())
+	}
+	if chunk == nil {
+		return makeNone()
+	}
+	return makeSome(chunk)
+}
+
+func newHttpBodyStream(url string, body io.ReadCloser, cancel context.CancelFunc) *HttpBodyStreamObject {
+	return &HttpBodyStreamObject{url: url, body: body, r: bufio.NewReader(body), cancel: cancel}
+}
+
+// readChunk returns what has arrived of the body, or nil at the end of it
+func (o *HttpBodyStreamObject) readChunk() (*Bytes, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return nil, nil
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := o.r.Read(buf)
+		if n > 0 {
+			return bytesFromSlice(buf[:n]), nil
+		}
+		if err == io.EOF {
+			o.closeLocked()
+			return nil, nil
+		}
+		if err != nil {
+			o.closeLocked()
+			return nil, err
+		}
+	}
+}
+
+// readEvent parses the next Server-Sent Event, or returns nil at the end of
+// the body. An event cut off by the end of the body is dropped.
+func (o *HttpBodyStreamObject) readEvent() (Object, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return nil, nil
+	}
+	eventType := ""
+	var data strings.Builder
+	hasData := false
+	var retry int64
+	for {
+		line, err := o.r.ReadString('\n')
+		if err == io.EOF {
+			o.closeLocked()
+			return nil, nil
+		}
+		if err != nil {
+			o.closeLocked()
+			return nil, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			// A blank line dispatches the event, unless it has no data
+			if !hasData {
+				eventType, retry = "", 0
+				continue
+			}
+			if eventType == "" {
+				eventType = "message"
+			}
+			return NewRecord(map[string]Object{
+				"event": StringToList(eventType),
+				"data":  StringToList(data.String()),
+				"id":    StringToList(o.lastID),
+				"retry": &Integer{Value: retry},
+			}), nil
+		}
+		if line[0] == ':' {
+			// Comment, often sent to keep the connection open
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				o.lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseInt(value, 10, 64); err == nil && ms >= 0 {
+				retry = ms
+			}
+		}
+	}
+}
+
+func (o *HttpBodyStreamObject) close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closeLocked()
+}
+
+func (o *HttpBodyStreamObject) closeLocked() {
+	if o.closed {
+		return
+	}
+	o.closed = true
+	_ = o.body.Close()
+	if o.cancel != nil {
+		o.cancel()
+	}
+}
+
+// removeTempFiles deletes the files httpParseMultipart spilled uploads to
+func (o *HttpBodyStreamObject) removeTempFiles() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, path := range o.tempFiles {
+		_ = os.Remove(path)
+	}
+	o.tempFiles = nil
+}
+
+// HttpEventStreamObject reads a body as Server-Sent Events. Iterating over it
+// yields SseEvent records.
+type HttpEventStreamObject struct {
+	body *HttpBodyStreamObject
+}
+
+func (o *HttpEventStreamObject) Type() ObjectType { return "HttpEventStream" }
+func (o *HttpEventStreamObject) Inspect() string {
+	return fmt.Sprintf("HttpEventStream(%s)", o.body.url)
+}
+func (o *HttpEventStreamObject) RuntimeType() typesystem.Type {
+	return typesystem.TCon{Name: "HttpEventStream"}
+}
+func (o *HttpEventStreamObject) Hash() uint32 {
+	return 0
+}
+
+// Next reads the next event for for-in loops
+func (o *HttpEventStreamObject) Next() Object {
+	event, err := o.body.readEvent()
+	if err != nil {
+		return newError("HTTP event stream failed: %s", err.Error())
+	}
+	if event == nil {
+		return makeNone()
+	}
+	return makeSome(event)
+}
+
+// httpStream: (Int, List<(String, String)>, (HttpWriter) -> A) -> HttpResponse
+// Builds a response whose body is written by the producer after the handler returns
+func builtinHttpStream(e *Evaluator, args ...Object) Object {
+	if len(args) != 3 {
+		return newError("httpStream expects 3 arguments, got %d", len(args))
+	}
+	status, ok := args[0].(*Integer)
+	if !ok {
+		return newError("httpStream expects an integer status, got %s", args[0].Type())
+	}
+	headers, ok := args[1].(*List)
+	if !ok {
+		return newError("httpStream expects a list of headers, got %s", args[1].Type())
+	}
+	if !httpIsCallable(args[2]) {
+		return newError("httpStream expects a producer function, got %s", args[2].Type())
+	}
+	return NewRecord(map[string]Object{
+		"status":  status,
+		"headers": headers,
+		"body":    &httpStreamBody{producer: args[2]},
+	})
+}
+
+// httpEventStream: ((HttpWriter) -> A, List<(String, String)>) -> HttpResponse
+// httpStream with status 200 and the headers of a Server-Sent Events stream
+func builtinHttpEventStream(e *Evaluator, args ...Object) Object {
+	if len(args) < 1 || len(args) > 2 {
+		return newError("httpEventStream expects 1 or 2 arguments, got %d", len(args))
+	}
+	headers := []Object{
+		&Tuple{Elements: []Object{StringToList("Content-Type"), StringToList("text/event-stream")}},
+		&Tuple{Elements: []Object{StringToList("Cache-Control"), StringToList("no-cache")}},
+		// Keeps reverse proxies such as nginx from buffering the events
+		&Tuple{Elements: []Object{StringToList("X-Accel-Buffering"), StringToList("no")}},
+	}
+	if len(args) == 2 {
+		extra, ok := args[1].(*List)
+		if !ok {
+			return newError("httpEventStream expects a list of headers, got %s", args[1].Type())
+		}
+		headers = append(headers, extra.ToSlice()...)
+	}
+	return builtinHttpStream(e, &Integer{Value: 200}, newList(headers), args[0])
+}
+
+func httpWriterArg(name string, args []Object, count int) (*HttpWriterObject, *Error) {
+	if len(args) != count {
+		return nil, newError("%s expects %d arguments, got %d", name, count, len(args))
+	}
+	w, ok := args[0].(*HttpWriterObject)
+	if !ok {
+		return nil, newError("%s expects an HttpWriter, got %s", name, args[0].Type())
+	}
+	return w, nil
+}
+
+// httpWrite: (HttpWriter, String | Bytes) -> Result<String, Nil>
+func builtinHttpWrite(e *Evaluator, args ...Object) Object {
+	w, errObj := httpWriterArg("httpWrite", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	var data []byte
+	switch chunk := args[1].(type) {
+	case *Bytes:
+		data = chunk.ToSlice()
+	case *List:
+		data = []byte(ListToString(chunk))
+	default:
+		return newError("httpWrite expects String or Bytes, got %s", args[1].Type())
+	}
+	if err := w.write(data, false); err != nil {
+		return makeFailStr(err.Error())
+	}
+	return makeOk(&Nil{})
+}
+
+// httpFlush: (HttpWriter) -> Result<String, Nil>
+// Sends what has been written so far to the client
+func builtinHttpFlush(e *Evaluator, args ...Object) Object {
+	w, errObj := httpWriterArg("httpFlush", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	if err := w.write(nil, true); err != nil {
+		return makeFailStr(err.Error())
+	}
+	return makeOk(&Nil{})
+}
+
+// httpSendEvent: (HttpWriter, { data: String, event?, id?, retry? }) -> Result<String, Nil>
+// Writes one Server-Sent Event and flushes it
+func builtinHttpSendEvent(e *Evaluator, args ...Object) Object {
+	w, errObj := httpWriterArg("httpSendEvent", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	rec, ok := args[1].(*RecordInstance)
+	if !ok {
+		return newError("httpSendEvent expects an event record, got %s", args[1].Type())
+	}
+	frame, err := formatSseEvent(rec)
+	if err != nil {
+		return newError("httpSendEvent: %s", err.Error())
+	}
+	if err := w.write([]byte(frame), true); err != nil {
+		return makeFailStr(err.Error())
+	}
+	return makeOk(&Nil{})
+}
+
+// httpClosed: (HttpWriter) -> Bool
+// True once the client has disconnected
+func builtinHttpClosed(e *Evaluator, args ...Object) Object {
+	w, errObj := httpWriterArg("httpClosed", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	return &Boolean{Value: w.isClosed()}
+}
+
+// httpRequestStream: (String, String, List<(String, String)>, String | Bytes, Int) -> Result<String, HttpStreamResponse>
+// Like httpRequest, but returns as soon as the headers arrive. The timeout
+// only covers waiting for the headers.
+func builtinHttpRequestStream(e *Evaluator, args ...Object) Object {
+	req, errObj := parseHttpRequestArgs("httpRequestStream", args)
+	if errObj != nil {
+		return errObj
+	}
+
+	tr := GetTestRunner()
+	if errMsg, found := tr.FindHttpMockError(req.url); found {
+		return makeFail(StringToList(errMsg))
+	}
+	if mockResp, found := tr.FindHttpMock(req.url); found {
+		return httpMockStreamResponse(req.url, mockResp)
+	}
+	if tr.ShouldBlockHttp(req.url) {
+		return makeFail(StringToList("HTTP request blocked: no mock found for " + req.url))
+	}
+
+	client, httpReq, err := newHttpClientRequest(req.method, req.url, req.headers, req.body, 0)
+	if err != nil {
+		return makeFail(StringToList("failed to create request: " + err.Error()))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var timer *time.Timer
+	if req.timeout > 0 {
+		timer = time.AfterFunc(req.timeout, cancel)
+	}
+	resp, err := client.Do(httpReq.WithContext(ctx))
+	if err == nil && timer != nil && !timer.Stop() {
+		// The timeout fired just as the headers arrived
+		_ = resp.Body.Close()
+		err = fmt.Errorf("timed out waiting for the response headers")
+	}
+	if err != nil {
+		cancel()
+		return makeFail(StringToList("request failed: " + err.Error()))
+	}
+
+	return makeOk(NewRecord(map[string]Object{
+		"status":  &Integer{Value: int64(resp.StatusCode)},
+		"headers": httpHeadersToList(resp.Header),
+		"body":    newHttpBodyStream(req.url, resp.Body, cancel),
+	}))
+}
+
+// httpMockStreamResponse streams the body of a mocked response
+func httpMockStreamResponse(url string, mock Object) Object {
+	rec, ok := mock.(*RecordInstance)
+	if !ok {
+		return makeFailStr("invalid HTTP mock for " + url)
+	}
+	var body string
+	switch b := rec.Get("body").(type) {
+	case *List:
+		body = ListToString(b)
+	case *Bytes:
+		body = string(b.ToSlice())
+	}
+	return makeOk(NewRecord(map[string]Object{
+		"status":  rec.Get("status"),
+		"headers": rec.Get("headers"),
+		"body":    newHttpBodyStream(url, io.NopCloser(strings.NewReader(body)), nil),
+	}))
+}
+
+func httpBodyStreamArg(name string, args []Object) (*HttpBodyStreamObject, *Error) {
+	if len(args) != 1 {
+		return nil, newError("%s expects 1 argument, got %d", name, len(args))
+	}
+	stream, ok := args[0].(*HttpBodyStreamObject)
+	if !ok {
+		return nil, newError("%s expects an HttpBodyStream, got %s", name, args[0].Type())
+	}
+	return stream, nil
+}
+
+// httpReadChunk: (HttpBodyStream) -> Result<String, Option<Bytes>>
+func builtinHttpReadChunk(e *Evaluator, args ...Object) Object {
+	stream, errObj := httpBodyStreamArg("httpReadChunk", args)
+	if errObj != nil {
+		return errObj
+	}
+	chunk, err := stream.readChunk()
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	if chunk == nil {
+		return makeOk(makeNone())
+	}
+	return makeOk(makeSome(chunk))
+}
+
+// httpReadEvent: (HttpBodyStream) -> Result<String, Option<SseEvent>>
+func builtinHttpReadEvent(e *Evaluator, args ...Object) Object {
+	stream, errObj := httpBodyStreamArg("httpReadEvent", args)
+	if errObj != nil {
+		return errObj
+	}
+	event, err := stream.readEvent()
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	if event == nil {
+		return makeOk(makeNone())
+	}
+	return makeOk(makeSome(event))
+}
+
+// httpEvents: (HttpBodyStream) -> HttpEventStream
+// Reads the body as Server-Sent Events in for-in loops
+func builtinHttpEvents(e *Evaluator, args ...Object) Object {
+	stream, errObj := httpBodyStreamArg("httpEvents", args)
+	if errObj != nil {
+		return errObj
+	}
+	return &HttpEventStreamObject{body: stream}
+}
+
+// httpStreamClose: (HttpBodyStream) -> Nil
+// Stops reading a response before its end
+func builtinHttpStreamClose(e *Evaluator, args ...Object) Object {
+	stream, errObj := httpBodyStreamArg("httpStreamClose", args)
+	if errObj != nil {
+		return errObj
+	}
+	stream.close()
+	return &Nil{}
+}
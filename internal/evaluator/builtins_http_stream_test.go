@@ -0,0 +1,157 @@
+package evaluator
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHttpStream_ClientDisconnect(t *testing.T) {
+	written := make(chan int, 1)
+	producer := &Builtin{Name: "producer", Fn: func(e *Evaluator, args ...Object) Object {
+		w := args[0]
+		n := 0
+		for {
+			res := builtinHttpWrite(e, w, StringToList("tick\n"))
+			if data, ok := res.(*DataInstance); !ok || data.Name != "Ok" {
+				break
+			}
+			builtinHttpFlush(e, w)
+			n++
+			time.Sleep(5 * time.Millisecond)
+		}
+		if !builtinHttpClosed(e, w).(*Boolean).Value {
+			t.Error("httpClosed should be true after the client left")
+		}
+		written <- n
+		return &Nil{}
+	}}
+	handler := &Builtin{Name: "handler", Fn: func(e *Evaluator, args ...Object) Object {
+		return builtinHttpStream(e, &Integer{Value: 200}, newList(nil), producer)
+	}}
+	port := freePort(t)
+	id := builtinHttpServeAsync(New(), &Integer{Value: int64(port)}, handler)
+	defer builtinHttpServerStop(New(), id)
+
+	resp, err := http.Get("http://127.0.0.1:" + itoa(port) + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil || line != "tick\n" {
+		t.Fatalf("first line %q, %v", line, err)
+	}
+	resp.Body.Close()
+
+	select {
+	case n := <-written:
+		if n < 1 {
+			t.Errorf("expected at least one write, got %d", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the producer did not notice the disconnect")
+	}
+}
+
+func TestHttpStream_OutlivesWriteTimeout(t *testing.T) {
+	producer := &Builtin{Name: "producer", Fn: func(e *Evaluator, args ...Object) Object {
+		for i := 0; i < 4; i++ {
+			builtinHttpWrite(e, args[0], StringToList("x"))
+			builtinHttpFlush(e, args[0])
+			time.Sleep(60 * time.Millisecond)
+		}
+		return &Nil{}
+	}}
+	handler := &Builtin{Name: "handler", Fn: func(e *Evaluator, args ...Object) Object {
+		return builtinHttpStream(e, &Integer{Value: 200}, newList(nil), producer)
+	}}
+	port := freePort(t)
+	id := builtinHttpServeAsync(New(), serverConfigRecord(map[string]any{"port": port, "host": "127.0.0.1", "writeTimeout": 100}), handler)
+	defer builtinHttpServerStop(New(), id)
+
+	// The stream takes longer than writeTimeout, but every write is quick
+	resp, err := http.Get("http://127.0.0.1:" + itoa(port) + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "xxxx" {
+		t.Errorf("body %q, %v", body, err)
+	}
+}
+
+func TestHttpBodyStream_ReadEvent(t *testing.T) {
+	input := ": keep-alive\r\n" +
+		"retry: 250\r\n" +
+		"data: first\r\n\r\n" +
+		"event: update\n" +
+		"id: 42\n" +
+		"data:no space\n" +
+		"data:  two spaces\n" +
+		"unknown: ignored\n\n" +
+		"event: empty\n\n" +
+		"data: after empty\n\n" +
+		"data: cut off"
+	stream := newHttpBodyStream("test", io.NopCloser(strings.NewReader(input)), nil)
+
+	type event struct{ event, data, id string }
+	var got []event
+	var retries []int64
+	for {
+		obj, err := stream.readEvent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if obj == nil {
+			break
+		}
+		rec := obj.(*RecordInstance)
+		got = append(got, event{
+			ListToString(rec.Get("event").(*List)),
+			ListToString(rec.Get("data").(*List)),
+			ListToString(rec.Get("id").(*List)),
+		})
+		retries = append(retries, rec.Get("retry").(*Integer).Value)
+	}
+	want := []event{
+		{"message", "first", ""},
+		{"update", "no space\n two spaces", "42"},
+		{"message", "after empty", "42"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if retries[0] != 250 || retries[1] != 0 {
+		t.Errorf("retries = %v", retries)
+	}
+}
+
+func TestFormatSseEvent(t *testing.T) {
+	frame, err := formatSseEvent(NewRecord(map[string]Object{
+		"event": StringToList("update"),
+		"id":    StringToList("3"),
+		"retry": &Integer{Value: 100},
+		"data":  StringToList("a\r\nb"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "id: 3\nevent: update\nretry: 100\ndata: a\ndata: b\n\n"; frame != want {
+		t.Errorf("frame = %q, want %q", frame, want)
+	}
+	if _, err := formatSseEvent(NewRecord(map[string]Object{
+		"event": StringToList("bad\nname"),
+		"data":  StringToList("x"),
+	})); err == nil {
+		t.Error("an event name with a line break should be rejected")
+	}
+}
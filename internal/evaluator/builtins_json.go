@@ -187,6 +187,9 @@ func RegisterJsonBuiltins(env *Environment) {
 	for name, fn := range builtins {
 		env.Set(name, fn)
 	}
+
+	// ToJson trait method
+	env.Set("toJson", toJsonClassMethod())
 }
 
 // JsonBuiltins returns built-in functions for lib/json virtual package
@@ -229,6 +232,7 @@ func builtinEncodeBytes(e *Evaluator, args ...Object) Object {
 }
 
 func builtinDecode(e *Evaluator, args ...Object) Object {
+	target, args := decodeTarget(args)
 	if len(args) != 1 {
 		return newError("decode requires exactly 1 argument")
 	}
@@ -247,8 +251,16 @@ func builtinDecode(e *Evaluator, args ...Object) Object {
 		return makeFailStr(parseErr.Error())
 	}
 
-	// Infer types from JSON
-	result, err := inferFromJson(data, e)
+	// Check against the expected type, or infer types from JSON
+	var result Object
+	var err error
+	if target != nil {
+		result, err = decodeTyped(e, data, target, func(v interface{}) (Object, error) {
+			return inferFromJson(v, e)
+		})
+	} else {
+		result, err = inferFromJson(data, e)
+	}
 	if err != nil {
 		return makeFailStr(err.Error())
 	}
@@ -371,8 +383,8 @@ func goToJsonADT(data interface{}) Object {
 		return &DataInstance{Name: "JArr", Fields: []Object{arr}, TypeName: "Json"}
 	case map[string]interface{}:
 		pairs := make([]Object, 0, len(v))
-		for key, val := range v {
-			pair := &Tuple{Elements: []Object{StringToList(key), goToJsonADT(val)}}
+		for _, key := range sortedKeys(v) {
+			pair := &Tuple{Elements: []Object{StringToList(key), goToJsonADT(v[key])}}
 			pairs = append(pairs, pair)
 		}
 		pairList := newList(pairs)
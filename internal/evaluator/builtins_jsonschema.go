@@ -0,0 +1,738 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/funvibe/funxy/internal/config"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// JSON Schema generation and validation for lib/jsonschema.
+//
+// jsonSchemaOf receives its type expanded by the analyzer, like the typed
+// decoders (see builtins_decode.go), and describes the JSON that jsonEncode
+// writes and jsonDecode accepts for it. Named types go to "$defs", so
+// recursive types are described by reference. jsonSchemaValidate checks a
+// Json value against a JSON Schema (draft 2020-12) document.
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// maxSchemaRefDepth bounds $ref chains that do not descend into the value
+const maxSchemaRefDepth = 64
+
+// RegisterJsonSchemaBuiltins registers lib/jsonschema types and functions
+func RegisterJsonSchemaBuiltins(env *Environment) {
+	env.Set("SchemaViolation", &TypeObject{TypeVal: typesystem.TCon{Name: "SchemaViolation"}})
+
+	for name, fn := range JsonSchemaBuiltins() {
+		env.Set(name, fn)
+	}
+}
+
+// JsonSchemaBuiltins returns built-in functions for lib/jsonschema
+func JsonSchemaBuiltins() map[string]*Builtin {
+	return map[string]*Builtin{
+		"jsonSchemaOf":       {Name: "jsonSchemaOf", Fn: builtinJsonSchemaOf},
+		"jsonSchemaEncode":   {Name: "jsonSchemaEncode", Fn: builtinJsonSchemaEncode},
+		"jsonSchemaValidate": {Name: "jsonSchemaValidate", Fn: builtinJsonSchemaValidate},
+	}
+}
+
+// jsonSchemaOf(type) -> Json
+func builtinJsonSchemaOf(e *Evaluator, args ...Object) Object {
+	target, _ := decodeTarget(args)
+	if target == nil {
+		return newError("jsonSchemaOf expects a type")
+	}
+	return goToJsonADT(generateJsonSchema(target))
+}
+
+// jsonSchemaEncode(schema: Json) -> String
+func builtinJsonSchemaEncode(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("jsonSchemaEncode expects 1 argument, got %d", len(args))
+	}
+	schema, err := jsonADTToGo(args[0])
+	if err != nil {
+		return newError("jsonSchemaEncode: %s", err.Error())
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return newError("jsonSchemaEncode: %s", err.Error())
+	}
+	return StringToList(string(data))
+}
+
+// jsonSchemaValidate(schema: Json, value: Json) -> List<SchemaViolation>
+func builtinJsonSchemaValidate(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 {
+		return newError("jsonSchemaValidate expects 2 arguments, got %d", len(args))
+	}
+	schema, err := jsonADTToGo(args[0])
+	if err != nil {
+		return newError("jsonSchemaValidate: schema: %s", err.Error())
+	}
+	value, err := jsonADTToGo(args[1])
+	if err != nil {
+		return newError("jsonSchemaValidate: value: %s", err.Error())
+	}
+
+	v := &schemaValidator{root: schema, patterns: make(map[string]*regexp.Regexp)}
+	v.validate(schema, value, "", 0)
+	violations := make([]Object, len(v.violations))
+	for i, violation := range v.violations {
+		rec := NewRecord(map[string]Object{
+			"path":    StringToList(violation.path),
+			"message": StringToList(violation.message),
+		})
+		rec.TypeName = "SchemaViolation"
+		violations[i] = rec
+	}
+	return newList(violations)
+}
+
+// jsonADTToGo converts a Json value to the Go values of encoding/json
+func jsonADTToGo(obj Object) (interface{}, error) {
+	di, ok := obj.(*DataInstance)
+	if !ok {
+		return nil, fmt.Errorf("expected Json, got %s", obj.Type())
+	}
+	switch di.Name {
+	case "JNull":
+		return nil, nil
+	case "JBool":
+		if b, ok := di.Fields[0].(*Boolean); ok {
+			return b.Value, nil
+		}
+	case "JNum":
+		switch n := di.Fields[0].(type) {
+		case *Float:
+			return n.Value, nil
+		case *Integer:
+			return float64(n.Value), nil
+		}
+	case "JStr":
+		if l, ok := di.Fields[0].(*List); ok {
+			return ListToString(l), nil
+		}
+	case "JArr":
+		if l, ok := di.Fields[0].(*List); ok {
+			items := make([]interface{}, 0, l.len())
+			for _, el := range l.ToSlice() {
+				item, err := jsonADTToGo(el)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			return items, nil
+		}
+	case "JObj":
+		if l, ok := di.Fields[0].(*List); ok {
+			obj := make(map[string]interface{}, l.len())
+			for _, el := range l.ToSlice() {
+				pair, ok := el.(*Tuple)
+				if !ok || len(pair.Elements) != 2 {
+					return nil, fmt.Errorf("JObj expects (String, Json) pairs")
+				}
+				key, ok := pair.Elements[0].(*List)
+				if !ok {
+					return nil, fmt.Errorf("JObj expects (String, Json) pairs")
+				}
+				value, err := jsonADTToGo(pair.Elements[1])
+				if err != nil {
+					return nil, err
+				}
+				obj[ListToString(key)] = value
+			}
+			return obj, nil
+		}
+	}
+	return nil, fmt.Errorf("expected Json, got %s", di.Name)
+}
+
+// schemaGenerator builds the schema of an expanded type
+type schemaGenerator struct {
+	types typeDefs
+	// defs are the schemas of the named types met so far
+	defs map[string]interface{}
+}
+
+// generateJsonSchema returns the schema document for t
+func generateJsonSchema(t typesystem.Type) map[string]interface{} {
+	g := &schemaGenerator{types: make(typeDefs), defs: make(map[string]interface{})}
+	g.types.collect(t)
+	doc := map[string]interface{}{"$schema": jsonSchemaDialect}
+	for key, value := range g.schema(t) {
+		doc[key] = value
+	}
+	if len(g.defs) > 0 {
+		doc["$defs"] = g.defs
+	}
+	return doc
+}
+
+func (g *schemaGenerator) schema(t typesystem.Type) map[string]interface{} {
+	if name, def, ok := g.types.lookup(t); ok {
+		return g.named(t, name, def)
+	}
+
+	switch ty := t.(type) {
+	case typesystem.TCon:
+		return scalarSchema(ty.Name)
+	case typesystem.TApp:
+		con, ok := ty.Constructor.(typesystem.TCon)
+		if !ok {
+			break
+		}
+		switch {
+		case con.Name == config.ListTypeName && len(ty.Args) == 1:
+			if elem, ok := ty.Args[0].(typesystem.TCon); ok && elem.Name == "Char" {
+				return scalarSchema("String")
+			}
+			return map[string]interface{}{"type": "array", "items": g.schema(ty.Args[0])}
+		case con.Name == config.OptionTypeName && len(ty.Args) == 1:
+			return map[string]interface{}{"anyOf": []interface{}{g.schema(ty.Args[0]), map[string]interface{}{"type": "null"}}}
+		case con.Name == config.MapTypeName && len(ty.Args) == 2:
+			return map[string]interface{}{"type": "object", "additionalProperties": g.schema(ty.Args[1])}
+		}
+	case typesystem.TRecord:
+		return g.record(ty, false)
+	case typesystem.TTuple:
+		return g.tuple(ty.Elements)
+	case typesystem.TUnion:
+		members := make([]interface{}, len(ty.Types))
+		for i, member := range ty.Types {
+			members[i] = g.schema(member)
+		}
+		return map[string]interface{}{"anyOf": members}
+	}
+	return map[string]interface{}{}
+}
+
+// named returns a reference to the schema of the named type t, adding the
+// schema to $defs the first time t is met
+func (g *schemaGenerator) named(t typesystem.Type, name string, def typesystem.Type) map[string]interface{} {
+	key := name
+	if app, ok := t.(typesystem.TApp); ok {
+		key = decodeTypeKey(app)
+	}
+	ref := map[string]interface{}{"$ref": "#/$defs/" + url.PathEscape(escapeJSONPointer(key))}
+	if _, ok := g.defs[key]; ok {
+		return ref
+	}
+	// Reserve the entry first, so recursive occurrences become references
+	g.defs[key] = map[string]interface{}{}
+
+	var schema map[string]interface{}
+	switch ty := def.(type) {
+	case typesystem.TUnion:
+		if isConstructorUnion(ty) {
+			schema = g.adt(ty)
+		} else {
+			schema = g.schema(ty)
+		}
+	case typesystem.TRecord:
+		schema = g.record(ty, false)
+	case typesystem.TApp:
+		if isDefaultMarker(ty) {
+			schema = g.record(ty.Args[0].(typesystem.TRecord), true)
+		} else {
+			schema = g.schema(ty)
+		}
+	default:
+		schema = g.schema(ty)
+	}
+	schema["title"] = key
+	g.defs[key] = schema
+	return ref
+}
+
+// record describes a record as jsonDecode reads it: every field is required
+// unless it is an Option, is nullable (T?) or the record has a Default instance
+func (g *schemaGenerator) record(t typesystem.TRecord, hasDefault bool) map[string]interface{} {
+	properties := make(map[string]interface{}, len(t.Fields))
+	required := []interface{}{}
+	for _, name := range sortedFieldNames(t) {
+		field := t.Fields[name]
+		properties[name] = g.schema(field)
+		if !hasDefault && !isOptionType(field) && !isNullableType(field) {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (g *schemaGenerator) tuple(elements []typesystem.Type) map[string]interface{} {
+	items := make([]interface{}, len(elements))
+	for i, el := range elements {
+		items[i] = g.schema(el)
+	}
+	n := float64(len(items))
+	schema := map[string]interface{}{"type": "array", "minItems": n, "maxItems": n}
+	if len(items) > 0 {
+		schema["prefixItems"] = items
+	}
+	return schema
+}
+
+// adt describes the {"_type": ..., "_fields": [...]} objects of jsonEncode
+func (g *schemaGenerator) adt(ctors typesystem.TUnion) map[string]interface{} {
+	variants := make([]interface{}, len(ctors.Types))
+	for i, member := range ctors.Types {
+		ctor := member.(typesystem.TFunc)
+		name := ctor.ReturnType.(typesystem.TCon).Name
+		properties := map[string]interface{}{
+			"_type":   map[string]interface{}{"const": name},
+			"_fields": g.tuple(ctor.Params),
+		}
+		required := []interface{}{"_type"}
+		if len(ctor.Params) > 0 {
+			required = append(required, "_fields")
+		}
+		variants[i] = map[string]interface{}{"type": "object", "properties": properties, "required": required}
+	}
+	return map[string]interface{}{"oneOf": variants}
+}
+
+// scalarSchema describes the built-in types; others may be anything
+func scalarSchema(name string) map[string]interface{} {
+	switch name {
+	case "Int":
+		return map[string]interface{}{"type": "integer"}
+	case "Float":
+		return map[string]interface{}{"type": "number"}
+	case "Bool":
+		return map[string]interface{}{"type": "boolean"}
+	case "String":
+		return map[string]interface{}{"type": "string"}
+	case "Char":
+		return map[string]interface{}{"type": "string", "minLength": 1.0, "maxLength": 1.0}
+	case "Nil":
+		return map[string]interface{}{"type": "null"}
+	case "BigInt":
+		// jsonEncode writes BigInt as a string of digits
+		return map[string]interface{}{"type": []interface{}{"integer", "string"}, "pattern": "^-?[0-9]+$"}
+	case "Rational":
+		return map[string]interface{}{"type": "string"}
+	}
+	return map[string]interface{}{}
+}
+
+func sortedFieldNames(t typesystem.TRecord) []string {
+	names := make([]string, 0, len(t.Fields))
+	for name := range t.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isOptionType(t typesystem.Type) bool {
+	if app, ok := t.(typesystem.TApp); ok {
+		if con, ok := app.Constructor.(typesystem.TCon); ok {
+			return con.Name == config.OptionTypeName
+		}
+	}
+	return false
+}
+
+// isNullableType reports whether t is a union with Nil, such as Int?
+func isNullableType(t typesystem.Type) bool {
+	if union, ok := t.(typesystem.TUnion); ok {
+		for _, member := range union.Types {
+			if con, ok := member.(typesystem.TCon); ok && con.Name == "Nil" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// schemaViolation is a value at path that does not satisfy its schema
+type schemaViolation struct {
+	path    string
+	message string
+}
+
+// schemaValidator checks values against a schema document
+type schemaValidator struct {
+	root       interface{}
+	patterns   map[string]*regexp.Regexp
+	violations []schemaViolation
+}
+
+func (v *schemaValidator) fail(path, format string, args ...interface{}) {
+	v.violations = append(v.violations, schemaViolation{path: path, message: fmt.Sprintf(format, args...)})
+}
+
+// matches reports whether data satisfies schema, without recording violations
+func (v *schemaValidator) matches(schema, data interface{}, path string, depth int) bool {
+	saved := v.violations
+	v.violations = nil
+	v.validate(schema, data, path, depth)
+	ok := len(v.violations) == 0
+	v.violations = saved
+	return ok
+}
+
+// validate records the violations of schema by the value data at path.
+// depth counts the $ref hops taken at path.
+func (v *schemaValidator) validate(schema, data interface{}, path string, depth int) {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.fail(path, "no value is allowed here")
+		}
+		return
+	case map[string]interface{}:
+		v.validateObject(s, data, path, depth)
+	default:
+		v.fail(path, "invalid schema: expected an object or a boolean, got %s", schemaKind(schema))
+	}
+}
+
+func (v *schemaValidator) validateObject(s map[string]interface{}, data interface{}, path string, depth int) {
+	if ref, ok := s["$ref"].(string); ok {
+		if depth >= maxSchemaRefDepth {
+			v.fail(path, "invalid schema: $ref %s nests too deeply", ref)
+		} else if target, ok := v.resolve(ref); ok {
+			v.validate(target, data, path, depth+1)
+		} else {
+			v.fail(path, "invalid schema: cannot resolve $ref %s", ref)
+		}
+	}
+
+	if t, ok := s["type"]; ok && !schemaTypeMatches(t, data) {
+		v.fail(path, "expected %s, got %s", schemaTypeName(t), schemaKind(data))
+		return
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if reflect.DeepEqual(allowed, data) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "value is not one of %s", compactJSON(enum))
+		}
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, data) {
+		v.fail(path, "expected %s", compactJSON(c))
+	}
+
+	switch d := data.(type) {
+	case float64:
+		v.validateNumber(s, d, path)
+	case string:
+		v.validateString(s, d, path)
+	case []interface{}:
+		v.validateArray(s, d, path, depth)
+	case map[string]interface{}:
+		v.validateProperties(s, d, path, depth)
+	}
+
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			v.validate(sub, data, path, depth)
+		}
+	}
+	if alternatives, ok := s["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range alternatives {
+			if v.matches(sub, data, path, depth) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "value does not match any schema of anyOf")
+		}
+	}
+	if one, ok := s["oneOf"].([]interface{}); ok {
+		matched := 0
+		for _, sub := range one {
+			if v.matches(sub, data, path, depth) {
+				matched++
+			}
+		}
+		if matched == 0 {
+			v.fail(path, "value does not match any schema of oneOf")
+		} else if matched > 1 {
+			v.fail(path, "value matches %d schemas of oneOf, expected exactly one", matched)
+		}
+	}
+	if not, ok := s["not"]; ok && v.matches(not, data, path, depth) {
+		v.fail(path, "value must not match the schema of not")
+	}
+	if cond, ok := s["if"]; ok {
+		if v.matches(cond, data, path, depth) {
+			if then, ok := s["then"]; ok {
+				v.validate(then, data, path, depth)
+			}
+		} else if els, ok := s["else"]; ok {
+			v.validate(els, data, path, depth)
+		}
+	}
+}
+
+func (v *schemaValidator) validateNumber(s map[string]interface{}, n float64, path string) {
+	if min, ok := s["minimum"].(float64); ok && n < min {
+		v.fail(path, "%s is less than the minimum %s", compactJSON(n), compactJSON(min))
+	}
+	if max, ok := s["maximum"].(float64); ok && n > max {
+		v.fail(path, "%s is greater than the maximum %s", compactJSON(n), compactJSON(max))
+	}
+	if min, ok := s["exclusiveMinimum"].(float64); ok && n <= min {
+		v.fail(path, "%s must be greater than %s", compactJSON(n), compactJSON(min))
+	}
+	if max, ok := s["exclusiveMaximum"].(float64); ok && n >= max {
+		v.fail(path, "%s must be less than %s", compactJSON(n), compactJSON(max))
+	}
+	if m, ok := s["multipleOf"].(float64); ok && m > 0 {
+		if q := n / m; q != math.Trunc(q) {
+			v.fail(path, "%s is not a multiple of %s", compactJSON(n), compactJSON(m))
+		}
+	}
+}
+
+func (v *schemaValidator) validateString(s map[string]interface{}, str, path string) {
+	length := utf8.RuneCountInString(str)
+	if min, ok := s["minLength"].(float64); ok && float64(length) < min {
+		v.fail(path, "string is shorter than %s characters", compactJSON(min))
+	}
+	if max, ok := s["maxLength"].(float64); ok && float64(length) > max {
+		v.fail(path, "string is longer than %s characters", compactJSON(max))
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := v.pattern(pattern)
+		if err != nil {
+			v.fail(path, "invalid schema: pattern %s: %s", pattern, err.Error())
+		} else if !re.MatchString(str) {
+			v.fail(path, "string does not match the pattern %s", pattern)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(s map[string]interface{}, items []interface{}, path string, depth int) {
+	if min, ok := s["minItems"].(float64); ok && float64(len(items)) < min {
+		v.fail(path, "expected at least %s items, got %d", compactJSON(min), len(items))
+	}
+	if max, ok := s["maxItems"].(float64); ok && float64(len(items)) > max {
+		v.fail(path, "expected at most %s items, got %d", compactJSON(max), len(items))
+	}
+
+	prefix, _ := s["prefixItems"].([]interface{})
+	rest, hasRest := s["items"]
+	// The array form of items is the prefixItems of earlier drafts
+	if tuple, ok := rest.([]interface{}); ok {
+		prefix, rest, hasRest = tuple, s["additionalItems"], s["additionalItems"] != nil
+	}
+	for i, item := range items {
+		itemPath := fmt.Sprintf("%s/%d", path, i)
+		if i < len(prefix) {
+			v.validate(prefix[i], item, itemPath, 0)
+		} else if hasRest {
+			v.validate(rest, item, itemPath, 0)
+		}
+	}
+
+	if contains, ok := s["contains"]; ok {
+		found := false
+		for i, item := range items {
+			if v.matches(contains, item, fmt.Sprintf("%s/%d", path, i), 0) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "no item matches the schema of contains")
+		}
+	}
+	if unique, ok := s["uniqueItems"].(bool); ok && unique {
+		for i := range items {
+			for j := i + 1; j < len(items); j++ {
+				if reflect.DeepEqual(items[i], items[j]) {
+					v.fail(path, "items %d and %d are equal", i, j)
+				}
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) validateProperties(s map[string]interface{}, obj map[string]interface{}, path string, depth int) {
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, present := obj[key]; !present {
+					v.fail(path+"/"+escapeJSONPointer(key), "missing required property")
+				}
+			}
+		}
+	}
+	if min, ok := s["minProperties"].(float64); ok && float64(len(obj)) < min {
+		v.fail(path, "expected at least %s properties, got %d", compactJSON(min), len(obj))
+	}
+	if max, ok := s["maxProperties"].(float64); ok && float64(len(obj)) > max {
+		v.fail(path, "expected at most %s properties, got %d", compactJSON(max), len(obj))
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	patterns, _ := s["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := s["additionalProperties"]
+	names, hasNames := s["propertyNames"]
+	for _, key := range sortedKeys(obj) {
+		keyPath := path + "/" + escapeJSONPointer(key)
+		if hasNames && !v.matches(names, key, keyPath, 0) {
+			v.fail(keyPath, "property name does not match the schema of propertyNames")
+		}
+		known := false
+		if sub, ok := properties[key]; ok {
+			known = true
+			v.validate(sub, obj[key], keyPath, 0)
+		}
+		for _, pattern := range sortedKeys(patterns) {
+			re, err := v.pattern(pattern)
+			if err != nil {
+				v.fail(keyPath, "invalid schema: pattern %s: %s", pattern, err.Error())
+				continue
+			}
+			if re.MatchString(key) {
+				known = true
+				v.validate(patterns[pattern], obj[key], keyPath, 0)
+			}
+		}
+		if !known && hasAdditional {
+			if allowed, ok := additional.(bool); ok && !allowed {
+				v.fail(keyPath, "unexpected property")
+			} else {
+				v.validate(additional, obj[key], keyPath, 0)
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) pattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns[pattern] = re
+	return re, nil
+}
+
+// resolve follows a $ref within the schema document
+func (v *schemaValidator) resolve(ref string) (interface{}, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+	target := v.root
+	pointer := strings.TrimPrefix(ref, "#")
+	if pointer == "" {
+		return target, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token, err := url.PathUnescape(token)
+		if err != nil {
+			return nil, false
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch node := target.(type) {
+		case map[string]interface{}:
+			next, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			target = next
+		case []interface{}:
+			var i int
+			if _, err := fmt.Sscanf(token, "%d", &i); err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			target = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return target, true
+}
+
+// schemaTypeMatches checks data against the "type" keyword t
+func schemaTypeMatches(t, data interface{}) bool {
+	if types, ok := t.([]interface{}); ok {
+		for _, member := range types {
+			if schemaTypeMatches(member, data) {
+				return true
+			}
+		}
+		return false
+	}
+	name, _ := t.(string)
+	kind := schemaKind(data)
+	switch name {
+	case "integer":
+		n, ok := data.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		return kind == "number"
+	}
+	return kind == name
+}
+
+func schemaTypeName(t interface{}) string {
+	if types, ok := t.([]interface{}); ok {
+		names := make([]string, len(types))
+		for i, member := range types {
+			names[i] = fmt.Sprintf("%v", member)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprintf("%v", t)
+}
+
+// schemaKind names the JSON type of a value as JSON Schema does
+func schemaKind(data interface{}) string {
+	switch data.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", data)
+}
+
+func compactJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
@@ -0,0 +1,76 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestSchemaValidator(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   []string
+	}{
+		{"boolean schemas", `{"properties": {"a": true, "b": false}}`, `{"a": 1, "b": 2}`, []string{"/b: no value is allowed here"}},
+		{"type lists", `{"type": ["integer", "null"]}`, `"x"`, []string{": expected integer or null, got string"}},
+		{"local refs", `{"$defs": {"n": {"type": "integer"}}, "items": {"$ref": "#/$defs/n"}}`, `[1, 2.5]`, []string{"/1: expected integer, got number"}},
+		{"escaped refs", `{"$defs": {"Box<Int>": {"type": "integer"}}, "$ref": "#/$defs/Box%3CInt%3E"}`, `true`, []string{": expected integer, got boolean"}},
+		{"unresolved refs", `{"$ref": "#/$defs/missing"}`, `1`, []string{": invalid schema: cannot resolve $ref #/$defs/missing"}},
+		{"ref cycles", `{"$ref": "#"}`, `1`, []string{": invalid schema: $ref # nests too deeply"}},
+		{"oneOf", `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, `1`, []string{": value matches 2 schemas of oneOf, expected exactly one"}},
+		{"anyOf", `{"anyOf": [{"type": "string"}, {"type": "null"}]}`, `1`, []string{": value does not match any schema of anyOf"}},
+		{"allOf", `{"allOf": [{"minimum": 2}, {"maximum": 0}]}`, `1`, []string{": 1 is less than the minimum 2", ": 1 is greater than the maximum 0"}},
+		{"not", `{"not": {"const": 1}}`, `1`, []string{": value must not match the schema of not"}},
+		{"if then else", `{"if": {"type": "string"}, "then": {"minLength": 2}, "else": {"type": "null"}}`, `"a"`, []string{": string is shorter than 2 characters"}},
+		{"prefixItems", `{"prefixItems": [{"type": "string"}], "items": {"type": "integer"}}`, `["a", "b"]`, []string{"/1: expected integer, got string"}},
+		{"tuple items", `{"items": [{"type": "string"}], "additionalItems": false}`, `["a", 1]`, []string{"/1: no value is allowed here"}},
+		{"array bounds", `{"minItems": 2, "uniqueItems": true, "contains": {"type": "string"}}`, `[1]`, []string{": expected at least 2 items, got 1", ": no item matches the schema of contains"}},
+		{"uniqueItems", `{"uniqueItems": true}`, `[{"a": 1}, {"a": 1}]`, []string{": items 0 and 1 are equal"}},
+		{"string length in characters", `{"maxLength": 2}`, `"日本"`, nil},
+		{"exclusive bounds", `{"exclusiveMinimum": 1, "exclusiveMaximum": 2}`, `2`, []string{": 2 must be less than 2"}},
+		{"propertyNames", `{"propertyNames": {"pattern": "^[a-z]+$"}}`, `{"A": 1}`, []string{"/A: property name does not match the schema of propertyNames"}},
+		{"escaped paths", `{"required": ["a/b"]}`, `{}`, []string{"/a~1b: missing required property"}},
+		{"invalid patterns", `{"pattern": "("}`, `"x"`, []string{": invalid schema: pattern (: error parsing regexp: missing closing ): `(`"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema, value interface{}
+			if err := json.Unmarshal([]byte(tt.schema), &schema); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			v := &schemaValidator{root: schema, patterns: make(map[string]*regexp.Regexp)}
+			v.validate(schema, value, "", 0)
+			var got []string
+			for _, violation := range v.violations {
+				got = append(got, violation.path+": "+violation.message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJsonADTRoundTrip(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(`{"a": [1, "x", null, true], "b": {}}`), &data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := jsonADTToGo(goToJsonADT(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, data) {
+		t.Errorf("got %v, want %v", got, data)
+	}
+	if _, err := jsonADTToGo(&Integer{Value: 1}); err == nil {
+		t.Error("converting a non-Json value succeeded")
+	}
+}
@@ -0,0 +1,240 @@
+package evaluator
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// serverConfig holds the listening options of httpServe and wsServe. Both
+// take either a port or a record with these fields, all optional but port:
+//
+//	{ port: Int, host: String,
+//	  certFile: String, keyFile: String, clientCAFile: String, clientAuth: String,
+//	  readTimeout: Int, writeTimeout: Int, idleTimeout: Int, http2: Bool,
+//	  maxBodySize: Int, streamBody: Bool }
+//
+// Timeouts are in milliseconds, maxBodySize in bytes. http2, maxBodySize and
+// streamBody only apply to HTTP servers. Certificate files are looked up in the
+// resources embedded with `funxy build --embed` before the file system.
+// grpcServer takes the certificate fields only.
+type serverConfig struct {
+	host         string
+	port         int
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	clientAuth   tls.ClientAuthType
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	idleTimeout  time.Duration
+	http2        *bool // nil keeps Go's default: HTTP/2 over TLS only
+	maxBodySize  int64 // 0 means no limit
+	streamBody   bool  // leave request bodies to be read by the handler
+}
+
+// parseServerConfig reads the port or config record passed to a server builtin
+func parseServerConfig(name string, arg Object) (*serverConfig, *Error) {
+	switch a := arg.(type) {
+	case *Integer:
+		return &serverConfig{port: int(a.Value)}, nil
+	case *RecordInstance:
+		return parseServerConfigRecord(name, a)
+	default:
+		return nil, newError("%s expects a port or a config record, got %s", name, arg.Type())
+	}
+}
+
+func parseServerConfigRecord(name string, rec *RecordInstance) (*serverConfig, *Error) {
+	cfg := &serverConfig{}
+	port, ok := rec.Get("port").(*Integer)
+	if !ok {
+		return nil, newError("%s: config field port must be an Int", name)
+	}
+	cfg.port = int(port.Value)
+
+	if val := rec.Get("host"); val != nil {
+		list, ok := val.(*List)
+		if !ok {
+			return nil, newError("%s: config field host must be a String", name)
+		}
+		cfg.host = ListToString(list)
+	}
+	if err := cfg.parseTLSFields(name, rec); err != nil {
+		return nil, err
+	}
+
+	timeouts := []struct {
+		field  string
+		target *time.Duration
+	}{
+		{"readTimeout", &cfg.readTimeout},
+		{"writeTimeout", &cfg.writeTimeout},
+		{"idleTimeout", &cfg.idleTimeout},
+	}
+	for _, t := range timeouts {
+		if val := rec.Get(t.field); val != nil {
+			ms, ok := val.(*Integer)
+			if !ok || ms.Value < 0 {
+				return nil, newError("%s: config field %s must be a non-negative Int (milliseconds)", name, t.field)
+			}
+			*t.target = time.Duration(ms.Value) * time.Millisecond
+		}
+	}
+
+	if val := rec.Get("http2"); val != nil {
+		b, ok := val.(*Boolean)
+		if !ok {
+			return nil, newError("%s: config field http2 must be a Bool", name)
+		}
+		cfg.http2 = &b.Value
+	}
+	if val := rec.Get("maxBodySize"); val != nil {
+		size, ok := val.(*Integer)
+		if !ok || size.Value < 0 {
+			return nil, newError("%s: config field maxBodySize must be a non-negative Int (bytes)", name)
+		}
+		cfg.maxBodySize = size.Value
+	}
+	if val := rec.Get("streamBody"); val != nil {
+		b, ok := val.(*Boolean)
+		if !ok {
+			return nil, newError("%s: config field streamBody must be a Bool", name)
+		}
+		cfg.streamBody = b.Value
+	}
+	return cfg, nil
+}
+
+// parseTLSFields reads certFile, keyFile, clientCAFile and clientAuth
+func (cfg *serverConfig) parseTLSFields(name string, rec *RecordInstance) *Error {
+	textFields := []struct {
+		field  string
+		target *string
+	}{
+		{"certFile", &cfg.certFile},
+		{"keyFile", &cfg.keyFile},
+		{"clientCAFile", &cfg.clientCAFile},
+	}
+	for _, s := range textFields {
+		if val := rec.Get(s.field); val != nil {
+			list, ok := val.(*List)
+			if !ok {
+				return newError("%s: config field %s must be a String", name, s.field)
+			}
+			*s.target = ListToString(list)
+		}
+	}
+
+	if (cfg.certFile == "") != (cfg.keyFile == "") {
+		return newError("%s: config fields certFile and keyFile must be given together", name)
+	}
+	if cfg.clientCAFile != "" && cfg.certFile == "" {
+		return newError("%s: config field clientCAFile requires certFile and keyFile", name)
+	}
+	cfg.clientAuth = tls.NoClientCert
+	if cfg.clientCAFile != "" {
+		cfg.clientAuth = tls.RequireAndVerifyClientCert
+	}
+	if val := rec.Get("clientAuth"); val != nil {
+		list, ok := val.(*List)
+		if !ok {
+			return newError("%s: config field clientAuth must be a String", name)
+		}
+		switch mode := ListToString(list); mode {
+		case "require":
+		case "optional":
+			cfg.clientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return newError("%s: config field clientAuth must be \"require\" or \"optional\", got %q", name, mode)
+		}
+		if cfg.clientCAFile == "" {
+			return newError("%s: config field clientAuth requires clientCAFile", name)
+		}
+	}
+	return nil
+}
+
+func (c *serverConfig) addr() string {
+	return net.JoinHostPort(c.host, strconv.Itoa(c.port))
+}
+
+// tlsConfig loads the certificates, or returns nil when serving plain text
+func (c *serverConfig) tlsConfig(e *Evaluator) (*tls.Config, error) {
+	if c.certFile == "" {
+		return nil, nil
+	}
+	certPEM, err := readServerFile(e, c.certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := readServerFile(e, c.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate or key: %v", err)
+	}
+	conf := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		ClientAuth:   c.clientAuth,
+	}
+	if c.clientCAFile != "" {
+		caPEM, err := readServerFile(e, c.clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", c.clientCAFile)
+		}
+		conf.ClientCAs = pool
+	}
+	return conf, nil
+}
+
+// configureHttp applies the timeouts, TLS and protocol options to server
+func (c *serverConfig) configureHttp(server *http.Server, tlsConf *tls.Config) {
+	server.ReadTimeout = c.readTimeout
+	server.WriteTimeout = c.writeTimeout
+	server.IdleTimeout = c.idleTimeout
+	server.TLSConfig = tlsConf
+	if c.http2 != nil {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		if tlsConf != nil {
+			protocols.SetHTTP2(*c.http2)
+		} else {
+			// Without TLS, HTTP/2 is spoken to clients that start with it (h2c)
+			protocols.SetUnencryptedHTTP2(*c.http2)
+		}
+		server.Protocols = protocols
+	}
+}
+
+// serveHttp serves on l, over TLS when the server has a TLS config
+func serveHttp(server *http.Server, l net.Listener) error {
+	if server.TLSConfig != nil {
+		return server.ServeTLS(l, "", "")
+	}
+	return server.Serve(l)
+}
+
+// readServerFile reads a certificate or key, preferring embedded resources
+func readServerFile(e *Evaluator, path string) ([]byte, error) {
+	if data, found := lookupEmbed(e.EmbeddedResources, path); found {
+		return data, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %v", path, err)
+	}
+	return data, nil
+}
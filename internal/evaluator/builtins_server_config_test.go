@@ -0,0 +1,334 @@
+package evaluator
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPKI is a CA with a server certificate for 127.0.0.1 and a client certificate.
+type testPKI struct {
+	pool           *x509.CertPool
+	caFile         string
+	certFile       string
+	keyFile        string
+	clientCert     tls.Certificate
+	clientCertFile string
+	clientKeyFile  string
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) ([]byte, []byte) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "localhost"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+
+	pki := &testPKI{
+		pool:           x509.NewCertPool(),
+		caFile:         filepath.Join(dir, "ca.pem"),
+		certFile:       filepath.Join(dir, "server.pem"),
+		keyFile:        filepath.Join(dir, "server.key"),
+		clientCertFile: filepath.Join(dir, "client.pem"),
+		clientKeyFile:  filepath.Join(dir, "client.key"),
+	}
+	pki.pool.AddCert(ca)
+	certPEM, keyPEM := issue(2, x509.ExtKeyUsageServerAuth)
+	clientPEM, clientKeyPEM := issue(3, x509.ExtKeyUsageClientAuth)
+	if pki.clientCert, err = tls.X509KeyPair(clientPEM, clientKeyPEM); err != nil {
+		t.Fatal(err)
+	}
+	for path, data := range map[string][]byte{
+		pki.caFile:         pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		pki.certFile:       certPEM,
+		pki.keyFile:        keyPEM,
+		pki.clientCertFile: clientPEM,
+		pki.clientKeyFile:  clientKeyPEM,
+	} {
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return pki
+}
+
+// freePort returns a port that was free a moment ago.
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func serverConfigRecord(fields map[string]any) *RecordInstance {
+	objects := map[string]Object{}
+	for name, val := range fields {
+		switch v := val.(type) {
+		case int:
+			objects[name] = &Integer{Value: int64(v)}
+		case string:
+			objects[name] = StringToList(v)
+		case bool:
+			objects[name] = &Boolean{Value: v}
+		}
+	}
+	return NewRecord(objects)
+}
+
+var echoPathHandler = &Builtin{Name: "handler", Fn: func(e *Evaluator, args ...Object) Object {
+	req := args[0].(*RecordInstance)
+	return NewRecord(map[string]Object{
+		"status":  &Integer{Value: 200},
+		"body":    StringToList("path " + ListToString(req.Get("path").(*List))),
+		"headers": newList(nil),
+	})
+}}
+
+func startHttpServer(t *testing.T, config Object) {
+	t.Helper()
+	id := builtinHttpServeAsync(New(), config, echoPathHandler)
+	if _, ok := id.(*Integer); !ok {
+		t.Fatalf("httpServeAsync returned %s", id.Inspect())
+	}
+	t.Cleanup(func() { builtinHttpServerStop(New(), id) })
+}
+
+func TestHttpServeTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	port := freePort(t)
+	startHttpServer(t, serverConfigRecord(map[string]any{
+		"port": port, "host": "127.0.0.1", "certFile": pki.certFile, "keyFile": pki.keyFile,
+	}))
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pki.pool},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get("https://127.0.0.1:" + itoa(port) + "/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "path /hello" {
+		t.Errorf("body = %q", body)
+	}
+	if resp.ProtoMajor != 2 {
+		t.Errorf("expected HTTP/2 over TLS by default, got %s", resp.Proto)
+	}
+
+	// Only 127.0.0.1 is bound
+	if conn, err := net.DialTimeout("tcp", "[::1]:"+itoa(port), time.Second); err == nil {
+		conn.Close()
+		t.Error("server should not listen on ::1")
+	}
+}
+
+func TestHttpServeHTTP2Disabled(t *testing.T) {
+	pki := newTestPKI(t)
+	port := freePort(t)
+	startHttpServer(t, serverConfigRecord(map[string]any{
+		"port": port, "certFile": pki.certFile, "keyFile": pki.keyFile, "http2": false,
+	}))
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pki.pool},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get("https://127.0.0.1:" + itoa(port) + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 1 {
+		t.Errorf("expected HTTP/1.1, got %s", resp.Proto)
+	}
+}
+
+func TestHttpServeClientCertificates(t *testing.T) {
+	pki := newTestPKI(t)
+	port := freePort(t)
+	startHttpServer(t, serverConfigRecord(map[string]any{
+		"port": port, "certFile": pki.certFile, "keyFile": pki.keyFile, "clientCAFile": pki.caFile,
+	}))
+	get := func(certs []tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pki.pool, Certificates: certs},
+		}}
+		resp, err := client.Get("https://127.0.0.1:" + itoa(port) + "/")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	if err := get(nil); err == nil {
+		t.Error("request without a client certificate should fail")
+	}
+	if err := get([]tls.Certificate{pki.clientCert}); err != nil {
+		t.Errorf("request with a client certificate failed: %v", err)
+	}
+}
+
+func TestHttpServeTimeouts(t *testing.T) {
+	port := freePort(t)
+	startHttpServer(t, serverConfigRecord(map[string]any{"port": port, "host": "127.0.0.1", "readTimeout": 100}))
+
+	conn, err := net.Dial("tcp", "127.0.0.1:"+itoa(port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// An unfinished request is cut off after the read timeout
+	_, _ = conn.Write([]byte("GET / HTTP/1.1\r\nHost: x\r\n"))
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	buf := make([]byte, 1)
+	if _, err := conn.Read(buf); err == nil || isTimeout(err) {
+		t.Errorf("expected the server to close the connection, got %v", err)
+	}
+}
+
+func TestServeConfigErrors(t *testing.T) {
+	e := New()
+	tests := []struct {
+		config Object
+		want   string
+	}{
+		{serverConfigRecord(map[string]any{"host": "127.0.0.1"}), "port must be an Int"},
+		{serverConfigRecord(map[string]any{"port": 1, "certFile": "a.pem"}), "must be given together"},
+		{serverConfigRecord(map[string]any{"port": 1, "readTimeout": -1}), "non-negative"},
+		{serverConfigRecord(map[string]any{"port": 1, "certFile": "a", "keyFile": "b", "clientCAFile": "c", "clientAuth": "maybe"}), "clientAuth"},
+	}
+	for _, tt := range tests {
+		res := builtinHttpServe(e, tt.config, echoPathHandler)
+		if err, ok := res.(*Error); !ok || !strings.Contains(err.Message, tt.want) {
+			t.Errorf("expected an error containing %q, got %s", tt.want, res.Inspect())
+		}
+	}
+	res := builtinHttpServe(e, serverConfigRecord(map[string]any{"port": 1, "certFile": "missing.pem", "keyFile": "missing.key"}), echoPathHandler)
+	if data, ok := res.(*DataInstance); !ok || data.Name != "Fail" {
+		t.Errorf("expected Fail for a missing certificate, got %s", res.Inspect())
+	}
+	if res := builtinWsServe(e, serverConfigRecord(map[string]any{"port": 1, "http2": true}), echoPathHandler); !strings.Contains(res.Inspect(), "http2") {
+		t.Errorf("wsServe should reject http2, got %s", res.Inspect())
+	}
+}
+
+func TestHttpServeEmbeddedCertificates(t *testing.T) {
+	pki := newTestPKI(t)
+	certPEM, _ := os.ReadFile(pki.certFile)
+	keyPEM, _ := os.ReadFile(pki.keyFile)
+	e := New()
+	e.EmbeddedResources = map[string][]byte{"certs/server.pem": certPEM, "certs/server.key": keyPEM}
+	port := freePort(t)
+	id := builtinHttpServeAsync(e, serverConfigRecord(map[string]any{
+		"port": port, "certFile": "./certs/server.pem", "keyFile": "certs/server.key",
+	}), echoPathHandler)
+	if _, ok := id.(*Integer); !ok {
+		t.Fatalf("httpServeAsync returned %s", id.Inspect())
+	}
+	defer builtinHttpServerStop(e, id)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pki.pool}}}
+	resp, err := client.Get("https://127.0.0.1:" + itoa(port) + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestWsServeTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	port := freePort(t)
+	handler := &Builtin{Name: "handler", Fn: func(e *Evaluator, args ...Object) Object {
+		return StringToList("echo " + ListToString(args[1].(*List)))
+	}}
+	res := builtinWsServeAsync(New(), serverConfigRecord(map[string]any{
+		"port": port, "host": "127.0.0.1", "certFile": pki.certFile, "keyFile": pki.keyFile, "idleTimeout": 200,
+	}), handler)
+	id, ok := res.(*DataInstance)
+	if !ok || id.Name != "Ok" {
+		t.Fatalf("wsServeAsync returned %s", res.Inspect())
+	}
+	defer builtinWsServerStop(New(), id.Fields[0])
+
+	addr := "127.0.0.1:" + itoa(port)
+	conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: pki.pool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws, err := wsClientHandshake(conn, &url.URL{Scheme: "wss", Host: addr, Path: "/"}, 2*time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.conn.Close()
+	if err := wsSendFrame(ws, wsOpText, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := wsReadMessage(ws); err != nil || msg != "echo hi" {
+		t.Fatalf("got %q, %v", msg, err)
+	}
+
+	// The server drops the connection after the idle timeout
+	_ = ws.conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	if _, err := bufio.NewReader(ws.conn).ReadByte(); err == nil || isTimeout(err) {
+		t.Errorf("expected the idle connection to be closed, got %v", err)
+	}
+}
+
+func itoa(n int) string {
+	return big.NewInt(int64(n)).String()
+}
+
+func isTimeout(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
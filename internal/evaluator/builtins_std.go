@@ -50,7 +50,7 @@ func registerShowTrait(e *Evaluator, env *Environment) {
 		"BigInt", "Rational", "Function",
 		"Tuple", "Task",
 		// Add concrete types that were missing in analyzer/builtins.go but good to have
-		"Uuid", "Reader", "Identity", "State", "Writer", "OptionT", "ResultT",
+		"Uuid", "Zone", "Reader", "Identity", "State", "Writer", "OptionT", "ResultT",
 	}
 
 	// Also register for specific DataInstance types if needed,
@@ -63,7 +63,7 @@ func listToGoString(obj Object) (string, bool) {
 
 // IsStringList checks if a list contains only Char elements (is a string)
 func IsStringList(l *List) bool {
-	if l.ElementType == "Char" {
+	if l.ElementType == "Char" || l.str != nil {
 		return true
 	}
 	if l.Len() == 0 {
@@ -78,11 +78,7 @@ func IsStringList(l *List) bool {
 }
 
 func goStringToList(s string) *List {
-	elements := make([]Object, 0, len(s))
-	for _, r := range s {
-		elements = append(elements, &Char{Value: int64(r)})
-	}
-	return newListWithType(elements, "Char")
+	return StringToList(s)
 }
 
 func goStringsToList(strs []string) *List {
@@ -20,6 +20,20 @@ func SysBuiltins() map[string]*Builtin {
 		"sysExePath":   {Fn: builtinSysExePath, Name: "sysExePath"},
 		"sysCPUCount":  {Fn: builtinSysCPUCount, Name: "sysCPUCount"},
 		"sysScriptDir": {Fn: builtinSysScriptDir, Name: "sysScriptDir"},
+		// Processes
+		"sysSpawn":       {Fn: builtinSysSpawn, Name: "sysSpawn"},
+		"sysPipeline":    {Fn: builtinSysPipeline, Name: "sysPipeline"},
+		"sysReadLine":    {Fn: builtinSysReadLine, Name: "sysReadLine"},
+		"sysReadErrLine": {Fn: builtinSysReadErrLine, Name: "sysReadErrLine"},
+		"sysRead":        {Fn: builtinSysRead, Name: "sysRead"},
+		"sysReadErr":     {Fn: builtinSysReadErr, Name: "sysReadErr"},
+		"sysWrite":       {Fn: builtinSysWrite, Name: "sysWrite"},
+		"sysCloseStdin":  {Fn: builtinSysCloseStdin, Name: "sysCloseStdin"},
+		"sysWait":        {Fn: builtinSysWait, Name: "sysWait"},
+		"sysWaitTimeout": {Fn: builtinSysWaitTimeout, Name: "sysWaitTimeout"},
+		"sysKill":        {Fn: builtinSysKill, Name: "sysKill"},
+		"sysSignal":      {Fn: builtinSysSignal, Name: "sysSignal"},
+		"sysPid":         {Fn: builtinSysPid, Name: "sysPid"},
 	}
 }
 
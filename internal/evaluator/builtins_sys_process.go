@@ -0,0 +1,674 @@
+package evaluator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// ProcessObject is a running command or pipeline started by sysSpawn or
+// sysPipeline. Its output is collected as it is produced, so reading stdout
+// never blocks on a full stderr pipe and the other way round.
+type ProcessObject struct {
+	cmds   []*exec.Cmd
+	stdin  *os.File // nil unless stdin is "pipe"
+	stdout *processOutput
+	stderr *processOutput
+
+	stdinMu sync.Mutex
+	copiers sync.WaitGroup // goroutines copying the OS pipes into stdout and stderr
+	outputs []*os.File     // read ends of those pipes
+	done    chan struct{}  // closed once every command has exited
+	code    int
+	waitErr error
+}
+
+func (p *ProcessObject) Type() ObjectType { return "Process" }
+func (p *ProcessObject) Inspect() string {
+	return fmt.Sprintf("Process(%d)", p.cmds[0].Process.Pid)
+}
+func (p *ProcessObject) RuntimeType() typesystem.Type {
+	return typesystem.TCon{Name: "Process"}
+}
+func (p *ProcessObject) Hash() uint32 {
+	return 0
+}
+
+// Next reads the next line of stdout for for-in loops
+func (p *ProcessObject) Next() Object {
+	line, ok := p.stdout.readLine()
+	if !ok {
+		return makeNone()
+	}
+	return makeSome(stringToList(line))
+}
+
+// processOutputLimit is how much unread output a process may have buffered
+// before its writes block, the same way they would on a full OS pipe.
+const processOutputLimit = 1 << 20
+
+// processDrainDelay is how long output is still collected once the commands
+// have exited. A background child that inherited stdout or stderr may keep
+// the pipe open for good; what it writes after this is dropped.
+const processDrainDelay = time.Second
+
+// processOutput buffers what a command writes to stdout or stderr until it is
+// read. Writes block while processOutputLimit bytes are unread, so a chatty
+// command that nobody reads from is paused instead of growing memory. It is
+// closed when the command exits.
+type processOutput struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	buf      bytes.Buffer
+	closed   bool
+	released bool // writes no longer block, see release
+}
+
+func newProcessOutput() *processOutput {
+	o := &processOutput{}
+	o.cond = sync.NewCond(&o.mu)
+	return o
+}
+
+func (o *processOutput) Write(data []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	written := 0
+	for written < len(data) {
+		for !o.released && o.buf.Len() >= processOutputLimit {
+			o.cond.Wait()
+		}
+		chunk := data[written:]
+		if !o.released {
+			chunk = chunk[:min(len(chunk), processOutputLimit-o.buf.Len())]
+		}
+		o.buf.Write(chunk)
+		written += len(chunk)
+		o.cond.Broadcast()
+	}
+	return written, nil
+}
+
+// release stops writes from blocking. It is used once the commands have
+// exited, however they were stopped: whatever is still in flight from the OS
+// pipe is bounded, and the copying goroutine has to finish before the process
+// counts as done.
+func (o *processOutput) release() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.released = true
+	o.cond.Broadcast()
+}
+
+func (o *processOutput) close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+	o.cond.Broadcast()
+}
+
+// readLine waits for a complete line, or the rest of the output once the
+// command has exited. A line longer than processOutputLimit is returned in
+// pieces, since the writer cannot add more until some of it is read. It
+// returns false when there is nothing left to read.
+func (o *processOutput) readLine() (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for !o.closed && o.buf.Len() < processOutputLimit && bytes.IndexByte(o.buf.Bytes(), '\n') < 0 {
+		o.cond.Wait()
+	}
+	if o.buf.Len() == 0 {
+		return "", false
+	}
+	line, err := o.buf.ReadString('\n')
+	o.cond.Broadcast()
+	if err == nil {
+		line = line[:len(line)-1]
+		if len(line) > 0 && line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+	}
+	return line, true
+}
+
+// read waits for output and returns at most max bytes of it. It returns false
+// when there is nothing left to read.
+func (o *processOutput) read(max int) ([]byte, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for !o.closed && o.buf.Len() == 0 {
+		o.cond.Wait()
+	}
+	if o.buf.Len() == 0 {
+		return nil, false
+	}
+	data := make([]byte, min(max, o.buf.Len()))
+	n, _ := o.buf.Read(data)
+	o.cond.Broadcast()
+	return data[:n], true
+}
+
+// processOptions are the options of sysSpawn and sysPipeline
+type processOptions struct {
+	dir      string
+	env      []string // nil means inherit
+	stdin    string
+	stdout   string
+	stderr   string
+	hasStdin bool
+}
+
+// parseProcessOptions reads the optional fields of an options record:
+// cwd, env (Map<String, String>), clearEnv, stdin, stdout and stderr.
+func parseProcessOptions(name string, args []Object) (*processOptions, *Error) {
+	opts := &processOptions{stdin: "pipe", stdout: "pipe", stderr: "pipe"}
+	if len(args) == 0 {
+		return opts, nil
+	}
+	rec, ok := args[0].(*RecordInstance)
+	if !ok {
+		return nil, newError("%s expects an options record, got %s", name, args[0].Type())
+	}
+
+	stringField := func(field string) (string, bool, *Error) {
+		val := rec.Get(field)
+		if val == nil {
+			return "", false, nil
+		}
+		list, ok := val.(*List)
+		if !ok {
+			return "", false, newError("%s: option %s must be a String", name, field)
+		}
+		return listToString(list), true, nil
+	}
+
+	if dir, ok, err := stringField("cwd"); err != nil {
+		return nil, err
+	} else if ok {
+		opts.dir = dir
+	}
+
+	clearEnv := false
+	if val := rec.Get("clearEnv"); val != nil {
+		b, ok := val.(*Boolean)
+		if !ok {
+			return nil, newError("%s: option clearEnv must be a Bool", name)
+		}
+		clearEnv = b.Value
+	}
+	if val := rec.Get("env"); val != nil || clearEnv {
+		if !clearEnv {
+			opts.env = os.Environ()
+		} else {
+			opts.env = []string{}
+		}
+		if val != nil {
+			m, ok := val.(*Map)
+			if !ok {
+				return nil, newError("%s: option env must be a Map<String, String>", name)
+			}
+			for _, item := range m.Items() {
+				opts.env = append(opts.env, listToString(item.Key)+"="+listToString(item.Value))
+			}
+		}
+	}
+
+	modes := []struct {
+		field   string
+		target  *string
+		allowed map[string]bool
+	}{
+		{"stdin", &opts.stdin, map[string]bool{"pipe": true, "inherit": true, "null": true}},
+		{"stdout", &opts.stdout, map[string]bool{"pipe": true, "inherit": true, "null": true}},
+		{"stderr", &opts.stderr, map[string]bool{"pipe": true, "inherit": true, "null": true, "stdout": true}},
+	}
+	for _, m := range modes {
+		mode, ok, err := stringField(m.field)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if !m.allowed[mode] {
+			return nil, newError("%s: unknown %s mode %q", name, m.field, mode)
+		}
+		*m.target = mode
+	}
+	return opts, nil
+}
+
+// parseCommand reads a command name and its List<String> arguments
+func parseCommand(name string, cmdObj, argsObj Object) (*exec.Cmd, *Error) {
+	cmdList, ok := cmdObj.(*List)
+	if !ok {
+		return nil, newError("%s expects a string command, got %s", name, cmdObj.Type())
+	}
+	argsList, ok := argsObj.(*List)
+	if !ok {
+		return nil, newError("%s expects a list of string arguments, got %s", name, argsObj.Type())
+	}
+	cmdArgs := make([]string, argsList.len())
+	for i, arg := range argsList.ToSlice() {
+		argList, ok := arg.(*List)
+		if !ok {
+			return nil, newError("%s argument %d is not a string", name, i)
+		}
+		cmdArgs[i] = listToString(argList)
+	}
+	return exec.Command(listToString(cmdList), cmdArgs...), nil
+}
+
+// startProcess connects the commands into a pipeline, starts them and
+// collects their exit status in the background.
+func startProcess(cmds []*exec.Cmd, opts *processOptions) (*ProcessObject, error) {
+	p := &ProcessObject{
+		cmds:   cmds,
+		stdout: newProcessOutput(),
+		stderr: newProcessOutput(),
+		done:   make(chan struct{}),
+	}
+
+	for _, cmd := range cmds {
+		cmd.Dir = opts.dir
+		cmd.Env = opts.env
+	}
+
+	// The commands write to OS pipes that are copied into the outputs here,
+	// rather than handing exec a Writer: exec would only finish its own
+	// copying inside Wait, and a full output would then keep Wait from ever
+	// returning. Stdin is an OS pipe too, so that it stays open until
+	// sysCloseStdin instead of being closed by Wait when the command exits.
+	var pipeEnds []*os.File
+	closePipeEnds := func() {
+		for _, f := range pipeEnds {
+			f.Close()
+		}
+		if p.stdin != nil {
+			p.stdin.Close()
+		}
+	}
+	outputPipes := map[*processOutput]*os.File{}
+	outputPipe := func(out *processOutput) (*os.File, error) {
+		if w, ok := outputPipes[out]; ok {
+			return w, nil
+		}
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		outputPipes[out] = w
+		pipeEnds = append(pipeEnds, w)
+		p.outputs = append(p.outputs, r)
+		p.copiers.Add(1)
+		go func() {
+			defer p.copiers.Done()
+			_, _ = io.Copy(out, r)
+			r.Close()
+		}()
+		return w, nil
+	}
+
+	first, last := cmds[0], cmds[len(cmds)-1]
+	switch opts.stdin {
+	case "pipe":
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		first.Stdin = r
+		pipeEnds = append(pipeEnds, r)
+		p.stdin = w
+	case "inherit":
+		first.Stdin = os.Stdin
+	}
+	switch opts.stdout {
+	case "pipe":
+		w, err := outputPipe(p.stdout)
+		if err != nil {
+			closePipeEnds()
+			return nil, err
+		}
+		last.Stdout = w
+	case "inherit":
+		last.Stdout = os.Stdout
+	}
+	for _, cmd := range cmds {
+		var err error
+		switch opts.stderr {
+		case "pipe":
+			cmd.Stderr, err = outputPipe(p.stderr)
+		case "inherit":
+			cmd.Stderr = os.Stderr
+		case "stdout":
+			cmd.Stderr, err = outputPipe(p.stdout)
+		}
+		if err != nil {
+			closePipeEnds()
+			return nil, err
+		}
+	}
+
+	// Each command writes to the next one's stdin
+	for i := 0; i < len(cmds)-1; i++ {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closePipeEnds()
+			return nil, err
+		}
+		cmds[i].Stdout = w
+		cmds[i+1].Stdin = r
+		pipeEnds = append(pipeEnds, r, w)
+	}
+
+	for i, cmd := range cmds {
+		if err := cmd.Start(); err != nil {
+			for _, started := range cmds[:i] {
+				_ = started.Process.Kill()
+				_ = started.Wait()
+			}
+			closePipeEnds()
+			return nil, err
+		}
+	}
+	// The commands have their own copies now
+	for _, f := range pipeEnds {
+		f.Close()
+	}
+
+	go p.wait()
+	return p, nil
+}
+
+// wait waits for every command. The exit code is the one of the last command
+// that failed, so a failure anywhere in a pipeline is not hidden.
+func (p *ProcessObject) wait() {
+	for _, cmd := range p.cmds {
+		err := cmd.Wait()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			p.code = exitErr.ExitCode()
+		} else if err != nil {
+			p.waitErr = err
+		}
+	}
+	// Nobody may read what is left, so the copiers must not block on it
+	p.stdout.release()
+	p.stderr.release()
+	drained := make(chan struct{})
+	go func() {
+		p.copiers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(processDrainDelay):
+		for _, r := range p.outputs {
+			r.Close()
+		}
+		<-drained
+	}
+	p.stdout.close()
+	p.stderr.close()
+	close(p.done)
+}
+
+// exitResult returns the outcome of a process that has exited
+func (p *ProcessObject) exitResult() Object {
+	if p.waitErr != nil {
+		return makeFailStr(p.waitErr.Error())
+	}
+	return makeOk(&Integer{Value: int64(p.code)})
+}
+
+// sysSpawn: (String, List<String>, opts) -> Result<String, Process>
+func builtinSysSpawn(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 && len(args) != 3 {
+		return newError("sysSpawn expects 2 or 3 arguments, got %d", len(args))
+	}
+	cmd, errObj := parseCommand("sysSpawn", args[0], args[1])
+	if errObj != nil {
+		return errObj
+	}
+	opts, errObj := parseProcessOptions("sysSpawn", args[2:])
+	if errObj != nil {
+		return errObj
+	}
+
+	p, err := startProcess([]*exec.Cmd{cmd}, opts)
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	return makeOk(p)
+}
+
+// sysPipeline: (List<(String, List<String>)>, opts) -> Result<String, Process>
+func builtinSysPipeline(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("sysPipeline expects 1 or 2 arguments, got %d", len(args))
+	}
+	list, ok := args[0].(*List)
+	if !ok || list.len() == 0 {
+		return newError("sysPipeline expects a non-empty list of (command, args) tuples")
+	}
+
+	var cmds []*exec.Cmd
+	for _, item := range list.ToSlice() {
+		tuple, ok := item.(*Tuple)
+		if !ok || len(tuple.Elements) != 2 {
+			return newError("sysPipeline expects a non-empty list of (command, args) tuples")
+		}
+		cmd, errObj := parseCommand("sysPipeline", tuple.Elements[0], tuple.Elements[1])
+		if errObj != nil {
+			return errObj
+		}
+		cmds = append(cmds, cmd)
+	}
+	opts, errObj := parseProcessOptions("sysPipeline", args[1:])
+	if errObj != nil {
+		return errObj
+	}
+
+	p, err := startProcess(cmds, opts)
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	return makeOk(p)
+}
+
+func processArg(name string, args []Object, count int) (*ProcessObject, *Error) {
+	if len(args) != count {
+		return nil, newError("%s expects %d arguments, got %d", name, count, len(args))
+	}
+	p, ok := args[0].(*ProcessObject)
+	if !ok {
+		return nil, newError("%s expects a Process, got %s", name, args[0].Type())
+	}
+	return p, nil
+}
+
+// sysReadLine: (Process) -> Option<String>
+func builtinSysReadLine(e *Evaluator, args ...Object) Object {
+	p, errObj := processArg("sysReadLine", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	return p.Next()
+}
+
+// sysReadErrLine: (Process) -> Option<String>
+func builtinSysReadErrLine(e *Evaluator, args ...Object) Object {
+	p, errObj := processArg("sysReadErrLine", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	line, ok := p.stderr.readLine()
+	if !ok {
+		return makeNone()
+	}
+	return makeSome(stringToList(line))
+}
+
+func readProcessOutput(name string, args []Object, stderr bool) Object {
+	p, errObj := processArg(name, args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	max, ok := args[1].(*Integer)
+	if !ok || max.Value <= 0 {
+		return newError("%s expects a positive Int size", name)
+	}
+	out := p.stdout
+	if stderr {
+		out = p.stderr
+	}
+	data, ok := out.read(int(max.Value))
+	if !ok {
+		return makeNone()
+	}
+	return makeSome(BytesFromSlice(data))
+}
+
+// sysRead: (Process, Int) -> Option<Bytes>
+func builtinSysRead(e *Evaluator, args ...Object) Object {
+	return readProcessOutput("sysRead", args, false)
+}
+
+// sysReadErr: (Process, Int) -> Option<Bytes>
+func builtinSysReadErr(e *Evaluator, args ...Object) Object {
+	return readProcessOutput("sysReadErr", args, true)
+}
+
+// sysWrite: (Process, String | Bytes) -> Result<String, Nil>
+func builtinSysWrite(e *Evaluator, args ...Object) Object {
+	p, errObj := processArg("sysWrite", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	var data []byte
+	switch v := args[1].(type) {
+	case *Bytes:
+		data = v.data
+	case *List:
+		data = []byte(listToString(v))
+	default:
+		return newError("sysWrite expects a String or Bytes, got %s", args[1].Type())
+	}
+
+	p.stdinMu.Lock()
+	defer p.stdinMu.Unlock()
+	if p.stdin == nil {
+		return makeFailStr("stdin is not a pipe")
+	}
+	if _, err := p.stdin.Write(data); err != nil {
+		return makeFailStr(err.Error())
+	}
+	return makeOk(&Nil{})
+}
+
+// sysCloseStdin: (Process) -> Result<String, Nil>
+func builtinSysCloseStdin(e *Evaluator, args ...Object) Object {
+	p, errObj := processArg("sysCloseStdin", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+
+	p.stdinMu.Lock()
+	defer p.stdinMu.Unlock()
+	if p.stdin == nil {
+		return makeFailStr("stdin is not a pipe")
+	}
+	err := p.stdin.Close()
+	p.stdin = nil
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	return makeOk(&Nil{})
+}
+
+// sysWait: (Process) -> Result<String, Int>
+func builtinSysWait(e *Evaluator, args ...Object) Object {
+	p, errObj := processArg("sysWait", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	<-p.done
+	return p.exitResult()
+}
+
+// sysWaitTimeout: (Process, Int) -> Result<String, Option<Int>>
+func builtinSysWaitTimeout(e *Evaluator, args ...Object) Object {
+	p, errObj := processArg("sysWaitTimeout", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	timeoutMs, ok := args[1].(*Integer)
+	if !ok {
+		return newError("sysWaitTimeout: timeoutMs must be an Int")
+	}
+
+	select {
+	case <-p.done:
+	case <-time.After(time.Duration(timeoutMs.Value) * time.Millisecond):
+		return makeOk(makeNone())
+	}
+	if p.waitErr != nil {
+		return makeFailStr(p.waitErr.Error())
+	}
+	return makeOk(makeSome(&Integer{Value: int64(p.code)}))
+}
+
+// sysKill: (Process) -> Result<String, Nil>
+func builtinSysKill(e *Evaluator, args ...Object) Object {
+	p, errObj := processArg("sysKill", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	return signalProcess(p, os.Kill)
+}
+
+// sysSignal: (Process, String) -> Result<String, Nil>
+func builtinSysSignal(e *Evaluator, args ...Object) Object {
+	p, errObj := processArg("sysSignal", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	name, ok := args[1].(*List)
+	if !ok {
+		return newError("sysSignal expects a signal name, got %s", args[1].Type())
+	}
+	sig, ok := processSignals[listToString(name)]
+	if !ok {
+		return makeFailStr(fmt.Sprintf("unsupported signal %q", listToString(name)))
+	}
+	return signalProcess(p, sig)
+}
+
+// signalProcess sends sig to every command that has not exited yet
+func signalProcess(p *ProcessObject, sig os.Signal) Object {
+	select {
+	case <-p.done:
+		return makeFailStr("process has already exited")
+	default:
+	}
+	for _, cmd := range p.cmds {
+		if err := cmd.Process.Signal(sig); err != nil && err != os.ErrProcessDone {
+			return makeFailStr(err.Error())
+		}
+	}
+	return makeOk(&Nil{})
+}
+
+// sysPid: (Process) -> Int
+func builtinSysPid(e *Evaluator, args ...Object) Object {
+	p, errObj := processArg("sysPid", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	return &Integer{Value: int64(p.cmds[0].Process.Pid)}
+}
@@ -0,0 +1,54 @@
+package evaluator
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestProcessOutputIsBounded(t *testing.T) {
+	if _, err := exec.LookPath("yes"); err != nil {
+		t.Skip("yes is not available")
+	}
+	p, err := startProcess([]*exec.Cmd{exec.Command("yes", "chatty")}, &processOptions{stdin: "null", stdout: "pipe", stderr: "pipe"})
+	if err != nil {
+		t.Fatalf("startProcess failed: %v", err)
+	}
+	defer func() {
+		_ = p.cmds[0].Process.Signal(os.Kill)
+		p.stdout.release()
+		<-p.done
+	}()
+
+	// Give the writer plenty of time to fill the buffer past the limit
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		p.stdout.mu.Lock()
+		n := p.stdout.buf.Len()
+		p.stdout.mu.Unlock()
+		if n > processOutputLimit {
+			t.Fatalf("buffered %d bytes, limit is %d", n, processOutputLimit)
+		}
+		if n == processOutputLimit {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	p.stdout.mu.Lock()
+	n := p.stdout.buf.Len()
+	p.stdout.mu.Unlock()
+	if n != processOutputLimit {
+		t.Fatalf("expected the buffer to stop at %d bytes, got %d", processOutputLimit, n)
+	}
+
+	// Reading makes room and the process continues
+	if line, ok := p.stdout.readLine(); !ok || line != "chatty" {
+		t.Fatalf("readLine = %q, %v", line, ok)
+	}
+	if _, ok := p.stdout.read(4096); !ok {
+		t.Fatal("expected more output")
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+// +build !windows
+
+package evaluator
+
+import (
+	"os"
+	"syscall"
+)
+
+// processSignals are the signals sysSignal can send, by name
+var processSignals = map[string]os.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"CONT": syscall.SIGCONT,
+	"STOP": syscall.SIGSTOP,
+}
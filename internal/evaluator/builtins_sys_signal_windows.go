@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package evaluator
+
+import "os"
+
+// processSignals are the signals sysSignal can send, by name. Windows can
+// only kill a process.
+var processSignals = map[string]os.Signal{
+	"KILL": os.Kill,
+}
@@ -2,6 +2,8 @@ package evaluator
 
 import (
 	"math/big"
+
+	"github.com/funvibe/funxy/internal/typesystem"
 )
 
 // RegisterBasicTraits registers basic traits like Equal, Order, Numeric, etc.
@@ -13,6 +15,8 @@ func RegisterBasicTraits(e *Evaluator, env *Environment) {
 	registerBitwiseInstances(e)
 	registerConcatInstances(e)
 	registerDefaultInstances(e)
+	registerHashInstances(e)
+	registerToJsonInstances(e)
 }
 
 func registerEqualInstances(e *Evaluator) {
@@ -62,7 +66,7 @@ func registerEqualInstances(e *Evaluator) {
 		"(!=)": &Builtin{Name: "(!=)", Fn: notEqualFn},
 	}
 
-	types := []string{"Int", "Float", "Bool", "Char", "String", "BigInt", "Rational", "Bytes", "Bits", "Uuid", "Nil"}
+	types := []string{"Int", "Float", "Bool", "Char", "String", "BigInt", "Rational", "Bytes", "Bits", "Uuid", "Zone", "Nil"}
 	for _, t := range types {
 		// Map init removed
 		e.AddClassImplementation("Equal", t, &MethodTable{Methods: methods})
@@ -313,3 +317,91 @@ func registerDefaultInstances(e *Evaluator) {
 	// Maybe Default is not implemented for Result generally unless E has default?
 	// Skip Result for now.
 }
+
+// hashClassMethod is hash :: T -> Int, exported by lib/crypto together with
+// the Hash trait. Its instances are registered with the other traits.
+func hashClassMethod() *ClassMethod {
+	return &ClassMethod{
+		Name:            "hash",
+		ClassName:       "Hash",
+		Arity:           1,
+		DispatchSources: []typesystem.DispatchSource{{Kind: typesystem.DispatchArg, Index: 0}},
+	}
+}
+
+func registerHashInstances(e *Evaluator) {
+	methods := map[string]Object{
+		"hash": &Builtin{
+			Name: "hash",
+			Fn: func(eval *Evaluator, args ...Object) Object {
+				if _, rest, found := extractWitnessMethod(args, "hash"); found {
+					args = rest
+				}
+				if len(args) != 1 {
+					return newError("hash expects 1 argument, got %d", len(args))
+				}
+				return HashValue(args[0])
+			},
+		},
+	}
+
+	types := []string{
+		"Int", "Float", "Bool", "Char", "String", "BigInt", "Rational", "Bytes", "Bits", "Nil", string(NIL_OBJ),
+		"List", "Map", "Option", "Result", "Tuple", RUNTIME_TYPE_TUPLE, RUNTIME_TYPE_RECORD,
+	}
+	for _, t := range types {
+		e.AddClassImplementation("Hash", t, &MethodTable{Methods: methods})
+	}
+}
+
+// toJsonClassMethod is toJson :: T -> String, exported by lib/json together
+// with the ToJson trait.
+func toJsonClassMethod() *ClassMethod {
+	return &ClassMethod{
+		Name:            "toJson",
+		ClassName:       "ToJson",
+		Arity:           1,
+		DispatchSources: []typesystem.DispatchSource{{Kind: typesystem.DispatchArg, Index: 0}},
+	}
+}
+
+func registerToJsonInstances(e *Evaluator) {
+	methods := map[string]Object{
+		"toJson": &Builtin{
+			Name: "toJson",
+			Fn: func(eval *Evaluator, args ...Object) Object {
+				if _, rest, found := extractWitnessMethod(args, "toJson"); found {
+					args = rest
+				}
+				if len(args) != 1 {
+					return newError("toJson expects 1 argument, got %d", len(args))
+				}
+				return ToJsonValue(args[0])
+			},
+		},
+	}
+
+	types := []string{
+		"Int", "Float", "Bool", "Char", "String", "BigInt", "Rational", "Nil", string(NIL_OBJ),
+		"List", "Option", "Tuple", RUNTIME_TYPE_TUPLE, RUNTIME_TYPE_RECORD,
+	}
+	for _, t := range types {
+		e.AddClassImplementation("ToJson", t, &MethodTable{Methods: methods})
+	}
+}
+
+// HashValue implements hash for built-in types with the structural
+// Object.Hash, which agrees with areObjectsEqual.
+func HashValue(obj Object) Object {
+	return &Integer{Value: int64(obj.Hash())}
+}
+
+// ToJsonValue implements toJson for built-in types with the encoding
+// jsonEncode from lib/json uses.
+func ToJsonValue(obj Object) Object {
+	text, err := jsonEncode(obj)
+	if err != nil {
+		return newError("toJson: %s", err.Error())
+	}
+	return StringToList(string(text))
+}
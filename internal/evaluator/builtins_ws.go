@@ -4,13 +4,17 @@ import (
 	"bufio"
 	"crypto/rand"
 	"crypto/sha1"
+	"crypto/tls"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/funvibe/funbit/pkg/funbit"
@@ -26,14 +30,238 @@ const (
 	wsOpPong         = 10
 )
 
+var (
+	errWsClosed       = errors.New("connection closed")
+	errWsClosedByPeer = errors.New("connection closed by peer")
+	errWsTooBig       = errors.New("message too big")
+)
+
+// wsDefaultMaxMessageSize bounds incoming messages, after decompression,
+// unless the maxMessageSize option says otherwise
+const wsDefaultMaxMessageSize = 32 << 20
+
 // wsConnection represents a WebSocket connection
 type wsConnection struct {
-	conn     net.Conn
-	isClient bool
-	reader   *bufio.Reader
-	writeMu  sync.Mutex
-	closed   bool
-	closeMu  sync.Mutex
+	conn         net.Conn
+	isClient     bool
+	reader       *bufio.Reader
+	writeMu      sync.Mutex
+	writeTimeout time.Duration // per frame, 0 = none
+	closed       bool
+	closeMu      sync.Mutex
+	failure      error // why keepAlive dropped the peer, guarded by closeMu
+
+	protocol       string       // negotiated subprotocol, "" if none
+	deflate        *wsDeflate   // permessage-deflate state, nil if not negotiated
+	maxMessageSize int64        // largest incoming message in bytes
+	lastSeen       atomic.Int64 // UnixNano of the last frame received
+	done           chan struct{}
+	doneOnce       sync.Once
+
+	// Messages are read in the background, so pings are answered and pongs
+	// noticed while the program is busy. Server connections are consumed by
+	// their handlers.
+	inbox   chan wsIncoming
+	readErr error // guarded by closeMu, set before inbox is closed
+	served  bool
+}
+
+// wsIncoming is a complete text or binary message
+type wsIncoming struct {
+	opcode int
+	data   []byte
+}
+
+func newWsConnection(conn net.Conn, reader *bufio.Reader, isClient bool) *wsConnection {
+	ws := &wsConnection{
+		conn:     conn,
+		isClient: isClient,
+		reader:   reader,
+		done:     make(chan struct{}),
+
+		maxMessageSize: wsDefaultMaxMessageSize,
+	}
+	ws.lastSeen.Store(time.Now().UnixNano())
+	return ws
+}
+
+// shutdown closes the underlying connection without a close frame
+func (ws *wsConnection) shutdown() {
+	ws.closeMu.Lock()
+	ws.closed = true
+	ws.closeMu.Unlock()
+	ws.doneOnce.Do(func() { close(ws.done) })
+	_ = ws.conn.Close()
+}
+
+// wait sleeps for d; false if the connection was shut down meanwhile
+func (ws *wsConnection) wait(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ws.done:
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// startReader reads messages into the inbox until the connection ends
+func (ws *wsConnection) startReader() {
+	ws.inbox = make(chan wsIncoming, 16)
+	go func() {
+		defer close(ws.inbox)
+		for {
+			opcode, data, err := wsReadFrameMessage(ws)
+			if err != nil {
+				ws.closeMu.Lock()
+				switch {
+				case ws.failure != nil:
+					err = ws.failure
+				case ws.closed && err != errWsClosedByPeer && err != errWsTooBig:
+					err = errWsClosed
+				}
+				ws.readErr = err
+				ws.closeMu.Unlock()
+				return
+			}
+			select {
+			case ws.inbox <- wsIncoming{opcode: opcode, data: data}:
+			case <-ws.done:
+				ws.closeMu.Lock()
+				ws.readErr = errWsClosed
+				ws.closeMu.Unlock()
+				return
+			}
+		}
+	}()
+}
+
+// receive waits for the next message: forever when timeout is 0, at most
+// timeout when it is positive, not at all when it is negative. ok is false
+// when no message arrived in time.
+func (ws *wsConnection) receive(timeout time.Duration) (msg wsIncoming, ok bool, err error) {
+	var expired <-chan time.Time
+	if timeout < 0 {
+		select {
+		case msg, open := <-ws.inbox:
+			return ws.received(msg, open)
+		default:
+			return wsIncoming{}, false, nil
+		}
+	}
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+	select {
+	case msg, open := <-ws.inbox:
+		return ws.received(msg, open)
+	case <-expired:
+		return wsIncoming{}, false, nil
+	}
+}
+
+func (ws *wsConnection) received(msg wsIncoming, open bool) (wsIncoming, bool, error) {
+	if !open {
+		ws.closeMu.Lock()
+		defer ws.closeMu.Unlock()
+		return wsIncoming{}, false, ws.readErr
+	}
+	return msg, true, nil
+}
+
+// keepAlive pings the peer every interval and drops the connection when
+// nothing arrives within timeout of a ping
+func (ws *wsConnection) keepAlive(interval, timeout time.Duration) {
+	for ws.wait(interval) {
+		sent := time.Now().UnixNano()
+		if err := wsSendFrame(ws, wsOpPing, nil); err != nil {
+			return
+		}
+		if !ws.wait(timeout) {
+			return
+		}
+		if ws.lastSeen.Load() < sent {
+			ws.closeMu.Lock()
+			ws.failure = fmt.Errorf("peer did not answer ping within %v", timeout)
+			ws.closeMu.Unlock()
+			ws.shutdown()
+			return
+		}
+	}
+}
+
+// wsOptions are the WebSocket options of wsConnect and of the wsServe config
+// record, all optional:
+//
+//	{ protocols: List<String>, compress: Bool, pingInterval: Int, pongTimeout: Int,
+//	  maxMessageSize: Int }
+//
+// protocols are the subprotocols offered (client) or supported (server), in
+// order of preference. compress negotiates permessage-deflate. With a
+// pingInterval (milliseconds), the peer is pinged and dropped when nothing
+// arrives within pongTimeout of a ping, which defaults to pingInterval.
+// maxMessageSize (bytes, after decompression) closes the connection with
+// status 1009 when the peer sends a larger message.
+type wsOptions struct {
+	protocols      []string
+	compress       bool
+	pingInterval   time.Duration
+	pongTimeout    time.Duration
+	maxMessageSize int64 // 0 means wsDefaultMaxMessageSize
+}
+
+func parseWsOptions(name string, rec *RecordInstance) (*wsOptions, *Error) {
+	opts := &wsOptions{}
+	if rec == nil {
+		return opts, nil
+	}
+	if val := rec.Get("protocols"); val != nil {
+		list, ok := val.(*List)
+		if !ok {
+			return nil, newError("%s: protocols must be a List<String>", name)
+		}
+		for _, p := range list.ToSlice() {
+			s, ok := p.(*List)
+			protocol := ""
+			if ok {
+				protocol = ListToString(s)
+			}
+			if protocol == "" || strings.ContainsAny(protocol, " ,;\t\r\n") {
+				return nil, newError("%s: protocols must be non-empty names without spaces or commas", name)
+			}
+			opts.protocols = append(opts.protocols, protocol)
+		}
+	}
+	if val := rec.Get("compress"); val != nil {
+		b, ok := val.(*Boolean)
+		if !ok {
+			return nil, newError("%s: compress must be a Bool", name)
+		}
+		opts.compress = b.Value
+	}
+	for field, target := range map[string]*time.Duration{"pingInterval": &opts.pingInterval, "pongTimeout": &opts.pongTimeout} {
+		if val := rec.Get(field); val != nil {
+			ms, ok := val.(*Integer)
+			if !ok || ms.Value < 0 {
+				return nil, newError("%s: %s must be a non-negative Int (milliseconds)", name, field)
+			}
+			*target = time.Duration(ms.Value) * time.Millisecond
+		}
+	}
+	if opts.pongTimeout == 0 {
+		opts.pongTimeout = opts.pingInterval
+	}
+	if val := rec.Get("maxMessageSize"); val != nil {
+		size, ok := val.(*Integer)
+		if !ok || size.Value <= 0 {
+			return nil, newError("%s: maxMessageSize must be a positive Int (bytes)", name)
+		}
+		opts.maxMessageSize = size.Value
+	}
+	return opts, nil
 }
 
 // Global WebSocket connection storage
@@ -84,34 +312,96 @@ func releaseWsConnSlot() {
 type wsServer struct {
 	listener net.Listener
 	shutdown chan struct{}
-	handler  Object
+	handlers *wsHandlers
 	eval     *Evaluator
+	config   *serverConfig
+	options  *wsOptions
+	tls      *tls.Config
 	running  bool
+
+	connsMu sync.Mutex
+	conns   map[int64]bool // open connections, for wsServerConnections
+}
+
+// wsHandlers are the callbacks of a server. A plain function is the reply
+// form: it receives every message as a String and a non-empty result is sent
+// back. A record may set any of onOpen, onMessage, onClose and onError.
+type wsHandlers struct {
+	reply     Object // (connId, message: String) -> String
+	onOpen    Object // (connId) -> A
+	onMessage Object // (connId, message: String | Bytes) -> A
+	onClose   Object // (connId) -> A
+	onError   Object // (connId, error: String) -> A
+}
+
+func parseWsHandlers(e *Evaluator, name string, arg Object) (*wsHandlers, *Error) {
+	capture := func(fn Object) Object {
+		if e.CaptureHandler != nil {
+			return e.CaptureHandler(fn)
+		}
+		return fn
+	}
+
+	rec, ok := arg.(*RecordInstance)
+	if !ok {
+		handler := capture(arg)
+		if !wsIsCallable(handler) {
+			return nil, newError("%s: handler must be a function or a record of callbacks", name)
+		}
+		return &wsHandlers{reply: handler}, nil
+	}
+
+	h := &wsHandlers{}
+	callbacks := []struct {
+		field  string
+		target *Object
+	}{
+		{"onOpen", &h.onOpen},
+		{"onMessage", &h.onMessage},
+		{"onClose", &h.onClose},
+		{"onError", &h.onError},
+	}
+	for _, c := range callbacks {
+		if val := rec.Get(c.field); val != nil {
+			fn := capture(val)
+			if !wsIsCallable(fn) {
+				return nil, newError("%s: handler field %s must be a function", name, c.field)
+			}
+			*c.target = fn
+		}
+	}
+	return h, nil
 }
 
 // WsBuiltins returns WebSocket built-in functions
 func WsBuiltins() map[string]*Builtin {
 	return map[string]*Builtin{
-		"wsConnect":           {Fn: builtinWsConnect, Name: "wsConnect"},
-		"wsConnectTimeout":    {Fn: builtinWsConnectTimeout, Name: "wsConnectTimeout"},
-		"wsSend":              {Fn: builtinWsSend, Name: "wsSend"},
-		"wsRecv":              {Fn: builtinWsRecv, Name: "wsRecv"},
-		"wsRecvTimeout":       {Fn: builtinWsRecvTimeout, Name: "wsRecvTimeout"},
-		"wsClose":             {Fn: builtinWsClose, Name: "wsClose"},
-		"wsServe":             {Fn: builtinWsServe, Name: "wsServe"},
-		"wsServeAsync":        {Fn: builtinWsServeAsync, Name: "wsServeAsync"},
-		"wsServerStop":        {Fn: builtinWsServerStop, Name: "wsServerStop"},
-		"wsSetMaxConnections": {Fn: builtinWsSetMaxConnections, Name: "wsSetMaxConnections"},
+		"wsConnect":            {Fn: builtinWsConnect, Name: "wsConnect"},
+		"wsConnectTimeout":     {Fn: builtinWsConnectTimeout, Name: "wsConnectTimeout"},
+		"wsSend":               {Fn: builtinWsSend, Name: "wsSend"},
+		"wsSendBytes":          {Fn: builtinWsSendBytes, Name: "wsSendBytes"},
+		"wsBroadcast":          {Fn: builtinWsBroadcast, Name: "wsBroadcast"},
+		"wsRecv":               {Fn: builtinWsRecv, Name: "wsRecv"},
+		"wsRecvTimeout":        {Fn: builtinWsRecvTimeout, Name: "wsRecvTimeout"},
+		"wsRecvMessage":        {Fn: builtinWsRecvMessage, Name: "wsRecvMessage"},
+		"wsRecvMessageTimeout": {Fn: builtinWsRecvMessageTimeout, Name: "wsRecvMessageTimeout"},
+		"wsProtocol":           {Fn: builtinWsProtocol, Name: "wsProtocol"},
+		"wsClose":              {Fn: builtinWsClose, Name: "wsClose"},
+		"wsServe":              {Fn: builtinWsServe, Name: "wsServe"},
+		"wsServeAsync":         {Fn: builtinWsServeAsync, Name: "wsServeAsync"},
+		"wsServerStop":         {Fn: builtinWsServerStop, Name: "wsServerStop"},
+		"wsServerConnections":  {Fn: builtinWsServerConnections, Name: "wsServerConnections"},
+		"wsSetMaxConnections":  {Fn: builtinWsSetMaxConnections, Name: "wsSetMaxConnections"},
 	}
 }
 
 // SetWsBuiltinTypes sets type information for WebSocket builtins
 
 // builtinWsConnect connects to a WebSocket server
-// wsConnect(url: String) -> Result<String, Int>
+// wsConnect(url: String, options?: { timeout?, protocols?, compress?, pingInterval?, pongTimeout?, maxMessageSize? }) -> Result<String, Int>
 func builtinWsConnect(e *Evaluator, args ...Object) Object {
-	if len(args) != 1 {
-		return newError("wsConnect requires 1 argument (url)")
+	if len(args) < 1 || len(args) > 2 {
+		return newError("wsConnect requires 1 or 2 arguments (url, options?)")
 	}
 
 	urlList, ok := args[0].(*List)
@@ -119,19 +409,27 @@ func builtinWsConnect(e *Evaluator, args ...Object) Object {
 		return newError("wsConnect: url must be a String")
 	}
 
-	urlStr := listToString(urlList)
-	conn, err := wsDialWithTimeout(urlStr, 30*time.Second)
-	if err != nil {
-		return makeFailStr(err.Error())
+	timeout := 30 * time.Second
+	var optsRec *RecordInstance
+	if len(args) == 2 {
+		optsRec, ok = args[1].(*RecordInstance)
+		if !ok {
+			return newError("wsConnect: options must be a record")
+		}
+		if val := optsRec.Get("timeout"); val != nil {
+			ms, ok := val.(*Integer)
+			if !ok || ms.Value <= 0 {
+				return newError("wsConnect: timeout must be a positive Int (milliseconds)")
+			}
+			timeout = time.Duration(ms.Value) * time.Millisecond
+		}
+	}
+	opts, errObj := parseWsOptions("wsConnect", optsRec)
+	if errObj != nil {
+		return errObj
 	}
 
-	wsConnectionsMu.Lock()
-	connID := wsNextConnID
-	wsNextConnID++
-	wsConnections[connID] = conn
-	wsConnectionsMu.Unlock()
-
-	return makeOk(&Integer{Value: connID})
+	return wsConnectAndRegister(listToString(urlList), timeout, opts)
 }
 
 // builtinWsConnectTimeout connects with custom timeout
@@ -151,11 +449,19 @@ func builtinWsConnectTimeout(e *Evaluator, args ...Object) Object {
 		return newError("wsConnectTimeout: timeoutMs must be an Int")
 	}
 
-	urlStr := listToString(urlList)
-	conn, err := wsDialWithTimeout(urlStr, time.Duration(timeoutMs.Value)*time.Millisecond)
+	return wsConnectAndRegister(listToString(urlList), time.Duration(timeoutMs.Value)*time.Millisecond, &wsOptions{})
+}
+
+// wsConnectAndRegister dials a server and returns Ok(connId)
+func wsConnectAndRegister(urlStr string, timeout time.Duration, opts *wsOptions) Object {
+	conn, err := wsDialWithTimeout(urlStr, timeout, opts)
 	if err != nil {
 		return makeFailStr(err.Error())
 	}
+	conn.startReader()
+	if opts.pingInterval > 0 {
+		go conn.keepAlive(opts.pingInterval, opts.pongTimeout)
+	}
 
 	wsConnectionsMu.Lock()
 	connID := wsNextConnID
@@ -167,7 +473,7 @@ func builtinWsConnectTimeout(e *Evaluator, args ...Object) Object {
 }
 
 // wsDialWithTimeout performs WebSocket handshake with timeout
-func wsDialWithTimeout(urlStr string, timeout time.Duration) (*wsConnection, error) {
+func wsDialWithTimeout(urlStr string, timeout time.Duration, opts *wsOptions) (*wsConnection, error) {
 	parsed, err := url.Parse(urlStr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %v", err)
@@ -188,6 +494,18 @@ func wsDialWithTimeout(urlStr string, timeout time.Duration) (*wsConnection, err
 	if err != nil {
 		return nil, fmt.Errorf("connection failed: %v", err)
 	}
+	if parsed.Scheme == "wss" {
+		conn = tls.Client(conn, &tls.Config{ServerName: parsed.Hostname()})
+	}
+
+	return wsClientHandshake(conn, parsed, timeout, opts)
+}
+
+// wsClientHandshake upgrades an open connection to a client WebSocket
+func wsClientHandshake(conn net.Conn, parsed *url.URL, timeout time.Duration, opts *wsOptions) (*wsConnection, error) {
+	if opts == nil {
+		opts = &wsOptions{}
+	}
 
 	// Generate WebSocket key
 	key := make([]byte, 16)
@@ -203,13 +521,22 @@ func wsDialWithTimeout(urlStr string, timeout time.Duration) (*wsConnection, err
 		path += "?" + parsed.RawQuery
 	}
 
+	var extra strings.Builder
+	if len(opts.protocols) > 0 {
+		extra.WriteString("Sec-WebSocket-Protocol: " + strings.Join(opts.protocols, ", ") + "\r\n")
+	}
+	if opts.compress {
+		extra.WriteString("Sec-WebSocket-Extensions: " + wsDeflateOffer + "\r\n")
+	}
+
 	request := fmt.Sprintf("GET %s HTTP/1.1\r\n"+
 		"Host: %s\r\n"+
 		"Upgrade: websocket\r\n"+
 		"Connection: Upgrade\r\n"+
 		"Sec-WebSocket-Key: %s\r\n"+
 		"Sec-WebSocket-Version: 13\r\n"+
-		"\r\n", path, parsed.Host, wsKey)
+		"%s"+
+		"\r\n", path, parsed.Host, wsKey, extra.String())
 
 	_ = conn.SetWriteDeadline(time.Now().Add(timeout))
 	if _, err := conn.Write([]byte(request)); err != nil {
@@ -235,6 +562,7 @@ func wsDialWithTimeout(urlStr string, timeout time.Duration) (*wsConnection, err
 	// Read headers until empty line
 	expectedAccept := computeAcceptKey(wsKey)
 	gotAccept := false
+	protocol, extensions := "", ""
 	for {
 		line, err := reader.ReadString('\n')
 		if err != nil {
@@ -245,11 +573,17 @@ func wsDialWithTimeout(urlStr string, timeout time.Duration) (*wsConnection, err
 		if line == "" {
 			break
 		}
-		if strings.HasPrefix(strings.ToLower(line), "sec-websocket-accept:") {
-			accept := strings.TrimSpace(strings.SplitN(line, ":", 2)[1])
-			if accept == expectedAccept {
+		name, value, _ := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "sec-websocket-accept":
+			if value == expectedAccept {
 				gotAccept = true
 			}
+		case "sec-websocket-protocol":
+			protocol = value
+		case "sec-websocket-extensions":
+			extensions = value
 		}
 	}
 
@@ -257,15 +591,41 @@ func wsDialWithTimeout(urlStr string, timeout time.Duration) (*wsConnection, err
 		_ = conn.Close()
 		return nil, fmt.Errorf("invalid Sec-WebSocket-Accept")
 	}
+	if protocol != "" && !containsString(opts.protocols, protocol) {
+		_ = conn.Close()
+		return nil, fmt.Errorf("server selected unrequested subprotocol %q", protocol)
+	}
+
+	ws := newWsConnection(conn, reader, true)
+	ws.protocol = protocol
+	if opts != nil && opts.maxMessageSize > 0 {
+		ws.maxMessageSize = opts.maxMessageSize
+	}
+	if extensions != "" {
+		if !opts.compress {
+			_ = conn.Close()
+			return nil, fmt.Errorf("server selected unrequested extensions: %s", extensions)
+		}
+		if err := wsCheckDeflateResponse(extensions); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		ws.deflate = &wsDeflate{}
+	}
 
 	// Clear deadlines
 	_ = conn.SetDeadline(time.Time{})
 
-	return &wsConnection{
-		conn:     conn,
-		isClient: true,
-		reader:   reader,
-	}, nil
+	return ws, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
 
 // computeAcceptKey computes the Sec-WebSocket-Accept value
@@ -276,6 +636,14 @@ func computeAcceptKey(key string) string {
 	return base64.StdEncoding.EncodeToString(h.Sum(nil))
 }
 
+// wsLookup returns a registered connection
+func wsLookup(connID int64) (*wsConnection, bool) {
+	wsConnectionsMu.RLock()
+	defer wsConnectionsMu.RUnlock()
+	conn, exists := wsConnections[connID]
+	return conn, exists
+}
+
 // builtinWsSend sends a text message
 // wsSend(connId: Int, message: String) -> Result<String, Nil>
 func builtinWsSend(e *Evaluator, args ...Object) Object {
@@ -293,10 +661,7 @@ func builtinWsSend(e *Evaluator, args ...Object) Object {
 		return newError("wsSend: message must be a String")
 	}
 
-	wsConnectionsMu.RLock()
-	conn, exists := wsConnections[connID.Value]
-	wsConnectionsMu.RUnlock()
-
+	conn, exists := wsLookup(connID.Value)
 	if !exists {
 		return makeFailStr("connection not found")
 	}
@@ -309,24 +674,116 @@ func builtinWsSend(e *Evaluator, args ...Object) Object {
 	return makeOk(&Nil{})
 }
 
-// wsSendFrame sends a WebSocket frame using funbit
+// builtinWsSendBytes sends a binary message
+// wsSendBytes(connId: Int, data: Bytes) -> Result<String, Nil>
+func builtinWsSendBytes(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 {
+		return newError("wsSendBytes requires 2 arguments (connId, data)")
+	}
+
+	connID, ok := args[0].(*Integer)
+	if !ok {
+		return newError("wsSendBytes: connId must be an Int")
+	}
+
+	data, ok := args[1].(*Bytes)
+	if !ok {
+		return newError("wsSendBytes: data must be Bytes")
+	}
+
+	conn, exists := wsLookup(connID.Value)
+	if !exists {
+		return makeFailStr("connection not found")
+	}
+
+	if err := wsSendFrame(conn, wsOpBinary, data.ToSlice()); err != nil {
+		return makeFailStr(err.Error())
+	}
+
+	return makeOk(&Nil{})
+}
+
+// builtinWsBroadcast sends one message to several connections
+// wsBroadcast(connIds: List<Int>, message: String | Bytes) -> Int
+// Returns how many connections the message was sent to
+func builtinWsBroadcast(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 {
+		return newError("wsBroadcast requires 2 arguments (connIds, message)")
+	}
+
+	ids, ok := args[0].(*List)
+	if !ok {
+		return newError("wsBroadcast: connIds must be a List<Int>")
+	}
+
+	opcode, payload, ok := wsMessagePayload(args[1])
+	if !ok {
+		return newError("wsBroadcast: message must be a String or Bytes")
+	}
+
+	sent := int64(0)
+	for _, id := range ids.ToSlice() {
+		connID, ok := id.(*Integer)
+		if !ok {
+			return newError("wsBroadcast: connIds must be a List<Int>")
+		}
+		if conn, exists := wsLookup(connID.Value); exists {
+			if wsSendFrame(conn, opcode, payload) == nil {
+				sent++
+			}
+		}
+	}
+	return &Integer{Value: sent}
+}
+
+// wsMessagePayload converts a String or Bytes message to a frame payload
+func wsMessagePayload(msg Object) (opcode int, payload []byte, ok bool) {
+	switch m := msg.(type) {
+	case *Bytes:
+		return wsOpBinary, m.ToSlice(), true
+	case *List:
+		return wsOpText, []byte(listToString(m)), true
+	}
+	return 0, nil, false
+}
+
+// wsMessageObject converts a received message to String or Bytes
+func wsMessageObject(msg wsIncoming) Object {
+	if msg.opcode == wsOpBinary {
+		return bytesFromSlice(msg.data)
+	}
+	return stringToList(string(msg.data))
+}
+
+// wsSendFrame sends a WebSocket frame using funbit. Data frames are
+// compressed when permessage-deflate was negotiated.
 func wsSendFrame(ws *wsConnection, opcode int, payload []byte) error {
 	ws.closeMu.Lock()
 	if ws.closed {
 		ws.closeMu.Unlock()
-		return fmt.Errorf("connection closed")
+		return errWsClosed
 	}
 	ws.closeMu.Unlock()
 
+	rsv := 0
+	if ws.deflate != nil && (opcode == wsOpText || opcode == wsOpBinary) {
+		compressed, err := ws.deflate.compress(payload)
+		if err != nil {
+			return fmt.Errorf("compression failed: %v", err)
+		}
+		payload = compressed
+		rsv = 4 // RSV1
+	}
+
 	ws.writeMu.Lock()
 	defer ws.writeMu.Unlock()
 
 	// Build frame using funbit
 	builder := funbit.NewBuilder()
 
-	// FIN=1, RSV1-3=0, opcode
+	// FIN=1, RSV1-3, opcode
 	funbit.AddInteger(builder, 1, funbit.WithSize(1))             // FIN
-	funbit.AddInteger(builder, 0, funbit.WithSize(3))             // RSV1-3
+	funbit.AddInteger(builder, int64(rsv), funbit.WithSize(3))    // RSV1-3
 	funbit.AddInteger(builder, int64(opcode), funbit.WithSize(4)) // opcode
 
 	// MASK (client must mask, server must not)
@@ -361,6 +818,10 @@ func wsSendFrame(ws *wsConnection, opcode int, payload []byte) error {
 		return fmt.Errorf("frame build failed: %v", err)
 	}
 
+	if ws.writeTimeout > 0 {
+		_ = ws.conn.SetWriteDeadline(time.Now().Add(ws.writeTimeout))
+	}
+
 	// Write header
 	if _, err := ws.conn.Write(bitstring.ToBytes()); err != nil {
 		return fmt.Errorf("write failed: %v", err)
@@ -384,6 +845,18 @@ func wsSendFrame(ws *wsConnection, opcode int, payload []byte) error {
 	return nil
 }
 
+// wsReceiveFrom looks up a connection that the program reads itself
+func wsReceiveFrom(connID int64) (*wsConnection, Object) {
+	conn, exists := wsLookup(connID)
+	if !exists {
+		return nil, makeFailStr("connection not found")
+	}
+	if conn.served {
+		return nil, makeFailStr("connection is read by its server handler")
+	}
+	return conn, nil
+}
+
 // builtinWsRecv receives a message (blocking)
 // wsRecv(connId: Int) -> Result<String, String>
 func builtinWsRecv(e *Evaluator, args ...Object) Object {
@@ -396,23 +869,17 @@ func builtinWsRecv(e *Evaluator, args ...Object) Object {
 		return newError("wsRecv: connId must be an Int")
 	}
 
-	wsConnectionsMu.RLock()
-	conn, exists := wsConnections[connID.Value]
-	wsConnectionsMu.RUnlock()
-
-	if !exists {
-		return makeFailStr("connection not found")
+	conn, fail := wsReceiveFrom(connID.Value)
+	if fail != nil {
+		return fail
 	}
 
-	// Clear deadline for blocking read
-	_ = conn.conn.SetReadDeadline(time.Time{})
-
-	message, err := wsReadMessage(conn)
+	msg, _, err := conn.receive(0)
 	if err != nil {
 		return makeFailStr(err.Error())
 	}
 
-	return makeOk(stringToList(message))
+	return makeOk(stringToList(string(msg.data)))
 }
 
 // builtinWsRecvTimeout receives with timeout
@@ -432,47 +899,142 @@ func builtinWsRecvTimeout(e *Evaluator, args ...Object) Object {
 		return newError("wsRecvTimeout: timeoutMs must be an Int")
 	}
 
-	wsConnectionsMu.RLock()
-	conn, exists := wsConnections[connID.Value]
-	wsConnectionsMu.RUnlock()
+	conn, fail := wsReceiveFrom(connID.Value)
+	if fail != nil {
+		return fail
+	}
 
-	if !exists {
-		return makeFailStr("connection not found")
+	msg, ok, err := conn.receive(wsRecvWait(timeoutMs.Value))
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	if !ok {
+		// Timeout -> return Ok(None)
+		return makeOk(makeNone())
 	}
 
-	// Set read deadline
-	_ = conn.conn.SetReadDeadline(time.Now().Add(time.Duration(timeoutMs.Value) * time.Millisecond))
+	// Return Ok(Some(message))
+	return makeOk(makeSome(stringToList(string(msg.data))))
+}
+
+// builtinWsRecvMessage receives a text message as String, a binary one as Bytes
+// wsRecvMessage(connId: Int) -> Result<String, String | Bytes>
+func builtinWsRecvMessage(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("wsRecvMessage requires 1 argument (connId)")
+	}
+
+	connID, ok := args[0].(*Integer)
+	if !ok {
+		return newError("wsRecvMessage: connId must be an Int")
+	}
+
+	conn, fail := wsReceiveFrom(connID.Value)
+	if fail != nil {
+		return fail
+	}
 
-	message, err := wsReadMessage(conn)
+	msg, _, err := conn.receive(0)
 	if err != nil {
-		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-			// Timeout -> return Ok(None)
-			return makeOk(makeNone())
-		}
 		return makeFailStr(err.Error())
 	}
 
-	// Return Ok(Some(message))
-	return makeOk(makeSome(stringToList(message)))
+	return makeOk(wsMessageObject(msg))
+}
+
+// builtinWsRecvMessageTimeout is wsRecvMessage with a timeout
+// wsRecvMessageTimeout(connId: Int, timeoutMs: Int) -> Result<String, Option<String | Bytes>>
+func builtinWsRecvMessageTimeout(e *Evaluator, args ...Object) Object {
+	if len(args) != 2 {
+		return newError("wsRecvMessageTimeout requires 2 arguments (connId, timeoutMs)")
+	}
+
+	connID, ok := args[0].(*Integer)
+	if !ok {
+		return newError("wsRecvMessageTimeout: connId must be an Int")
+	}
+
+	timeoutMs, ok := args[1].(*Integer)
+	if !ok {
+		return newError("wsRecvMessageTimeout: timeoutMs must be an Int")
+	}
+
+	conn, fail := wsReceiveFrom(connID.Value)
+	if fail != nil {
+		return fail
+	}
+
+	msg, ok, err := conn.receive(wsRecvWait(timeoutMs.Value))
+	if err != nil {
+		return makeFailStr(err.Error())
+	}
+	if !ok {
+		return makeOk(makeNone())
+	}
+
+	return makeOk(makeSome(wsMessageObject(msg)))
+}
+
+// wsRecvWait converts a receive timeout; zero or less only takes a message
+// that has already arrived
+func wsRecvWait(ms int64) time.Duration {
+	if ms <= 0 {
+		return -1
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// builtinWsProtocol returns the subprotocol agreed in the handshake
+// wsProtocol(connId: Int) -> Result<String, String>
+func builtinWsProtocol(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("wsProtocol requires 1 argument (connId)")
+	}
+
+	connID, ok := args[0].(*Integer)
+	if !ok {
+		return newError("wsProtocol: connId must be an Int")
+	}
+
+	conn, exists := wsLookup(connID.Value)
+	if !exists {
+		return makeFailStr("connection not found")
+	}
+
+	return makeOk(stringToList(conn.protocol))
 }
 
-// wsReadMessage reads a complete WebSocket message using funbit
+// wsReadMessage reads a complete message as a string
 func wsReadMessage(ws *wsConnection) (string, error) {
+	_, data, err := wsReadFrameMessage(ws)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// wsReadFrameMessage reads a complete WebSocket message using funbit,
+// answering control frames on the way. It returns the opcode of the message
+// (text or binary) and its decompressed payload.
+func wsReadFrameMessage(ws *wsConnection) (int, []byte, error) {
 	ws.closeMu.Lock()
 	if ws.closed {
 		ws.closeMu.Unlock()
-		return "", fmt.Errorf("connection closed")
+		return 0, nil, errWsClosed
 	}
 	ws.closeMu.Unlock()
 
 	var messageData []byte
+	messageOp := wsOpText
+	compressed := false
 
 	for {
 		// Read first 2 bytes (header)
 		header := make([]byte, 2)
 		if _, err := io.ReadFull(ws.reader, header); err != nil {
-			return "", fmt.Errorf("read header failed: %v", err)
+			return 0, nil, fmt.Errorf("read header failed: %v", err)
 		}
+		ws.lastSeen.Store(time.Now().UnixNano())
 
 		// Parse header using funbit
 		headerBits := funbit.NewBitStringFromBytes(header)
@@ -488,7 +1050,12 @@ func wsReadMessage(ws *wsConnection) (string, error) {
 
 		results, err := funbit.Match(matcher, headerBits)
 		if err != nil || len(results) == 0 {
-			return "", fmt.Errorf("match header failed: %v", err)
+			return 0, nil, fmt.Errorf("match header failed: %v", err)
+		}
+
+		// RSV1 marks the first frame of a compressed message
+		if rsv&3 != 0 || (rsv&4 != 0 && (ws.deflate == nil || (opcode != wsOpText && opcode != wsOpBinary))) {
+			return 0, nil, fmt.Errorf("protocol error: unexpected reserved bits")
 		}
 
 		// Handle extended payload length
@@ -498,7 +1065,7 @@ func wsReadMessage(ws *wsConnection) (string, error) {
 		} else if payloadLen7 == 126 {
 			extLen := make([]byte, 2)
 			if _, err := io.ReadFull(ws.reader, extLen); err != nil {
-				return "", fmt.Errorf("read ext len failed: %v", err)
+				return 0, nil, fmt.Errorf("read ext len failed: %v", err)
 			}
 			extBits := funbit.NewBitStringFromBytes(extLen)
 			extMatcher := funbit.NewMatcher()
@@ -509,7 +1076,7 @@ func wsReadMessage(ws *wsConnection) (string, error) {
 		} else { // 127
 			extLen := make([]byte, 8)
 			if _, err := io.ReadFull(ws.reader, extLen); err != nil {
-				return "", fmt.Errorf("read ext len failed: %v", err)
+				return 0, nil, fmt.Errorf("read ext len failed: %v", err)
 			}
 			extBits := funbit.NewBitStringFromBytes(extLen)
 			extMatcher := funbit.NewMatcher()
@@ -522,15 +1089,20 @@ func wsReadMessage(ws *wsConnection) (string, error) {
 		if mask == 1 {
 			maskKey = make([]byte, 4)
 			if _, err := io.ReadFull(ws.reader, maskKey); err != nil {
-				return "", fmt.Errorf("read mask key failed: %v", err)
+				return 0, nil, fmt.Errorf("read mask key failed: %v", err)
 			}
 		}
 
+		// Refuse frames past the message limit before allocating for them
+		if payloadLen < 0 || payloadLen > ws.maxMessageSize-int64(len(messageData)) {
+			return 0, nil, ws.closeTooBig()
+		}
+
 		// Read payload
 		payload := make([]byte, payloadLen)
 		if payloadLen > 0 {
 			if _, err := io.ReadFull(ws.reader, payload); err != nil {
-				return "", fmt.Errorf("read payload failed: %v", err)
+				return 0, nil, fmt.Errorf("read payload failed: %v", err)
 			}
 		}
 
@@ -544,24 +1116,51 @@ func wsReadMessage(ws *wsConnection) (string, error) {
 		// Handle different opcodes
 		switch opcode {
 		case wsOpText, wsOpBinary, wsOpContinuation:
+			if opcode != wsOpContinuation {
+				messageOp = opcode
+				compressed = rsv&4 != 0
+			}
 			messageData = append(messageData, payload...)
 			if fin == 1 {
-				return string(messageData), nil
+				if compressed {
+					data, err := ws.deflate.decompress(messageData, ws.maxMessageSize)
+					if err == errWsTooBig {
+						return 0, nil, ws.closeTooBig()
+					}
+					if err != nil {
+						return 0, nil, err
+					}
+					messageData = data
+				}
+				return messageOp, messageData, nil
 			}
 		case wsOpClose:
+			// Echo the status code, then the connection is done
+			if len(payload) > 2 {
+				payload = payload[:2]
+			}
+			_ = wsSendFrame(ws, wsOpClose, payload)
 			ws.closeMu.Lock()
 			ws.closed = true
 			ws.closeMu.Unlock()
-			return "", fmt.Errorf("connection closed by peer")
+			return 0, nil, errWsClosedByPeer
 		case wsOpPing:
 			// Respond with pong
 			_ = wsSendFrame(ws, wsOpPong, payload)
 		case wsOpPong:
-			// Ignore pong
+			// Only refreshes lastSeen
 		}
 	}
 }
 
+// closeTooBig ends the connection over a message larger than
+// maxMessageSize with status 1009, message too big
+func (ws *wsConnection) closeTooBig() error {
+	_ = wsSendFrame(ws, wsOpClose, []byte{0x03, 0xf1})
+	ws.shutdown()
+	return errWsTooBig
+}
+
 // builtinWsClose closes a WebSocket connection
 // wsClose(connId: Int) -> Result<String, Nil>
 func builtinWsClose(e *Evaluator, args ...Object) Object {
@@ -585,45 +1184,52 @@ func builtinWsClose(e *Evaluator, args ...Object) Object {
 		return makeFailStr("connection not found")
 	}
 
-	// Send close frame
-	_ = wsSendFrame(conn, wsOpClose, nil)
-	_ = conn.conn.Close()
-
-	conn.closeMu.Lock()
-	conn.closed = true
-	conn.closeMu.Unlock()
+	// Send close frame (1000, normal closure)
+	_ = wsSendFrame(conn, wsOpClose, []byte{0x03, 0xe8})
+	conn.shutdown()
 
 	return makeOk(&Nil{})
 }
 
 // builtinWsServe starts a blocking WebSocket server
-// wsServe(port: Int, handler: (connId: Int, message: String) -> String) -> Result<String, Nil>
+// wsServe(port: Int | WsServerConfig, handler: ((connId: Int, message: String) -> String) | WsHandlers) -> Result<String, Nil>
 func builtinWsServe(e *Evaluator, args ...Object) Object {
 	if len(args) != 2 {
 		return newError("wsServe requires 2 arguments (port, handler)")
 	}
 
-	port, ok := args[0].(*Integer)
-	if !ok {
-		return newError("wsServe: port must be an Int")
+	cfg, opts, errObj := parseWsServerConfig("wsServe", args[0])
+	if errObj != nil {
+		return errObj
 	}
 
-	handler := args[1]
-	// Capture handler if CaptureHandler is available
-	if e.CaptureHandler != nil {
-		handler = e.CaptureHandler(handler)
+	handlers, errObj := parseWsHandlers(e, "wsServe", args[1])
+	if errObj != nil {
+		return errObj
 	}
 
-	if !wsIsCallable(handler) {
-		return newError("wsServe: handler must be a function")
+	tlsConf, err := cfg.tlsConfig(e)
+	if err != nil {
+		return makeFailStr(err.Error())
 	}
 
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port.Value))
+	listener, err := net.Listen("tcp", cfg.addr())
 	if err != nil {
 		return makeFailStr(fmt.Sprintf("listen failed: %v", err))
 	}
 	defer func() { _ = listener.Close() }()
 
+	srv := &wsServer{
+		listener: listener,
+		handlers: handlers,
+		eval:     e,
+		config:   cfg,
+		options:  opts,
+		tls:      tlsConf,
+		running:  true,
+		conns:    make(map[int64]bool),
+	}
+
 	for {
 		conn, err := listener.Accept()
 		if err != nil {
@@ -634,6 +1240,9 @@ func builtinWsServe(e *Evaluator, args ...Object) Object {
 			_ = conn.Close()
 			continue
 		}
+		if tlsConf != nil {
+			conn = tls.Server(conn, tlsConf)
+		}
 
 		// Create a fresh evaluator/VM for each connection
 		var connEval *Evaluator
@@ -645,11 +1254,28 @@ func builtinWsServe(e *Evaluator, args ...Object) Object {
 
 		go func() {
 			defer releaseWsConnSlot()
-			handleWsConnection(conn, handler, connEval)
+			handleWsConnection(conn, srv, connEval)
 		}()
 	}
 }
 
+// parseWsServerConfig reads the port or config record of wsServe/wsServeAsync
+func parseWsServerConfig(name string, arg Object) (*serverConfig, *wsOptions, *Error) {
+	cfg, errObj := parseServerConfig(name, arg)
+	if errObj != nil {
+		return nil, nil, errObj
+	}
+	if cfg.http2 != nil || cfg.maxBodySize != 0 || cfg.streamBody {
+		return nil, nil, newError("%s: config fields http2, maxBodySize and streamBody only apply to HTTP servers", name)
+	}
+	rec, _ := arg.(*RecordInstance)
+	opts, errObj := parseWsOptions(name, rec)
+	if errObj != nil {
+		return nil, nil, errObj
+	}
+	return cfg, opts, nil
+}
+
 // builtinWsSetMaxConnections sets max concurrent connections (0 = unlimited)
 func builtinWsSetMaxConnections(e *Evaluator, args ...Object) Object {
 	if len(args) != 1 {
@@ -671,29 +1297,30 @@ func builtinWsSetMaxConnections(e *Evaluator, args ...Object) Object {
 }
 
 // builtinWsServeAsync starts a non-blocking WebSocket server
-// wsServeAsync(port: Int, handler: (connId: Int, message: String) -> String) -> Result<String, Int>
+// wsServeAsync(port: Int | WsServerConfig, handler: ((connId: Int, message: String) -> String) | WsHandlers) -> Result<String, Int>
 func builtinWsServeAsync(e *Evaluator, args ...Object) Object {
 	if len(args) != 2 {
 		return newError("wsServeAsync requires 2 arguments (port, handler)")
 	}
 
-	port, ok := args[0].(*Integer)
-	if !ok {
-		return newError("wsServeAsync: port must be an Int")
+	cfg, opts, errObj := parseWsServerConfig("wsServeAsync", args[0])
+	if errObj != nil {
+		return errObj
 	}
 
-	handler := args[1]
-	// Capture handler if CaptureHandler is available (VM mode)
-	// This ensures upvalues are closed and safe for async execution
-	if e.CaptureHandler != nil {
-		handler = e.CaptureHandler(handler)
+	// Handlers are captured (VM mode) so upvalues are closed and safe for
+	// async execution
+	handlers, errObj := parseWsHandlers(e, "wsServeAsync", args[1])
+	if errObj != nil {
+		return errObj
 	}
 
-	if !wsIsCallable(handler) {
-		return newError("wsServeAsync: handler must be a function")
+	tlsConf, err := cfg.tlsConfig(e)
+	if err != nil {
+		return makeFailStr(err.Error())
 	}
 
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port.Value))
+	listener, err := net.Listen("tcp", cfg.addr())
 	if err != nil {
 		return makeFailStr(fmt.Sprintf("listen failed: %v", err))
 	}
@@ -701,9 +1328,13 @@ func builtinWsServeAsync(e *Evaluator, args ...Object) Object {
 	srv := &wsServer{
 		listener: listener,
 		shutdown: make(chan struct{}),
-		handler:  handler,
+		handlers: handlers,
 		eval:     e,
+		config:   cfg,
+		options:  opts,
+		tls:      tlsConf,
 		running:  true,
+		conns:    make(map[int64]bool),
 	}
 
 	wsServersMu.Lock()
@@ -736,6 +1367,9 @@ func builtinWsServeAsync(e *Evaluator, args ...Object) Object {
 					_ = conn.Close()
 					continue
 				}
+				if srv.tls != nil {
+					conn = tls.Server(conn, srv.tls)
+				}
 
 				// Create a fresh evaluator/VM for each connection to ensure thread safety
 				var connEval *Evaluator
@@ -747,7 +1381,7 @@ func builtinWsServeAsync(e *Evaluator, args ...Object) Object {
 
 				go func() {
 					defer releaseWsConnSlot()
-					handleWsConnection(conn, srv.handler, connEval)
+					handleWsConnection(conn, srv, connEval)
 				}()
 			}
 		}
@@ -786,42 +1420,103 @@ func builtinWsServerStop(e *Evaluator, args ...Object) Object {
 	return makeOk(&Nil{})
 }
 
-// handleWsConnection handles a single WebSocket connection
-func handleWsConnection(conn net.Conn, handler Object, eval *Evaluator) {
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Printf("panic in WebSocket handler: %v\n", r)
-		}
-		_ = conn.Close()
-	}()
+// builtinWsServerConnections lists the open connections of an async server
+// wsServerConnections(serverId: Int) -> Result<String, List<Int>>
+func builtinWsServerConnections(e *Evaluator, args ...Object) Object {
+	if len(args) != 1 {
+		return newError("wsServerConnections requires 1 argument (serverId)")
+	}
+
+	srvID, ok := args[0].(*Integer)
+	if !ok {
+		return newError("wsServerConnections: serverId must be an Int")
+	}
+
+	wsServersMu.RLock()
+	srv, exists := wsServers[srvID.Value]
+	wsServersMu.RUnlock()
 
-	// Perform server-side handshake
+	if !exists {
+		return makeFailStr("server not found")
+	}
+
+	srv.connsMu.Lock()
+	ids := make([]int64, 0, len(srv.conns))
+	for id := range srv.conns {
+		ids = append(ids, id)
+	}
+	srv.connsMu.Unlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	elements := make([]Object, len(ids))
+	for i, id := range ids {
+		elements[i] = &Integer{Value: id}
+	}
+	return makeOk(newList(elements))
+}
+
+// wsServerHandshake answers the opening handshake of a client, negotiating
+// the subprotocol and permessage-deflate
+func wsServerHandshake(conn net.Conn, cfg *serverConfig, opts *wsOptions) (*wsConnection, bool) {
+	if cfg.readTimeout > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(cfg.readTimeout))
+	}
 	reader := bufio.NewReader(conn)
 
 	// Read HTTP request
 	requestLine, err := reader.ReadString('\n')
 	if err != nil || !strings.HasPrefix(requestLine, "GET") {
-		return
+		return nil, false
 	}
 
 	// Read headers
 	var wsKey string
+	var offeredProtocols, extensions []string
 	for {
 		line, err := reader.ReadString('\n')
 		if err != nil {
-			return
+			return nil, false
 		}
 		line = strings.TrimSpace(line)
 		if line == "" {
 			break
 		}
-		if strings.HasPrefix(strings.ToLower(line), "sec-websocket-key:") {
-			wsKey = strings.TrimSpace(strings.SplitN(line, ":", 2)[1])
+		name, value, _ := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "sec-websocket-key":
+			wsKey = value
+		case "sec-websocket-protocol":
+			for _, p := range strings.Split(value, ",") {
+				offeredProtocols = append(offeredProtocols, strings.TrimSpace(p))
+			}
+		case "sec-websocket-extensions":
+			extensions = append(extensions, value)
 		}
 	}
 
 	if wsKey == "" {
-		return
+		return nil, false
+	}
+
+	ws := newWsConnection(conn, reader, false)
+	ws.writeTimeout = cfg.writeTimeout
+	if opts.maxMessageSize > 0 {
+		ws.maxMessageSize = opts.maxMessageSize
+	}
+
+	// The first of the server's protocols the client offers
+	var extra strings.Builder
+	for _, p := range opts.protocols {
+		if containsString(offeredProtocols, p) {
+			ws.protocol = p
+			extra.WriteString("Sec-WebSocket-Protocol: " + p + "\r\n")
+			break
+		}
+	}
+	if opts.compress && wsAcceptDeflate(extensions) {
+		ws.deflate = &wsDeflate{}
+		extra.WriteString("Sec-WebSocket-Extensions: " + wsDeflateResponse + "\r\n")
 	}
 
 	// Send handshake response
@@ -830,16 +1525,35 @@ func handleWsConnection(conn net.Conn, handler Object, eval *Evaluator) {
 		"Upgrade: websocket\r\n"+
 		"Connection: Upgrade\r\n"+
 		"Sec-WebSocket-Accept: %s\r\n"+
-		"\r\n", accept)
+		"%s"+
+		"\r\n", accept, extra.String())
 
-	_, _ = conn.Write([]byte(response))
+	if cfg.writeTimeout > 0 {
+		_ = conn.SetWriteDeadline(time.Now().Add(cfg.writeTimeout))
+	}
+	if _, err := conn.Write([]byte(response)); err != nil {
+		return nil, false
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return ws, true
+}
 
-	// Create server-side connection
-	ws := &wsConnection{
-		conn:     conn,
-		isClient: false,
-		reader:   reader,
+// handleWsConnection handles a single WebSocket connection. The read timeout
+// bounds the handshake, the idle timeout the wait for each message.
+func handleWsConnection(conn net.Conn, srv *wsServer, eval *Evaluator) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("panic in WebSocket handler: %v\n", r)
+		}
+		_ = conn.Close()
+	}()
+
+	ws, ok := wsServerHandshake(conn, srv.config, srv.options)
+	if !ok {
+		return
 	}
+	ws.served = true
+	defer ws.shutdown()
 
 	// Store connection
 	wsConnectionsMu.Lock()
@@ -848,34 +1562,74 @@ func handleWsConnection(conn net.Conn, handler Object, eval *Evaluator) {
 	wsConnections[connID] = ws
 	wsConnectionsMu.Unlock()
 
+	srv.connsMu.Lock()
+	srv.conns[connID] = true
+	srv.connsMu.Unlock()
+
 	defer func() {
 		wsConnectionsMu.Lock()
 		delete(wsConnections, connID)
 		wsConnectionsMu.Unlock()
+
+		srv.connsMu.Lock()
+		delete(srv.conns, connID)
+		srv.connsMu.Unlock()
 	}()
 
+	ws.startReader()
+	if srv.options.pingInterval > 0 {
+		go ws.keepAlive(srv.options.pingInterval, srv.options.pongTimeout)
+	}
+
+	h := srv.handlers
+	id := &Integer{Value: connID}
+	call := func(fn Object, args ...Object) Object {
+		if fn == nil {
+			return nil
+		}
+		result := eval.ApplyFunction(fn, args)
+		if err, ok := result.(*Error); ok {
+			fmt.Printf("error in WebSocket handler: %s\n", err.Message)
+		}
+		return result
+	}
+
+	call(h.onOpen, id)
+
 	// Message loop
+	var failure error
 	for {
-		message, err := wsReadMessage(ws)
+		msg, ok, err := ws.receive(srv.config.idleTimeout)
 		if err != nil {
+			if err != errWsClosedByPeer && err != errWsClosed {
+				failure = err
+			}
+			break
+		}
+		if !ok {
+			failure = fmt.Errorf("idle timeout")
 			break
 		}
 
+		if h.reply == nil {
+			call(h.onMessage, id, wsMessageObject(msg))
+			continue
+		}
+
 		// Call handler: (connId, message) -> response
-		resp := eval.ApplyFunction(handler, []Object{
-			&Integer{Value: connID},
-			stringToList(message),
-		})
-
-		if resp != nil {
-			if respList, ok := resp.(*List); ok {
-				respStr := listToString(respList)
-				if respStr != "" {
-					_ = wsSendFrame(ws, wsOpText, []byte(respStr))
-				}
+		resp := call(h.reply, id, stringToList(string(msg.data)))
+		if respList, ok := resp.(*List); ok {
+			respStr := listToString(respList)
+			if respStr != "" {
+				_ = wsSendFrame(ws, wsOpText, []byte(respStr))
 			}
 		}
 	}
+
+	if failure != nil {
+		call(h.onError, id, stringToList(failure.Error()))
+	}
+	call(h.onClose, id)
 }
 
 // Helper to check if object is callable
@@ -0,0 +1,124 @@
+package evaluator
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// permessage-deflate (RFC 7692). Outgoing messages are always compressed
+// without context takeover, so any peer can read them. Incoming messages may
+// use context takeover, so the reader keeps the last 32KB of output as the
+// dictionary of the next message.
+
+const wsDeflateWindow = 32 << 10
+
+// wsDeflateTail ends a message's sync flush and adds an empty final block
+var wsDeflateTail = []byte{0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff}
+
+var wsFlateWriters = sync.Pool{New: func() any {
+	w, _ := flate.NewWriter(nil, flate.DefaultCompression)
+	return w
+}}
+
+// wsDeflate is the compression state of one connection
+type wsDeflate struct {
+	mu   sync.Mutex
+	dict []byte // recent uncompressed input, guarded by mu
+}
+
+// compress deflates one message payload
+func (d *wsDeflate) compress(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := wsFlateWriters.Get().(*flate.Writer)
+	defer wsFlateWriters.Put(w)
+	w.Reset(&buf)
+	if _, err := w.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	// The sync flush ends with 00 00 ff ff, which the peer adds back
+	return bytes.TrimSuffix(buf.Bytes(), wsDeflateTail[:4]), nil
+}
+
+// decompress inflates one message payload. A message that inflates to more
+// than max bytes fails with errWsTooBig, without inflating the rest.
+func (d *wsDeflate) decompress(payload []byte, max int64) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	src := io.MultiReader(bytes.NewReader(payload), bytes.NewReader(wsDeflateTail))
+	r := flate.NewReaderDict(src, d.dict)
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed message: %v", err)
+	}
+	if int64(len(out)) > max {
+		return nil, errWsTooBig
+	}
+
+	d.dict = append(d.dict, out...)
+	if len(d.dict) > wsDeflateWindow {
+		d.dict = append([]byte(nil), d.dict[len(d.dict)-wsDeflateWindow:]...)
+	}
+	return out, nil
+}
+
+// wsDeflateOffer is the Sec-WebSocket-Extensions header a client sends
+const wsDeflateOffer = "permessage-deflate"
+
+// wsDeflateResponse is the header a server answers an accepted offer with
+const wsDeflateResponse = "permessage-deflate; server_no_context_takeover"
+
+// wsAcceptDeflate reports whether one of the permessage-deflate offers in
+// the client's Sec-WebSocket-Extensions headers can be accepted. Offers
+// asking for a window smaller than 32KB are declined, flate cannot honour
+// them.
+func wsAcceptDeflate(headers []string) bool {
+	for _, header := range headers {
+		for _, offer := range strings.Split(header, ",") {
+			params := strings.Split(offer, ";")
+			if strings.TrimSpace(params[0]) != "permessage-deflate" {
+				continue
+			}
+			ok := true
+			for _, p := range params[1:] {
+				name, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+				switch strings.TrimSpace(name) {
+				case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+				case "server_max_window_bits":
+					ok = strings.Trim(strings.TrimSpace(value), `"`) == "15"
+				default:
+					ok = false
+				}
+			}
+			if ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wsCheckDeflateResponse validates the extensions a server agreed to
+func wsCheckDeflateResponse(header string) error {
+	params := strings.Split(header, ";")
+	if strings.TrimSpace(params[0]) != "permessage-deflate" || strings.Contains(header, ",") {
+		return fmt.Errorf("server selected unsupported extensions: %s", header)
+	}
+	for _, p := range params[1:] {
+		name, _, _ := strings.Cut(strings.TrimSpace(p), "=")
+		switch strings.TrimSpace(name) {
+		case "server_no_context_takeover", "client_no_context_takeover", "server_max_window_bits":
+		default:
+			return fmt.Errorf("server selected unsupported extensions: %s", header)
+		}
+	}
+	return nil
+}
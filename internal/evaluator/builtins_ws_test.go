@@ -0,0 +1,189 @@
+package evaluator
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWsDeflate_ContextTakeover(t *testing.T) {
+	// A peer that keeps its compression context refers back to earlier messages
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.BestCompression)
+	d := &wsDeflate{}
+	for _, msg := range []string{strings.Repeat("funxy websocket ", 20), strings.Repeat("funxy websocket ", 21)} {
+		buf.Reset()
+		_, _ = w.Write([]byte(msg))
+		_ = w.Flush()
+		payload := bytes.TrimSuffix(buf.Bytes(), []byte{0x00, 0x00, 0xff, 0xff})
+		got, err := d.decompress(payload, wsDefaultMaxMessageSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != msg {
+			t.Fatalf("decompressed %q, want %q", got, msg)
+		}
+	}
+
+	compressed, err := d.compress([]byte("hello hello hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := (&wsDeflate{}).decompress(compressed, wsDefaultMaxMessageSize); err != nil || string(got) != "hello hello hello" {
+		t.Errorf("round trip gave %q, %v", got, err)
+	}
+}
+
+func TestWsAcceptDeflate(t *testing.T) {
+	cases := map[string]bool{
+		"permessage-deflate":                                                true,
+		"permessage-deflate; client_max_window_bits":                        true,
+		"x-webkit-deflate-frame, permessage-deflate":                        true,
+		"permessage-deflate; server_max_window_bits=10":                     false,
+		"permessage-deflate; server_max_window_bits=10, permessage-deflate": true,
+		"x-webkit-deflate-frame":                                            false,
+	}
+	for header, want := range cases {
+		if got := wsAcceptDeflate([]string{header}); got != want {
+			t.Errorf("wsAcceptDeflate(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
+
+func TestWsServe_DropsDeadPeer(t *testing.T) {
+	failures := make(chan string, 1)
+	closed := make(chan bool, 1)
+	handlers := NewRecord(map[string]Object{
+		"onError": &Builtin{Name: "onError", Fn: func(e *Evaluator, args ...Object) Object {
+			failures <- ListToString(args[1].(*List))
+			return &Nil{}
+		}},
+		"onClose": &Builtin{Name: "onClose", Fn: func(e *Evaluator, args ...Object) Object {
+			closed <- true
+			return &Nil{}
+		}},
+	})
+	port := freePort(t)
+	res := builtinWsServeAsync(New(), serverConfigRecord(map[string]any{
+		"port": port, "host": "127.0.0.1", "pingInterval": 50, "pongTimeout": 100,
+	}), handlers)
+	id, ok := res.(*DataInstance)
+	if !ok || id.Name != "Ok" {
+		t.Fatalf("wsServeAsync returned %s", res.Inspect())
+	}
+	defer builtinWsServerStop(New(), id.Fields[0])
+
+	// The client completes the handshake but never reads, so pings go unanswered
+	addr := "127.0.0.1:" + itoa(port)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := wsClientHandshake(conn, &url.URL{Scheme: "ws", Host: addr, Path: "/"}, 2*time.Second, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-failures:
+		if !strings.Contains(msg, "did not answer ping") {
+			t.Errorf("onError got %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the silent peer was not dropped")
+	}
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Error("onClose was not called after onError")
+	}
+}
+
+func TestWsConnect_KeepsAnsweringPings(t *testing.T) {
+	failures := make(chan string, 1)
+	handlers := NewRecord(map[string]Object{
+		"onError": &Builtin{Name: "onError", Fn: func(e *Evaluator, args ...Object) Object {
+			failures <- ListToString(args[1].(*List))
+			return &Nil{}
+		}},
+	})
+	port := freePort(t)
+	res := builtinWsServeAsync(New(), serverConfigRecord(map[string]any{
+		"port": port, "host": "127.0.0.1", "pingInterval": 30, "pongTimeout": 100,
+	}), handlers)
+	id := res.(*DataInstance)
+	defer builtinWsServerStop(New(), id.Fields[0])
+
+	// The program does not receive, pongs are sent from the background reader
+	conn := builtinWsConnect(New(), StringToList("ws://127.0.0.1:"+itoa(port)+"/")).(*DataInstance)
+	if conn.Name != "Ok" {
+		t.Fatalf("wsConnect returned %s", conn.Inspect())
+	}
+	defer builtinWsClose(New(), conn.Fields[0])
+
+	select {
+	case msg := <-failures:
+		t.Errorf("a responsive client was dropped: %s", msg)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+func TestWsServe_ClosesOversizedCompressedMessage(t *testing.T) {
+	failures := make(chan string, 1)
+	handlers := NewRecord(map[string]Object{
+		"onError": &Builtin{Name: "onError", Fn: func(e *Evaluator, args ...Object) Object {
+			failures <- ListToString(args[1].(*List))
+			return &Nil{}
+		}},
+	})
+	port := freePort(t)
+	res := builtinWsServeAsync(New(), serverConfigRecord(map[string]any{
+		"port": port, "host": "127.0.0.1", "compress": true, "maxMessageSize": 1024,
+	}), handlers)
+	id, ok := res.(*DataInstance)
+	if !ok || id.Name != "Ok" {
+		t.Fatalf("wsServeAsync returned %s", res.Inspect())
+	}
+	defer builtinWsServerStop(New(), id.Fields[0])
+
+	addr := "127.0.0.1:" + itoa(port)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ws, err := wsClientHandshake(conn, &url.URL{Scheme: "ws", Host: addr, Path: "/"}, 2*time.Second, &wsOptions{compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ws.deflate == nil {
+		t.Fatal("permessage-deflate was not negotiated")
+	}
+
+	// A few hundred bytes on the wire that inflate to a megabyte
+	if err := wsSendFrame(ws, wsOpBinary, make([]byte, 1<<20)); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	frame := make([]byte, 4)
+	if _, err := io.ReadFull(ws.reader, frame); err != nil {
+		t.Fatalf("no close frame: %v", err)
+	}
+	if frame[0] != 0x80|wsOpClose || int(frame[2])<<8|int(frame[3]) != 1009 {
+		t.Errorf("got frame % x, want a close with status 1009", frame)
+	}
+	select {
+	case msg := <-failures:
+		if !strings.Contains(msg, "message too big") {
+			t.Errorf("onError got %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("onError was not called")
+	}
+}
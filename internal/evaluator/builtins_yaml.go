@@ -5,20 +5,28 @@ import (
 	"os"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/funvibe/funxy/internal/typesystem"
 )
 
 // YAML encoding/decoding functions for lib/yaml
 
-// yamlDecode parses a YAML string into Funxy values.
-// Maps become Records, sequences become Lists, scalars become
-// Int/Float/Bool/String/Nil as appropriate.
-func yamlDecode(content string, e *Evaluator) (Object, error) {
+// yamlDecode parses a YAML string into Funxy values of type t.
+// Without a type, maps become Records, sequences become Lists, scalars
+// become Int/Float/Bool/String/Nil as appropriate.
+func yamlDecode(content string, t typesystem.Type, e *Evaluator) (Object, error) {
 	var data interface{}
 	if err := yaml.Unmarshal([]byte(content), &data); err != nil {
 		return nil, fmt.Errorf("YAML parse error: %v", err)
 	}
 
-	result, err := inferFromYaml(data)
+	var result Object
+	var err error
+	if t != nil {
+		result, err = decodeTyped(e, data, t, inferFromYaml)
+	} else {
+		result, err = inferFromYaml(data)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -97,13 +105,13 @@ func yamlEncode(obj Object) (string, error) {
 }
 
 // yamlRead reads and parses a YAML file.
-func yamlRead(path string, e *Evaluator) (Object, error) {
+func yamlRead(path string, t typesystem.Type, e *Evaluator) (Object, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return makeFailStr(fmt.Sprintf("Cannot read file: %v", err)), nil
 	}
 
-	return yamlDecode(string(content), e)
+	return yamlDecode(string(content), t, e)
 }
 
 // yamlWrite writes a Funxy value to a YAML file.
@@ -135,6 +143,7 @@ func YamlBuiltins() map[string]*Builtin {
 // Builtin function implementations
 
 func builtinYamlDecode(e *Evaluator, args ...Object) Object {
+	target, args := decodeTarget(args)
 	if len(args) != 1 {
 		return newError("yamlDecode(content: String)")
 	}
@@ -142,7 +151,7 @@ func builtinYamlDecode(e *Evaluator, args ...Object) Object {
 	if !ok {
 		return newError("yamlDecode: argument must be String")
 	}
-	result, err := yamlDecode(listToString(list), e)
+	result, err := yamlDecode(listToString(list), target, e)
 	if err != nil {
 		return makeFailStr(err.Error())
 	}
@@ -161,6 +170,7 @@ func builtinYamlEncode(e *Evaluator, args ...Object) Object {
 }
 
 func builtinYamlRead(e *Evaluator, args ...Object) Object {
+	target, args := decodeTarget(args)
 	if len(args) != 1 {
 		return newError("yamlRead(path: String)")
 	}
@@ -168,7 +178,7 @@ func builtinYamlRead(e *Evaluator, args ...Object) Object {
 	if !ok {
 		return newError("yamlRead: argument must be String")
 	}
-	result, err := yamlRead(listToString(list), e)
+	result, err := yamlRead(listToString(list), target, e)
 	if err != nil {
 		return makeFailStr(err.Error())
 	}
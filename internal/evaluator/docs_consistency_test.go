@@ -62,6 +62,7 @@ func TestVirtualModulesConsistency(t *testing.T) {
 		"lib/http", "lib/regex", "lib/crypto", "lib/json", "lib/char",
 		"lib/bignum", "lib/tuple", "lib/sys", "lib/io", "lib/bytes",
 		"lib/bits", "lib/map", "lib/yaml", "lib/vmm", "lib/rpc", "lib/termio",
+		"lib/jsonschema",
 	}
 
 	for _, pkgPath := range pkgNames {
@@ -88,6 +89,12 @@ func TestVirtualModulesConsistency(t *testing.T) {
 				found = true
 			} else if _, ok := vp.Constructors[name]; ok {
 				found = true
+			} else {
+				for _, trait := range vp.Traits {
+					if _, ok := trait.Methods[name]; ok {
+						found = true
+					}
+				}
 			}
 			if !found {
 				t.Errorf("Function %q implemented in %s but not defined in virtual package symbols, types or constructors", name, pkgPath)
@@ -120,5 +127,14 @@ func TestVirtualModulesConsistency(t *testing.T) {
 				t.Errorf("Constructor %q defined in %s constructors but missing implementation", name, pkgPath)
 			}
 		}
+
+		// Check trait methods are implemented
+		for traitName, trait := range vp.Traits {
+			for name := range trait.Methods {
+				if impls.Get(name) == nil {
+					t.Errorf("Method %q of trait %s in %s is missing implementation", name, traitName, pkgPath)
+				}
+			}
+		}
 	}
 }
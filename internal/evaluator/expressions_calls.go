@@ -78,13 +78,19 @@ func (e *Evaluator) evalCallExpression(node *ast.CallExpression, env *Environmen
 			return args[0]
 		}
 
-		// Prepend witnesses to args, but not for ClassMethod or Builtin (they handle their own arity)
+		// Prepend witnesses to args unless the target is a Builtin, as for non-tail calls below.
 		if len(witnessArgs) > 0 {
-			if _, ok := function.(*ClassMethod); !ok {
-				if _, ok := function.(*Builtin); !ok {
-					args = append(witnessArgs, args...)
-				}
+			if _, ok := function.(*Builtin); !ok {
+				args = append(witnessArgs, args...)
+			}
+		}
+
+		if node.TypeArgs != nil {
+			typeArgObjects := make([]Object, len(node.TypeArgs))
+			for i, typeArg := range node.TypeArgs {
+				typeArgObjects[i] = &TypeObject{TypeVal: typeArg}
 			}
+			args = append(typeArgObjects, args...)
 		}
 
 		// For tail call, we need to preserve the witness context.
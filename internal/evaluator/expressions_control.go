@@ -372,6 +372,13 @@ func (e *Evaluator) matchPattern(pat ast.Pattern, val Object, env *Environment)
 				bindings[bk] = bv
 			}
 		}
+		if p.Rest != nil && p.Rest.Value != "_" {
+			matchedKeys := make(map[string]bool, len(p.Fields))
+			for k := range p.Fields {
+				matchedKeys[k] = true
+			}
+			bindings[p.Rest.Value] = recordVal.Without(matchedKeys)
+		}
 		return true, bindings
 
 	case *ast.TypePattern:
@@ -500,6 +507,15 @@ func (e *Evaluator) matchesDirectType(val Object, typeName string) bool {
 	case "List":
 		_, ok := val.(*List)
 		return ok
+	case "Bytes":
+		_, ok := val.(*Bytes)
+		return ok
+	case "Bits":
+		_, ok := val.(*Bits)
+		return ok
+	case "Map":
+		_, ok := val.(*Map)
+		return ok
 	case "Option":
 		// Option<T> is Some(T) or None
 		if di, ok := val.(*DataInstance); ok {
@@ -396,12 +396,8 @@ func (e *Evaluator) evalRecordLiteral(node *ast.RecordLiteral, env *Environment)
 }
 
 func (e *Evaluator) evalStringLiteral(node *ast.StringLiteral, env *Environment) Object {
-	var elements []Object
-	for _, r := range node.Value {
-		elements = append(elements, &Char{Value: int64(r)})
-	}
 	// Strings are always List<Char>
-	return newListWithType(elements, "Char")
+	return StringToList(node.Value)
 }
 
 func (e *Evaluator) evalFormatStringLiteral(node *ast.FormatStringLiteral, env *Environment) Object {
@@ -436,7 +432,7 @@ func (e *Evaluator) evalFormatStringLiteral(node *ast.FormatStringLiteral, env *
 }
 
 func (e *Evaluator) evalInterpolatedString(node *ast.InterpolatedString, env *Environment) Object {
-	var result []Object
+	var sb strings.Builder
 
 	for _, part := range node.Parts {
 		val := e.Eval(part, env)
@@ -444,23 +440,21 @@ func (e *Evaluator) evalInterpolatedString(node *ast.InterpolatedString, env *En
 			return val
 		}
 
-		// Convert value to string (List<Char>)
-		chars := e.objectToChars(val)
-		result = append(result, chars...)
+		sb.WriteString(e.objectToText(val))
 	}
 
-	return newListWithType(result, "Char")
+	return StringToList(sb.String())
 }
 
-// objectToChars converts any object to its string representation as []Object of Char
-func (e *Evaluator) objectToChars(obj Object) []Object {
+// objectToText converts any object to its string representation for interpolation
+func (e *Evaluator) objectToText(obj Object) string {
 	var str string
 
 	switch o := obj.(type) {
 	case *List:
 		// If it's already a string (List<Char>), extract it
 		if o.ElementType == "Char" {
-			return o.ToSlice()
+			return ListToString(o)
 		}
 		// Otherwise use Inspect
 		str = o.Inspect()
@@ -476,11 +470,7 @@ func (e *Evaluator) objectToChars(obj Object) []Object {
 		str = obj.Inspect()
 	}
 
-	var result []Object
-	for _, r := range str {
-		result = append(result, &Char{Value: int64(r)})
-	}
-	return result
+	return str
 }
 
 func (e *Evaluator) evalCharLiteral(node *ast.CharLiteral, env *Environment) Object {
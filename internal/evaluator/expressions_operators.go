@@ -691,6 +691,9 @@ func (e *Evaluator) evalListInfixExpression(operator string, left, right Object)
 // compareListsLexicographic compares two lists lexicographically
 // Returns -1 if left < right, 0 if equal, 1 if left > right
 func (e *Evaluator) compareListsLexicographic(left, right *List) int {
+	if cmp, ok := CompareStrings(left, right); ok {
+		return cmp
+	}
 	minLen := left.len()
 	if right.len() < minLen {
 		minLen = right.len()
@@ -316,6 +316,11 @@ func decodeFDF(buf *bytes.Reader) (Object, error) {
 			}
 			elements = append(elements, el)
 		}
+		if elementType == "Char" {
+			if text, ok := charsToString(elements); ok {
+				return StringToList(text), nil
+			}
+		}
 		list := newList(elements)
 		list.ElementType = elementType
 		return list, nil
@@ -466,6 +471,7 @@ const (
 	fdfTypeTUnion
 	fdfTypeTType
 	fdfTypeTForall
+	fdfTypeTLambda
 )
 
 func encodeFDFKind(buf *bytes.Buffer, k typesystem.Kind) error {
@@ -713,6 +719,19 @@ func encodeFDFType(buf *bytes.Buffer, t typesystem.Type) error {
 		if err := encodeFDFType(buf, v.Type); err != nil {
 			return err
 		}
+	case typesystem.TLambda:
+		buf.WriteByte(fdfTypeTLambda)
+		if err := binary.Write(buf, binary.BigEndian, uint32(len(v.Params))); err != nil {
+			return err
+		}
+		for _, p := range v.Params {
+			if err := encodeFDFType(buf, p); err != nil {
+				return err
+			}
+		}
+		if err := encodeFDFType(buf, v.Body); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("FDF unsupported typesystem.Type: %T", v)
 	}
@@ -913,6 +932,28 @@ func decodeFDFType(buf *bytes.Reader) (typesystem.Type, error) {
 			Constraints: constraints,
 			Type:        inner,
 		}, nil
+	case fdfTypeTLambda:
+		l, err := readBoundedLen32(buf, maxFDFCollectionSize, "type lambda params count")
+		if err != nil {
+			return nil, err
+		}
+		params := make([]typesystem.TVar, l)
+		for i := 0; i < int(l); i++ {
+			p, err := decodeFDFType(buf)
+			if err != nil {
+				return nil, err
+			}
+			tv, ok := p.(typesystem.TVar)
+			if !ok {
+				return nil, fmt.Errorf("FDF decoding error: type lambda parameter is %T", p)
+			}
+			params[i] = tv
+		}
+		body, err := decodeFDFType(buf)
+		if err != nil {
+			return nil, err
+		}
+		return typesystem.TLambda{Params: params, Body: body}, nil
 	default:
 		return nil, fmt.Errorf("FDF decoding error: unknown typesystem tag %d", tag)
 	}
@@ -142,6 +142,16 @@ func TestFDF_TypesSerialization(t *testing.T) {
 			},
 			Type: typesystem.TVar{Name: "a", KindVal: typesystem.KStar{}},
 		},
+		typesystem.TLambda{
+			Params: []typesystem.TVar{{Name: "_0", KindVal: typesystem.KStar{}}},
+			Body: typesystem.TApp{
+				Constructor: typesystem.TCon{Name: "Pair", KindVal: typesystem.MakeArrow(typesystem.Star, typesystem.Star, typesystem.Star)},
+				Args: []typesystem.Type{
+					typesystem.TVar{Name: "_0", KindVal: typesystem.KStar{}},
+					typesystem.TCon{Name: "String", KindVal: typesystem.KStar{}},
+				},
+			},
+		},
 	}
 
 	for i, typ := range types {
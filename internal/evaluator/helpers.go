@@ -338,6 +338,8 @@ func (e *Evaluator) areObjectsEqual(a, b Object) bool {
 		return a.equals(b.(*Map), e)
 	case *Uuid:
 		return a.Value == b.(*Uuid).Value
+	case *Zone:
+		return a.Location.String() == b.(*Zone).Location.String()
 	}
 	return false
 }
@@ -61,6 +61,14 @@ type Object interface {
 	Hash() uint32
 }
 
+// Iterator is implemented by host objects that produce their elements one at
+// a time, such as network streams, so that for-in loops can consume them.
+// Next returns Some(element), None at the end, or an Error.
+type Iterator interface {
+	Object
+	Next() Object
+}
+
 // Helper for hashing strings
 func hashString(s string) uint32 {
 	h := fnv.New32a()
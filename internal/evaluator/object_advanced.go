@@ -123,6 +123,19 @@ func (r *RecordInstance) Put(key string, val Object) *RecordInstance {
 	return &RecordInstance{Fields: newFields, TypeName: r.TypeName, ModuleName: r.ModuleName}
 }
 
+// Without returns a new RecordInstance without the given keys, as bound by a
+// record rest pattern. The result is structural: it no longer has the fields
+// of a nominal type.
+func (r *RecordInstance) Without(keys map[string]bool) *RecordInstance {
+	newFields := make([]RecordField, 0, len(r.Fields))
+	for _, field := range r.Fields {
+		if !keys[field.Key] {
+			newFields = append(newFields, field)
+		}
+	}
+	return &RecordInstance{Fields: newFields}
+}
+
 func (r *RecordInstance) Type() ObjectType { return RECORD_OBJ }
 func (r *RecordInstance) Inspect() string {
 	var out bytes.Buffer
@@ -48,9 +48,11 @@ func (t *Tuple) Hash() uint32 {
 // List represents a homogeneous (in principle, though runtime allows heterogenous) immutable collection.
 // It uses a hybrid representation:
 // - If vector is non-nil, it relies on PersistentVector (O(1) append, O(1) index).
-// - If vector is nil, it acts as a Cons list (head/tail) (O(1) prepend).
+// - If str is non-nil, it is a String packed as UTF-8 (O(1) length, index and slice).
+// - Otherwise, it acts as a Cons list (head/tail) (O(1) prepend).
 type List struct {
 	vector      *PersistentVector
+	str         *packedString
 	head        Object
 	tail        *List
 	length      int    // Cached length for Cons lists (vector tracks its own length)
@@ -93,9 +95,17 @@ func (l *List) len() int {
 	if l.vector != nil {
 		return l.vector.Len()
 	}
+	if l.str != nil {
+		return l.str.n
+	}
 	return l.length
 }
 
+// isCons reports whether the list is a Cons cell rather than a vector or packed string
+func (l *List) isCons() bool {
+	return l.vector == nil && l.str == nil
+}
+
 // Len returns the number of elements (exported for VM)
 func (l *List) Len() int {
 	return l.len()
@@ -110,11 +120,14 @@ func (l *List) get(i int) Object {
 	if l.vector != nil {
 		return l.vector.Get(i)
 	}
+	if l.str != nil {
+		return l.str.get(i)
+	}
 
 	// Traversal for Cons
 	curr := l
 	idx := i
-	for curr != nil && curr.vector == nil {
+	for curr != nil && curr.isCons() {
 		if idx == 0 {
 			return curr.head
 		}
@@ -125,8 +138,8 @@ func (l *List) get(i int) Object {
 	if curr == nil {
 		return nil
 	}
-	// We reached a vector part of the list
-	return curr.vector.Get(idx)
+	// We reached a vector or packed part of the list
+	return curr.get(idx)
 }
 
 // Get returns the element at index i (exported for VM)
@@ -158,15 +171,18 @@ func (l *List) ToSlice() []Object {
 	if l.vector != nil {
 		return l.vector.ToSlice()
 	}
+	if l.str != nil {
+		return l.str.chars()
+	}
 
 	result := make([]Object, 0, l.len())
 	curr := l
-	for curr != nil && curr.vector == nil {
+	for curr != nil && curr.isCons() {
 		result = append(result, curr.head)
 		curr = curr.tail
 	}
-	if curr != nil && curr.vector != nil {
-		result = append(result, curr.vector.ToSlice()...)
+	if curr != nil {
+		result = append(result, curr.ToSlice()...)
 	}
 	return result
 }
@@ -182,6 +198,9 @@ func (l *List) Slice(start, end int) *List {
 	if start < 0 || end > length || start > end {
 		panic(fmt.Sprintf("slice bounds out of range: [%d:%d] length=%d", start, end, length))
 	}
+	if l.str != nil {
+		return &List{str: l.str.slice(start, end), ElementType: l.ElementType}
+	}
 
 	// Optimization: tail slicing
 	if end == length {
@@ -226,6 +245,22 @@ func (l *List) concat(other *List) *List {
 		return &List{vector: l.vector.Concat(other.vector), ElementType: l.ElementType}
 	}
 
+	// Strings stay packed
+	if l.str != nil || other.str != nil {
+		if l.len() == 0 {
+			return other
+		}
+		if other.len() == 0 {
+			return l
+		}
+		left, ok := packedText(l)
+		if ok {
+			if right, ok := packedText(other); ok {
+				return &List{str: newPackedString(left + right), ElementType: "Char"}
+			}
+		}
+	}
+
 	// Fallback: convert to slice and create new Vector-based list
 	result := make([]Object, 0, l.len()+other.len())
 	result = append(result, l.ToSlice()...)
@@ -239,6 +274,9 @@ func (l *List) Concat(other *List) *List {
 }
 
 func (l *List) Inspect() string {
+	if l.str != nil && l.str.n > 0 {
+		return "\"" + l.str.s + "\""
+	}
 	// Heuristic: If all elements are chars, print as string
 	if l.len() > 0 {
 		allChars := true
@@ -293,6 +331,9 @@ func (l *List) RuntimeType() typesystem.Type {
 }
 
 func (l *List) Hash() uint32 {
+	if l.str != nil {
+		return l.str.hash()
+	}
 	h := uint32(1)
 	for _, obj := range l.ToSlice() {
 		h = 31*h + obj.Hash()
@@ -331,7 +372,13 @@ func (l *List) GobDecode(data []byte) error {
 	if err := dec.Decode(&gobList); err != nil {
 		return err
 	}
-	// Reconstruct the list
+	// Reconstruct the list, packing strings again
+	if gobList.ElementType == "Char" {
+		if text, ok := charsToString(gobList.Elements); ok {
+			*l = List{str: newPackedString(text), ElementType: "Char"}
+			return nil
+		}
+	}
 	newList := NewList(gobList.Elements)
 	newList.ElementType = gobList.ElementType
 	*l = *newList
@@ -0,0 +1,137 @@
+package evaluator
+
+import (
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// packedString is the storage of a List built from text: the characters are
+// kept as contiguous UTF-8 instead of one Char object each. The List keeps
+// list semantics on top of it; Chars are only created when elements are read.
+type packedString struct {
+	s string
+	n int // Number of characters; equal to len(s) for ASCII text
+	// Byte offsets of the characters, built on the first random access to
+	// non-ASCII text and shared with the slices taken afterwards.
+	index atomic.Pointer[runeIndex]
+}
+
+// runeIndex maps character positions to byte offsets. offsets[i] - base is
+// the byte offset of character i, and offsets has one extra entry for the end.
+type runeIndex struct {
+	offsets []int32
+	base    int32
+}
+
+// newPackedString packs s. Invalid UTF-8 is replaced the way ranging over a
+// string decodes it, one U+FFFD per bad byte, so that the packed text and its
+// Chars always agree.
+func newPackedString(s string) *packedString {
+	if !utf8.ValidString(s) {
+		s = string([]rune(s))
+	}
+	return &packedString{s: s, n: utf8.RuneCountInString(s)}
+}
+
+func (p *packedString) ascii() bool {
+	return p.n == len(p.s)
+}
+
+func (p *packedString) runeIndex() *runeIndex {
+	if idx := p.index.Load(); idx != nil {
+		return idx
+	}
+	offsets := make([]int32, 0, p.n+1)
+	for i := range p.s {
+		offsets = append(offsets, int32(i))
+	}
+	offsets = append(offsets, int32(len(p.s)))
+	idx := &runeIndex{offsets: offsets}
+	p.index.Store(idx)
+	return idx
+}
+
+// offset returns the byte offset of character i.
+func (p *packedString) offset(i int) int {
+	if p.ascii() {
+		return i
+	}
+	idx := p.runeIndex()
+	return int(idx.offsets[i] - idx.base)
+}
+
+func (p *packedString) get(i int) Object {
+	if p.ascii() {
+		return smallChars[p.s[i]]
+	}
+	r, _ := utf8.DecodeRuneInString(p.s[p.offset(i):])
+	return charObject(r)
+}
+
+// slice returns characters start to end (exclusive) without copying the text.
+func (p *packedString) slice(start, end int) *packedString {
+	if p.ascii() {
+		return &packedString{s: p.s[start:end], n: end - start}
+	}
+	idx := p.runeIndex()
+	sub := &packedString{s: p.s[p.offset(start):p.offset(end)], n: end - start}
+	sub.index.Store(&runeIndex{offsets: idx.offsets[start : end+1], base: idx.offsets[start]})
+	return sub
+}
+
+func (p *packedString) chars() []Object {
+	chars := make([]Object, 0, p.n)
+	for _, r := range p.s {
+		chars = append(chars, charObject(r))
+	}
+	return chars
+}
+
+func (p *packedString) hash() uint32 {
+	h := uint32(1)
+	for _, r := range p.s {
+		h = 31*h + uint32(r)
+	}
+	return h
+}
+
+// charObject returns the Char for r, shared for the first 256 code points.
+func charObject(r rune) *Char {
+	if r >= 0 && r < 256 {
+		return smallChars[r]
+	}
+	return &Char{Value: int64(r)}
+}
+
+// charsToString returns the text of elements when they are all Chars that
+// UTF-8 can represent.
+func charsToString(elements []Object) (string, bool) {
+	var sb strings.Builder
+	sb.Grow(len(elements))
+	for _, el := range elements {
+		c, ok := el.(*Char)
+		if !ok || c.Value > utf8.MaxRune || !utf8.ValidRune(rune(c.Value)) {
+			return "", false
+		}
+		sb.WriteRune(rune(c.Value))
+	}
+	return sb.String(), true
+}
+
+// packedText returns the text of a list that is a string, packed or not.
+func packedText(l *List) (string, bool) {
+	if l.str != nil {
+		return l.str.s, true
+	}
+	return charsToString(l.ToSlice())
+}
+
+// CompareStrings compares two packed strings by code points, which is their
+// UTF-8 byte order. It reports false unless both lists are packed strings.
+func CompareStrings(a, b *List) (int, bool) {
+	if a.str == nil || b.str == nil {
+		return 0, false
+	}
+	return strings.Compare(a.str.s, b.str.s), true
+}
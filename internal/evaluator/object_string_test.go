@@ -0,0 +1,89 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+)
+
+// unpacked builds the same string as a vector of Chars.
+func unpacked(s string) *List {
+	var chars []Object
+	for _, r := range s {
+		chars = append(chars, &Char{Value: int64(r)})
+	}
+	return newListWithType(chars, "Char")
+}
+
+func TestPackedString_MatchesCharList(t *testing.T) {
+	for _, s := range []string{"", "hello", "héllo, 世界", "áb"} {
+		packed, plain := StringToList(s), unpacked(s)
+		if packed.len() != plain.len() {
+			t.Fatalf("%q: length %d, want %d", s, packed.len(), plain.len())
+		}
+		for i := 0; i < plain.len(); i++ {
+			if !ObjectsEqual(packed.get(i), plain.get(i)) {
+				t.Errorf("%q[%d] = %s, want %s", s, i, packed.get(i).Inspect(), plain.get(i).Inspect())
+			}
+		}
+		if packed.Hash() != plain.Hash() {
+			t.Errorf("%q: hash differs from the Char list", s)
+		}
+		if !ObjectsEqual(packed, plain) || !ObjectsEqual(plain, packed) {
+			t.Errorf("%q: not equal to the Char list", s)
+		}
+	}
+}
+
+func TestPackedString_SlicesShareIndex(t *testing.T) {
+	l := StringToList("añbçdé")
+	rest := l.Slice(1, 6).Slice(1, 5).Slice(1, 3)
+	if got := ListToString(rest); got != "çd" {
+		t.Fatalf("slice = %q, want %q", got, "çd")
+	}
+	if rest.str == nil || rest.str.index.Load() == nil {
+		t.Fatal("slice of non-ASCII text should stay packed and reuse the index")
+	}
+	if c := rest.get(0).(*Char); c.Value != 'ç' {
+		t.Errorf("rest[0] = %c, want ç", rune(c.Value))
+	}
+}
+
+func TestPackedString_Concat(t *testing.T) {
+	l := StringToList("añ").Concat(unpacked("bç"))
+	if l.str == nil || ListToString(l) != "añbç" {
+		t.Errorf("string ++ Char list should stay packed, got %s", l.Inspect())
+	}
+	mixed := StringToList("a").Concat(newList([]Object{&Integer{Value: 1}}))
+	if mixed.str != nil || mixed.len() != 2 {
+		t.Errorf("string ++ Int list should be a plain list, got %s", mixed.Inspect())
+	}
+	if StringToList("ab").Concat(StringToList("")).str == nil {
+		t.Error("concatenating an empty string should keep the packed string")
+	}
+}
+
+func TestPackedString_InvalidUTF8(t *testing.T) {
+	s := "a\xffb"
+	if got, want := StringToList(s).len(), unpacked(s).len(); got != want {
+		t.Errorf("length %d, want %d", got, want)
+	}
+	if !ObjectsEqual(StringToList(s), unpacked(s)) {
+		t.Error("invalid bytes should decode like ranging over the string")
+	}
+}
+
+func TestPackedString_Gob(t *testing.T) {
+	ensureGobBenchTypesRegistered()
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(StringToList("héllo")); err != nil {
+		t.Fatal(err)
+	}
+	var l List
+	if err := gob.NewDecoder(&buf).Decode(&l); err != nil {
+		t.Fatal(err)
+	}
+	if l.str == nil || ListToString(&l) != "héllo" {
+		t.Errorf("decoded %s, want a packed \"héllo\"", l.Inspect())
+	}
+}
@@ -43,6 +43,9 @@ func ObjectsEqual(a, b Object) bool {
 			if aVal.Len() != bVal.Len() {
 				return false
 			}
+			if aVal.str != nil && bVal.str != nil {
+				return aVal.str.s == bVal.str.s
+			}
 			aSlice := aVal.ToSlice()
 			bSlice := bVal.ToSlice()
 			for i := range aSlice {
@@ -126,6 +129,10 @@ func ObjectsEqual(a, b Object) bool {
 		if bVal, ok := b.(*Uuid); ok {
 			return aVal.Value == bVal.Value
 		}
+	case *Zone:
+		if bVal, ok := b.(*Zone); ok {
+			return aVal.Location.String() == bVal.Location.String()
+		}
 	case *TypeObject:
 		if bVal, ok := b.(*TypeObject); ok {
 			return aVal.TypeVal.String() == bVal.TypeVal.String()
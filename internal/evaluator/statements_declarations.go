@@ -20,7 +20,7 @@ func (e *Evaluator) evalTypeDeclaration(node *ast.TypeDeclarationStatement, env
 		}
 		underlyingType := analyzer.BuildType(node.TargetType, nil, nil)
 		e.TypeAliases[node.Name.Value] = underlyingType
-		return &Nil{}
+		return e.evalDerivedInstances(node, env)
 	}
 
 	for _, c := range node.Constructors {
@@ -30,6 +30,17 @@ func (e *Evaluator) evalTypeDeclaration(node *ast.TypeDeclarationStatement, env
 			env.Set(c.Name.Value, &Constructor{Name: c.Name.Value, TypeName: node.Name.Value, Arity: len(c.Parameters)})
 		}
 	}
+	return e.evalDerivedInstances(node, env)
+}
+
+// evalDerivedInstances registers the instances the analyzer derived for the
+// type's deriving clause.
+func (e *Evaluator) evalDerivedInstances(node *ast.TypeDeclarationStatement, env *Environment) Object {
+	for _, inst := range node.Derived {
+		if res := e.evalInstanceDeclaration(inst, env); isError(res) {
+			return res
+		}
+	}
 	return &Nil{}
 }
 
@@ -113,11 +124,16 @@ func (e *Evaluator) evalInstanceDeclaration(node *ast.InstanceDeclaration, env *
 		// MPTC: Combine all arg types into a key "Type1_Type2"
 		// This matches the lookup strategy we will implement
 		var typeNames []string
-		for _, arg := range node.Args {
+		for i, arg := range node.Args {
 			typeName, err := e.resolveCanonicalTypeName(arg, env)
 			if err != nil {
 				return newError("%s", err.Error())
 			}
+			// A head with holes keeps them, so that the argument's constructor
+			// alone does not select it; calls pass its dictionary instead
+			if i == 0 && node.AnalyzedHeadName != "" {
+				typeName = node.AnalyzedHeadName
+			}
 			typeNames = append(typeNames, typeName)
 		}
 		typeKey = strings.Join(typeNames, "_")
@@ -160,6 +176,9 @@ func (e *Evaluator) evalInstanceDeclaration(node *ast.InstanceDeclaration, env *
 				}
 			}
 		}
+		if node.AnalyzedHeadName != "" {
+			typeName = node.AnalyzedHeadName
+		}
 
 		evidenceName := analyzer.GetDictionaryConstructorName(className, typeName)
 
@@ -40,9 +40,14 @@ func (e *Evaluator) evalForExpression(node *ast.ForExpression, env *Environment)
 
 		var iteratorFn Object
 
-		// Look up iter method from Iter trait implementation for this type
+		// Host iterators produce their elements themselves. Otherwise look up
+		// iter method from Iter trait implementation for this type
 		iterableTypeName := getRuntimeTypeName(iterable)
-		if iterMethod, found := e.lookupTraitMethod(config.IterTraitName, config.IterMethodName, iterableTypeName); found {
+		if it, ok := iterable.(Iterator); ok {
+			iteratorFn = &Builtin{Name: "next", Fn: func(_ *Evaluator, _ ...Object) Object {
+				return it.Next()
+			}}
+		} else if iterMethod, found := e.lookupTraitMethod(config.IterTraitName, config.IterMethodName, iterableTypeName); found {
 			res := e.ApplyFunction(iterMethod, []Object{iterable})
 			if !isError(res) {
 				iteratorFn = res
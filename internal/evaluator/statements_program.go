@@ -172,6 +172,18 @@ func (e *Evaluator) importVirtualModule(node *ast.ImportStatement, mod *modules.
 
 	if len(node.Symbols) > 0 {
 		for _, sym := range node.Symbols {
+			// A trait has no value of its own; importing it imports its methods
+			if pkg := modules.GetVirtualPackage("lib/" + mod.Name); pkg != nil {
+				if trait, ok := pkg.Traits[sym.Value]; ok {
+					for methodName := range trait.Methods {
+						if method := builtins.Get(methodName); method != nil {
+							env.Set(methodName, method)
+						}
+					}
+					continue
+				}
+			}
+
 			if fn := builtins.Get(sym.Value); fn != nil {
 				env.Set(sym.Value, fn)
 
@@ -269,6 +281,7 @@ func GetVirtualModuleBuiltins(name string) *StringMap {
 		return env.GetStore()
 	case "crypto":
 		builtins = CryptoBuiltins()
+		env.Set("hash", hashClassMethod())
 	case "regex":
 		builtins = RegexBuiltins()
 	case "http":
@@ -301,6 +314,10 @@ func GetVirtualModuleBuiltins(name string) *StringMap {
 		builtins = CsvBuiltins()
 	case "yaml":
 		builtins = YamlBuiltins()
+	case "jsonschema":
+		RegisterJsonSchemaBuiltins(env)
+		applyVirtualPackageTypes("jsonschema", env.GetStore())
+		return env.GetStore()
 	case "flag":
 		builtins = FlagBuiltins()
 	case "option":
@@ -308,7 +325,9 @@ func GetVirtualModuleBuiltins(name string) *StringMap {
 	case "result":
 		builtins = ResultBuiltins()
 	case "grpc":
-		builtins = GrpcBuiltins()
+		RegisterGrpcBuiltins(env)
+		applyVirtualPackageTypes("grpc", env.GetStore())
+		return env.GetStore()
 	case "proto":
 		builtins = ProtoBuiltins()
 	case "term":
@@ -337,8 +356,8 @@ func GetVirtualModuleBuiltins(name string) *StringMap {
 			env.Set("Logger", &TypeObject{TypeVal: typesystem.TCon{Name: "Logger"}})
 		} else if name == "uuid" {
 			env.Set("Uuid", &TypeObject{TypeVal: typesystem.TCon{Name: "Uuid"}})
-		} else if name == "http" {
-			if vp := modules.GetVirtualPackage("lib/http"); vp != nil {
+		} else if name == "http" || name == "sys" || name == "ws" {
+			if vp := modules.GetVirtualPackage("lib/" + name); vp != nil {
 				for typeName, typ := range vp.Types {
 					env.Set(typeName, &TypeObject{TypeVal: typ})
 				}
@@ -24,6 +24,15 @@ func New(input string) *Lexer {
 	return l
 }
 
+// Offset returns the byte offset of the character under examination.
+// Right after NextToken it is the offset just past the returned token.
+func (l *Lexer) Offset() int {
+	if l.position > len(l.input) {
+		return len(l.input)
+	}
+	return l.position
+}
+
 func (l *Lexer) readChar() {
 	if l.ch == '\n' {
 		l.line++
@@ -294,19 +303,20 @@ func (l *Lexer) NextToken() token.Token {
 	case '\\':
 		tok = newToken(token.BACKSLASH, l.ch, l.line, l.column)
 	case '@':
+		startLine, startCol := l.line, l.column
 		// @"...", @x"...", @b"..." - Bytes literals
 		if l.peekChar() == '"' {
 			// @"..." - UTF-8 bytes literal
 			l.readChar() // consume @, now at "
 			content := l.readString()
-			tok = token.Token{Type: token.BYTES_STRING, Lexeme: fmt.Sprintf("@%q", content), Literal: content, Line: l.line, Column: l.column}
+			tok = token.Token{Type: token.BYTES_STRING, Lexeme: fmt.Sprintf("@%q", content), Literal: content, Line: startLine, Column: startCol}
 		} else if l.peekChar() == 'x' {
 			// Check for @x"..."
 			l.readChar() // consume @, now at x
 			if l.peekChar() == '"' {
 				l.readChar() // consume x, now at "
 				content := l.readString()
-				tok = token.Token{Type: token.BYTES_HEX, Lexeme: fmt.Sprintf("@x%q", content), Literal: content, Line: l.line, Column: l.column}
+				tok = token.Token{Type: token.BYTES_HEX, Lexeme: fmt.Sprintf("@x%q", content), Literal: content, Line: startLine, Column: startCol}
 			} else {
 				// @x without " is illegal
 				tok = newToken(token.ILLEGAL, l.ch, l.line, l.column)
@@ -317,7 +327,7 @@ func (l *Lexer) NextToken() token.Token {
 			if l.peekChar() == '"' {
 				l.readChar() // consume b, now at "
 				content := l.readString()
-				tok = token.Token{Type: token.BYTES_BIN, Lexeme: fmt.Sprintf("@b%q", content), Literal: content, Line: l.line, Column: l.column}
+				tok = token.Token{Type: token.BYTES_BIN, Lexeme: fmt.Sprintf("@b%q", content), Literal: content, Line: startLine, Column: startCol}
 			} else {
 				// @b without " is illegal
 				tok = newToken(token.ILLEGAL, l.ch, l.line, l.column)
@@ -327,6 +337,7 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.ILLEGAL, l.ch, l.line, l.column)
 		}
 	case '#':
+		startLine, startCol := l.line, l.column
 		// #b"...", #x"...", #o"..." - Bits literals
 		if l.peekChar() == 'b' {
 			// Check for #b"..."
@@ -334,7 +345,7 @@ func (l *Lexer) NextToken() token.Token {
 			if l.peekChar() == '"' {
 				l.readChar() // consume b, now at "
 				content := l.readString()
-				tok = token.Token{Type: token.BITS_BIN, Lexeme: fmt.Sprintf("#b%q", content), Literal: content, Line: l.line, Column: l.column}
+				tok = token.Token{Type: token.BITS_BIN, Lexeme: fmt.Sprintf("#b%q", content), Literal: content, Line: startLine, Column: startCol}
 			} else {
 				// #b without " is illegal
 				tok = newToken(token.ILLEGAL, l.ch, l.line, l.column)
@@ -345,7 +356,7 @@ func (l *Lexer) NextToken() token.Token {
 			if l.peekChar() == '"' {
 				l.readChar() // consume x, now at "
 				content := l.readString()
-				tok = token.Token{Type: token.BITS_HEX, Lexeme: fmt.Sprintf("#x%q", content), Literal: content, Line: l.line, Column: l.column}
+				tok = token.Token{Type: token.BITS_HEX, Lexeme: fmt.Sprintf("#x%q", content), Literal: content, Line: startLine, Column: startCol}
 			} else {
 				// #x without " is illegal
 				tok = newToken(token.ILLEGAL, l.ch, l.line, l.column)
@@ -356,7 +367,7 @@ func (l *Lexer) NextToken() token.Token {
 			if l.peekChar() == '"' {
 				l.readChar() // consume o, now at "
 				content := l.readString()
-				tok = token.Token{Type: token.BITS_OCT, Lexeme: fmt.Sprintf("#o%q", content), Literal: content, Line: l.line, Column: l.column}
+				tok = token.Token{Type: token.BITS_OCT, Lexeme: fmt.Sprintf("#o%q", content), Literal: content, Line: startLine, Column: startCol}
 			} else {
 				// #o without " is illegal
 				tok = newToken(token.ILLEGAL, l.ch, l.line, l.column)
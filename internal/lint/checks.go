@@ -0,0 +1,170 @@
+package lint
+
+import (
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/config"
+	"github.com/funvibe/funxy/internal/token"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// terminates reports whether control never continues past stmt.
+func terminates(stmt ast.Statement) bool {
+	switch s := stmt.(type) {
+	case *ast.ReturnStatement, *ast.BreakStatement, *ast.ContinueStatement:
+		return true
+	case *ast.ExpressionStatement:
+		if ifExpr, ok := s.Expression.(*ast.IfExpression); ok {
+			return blockTerminates(ifExpr.Consequence) && blockTerminates(ifExpr.Alternative)
+		}
+	}
+	return false
+}
+
+func blockTerminates(block *ast.BlockStatement) bool {
+	if block == nil || len(block.Statements) == 0 {
+		return false
+	}
+	return terminates(block.Statements[len(block.Statements)-1])
+}
+
+func terminatorName(stmt ast.Statement) string {
+	switch s := stmt.(type) {
+	case *ast.BreakStatement:
+		return "break"
+	case *ast.ContinueStatement:
+		return "continue"
+	case *ast.ExpressionStatement:
+		if ifExpr, ok := s.Expression.(*ast.IfExpression); ok {
+			return terminatorName(ifExpr.Consequence.Statements[len(ifExpr.Consequence.Statements)-1])
+		}
+	}
+	return "return"
+}
+
+// startToken returns the first token of a statement. Binary and postfix
+// expressions carry their operator token, so descend along the left spine.
+func startToken(node ast.Node) token.Token {
+	for {
+		switch n := node.(type) {
+		case *ast.ExpressionStatement:
+			node = n.Expression
+		case *ast.InfixExpression:
+			node = n.Left
+		case *ast.PostfixExpression:
+			node = n.Left
+		case *ast.CallExpression:
+			node = n.Function
+		case *ast.IndexExpression:
+			node = n.Left
+		case *ast.MemberExpression:
+			node = n.Left
+		case *ast.AssignExpression:
+			node = n.Left
+		case *ast.PatternAssignExpression:
+			node = n.Pattern
+		case *ast.AnnotatedExpression:
+			node = n.Expression
+		case ast.TokenProvider:
+			return n.GetToken()
+		default:
+			return token.Token{}
+		}
+	}
+}
+
+// checkDiscarded reports an expression statement whose Result value is dropped.
+func (w *walker) checkDiscarded(stmt ast.Statement) {
+	es, ok := stmt.(*ast.ExpressionStatement)
+	if !ok || es.Expression == nil {
+		return
+	}
+	switch es.Expression.(type) {
+	case *ast.AssignExpression, *ast.PatternAssignExpression:
+		return
+	}
+	t := w.typeOf(es.Expression)
+	if isTypeApp(t, config.ResultTypeName) {
+		w.report(RuleDiscardedResult, startToken(es), t.String())
+	}
+}
+
+// checkTry reports `?` applied to a value that is always Ok or Some.
+func (w *walker) checkTry(n *ast.PostfixExpression) {
+	if n.Operator != "?" {
+		return
+	}
+	if name := wrapperName(n.Left); name != "" {
+		w.report(RuleRedundantTry, n.Token, name+"(...) never short-circuits")
+	}
+}
+
+// checkTail reports `Ok(e?)` and `Some(e?)` in return position when e already
+// has the returned type: the expression is equivalent to e.
+func (w *walker) checkTail(expr ast.Expression) {
+	switch e := expr.(type) {
+	case *ast.IfExpression:
+		w.checkBlockTail(e.Consequence)
+		w.checkBlockTail(e.Alternative)
+	case *ast.MatchExpression:
+		for _, arm := range e.Arms {
+			w.checkTail(arm.Expression)
+		}
+	case *ast.CallExpression:
+		name := wrapperName(e)
+		if name == "" || len(e.Arguments) != 1 {
+			return
+		}
+		inner, ok := e.Arguments[0].(*ast.PostfixExpression)
+		if !ok || inner.Operator != "?" {
+			return
+		}
+		innerType, outerType := w.typeOf(inner.Left), w.typeOf(e)
+		if innerType != nil && outerType != nil && innerType.String() == outerType.String() {
+			w.report(RuleRedundantTry, inner.Token, name+"(x?) is the same as x")
+		}
+	}
+}
+
+func (w *walker) checkBlockTail(block *ast.BlockStatement) {
+	if block == nil || len(block.Statements) == 0 {
+		return
+	}
+	if es, ok := block.Statements[len(block.Statements)-1].(*ast.ExpressionStatement); ok {
+		w.checkTail(es.Expression)
+	}
+}
+
+func (w *walker) typeOf(node ast.Node) typesystem.Type {
+	if w.unit.Types == nil {
+		return nil
+	}
+	return w.unit.Types[node]
+}
+
+// wrapperName returns "Ok" or "Some" for a constructor call wrapping a value.
+func wrapperName(expr ast.Expression) string {
+	call, ok := expr.(*ast.CallExpression)
+	if !ok {
+		return ""
+	}
+	ident, ok := call.Function.(*ast.Identifier)
+	if !ok {
+		return ""
+	}
+	if ident.Value == config.OkCtorName || ident.Value == config.SomeCtorName {
+		return ident.Value
+	}
+	return ""
+}
+
+func isTypeApp(t typesystem.Type, name string) bool {
+	if t == nil {
+		return false
+	}
+	app, ok := typesystem.UnwrapUnderlying(t).(typesystem.TApp)
+	if !ok {
+		return false
+	}
+	con, ok := app.Constructor.(typesystem.TCon)
+	return ok && con.Name == name
+}
@@ -0,0 +1,89 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Config holds the level of every rule.
+type Config struct {
+	levels           map[*Rule]Level
+	warningsAsErrors bool
+}
+
+// DefaultConfig returns a configuration with every rule at its default level.
+func DefaultConfig() *Config {
+	c := &Config{levels: make(map[*Rule]Level)}
+	for _, rule := range Rules {
+		c.levels[rule] = rule.Default
+	}
+	return c
+}
+
+// Level returns the effective level of rule.
+func (c *Config) Level(rule *Rule) Level {
+	level, ok := c.levels[rule]
+	if !ok {
+		level = rule.Default
+	}
+	if c.warningsAsErrors && level == LevelWarning {
+		return LevelError
+	}
+	return level
+}
+
+// Set changes the level of a rule given by name or code.
+func (c *Config) Set(key, level string) error {
+	rule := FindRule(key)
+	if rule == nil {
+		return fmt.Errorf("unknown lint rule: %s", key)
+	}
+	l, ok := levelNames[level]
+	if !ok {
+		return fmt.Errorf("unknown lint level %q for %s (expected off, info, warning or error)", level, rule.Name)
+	}
+	c.levels[rule] = l
+	return nil
+}
+
+// IsFlag reports whether arg is a -W flag understood by ApplyFlag.
+func IsFlag(arg string) bool {
+	return strings.HasPrefix(arg, "-W") && len(arg) > 2
+}
+
+// ApplyFlag applies a single -W flag:
+//
+//	-Wall             enable every rule as a warning
+//	-Werror           report warnings as errors
+//	-W<rule>          enable a rule as a warning
+//	-Wno-<rule>       disable a rule
+//	-Werror=<rule>    report a rule as an error
+//	-Winfo=<rule>     report a rule as information only
+//
+// Rules are named by name or code (-Wno-unused-parameter, -WW003).
+func (c *Config) ApplyFlag(arg string) error {
+	if !IsFlag(arg) {
+		return fmt.Errorf("not a warning flag: %s", arg)
+	}
+	flag := arg[2:]
+	switch {
+	case flag == "all":
+		for _, rule := range Rules {
+			if c.levels[rule] < LevelWarning {
+				c.levels[rule] = LevelWarning
+			}
+		}
+		return nil
+	case flag == "error":
+		c.warningsAsErrors = true
+		return nil
+	case strings.HasPrefix(flag, "no-"):
+		return c.Set(strings.TrimPrefix(flag, "no-"), "off")
+	case strings.HasPrefix(flag, "error="):
+		return c.Set(strings.TrimPrefix(flag, "error="), "error")
+	case strings.HasPrefix(flag, "info="):
+		return c.Set(strings.TrimPrefix(flag, "info="), "info")
+	default:
+		return c.Set(flag, "warning")
+	}
+}
@@ -0,0 +1,119 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/symbols"
+	"github.com/funvibe/funxy/internal/utils"
+)
+
+// checkImports reports imported symbols and modules that no file of the
+// package refers to. Imports are merged across the files of a package, so a
+// name used in any unit counts. Findings are returned per unit.
+func checkImports(units []*Unit) map[int][]finding {
+	referenced := make(map[string]bool)
+	for _, unit := range units {
+		if unit != nil && unit.Program != nil {
+			collectReferences(unit.Program, referenced)
+		}
+	}
+
+	result := make(map[int][]finding)
+	for i, unit := range units {
+		if unit == nil || unit.Program == nil {
+			continue
+		}
+		for _, imp := range programImports(unit.Program) {
+			if imp.ImportAll || len(imp.Exclude) > 0 {
+				continue
+			}
+			if len(imp.Symbols) == 0 {
+				name := utils.ExtractModuleName(imp.Path.Value)
+				tok := imp.Path.Token
+				if imp.Alias != nil {
+					name, tok = imp.Alias.Value, imp.Alias.Token
+				}
+				if !referenced[name] {
+					result[i] = append(result[i], finding{rule: RuleUnusedImport, tok: tok, args: []interface{}{name}})
+				}
+				continue
+			}
+			for _, sym := range imp.Symbols {
+				if !symbolReferenced(sym.Value, unit.Symbols, referenced) {
+					result[i] = append(result[i], finding{rule: RuleUnusedImport, tok: sym.Token, args: []interface{}{sym.Value}})
+				}
+			}
+		}
+	}
+	return result
+}
+
+// symbolReferenced checks an imported symbol. Importing a type also imports
+// its constructors, and traits are used implicitly through their methods and
+// instances, so they are only reported when that can be ruled out.
+func symbolReferenced(name string, table *symbols.SymbolTable, referenced map[string]bool) bool {
+	if referenced[name] {
+		return true
+	}
+	if table == nil {
+		return len(name) > 0 && name[0] >= 'A' && name[0] <= 'Z'
+	}
+	if sym, ok := table.Find(name); ok && sym.Kind == symbols.TraitSymbol {
+		return true
+	}
+	if variants, ok := table.GetVariants(name); ok {
+		for _, variant := range variants {
+			if referenced[variant] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func programImports(program *ast.Program) []*ast.ImportStatement {
+	if len(program.Imports) > 0 {
+		return program.Imports
+	}
+	var imports []*ast.ImportStatement
+	for _, stmt := range program.Statements {
+		if imp, ok := stmt.(*ast.ImportStatement); ok {
+			imports = append(imports, imp)
+		}
+	}
+	return imports
+}
+
+// collectReferences gathers every name a program mentions outside of imports:
+// identifiers (and the module part of qualified ones), pinned variables,
+// record pattern type names and operators.
+func collectReferences(program *ast.Program, names map[string]bool) {
+	addName := func(name string) {
+		names[name] = true
+		if dot := strings.Index(name, "."); dot > 0 {
+			names[name[:dot]] = true
+		}
+	}
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.ImportStatement:
+			return false
+		case *ast.Identifier:
+			addName(n.Value)
+		case *ast.PinPattern:
+			addName(n.Name)
+		case *ast.RecordPattern:
+			addName(n.TypeName)
+		case *ast.InfixExpression:
+			addName("(" + n.Operator + ")")
+			addName(n.Operator)
+		case *ast.PrefixExpression:
+			addName(n.Operator)
+		case *ast.OperatorAsFunction:
+			addName("(" + n.Operator + ")")
+			addName(n.Operator)
+		}
+		return true
+	})
+}
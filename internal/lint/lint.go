@@ -0,0 +1,82 @@
+package lint
+
+import (
+	"sort"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/diagnostics"
+	"github.com/funvibe/funxy/internal/symbols"
+	"github.com/funvibe/funxy/internal/token"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// Unit is one analyzed source file.
+type Unit struct {
+	Program *ast.Program
+	File    string // Reported file name; defaults to Program.File
+	Source  string // Used for lint:ignore comments
+	Types   map[ast.Node]typesystem.Type
+	Symbols *symbols.SymbolTable
+}
+
+// finding is a rule violation before configuration and suppressions apply.
+type finding struct {
+	rule *Rule
+	tok  token.Token
+	args []interface{}
+}
+
+// Check runs every enabled rule over units, which must belong to the same
+// package (imports are shared between the files of a package). It returns the
+// diagnostics sorted by file and position, with their severity set from cfg.
+func Check(units []*Unit, cfg *Config) []*diagnostics.DiagnosticError {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	perUnit := make([][]finding, len(units))
+	for i, unit := range units {
+		if unit == nil || unit.Program == nil {
+			continue
+		}
+		w := newWalker(unit)
+		w.walkProgram(unit.Program)
+		perUnit[i] = w.findings
+	}
+	for i, f := range checkImports(units) {
+		perUnit[i] = append(perUnit[i], f...)
+	}
+
+	var result []*diagnostics.DiagnosticError
+	for i, unit := range units {
+		if len(perUnit[i]) == 0 {
+			continue
+		}
+		ignored := parseSuppressions(unit.Source)
+		file := unit.File
+		if file == "" {
+			file = unit.Program.File
+		}
+		for _, f := range perUnit[i] {
+			level := cfg.Level(f.rule)
+			if level == LevelOff || f.tok.Line <= 0 || ignored.covers(f.tok.Line, f.rule) {
+				continue
+			}
+			diag := diagnostics.NewWarning(f.rule.Code, level.severity(), f.tok, f.args...)
+			diag.File = file
+			result = append(result, diag)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Token.Line != b.Token.Line {
+			return a.Token.Line < b.Token.Line
+		}
+		return a.Token.Column < b.Token.Column
+	})
+	return result
+}
@@ -0,0 +1,199 @@
+package lint
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/funvibe/funxy/internal/analyzer"
+	"github.com/funvibe/funxy/internal/diagnostics"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/parser"
+	"github.com/funvibe/funxy/internal/pipeline"
+)
+
+func lintSource(t *testing.T, input string, cfg *Config) []*diagnostics.DiagnosticError {
+	t.Helper()
+	ctx := pipeline.NewPipelineContext(input)
+	ctx = pipeline.New(
+		&lexer.LexerProcessor{},
+		&parser.ParserProcessor{},
+		&analyzer.SemanticAnalyzerProcessor{},
+	).Run(ctx)
+	if len(ctx.Errors) > 0 {
+		t.Fatalf("analysis failed: %v", ctx.Errors[0])
+	}
+	return Check(ContextUnits(ctx), cfg)
+}
+
+// expectFindings checks the reported codes and lines, in order.
+func expectFindings(t *testing.T, diags []*diagnostics.DiagnosticError, want ...string) {
+	t.Helper()
+	var got []string
+	for _, d := range diags {
+		got = append(got, string(d.Code)+"@"+strconv.Itoa(d.Token.Line))
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		var msgs []string
+		for _, d := range diags {
+			msgs = append(msgs, d.Error())
+		}
+		t.Errorf("expected %v, got %v\n%s", want, got, strings.Join(msgs, "\n"))
+	}
+}
+
+func TestUnusedVariable(t *testing.T) {
+	diags := lintSource(t, `
+total = 0
+fun f(xs: List<Int>) -> Int {
+    unused = 1
+    acc = 0
+    for x in xs { acc = acc + x }
+    _ignored = 2
+    acc
+}
+print(f([1, 2]))
+`, nil)
+	expectFindings(t, diags, "W001@4")
+	if !strings.Contains(diags[0].Error(), "warning at 4:5 [W001]: unused variable: 'unused'") {
+		t.Errorf("unexpected message: %s", diags[0].Error())
+	}
+}
+
+func TestUnusedPatternAndReassignedVariable(t *testing.T) {
+	diags := lintSource(t, `
+fun f(p: (Int, Int)) -> Int {
+    (a, b) = p
+    count = 0
+    count = 1
+    a
+}
+print(f((1, 2)))
+`, nil)
+	expectFindings(t, diags, "W001@3", "W001@4")
+}
+
+func TestUnusedParameter(t *testing.T) {
+	diags := lintSource(t, `
+fun f(x: Int, y: Int, _z: Int) -> Int { x }
+g = fun(a: Int) -> Int { 1 }
+print(f(1, 2, 3) + g(4))
+`, nil)
+	expectFindings(t, diags, "W003@2", "W003@3")
+	if diags[0].Severity != diagnostics.SeverityInfo {
+		t.Errorf("unused parameters should be infos by default, got %s", diags[0].Severity)
+	}
+}
+
+func TestShadowedBinding(t *testing.T) {
+	diags := lintSource(t, `
+fun f(x: Int) -> Int {
+    match x {
+        x -> x + 1
+    }
+}
+print(f(1))
+`, nil)
+	expectFindings(t, diags, "W004@4")
+	if !strings.Contains(diags[0].Error(), "'x' shadows a binding declared at line 2") {
+		t.Errorf("unexpected message: %s", diags[0].Error())
+	}
+}
+
+func TestUnreachableCode(t *testing.T) {
+	diags := lintSource(t, `
+fun f(x: Int) -> Int {
+    if x > 0 { return 1 } else { return 2 }
+    print("never")
+    3
+}
+fun g(xs: List<Int>) -> Int {
+    for x in xs {
+        break
+        print(x)
+    }
+    0
+}
+print(f(1) + g([1]))
+`, nil)
+	expectFindings(t, diags, "W005@4", "W005@10")
+}
+
+func TestDiscardedResult(t *testing.T) {
+	diags := lintSource(t, `
+fun parse(s: String) -> Result<String, Int> { if s == "" { Fail("empty") } else { Ok(1) } }
+fun f() -> Int {
+    parse("a")
+    r = parse("b")
+    match r { Ok(n) -> n, Fail(_) -> 0 }
+}
+parse("c")
+print(f())
+`, nil)
+	expectFindings(t, diags, "W006@4", "W006@8")
+}
+
+func TestRedundantTry(t *testing.T) {
+	diags := lintSource(t, `
+fun parse(s: String) -> Result<String, Int> { Ok(len(s)) }
+fun f() -> Result<String, Int> {
+    n = Ok(2)?
+    print(n)
+    Ok(parse("x")?)
+}
+fun g() -> Result<String, Int> {
+    n = parse("y")?
+    Ok(n + 1)
+}
+print(f())
+print(g())
+`, nil)
+	expectFindings(t, diags, "W007@4", "W007@6")
+}
+
+func TestSuppression(t *testing.T) {
+	diags := lintSource(t, `
+fun f() -> Int {
+    a = 1 // lint:ignore unused-variable
+    // lint:ignore W001
+    b = 2
+    c = 3 // lint:ignore unreachable-code
+    // lint:ignore
+    d = 4
+    0
+}
+print(f())
+`, nil)
+	expectFindings(t, diags, "W001@6")
+}
+
+func TestConfigFlags(t *testing.T) {
+	source := `
+fun f(x: Int) -> Int {
+    y = 1
+    0
+}
+print(f(1))
+`
+	cfg := DefaultConfig()
+	for _, flag := range []string{"-Wno-unused-parameter", "-Werror=W001"} {
+		if err := cfg.ApplyFlag(flag); err != nil {
+			t.Fatalf("ApplyFlag(%s) failed: %v", flag, err)
+		}
+	}
+	diags := lintSource(t, source, cfg)
+	expectFindings(t, diags, "W001@3")
+	if diags[0].Severity != diagnostics.SeverityError {
+		t.Errorf("expected -Werror=W001 to report an error, got %s", diags[0].Severity)
+	}
+
+	cfg = DefaultConfig()
+	_ = cfg.ApplyFlag("-Werror")
+	if cfg.Level(RuleUnusedVariable) != LevelError || cfg.Level(RuleUnusedParameter) != LevelInfo {
+		t.Error("-Werror should only promote warnings")
+	}
+
+	if err := cfg.ApplyFlag("-Wno-such-rule"); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
@@ -0,0 +1,77 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/diagnostics"
+	"github.com/funvibe/funxy/internal/modules"
+	"github.com/funvibe/funxy/internal/pipeline"
+)
+
+// Processor is a pipeline stage that lints an analyzed program. Diagnostics
+// configured as errors are added to ctx.Errors, all others to ctx.Warnings.
+// Programs that failed analysis are not linted.
+type Processor struct {
+	Config *Config
+}
+
+func (p *Processor) Process(ctx *pipeline.PipelineContext) *pipeline.PipelineContext {
+	if len(ctx.Errors) > 0 {
+		return ctx
+	}
+	for _, diag := range Check(ContextUnits(ctx), p.Config) {
+		if diag.Severity == diagnostics.SeverityError {
+			ctx.Errors = append(ctx.Errors, diag)
+		} else {
+			ctx.Warnings = append(ctx.Warnings, diag)
+		}
+	}
+	return ctx
+}
+
+// ContextUnits returns the files analyzed by a pipeline run: every file of the
+// entry package, or the single program of a script.
+func ContextUnits(ctx *pipeline.PipelineContext) []*Unit {
+	if mod, ok := ctx.Module.(*modules.Module); ok && mod != nil && len(mod.Files) > 0 {
+		return ModuleUnits(mod, ctx.FilePath, ctx.SourceCode)
+	}
+	program, ok := ctx.AstRoot.(*ast.Program)
+	if !ok || program == nil {
+		return nil
+	}
+	return []*Unit{{
+		Program: program,
+		File:    ctx.FilePath,
+		Source:  ctx.SourceCode,
+		Types:   ctx.TypeMap,
+		Symbols: ctx.SymbolTable,
+	}}
+}
+
+// ModuleUnits returns the files of an analyzed package. The source of
+// overridePath is taken from overrideSource (an unsaved editor buffer or the
+// text already read by the caller), other files are read from disk.
+func ModuleUnits(mod *modules.Module, overridePath, overrideSource string) []*Unit {
+	units := make([]*Unit, 0, len(mod.Files))
+	for _, file := range mod.Files {
+		unit := &Unit{Program: file, File: file.File, Types: mod.TypeMap, Symbols: mod.SymbolTable}
+		if overridePath != "" && samePath(file.File, overridePath) {
+			unit.Source = overrideSource
+		} else if data, err := os.ReadFile(file.File); err == nil {
+			unit.Source = string(data)
+		}
+		units = append(units, unit)
+	}
+	return units
+}
+
+func samePath(a, b string) bool {
+	if a == b {
+		return true
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,86 @@
+// Package lint implements the warning pass that runs after semantic analysis.
+//
+// Lint rules never change whether a program is valid: they report suspicious
+// but legal code (unused bindings, unreachable statements, ignored Results).
+// Each rule has a default level that can be changed with -W flags or per line
+// with a `// lint:ignore` comment.
+package lint
+
+import "github.com/funvibe/funxy/internal/diagnostics"
+
+// Level is the configured reporting level of a rule.
+type Level int
+
+const (
+	LevelOff Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+var levelNames = map[string]Level{
+	"off":     LevelOff,
+	"info":    LevelInfo,
+	"warning": LevelWarning,
+	"error":   LevelError,
+}
+
+func (l Level) String() string {
+	for name, level := range levelNames {
+		if level == l {
+			return name
+		}
+	}
+	return "off"
+}
+
+// severity maps an enabled level to the severity of the reported diagnostic.
+func (l Level) severity() diagnostics.Severity {
+	switch l {
+	case LevelError:
+		return diagnostics.SeverityError
+	case LevelInfo:
+		return diagnostics.SeverityInfo
+	default:
+		return diagnostics.SeverityWarning
+	}
+}
+
+// Rule describes a single lint check.
+type Rule struct {
+	Code        diagnostics.ErrorCode
+	Name        string
+	Default     Level
+	Description string
+}
+
+var (
+	RuleUnusedVariable  = &Rule{diagnostics.WarnW001, "unused-variable", LevelWarning, "local binding is never read"}
+	RuleUnusedImport    = &Rule{diagnostics.WarnW002, "unused-import", LevelWarning, "imported symbol or module is never referenced"}
+	RuleUnusedParameter = &Rule{diagnostics.WarnW003, "unused-parameter", LevelInfo, "function parameter is never read"}
+	RuleShadowing       = &Rule{diagnostics.WarnW004, "shadowed-binding", LevelInfo, "binding hides one from an enclosing scope"}
+	RuleUnreachable     = &Rule{diagnostics.WarnW005, "unreachable-code", LevelWarning, "statement follows return, break or continue"}
+	RuleDiscardedResult = &Rule{diagnostics.WarnW006, "discarded-result", LevelWarning, "Result value is computed and ignored"}
+	RuleRedundantTry    = &Rule{diagnostics.WarnW007, "redundant-try", LevelWarning, "'?' that can never short-circuit or is immediately re-wrapped"}
+)
+
+// Rules lists every lint rule in code order.
+var Rules = []*Rule{
+	RuleUnusedVariable,
+	RuleUnusedImport,
+	RuleUnusedParameter,
+	RuleShadowing,
+	RuleUnreachable,
+	RuleDiscardedResult,
+	RuleRedundantTry,
+}
+
+// FindRule looks a rule up by name ("unused-variable") or code ("W001").
+func FindRule(key string) *Rule {
+	for _, rule := range Rules {
+		if rule.Name == key || string(rule.Code) == key {
+			return rule
+		}
+	}
+	return nil
+}
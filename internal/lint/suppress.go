@@ -0,0 +1,61 @@
+package lint
+
+import "strings"
+
+const ignoreDirective = "lint:ignore"
+
+// suppressions maps a line number to the rules ignored on it.
+// A nil rule list ignores every rule.
+type suppressions map[int][]string
+
+// parseSuppressions finds `// lint:ignore [rule, ...]` comments. A trailing
+// comment applies to its own line, a comment on a line of its own applies to
+// the next line. Rules are given by name or code; without rules every
+// diagnostic is ignored.
+func parseSuppressions(source string) suppressions {
+	result := make(suppressions)
+	for i, line := range strings.Split(source, "\n") {
+		at := strings.Index(line, ignoreDirective)
+		if at == -1 {
+			continue
+		}
+		before := strings.TrimRight(line[:at], " \t")
+		if !strings.HasSuffix(before, "//") {
+			continue
+		}
+		idx := len(before) - 2
+		rules := strings.FieldsFunc(line[at+len(ignoreDirective):], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if len(rules) == 0 {
+			rules = nil
+		}
+
+		target := i + 1 // lines are 1-based
+		if strings.TrimSpace(line[:idx]) == "" {
+			target++
+		}
+		if existing, ok := result[target]; ok && (existing == nil || rules == nil) {
+			result[target] = nil
+		} else {
+			result[target] = append(existing, rules...)
+		}
+	}
+	return result
+}
+
+func (s suppressions) covers(line int, rule *Rule) bool {
+	rules, ok := s[line]
+	if !ok {
+		return false
+	}
+	if rules == nil {
+		return true
+	}
+	for _, r := range rules {
+		if r == rule.Name || r == string(rule.Code) {
+			return true
+		}
+	}
+	return false
+}
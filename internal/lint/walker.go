@@ -0,0 +1,441 @@
+package lint
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/token"
+)
+
+type bindingKind int
+
+const (
+	bindVariable bindingKind = iota
+	bindParameter
+	bindGlobal
+)
+
+type binding struct {
+	name   string
+	tok    token.Token
+	kind   bindingKind
+	used   bool
+	exempt bool
+}
+
+// scope mirrors the analyzer's scoping: blocks, function bodies, match arms,
+// loops and comprehensions each open one.
+type scope struct {
+	parent   *scope
+	bindings map[string]*binding
+	order    []*binding
+	function bool // function scopes cannot reassign globals
+}
+
+// walker tracks bindings and their uses in a single file and runs the
+// statement-level rules along the way.
+type walker struct {
+	unit     *Unit
+	scope    *scope
+	global   *scope
+	findings []finding
+}
+
+func newWalker(unit *Unit) *walker {
+	global := &scope{bindings: make(map[string]*binding)}
+	return &walker{unit: unit, scope: global, global: global}
+}
+
+func (w *walker) report(rule *Rule, tok token.Token, args ...interface{}) {
+	w.findings = append(w.findings, finding{rule: rule, tok: tok, args: args})
+}
+
+func (w *walker) push(function bool) {
+	w.scope = &scope{parent: w.scope, bindings: make(map[string]*binding), function: function}
+}
+
+func (w *walker) pop() {
+	for _, b := range w.scope.order {
+		if b.used || b.exempt {
+			continue
+		}
+		if b.kind == bindParameter {
+			w.report(RuleUnusedParameter, b.tok, b.name)
+		} else {
+			w.report(RuleUnusedVariable, b.tok, b.name)
+		}
+	}
+	w.scope = w.scope.parent
+}
+
+func (w *walker) lookup(name string) (*binding, *scope) {
+	for s := w.scope; s != nil; s = s.parent {
+		if b, ok := s.bindings[name]; ok {
+			return b, s
+		}
+	}
+	return nil, nil
+}
+
+func (w *walker) inFunction() bool {
+	for s := w.scope; s != nil; s = s.parent {
+		if s.function {
+			return true
+		}
+	}
+	return false
+}
+
+// define adds a binding to the current scope. Parameters and pattern bindings
+// that hide an enclosing binding are reported as shadowing.
+func (w *walker) define(name string, tok token.Token, kind bindingKind, exempt bool, checkShadow bool) {
+	if name == "" {
+		return
+	}
+	if name == "_" || strings.HasPrefix(name, "_") || strings.HasPrefix(name, "$") {
+		exempt = true
+	}
+	if checkShadow && !exempt {
+		// Globals declared further down the file are not shadowed yet
+		if outer, _ := w.lookup(name); outer != nil && outer.tok.Line > 0 && (outer.kind != bindGlobal || outer.tok.Line < tok.Line) {
+			if _, same := w.scope.bindings[name]; !same {
+				w.report(RuleShadowing, tok, name, outer.tok.Line)
+			}
+		}
+	}
+	b := &binding{name: name, tok: tok, kind: kind, exempt: exempt || kind == bindGlobal}
+	w.scope.bindings[name] = b
+	w.scope.order = append(w.scope.order, b)
+}
+
+func (w *walker) use(name string) {
+	if b, _ := w.lookup(name); b != nil {
+		b.used = true
+	}
+}
+
+// assign handles `x = e`: an existing binding is reassigned (which is not a
+// read), otherwise a new variable is defined. Inside functions, assigning a
+// global name creates a local.
+func (w *walker) assign(ident *ast.Identifier) {
+	b, s := w.lookup(ident.Value)
+	if b != nil && !(s == w.global && w.inFunction()) {
+		return
+	}
+	w.define(ident.Value, ident.Token, bindVariable, false, false)
+}
+
+func (w *walker) walkProgram(program *ast.Program) {
+	stmts := make([]ast.Statement, 0, len(program.Statements))
+	for _, stmt := range program.Statements {
+		if !isGenerated(stmt) {
+			stmts = append(stmts, stmt)
+		}
+	}
+	w.predeclare(stmts, bindGlobal)
+	w.walkStatements(stmts, true)
+}
+
+// isGenerated reports declarations the analyzer injects into the program,
+// such as instance dictionaries ($impl_*, $ctor_*). Their bodies repeat the
+// instance methods.
+func isGenerated(stmt ast.Statement) bool {
+	switch s := stmt.(type) {
+	case *ast.FunctionStatement:
+		return s.Name != nil && strings.HasPrefix(s.Name.Value, "$")
+	case *ast.ConstantDeclaration:
+		return s.Name != nil && strings.HasPrefix(s.Name.Value, "$")
+	}
+	return false
+}
+
+// predeclare registers the names a statement list introduces up front, so that
+// functions can refer to each other regardless of order.
+func (w *walker) predeclare(stmts []ast.Statement, kind bindingKind) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.FunctionStatement:
+			if s.Name != nil && s.Receiver == nil {
+				w.define(s.Name.Value, s.Name.Token, kind, false, false)
+			}
+		case *ast.ConstantDeclaration:
+			if kind == bindGlobal {
+				if s.Name != nil {
+					w.define(s.Name.Value, s.Name.Token, kind, false, false)
+				} else if s.Pattern != nil {
+					w.bindPattern(s.Pattern, kind, false)
+				}
+			}
+		case *ast.ExpressionStatement:
+			if kind != bindGlobal {
+				continue
+			}
+			switch e := s.Expression.(type) {
+			case *ast.AssignExpression:
+				if ident, ok := e.Left.(*ast.Identifier); ok {
+					if b, _ := w.lookup(ident.Value); b == nil {
+						w.define(ident.Value, ident.Token, kind, false, false)
+					}
+				}
+			case *ast.PatternAssignExpression:
+				w.bindPattern(e.Pattern, kind, false)
+			}
+		}
+	}
+}
+
+// walkStatements walks a statement list. At the top level every expression
+// statement discards its value; in blocks all but the last one do.
+func (w *walker) walkStatements(stmts []ast.Statement, topLevel bool) {
+	reportedUnreachable := false
+	for i, stmt := range stmts {
+		if !reportedUnreachable && i > 0 && terminates(stmts[i-1]) {
+			w.report(RuleUnreachable, startToken(stmt), terminatorName(stmts[i-1]))
+			reportedUnreachable = true
+		}
+		if topLevel || i < len(stmts)-1 {
+			w.checkDiscarded(stmt)
+		}
+		if topLevel {
+			w.walkTopLevel(stmt)
+		} else {
+			w.walk(stmt)
+		}
+	}
+}
+
+// walkTopLevel walks a top-level statement whose bindings were predeclared.
+func (w *walker) walkTopLevel(stmt ast.Statement) {
+	switch s := stmt.(type) {
+	case *ast.ConstantDeclaration:
+		w.walk(s.Value)
+	case *ast.ExpressionStatement:
+		if pa, ok := s.Expression.(*ast.PatternAssignExpression); ok {
+			w.walk(pa.Value)
+			w.pins(pa.Pattern)
+			return
+		}
+		w.walk(s)
+	default:
+		w.walk(stmt)
+	}
+}
+
+func (w *walker) walkBlock(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	w.push(false)
+	w.predeclare(block.Statements, bindVariable)
+	w.walkStatements(block.Statements, false)
+	w.pop()
+}
+
+func (w *walker) walk(node ast.Node) {
+	if node == nil {
+		return
+	}
+	if _, ok := node.(ast.Type); ok {
+		return
+	}
+
+	switch n := node.(type) {
+	case *ast.Identifier:
+		if n != nil {
+			w.use(n.Value)
+		}
+	case *ast.ImportStatement, *ast.PackageDeclaration, *ast.TypeDeclarationStatement, *ast.DirectiveStatement:
+		// No value bindings
+	case *ast.BlockStatement:
+		w.walkBlock(n)
+	case *ast.AssignExpression:
+		w.walk(n.Value)
+		if ident, ok := n.Left.(*ast.Identifier); ok {
+			w.assign(ident)
+		} else {
+			w.walk(n.Left)
+		}
+	case *ast.PatternAssignExpression:
+		w.walk(n.Value)
+		w.bindPattern(n.Pattern, bindVariable, false)
+	case *ast.ConstantDeclaration:
+		w.walk(n.Value)
+		if n.Name != nil {
+			w.define(n.Name.Value, n.Name.Token, bindVariable, false, false)
+		} else if n.Pattern != nil {
+			w.bindPattern(n.Pattern, bindVariable, false)
+		}
+	case *ast.FunctionStatement:
+		if n.Body == nil {
+			return
+		}
+		w.walkFunction(n.Parameters, n.Receiver, n.Body, false, false)
+	case *ast.FunctionLiteral:
+		w.walkFunction(n.Parameters, nil, n.Body, false, isDoLambda(n))
+	case *ast.TraitDeclaration:
+		for _, sig := range n.Signatures {
+			if sig.Body != nil {
+				w.walkFunction(sig.Parameters, nil, sig.Body, true, false)
+			}
+		}
+	case *ast.InstanceDeclaration:
+		for _, method := range n.Methods {
+			if method.Body != nil {
+				w.walkFunction(method.Parameters, method.Receiver, method.Body, true, false)
+			}
+		}
+	case *ast.ReturnStatement:
+		w.walk(n.Value)
+		w.checkTail(n.Value)
+	case *ast.ForExpression:
+		w.walk(n.Initializer)
+		w.walk(n.Condition)
+		w.walk(n.Iterable)
+		w.push(false)
+		if n.ItemName != nil {
+			w.define(n.ItemName.Value, n.ItemName.Token, bindVariable, false, true)
+		}
+		if n.Body != nil {
+			w.predeclare(n.Body.Statements, bindVariable)
+			w.walkStatements(n.Body.Statements, false)
+		}
+		w.pop()
+	case *ast.MatchExpression:
+		w.walk(n.Expression)
+		for _, arm := range n.Arms {
+			w.push(false)
+			w.bindPattern(arm.Pattern, bindVariable, true)
+			w.walk(arm.Guard)
+			w.walk(arm.Expression)
+			w.pop()
+		}
+	case *ast.ListComprehension:
+		w.push(false)
+		w.walkClauses(n.Clauses)
+		w.walk(n.Output)
+		w.pop()
+	case *ast.MapComprehension:
+		w.push(false)
+		w.walkClauses(n.Clauses)
+		w.walk(n.Key)
+		w.walk(n.Value)
+		w.pop()
+	case *ast.MemberExpression:
+		w.walk(n.Left)
+	case *ast.AnnotatedExpression:
+		w.walk(n.Expression)
+	case *ast.TypeApplicationExpression:
+		w.walk(n.Expression)
+	case *ast.PostfixExpression:
+		w.walk(n.Left)
+		w.checkTry(n)
+	default:
+		ast.Children(node, w.walk)
+	}
+}
+
+// walkFunction walks a function body in a new scope holding its parameters.
+// Receivers and the parameters of trait methods are fixed by the signature
+// and never reported.
+func (w *walker) walkFunction(params []*ast.Parameter, receiver *ast.Parameter, body *ast.BlockStatement, method bool, doLambda bool) {
+	for _, p := range params {
+		if p != nil {
+			w.walk(p.Default)
+		}
+	}
+
+	w.push(true)
+	if receiver != nil && receiver.Name != nil {
+		w.define(receiver.Name.Value, receiver.Name.Token, bindParameter, true, false)
+	}
+	for _, p := range params {
+		if p == nil || p.Name == nil {
+			continue
+		}
+		kind := bindParameter
+		if doLambda {
+			// `x <- m` in a do block binds a variable, not a parameter
+			kind = bindVariable
+		}
+		w.define(p.Name.Value, p.Name.Token, kind, method || p.IsIgnored, true)
+	}
+	if body != nil {
+		w.predeclare(body.Statements, bindVariable)
+		w.walkStatements(body.Statements, false)
+		if len(body.Statements) > 0 {
+			if es, ok := body.Statements[len(body.Statements)-1].(*ast.ExpressionStatement); ok {
+				w.checkTail(es.Expression)
+			}
+		}
+	}
+	w.pop()
+}
+
+func (w *walker) walkClauses(clauses []ast.CompClause) {
+	for _, clause := range clauses {
+		switch c := clause.(type) {
+		case *ast.CompGenerator:
+			w.walk(c.Iterable)
+			w.bindPattern(c.Pattern, bindVariable, true)
+		case *ast.CompFilter:
+			w.walk(c.Condition)
+		}
+	}
+}
+
+// bindPattern defines the variables a pattern binds; pinned variables are uses.
+func (w *walker) bindPattern(p ast.Pattern, kind bindingKind, checkShadow bool) {
+	switch pat := p.(type) {
+	case *ast.IdentifierPattern:
+		w.define(pat.Value, pat.Token, kind, false, checkShadow)
+	case *ast.TypePattern:
+		w.define(pat.Name, pat.Token, kind, false, checkShadow)
+	case *ast.StringPattern:
+		for _, part := range pat.Parts {
+			if part.IsCapture {
+				w.define(part.Value, pat.Token, kind, false, checkShadow)
+			}
+		}
+	case *ast.PinPattern:
+		w.use(pat.Name)
+	case *ast.ConstructorPattern:
+		for _, el := range pat.Elements {
+			w.bindPattern(el, kind, checkShadow)
+		}
+	case *ast.TuplePattern:
+		for _, el := range pat.Elements {
+			w.bindPattern(el, kind, checkShadow)
+		}
+	case *ast.ListPattern:
+		for _, el := range pat.Elements {
+			w.bindPattern(el, kind, checkShadow)
+		}
+	case *ast.SpreadPattern:
+		w.bindPattern(pat.Pattern, kind, checkShadow)
+	case *ast.RecordPattern:
+		keys := make([]string, 0, len(pat.Fields))
+		for key := range pat.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			w.bindPattern(pat.Fields[key], kind, checkShadow)
+		}
+	}
+}
+
+// pins marks the pinned variables of a predeclared pattern as used.
+func (w *walker) pins(p ast.Pattern) {
+	ast.Inspect(p, func(n ast.Node) bool {
+		if pin, ok := n.(*ast.PinPattern); ok {
+			w.use(pin.Name)
+		}
+		return true
+	})
+}
+
+// isDoLambda reports lambdas synthesized by do-notation desugaring.
+func isDoLambda(fn *ast.FunctionLiteral) bool {
+	return fn.Token.Type == token.BACKSLASH && fn.Token.Line == 0
+}
@@ -94,6 +94,29 @@ func SearchDocs(term string) []*DocEntry {
 	return results
 }
 
+// DocMatch is a documented item and the package that documents it.
+type DocMatch struct {
+	Package string
+	Entry   *DocEntry
+}
+
+// FindDocs returns the items named exactly name, ordered by package path.
+func FindDocs(name string) []DocMatch {
+	var results []DocMatch
+	for _, pkg := range GetAllDocPackages() {
+		for _, entries := range [][]*DocEntry{pkg.Functions, pkg.Types, pkg.Traits, pkg.Operators} {
+			for _, entry := range entries {
+				// Trait entries are named like "Show<T>"
+				entryName, _, _ := strings.Cut(entry.Name, "<")
+				if entry.Name == name || entryName == name {
+					results = append(results, DocMatch{Package: pkg.Path, Entry: entry})
+				}
+			}
+		}
+	}
+	return results
+}
+
 func matchesSearch(entry *DocEntry, term string) bool {
 	return strings.Contains(strings.ToLower(entry.Name), term) ||
 		strings.Contains(strings.ToLower(entry.Description), term)
@@ -187,6 +210,7 @@ func PrintHelp() string {
 	sb.WriteString("=========================================\n\n")
 	sb.WriteString("Usage:\n")
 	sb.WriteString("  funxy <file>                Run a program\n")
+	sb.WriteString("  funxy repl                  Start an interactive session (also: funxy on a terminal)\n")
 	sb.WriteString("  funxy -e '<expr>'           Evaluate expression\n")
 	sb.WriteString("  funxy -pe '<expr>'          Evaluate and print result\n")
 	sb.WriteString("  funxy -lpe '<expr>'         Process stdin line-by-line\n")
@@ -208,6 +232,33 @@ func PrintHelp() string {
 	sb.WriteString("  cat data.json | funxy -pe '|>> jsonDecode |> \\x -> x.name'\n")
 	sb.WriteString("  funxy -lpe 'stringToUpper(stdin)' < file.txt\n")
 	sb.WriteString("\n")
+	sb.WriteString("Lint:\n")
+	sb.WriteString("  funxy lint [-W...] [paths...]             Report warnings (default path: .)\n")
+	sb.WriteString("  funxy -W... <file>                        Run a program, printing warnings first\n")
+	sb.WriteString("  -Wall           Enable every rule as a warning\n")
+	sb.WriteString("  -Werror         Report warnings as errors\n")
+	sb.WriteString("  -W<rule>        Enable a rule (e.g. -Wunused-parameter)\n")
+	sb.WriteString("  -Wno-<rule>     Disable a rule (e.g. -Wno-unused-import)\n")
+	sb.WriteString("  -Werror=<rule>  Report a rule as an error\n")
+	sb.WriteString("  Rules: unused-variable, unused-import, unused-parameter, shadowed-binding,\n")
+	sb.WriteString("         unreachable-code, discarded-result, redundant-try (or codes W001-W007)\n")
+	sb.WriteString("  Suppress per line with // lint:ignore [rule, ...]\n")
+	sb.WriteString("\n")
+	sb.WriteString("Formatting:\n")
+	sb.WriteString("  funxy fmt [paths...]                      Print formatted source (default path: .)\n")
+	sb.WriteString("  funxy fmt -w [paths...]                   Rewrite files in place\n")
+	sb.WriteString("  funxy fmt -l [paths...]                   List files whose formatting differs\n")
+	sb.WriteString("  funxy fmt --check [paths...]              Like -l, but exit with status 1 if any differ\n")
+	sb.WriteString("\n")
+	sb.WriteString("REPL:\n")
+	sb.WriteString("  Definitions, imports, types and instances persist between inputs.\n")
+	sb.WriteString("  Unclosed brackets or a trailing operator continue the input on the next line.\n")
+	sb.WriteString("  :type <expr>    Show the type of an expression\n")
+	sb.WriteString("  :doc <name>     Show documentation for a builtin, package or definition\n")
+	sb.WriteString("  :load <file>    Evaluate a source file\n")
+	sb.WriteString("  :reset          Forget every definition\n")
+	sb.WriteString("  :quit           Exit (or Ctrl-D); Tab completes names, Up/Down browse history\n")
+	sb.WriteString("\n")
 	sb.WriteString("Build & Distribution:\n")
 	sb.WriteString("  funxy build <file> [-o out] [--host bin] [--embed path]  Build self-contained binary\n")
 	sb.WriteString("  funxy -c <file>                           Compile to bytecode bundle (.fbc)\n")
@@ -361,6 +412,7 @@ func InitDocumentation() {
 	initTaskDocs()
 	initCsvDocs()
 	initYamlDocs()
+	initJsonSchemaDocs()
 	initFlagDocs()
 	initTermDocs()
 	initTermIODocs()
@@ -380,20 +432,36 @@ func InitDocumentation() {
 
 func initGrpcDocs() {
 	meta := map[string]*DocMeta{
-		"grpcConnect":           {Description: "Connect to gRPC server (target)", Category: "Client"},
-		"grpcClose":             {Description: "Close gRPC connection", Category: "Client"},
-		"grpcInvoke":            {Description: "Invoke gRPC method (conn, method, request)", Category: "Client"},
-		"grpcLoadProto":         {Description: "Load .proto files (path)", Category: "Configuration"},
-		"grpcServer":            {Description: "Create a new gRPC server", Category: "Server"},
-		"grpcRegister":          {Description: "Register a service implementation (server, serviceName, impl)", Category: "Server"},
-		"grpcServe":             {Description: "Start serving requests (blocking) (server, address)", Category: "Server"},
-		"grpcServeAsync":        {Description: "Start serving requests (async) (server, address)", Category: "Server"},
-		"grpcStop":              {Description: "Stop the server (server)", Category: "Server"},
-		"grpcSetMaxConnections": {Description: "Set max concurrent server connections (0=unlimited)", Category: "Config"},
+		"grpcConnect":             {Description: "Connect to gRPC server (target, options?); options { tls, caFile, certFile, keyFile, serverName, insecureSkipVerify } enable TLS and mTLS", Category: "Client"},
+		"grpcClose":               {Description: "Close gRPC connection", Category: "Client"},
+		"grpcInvoke":              {Description: "Invoke gRPC method (conn, method, request, options?); options { metadata: List<(String, String)>, timeout: Int }", Category: "Client"},
+		"grpcCall":                {Description: "grpcInvoke failing with a GrpcError instead of a String (conn, method, request, options?)", Category: "Client"},
+		"grpcInvokeStream":        {Description: "Start a server-streaming call; iterate the stream for responses (conn, method, request, options?)", Category: "Streaming"},
+		"grpcInvokeStreamTimeout": {Description: "grpcInvokeStream with a deadline for the whole call (conn, method, request, timeoutMs)", Category: "Streaming"},
+		"grpcOpenStream":          {Description: "Start a client-streaming or bidirectional call (conn, method, options?)", Category: "Streaming"},
+		"grpcOpenStreamTimeout":   {Description: "grpcOpenStream with a deadline for the whole call (conn, method, timeoutMs)", Category: "Streaming"},
+		"grpcSend":                {Description: "Send a message on a stream (stream, message)", Category: "Streaming"},
+		"grpcRecv":                {Description: "Receive the next message, None when the other side is done (stream)", Category: "Streaming"},
+		"grpcCloseSend":           {Description: "Signal that the client has sent all its messages (stream)", Category: "Streaming"},
+		"grpcCloseAndRecv":        {Description: "Close sending and receive the response of a client-streaming call (stream)", Category: "Streaming"},
+		"grpcCancel":              {Description: "Cancel a client call (stream)", Category: "Streaming"},
+		"grpcLoadProto":           {Description: "Load .proto files (path)", Category: "Configuration"},
+		"grpcServer":              {Description: "Create a new gRPC server (options?); options { certFile, keyFile, clientCAFile, clientAuth, reflection, interceptors }", Category: "Server"},
+		"grpcMetadata":            {Description: "Metadata sent by the client of the call being handled", Category: "Server"},
+		"grpcRegister":            {Description: "Register a service implementation (server, serviceName, impl); streaming handlers take (request, stream) or (stream)", Category: "Server"},
+		"grpcServe":               {Description: "Start serving requests (blocking) (server, address)", Category: "Server"},
+		"grpcServeAsync":          {Description: "Start serving requests (async) (server, address)", Category: "Server"},
+		"grpcStop":                {Description: "Stop the server (server)", Category: "Server"},
+		"grpcSetMaxConnections":   {Description: "Set max concurrent server connections (0=unlimited)", Category: "Config"},
 	}
 	types := []*DocEntry{
 		{Name: "GrpcConn", Signature: "opaque", Description: "gRPC client connection"},
 		{Name: "GrpcServer", Signature: "opaque", Description: "gRPC server instance"},
+		{Name: "GrpcStream", Signature: "opaque", Description: "One side of a streaming call; usable in for ... in"},
+		{Name: "GrpcError", Signature: "GrpcStatus(GrpcCode, String, List<GrpcDetail>)", Description: "Status error: code, message and details; handlers and interceptors return it to fail a call"},
+		{Name: "GrpcCode", Signature: "GrpcCancelled | GrpcUnknown | GrpcInvalidArgument | GrpcDeadlineExceeded | GrpcNotFound | GrpcAlreadyExists | GrpcPermissionDenied | GrpcResourceExhausted | GrpcFailedPrecondition | GrpcAborted | GrpcOutOfRange | GrpcUnimplemented | GrpcInternal | GrpcUnavailable | GrpcDataLoss | GrpcUnauthenticated", Description: "gRPC status code"},
+		{Name: "GrpcDetail", Signature: "{ typeName: String, value: Bytes }", Description: "Encoded error detail message and its full type name"},
+		{Name: "GrpcCall", Signature: "{ method: String, metadata: List<(String, String)>, peer: String }", Description: "The call an interceptor runs around"},
 	}
 	pkg := generatePackageDocs("lib/grpc", "gRPC client and server support", meta, types)
 	RegisterDocPackage(pkg)
@@ -880,15 +948,31 @@ func initIODocs() {
 
 func initSysDocs() {
 	meta := map[string]*DocMeta{
-		"sysArgs":      {Description: "Command line arguments"},
-		"sysEnv":       {Description: "Get environment variable"},
-		"sysExit":      {Description: "Exit with status code"},
-		"sysExec":      {Description: "Execute external command"},
-		"sysExePath":   {Description: "Absolute path to current executable"},
-		"sysCPUCount":  {Description: "Current Go runtime CPU parallelism (GOMAXPROCS)"},
-		"sysScriptDir": {Description: "Directory of the currently running script. Returns \"\" in compiled binary (bundle) mode — use pathJoin([sysScriptDir(), \"file\"]) for portable code"},
-	}
-	pkg := generatePackageDocs("lib/sys", "System interaction (sysArgs, sysEnv, sysExit, sysExec, sysExePath, sysCPUCount, sysScriptDir)", meta, nil)
+		"sysArgs":        {Description: "Command line arguments"},
+		"sysEnv":         {Description: "Get environment variable"},
+		"sysExit":        {Description: "Exit with status code"},
+		"sysExec":        {Description: "Execute external command"},
+		"sysExePath":     {Description: "Absolute path to current executable"},
+		"sysCPUCount":    {Description: "Current Go runtime CPU parallelism (GOMAXPROCS)"},
+		"sysScriptDir":   {Description: "Directory of the currently running script. Returns \"\" in compiled binary (bundle) mode — use pathJoin([sysScriptDir(), \"file\"]) for portable code"},
+		"sysSpawn":       {Description: "Start a command without waiting (cmd, args, opts). Options: cwd, env (Map, added to the inherited environment), clearEnv, stdin/stdout/stderr (\"pipe\", \"inherit\", \"null\"; stderr also \"stdout\")"},
+		"sysPipeline":    {Description: "Start commands with each one's stdout piped into the next one's stdin ([(cmd, args)], opts). The exit code is the last non-zero one"},
+		"sysReadLine":    {Description: "Next line of a process's stdout, None at the end. A process is also iterable: for line in proc"},
+		"sysReadErrLine": {Description: "Next line of a process's stderr, None at the end"},
+		"sysRead":        {Description: "Up to n bytes of a process's stdout, None at the end (proc, n)"},
+		"sysReadErr":     {Description: "Up to n bytes of a process's stderr, None at the end (proc, n)"},
+		"sysWrite":       {Description: "Write a String or Bytes to a process's stdin"},
+		"sysCloseStdin":  {Description: "Close a process's stdin so it sees end of input"},
+		"sysWait":        {Description: "Wait for a process to exit and return its exit code"},
+		"sysWaitTimeout": {Description: "Wait up to timeoutMs for a process to exit; None if it is still running (proc, timeoutMs)"},
+		"sysKill":        {Description: "Kill a process"},
+		"sysSignal":      {Description: "Send a signal by name: HUP, INT, QUIT, KILL, TERM, USR1, USR2, CONT, STOP (only KILL on Windows)"},
+		"sysPid":         {Description: "Process ID (of the first command of a pipeline)"},
+	}
+	types := []*DocEntry{
+		{Name: "Process", Signature: "opaque", Description: "A running command or pipeline started by sysSpawn or sysPipeline"},
+	}
+	pkg := generatePackageDocs("lib/sys", "System interaction (sysArgs, sysEnv, sysExit, sysExec, sysExePath, sysCPUCount, sysScriptDir) and processes (sysSpawn, sysPipeline)", meta, types)
 	RegisterDocPackage(pkg)
 }
 
@@ -1012,6 +1096,9 @@ func initJsonDocs() {
 		{Name: "Json", Signature: "JNull | JBool Bool | JNum Float | JStr String | JArr List<Json> | JObj List<(String, Json)>", Description: "JSON value ADT"},
 	}
 	pkg := generatePackageDocs("lib/json", "JSON encoding, decoding, and manipulation", meta, types)
+	pkg.Traits = []*DocEntry{
+		{Name: "ToJson<t>", Signature: "toJson", Description: "JSON text representation, derivable"},
+	}
 	RegisterDocPackage(pkg)
 }
 
@@ -1037,6 +1124,9 @@ func initCryptoDocs() {
 		"cryptoRandomHex":   {Description: "Cryptographically secure random hex string", Category: "Random"},
 	}
 	pkg := generatePackageDocs("lib/crypto", "Cryptographic hashing, encoding, and secure random functions", meta, nil)
+	pkg.Traits = []*DocEntry{
+		{Name: "Hash<t>", Signature: "hash", Description: "Hash code, equal for equal values, derivable"},
+	}
 	RegisterDocPackage(pkg)
 }
 
@@ -1074,13 +1164,35 @@ func initHttpDocs() {
 		"httpSetTimeout":        {Description: "Set request timeout (milliseconds)", Category: "Config"},
 		"httpSetNoRedirect":     {Description: "When true, return 3xx responses without following redirects", Category: "Config"},
 		"httpSetMaxConnections": {Description: "Set max concurrent server connections (0=unlimited)", Category: "Config"},
-		"httpServe":             {Description: "Start HTTP server (blocking)", Category: "Server"},
-		"httpServeAsync":        {Description: "Start HTTP server (non-blocking, returns server ID)", Category: "Server"},
+		"httpServe":             {Description: "Start HTTP server (blocking) on a port or a config record { port, host?, certFile?, keyFile?, clientCAFile?, clientAuth?, readTimeout?, writeTimeout?, idleTimeout?, http2?, maxBodySize?, streamBody? }", Category: "Server"},
+		"httpServeAsync":        {Description: "Start HTTP server (non-blocking, returns server ID); takes a port or a config record like httpServe", Category: "Server"},
 		"httpServerStop":        {Description: "Stop a running server by ID (timeout? default 5000ms)", Category: "Server"},
+		"httpStream":            {Description: "Streamed response (status, headers, producer); the producer gets an HttpWriter after the handler returns", Category: "Streaming"},
+		"httpEventStream":       {Description: "Server-Sent Events response (producer, headers?) with status 200 and text/event-stream headers", Category: "Streaming"},
+		"httpWrite":             {Description: "Write a chunk (String or Bytes) to a streamed response; fails once the client has disconnected", Category: "Streaming"},
+		"httpFlush":             {Description: "Send what has been written so far to the client", Category: "Streaming"},
+		"httpSendEvent":         {Description: "Write and flush one Server-Sent Event { data, event?, id?, retry? }", Category: "Streaming"},
+		"httpClosed":            {Description: "True once the client of a streamed response has disconnected", Category: "Streaming"},
+		"httpRequestStream":     {Description: "httpRequest that returns when the headers arrive, with the body as an HttpBodyStream; the timeout covers only the headers", Category: "Streaming"},
+		"httpReadChunk":         {Description: "Read the next chunk of a streamed body, None at the end", Category: "Streaming"},
+		"httpReadEvent":         {Description: "Read the next Server-Sent Event of a streamed body, None at the end", Category: "Streaming"},
+		"httpEvents":            {Description: "View a streamed body as Server-Sent Events for for ... in", Category: "Streaming"},
+		"httpStreamClose":       {Description: "Stop reading a streamed body and close the connection", Category: "Streaming"},
+		"httpParseMultipart":    {Description: "Parse a multipart/form-data request into fields and files (req, { maxMemory?, maxFileSize? }); large files go to temporary files removed after the request", Category: "Multipart"},
+		"httpReadUpload":        {Description: "Content of an uploaded file, from memory or its temporary file", Category: "Multipart"},
+		"httpMultipart":         {Description: "Build a multipart/form-data body (fields, files); returns (contentType, body) for httpRequest", Category: "Multipart"},
 	}
 	types := []*DocEntry{
 		{Name: "HttpResponse", Signature: "{ status: Int, body: String, headers: List<(String, String)> }", Description: "HTTP response type"},
-		{Name: "HttpRequest", Signature: "{ method: String, path: String, query: String, headers: List<(String, String)>, body: String }", Description: "HTTP request type (server)"},
+		{Name: "HttpRequest", Signature: "{ method: String, path: String, query: String, headers: List<(String, String)>, body: String, bodyStream: HttpBodyStream }", Description: "HTTP request type (server); with streamBody the body is only available from bodyStream"},
+		{Name: "HttpWriter", Signature: "opaque", Description: "Writes the body of a streamed response"},
+		{Name: "HttpStreamResponse", Signature: "{ status: Int, headers: List<(String, String)>, body: HttpBodyStream }", Description: "Response of httpRequestStream"},
+		{Name: "HttpBodyStream", Signature: "opaque", Description: "Response body read as it arrives; for ... in yields Bytes chunks"},
+		{Name: "HttpEventStream", Signature: "opaque", Description: "Response body read as Server-Sent Events; for ... in yields SseEvent"},
+		{Name: "UploadedFile", Signature: "{ field: String, filename: String, contentType: String, size: Int, data: Option<Bytes>, path: Option<String> }", Description: "A file of a multipart request, kept in memory (data) or in a temporary file (path)"},
+		{Name: "MultipartForm", Signature: "{ fields: List<(String, String)>, files: List<UploadedFile> }", Description: "Result of httpParseMultipart"},
+		{Name: "MultipartFile", Signature: "{ field: String, filename: String, contentType: String, data: Bytes }", Description: "A file to send with httpMultipart; an empty contentType means application/octet-stream"},
+		{Name: "SseEvent", Signature: "{ event: String, data: String, id: String, retry: Int }", Description: "A received Server-Sent Event (event defaults to \"message\")"},
 	}
 	pkg := generatePackageDocs("lib/http", "HTTP client and server. Use http+unix:///socket:/path for Unix domain sockets", meta, types)
 	RegisterDocPackage(pkg)
@@ -1174,6 +1286,15 @@ func initDateDocs() {
 		"dateOffset":     {Description: "Get offset in minutes from UTC", Category: "Offset"},
 		"dateWithOffset": {Description: "Change offset (adjusts time)", Category: "Offset"},
 
+		// Named zones
+		"zoneLoad":        {Description: "Load an IANA zone such as \"Europe/Berlin\" (tz database embedded) -> Result<String, Zone>", Category: "Zones"},
+		"zoneName":        {Description: "Name of a zone", Category: "Zones"},
+		"zoneOffsetAt":    {Description: "Offset of a zone in minutes at the instant of a date (DST-aware)", Category: "Zones"},
+		"dateNowIn":       {Description: "Current date/time in a zone", Category: "Zones"},
+		"dateNewIn":       {Description: "Create date (y, m, d, h, min, s, zone); skipped wall times move forward, repeated ones take the earlier offset", Category: "Zones"},
+		"dateToZone":      {Description: "Same instant with the zone's offset at that instant", Category: "Zones"},
+		"dateAddPeriodIn": {Description: "Add a Period to the wall time in a zone (09:00 + 1 day is 09:00 across DST)", Category: "Zones"},
+
 		// Formatting
 		"dateFormat": {Description: "Format date to string", Category: "Formatting"},
 		"dateParse":  {Description: "Parse string to date", Category: "Formatting"},
@@ -1198,11 +1319,23 @@ func initDateDocs() {
 		// Difference
 		"dateDiffDays":    {Description: "Difference in days", Category: "Difference"},
 		"dateDiffSeconds": {Description: "Difference in seconds", Category: "Difference"},
+
+		// Durations and periods
+		"durationParse":   {Description: "Parse ISO 8601 duration (\"PT1H30M\", \"P2DT3H\"; days are 24h, no years/months) -> Result<String, Duration>", Category: "Durations"},
+		"durationFormat":  {Description: "Format duration as ISO 8601 in hours, minutes, seconds (\"PT26H\")", Category: "Durations"},
+		"durationBetween": {Description: "Exact duration from the first date to the second", Category: "Durations"},
+		"periodParse":     {Description: "Parse ISO 8601 period (\"P1Y2M3D\", weeks are 7 days, no time part) -> Result<String, Period>", Category: "Durations"},
+		"periodFormat":    {Description: "Format period as ISO 8601 (\"P1Y2M3D\")", Category: "Durations"},
+		"dateAddDuration": {Description: "Add exact elapsed time (keeps offset)", Category: "Durations"},
+		"dateAddPeriod":   {Description: "Add calendar years, months, days (keeps offset)", Category: "Durations"},
 	}
 	types := []*DocEntry{
 		{Name: "Date", Signature: "{ year, month, day, hour, minute, second, offset: Int }", Description: "Date/time record with offset (minutes from UTC)"},
+		{Name: "Zone", Signature: "opaque", Description: "IANA time zone with its DST rules"},
+		{Name: "Duration", Signature: "{ seconds: Int }", Description: "Exact elapsed time"},
+		{Name: "Period", Signature: "{ years, months, days: Int }", Description: "Calendar time whose length depends on where it is added"},
 	}
-	pkg := generatePackageDocs("lib/date", "Date and time manipulation with timezone offset", meta, types)
+	pkg := generatePackageDocs("lib/date", "Date and time manipulation with offsets and named time zones", meta, types)
 	RegisterDocPackage(pkg)
 }
 
@@ -1213,20 +1346,31 @@ func initDateDocs() {
 func initWsDocs() {
 	meta := map[string]*DocMeta{
 		// Client
-		"wsConnect":        {Description: "Connect to WebSocket server (30s timeout)", Category: "Client"},
+		"wsConnect":        {Description: "Connect to WebSocket server; options { timeout?, protocols?, compress?, pingInterval?, pongTimeout?, maxMessageSize? } (default timeout 30s)", Category: "Client"},
 		"wsConnectTimeout": {Description: "Connect with custom timeout (ms)", Category: "Client"},
-		"wsSend":           {Description: "Send text message", Category: "Client"},
-		"wsRecv":           {Description: "Receive message (blocking)", Category: "Client"},
+		"wsRecv":           {Description: "Receive message as String (blocking)", Category: "Client"},
 		"wsRecvTimeout":    {Description: "Receive with timeout (returns Option)", Category: "Client"},
 		"wsClose":          {Description: "Close connection", Category: "Client"},
 
+		// Messages
+		"wsSend":               {Description: "Send text message to any connection", Category: "Messages"},
+		"wsSendBytes":          {Description: "Send binary message to any connection", Category: "Messages"},
+		"wsBroadcast":          {Description: "Send a String or Bytes message to several connections; returns how many it reached", Category: "Messages"},
+		"wsRecvMessage":        {Description: "Receive a text message as String, a binary one as Bytes (blocking)", Category: "Messages"},
+		"wsRecvMessageTimeout": {Description: "wsRecvMessage with a timeout (returns Option)", Category: "Messages"},
+		"wsProtocol":           {Description: "Subprotocol agreed in the handshake (\"\" if none)", Category: "Messages"},
+
 		// Server
-		"wsServe":             {Description: "Start blocking WebSocket server", Category: "Server"},
-		"wsServeAsync":        {Description: "Start non-blocking server (returns ID)", Category: "Server"},
+		"wsServe":             {Description: "Start blocking WebSocket server on a port or a config record { port, host?, certFile?, keyFile?, clientCAFile?, clientAuth?, readTimeout?, writeTimeout?, idleTimeout?, protocols?, compress?, pingInterval?, pongTimeout?, maxMessageSize? }; the handler is a reply function (connId, String) -> String or a record { onOpen?, onMessage?, onClose?, onError? }", Category: "Server"},
+		"wsServeAsync":        {Description: "Start non-blocking server (returns ID); takes a port or a config record and a handler like wsServe", Category: "Server"},
 		"wsServerStop":        {Description: "Stop async server by ID", Category: "Server"},
+		"wsServerConnections": {Description: "Open connections of an async server", Category: "Server"},
 		"wsSetMaxConnections": {Description: "Set max concurrent server connections (0=unlimited)", Category: "Config"},
 	}
-	pkg := generatePackageDocs("lib/ws", "WebSocket client and server (RFC 6455)", meta, nil)
+	types := []*DocEntry{
+		{Name: "WsMessage", Signature: "String | Bytes", Description: "A text or binary message"},
+	}
+	pkg := generatePackageDocs("lib/ws", "WebSocket client and server (RFC 6455)", meta, types)
 	RegisterDocPackage(pkg)
 }
 
@@ -1537,6 +1681,20 @@ func initYamlDocs() {
 	RegisterDocPackage(pkg)
 }
 
+// ============================================================================
+// lib/jsonschema
+// ============================================================================
+
+func initJsonSchemaDocs() {
+	meta := map[string]*DocMeta{
+		"jsonSchemaOf":       {Description: "Generate the JSON Schema (draft 2020-12) of a type: records, unions, ADTs, Option, List, Map", Category: "Generate"},
+		"jsonSchemaEncode":   {Description: "Encode a schema as a JSON string", Category: "Generate"},
+		"jsonSchemaValidate": {Description: "Validate a Json value against a schema, returning every violation with its JSON pointer", Category: "Validate"},
+	}
+	pkg := generatePackageDocs("lib/jsonschema", "JSON Schema generation from types and validation of Json values", meta, nil)
+	RegisterDocPackage(pkg)
+}
+
 // ============================================================================
 // lib/flag
 // ============================================================================
@@ -70,6 +70,7 @@ func InitVirtualPackages() {
 		initTaskPackage()
 		initCsvPackage()
 		initYamlPackage()
+		initJsonSchemaPackage()
 		initFlagPackage()
 		initGrpcPackage()
 		initProtoPackage()
@@ -88,6 +88,16 @@ func initJsonPackage() {
 				ReturnType: typesystem.TApp{Constructor: ListCon, Args: []typesystem.Type{stringType}},
 			},
 		},
+		Traits: map[string]*VirtualTrait{
+			// ToJson<t>: toJson(x) -> String, derivable; built-in types
+			// encode as jsonEncode does
+			"ToJson": {
+				TypeParams: []string{"t"},
+				Methods: map[string]typesystem.Type{
+					"toJson": typesystem.TFunc{Params: []typesystem.Type{typesystem.TVar{Name: "t"}}, ReturnType: stringType},
+				},
+			},
+		},
 	}
 	RegisterVirtualPackage("lib/json", pkg)
 }
@@ -129,6 +139,15 @@ func initCryptoPackage() {
 				ReturnType: stringType,
 			},
 		},
+		Traits: map[string]*VirtualTrait{
+			// Hash<t>: hash(x) -> Int, equal for equal values; derivable
+			"Hash": {
+				TypeParams: []string{"t"},
+				Methods: map[string]typesystem.Type{
+					"hash": typesystem.TFunc{Params: []typesystem.Type{typesystem.TVar{Name: "t"}}, ReturnType: typesystem.Int},
+				},
+			},
+		},
 	}
 	RegisterVirtualPackage("lib/crypto", pkg)
 }
@@ -235,10 +254,30 @@ func initDatePackage() {
 		Constructor: OptionCon,
 		Args:        []typesystem.Type{dateType},
 	}
+	// Zone is an IANA time zone, e.g. Europe/Berlin
+	zoneType := typesystem.TCon{Name: "Zone"}
+	// Duration = { seconds } is exact elapsed time
+	durationType := typesystem.TRecord{
+		Fields: map[string]typesystem.Type{"seconds": typesystem.Int},
+	}
+	// Period = { years, months, days } is calendar time
+	periodType := typesystem.TRecord{
+		Fields: map[string]typesystem.Type{
+			"years":  typesystem.Int,
+			"months": typesystem.Int,
+			"days":   typesystem.Int,
+		},
+	}
+	resultOf := func(t typesystem.Type) typesystem.Type {
+		return typesystem.TApp{Constructor: ResultCon, Args: []typesystem.Type{stringType, t}}
+	}
 	pkg := &VirtualPackage{
 		Name: "date",
 		Types: map[string]typesystem.Type{
-			"Date": dateType,
+			"Date":     dateType,
+			"Zone":     zoneType,
+			"Duration": durationType,
+			"Period":   periodType,
 		},
 		Symbols: map[string]typesystem.Type{
 			// Creation (dateNew and dateNewTime have optional offset, default = local)
@@ -261,6 +300,17 @@ func initDatePackage() {
 			"dateToLocal":    typesystem.TFunc{Params: []typesystem.Type{dateType}, ReturnType: dateType},
 			"dateOffset":     typesystem.TFunc{Params: []typesystem.Type{dateType}, ReturnType: typesystem.Int},
 			"dateWithOffset": typesystem.TFunc{Params: []typesystem.Type{dateType, typesystem.Int}, ReturnType: dateType},
+			// Named zones
+			"zoneLoad":     typesystem.TFunc{Params: []typesystem.Type{stringType}, ReturnType: resultOf(zoneType)},
+			"zoneName":     typesystem.TFunc{Params: []typesystem.Type{zoneType}, ReturnType: stringType},
+			"zoneOffsetAt": typesystem.TFunc{Params: []typesystem.Type{zoneType, dateType}, ReturnType: typesystem.Int},
+			"dateNowIn":    typesystem.TFunc{Params: []typesystem.Type{zoneType}, ReturnType: dateType},
+			"dateNewIn": typesystem.TFunc{
+				Params:     []typesystem.Type{typesystem.Int, typesystem.Int, typesystem.Int, typesystem.Int, typesystem.Int, typesystem.Int, zoneType},
+				ReturnType: dateType,
+			},
+			"dateToZone":      typesystem.TFunc{Params: []typesystem.Type{dateType, zoneType}, ReturnType: dateType},
+			"dateAddPeriodIn": typesystem.TFunc{Params: []typesystem.Type{dateType, periodType, zoneType}, ReturnType: dateType},
 			// Formatting
 			"dateFormat": typesystem.TFunc{Params: []typesystem.Type{dateType, stringType}, ReturnType: stringType},
 			"dateParse":  typesystem.TFunc{Params: []typesystem.Type{stringType, stringType}, ReturnType: optionDate},
@@ -282,6 +332,14 @@ func initDatePackage() {
 			// Difference
 			"dateDiffDays":    typesystem.TFunc{Params: []typesystem.Type{dateType, dateType}, ReturnType: typesystem.Int},
 			"dateDiffSeconds": typesystem.TFunc{Params: []typesystem.Type{dateType, dateType}, ReturnType: typesystem.Int},
+			// Durations and periods
+			"durationParse":   typesystem.TFunc{Params: []typesystem.Type{stringType}, ReturnType: resultOf(durationType)},
+			"durationFormat":  typesystem.TFunc{Params: []typesystem.Type{durationType}, ReturnType: stringType},
+			"durationBetween": typesystem.TFunc{Params: []typesystem.Type{dateType, dateType}, ReturnType: durationType},
+			"periodParse":     typesystem.TFunc{Params: []typesystem.Type{stringType}, ReturnType: resultOf(periodType)},
+			"periodFormat":    typesystem.TFunc{Params: []typesystem.Type{periodType}, ReturnType: stringType},
+			"dateAddDuration": typesystem.TFunc{Params: []typesystem.Type{dateType, durationType}, ReturnType: dateType},
+			"dateAddPeriod":   typesystem.TFunc{Params: []typesystem.Type{dateType, periodType}, ReturnType: dateType},
 		},
 	}
 	RegisterVirtualPackage("lib/date", pkg)
@@ -461,4 +519,49 @@ func initYamlPackage() {
 	RegisterVirtualPackage("lib/yaml", pkg)
 }
 
+// initJsonSchemaPackage registers the lib/jsonschema virtual package
+func initJsonSchemaPackage() {
+	stringType := typesystem.TApp{
+		Constructor: ListCon,
+		Args:        []typesystem.Type{typesystem.Char},
+	}
+	jsonType := typesystem.TCon{Name: "Json"}
+	// SchemaViolation: the JSON pointer of a value and what is wrong with it
+	violationType := typesystem.TRecord{Fields: map[string]typesystem.Type{
+		"path":    stringType,
+		"message": stringType,
+	}}
+
+	pkg := &VirtualPackage{
+		Name: "jsonschema",
+		Types: map[string]typesystem.Type{
+			"SchemaViolation": violationType,
+		},
+		Symbols: map[string]typesystem.Type{
+			// jsonSchemaOf(type: Type<T>) -> Json
+			// Generates the JSON Schema of a type
+			"jsonSchemaOf": typesystem.TFunc{
+				Params:     []typesystem.Type{typesystem.TType{Type: typesystem.TVar{Name: "T"}}},
+				ReturnType: jsonType,
+			},
+			// jsonSchemaEncode(schema: Json) -> String
+			// Encodes a schema as a JSON document
+			"jsonSchemaEncode": typesystem.TFunc{
+				Params:     []typesystem.Type{jsonType},
+				ReturnType: stringType,
+			},
+			// jsonSchemaValidate(schema: Json, value: Json) -> List<SchemaViolation>
+			// Validates a Json value, returning every violation
+			"jsonSchemaValidate": typesystem.TFunc{
+				Params: []typesystem.Type{jsonType, jsonType},
+				ReturnType: typesystem.TApp{
+					Constructor: ListCon,
+					Args:        []typesystem.Type{typesystem.TCon{Name: "SchemaViolation", UnderlyingType: violationType}},
+				},
+			},
+		},
+	}
+	RegisterVirtualPackage("lib/jsonschema", pkg)
+}
+
 // initFlagPackage registers the lib/flag virtual package
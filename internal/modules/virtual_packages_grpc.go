@@ -14,17 +14,71 @@ func initGrpcPackage() {
 	// Opaque types
 	grpcConnType := typesystem.TCon{Name: "GrpcConn"}
 	grpcServerType := typesystem.TCon{Name: "GrpcServer"}
+	grpcStreamType := typesystem.TCon{Name: "GrpcStream"}
 
 	// Generic types
 	typeA := typesystem.TVar{Name: "A"}
 	typeB := typesystem.TVar{Name: "B"}
 
+	// Metadata: List<(String, String)>, like HTTP headers
+	metadataType := typesystem.TApp{
+		Constructor: ListCon,
+		Args:        []typesystem.Type{typesystem.TTuple{Elements: []typesystem.Type{stringType, stringType}}},
+	}
+
+	// Status errors: GrpcStatus(code, message, details)
+	grpcCodeType := typesystem.TCon{Name: "GrpcCode"}
+	grpcErrorType := typesystem.TCon{Name: "GrpcError"}
+	grpcDetailType := typesystem.TRecord{Fields: map[string]typesystem.Type{
+		"typeName": stringType,
+		"value":    typesystem.Bytes,
+	}}
+	codeNames := []string{
+		"GrpcCancelled", "GrpcUnknown", "GrpcInvalidArgument", "GrpcDeadlineExceeded",
+		"GrpcNotFound", "GrpcAlreadyExists", "GrpcPermissionDenied", "GrpcResourceExhausted",
+		"GrpcFailedPrecondition", "GrpcAborted", "GrpcOutOfRange", "GrpcUnimplemented",
+		"GrpcInternal", "GrpcUnavailable", "GrpcDataLoss", "GrpcUnauthenticated",
+	}
+	constructors := map[string]typesystem.Type{
+		"GrpcStatus": typesystem.TFunc{
+			Params: []typesystem.Type{
+				grpcCodeType,
+				stringType,
+				typesystem.TApp{Constructor: ListCon, Args: []typesystem.Type{grpcDetailType}},
+			},
+			ReturnType: grpcErrorType,
+		},
+	}
+	for _, name := range codeNames {
+		constructors[name] = grpcCodeType
+	}
+
+	// The call an interceptor runs around
+	grpcCallType := typesystem.TRecord{Fields: map[string]typesystem.Type{
+		"method":   stringType,
+		"metadata": metadataType,
+		"peer":     stringType,
+	}}
+
+	// Client options: { tls?, caFile?, certFile?, keyFile?, serverName?, insecureSkipVerify? }
+	connectOptionsType := typesystem.TRecord{Fields: map[string]typesystem.Type{}, IsOpen: true}
+	// Call options: { metadata?, timeout? }
+	callOptionsType := typesystem.TRecord{Fields: map[string]typesystem.Type{}, IsOpen: true}
+	// Server options: { certFile?, keyFile?, clientCAFile?, clientAuth?, reflection?, interceptors? }
+	serverOptionsType := typesystem.TRecord{Fields: map[string]typesystem.Type{}, IsOpen: true}
+
 	// Result<String, GrpcConn>
 	resultConn := typesystem.TApp{
 		Constructor: ResultCon,
 		Args:        []typesystem.Type{stringType, grpcConnType},
 	}
 
+	// Result<GrpcError, B>
+	resultStatusB := typesystem.TApp{
+		Constructor: ResultCon,
+		Args:        []typesystem.Type{grpcErrorType, typeB},
+	}
+
 	// Result<String, Nil>
 	resultNil := typesystem.TApp{
 		Constructor: ResultCon,
@@ -37,17 +91,43 @@ func initGrpcPackage() {
 		Args:        []typesystem.Type{stringType, typeB},
 	}
 
+	// Result<String, GrpcStream>
+	resultStream := typesystem.TApp{
+		Constructor: ResultCon,
+		Args:        []typesystem.Type{stringType, grpcStreamType},
+	}
+
+	// Result<String, Option<B>>
+	resultOptionB := typesystem.TApp{
+		Constructor: ResultCon,
+		Args: []typesystem.Type{stringType, typesystem.TApp{
+			Constructor: OptionCon,
+			Args:        []typesystem.Type{typeB},
+		}},
+	}
+
 	pkg := &VirtualPackage{
 		Name: "grpc",
 		Types: map[string]typesystem.Type{
 			"GrpcConn":   grpcConnType,
 			"GrpcServer": grpcServerType,
+			"GrpcStream": grpcStreamType,
+			"GrpcCode":   grpcCodeType,
+			"GrpcError":  grpcErrorType,
+			"GrpcDetail": grpcDetailType,
+			"GrpcCall":   grpcCallType,
+		},
+		Constructors: constructors,
+		Variants: map[string][]string{
+			"GrpcCode":  codeNames,
+			"GrpcError": {"GrpcStatus"},
 		},
 		Symbols: map[string]typesystem.Type{
-			// Connect
+			// Connect: (Target, Options?) -> Result<String, GrpcConn>
 			"grpcConnect": typesystem.TFunc{
-				Params:     []typesystem.Type{stringType},
-				ReturnType: resultConn,
+				Params:       []typesystem.Type{stringType, connectOptionsType},
+				ReturnType:   resultConn,
+				DefaultCount: 1,
 			},
 			// Close
 			"grpcClose": typesystem.TFunc{
@@ -59,15 +139,71 @@ func initGrpcPackage() {
 				Params:     []typesystem.Type{stringType},
 				ReturnType: resultNil,
 			},
-			// Invoke: (GrpcConn, MethodName, Request) -> Result<String, Response>
+			// Invoke: (GrpcConn, MethodName, Request, Options?) -> Result<String, Response>
 			"grpcInvoke": typesystem.TFunc{
-				Params:     []typesystem.Type{grpcConnType, stringType, typeA},
+				Params:       []typesystem.Type{grpcConnType, stringType, typeA, callOptionsType},
+				ReturnType:   resultB,
+				DefaultCount: 1,
+			},
+			// Call: grpcInvoke failing with a GrpcError
+			"grpcCall": typesystem.TFunc{
+				Params:       []typesystem.Type{grpcConnType, stringType, typeA, callOptionsType},
+				ReturnType:   resultStatusB,
+				DefaultCount: 1,
+			},
+			// Server-streaming call: (GrpcConn, MethodName, Request, Options?) -> Result<String, GrpcStream>
+			"grpcInvokeStream": typesystem.TFunc{
+				Params:       []typesystem.Type{grpcConnType, stringType, typeA, callOptionsType},
+				ReturnType:   resultStream,
+				DefaultCount: 1,
+			},
+			"grpcInvokeStreamTimeout": typesystem.TFunc{
+				Params:     []typesystem.Type{grpcConnType, stringType, typeA, typesystem.Int},
+				ReturnType: resultStream,
+			},
+			// Client-streaming or bidirectional call: (GrpcConn, MethodName, Options?) -> Result<String, GrpcStream>
+			"grpcOpenStream": typesystem.TFunc{
+				Params:       []typesystem.Type{grpcConnType, stringType, callOptionsType},
+				ReturnType:   resultStream,
+				DefaultCount: 1,
+			},
+			"grpcOpenStreamTimeout": typesystem.TFunc{
+				Params:     []typesystem.Type{grpcConnType, stringType, typesystem.Int},
+				ReturnType: resultStream,
+			},
+			// Send: (GrpcStream, Message) -> Result<String, Nil>
+			"grpcSend": typesystem.TFunc{
+				Params:     []typesystem.Type{grpcStreamType, typeA},
+				ReturnType: resultNil,
+			},
+			// Recv: (GrpcStream) -> Result<String, Option<Message>>, None at the end
+			"grpcRecv": typesystem.TFunc{
+				Params:     []typesystem.Type{grpcStreamType},
+				ReturnType: resultOptionB,
+			},
+			"grpcCloseSend": typesystem.TFunc{
+				Params:     []typesystem.Type{grpcStreamType},
+				ReturnType: resultNil,
+			},
+			// CloseAndRecv: (GrpcStream) -> Result<String, Response>
+			"grpcCloseAndRecv": typesystem.TFunc{
+				Params:     []typesystem.Type{grpcStreamType},
 				ReturnType: resultB,
 			},
-			// Server
+			"grpcCancel": typesystem.TFunc{
+				Params:     []typesystem.Type{grpcStreamType},
+				ReturnType: nilType,
+			},
+			// Server: (Options?) -> GrpcServer
 			"grpcServer": typesystem.TFunc{
+				Params:       []typesystem.Type{serverOptionsType},
+				ReturnType:   grpcServerType,
+				DefaultCount: 1,
+			},
+			// Metadata of the call being handled
+			"grpcMetadata": typesystem.TFunc{
 				Params:     []typesystem.Type{},
-				ReturnType: grpcServerType,
+				ReturnType: metadataType,
 			},
 			// Register: (GrpcServer, ServiceName, Implementation) -> Result<String, Nil>
 			"grpcRegister": typesystem.TFunc{
@@ -133,8 +133,33 @@ func initSysPackage() {
 			"stderr": stringType,
 		},
 	}
+	processType := typesystem.TCon{Name: "Process"}
+	bytesType := typesystem.Bytes
+	// String | Bytes
+	stringOrBytes := typesystem.TUnion{
+		Types: []typesystem.Type{stringType, bytesType},
+	}
+	// Optional spawn settings: { cwd, env, clearEnv, stdin, stdout, stderr }
+	processOptions := typesystem.TRecord{Fields: map[string]typesystem.Type{}, IsOpen: true}
+	// List<(String, List<String>)>
+	commandList := typesystem.TApp{
+		Constructor: ListCon,
+		Args:        []typesystem.Type{typesystem.TTuple{Elements: []typesystem.Type{stringType, listString}}},
+	}
+	resultProcess := typesystem.TApp{Constructor: ResultCon, Args: []typesystem.Type{stringType, processType}}
+	resultNil := typesystem.TApp{Constructor: ResultCon, Args: []typesystem.Type{stringType, typesystem.Nil}}
+	resultInt := typesystem.TApp{Constructor: ResultCon, Args: []typesystem.Type{stringType, typesystem.Int}}
+	resultOptionInt := typesystem.TApp{
+		Constructor: ResultCon,
+		Args:        []typesystem.Type{stringType, typesystem.TApp{Constructor: OptionCon, Args: []typesystem.Type{typesystem.Int}}},
+	}
+	optionBytes := typesystem.TApp{Constructor: OptionCon, Args: []typesystem.Type{bytesType}}
+
 	pkg := &VirtualPackage{
 		Name: "sys",
+		Types: map[string]typesystem.Type{
+			"Process": processType,
+		},
 		Symbols: map[string]typesystem.Type{
 			// Command line arguments
 			"sysArgs": typesystem.TFunc{Params: []typesystem.Type{}, ReturnType: listString},
@@ -150,6 +175,25 @@ func initSysPackage() {
 			"sysCPUCount": typesystem.TFunc{Params: []typesystem.Type{}, ReturnType: typesystem.Int},
 			// Directory of the currently running script
 			"sysScriptDir": typesystem.TFunc{Params: []typesystem.Type{}, ReturnType: stringType},
+			// Start a command: sysSpawn(cmd, args, opts = {}) -> Result<String, Process>
+			"sysSpawn": typesystem.TFunc{Params: []typesystem.Type{stringType, listString, processOptions}, ReturnType: resultProcess, DefaultCount: 1},
+			// Start commands connected stdout to stdin: sysPipeline([(cmd, args)], opts = {})
+			"sysPipeline": typesystem.TFunc{Params: []typesystem.Type{commandList, processOptions}, ReturnType: resultProcess, DefaultCount: 1},
+			// Next line of stdout / stderr, None once the process has exited and it is all read
+			"sysReadLine":    typesystem.TFunc{Params: []typesystem.Type{processType}, ReturnType: optionString},
+			"sysReadErrLine": typesystem.TFunc{Params: []typesystem.Type{processType}, ReturnType: optionString},
+			// Up to n bytes of stdout / stderr
+			"sysRead":    typesystem.TFunc{Params: []typesystem.Type{processType, typesystem.Int}, ReturnType: optionBytes},
+			"sysReadErr": typesystem.TFunc{Params: []typesystem.Type{processType, typesystem.Int}, ReturnType: optionBytes},
+			// Write to stdin
+			"sysWrite":      typesystem.TFunc{Params: []typesystem.Type{processType, stringOrBytes}, ReturnType: resultNil},
+			"sysCloseStdin": typesystem.TFunc{Params: []typesystem.Type{processType}, ReturnType: resultNil},
+			// Wait for exit: exit code; with timeout, None if still running
+			"sysWait":        typesystem.TFunc{Params: []typesystem.Type{processType}, ReturnType: resultInt},
+			"sysWaitTimeout": typesystem.TFunc{Params: []typesystem.Type{processType, typesystem.Int}, ReturnType: resultOptionInt},
+			"sysKill":        typesystem.TFunc{Params: []typesystem.Type{processType}, ReturnType: resultNil},
+			"sysSignal":      typesystem.TFunc{Params: []typesystem.Type{processType, stringType}, ReturnType: resultNil},
+			"sysPid":         typesystem.TFunc{Params: []typesystem.Type{processType}, ReturnType: typesystem.Int},
 		},
 	}
 	RegisterVirtualPackage("lib/sys", pkg)
@@ -332,26 +332,87 @@ func initWsPackage() {
 		Args:        []typesystem.Type{stringType, optionString},
 	}
 
-	// Handler type: (Int, String) -> String
-	handlerType := typesystem.TFunc{
-		Params:     []typesystem.Type{typesystem.Int, stringType},
-		ReturnType: stringType,
+	// WsMessage = String | Bytes: text and binary messages
+	messageType := typesystem.TUnion{
+		Types: []typesystem.Type{stringType, typesystem.Bytes},
+	}
+	resultMessage := typesystem.TApp{
+		Constructor: ResultCon,
+		Args:        []typesystem.Type{stringType, messageType},
+	}
+	resultOptionMessage := typesystem.TApp{
+		Constructor: ResultCon,
+		Args: []typesystem.Type{stringType, typesystem.TApp{
+			Constructor: OptionCon,
+			Args:        []typesystem.Type{messageType},
+		}},
+	}
+	listInt := typesystem.TApp{
+		Constructor: ListCon,
+		Args:        []typesystem.Type{typesystem.Int},
+	}
+
+	// Handler: a reply function (Int, String) -> String, or a record of
+	// callbacks, all optional:
+	// { onOpen: (Int) -> A, onMessage: (Int, WsMessage) -> A, onClose: (Int) -> A, onError: (Int, String) -> A }
+	handlersType := typesystem.TRecord{Fields: map[string]typesystem.Type{}, IsOpen: true}
+	handlerType := typesystem.TUnion{
+		Types: []typesystem.Type{
+			typesystem.TFunc{
+				Params:     []typesystem.Type{typesystem.Int, stringType},
+				ReturnType: stringType,
+			},
+			handlersType,
+		},
 	}
 
+	// Port or config record: { port: Int, host?, certFile?, keyFile?, clientCAFile?,
+	// clientAuth?, readTimeout?, writeTimeout?, idleTimeout?, protocols?, compress?,
+	// pingInterval?, pongTimeout?, maxMessageSize? }
+	serverConfigType := typesystem.TUnion{
+		Types: []typesystem.Type{
+			typesystem.Int,
+			typesystem.TRecord{Fields: map[string]typesystem.Type{"port": typesystem.Int}, IsOpen: true},
+		},
+	}
+
+	// Client options: { timeout?, protocols?, compress?, pingInterval?, pongTimeout?,
+	// maxMessageSize? }
+	connectOptionsType := typesystem.TRecord{Fields: map[string]typesystem.Type{}, IsOpen: true}
+
 	pkg := &VirtualPackage{
 		Name: "ws",
 		Symbols: map[string]typesystem.Type{
-			"wsConnect":           typesystem.TFunc{Params: []typesystem.Type{stringType}, ReturnType: resultInt},
-			"wsConnectTimeout":    typesystem.TFunc{Params: []typesystem.Type{stringType, typesystem.Int}, ReturnType: resultInt},
-			"wsSend":              typesystem.TFunc{Params: []typesystem.Type{typesystem.Int, stringType}, ReturnType: resultNil},
-			"wsRecv":              typesystem.TFunc{Params: []typesystem.Type{typesystem.Int}, ReturnType: resultString},
-			"wsRecvTimeout":       typesystem.TFunc{Params: []typesystem.Type{typesystem.Int, typesystem.Int}, ReturnType: resultStringOptionString},
-			"wsClose":             typesystem.TFunc{Params: []typesystem.Type{typesystem.Int}, ReturnType: resultNil},
-			"wsServe":             typesystem.TFunc{Params: []typesystem.Type{typesystem.Int, handlerType}, ReturnType: resultNil},
-			"wsServeAsync":        typesystem.TFunc{Params: []typesystem.Type{typesystem.Int, handlerType}, ReturnType: resultInt},
-			"wsServerStop":        typesystem.TFunc{Params: []typesystem.Type{typesystem.Int}, ReturnType: resultNil},
+			"wsConnect": typesystem.TFunc{
+				Params:       []typesystem.Type{stringType, connectOptionsType},
+				ReturnType:   resultInt,
+				DefaultCount: 1,
+			},
+			"wsConnectTimeout":     typesystem.TFunc{Params: []typesystem.Type{stringType, typesystem.Int}, ReturnType: resultInt},
+			"wsSend":               typesystem.TFunc{Params: []typesystem.Type{typesystem.Int, stringType}, ReturnType: resultNil},
+			"wsSendBytes":          typesystem.TFunc{Params: []typesystem.Type{typesystem.Int, typesystem.Bytes}, ReturnType: resultNil},
+			"wsBroadcast":          typesystem.TFunc{Params: []typesystem.Type{listInt, messageType}, ReturnType: typesystem.Int},
+			"wsRecv":               typesystem.TFunc{Params: []typesystem.Type{typesystem.Int}, ReturnType: resultString},
+			"wsRecvTimeout":        typesystem.TFunc{Params: []typesystem.Type{typesystem.Int, typesystem.Int}, ReturnType: resultStringOptionString},
+			"wsRecvMessage":        typesystem.TFunc{Params: []typesystem.Type{typesystem.Int}, ReturnType: resultMessage},
+			"wsRecvMessageTimeout": typesystem.TFunc{Params: []typesystem.Type{typesystem.Int, typesystem.Int}, ReturnType: resultOptionMessage},
+			"wsProtocol":           typesystem.TFunc{Params: []typesystem.Type{typesystem.Int}, ReturnType: resultString},
+			"wsClose":              typesystem.TFunc{Params: []typesystem.Type{typesystem.Int}, ReturnType: resultNil},
+			"wsServe":              typesystem.TFunc{Params: []typesystem.Type{serverConfigType, handlerType}, ReturnType: resultNil},
+			"wsServeAsync":         typesystem.TFunc{Params: []typesystem.Type{serverConfigType, handlerType}, ReturnType: resultInt},
+			"wsServerStop":         typesystem.TFunc{Params: []typesystem.Type{typesystem.Int}, ReturnType: resultNil},
+			"wsServerConnections": typesystem.TFunc{
+				Params: []typesystem.Type{typesystem.Int},
+				ReturnType: typesystem.TApp{
+					Constructor: ResultCon,
+					Args:        []typesystem.Type{stringType, listInt},
+				},
+			},
 			"wsSetMaxConnections": typesystem.TFunc{Params: []typesystem.Type{typesystem.Int}, ReturnType: typesystem.Nil},
 		},
+		Types: map[string]typesystem.Type{
+			"WsMessage": messageType,
+		},
 	}
 
 	RegisterVirtualPackage("lib/ws", pkg)
@@ -39,14 +39,30 @@ func initHttpPackage() {
 		},
 	}
 
-	// HttpRequest = { method: String, path: String, query: String, headers: List<(String, String)>, body: String }
+	// Streaming: the writer of a streamed response and a body read as it arrives
+	writerType := typesystem.TCon{Name: "HttpWriter"}
+	bodyStreamType := typesystem.TCon{Name: "HttpBodyStream"}
+	eventStreamType := typesystem.TCon{Name: "HttpEventStream"}
+
+	// HttpRequest = { method: String, path: String, query: String, headers: List<(String, String)>,
+	//                 body: String, bodyStream: HttpBodyStream }
 	requestType := typesystem.TRecord{
 		Fields: map[string]typesystem.Type{
-			"method":  stringType,
-			"path":    stringType,
-			"query":   stringType,
-			"headers": headersType,
-			"body":    stringType,
+			"method":     stringType,
+			"path":       stringType,
+			"query":      stringType,
+			"headers":    headersType,
+			"body":       stringType,
+			"bodyStream": bodyStreamType,
+		},
+	}
+
+	// Port or config record: { port: Int, host?, certFile?, keyFile?, clientCAFile?,
+	// clientAuth?, readTimeout?, writeTimeout?, idleTimeout?, http2?, maxBodySize?, streamBody? }
+	serverConfigType := typesystem.TUnion{
+		Types: []typesystem.Type{
+			typesystem.Int,
+			typesystem.TRecord{Fields: map[string]typesystem.Type{"port": typesystem.Int}, IsOpen: true},
 		},
 	}
 
@@ -56,6 +72,81 @@ func initHttpPackage() {
 		Args:        []typesystem.Type{stringType, responseType},
 	}
 
+	producerType := typesystem.TFunc{
+		Params:     []typesystem.Type{writerType},
+		ReturnType: typesystem.TVar{Name: "A"},
+	}
+
+	// SseEvent = { event: String, data: String, id: String, retry: Int }
+	sseEventType := typesystem.TRecord{
+		Fields: map[string]typesystem.Type{
+			"event": stringType,
+			"data":  stringType,
+			"id":    stringType,
+			"retry": typesystem.Int,
+		},
+	}
+
+	// Event to send: { data: String, event?, id?, retry? }
+	sseEventInput := typesystem.TRecord{
+		Fields: map[string]typesystem.Type{"data": stringType},
+		IsOpen: true,
+	}
+
+	// HttpStreamResponse = { status: Int, headers: List<(String, String)>, body: HttpBodyStream }
+	streamResponseType := typesystem.TRecord{
+		Fields: map[string]typesystem.Type{
+			"status":  typesystem.Int,
+			"headers": headersType,
+			"body":    bodyStreamType,
+		},
+	}
+
+	// UploadedFile = { field, filename, contentType: String, size: Int, data: Option<Bytes>, path: Option<String> }
+	// Small files are kept in data, larger ones in a temporary file at path
+	uploadedFileType := typesystem.TRecord{
+		Fields: map[string]typesystem.Type{
+			"field":       stringType,
+			"filename":    stringType,
+			"contentType": stringType,
+			"size":        typesystem.Int,
+			"data": typesystem.TApp{
+				Constructor: typesystem.TCon{Name: "Option"},
+				Args:        []typesystem.Type{bytesType},
+			},
+			"path": typesystem.TApp{
+				Constructor: typesystem.TCon{Name: "Option"},
+				Args:        []typesystem.Type{stringType},
+			},
+		},
+	}
+
+	// MultipartForm = { fields: List<(String, String)>, files: List<UploadedFile> }
+	multipartFormType := typesystem.TRecord{
+		Fields: map[string]typesystem.Type{
+			"fields": headersType,
+			"files": typesystem.TApp{
+				Constructor: typesystem.TCon{Name: "List"},
+				Args:        []typesystem.Type{uploadedFileType},
+			},
+		},
+	}
+
+	// MultipartFile = { field: String, filename: String, contentType: String, data: Bytes }
+	multipartFileType := typesystem.TRecord{
+		Fields: map[string]typesystem.Type{
+			"field":       stringType,
+			"filename":    stringType,
+			"contentType": stringType,
+			"data":        bytesType,
+		},
+	}
+
+	resultNil := typesystem.TApp{
+		Constructor: typesystem.TCon{Name: "Result"},
+		Args:        []typesystem.Type{stringType, typesystem.Nil},
+	}
+
 	pkg := &VirtualPackage{
 		Name: "http",
 		Symbols: map[string]typesystem.Type{
@@ -116,11 +207,11 @@ func initHttpPackage() {
 
 			// ========== Server functions ==========
 
-			// httpServe: (Int, (HttpRequest) -> HttpResponse) -> Result<String, Nil>
+			// httpServe: (Int | HttpServerConfig, (HttpRequest) -> HttpResponse) -> Result<String, Nil>
 			// Starts server and blocks, calling handler for each request
 			"httpServe": typesystem.TFunc{
 				Params: []typesystem.Type{
-					typesystem.Int,
+					serverConfigType,
 					typesystem.TFunc{
 						Params: []typesystem.Type{
 							// HttpRequest record
@@ -135,11 +226,11 @@ func initHttpPackage() {
 				},
 			},
 
-			// httpServeAsync: (Int, (HttpRequest) -> HttpResponse) -> Int
+			// httpServeAsync: (Int | HttpServerConfig, (HttpRequest) -> HttpResponse) -> Int
 			// Starts server in background, returns server ID
 			"httpServeAsync": typesystem.TFunc{
 				Params: []typesystem.Type{
-					typesystem.Int,
+					serverConfigType,
 					typesystem.TFunc{
 						Params: []typesystem.Type{
 							requestType,
@@ -157,10 +248,136 @@ func initHttpPackage() {
 				ReturnType:   typesystem.Nil,
 				DefaultCount: 1,
 			},
+
+			// ========== Streaming ==========
+
+			// httpStream: (Int, List<(String, String)>, (HttpWriter) -> A) -> HttpResponse
+			// The producer writes the body after the handler returns
+			"httpStream": typesystem.TFunc{
+				Params:     []typesystem.Type{typesystem.Int, headersType, producerType},
+				ReturnType: responseType,
+			},
+
+			// httpEventStream: ((HttpWriter) -> A, List<(String, String)>) -> HttpResponse
+			// A Server-Sent Events response; extra headers are optional
+			"httpEventStream": typesystem.TFunc{
+				Params:       []typesystem.Type{producerType, headersType},
+				ReturnType:   responseType,
+				DefaultCount: 1,
+			},
+
+			// httpWrite: (HttpWriter, String | Bytes) -> Result<String, Nil>
+			"httpWrite": typesystem.TFunc{
+				Params:     []typesystem.Type{writerType, stringOrBytes},
+				ReturnType: resultNil,
+			},
+
+			// httpFlush: (HttpWriter) -> Result<String, Nil>
+			"httpFlush": typesystem.TFunc{
+				Params:     []typesystem.Type{writerType},
+				ReturnType: resultNil,
+			},
+
+			// httpSendEvent: (HttpWriter, { data: String, event?, id?, retry? }) -> Result<String, Nil>
+			"httpSendEvent": typesystem.TFunc{
+				Params:     []typesystem.Type{writerType, sseEventInput},
+				ReturnType: resultNil,
+			},
+
+			// httpClosed: (HttpWriter) -> Bool
+			"httpClosed": typesystem.TFunc{
+				Params:     []typesystem.Type{writerType},
+				ReturnType: typesystem.Bool,
+			},
+
+			// httpRequestStream: same arguments as httpRequest, the body is read as it arrives
+			"httpRequestStream": typesystem.TFunc{
+				Params: []typesystem.Type{stringType, stringType, headersType, stringOrBytes, typesystem.Int},
+				ReturnType: typesystem.TApp{
+					Constructor: typesystem.TCon{Name: "Result"},
+					Args:        []typesystem.Type{stringType, streamResponseType},
+				},
+				DefaultCount: 2,
+			},
+
+			// httpReadChunk: (HttpBodyStream) -> Result<String, Option<Bytes>>
+			"httpReadChunk": typesystem.TFunc{
+				Params: []typesystem.Type{bodyStreamType},
+				ReturnType: typesystem.TApp{
+					Constructor: typesystem.TCon{Name: "Result"},
+					Args: []typesystem.Type{stringType, typesystem.TApp{
+						Constructor: typesystem.TCon{Name: "Option"},
+						Args:        []typesystem.Type{bytesType},
+					}},
+				},
+			},
+
+			// httpReadEvent: (HttpBodyStream) -> Result<String, Option<SseEvent>>
+			"httpReadEvent": typesystem.TFunc{
+				Params: []typesystem.Type{bodyStreamType},
+				ReturnType: typesystem.TApp{
+					Constructor: typesystem.TCon{Name: "Result"},
+					Args: []typesystem.Type{stringType, typesystem.TApp{
+						Constructor: typesystem.TCon{Name: "Option"},
+						Args:        []typesystem.Type{sseEventType},
+					}},
+				},
+			},
+
+			// httpEvents: (HttpBodyStream) -> HttpEventStream, for for-in loops over events
+			"httpEvents": typesystem.TFunc{
+				Params:     []typesystem.Type{bodyStreamType},
+				ReturnType: eventStreamType,
+			},
+
+			// httpStreamClose: (HttpBodyStream) -> Nil
+			"httpStreamClose": typesystem.TFunc{
+				Params:     []typesystem.Type{bodyStreamType},
+				ReturnType: typesystem.Nil,
+			},
+
+			// ========== Multipart ==========
+
+			// httpParseMultipart: (HttpRequest, { maxMemory?, maxFileSize? }) -> Result<String, MultipartForm>
+			"httpParseMultipart": typesystem.TFunc{
+				Params: []typesystem.Type{requestType, typesystem.TRecord{Fields: map[string]typesystem.Type{}, IsOpen: true}},
+				ReturnType: typesystem.TApp{
+					Constructor: typesystem.TCon{Name: "Result"},
+					Args:        []typesystem.Type{stringType, multipartFormType},
+				},
+				DefaultCount: 1,
+			},
+
+			// httpReadUpload: (UploadedFile) -> Result<String, Bytes>
+			"httpReadUpload": typesystem.TFunc{
+				Params: []typesystem.Type{uploadedFileType},
+				ReturnType: typesystem.TApp{
+					Constructor: typesystem.TCon{Name: "Result"},
+					Args:        []typesystem.Type{stringType, bytesType},
+				},
+			},
+
+			// httpMultipart: (List<(String, String)>, List<MultipartFile>) -> (String, Bytes)
+			// Returns the Content-Type header value and the body
+			"httpMultipart": typesystem.TFunc{
+				Params: []typesystem.Type{headersType, typesystem.TApp{
+					Constructor: typesystem.TCon{Name: "List"},
+					Args:        []typesystem.Type{multipartFileType},
+				}},
+				ReturnType: typesystem.TTuple{Elements: []typesystem.Type{stringType, bytesType}},
+			},
 		},
 		Types: map[string]typesystem.Type{
-			"HttpRequest":  requestType,
-			"HttpResponse": responseType,
+			"HttpRequest":        requestType,
+			"HttpResponse":       responseType,
+			"HttpWriter":         writerType,
+			"HttpBodyStream":     bodyStreamType,
+			"HttpEventStream":    eventStreamType,
+			"HttpStreamResponse": streamResponseType,
+			"SseEvent":           sseEventType,
+			"UploadedFile":       uploadedFileType,
+			"MultipartForm":      multipartFormType,
+			"MultipartFile":      multipartFileType,
 		},
 	}
 
@@ -66,27 +66,28 @@ func IsPureVirtualPackage(path string) bool {
 	// Only pure math/data structures/formatting are allowed by default.
 	// All I/O, network, OS interactons are dirty.
 	purePackages := map[string]bool{
-		"lib/bignum": true,
-		"lib/bits":   true,
-		"lib/bytes":  true,
-		"lib/char":   true,
-		"lib/crypto": true,
-		"lib/date":   true,
-		"lib/flag":   true,
-		"lib/json":   true,
-		"lib/list":   true,
-		"lib/map":    true,
-		"lib/math":   true,
-		"lib/path":   true,
-		"lib/rand":   true,
-		"lib/regex":  true,
-		"lib/string": true,
-		"lib/test":   true,
-		"lib/time":   true,
-		"lib/tuple":  true,
-		"lib/url":    true,
-		"lib/uuid":   true,
-		"lib/yaml":   true,
+		"lib/bignum":     true,
+		"lib/bits":       true,
+		"lib/bytes":      true,
+		"lib/char":       true,
+		"lib/crypto":     true,
+		"lib/date":       true,
+		"lib/flag":       true,
+		"lib/json":       true,
+		"lib/jsonschema": true,
+		"lib/list":       true,
+		"lib/map":        true,
+		"lib/math":       true,
+		"lib/path":       true,
+		"lib/rand":       true,
+		"lib/regex":      true,
+		"lib/string":     true,
+		"lib/test":       true,
+		"lib/time":       true,
+		"lib/tuple":      true,
+		"lib/url":        true,
+		"lib/uuid":       true,
+		"lib/yaml":       true,
 	}
 
 	// 'lib' meta package is allowed to import pure sub-packages
@@ -169,9 +170,14 @@ func (vp *VirtualPackage) CreateVirtualModule() *Module {
 		mod.Exports[traitName] = true
 		mod.SymbolTable.DefineTrait(traitName, trait.TypeParams, trait.SuperTraits, origin)
 
-		// Register kind if specified
+		// Register kind if specified; type parameters are of kind * otherwise
+		var paramKind typesystem.Kind = typesystem.Star
 		if trait.Kind != nil {
 			mod.SymbolTable.RegisterKind(traitName, trait.Kind)
+			paramKind = trait.Kind
+		}
+		for _, param := range trait.TypeParams {
+			mod.SymbolTable.RegisterTraitTypeParamKind(traitName, param, paramKind)
 		}
 
 		// Register trait methods
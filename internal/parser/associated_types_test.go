@@ -0,0 +1,63 @@
+package parser_test
+
+import (
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/parser"
+	"github.com/funvibe/funxy/internal/pipeline"
+	"testing"
+)
+
+func TestAssociatedTypes(t *testing.T) {
+	input := `trait Container<c> {
+    type Elem
+    fun cfirst(coll: c) -> Elem<c>
+}
+instance Container List<t> {
+    type Elem = t
+    fun cfirst(coll: List<t>) -> t { coll[0] }
+}`
+	ctx := pipeline.NewPipelineContext(input)
+	ctx = (&lexer.LexerProcessor{}).Process(ctx)
+	ctx = (&parser.ParserProcessor{}).Process(ctx)
+	if len(ctx.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", ctx.Errors)
+	}
+	program := ctx.AstRoot.(*ast.Program)
+
+	trait := program.Statements[0].(*ast.TraitDeclaration)
+	if len(trait.AssociatedTypes) != 1 || trait.AssociatedTypes[0].Name.Value != "Elem" {
+		t.Fatalf("expected trait to declare Elem, got %v", trait.AssociatedTypes)
+	}
+	if trait.AssociatedTypes[0].Type != nil {
+		t.Errorf("expected no definition in the trait")
+	}
+	if len(trait.Signatures) != 1 {
+		t.Errorf("expected 1 method signature, got %d", len(trait.Signatures))
+	}
+
+	inst := program.Statements[1].(*ast.InstanceDeclaration)
+	if len(inst.AssociatedTypes) != 1 || inst.AssociatedTypes[0].Name.Value != "Elem" {
+		t.Fatalf("expected instance to define Elem, got %v", inst.AssociatedTypes)
+	}
+	if def, ok := inst.AssociatedTypes[0].Type.(*ast.NamedType); !ok || def.Name.Value != "t" {
+		t.Errorf("expected Elem = t, got %v", inst.AssociatedTypes[0].Type)
+	}
+	if len(inst.Methods) != 1 {
+		t.Errorf("expected 1 method, got %d", len(inst.Methods))
+	}
+}
+
+func TestAssociatedTypeErrors(t *testing.T) {
+	for _, input := range []string{
+		"trait Container<c> { type elem }",
+		"instance Container IntBag { type Elem = }",
+	} {
+		ctx := pipeline.NewPipelineContext(input)
+		ctx = (&lexer.LexerProcessor{}).Process(ctx)
+		ctx = (&parser.ParserProcessor{}).Process(ctx)
+		if len(ctx.Errors) == 0 {
+			t.Errorf("expected a parse error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package parser_test
+
+import (
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/parser"
+	"github.com/funvibe/funxy/internal/pipeline"
+	"strings"
+	"testing"
+)
+
+func TestDerivingClause(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		constructors int
+		want         string
+	}{
+		{"parenthesized list", "type Color = Red | Green deriving (Equal, Show)", 2, "Equal,Show"},
+		{"single trait", "type Color = Red | Green deriving Show", 2, "Show"},
+		{"ML-style constructor", "type Id = Id Int deriving (Equal)", 1, "Equal"},
+		{"next line", "type Color = Red | Green\n    deriving (Order)", 2, "Order"},
+		{"record alias", "type alias P = { x: Int }\nderiving (Default)", 0, "Default"},
+		{"none", "type Color = Red | Green\nx = 1", 2, ""},
+		{"name on next line", "type Color = Red | Green\nderiving = 1", 2, ""},
+		{"type variable", "type Box<deriving> = Box deriving", 1, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := pipeline.NewPipelineContext(tc.input)
+			ctx = (&lexer.LexerProcessor{}).Process(ctx)
+			ctx = (&parser.ParserProcessor{}).Process(ctx)
+			if len(ctx.Errors) > 0 {
+				t.Fatalf("unexpected parse errors: %v", ctx.Errors)
+			}
+			td := ctx.AstRoot.(*ast.Program).Statements[0].(*ast.TypeDeclarationStatement)
+			if len(td.Constructors) != tc.constructors {
+				t.Errorf("expected %d constructors, got %d", tc.constructors, len(td.Constructors))
+			}
+			for _, c := range td.Constructors {
+				if c.Name.Value == "Id" && len(c.Parameters) != 1 {
+					t.Errorf("expected Id to take 1 parameter, got %d", len(c.Parameters))
+				}
+			}
+			var traits []string
+			for _, d := range td.Deriving {
+				traits = append(traits, d.Value)
+			}
+			if got := strings.Join(traits, ","); got != tc.want {
+				t.Errorf("expected deriving %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDerivingIsContextual(t *testing.T) {
+	input := "deriving = [1]\nfun count(deriving: List<Int>) -> Int { len(deriving) }\nx = { deriving: 1 }.deriving"
+	ctx := pipeline.NewPipelineContext(input)
+	ctx = (&lexer.LexerProcessor{}).Process(ctx)
+	ctx = (&parser.ParserProcessor{}).Process(ctx)
+	if len(ctx.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", ctx.Errors)
+	}
+	if n := len(ctx.AstRoot.(*ast.Program).Statements); n != 3 {
+		t.Errorf("expected 3 statements, got %d", n)
+	}
+}
+
+func TestDerivingClauseErrors(t *testing.T) {
+	for _, input := range []string{
+		"type Color = Red deriving ()",
+		"type Color = Red deriving (Equal,)",
+		"type Color = Red deriving (equal)",
+	} {
+		ctx := pipeline.NewPipelineContext(input)
+		ctx = (&lexer.LexerProcessor{}).Process(ctx)
+		ctx = (&parser.ParserProcessor{}).Process(ctx)
+		if len(ctx.Errors) == 0 {
+			t.Errorf("expected a parse error for %q", input)
+		}
+	}
+}
@@ -104,14 +104,8 @@ func (p *Parser) parseForExpression() ast.Expression {
 		} else {
 			// Destructuring target (e.g. `for (k, v) in xs`). Desugar into a hidden
 			// item bound by a pattern assignment prepended to the loop body, reusing
-			// the existing pattern-assignment machinery.
-			//
-			// NOTE: this desugaring happens in the parser, so the AST loses the original
-			// surface pattern (ItemName becomes the synthetic `$foritemN`). That's fine
-			// today because there is no formatter command. If a `fmt`/round-trip printer
-			// is ever added, store the original pattern on ast.ForExpression (e.g. an
-			// `ItemPattern` field) and reconstruct `for (k, v) in ...` from it instead of
-			// emitting the desugared `$foritemN`.
+			// the existing pattern-assignment machinery. The surface pattern is kept
+			// in ItemPattern so that printers can reconstruct `for (k, v) in ...`.
 			destructurePattern = p.exprToPattern(header)
 			if destructurePattern == nil {
 				p.ctx.Errors = append(p.ctx.Errors, diagnostics.NewError(
@@ -123,6 +117,7 @@ func (p *Parser) parseForExpression() ast.Expression {
 			hiddenName = fmt.Sprintf("$foritem%d", p.forPatternCounter)
 			p.forPatternCounter++
 			expr.ItemName = &ast.Identifier{Token: header.GetToken(), Value: hiddenName}
+			expr.ItemPattern = destructurePattern
 		}
 
 		p.nextToken() // consume last header token -> curToken = in
@@ -9,7 +9,7 @@ import (
 // parseKind parses a kind annotation.
 // Grammar:
 // Kind ::= AtomicKind ("->" Kind)*
-// AtomicKind ::= "*" | "(" Kind ")"
+// AtomicKind ::= "*" | kindVar | "(" Kind ")"
 func (p *Parser) parseKind() typesystem.Kind {
 	// Parse left side
 	left := p.parseAtomicKind()
@@ -37,11 +37,21 @@ func (p *Parser) parseKind() typesystem.Kind {
 	return left
 }
 
+// atKindStart reports whether the current token begins a kind annotation.
+// Trait constraints are uppercase, so a lowercase name is a kind variable.
+func (p *Parser) atKindStart() bool {
+	return p.curTokenIs(token.ASTERISK) || p.curTokenIs(token.LPAREN) || p.curTokenIs(token.IDENT_LOWER)
+}
+
 func (p *Parser) parseAtomicKind() typesystem.Kind {
 	if p.curTokenIs(token.ASTERISK) { // '*' is used for Star kind
 		return typesystem.Star
 	}
 
+	if p.curTokenIs(token.IDENT_LOWER) { // kind variable: Proxy<t: k>
+		return typesystem.KVar{Name: p.curToken.Literal.(string)}
+	}
+
 	if p.curTokenIs(token.LPAREN) {
 		p.nextToken()
 		k := p.parseKind()
@@ -80,6 +80,21 @@ func (p *Parser) parseRecordPattern() ast.Pattern {
 			continue
 		}
 
+		// Rest of the record: { x: p1, ...rest }, always last
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT_LOWER) {
+				return nil
+			}
+			rp.Rest = &ast.IdentifierPattern{Token: p.curToken, Value: p.curToken.Literal.(string)}
+			for p.peekTokenIs(token.NEWLINE) {
+				p.nextToken()
+			}
+			if !p.expectPeek(token.RBRACE) {
+				return nil
+			}
+			break
+		}
+
 		if !p.curTokenIs(token.IDENT_LOWER) && !p.curTokenIs(token.IDENT_UPPER) {
 			return nil // Expected field name
 		}
@@ -0,0 +1,101 @@
+package parser_test
+
+import (
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/parser"
+	"github.com/funvibe/funxy/internal/pipeline"
+	"testing"
+)
+
+func parseParamType(t *testing.T, input string) ast.Type {
+	t.Helper()
+	ctx := pipeline.NewPipelineContext(input)
+	ctx = (&lexer.LexerProcessor{}).Process(ctx)
+	ctx = (&parser.ParserProcessor{}).Process(ctx)
+	if len(ctx.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", ctx.Errors)
+	}
+	fn := ctx.AstRoot.(*ast.Program).Statements[0].(*ast.FunctionStatement)
+	return fn.Parameters[0].Type
+}
+
+func TestRecordTypeRowVariable(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   string
+		fields  int
+		wantRow string
+	}{
+		{"row after fields", "fun f(r: { x: Int, y: String, ...rest }) { r }", 2, "rest"},
+		{"row only", "fun f(r: { ...rest }) { r }", 0, "rest"},
+		{"union field ending in a type variable", "fun f(r: { x: Int | t }) { r }", 1, ""},
+		{"parenthesized union field", "fun f(r: { x: (Int | t) }) { r }", 1, ""},
+		{"no row", "fun f(r: { x: Int }) { r }", 1, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rt, ok := parseParamType(t, tc.input).(*ast.RecordType)
+			if !ok {
+				t.Fatalf("expected *ast.RecordType")
+			}
+			if len(rt.Fields) != tc.fields {
+				t.Errorf("got %d fields, want %d", len(rt.Fields), tc.fields)
+			}
+			gotRow := ""
+			if rt.Row != nil {
+				gotRow = rt.Row.Name.Value
+			}
+			if gotRow != tc.wantRow {
+				t.Errorf("got row %q, want %q", gotRow, tc.wantRow)
+			}
+		})
+	}
+}
+
+// A '|' inside a record type always belongs to a field's union type
+func TestRecordTypeUnionField(t *testing.T) {
+	rt := parseParamType(t, "fun f(r: { x: Int | t }) { r }").(*ast.RecordType)
+	union, ok := rt.Fields["x"].(*ast.UnionType)
+	if !ok {
+		t.Fatalf("expected field x to be a union type, got %T", rt.Fields["x"])
+	}
+	if len(union.Types) != 2 {
+		t.Fatalf("expected 2 union members, got %d", len(union.Types))
+	}
+	if named, ok := union.Types[1].(*ast.NamedType); !ok || named.Name.Value != "t" {
+		t.Errorf("expected the last union member to be t, got %v", union.Types[1])
+	}
+
+	ctx := pipeline.NewPipelineContext("fun f(r: { ...rest, x: Int }) { r }")
+	ctx = (&lexer.LexerProcessor{}).Process(ctx)
+	ctx = (&parser.ParserProcessor{}).Process(ctx)
+	if len(ctx.Errors) == 0 {
+		t.Errorf("expected an error for a row variable that is not last")
+	}
+}
+
+func TestRecordPatternRest(t *testing.T) {
+	input := "match r { { x: a, ...others } -> others }"
+	ctx := pipeline.NewPipelineContext(input)
+	ctx = (&lexer.LexerProcessor{}).Process(ctx)
+	ctx = (&parser.ParserProcessor{}).Process(ctx)
+	if len(ctx.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", ctx.Errors)
+	}
+	match := ctx.AstRoot.(*ast.Program).Statements[0].(*ast.ExpressionStatement).Expression.(*ast.MatchExpression)
+	rp, ok := match.Arms[0].Pattern.(*ast.RecordPattern)
+	if !ok {
+		t.Fatalf("expected *ast.RecordPattern")
+	}
+	if rp.Rest == nil || rp.Rest.Value != "others" {
+		t.Errorf("expected rest binding 'others', got %v", rp.Rest)
+	}
+
+	ctx = pipeline.NewPipelineContext("match r { { ...others, x: a } -> others }")
+	ctx = (&lexer.LexerProcessor{}).Process(ctx)
+	ctx = (&parser.ParserProcessor{}).Process(ctx)
+	if len(ctx.Errors) == 0 {
+		t.Errorf("expected an error for a rest binding that is not last")
+	}
+}
@@ -58,7 +58,7 @@ func (p *Parser) parseFunctionSignature() *ast.FunctionStatement {
 				p.nextToken() // consume :
 
 				// Check for Kind annotation: t: * -> *
-				if p.curTokenIs(token.ASTERISK) || p.curTokenIs(token.LPAREN) {
+				if p.atKindStart() {
 					typeParam.Kind = p.parseKind()
 					p.nextToken() // move past Kind
 
@@ -221,7 +221,7 @@ func (p *Parser) parseFunctionSignature() *ast.FunctionStatement {
 				p.nextToken() // consume :
 
 				// Check for Kind annotation: t: * -> *
-				if p.curTokenIs(token.ASTERISK) || p.curTokenIs(token.LPAREN) {
+				if p.atKindStart() {
 					typeParam.Kind = p.parseKind()
 					p.nextToken() // move past Kind
 
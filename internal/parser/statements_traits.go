@@ -74,7 +74,7 @@ func (p *Parser) parseTraitDeclaration() *ast.TraitDeclaration {
 
 				// Try parse Kind
 				var kind typesystem.Kind
-				if p.curTokenIs(token.ASTERISK) || p.curTokenIs(token.LPAREN) {
+				if p.atKindStart() {
 					kind = p.parseKind()
 					tp.Kind = kind
 					p.nextToken()
@@ -317,6 +317,16 @@ func (p *Parser) parseTraitDeclaration() *ast.TraitDeclaration {
 				p.nextToken()
 			}
 			p.nextToken()
+		} else if p.curTokenIs(token.TYPE) {
+			// Associated type: type Elem
+			at := p.parseAssociatedType()
+			if at != nil {
+				stmt.AssociatedTypes = append(stmt.AssociatedTypes, at)
+			}
+			if p.peekTokenIs(token.NEWLINE) {
+				p.nextToken()
+			}
+			p.nextToken()
 		} else {
 			p.nextToken()
 		}
@@ -325,6 +335,30 @@ func (p *Parser) parseTraitDeclaration() *ast.TraitDeclaration {
 	return stmt
 }
 
+// parseAssociatedType parses 'type Elem' in a trait body and
+// 'type Elem = Int' in an instance body.
+func (p *Parser) parseAssociatedType() *ast.AssociatedType {
+	at := &ast.AssociatedType{Token: p.curToken}
+	if !p.expectPeek(token.IDENT_UPPER) {
+		return nil
+	}
+	at.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal.(string)}
+
+	if p.peekTokenIs(token.ASSIGN) {
+		p.nextToken() // consume name
+		p.nextToken() // consume '='
+		at.Type = p.parseType()
+		if at.Type == nil {
+			p.ctx.Errors = append(p.ctx.Errors, diagnostics.NewError(
+				diagnostics.ErrP005, p.curToken,
+				"expected type", p.curToken.Literal,
+			))
+			return nil
+		}
+	}
+	return at
+}
+
 // parseOperatorMethod parses operator (+)<A, B>(a: T, b: T) -> T { body }
 // Supports optional generic type params: operator (<~>)<A, B>(...)
 func (p *Parser) parseOperatorMethod() *ast.FunctionStatement {
@@ -537,6 +571,16 @@ func (p *Parser) parseInstanceDeclaration() *ast.InstanceDeclaration {
 				p.nextToken()
 			}
 			p.nextToken()
+		} else if p.curTokenIs(token.TYPE) {
+			// Associated type definition: type Elem = Int
+			at := p.parseAssociatedType()
+			if at != nil {
+				stmt.AssociatedTypes = append(stmt.AssociatedTypes, at)
+			}
+			if p.peekTokenIs(token.NEWLINE) {
+				p.nextToken()
+			}
+			p.nextToken()
 		} else {
 			p.nextToken()
 		}
@@ -64,9 +64,9 @@ func (p *Parser) parseTypeDeclarationStatement() *ast.TypeDeclarationStatement {
 			if p.curTokenIs(token.COLON) {
 				p.nextToken() // consume :
 
-				// Try to parse Kind first if it starts with * or (
+				// Try to parse Kind first if it starts with *, ( or a kind variable
 				var kind typesystem.Kind
-				if p.curTokenIs(token.ASTERISK) || p.curTokenIs(token.LPAREN) {
+				if p.atKindStart() {
 					kind = p.parseKind()
 					tp.Kind = kind
 					p.nextToken() // consume last token of kind
@@ -207,9 +207,64 @@ func (p *Parser) parseTypeDeclarationStatement() *ast.TypeDeclarationStatement {
 		}
 	}
 
+	// 5. Optional deriving clause (may start on the next line)
+	if p.derivingAhead() {
+		for p.peekTokenIs(token.NEWLINE) {
+			p.nextToken()
+		}
+		p.nextToken() // consume 'deriving'
+		stmt.Deriving = p.parseDerivingClause()
+	}
+
 	return stmt
 }
 
+// derivingAhead reports whether a deriving clause follows, possibly on the
+// next line. 'deriving' is a contextual keyword, so it is only one here and
+// only when a trait or a trait list comes after it; elsewhere it is a name.
+func (p *Parser) derivingAhead() bool {
+	tokens := append([]token.Token{p.peekToken}, p.stream.Peek(10)...)
+	for i, t := range tokens {
+		if t.Type == token.NEWLINE {
+			continue
+		}
+		if t.Type != token.IDENT_LOWER || t.Literal != "deriving" || i+1 == len(tokens) {
+			return false
+		}
+		next := tokens[i+1].Type
+		return next == token.LPAREN || next == token.IDENT_UPPER
+	}
+	return false
+}
+
+// parseDerivingClause parses the trait list after 'deriving':
+// either a single trait (deriving Show) or a parenthesized list (deriving (Equal, Show)).
+func (p *Parser) parseDerivingClause() []*ast.Identifier {
+	if !p.peekTokenIs(token.LPAREN) {
+		if !p.expectPeek(token.IDENT_UPPER) {
+			return nil
+		}
+		return []*ast.Identifier{{Token: p.curToken, Value: p.curToken.Literal.(string)}}
+	}
+	p.nextToken() // consume '('
+
+	var traits []*ast.Identifier
+	for {
+		if !p.expectPeek(token.IDENT_UPPER) {
+			return nil
+		}
+		traits = append(traits, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal.(string)})
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken() // consume ','
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	return traits
+}
+
 func (p *Parser) parseDataConstructor() *ast.DataConstructor {
 	dc := &ast.DataConstructor{Token: p.curToken}
 	if p.curTokenIs(token.IDENT_LOWER) {
@@ -259,8 +314,8 @@ func (p *Parser) parseDataConstructor() *ast.DataConstructor {
 	}
 
 	// ML-style syntax: Constructor Type Type ...
-	// Parse parameters (Types) until next PIPE or NEWLINE/EOF
-	for !p.peekTokenIs(token.PIPE) && !p.peekTokenIs(token.NEWLINE) && !p.peekTokenIs(token.EOF) {
+	// Parse parameters (Types) until next PIPE, NEWLINE/EOF or a deriving clause
+	for !p.peekTokenIs(token.PIPE) && !p.peekTokenIs(token.NEWLINE) && !p.peekTokenIs(token.EOF) && !p.derivingAhead() {
 		p.nextToken()
 		// Use parseNonUnionType to avoid consuming | as part of union type
 		// ADT syntax: Constructor Type Type | Constructor Type
@@ -131,7 +131,7 @@ func (p *Parser) parseTypeAnnotations(ident *ast.Identifier) {
 	p.nextToken() // consume ':'
 
 	// 1. Parse Kind
-	if p.curTokenIs(token.ASTERISK) || p.curTokenIs(token.LPAREN) {
+	if p.atKindStart() {
 		ident.Kind = p.parseKind()
 		p.nextToken() // consume last token of kind
 	}
@@ -214,6 +214,21 @@ func (p *Parser) parseRecordType() ast.Type {
 			continue
 		}
 
+		// Row variable for the other fields: { x: Int, ...r }, always last
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT_LOWER) {
+				return nil
+			}
+			rt.Row = &ast.NamedType{Token: p.curToken, Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal.(string)}}
+			for p.peekTokenIs(token.NEWLINE) {
+				p.nextToken()
+			}
+			if !p.expectPeek(token.RBRACE) {
+				return nil
+			}
+			break
+		}
+
 		if !p.curTokenIs(token.IDENT_LOWER) && !p.curTokenIs(token.IDENT_UPPER) {
 			return nil // Expected key
 		}
@@ -434,6 +449,11 @@ func (p *Parser) parseAtomicType() ast.Type {
 		return t // Grouped type or partial application
 	}
 
+	// A hole in a type application: Pair<_, String>
+	if p.curTokenIs(token.UNDERSCORE) {
+		return &ast.NamedType{Token: p.curToken, Name: &ast.Identifier{Token: p.curToken, Value: "_"}}
+	}
+
 	if p.curTokenIs(token.IDENT_UPPER) || p.curTokenIs(token.IDENT_LOWER) {
 		nameVal := p.curToken.Literal.(string)
 		startToken := p.curToken
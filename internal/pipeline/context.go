@@ -19,6 +19,7 @@ type PipelineContext struct {
 	TypeMap       map[ast.Node]typesystem.Type // Stores inferred types for expressions
 	ResolutionMap map[ast.Node]symbols.Symbol  // Stores resolved symbols for identifiers
 	Errors        []*diagnostics.DiagnosticError
+	Warnings      []*diagnostics.DiagnosticError // Non-fatal diagnostics (lint warnings and infos)
 
 	// Trait default method implementations: "TraitName.methodName" -> FunctionStatement
 	TraitDefaults map[string]*ast.FunctionStatement
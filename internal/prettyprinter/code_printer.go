@@ -2,7 +2,11 @@ package prettyprinter
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/token"
+	"github.com/funvibe/funxy/internal/typesystem"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -86,10 +90,14 @@ var rightAssoc = map[string]bool{
 }
 
 type CodePrinter struct {
-	buf       bytes.Buffer
-	indent    int
-	lineWidth int // max line width (0 = unlimited)
-	column    int // current column position
+	buf        bytes.Buffer
+	indent     int
+	lineWidth  int           // max line width (0 = unlimited)
+	column     int           // current column position
+	layout     *sourceLayout // comments and blank lines of the source, if known
+	followed   bool          // more of the enclosing expression follows the current one
+	commentEnd int           // buffer length after the last printed comment
+	trailing   []int         // buffer offsets of comments appended to a line
 }
 
 func NewCodePrinter() *CodePrinter {
@@ -126,6 +134,10 @@ func (p *CodePrinter) printExpr(expr ast.Expression, parentPrec int, isRight boo
 		p.write("<???>")
 		return
 	}
+	if lines, ok := doLines(expr); ok {
+		p.printDo(lines)
+		return
+	}
 	switch e := expr.(type) {
 	case *ast.InfixExpression:
 		if e == nil {
@@ -142,19 +154,25 @@ func (p *CodePrinter) printExpr(expr ast.Expression, parentPrec int, isRight boo
 				needParens = true
 			}
 		}
+		followed := p.followed
 		if needParens {
 			p.write("(")
+			p.followed = false
 		}
 
 		// Special handling for pipe chains
 		if (e.Operator == "|>" || e.Operator == "|>>") && countPipeSteps(e) >= 2 && parentPrec == 0 {
 			p.printPipeChain(e)
 		} else {
+			rightFollowed := p.followed
+			p.followed = true
 			p.printExpr(e.Left, prec, false)
 			p.write(" " + e.Operator + " ")
+			p.followed = rightFollowed
 			p.printExpr(e.Right, prec, true)
 		}
 
+		p.followed = followed
 		if needParens {
 			p.write(")")
 		}
@@ -166,9 +184,78 @@ func (p *CodePrinter) printExpr(expr ast.Expression, parentPrec int, isRight boo
 		p.write(e.Operator)
 		// Prefix has high precedence
 		p.printExpr(e.Right, 100, false)
+	case *ast.FunctionLiteral, *ast.AnnotatedExpression:
+		// Both extend as far to the right as they can.
+		needParens := p.followed
+		if _, ok := e.(*ast.AnnotatedExpression); ok && parentPrec > 0 {
+			needParens = true
+		}
+		p.printDelimited(expr, needParens)
 	default:
 		// For non-infix expressions, just use visitor
-		expr.Accept(p)
+		p.printDelimited(expr, false)
+	}
+}
+
+// printDelimited prints expr through the visitor. Whatever expr contains is
+// delimited by expr itself (or by the parentheses), so nothing follows it.
+func (p *CodePrinter) printDelimited(expr ast.Expression, parens bool) {
+	followed := p.followed
+	p.followed = false
+	if parens {
+		p.write("(")
+	}
+	expr.Accept(p)
+	if parens {
+		p.write(")")
+	}
+	p.followed = followed
+}
+
+// leftmost returns the subexpression that is printed first in expr.
+func leftmost(expr ast.Expression) ast.Expression {
+	for {
+		switch e := expr.(type) {
+		case *ast.InfixExpression:
+			expr = e.Left
+		case *ast.CallExpression:
+			expr = e.Function
+		case *ast.IndexExpression:
+			expr = e.Left
+		case *ast.MemberExpression:
+			expr = e.Left
+		case *ast.PostfixExpression:
+			expr = e.Left
+		case *ast.AnnotatedExpression:
+			expr = e.Expression
+		default:
+			return expr
+		}
+	}
+}
+
+// printFollowed prints an expression that is followed by more syntax, such
+// as a map key before '=>'.
+func (p *CodePrinter) printFollowed(expr ast.Expression, parentPrec int) {
+	followed := p.followed
+	p.followed = true
+	p.printExpr(expr, parentPrec, false)
+	p.followed = followed
+}
+
+// printOperand prints the operand of a call, index, member access or postfix
+// operator, which binds tighter than any infix operator or lambda.
+func (p *CodePrinter) printOperand(expr ast.Expression) {
+	if expr == nil {
+		p.write("<???>")
+		return
+	}
+	switch expr.(type) {
+	case *ast.InfixExpression, *ast.PrefixExpression, *ast.FunctionLiteral,
+		*ast.AnnotatedExpression, *ast.AssignExpression:
+		p.printDelimited(expr, true)
+	default:
+		p.printDelimited(expr, false)
 	}
 }
 
@@ -213,7 +300,11 @@ func (p *CodePrinter) printPipeChain(expr *ast.InfixExpression) {
 
 	pipePrec := getPrecedence("|>")
 
+	followed := p.followed
+	defer func() { p.followed = followed }()
+
 	// Print first step (source) — parenthesize if it has lower-precedence ops
+	p.followed = true
 	if steps[0].expr != nil {
 		p.printExpr(steps[0].expr, pipePrec, false)
 	} else {
@@ -224,7 +315,11 @@ func (p *CodePrinter) printPipeChain(expr *ast.InfixExpression) {
 	p.indent++
 	for i := 1; i < len(steps); i++ {
 		p.writeln()
-		p.writeIndent()
+		if steps[i].expr != nil {
+			p.breakLine(startPos(steps[i].expr))
+		} else {
+			p.writeIndent()
+		}
 		op := steps[i].op
 		if op == "" {
 			op = "|>"
@@ -233,6 +328,7 @@ func (p *CodePrinter) printPipeChain(expr *ast.InfixExpression) {
 		if steps[i].expr != nil {
 			// Use printExpr with isRight=true so that same-precedence left-assoc
 			// pipe expressions on the right side get parenthesized (e.g. a |> (b |> c))
+			p.followed = i < len(steps)-1 || followed
 			p.printExpr(steps[i].expr, pipePrec, true)
 		} else {
 			p.write("<?>")
@@ -299,7 +395,6 @@ func (p *CodePrinter) VisitPackageDeclaration(n *ast.PackageDeclaration) {
 		}
 		p.write(")")
 	}
-	p.write("\n")
 }
 
 func (p *CodePrinter) VisitImportStatement(n *ast.ImportStatement) {
@@ -309,17 +404,60 @@ func (p *CodePrinter) VisitImportStatement(n *ast.ImportStatement) {
 		p.write(" as ")
 		p.write(n.Alias.Value)
 	}
-	p.write("\n")
+	switch {
+	case n.ImportAll:
+		p.write(" (*)")
+	case len(n.Exclude) > 0:
+		p.write(" !")
+		p.writeIdentifierList(n.Exclude)
+	case len(n.Symbols) > 0:
+		p.write(" ")
+		p.writeIdentifierList(n.Symbols)
+	}
+}
+
+// writeIdentifierList writes a parenthesized, comma-separated list of names.
+func (p *CodePrinter) writeIdentifierList(idents []*ast.Identifier) {
+	p.write("(")
+	for i, ident := range idents {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.write(ident.Value)
+	}
+	p.write(")")
 }
 
 func (p *CodePrinter) VisitProgram(n *ast.Program) {
+	var prev ast.Statement
 	for _, stmt := range n.Statements {
-		if stmt != nil {
-			stmt.Accept(p)
-		} else {
-			p.write("<???>")
+		if stmt == nil {
+			p.write("<???>\n")
+			continue
 		}
-		p.write("\n")
+		// Without the source to follow, the package clause and the imports
+		// are set apart from the declarations.
+		if p.layout == nil && prev != nil && declarationGroup(prev) != declarationGroup(stmt) {
+			p.writeln()
+		}
+		p.beginLine(startPos(stmt))
+		stmt.Accept(p)
+		p.writeln()
+		prev = stmt
+	}
+	if p.layout != nil {
+		p.flushComments(position{line: math.MaxInt32})
+	}
+}
+
+func declarationGroup(stmt ast.Statement) int {
+	switch stmt.(type) {
+	case *ast.PackageDeclaration:
+		return 0
+	case *ast.ImportStatement:
+		return 1
+	default:
+		return 2
 	}
 }
 
@@ -332,42 +470,30 @@ func (p *CodePrinter) VisitExpressionStatement(n *ast.ExpressionStatement) {
 }
 
 func (p *CodePrinter) VisitFunctionStatement(n *ast.FunctionStatement) {
-	p.write("fun ")
-	if n.Name != nil {
-		p.write(n.Name.Value)
+	if n.Operator != "" {
+		p.write("operator (" + n.Operator + ")")
 	} else {
-		p.write("<???>")
+		p.write("fun ")
+		if n.Receiver != nil {
+			p.write("(")
+			p.writeParameter(n.Receiver, 0)
+			p.write(") ")
+		}
+		if n.Name != nil {
+			p.write(n.Name.Value)
+		} else {
+			p.write("<???>")
+		}
 	}
 
 	// Generics <T: Show>
-	if len(n.TypeParams) > 0 {
-		p.write("<")
-		for i, tp := range n.TypeParams {
-			if i > 0 {
-				p.write(", ")
-			}
-			p.write(tp.Value)
-
-			// Find constraints for this param
-			var constraints []string
-			for _, c := range n.Constraints {
-				if c.TypeVar == tp.Value {
-					constraints = append(constraints, c.Trait)
-				}
-			}
-			if len(constraints) > 0 {
-				p.write(": ")
-				p.write(strings.Join(constraints, " + "))
-			}
-		}
-		p.write(">")
-	}
+	p.writeTypeParams(n.TypeParams, n.Constraints)
 
 	if len(n.Parameters) > 3 {
 		// Multiline parameters with alignment
 		maxNameLen := 0
 		for _, param := range n.Parameters {
-			if param != nil && param.Name != nil {
+			if param != nil && param.Name != nil && param.Type != nil {
 				if len(param.Name.Value) > maxNameLen {
 					maxNameLen = len(param.Name.Value)
 				}
@@ -377,25 +503,11 @@ func (p *CodePrinter) VisitFunctionStatement(n *ast.FunctionStatement) {
 		p.write("(\n")
 		p.indent++
 		for i, param := range n.Parameters {
-			p.writeIndent()
 			if param != nil {
-				if param.Name != nil {
-					p.write(param.Name.Value)
-					// Align colons
-					for j := len(param.Name.Value); j < maxNameLen; j++ {
-						p.write(" ")
-					}
-				} else {
-					p.write("<???>")
-				}
-				p.write(": ")
-				if param.IsVariadic {
-					p.write("...")
-				}
-				if param.Type != nil {
-					param.Type.Accept(p)
-				}
+				p.breakLine(tokenPos(param.Token))
+				p.writeParameter(param, maxNameLen)
 			} else {
+				p.writeIndent()
 				p.write("<???>")
 			}
 			if i < len(n.Parameters)-1 {
@@ -413,18 +525,7 @@ func (p *CodePrinter) VisitFunctionStatement(n *ast.FunctionStatement) {
 				p.write(", ")
 			}
 			if param != nil {
-				if param.Name != nil {
-					p.write(param.Name.Value)
-				} else {
-					p.write("<???>")
-				}
-				p.write(": ")
-				if param.IsVariadic {
-					p.write("...")
-				}
-				if param.Type != nil {
-					param.Type.Accept(p)
-				}
+				p.writeParameter(param, 0)
 			} else {
 				p.write("<???>")
 			}
@@ -437,12 +538,125 @@ func (p *CodePrinter) VisitFunctionStatement(n *ast.FunctionStatement) {
 		n.ReturnType.Accept(p)
 	}
 
-	p.write(" ")
 	if n.Body != nil {
+		p.write(" ")
 		n.Body.Accept(p)
 	}
 }
 
+// writeParameter writes a function parameter. Typed parameter names are
+// padded to align, so that the colons of a multiline parameter list line up.
+func (p *CodePrinter) writeParameter(param *ast.Parameter, align int) {
+	if param.IsVariadic && param.Type == nil {
+		p.write("...")
+	}
+	if param.Name != nil {
+		p.write(param.Name.Value)
+	} else {
+		p.write("<???>")
+	}
+	if param.Type != nil {
+		if param.Name != nil {
+			for j := len(param.Name.Value); j < align; j++ {
+				p.write(" ")
+			}
+		}
+		p.write(": ")
+		if param.IsVariadic {
+			p.write("...")
+		}
+		param.Type.Accept(p)
+	}
+	if param.Default != nil {
+		p.write(" = ")
+		param.Default.Accept(p)
+	}
+}
+
+// writeTypeParams writes generic parameters with their kinds and constraints,
+// e.g. <t: Show + Eq, f: * -> *>. Constraints are taken from the identifiers
+// themselves or, when those carry none, from the declaration's list.
+func (p *CodePrinter) writeTypeParams(params []*ast.Identifier, constraints []*ast.TypeConstraint) {
+	if len(params) == 0 {
+		return
+	}
+	p.write("<")
+	printed := make(map[string]bool)
+	for i, tp := range params {
+		if i > 0 {
+			p.write(", ")
+		}
+		// <t: A, t: B> repeats a variable; its constraints are printed once.
+		if printed[tp.Value] {
+			p.writeTypeVar(tp, nil)
+		} else {
+			p.writeTypeVar(tp, constraints)
+		}
+		printed[tp.Value] = true
+	}
+	p.write(">")
+}
+
+// writeTypeVar prints a type variable with its kind and trait constraints:
+// f: * -> * + Functor. Constraints kept outside the identifier are looked up
+// by name in constraints.
+func (p *CodePrinter) writeTypeVar(tp *ast.Identifier, constraints []*ast.TypeConstraint) {
+	p.write(tp.Value)
+	own := tp.Constraints
+	if len(own) == 0 {
+		for _, c := range constraints {
+			if c.TypeVar == tp.Value {
+				own = append(own, c)
+			}
+		}
+	}
+	if tp.Kind == nil && len(own) == 0 {
+		return
+	}
+	p.write(": ")
+	var parts []string
+	if tp.Kind != nil {
+		parts = append(parts, kindString(tp.Kind, false))
+	}
+	for _, c := range own {
+		parts = append(parts, p.constraintString(c))
+	}
+	p.write(strings.Join(parts, " + "))
+}
+
+// constraintString renders a trait constraint such as Convert<Int>.
+func (p *CodePrinter) constraintString(c *ast.TypeConstraint) string {
+	if len(c.Args) == 0 {
+		return c.Trait
+	}
+	temp := &CodePrinter{}
+	temp.write(c.Trait + "<")
+	for i, arg := range c.Args {
+		if i > 0 {
+			temp.write(", ")
+		}
+		arg.Accept(temp)
+	}
+	if strings.HasSuffix(temp.String(), ">") {
+		temp.write(" ")
+	}
+	temp.write(">")
+	return temp.String()
+}
+
+// kindString renders a kind annotation; arrows associate to the right.
+func kindString(k typesystem.Kind, nested bool) string {
+	arrow, ok := k.(typesystem.KArrow)
+	if !ok {
+		return k.String()
+	}
+	s := kindString(arrow.Left, true) + " -> " + kindString(arrow.Right, false)
+	if nested {
+		return "(" + s + ")"
+	}
+	return s
+}
+
 func (p *CodePrinter) VisitTraitDeclaration(n *ast.TraitDeclaration) {
 	if n == nil {
 		p.write("nil")
@@ -454,30 +668,25 @@ func (p *CodePrinter) VisitTraitDeclaration(n *ast.TraitDeclaration) {
 	} else {
 		p.write("<???>")
 	}
-	if len(n.TypeParams) > 0 {
-		p.write("<")
-		for i, tp := range n.TypeParams {
-			if i > 0 {
-				p.write(", ")
-			}
-			if tp != nil {
-				p.write(tp.Value)
-			} else {
-				p.write("<???>")
-			}
+	p.writeTypeParams(n.TypeParams, n.Constraints)
+	for i, super := range n.SuperTraits {
+		if i == 0 {
+			p.write(" : ")
+		} else {
+			p.write(", ")
 		}
-		p.write(">")
+		super.Accept(p)
 	}
-	p.write(" {\n")
-	for _, method := range n.Signatures {
-		if method != nil {
-			method.Accept(p) // Prints the function signature
+	for i, dep := range n.Dependencies {
+		if i == 0 {
+			p.write(" | ")
 		} else {
-			p.write("<???>")
+			p.write(", ")
 		}
-		p.write("\n")
+		p.write(strings.Join(dep.From, ", ") + " -> " + strings.Join(dep.To, ", "))
 	}
-	p.write("}")
+	p.write(" ")
+	p.writeMethods(n.Token, n.AssociatedTypes, n.Signatures)
 }
 
 func (p *CodePrinter) VisitInstanceDeclaration(n *ast.InstanceDeclaration) {
@@ -486,6 +695,9 @@ func (p *CodePrinter) VisitInstanceDeclaration(n *ast.InstanceDeclaration) {
 		return
 	}
 	p.write("instance ")
+	if n.ModuleName != nil {
+		p.write(n.ModuleName.Value + ".")
+	}
 	if n.TraitName != nil {
 		p.write(n.TraitName.Value)
 	} else {
@@ -503,6 +715,9 @@ func (p *CodePrinter) VisitInstanceDeclaration(n *ast.InstanceDeclaration) {
 				p.write("<???>")
 			}
 		}
+		if strings.HasSuffix(p.buf.String(), ">") {
+			p.write(" ")
+		}
 		p.write(">")
 	} else if len(n.Args) == 1 {
 		p.write(" ")
@@ -512,15 +727,41 @@ func (p *CodePrinter) VisitInstanceDeclaration(n *ast.InstanceDeclaration) {
 			p.write("<???>")
 		}
 	}
-	p.write(" {\n")
-	for _, method := range n.Methods {
-		if method != nil {
-			method.Accept(p)
-		} else {
-			p.write("<???>")
+	p.write(" ")
+	p.writeMethods(n.Token, n.AssociatedTypes, n.Methods)
+}
+
+// writeMethods writes the braced body of a trait or instance: associated
+// types first, then methods.
+func (p *CodePrinter) writeMethods(start token.Token, assocs []*ast.AssociatedType, methods []*ast.FunctionStatement) {
+	p.write("{\n")
+	p.indent++
+	for _, at := range assocs {
+		p.beginLine(tokenPos(at.Token))
+		p.write("type " + at.Name.Value)
+		if at.Type != nil {
+			p.write(" = ")
+			at.Type.Accept(p)
 		}
-		p.write("\n")
+		p.writeln()
+	}
+	for _, method := range methods {
+		if method == nil {
+			p.writeIndent()
+			p.write("<???>\n")
+			continue
+		}
+		p.beginLine(startPos(method))
+		method.Accept(p)
+		p.writeln()
+	}
+	if len(assocs) > 0 {
+		p.endBraces(tokenPos(start), tokenPos(assocs[0].Token))
+	} else if len(methods) > 0 && methods[0] != nil {
+		p.endBraces(tokenPos(start), startPos(methods[0]))
 	}
+	p.indent--
+	p.writeIndent()
 	p.write("}")
 }
 
@@ -553,21 +794,26 @@ func (p *CodePrinter) VisitFunctionLiteral(n *ast.FunctionLiteral) {
 		p.write("nil")
 		return
 	}
+	if n.Token.Type == token.BACKSLASH {
+		// \x, y -> body
+		p.write("\\")
+		for i, param := range n.Parameters {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.writeParameter(param, 0)
+		}
+		p.write(" -> ")
+		p.writeFunctionBody(n.Body)
+		return
+	}
 	p.write("fun(")
 	for i, param := range n.Parameters {
 		if i > 0 {
 			p.write(", ")
 		}
 		if param != nil {
-			if param.Name != nil {
-				p.write(param.Name.Value)
-			} else {
-				p.write("<???>")
-			}
-			if param.Type != nil {
-				p.write(": ")
-				param.Type.Accept(p)
-			}
+			p.writeParameter(param, 0)
 		} else {
 			p.write("<???>")
 		}
@@ -575,11 +821,52 @@ func (p *CodePrinter) VisitFunctionLiteral(n *ast.FunctionLiteral) {
 	p.write(")")
 	if n.ReturnType != nil {
 		p.write(" -> ")
-		n.ReturnType.Accept(p)
+		// fun(x) -> (a) -> b { would read (a) as the expression body.
+		if _, ok := n.ReturnType.(*ast.FunctionType); ok {
+			p.write("(")
+			n.ReturnType.Accept(p)
+			p.write(")")
+		} else {
+			n.ReturnType.Accept(p)
+		}
+		p.write(" ")
+		if n.Body != nil {
+			n.Body.Accept(p)
+		} else {
+			p.write("<???>")
+		}
+		return
 	}
-	p.write(" ")
-	if n.Body != nil {
-		n.Body.Accept(p)
+	if expressionBody(n.Body) != nil {
+		p.write(" -> ")
+	} else {
+		p.write(" ")
+	}
+	p.writeFunctionBody(n.Body)
+}
+
+// expressionBody returns the expression of a lambda written as `-> expr`.
+// The parser wraps such bodies in a block that has no '{' token.
+func expressionBody(body *ast.BlockStatement) ast.Expression {
+	if body == nil || body.Token.Type == token.LBRACE || len(body.Statements) != 1 {
+		return nil
+	}
+	if es, ok := body.Statements[0].(*ast.ExpressionStatement); ok {
+		return es.Expression
+	}
+	return nil
+}
+
+func (p *CodePrinter) writeFunctionBody(body *ast.BlockStatement) {
+	if expr := expressionBody(body); expr != nil {
+		// -> { ... } would be read as a block body.
+		if _, ok := leftmost(expr).(*ast.RecordLiteral); ok {
+			p.printDelimited(expr, true)
+		} else {
+			expr.Accept(p)
+		}
+	} else if body != nil {
+		body.Accept(p)
 	} else {
 		p.write("<???>")
 	}
@@ -643,7 +930,7 @@ func (p *CodePrinter) VisitTupleLiteral(n *ast.TupleLiteral) {
 		p.write("(\n")
 		p.indent++
 		for i, el := range n.Elements {
-			p.writeIndent()
+			p.breakLine(startPos(el))
 			if el != nil {
 				el.Accept(p)
 			} else {
@@ -683,7 +970,7 @@ func (p *CodePrinter) VisitListLiteral(n *ast.ListLiteral) {
 		p.write("[\n")
 		p.indent++
 		for i, el := range n.Elements {
-			p.writeIndent()
+			p.breakLine(startPos(el))
 			if i == 0 {
 				// First element needs to avoid ambiguity with list comprehension |
 				p.printExpr(el, getPrecedence("|"), false)
@@ -724,11 +1011,7 @@ func (p *CodePrinter) VisitListLiteral(n *ast.ListLiteral) {
 }
 
 func (p *CodePrinter) VisitIndexExpression(n *ast.IndexExpression) {
-	if n.Left != nil {
-		n.Left.Accept(p)
-	} else {
-		p.write("<???>")
-	}
+	p.printOperand(n.Left)
 	p.write("[")
 	if n.Index != nil {
 		n.Index.Accept(p)
@@ -743,7 +1026,12 @@ func (p *CodePrinter) VisitStringLiteral(n *ast.StringLiteral) {
 		p.write("nil")
 		return
 	}
-	p.write(strconv.Quote(n.Value))
+	// Raw strings keep their backticks; their lexeme is the source text.
+	if strings.HasPrefix(n.Token.Lexeme, "`") && n.Token.Literal == n.Value {
+		p.write(n.Token.Lexeme)
+		return
+	}
+	p.write("\"" + escapeString(n.Value) + "\"")
 }
 
 func (p *CodePrinter) VisitFormatStringLiteral(n *ast.FormatStringLiteral) {
@@ -761,10 +1049,10 @@ func (p *CodePrinter) VisitInterpolatedString(n *ast.InterpolatedString) {
 	}
 	p.write("\"")
 	for _, part := range n.Parts {
-		if sl, ok := part.(*ast.StringLiteral); ok {
-			// Escape the string content but remove the surrounding quotes added by Quote
-			quoted := strconv.Quote(sl.Value)
-			p.write(quoted[1 : len(quoted)-1])
+		// Text between interpolations carries the token of the whole string;
+		// a string literal written inside ${...} has its own.
+		if sl, ok := part.(*ast.StringLiteral); ok && sameToken(sl.Token, n.Token) {
+			p.write(escapeString(sl.Value))
 		} else {
 			p.write("${")
 			part.Accept(p)
@@ -774,12 +1062,74 @@ func (p *CodePrinter) VisitInterpolatedString(n *ast.InterpolatedString) {
 	p.write("\"")
 }
 
+func sameToken(a, b token.Token) bool {
+	return a.Type == b.Type && a.Line == b.Line && a.Column == b.Column && a.Lexeme == b.Lexeme
+}
+
+// escapeString escapes s for a double-quoted string literal. Only the escapes
+// the lexer understands are used; "${" is escaped so that it does not start
+// an interpolation.
+func escapeString(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '$':
+			if strings.HasPrefix(s[i+1:], "{") {
+				b.WriteString(`\$`)
+			} else {
+				b.WriteRune(r)
+			}
+		default:
+			writeRuneEscaped(&b, r)
+		}
+	}
+	return b.String()
+}
+
+func quoteChar(r rune) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	switch r {
+	case '\'':
+		b.WriteString(`\'`)
+	case '\\':
+		b.WriteString(`\\`)
+	default:
+		writeRuneEscaped(&b, r)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+func writeRuneEscaped(b *strings.Builder, r rune) {
+	switch {
+	case r == '\n':
+		b.WriteString(`\n`)
+	case r == '\t':
+		b.WriteString(`\t`)
+	case r == '\r':
+		b.WriteString(`\r`)
+	case r == 0:
+		b.WriteString(`\0`)
+	case r < 0x10000 && !strconv.IsPrint(r):
+		b.WriteString(fmt.Sprintf(`\u%04x`, r))
+	case !strconv.IsPrint(r):
+		b.WriteString(fmt.Sprintf(`\U%08x`, r))
+	default:
+		b.WriteRune(r)
+	}
+}
+
 func (p *CodePrinter) VisitCharLiteral(n *ast.CharLiteral) {
 	if n == nil {
 		p.write("nil")
 		return
 	}
-	p.write(strconv.QuoteRune(rune(n.Value)))
+	p.write(quoteChar(rune(n.Value)))
 }
 
 func (p *CodePrinter) VisitBytesLiteral(n *ast.BytesLiteral) {
@@ -878,11 +1228,7 @@ func (p *CodePrinter) VisitPostfixExpression(n *ast.PostfixExpression) {
 		p.write("nil")
 		return
 	}
-	if n.Left != nil {
-		n.Left.Accept(p)
-	} else {
-		p.write("<???>")
-	}
+	p.printOperand(n.Left)
 	p.write(n.Operator)
 }
 
@@ -896,8 +1242,15 @@ func (p *CodePrinter) VisitAssignExpression(n *ast.AssignExpression) {
 	} else {
 		p.write("<???>")
 	}
+	// x += y is parsed as x = x + y with the operator at the position of +=.
+	if op, ok := n.Value.(*ast.InfixExpression); ok && op.Left == n.Left && n.AnnotatedType == nil &&
+		op.Token.Line > 0 && op.Token.Line == n.Token.Line && op.Token.Column == n.Token.Column {
+		p.write(" " + op.Operator + "= ")
+		p.printExpr(op.Right, 0, false)
+		return
+	}
 	if n.AnnotatedType != nil {
-		p.write(" : ")
+		p.write(": ")
 		n.AnnotatedType.Accept(p)
 	}
 	p.write(" = ")
@@ -918,6 +1271,10 @@ func (p *CodePrinter) VisitPatternAssignExpression(n *ast.PatternAssignExpressio
 	} else {
 		p.write("<???>")
 	}
+	if n.AnnotatedType != nil {
+		p.write(": ")
+		n.AnnotatedType.Accept(p)
+	}
 	p.write(" = ")
 	if n.Value != nil {
 		n.Value.Accept(p)
@@ -949,17 +1306,31 @@ func (p *CodePrinter) VisitCallExpression(n *ast.CallExpression) {
 		p.write("nil")
 		return
 	}
-	if n.Function != nil {
-		n.Function.Accept(p)
-	} else {
-		p.write("<???>")
+	if lines, ok := doLines(n); ok {
+		p.printDo(lines)
+		return
 	}
+	p.printOperand(n.Function)
 	p.write("(")
 
+	args := n.Arguments
+	var named *ast.RecordLiteral
+	if len(args) > 0 {
+		if rec, ok := args[len(args)-1].(*ast.RecordLiteral); ok && isNamedArguments(rec) {
+			named = rec
+			args = args[:len(args)-1]
+		}
+	}
+	count := len(args)
+	if named != nil {
+		count += len(named.Fields)
+	}
+
 	// If many args or long, format multiline
-	multiline := len(n.Arguments) > 4
+	multiline := count > 4
 
-	for i, arg := range n.Arguments {
+	i := 0
+	separate := func() {
 		if i > 0 {
 			p.write(", ")
 			if multiline {
@@ -968,25 +1339,52 @@ func (p *CodePrinter) VisitCallExpression(n *ast.CallExpression) {
 				p.write("    ") // extra indent for args
 			}
 		}
-		if arg != nil {
+		i++
+	}
+	for _, arg := range args {
+		separate()
+		if ann, ok := arg.(*ast.AnnotatedExpression); ok {
+			// name: T would be read back as a named argument
+			p.printDelimited(ann, true)
+		} else if arg != nil {
 			arg.Accept(p)
 		} else {
 			p.write("<???>")
 		}
 	}
+	if named != nil {
+		for _, key := range recordKeys(named.Fields) {
+			separate()
+			p.write(key + ": ")
+			named.Fields[key].Accept(p)
+		}
+	}
 	p.write(")")
 }
 
+// isNamedArguments reports whether rec was built by the parser from named
+// call arguments f(a: 1, b: 2). Such a record is positioned at the closing
+// parenthesis, after its values; a record literal written in the source is
+// positioned at its '{'.
+func isNamedArguments(rec *ast.RecordLiteral) bool {
+	if rec.Spread != nil || len(rec.Fields) == 0 {
+		return false
+	}
+	end := tokenPos(rec.Token)
+	for _, v := range rec.Fields {
+		if !startPos(v).before(end) {
+			return false
+		}
+	}
+	return true
+}
+
 func (p *CodePrinter) VisitTypeApplicationExpression(n *ast.TypeApplicationExpression) {
 	if n == nil {
 		p.write("nil")
 		return
 	}
-	if n.Expression != nil {
-		n.Expression.Accept(p)
-	} else {
-		p.write("<???>")
-	}
+	p.printOperand(n.Expression)
 	p.write("<")
 	for i, t := range n.TypeArguments {
 		if i > 0 {
@@ -1006,17 +1404,23 @@ func (p *CodePrinter) VisitBlockStatement(n *ast.BlockStatement) {
 		p.write("nil")
 		return
 	}
+	if line, ok := p.inlineBlock(n); ok {
+		p.write("{ " + line + " }")
+		return
+	}
 	p.write("{\n")
 	p.indent++
 	for _, stmt := range n.Statements {
-		p.writeIndent()
-		if stmt != nil {
-			stmt.Accept(p)
-		} else {
-			p.write("<???>")
+		if stmt == nil {
+			p.writeIndent()
+			p.write("<???>\n")
+			continue
 		}
-		p.write("\n")
+		p.beginLine(startPos(stmt))
+		stmt.Accept(p)
+		p.writeln()
 	}
+	p.endList(tokenPos(n.RBraceToken))
 	p.indent--
 	p.writeIndent()
 	p.write("}")
@@ -1051,9 +1455,16 @@ func (p *CodePrinter) VisitForExpression(n *ast.ForExpression) {
 		return
 	}
 	p.write("for ")
+	body := n.Body
 	if n.Iterable != nil {
 		// for item in iterable
-		if n.ItemName != nil {
+		if n.ItemPattern != nil && body != nil && len(body.Statements) > 0 {
+			// The pattern is bound by a statement the parser put first in the body.
+			n.ItemPattern.Accept(p)
+			rest := *body
+			rest.Statements = body.Statements[1:]
+			body = &rest
+		} else if n.ItemName != nil {
 			p.write(n.ItemName.Value)
 		} else {
 			p.write("<???>")
@@ -1069,8 +1480,8 @@ func (p *CodePrinter) VisitForExpression(n *ast.ForExpression) {
 		}
 	}
 	p.write(" ")
-	if n.Body != nil {
-		n.Body.Accept(p)
+	if body != nil {
+		body.Accept(p)
 	} else {
 		p.write("<???>")
 	}
@@ -1123,20 +1534,7 @@ func (p *CodePrinter) VisitTypeDeclarationStatement(n *ast.TypeDeclarationStatem
 		p.write("<???>")
 	}
 
-	if len(n.TypeParameters) > 0 {
-		p.write("<")
-		for i, param := range n.TypeParameters {
-			if i > 0 {
-				p.write(", ")
-			}
-			if param != nil {
-				param.Accept(p)
-			} else {
-				p.write("<???>")
-			}
-		}
-		p.write(">")
-	}
+	p.writeTypeParams(n.TypeParameters, nil)
 
 	p.write(" = ")
 	if n.TargetType != nil {
@@ -1153,10 +1551,21 @@ func (p *CodePrinter) VisitTypeDeclarationStatement(n *ast.TypeDeclarationStatem
 			p.write("<???>")
 		}
 	}
+
+	if len(n.Deriving) > 0 {
+		p.write(" deriving (")
+		for i, d := range n.Deriving {
+			if i > 0 {
+				p.write(", ")
+			}
+			d.Accept(p)
+		}
+		p.write(")")
+	}
 }
 
 func (p *CodePrinter) VisitNamedType(n *ast.NamedType) {
-	n.Name.Accept(p)
+	p.writeTypeVar(n.Name, nil)
 	if len(n.Args) > 0 {
 		p.write("<")
 		for i, arg := range n.Args {
@@ -1236,7 +1645,11 @@ func (p *CodePrinter) VisitMatchExpression(n *ast.MatchExpression) {
 	}
 
 	for i, arm := range n.Arms {
-		p.writeIndent()
+		if arm.Pattern != nil {
+			p.beginLine(startPos(arm.Pattern))
+		} else {
+			p.writeIndent()
+		}
 		p.write(patStrings[i])
 		// Align arrows
 		for j := len(patStrings[i]); j < maxPatLen; j++ {
@@ -1248,7 +1661,10 @@ func (p *CodePrinter) VisitMatchExpression(n *ast.MatchExpression) {
 		} else {
 			p.write("<???>")
 		}
-		p.write("\n")
+		p.writeln()
+	}
+	if len(n.Arms) > 0 && n.Arms[0].Pattern != nil {
+		p.endBraces(tokenPos(n.Token), startPos(n.Arms[0].Pattern))
 	}
 	p.indent--
 	p.writeIndent()
@@ -1256,7 +1672,14 @@ func (p *CodePrinter) VisitMatchExpression(n *ast.MatchExpression) {
 }
 
 func (p *CodePrinter) VisitWildcardPattern(n *ast.WildcardPattern) { p.write("_") }
-func (p *CodePrinter) VisitLiteralPattern(n *ast.LiteralPattern)   { p.write(n.Token.Lexeme) }
+func (p *CodePrinter) VisitLiteralPattern(n *ast.LiteralPattern) {
+	if str, ok := n.Value.(string); ok && !strings.HasPrefix(n.Token.Lexeme, "`") {
+		p.write("\"" + escapeString(str) + "\"")
+		return
+	}
+	p.write(n.Token.Lexeme)
+}
+
 func (p *CodePrinter) VisitIdentifierPattern(n *ast.IdentifierPattern) {
 	p.write(n.Value)
 }
@@ -1329,12 +1752,11 @@ func (p *CodePrinter) VisitRecordPattern(n *ast.RecordPattern) {
 		p.write("nil")
 		return
 	}
-	p.write("{")
-	keys := make([]string, 0, len(n.Fields))
-	for k := range n.Fields {
-		keys = append(keys, k)
+	if n.TypeName != "" {
+		p.write(n.TypeName + " ")
 	}
-	sort.Strings(keys)
+	p.write("{")
+	keys := recordKeys(n.Fields)
 
 	for i, k := range keys {
 		if i > 0 {
@@ -1348,6 +1770,12 @@ func (p *CodePrinter) VisitRecordPattern(n *ast.RecordPattern) {
 			p.write("<???>")
 		}
 	}
+	if n.Rest != nil {
+		if len(keys) > 0 {
+			p.write(", ")
+		}
+		p.write("..." + n.Rest.Value)
+	}
 	p.write("}")
 }
 
@@ -1374,13 +1802,15 @@ func (p *CodePrinter) VisitStringPattern(n *ast.StringPattern) {
 	for _, part := range n.Parts {
 		if part.IsCapture {
 			p.write("{")
-			p.write(part.Value)
 			if part.Greedy {
 				p.write("...")
 			}
+			p.write(part.Value)
 			p.write("}")
 		} else {
-			p.write(part.Value)
+			// Braces outside captures are doubled.
+			lit := strings.NewReplacer("{", "{{", "}", "}}").Replace(part.Value)
+			p.write(escapeString(lit))
 		}
 	}
 	p.write("\"")
@@ -1413,12 +1843,12 @@ func (p *CodePrinter) VisitSpreadPattern(n *ast.SpreadPattern) {
 		p.write("nil")
 		return
 	}
+	p.write("...")
 	if n.Pattern != nil {
 		n.Pattern.Accept(p)
 	} else {
 		p.write("<???>")
 	}
-	p.write("...")
 }
 
 func (p *CodePrinter) VisitRecordLiteral(n *ast.RecordLiteral) {
@@ -1426,38 +1856,17 @@ func (p *CodePrinter) VisitRecordLiteral(n *ast.RecordLiteral) {
 		p.write("nil")
 		return
 	}
-	// Check if it's shorthand { name } where value is same as key
-	isShorthand := func(k string, v ast.Expression) bool {
-		if v == nil {
-			return true
-		}
-		if ident, ok := v.(*ast.Identifier); ok {
-			return ident.Value == k
-		}
-		return false
-	}
-
-	// Check if all fields are shorthand
-	allShorthand := true
-	for k, v := range n.Fields {
-		if !isShorthand(k, v) {
-			allShorthand = false
-			break
-		}
-	}
+	keys := recordKeys(n.Fields)
 
 	// Multi-field records with alignment
-	if len(n.Fields) > 3 && !allShorthand {
+	if len(n.Fields) > 3 {
 		// Find max key length for alignment
 		maxKeyLen := 0
-		keys := make([]string, 0, len(n.Fields))
-		for k := range n.Fields {
-			keys = append(keys, k)
+		for _, k := range keys {
 			if len(k) > maxKeyLen {
 				maxKeyLen = len(k)
 			}
 		}
-		sort.Strings(keys)
 
 		p.write("{\n")
 		p.indent++
@@ -1471,7 +1880,7 @@ func (p *CodePrinter) VisitRecordLiteral(n *ast.RecordLiteral) {
 		}
 
 		for i, k := range keys {
-			p.writeIndent()
+			p.breakLine(startPos(n.Fields[k]))
 			p.write(k)
 			// Align colons
 			for j := len(k); j < maxKeyLen; j++ {
@@ -1491,68 +1900,50 @@ func (p *CodePrinter) VisitRecordLiteral(n *ast.RecordLiteral) {
 		p.indent--
 		p.writeIndent()
 		p.write("}")
-	} else if allShorthand && len(n.Fields) > 3 {
-		// Multiline shorthand with commas
-		p.write("{ ")
+		return
+	}
 
-		// Handle spread expression first if present
-		if n.Spread != nil {
-			p.write("...")
-			n.Spread.Accept(p)
+	// Inline for small records
+	p.write("{ ")
+	if n.Spread != nil {
+		p.write("...")
+		n.Spread.Accept(p)
+		if len(n.Fields) > 0 {
 			p.write(", ")
 		}
-
-		keys := make([]string, 0, len(n.Fields))
-		for k := range n.Fields {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-
-		for i, k := range keys {
-			if i > 0 {
-				p.write(", ")
-			}
-			p.write(k)
-		}
-		p.write(" }")
-	} else {
-		// Inline for small records
-		p.write("{ ")
-
-		// Handle spread expression first if present
-		if n.Spread != nil {
-			p.write("...")
-			n.Spread.Accept(p)
-			if len(n.Fields) > 0 {
-				p.write(", ")
-			}
+	}
+	for i, k := range keys {
+		if i > 0 {
+			p.write(", ")
 		}
-
-		keys := make([]string, 0, len(n.Fields))
-		for k := range n.Fields {
-			keys = append(keys, k)
+		p.write(k)
+		p.write(": ")
+		if v := n.Fields[k]; v != nil {
+			v.Accept(p)
+		} else {
+			p.write("<???>")
 		}
-		sort.Strings(keys)
+	}
+	p.write(" }")
+}
 
-		for i, k := range keys {
-			v := n.Fields[k]
-			if i > 0 {
-				p.write(", ")
-			}
-			if isShorthand(k, v) {
-				p.write(k)
-			} else {
-				p.write(k)
-				p.write(": ")
-				if v != nil {
-					v.Accept(p)
-				} else {
-					p.write("<???>")
-				}
-			}
-		}
-		p.write(" }")
+// recordKeys orders record fields as they were written, as far as the
+// positions of the values tell; the AST keeps fields in a map.
+func recordKeys[T ast.Node](fields map[string]T) []string {
+	keys := make([]string, 0, len(fields))
+	pos := make(map[string]position, len(fields))
+	for k, v := range fields {
+		keys = append(keys, k)
+		pos[k] = startPos(v)
 	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := pos[keys[i]], pos[keys[j]]
+		if a != b && a.line > 0 && b.line > 0 {
+			return a.before(b)
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
 }
 
 func (p *CodePrinter) VisitMapLiteral(n *ast.MapLiteral) {
@@ -1568,7 +1959,7 @@ func (p *CodePrinter) VisitMapLiteral(n *ast.MapLiteral) {
 		for i, pair := range n.Pairs {
 			temp := &CodePrinter{indent: 0, lineWidth: 0}
 			if pair.Key != nil {
-				temp.printExpr(pair.Key, mapKeyPrec, false)
+				temp.printFollowed(pair.Key, mapKeyPrec)
 			}
 			keyStrings[i] = temp.String()
 			if len(keyStrings[i]) > maxKeyLen {
@@ -1579,7 +1970,7 @@ func (p *CodePrinter) VisitMapLiteral(n *ast.MapLiteral) {
 		p.write("%{\n")
 		p.indent++
 		for i, pair := range n.Pairs {
-			p.writeIndent()
+			p.breakLine(startPos(pair.Key))
 			p.write(keyStrings[i])
 			// Align arrows
 			for j := len(keyStrings[i]); j < maxKeyLen; j++ {
@@ -1608,7 +1999,7 @@ func (p *CodePrinter) VisitMapLiteral(n *ast.MapLiteral) {
 			if pair.Key != nil {
 				// Map keys must be parenthesized if they contain pipe-level operators
 				// because the parser uses PIPE_PREC as stop precedence before =>
-				p.printExpr(pair.Key, getPrecedence("|>")+1, false)
+				p.printFollowed(pair.Key, getPrecedence("|>")+1)
 			} else {
 				p.write("<???>")
 			}
@@ -1629,11 +2020,7 @@ func (p *CodePrinter) VisitRecordType(n *ast.RecordType) {
 		return
 	}
 	p.write("{")
-	keys := make([]string, 0, len(n.Fields))
-	for k := range n.Fields {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
+	keys := recordKeys(n.Fields)
 
 	for i, k := range keys {
 		if i > 0 {
@@ -1647,6 +2034,13 @@ func (p *CodePrinter) VisitRecordType(n *ast.RecordType) {
 			p.write("<???>")
 		}
 	}
+	if n.Row != nil {
+		if len(keys) > 0 {
+			p.write(", ")
+		}
+		p.write("...")
+		n.Row.Accept(p)
+	}
 	p.write("}")
 }
 
@@ -1672,14 +2066,12 @@ func (p *CodePrinter) VisitForallType(n *ast.ForallType) {
 		p.write("nil")
 		return
 	}
-	p.write("forall")
-	for _, param := range n.Vars {
-		p.write(" ")
-		if param != nil {
-			param.Accept(p)
-		} else {
-			p.write("<???>")
+	p.write("forall ")
+	for i, param := range n.Vars {
+		if i > 0 {
+			p.write(", ")
 		}
+		p.writeTypeVar(param, nil)
 	}
 	p.write(". ")
 	if n.Type != nil {
@@ -1694,12 +2086,12 @@ func (p *CodePrinter) VisitMemberExpression(n *ast.MemberExpression) {
 		p.write("nil")
 		return
 	}
-	if n.Left != nil {
-		n.Left.Accept(p)
+	p.printOperand(n.Left)
+	if n.IsOptional {
+		p.write("?.")
 	} else {
-		p.write("<???>")
+		p.write(".")
 	}
-	p.write(".")
 	if n.Member != nil {
 		p.write(n.Member.Value)
 	} else {
@@ -1716,7 +2108,7 @@ func (p *CodePrinter) VisitListComprehension(n *ast.ListComprehension) {
 	// Use precedence of | to ensure output expression is parenthesized if needed
 	// e.g. [(a && b) | ...] because && has lower precedence than |
 	if n.Output != nil {
-		p.printExpr(n.Output, getPrecedence("|"), false)
+		p.printFollowed(n.Output, getPrecedence("|"))
 	} else {
 		p.write("<???>")
 	}
@@ -1853,5 +2245,5 @@ func (p *CodePrinter) VisitDirectiveStatement(n *ast.DirectiveStatement) {
 	}
 	p.write("directive \"")
 	p.write(n.Name)
-	p.write("\"\n")
+	p.write("\"")
 }
@@ -0,0 +1,130 @@
+package prettyprinter
+
+import (
+	"strings"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/token"
+)
+
+// doLine is one line of a do block: a bind (pattern <- value), a declaration
+// (value is then a *ast.ConstantDeclaration) or an expression.
+type doLine struct {
+	pattern ast.Node
+	value   ast.Node
+}
+
+func (l doLine) start() position {
+	if l.pattern != nil {
+		return startPos(l.pattern)
+	}
+	return startPos(l.value)
+}
+
+// doLines recovers the lines of a do block from the parser's desugaring:
+//
+//	x <- m; rest    =>  m >>= \x -> rest
+//	p <- m; rest    =>  m >>= \$do_bind_L_C -> { p = $do_bind_L_C; rest }
+//	e; rest         =>  e >>= \_ -> rest
+//	k :- v; rest    =>  (fun() { k :- v; rest })()
+//
+// The nodes made by the parser have no source position, which tells them
+// apart from the same code written by hand.
+func doLines(expr ast.Expression) ([]doLine, bool) {
+	var lines []doLine
+	for {
+		line, rest, ok := doStep(expr)
+		if !ok {
+			break
+		}
+		lines = append(lines, line)
+		expr = rest
+	}
+	if len(lines) == 0 {
+		return nil, false
+	}
+	return append(lines, doLine{value: expr}), true
+}
+
+// doStep splits the first line off a desugared do block.
+func doStep(expr ast.Expression) (doLine, ast.Expression, bool) {
+	switch e := expr.(type) {
+	case *ast.InfixExpression:
+		if e.Operator != ">>=" || e.Token.Line != 0 {
+			break
+		}
+		lambda, ok := e.Right.(*ast.FunctionLiteral)
+		if !ok || lambda.Token.Type != token.BACKSLASH || lambda.Token.Line != 0 ||
+			len(lambda.Parameters) != 1 || lambda.Body == nil {
+			break
+		}
+		stmts := lambda.Body.Statements
+		param := lambda.Parameters[0].Name
+		line := doLine{value: e.Left}
+		if strings.HasPrefix(param.Value, "$do_bind_") {
+			if len(stmts) != 2 {
+				break
+			}
+			stmt, ok := stmts[0].(*ast.ExpressionStatement)
+			if !ok {
+				break
+			}
+			assign, ok := stmt.Expression.(*ast.PatternAssignExpression)
+			if !ok {
+				break
+			}
+			line.pattern = assign.Pattern
+			stmts = stmts[1:]
+		} else if param.Value != "_" {
+			line.pattern = param
+		}
+		if len(stmts) != 1 {
+			break
+		}
+		rest, ok := stmts[0].(*ast.ExpressionStatement)
+		if !ok {
+			break
+		}
+		return line, rest.Expression, true
+	case *ast.CallExpression:
+		if e.Token.Line != 0 || len(e.Arguments) != 0 {
+			break
+		}
+		fn, ok := e.Function.(*ast.FunctionLiteral)
+		if !ok || fn.Token.Type != token.FUN || fn.Token.Line != 0 ||
+			len(fn.Parameters) != 0 || fn.Body == nil || len(fn.Body.Statements) != 2 {
+			break
+		}
+		decl, ok := fn.Body.Statements[0].(*ast.ConstantDeclaration)
+		if !ok {
+			break
+		}
+		rest, ok := fn.Body.Statements[1].(*ast.ExpressionStatement)
+		if !ok {
+			break
+		}
+		return doLine{value: decl}, rest.Expression, true
+	}
+	return doLine{}, nil, false
+}
+
+func (p *CodePrinter) printDo(lines []doLine) {
+	followed := p.followed
+	p.followed = false
+	p.write("do {\n")
+	p.indent++
+	for _, line := range lines {
+		p.beginLine(line.start())
+		if line.pattern != nil {
+			line.pattern.Accept(p)
+			p.write(" <- ")
+		}
+		line.value.Accept(p)
+		p.writeln()
+	}
+	p.endBraces(position{}, lines[0].start())
+	p.indent--
+	p.writeIndent()
+	p.write("}")
+	p.followed = followed
+}
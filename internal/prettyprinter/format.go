@@ -0,0 +1,66 @@
+package prettyprinter
+
+import (
+	"fmt"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/parser"
+	"github.com/funvibe/funxy/internal/pipeline"
+)
+
+// Format returns the canonical layout of a source file. Comments and the
+// blank lines between statements are kept; everything else is printed by the
+// CodePrinter. Formatted code is parsed again and rejected unless it yields
+// the same program with the same comments, so Format never changes what a
+// file means.
+func Format(source string) (string, error) {
+	program, err := parseSource(source)
+	if err != nil {
+		return "", err
+	}
+
+	layout := scanSource(source)
+	printer := &CodePrinter{lineWidth: 100, layout: layout}
+	program.Accept(printer)
+	out := alignComments(printer.String(), printer.trailing)
+
+	formatted, err := parseSource(out)
+	if err != nil {
+		return "", fmt.Errorf("formatter produced invalid code: %w", err)
+	}
+	if !Equivalent(program, formatted) {
+		return "", fmt.Errorf("formatter changed the meaning of the program")
+	}
+	if !sameComments(layout.comments, scanSource(out).comments) {
+		return "", fmt.Errorf("formatter could not place all comments")
+	}
+	return out, nil
+}
+
+func parseSource(source string) (*ast.Program, error) {
+	ctx := pipeline.NewPipelineContext(source)
+	ctx = pipeline.New(&lexer.LexerProcessor{}, &parser.ParserProcessor{}).Run(ctx)
+	if len(ctx.Errors) > 0 {
+		return nil, ctx.Errors[0]
+	}
+	program, ok := ctx.AstRoot.(*ast.Program)
+	if !ok || program == nil {
+		return nil, fmt.Errorf("no program parsed")
+	}
+	return program, nil
+}
+
+// sameComments reports whether both lists hold the same comment texts in the
+// same order.
+func sameComments(a, b []comment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].text != b[i].text {
+			return false
+		}
+	}
+	return true
+}
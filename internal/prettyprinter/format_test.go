@@ -0,0 +1,126 @@
+package prettyprinter
+
+import (
+	"strings"
+	"testing"
+)
+
+// expectFormat formats input and checks the result and that formatting it
+// again changes nothing.
+func expectFormat(t *testing.T, input, want string) {
+	t.Helper()
+	input = strings.TrimPrefix(input, "\n")
+	want = strings.TrimPrefix(want, "\n")
+	got, err := Format(input)
+	if err != nil {
+		t.Fatalf("format failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	again, err := Format(got)
+	if err != nil {
+		t.Fatalf("formatting the output failed: %v", err)
+	}
+	if again != got {
+		t.Errorf("formatting is not idempotent, second pass:\n%s", again)
+	}
+}
+
+func TestFormatComments(t *testing.T) {
+	expectFormat(t, `
+// Header comment
+import "lib/list" (map)
+
+
+/* block */
+fun double(x) {
+  // leading
+  x*2   // trailing
+  // before brace
+}
+xs = [1,2,3] // numbers
+// last
+`, `
+// Header comment
+import "lib/list" (map)
+
+/* block */
+fun double(x) {
+    // leading
+    x * 2 // trailing
+    // before brace
+}
+xs = [1, 2, 3] // numbers
+// last
+`)
+}
+
+func TestFormatAlignsTrailingComments(t *testing.T) {
+	expectFormat(t, `
+r = match 1 {
+    1 -> "one" // first
+    _ -> "many"   // rest
+}
+`, `
+r = match 1 {
+    1 -> "one"  // first
+    _ -> "many" // rest
+}
+`)
+}
+
+func TestFormatKeepsOneLineBlocks(t *testing.T) {
+	expectFormat(t, `
+fun add(a, b) { a+b }
+fun sub(a, b) {
+    a - b }
+`, `
+fun add(a, b) { a + b }
+fun sub(a, b) {
+    a - b
+}
+`)
+}
+
+func TestFormatDoNotation(t *testing.T) {
+	expectFormat(t, `
+r = do {
+    x <- Some(1)
+    (a, b) <- Some((x, 2))
+    k :- 3
+    Some(a + b + k)
+}
+`, `
+r = do {
+    x <- Some(1)
+    (a, b) <- Some((x, 2))
+    k :- 3
+    Some(a + b + k)
+}
+`)
+}
+
+func TestFormatSugar(t *testing.T) {
+	expectFormat(t, `
+for (k, v) in [(1, 2)] { print(k) }
+n = 0
+n += 2
+f = \ -> 1
+g = (\x -> x)(1)
+s = "${ n+1 } b"
+`, `
+for (k, v) in [(1, 2)] { print(k) }
+n = 0
+n += 2
+f = \ -> 1
+g = (\x -> x)(1)
+s = "${n + 1} b"
+`)
+}
+
+func TestFormatRejectsInvalidSource(t *testing.T) {
+	if _, err := Format("x = (\n"); err == nil {
+		t.Error("expected a parse error")
+	}
+}
@@ -0,0 +1,319 @@
+package prettyprinter
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/token"
+)
+
+// comment is a source comment that the parser dropped.
+type comment struct {
+	line     int
+	col      int
+	text     string
+	trailing bool // code precedes the comment on its line
+}
+
+// position is a line and column in the source.
+type position struct {
+	line, col int
+}
+
+func (a position) before(b position) bool {
+	return a.line < b.line || (a.line == b.line && a.col < b.col)
+}
+
+// bracePair records where a '{' and its matching '}' are.
+type bracePair struct {
+	open, close position
+}
+
+// sourceLayout holds what the printer needs to reproduce the parts of a
+// source file that are not in the AST: comments and blank lines.
+type sourceLayout struct {
+	comments []comment
+	next     int // index of the first comment not printed yet
+	blank    map[int]bool
+	braces   []bracePair // in order of the opening brace
+}
+
+// scanSource collects comments, blank lines and brace positions. The lexer
+// is driven token by token and the text between two tokens is scanned the
+// same way the lexer skips it.
+func scanSource(source string) *sourceLayout {
+	layout := &sourceLayout{blank: make(map[int]bool)}
+	for i, line := range strings.Split(source, "\n") {
+		if strings.TrimSpace(line) == "" {
+			layout.blank[i+1] = true
+		}
+	}
+
+	lineStarts := []int{0}
+	for i := 0; i < len(source); i++ {
+		if source[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	lineOf := func(offset int) int {
+		lo, hi := 0, len(lineStarts)-1
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+			if lineStarts[mid] <= offset {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+		return lo + 1
+	}
+
+	l := lexer.New(source)
+	codeLine := 0 // last line holding a token other than a newline
+	var open []int
+	for {
+		offset := l.Offset()
+		for offset < len(source) {
+			ch := source[offset]
+			if ch == ' ' || ch == '\t' || ch == '\r' {
+				offset++
+				continue
+			}
+			if ch != '/' || offset+1 >= len(source) || (source[offset+1] != '/' && source[offset+1] != '*') {
+				break
+			}
+			end := offset + 2
+			if source[offset+1] == '/' {
+				for end < len(source) && source[end] != '\n' {
+					end++
+				}
+			} else {
+				for end < len(source) && !strings.HasPrefix(source[end:], "*/") {
+					end++
+				}
+				end = min(end+2, len(source))
+			}
+			line := lineOf(offset)
+			layout.comments = append(layout.comments, comment{
+				line:     line,
+				col:      offset - lineStarts[line-1] + 1,
+				text:     strings.TrimRight(source[offset:end], " \t\r"),
+				trailing: codeLine == line,
+			})
+			offset = end
+		}
+
+		tok := l.NextToken()
+		if tok.Type == token.EOF {
+			break
+		}
+		if tok.Type != token.NEWLINE {
+			codeLine = lineOf(l.Offset() - 1)
+		}
+		switch tok.Type {
+		case token.LBRACE, token.PERCENT_LBRACE:
+			layout.braces = append(layout.braces, bracePair{open: position{tok.Line, tok.Column}})
+			open = append(open, len(layout.braces)-1)
+		case token.RBRACE:
+			if len(open) > 0 {
+				layout.braces[open[len(open)-1]].close = position{tok.Line, tok.Column}
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	return layout
+}
+
+// closingBrace returns the position of the '}' that closes the innermost
+// braces opened after start and enclosing inner.
+func (s *sourceLayout) closingBrace(start, inner position) (position, bool) {
+	var found *bracePair
+	for i := range s.braces {
+		pair := &s.braces[i]
+		if !start.before(pair.open) {
+			continue
+		}
+		if !pair.open.before(inner) {
+			break
+		}
+		if inner.before(pair.close) {
+			found = pair
+		}
+	}
+	if found == nil {
+		return position{}, false
+	}
+	return found.close, true
+}
+
+// startPos returns the position of the first token of node.
+func startPos(node ast.Node) position {
+	var first position
+	ast.Inspect(node, func(n ast.Node) bool {
+		if tp, ok := n.(ast.TokenProvider); ok {
+			tok := tp.GetToken()
+			pos := position{tok.Line, tok.Column}
+			if tok.Line > 0 && (first.line == 0 || pos.before(first)) {
+				first = pos
+			}
+		}
+		return true
+	})
+	return first
+}
+
+// beginLine starts the line of an item in a statement list (a statement,
+// method or match arm). The comments that precede the item in the source are
+// printed first, and a blank line that separated the item from the previous
+// one is kept.
+func (p *CodePrinter) beginLine(pos position) {
+	if p.layout != nil && pos.line > 0 {
+		p.flushComments(pos)
+		if p.layout.blank[pos.line-1] {
+			p.blankLine()
+		}
+	}
+	p.writeIndent()
+}
+
+// breakLine starts a new line inside a multi-line expression, such as an
+// element of a long list or a step of a pipe chain. Comments that precede the
+// element in the source are printed first; blank lines are not kept.
+func (p *CodePrinter) breakLine(pos position) {
+	if p.layout != nil && pos.line > 0 {
+		p.flushComments(pos)
+	}
+	p.writeIndent()
+}
+
+// inlineBlock prints a block that was written on one line in the source,
+// such as fun double(x) { x * 2 }, and reports whether it still fits on one.
+func (p *CodePrinter) inlineBlock(n *ast.BlockStatement) (string, bool) {
+	s := p.layout
+	if s == nil || len(n.Statements) != 1 || n.Token.Type != token.LBRACE ||
+		n.Token.Line == 0 || n.Token.Line != n.RBraceToken.Line {
+		return "", false
+	}
+	if s.next < len(s.comments) {
+		c := s.comments[s.next]
+		if (position{c.line, c.col}).before(tokenPos(n.RBraceToken)) {
+			return "", false
+		}
+	}
+	inner := &CodePrinter{lineWidth: p.lineWidth, layout: s}
+	n.Statements[0].Accept(inner)
+	line := inner.String()
+	if strings.Contains(line, "\n") || (p.lineWidth > 0 && p.column+len(line)+4 > p.lineWidth) {
+		return "", false
+	}
+	return line, true
+}
+
+// endList prints the comments left before the closing brace at pos.
+func (p *CodePrinter) endList(pos position) {
+	if p.layout != nil && pos.line > 0 {
+		p.flushComments(pos)
+	}
+}
+
+// endBraces is endList for a construct whose closing brace is not recorded in
+// the AST; it is looked up from the construct's start and its first item.
+func (p *CodePrinter) endBraces(start, first position) {
+	if p.layout == nil {
+		return
+	}
+	if pos, ok := p.layout.closingBrace(start, first); ok {
+		p.flushComments(pos)
+	}
+}
+
+// flushComments prints the pending comments that come before pos. Comments
+// that followed code on their line stay at the end of the last printed line.
+func (p *CodePrinter) flushComments(pos position) {
+	s := p.layout
+	for s.next < len(s.comments) {
+		c := s.comments[s.next]
+		if !(position{c.line, c.col}).before(pos) {
+			break
+		}
+		s.next++
+		if !c.trailing || !p.appendToLine(c.text) {
+			if s.blank[c.line-1] {
+				p.blankLine()
+			}
+			p.writeIndent()
+			p.write(c.text)
+			p.writeln()
+		}
+		p.commentEnd = p.buf.Len()
+	}
+}
+
+// appendToLine adds text to the end of the last, already finished line. A
+// line that already ends in a comment is left alone.
+func (p *CodePrinter) appendToLine(text string) bool {
+	b := p.buf.Bytes()
+	if len(b) < 2 || b[len(b)-1] != '\n' || b[len(b)-2] == '\n' || len(b) == p.commentEnd {
+		return false
+	}
+	p.buf.Truncate(len(b) - 1)
+	p.trailing = append(p.trailing, p.buf.Len())
+	p.buf.WriteString(" " + text + "\n")
+	return true
+}
+
+// alignComments lines up the comments that end consecutive lines. offsets
+// are the positions in out where those comments were appended.
+func alignComments(out string, offsets []int) string {
+	if len(offsets) == 0 {
+		return out
+	}
+	lines := strings.Split(out, "\n")
+	at := make(map[int]int) // line index -> byte column of the comment
+	line, start := 0, 0
+	for _, off := range offsets {
+		for start+len(lines[line])+1 <= off {
+			start += len(lines[line]) + 1
+			line++
+		}
+		at[line] = off - start
+	}
+	for i := 0; i < len(lines); {
+		if _, ok := at[i]; !ok {
+			i++
+			continue
+		}
+		j, width := i, 0
+		for ; j < len(lines); j++ {
+			col, ok := at[j]
+			if !ok {
+				break
+			}
+			width = max(width, utf8.RuneCountInString(lines[j][:col]))
+		}
+		for k := i; k < j; k++ {
+			col := at[k]
+			pad := width - utf8.RuneCountInString(lines[k][:col])
+			lines[k] = lines[k][:col] + strings.Repeat(" ", pad) + lines[k][col:]
+		}
+		i = j
+	}
+	return strings.Join(lines, "\n")
+}
+
+// blankLine ends the current item group with an empty line, unless the
+// output is at the start of the file or of a block, or already has one.
+func (p *CodePrinter) blankLine() {
+	b := p.buf.Bytes()
+	if len(b) == 0 || bytes.HasSuffix(b, []byte("\n\n")) || bytes.HasSuffix(b, []byte("{\n")) {
+		return
+	}
+	p.writeln()
+}
+
+func tokenPos(tok token.Token) position {
+	return position{tok.Line, tok.Column}
+}
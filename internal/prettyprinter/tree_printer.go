@@ -160,6 +160,10 @@ func (p *TreePrinter) VisitTraitDeclaration(n *ast.TraitDeclaration) {
 	}
 	p.write("\n")
 	p.indent++
+	for _, at := range n.AssociatedTypes {
+		p.writeIndent()
+		p.write("AssociatedType: " + at.Name.Value + "\n")
+	}
 	for _, m := range n.Signatures {
 		m.Accept(p)
 	}
@@ -178,6 +182,14 @@ func (p *TreePrinter) VisitInstanceDeclaration(n *ast.InstanceDeclaration) {
 	}
 	p.write("\n")
 	p.indent++
+	for _, at := range n.AssociatedTypes {
+		p.writeIndent()
+		p.write("AssociatedType: " + at.Name.Value + " = ")
+		if at.Type != nil {
+			at.Type.Accept(p)
+		}
+		p.write("\n")
+	}
 	for _, m := range n.Methods {
 		m.Accept(p)
 	}
@@ -611,6 +623,18 @@ func (p *TreePrinter) VisitTypeDeclarationStatement(n *ast.TypeDeclarationStatem
 		p.indent--
 	}
 
+	if len(n.Deriving) > 0 {
+		p.writeIndent()
+		p.write("Deriving: ")
+		for i, d := range n.Deriving {
+			if i > 0 {
+				p.write(", ")
+			}
+			d.Accept(p)
+		}
+		p.write("\n")
+	}
+
 	p.indent--
 	p.write("\n")
 }
@@ -719,6 +743,12 @@ func (p *TreePrinter) VisitRecordPattern(n *ast.RecordPattern) {
 		v.Accept(p)
 		p.write("\n")
 	}
+	if n.Rest != nil {
+		p.writeIndent()
+		p.write("...")
+		n.Rest.Accept(p)
+		p.write("\n")
+	}
 	p.indent--
 }
 
@@ -806,6 +836,12 @@ func (p *TreePrinter) VisitRecordType(n *ast.RecordType) {
 		v.Accept(p)
 		p.write("\n")
 	}
+	if n.Row != nil {
+		p.writeIndent()
+		p.write("...")
+		n.Row.Accept(p)
+		p.write("\n")
+	}
 	p.indent--
 }
 
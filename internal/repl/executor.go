@@ -0,0 +1,90 @@
+package repl
+
+import (
+	"fmt"
+
+	"github.com/funvibe/funxy/internal/analyzer"
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/evaluator"
+	"github.com/funvibe/funxy/internal/modules"
+	"github.com/funvibe/funxy/internal/symbols"
+	"github.com/funvibe/funxy/internal/vm"
+)
+
+// treeWalkExecutor runs every input in the same global environment.
+type treeWalkExecutor struct {
+	eval *evaluator.Evaluator
+	env  *evaluator.Environment
+}
+
+func newTreeWalkExecutor(table *symbols.SymbolTable, loader *modules.Loader) *treeWalkExecutor {
+	eval := evaluator.New()
+	eval.SetLoader(loader)
+	eval.BaseDir = "."
+	eval.CurrentFile = "<repl>"
+
+	env := evaluator.NewEnvironment()
+	env.SymbolTable = table
+	evaluator.RegisterBuiltins(env)
+	evaluator.RegisterBasicTraits(eval, env)
+	evaluator.RegisterStandardTraits(eval, env)
+	evaluator.RegisterFPTraits(eval, env)
+	evaluator.RegisterDictionaryGlobals(eval, env)
+	eval.RegisterExtensionMethods()
+	eval.GlobalEnv = env
+	eval.PushCall("<repl>", eval.CurrentFile, 1, 0)
+
+	return &treeWalkExecutor{eval: eval, env: env}
+}
+
+func (e *treeWalkExecutor) run(program *ast.Program, an *analyzer.Analyzer, table *symbols.SymbolTable, baseDir string) (evaluator.Object, error) {
+	e.eval.TraitDefaults = an.TraitDefaults
+	e.eval.OperatorTraits = table.GetAllOperatorTraits()
+	e.eval.TypeMap = an.TypeMap
+	e.eval.BaseDir = baseDir
+
+	result := e.eval.Eval(program, e.env)
+	// Drop the frames an error left behind, keeping the REPL's own
+	e.eval.CallStack = e.eval.CallStack[:1]
+	if result != nil && result.Type() == evaluator.ERROR_OBJ {
+		return nil, fmt.Errorf("%s", result.Inspect())
+	}
+	return result, nil
+}
+
+// vmExecutor compiles each input separately and runs it on the same VM, whose
+// globals outlive a single chunk.
+type vmExecutor struct {
+	machine *vm.VM
+	globals []string // globals defined by earlier inputs
+}
+
+func newVMExecutor(loader *modules.Loader) *vmExecutor {
+	machine := vm.New()
+	machine.RegisterBuiltins()
+	machine.RegisterFPTraits()
+	machine.SetLoader(loader)
+	return &vmExecutor{machine: machine}
+}
+
+func (e *vmExecutor) run(program *ast.Program, an *analyzer.Analyzer, table *symbols.SymbolTable, baseDir string) (evaluator.Object, error) {
+	compiler := vm.NewCompiler()
+	compiler.SetBaseDir(baseDir)
+	compiler.SetTypeMap(an.TypeMap)
+	compiler.SetSymbolTable(table)
+	compiler.SetResolutionMap(an.ResolutionMap)
+	compiler.DeclareGlobals(e.globals)
+	chunk, err := compiler.Compile(program)
+	if err != nil {
+		return nil, fmt.Errorf("compilation error: %w", err)
+	}
+	e.globals = compiler.GetGlobals()
+
+	e.machine.SetTypeAliases(compiler.GetTypeAliases())
+	e.machine.SetTraitDefaults(an.TraitDefaults)
+	e.machine.SetBaseDir(baseDir)
+	if err := e.machine.ProcessImports(compiler.GetPendingImports()); err != nil {
+		return nil, fmt.Errorf("import error: %w", err)
+	}
+	return e.machine.Run(chunk)
+}
@@ -0,0 +1,71 @@
+package repl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/funvibe/funxy/internal/config"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/token"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// continuations are the tokens, besides binary operators, after which an
+// input cannot end.
+var continuations = map[string]bool{
+	",": true, "=": true, ":-": true, "->": true, "<-": true, "\\": true, ":": true,
+}
+
+// needsMore reports whether source is unfinished: a bracket or raw string is
+// still open, or the last token expects an operand.
+func needsMore(source string) bool {
+	l := lexer.New(source)
+	depth := 0
+	last := ""
+	for {
+		tok := l.NextToken()
+		if tok.Type == token.EOF {
+			break
+		}
+		switch tok.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET, token.PERCENT_LBRACE:
+			depth++
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			depth--
+		case token.NEWLINE:
+			continue
+		case token.STRING:
+			// The lexer closes a raw string at the end of the input
+			if strings.HasPrefix(tok.Lexeme, "`") && l.Offset() >= len(source) && !strings.HasSuffix(strings.TrimRight(source, " \t\r\n"), "`") {
+				return true
+			}
+		}
+		last = tok.Lexeme
+	}
+	if depth > 0 {
+		return true
+	}
+	if continuations[last] {
+		return true
+	}
+	switch last {
+	case "!", "?", "..":
+		// Also postfix or open-ended
+		return false
+	}
+	return config.GetOperator(last) != nil
+}
+
+// formatType prints a type with its inference variables renamed to a, b, c...
+// in order of appearance.
+func formatType(t typesystem.Type) string {
+	subst := make(typesystem.Subst)
+	for i, v := range t.FreeTypeVariables() {
+		name := string(rune('a' + i))
+		if i >= 26 {
+			name = fmt.Sprintf("t%d", i-25)
+		}
+		subst[v.Name] = typesystem.TVar{Name: name}
+	}
+	return t.Apply(subst).String()
+}
@@ -0,0 +1,336 @@
+package repl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// errInterrupted is returned by readLine when the user presses Ctrl-C.
+var errInterrupted = errors.New("interrupted")
+
+// lineEditor reads lines from a terminal in raw mode. It supports cursor
+// movement, history and tab completion.
+type lineEditor struct {
+	in      *os.File
+	out     io.Writer
+	history []string
+	// complete returns the completions of the word that ends the text
+	// before the cursor, and the length in runes of that word.
+	complete func(before string) ([]string, int)
+
+	prompt string
+	buf    []rune
+	pos    int
+}
+
+// readLine reads one line. The terminal is in raw mode only while the line
+// is being edited, so the program run for the line sees a normal terminal.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	restore, err := makeRaw(int(e.in.Fd()))
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	e.prompt, e.buf, e.pos = prompt, nil, 0
+	index := len(e.history) // history entry being shown; len means the new line
+	draft := ""
+	e.refresh()
+
+	for {
+		r, err := e.readRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(e.buf), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+		case 4: // Ctrl-D
+			if len(e.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+		case 127, 8: // Backspace
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+		case 1: // Ctrl-A
+			e.pos = 0
+		case 5: // Ctrl-E
+			e.pos = len(e.buf)
+		case 2: // Ctrl-B
+			e.pos = max(e.pos-1, 0)
+		case 6: // Ctrl-F
+			e.pos = min(e.pos+1, len(e.buf))
+		case 11: // Ctrl-K
+			e.buf = e.buf[:e.pos]
+		case 21: // Ctrl-U
+			e.buf = e.buf[e.pos:]
+			e.pos = 0
+		case 23: // Ctrl-W
+			start := e.wordStart()
+			e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+			e.pos = start
+		case 12: // Ctrl-L
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case 16, 14: // Ctrl-P, Ctrl-N
+			index, draft = e.browse(index, draft, r == 16)
+		case '\t':
+			e.completeWord()
+		case 27:
+			key, err := e.readEscape()
+			if err != nil {
+				return "", err
+			}
+			switch key {
+			case "up", "down":
+				index, draft = e.browse(index, draft, key == "up")
+			case "left":
+				e.pos = max(e.pos-1, 0)
+			case "right":
+				e.pos = min(e.pos+1, len(e.buf))
+			case "home":
+				e.pos = 0
+			case "end":
+				e.pos = len(e.buf)
+			case "delete":
+				e.deleteAt(e.pos)
+			}
+		default:
+			if unicode.IsPrint(r) {
+				e.insert([]rune{r})
+			}
+		}
+		e.refresh()
+	}
+}
+
+// browse moves through the history, keeping the line being typed as draft.
+func (e *lineEditor) browse(index int, draft string, back bool) (int, string) {
+	if index == len(e.history) {
+		draft = string(e.buf)
+	}
+	if back && index > 0 {
+		index--
+	} else if !back && index < len(e.history) {
+		index++
+	} else {
+		return index, draft
+	}
+	line := draft
+	if index < len(e.history) {
+		line = e.history[index]
+	}
+	e.buf = []rune(line)
+	e.pos = len(e.buf)
+	return index, draft
+}
+
+// completeWord completes the word before the cursor. A single candidate is
+// inserted; otherwise their common prefix is, or they are listed.
+func (e *lineEditor) completeWord() {
+	if e.complete == nil {
+		return
+	}
+	candidates, n := e.complete(string(e.buf[:e.pos]))
+	if len(candidates) == 0 {
+		fmt.Fprint(e.out, "\a")
+		return
+	}
+	prefix := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	if len(candidates) == 1 {
+		prefix += " "
+	}
+	if utf8.RuneCountInString(prefix) > n {
+		e.insert([]rune(prefix)[n:])
+		return
+	}
+	fmt.Fprint(e.out, "\r\n")
+	fmt.Fprint(e.out, strings.ReplaceAll(columns(candidates, 80), "\n", "\r\n"))
+}
+
+// columns lays names out in columns that fit width.
+func columns(names []string, width int) string {
+	longest := 0
+	for _, name := range names {
+		longest = max(longest, utf8.RuneCountInString(name))
+	}
+	perLine := max(width/(longest+2), 1)
+	var sb strings.Builder
+	for i, name := range names {
+		sb.WriteString(name)
+		if (i+1)%perLine == 0 || i == len(names)-1 {
+			sb.WriteString("\n")
+		} else {
+			sb.WriteString(strings.Repeat(" ", longest+2-utf8.RuneCountInString(name)))
+		}
+	}
+	return sb.String()
+}
+
+func (e *lineEditor) insert(runes []rune) {
+	e.buf = append(e.buf[:e.pos], append(runes, e.buf[e.pos:]...)...)
+	e.pos += len(runes)
+}
+
+func (e *lineEditor) deleteAt(i int) {
+	if i < len(e.buf) {
+		e.buf = append(e.buf[:i], e.buf[i+1:]...)
+	}
+}
+
+// wordStart returns the start of the word before the cursor, skipping the
+// spaces that precede the cursor.
+func (e *lineEditor) wordStart() int {
+	i := e.pos
+	for i > 0 && e.buf[i-1] == ' ' {
+		i--
+	}
+	for i > 0 && e.buf[i-1] != ' ' {
+		i--
+	}
+	return i
+}
+
+// refresh redraws the line and places the cursor.
+func (e *lineEditor) refresh() {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", e.prompt, string(e.buf))
+	if back := len(e.buf) - e.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+func (e *lineEditor) readByte() (byte, error) {
+	var b [1]byte
+	for {
+		n, err := e.in.Read(b[:])
+		if n == 1 {
+			return b[0], nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (e *lineEditor) readRune() (rune, error) {
+	first, err := e.readByte()
+	if err != nil {
+		return 0, err
+	}
+	if first < utf8.RuneSelf {
+		return rune(first), nil
+	}
+	seq := []byte{first}
+	for !utf8.FullRune(seq) {
+		b, err := e.readByte()
+		if err != nil {
+			return 0, err
+		}
+		seq = append(seq, b)
+	}
+	r, _ := utf8.DecodeRune(seq)
+	return r, nil
+}
+
+// readEscape reads the rest of an escape sequence and names the key.
+func (e *lineEditor) readEscape() (string, error) {
+	b, err := e.readByte()
+	if err != nil {
+		return "", err
+	}
+	if b != '[' && b != 'O' {
+		return "", nil
+	}
+	b, err = e.readByte()
+	if err != nil {
+		return "", err
+	}
+	switch b {
+	case 'A':
+		return "up", nil
+	case 'B':
+		return "down", nil
+	case 'C':
+		return "right", nil
+	case 'D':
+		return "left", nil
+	case 'H':
+		return "home", nil
+	case 'F':
+		return "end", nil
+	}
+	// Sequences like ESC [ 3 ~
+	code := b
+	for b >= '0' && b <= '9' || b == ';' {
+		if b, err = e.readByte(); err != nil {
+			return "", err
+		}
+	}
+	if b != '~' {
+		return "", nil
+	}
+	switch code {
+	case '1', '7':
+		return "home", nil
+	case '4', '8':
+		return "end", nil
+	case '3':
+		return "delete", nil
+	}
+	return "", nil
+}
+
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".funxy_history")
+}
+
+func loadHistory() []string {
+	path := historyPath()
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+}
+
+// addHistory records the lines of an input and saves the last historySize
+// lines. Failing to save them is not worth interrupting the session for.
+func (e *lineEditor) addHistory(input string) {
+	for _, line := range strings.Split(input, "\n") {
+		if line != "" && (len(e.history) == 0 || e.history[len(e.history)-1] != line) {
+			e.history = append(e.history, line)
+		}
+	}
+	if len(e.history) > historySize {
+		e.history = e.history[len(e.history)-historySize:]
+	}
+	if path := historyPath(); path != "" {
+		_ = os.WriteFile(path, []byte(strings.Join(e.history, "\n")+"\n"), 0o600)
+	}
+}
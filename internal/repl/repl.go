@@ -0,0 +1,223 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/funvibe/funxy/internal/modules"
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	prompt         = "> "
+	continuePrompt = "| "
+	historySize    = 1000
+)
+
+// commands lists the REPL commands with their help lines.
+var commands = []struct{ name, args, help string }{
+	{":help", "", "show this help"},
+	{":type", "<expr>", "show the type of an expression"},
+	{":doc", "<name>", "show the documentation of a builtin, a package or a definition"},
+	{":load", "<file>", "evaluate a source file"},
+	{":reset", "", "forget every definition"},
+	{":quit", "", "exit (or Ctrl-D)"},
+}
+
+// lineReader reads the lines of the inputs.
+type lineReader interface {
+	readLine(prompt string) (string, error)
+}
+
+// plainReader reads lines from a non-terminal input without prompting.
+type plainReader struct {
+	in *bufio.Reader
+}
+
+func (r *plainReader) readLine(string) (string, error) {
+	line, err := r.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// REPL reads inputs, evaluates them in a Session and prints the results.
+type REPL struct {
+	session  *Session
+	treeWalk bool
+	reader   lineReader
+	out      io.Writer
+}
+
+// New creates a REPL that reads from in and writes results and errors to
+// out. When in is a terminal, lines can be edited, the history is kept in
+// ~/.funxy_history and Tab completes names.
+func New(in io.Reader, out io.Writer, treeWalk bool) *REPL {
+	r := &REPL{session: NewSession(treeWalk), treeWalk: treeWalk, out: out}
+	if f, ok := in.(*os.File); ok && lineEditing && isatty.IsTerminal(f.Fd()) {
+		r.reader = &lineEditor{in: f, out: out, history: loadHistory(), complete: r.complete}
+		return r
+	}
+	r.reader = &plainReader{in: bufio.NewReader(in)}
+	return r
+}
+
+// Run reads and evaluates inputs until the end of the input or :quit.
+func (r *REPL) Run() {
+	_, interactive := r.reader.(*lineEditor)
+	if interactive {
+		fmt.Fprintln(r.out, "Funxy REPL. Type :help for commands, :quit to exit.")
+	}
+	for {
+		input, err := r.readInput()
+		if errors.Is(err, errInterrupted) {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		if strings.TrimSpace(input) == "" {
+			continue
+		}
+		if editor, ok := r.reader.(*lineEditor); ok {
+			editor.addHistory(input)
+		}
+		if strings.HasPrefix(strings.TrimSpace(input), ":") {
+			if !r.command(strings.TrimSpace(input)) {
+				return
+			}
+			continue
+		}
+		r.eval(input)
+	}
+}
+
+// readInput reads one input, which spans several lines while it is
+// unfinished. An empty line ends an unfinished input anyway.
+func (r *REPL) readInput() (string, error) {
+	line, err := r.reader.readLine(prompt)
+	if err != nil {
+		return "", err
+	}
+	input := line
+	for !strings.HasPrefix(strings.TrimSpace(input), ":") && needsMore(input) {
+		line, err = r.reader.readLine(continuePrompt)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+		input += "\n" + line
+	}
+	return input, nil
+}
+
+func (r *REPL) eval(input string) {
+	result, show, err := r.session.Eval(input)
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+	if show {
+		fmt.Fprintln(r.out, result.Inspect())
+	}
+}
+
+// command runs a REPL command and reports whether the REPL should go on.
+func (r *REPL) command(input string) bool {
+	name, arg, _ := strings.Cut(input, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case ":help", ":h", ":?":
+		for _, c := range commands {
+			fmt.Fprintf(r.out, "  %-18s %s\n", strings.TrimSpace(c.name+" "+c.args), c.help)
+		}
+	case ":type", ":t":
+		t, err := r.session.TypeOf(arg)
+		if err != nil {
+			fmt.Fprintln(r.out, err)
+		} else {
+			fmt.Fprintf(r.out, "%s : %s\n", arg, formatType(t))
+		}
+	case ":doc", ":d":
+		r.doc(arg)
+	case ":load", ":l":
+		if err := r.session.Load(arg); err != nil {
+			fmt.Fprintln(r.out, err)
+		} else {
+			fmt.Fprintf(r.out, "Loaded %s\n", arg)
+		}
+	case ":reset":
+		r.session = NewSession(r.treeWalk)
+	case ":quit", ":q":
+		return false
+	default:
+		fmt.Fprintf(r.out, "Unknown command %s (:help lists the commands)\n", name)
+	}
+	return true
+}
+
+// doc prints the documentation of a package or of the builtins named name,
+// or the type of a name defined in the session.
+func (r *REPL) doc(name string) {
+	if name == "" {
+		fmt.Fprintln(r.out, "Usage: :doc <name>")
+		return
+	}
+	// A full package path wins, then builtins, then a short package name,
+	// so ":doc map" is the function and ":doc lib/map" the package.
+	if pkg := modules.GetDocPackage(name); pkg != nil {
+		fmt.Fprint(r.out, modules.FormatDocPackage(pkg))
+		return
+	}
+	if matches := modules.FindDocs(name); len(matches) > 0 {
+		for _, m := range matches {
+			fmt.Fprintf(r.out, "%s:\n", m.Package)
+			fmt.Fprint(r.out, modules.FormatDocEntry(m.Entry))
+		}
+		return
+	}
+	if pkg := modules.GetDocPackage("lib/" + name); pkg != nil {
+		fmt.Fprint(r.out, modules.FormatDocPackage(pkg))
+		return
+	}
+	if sym, ok := r.session.Lookup(name); ok && sym.Type != nil {
+		fmt.Fprintf(r.out, "  %s : %s\n", name, formatType(sym.Type))
+		return
+	}
+	fmt.Fprintf(r.out, "No documentation for %s\n", name)
+}
+
+// complete returns the completions for the word before the cursor: command
+// names at the start of a line, otherwise the names known to the session.
+func (r *REPL) complete(before string) ([]string, int) {
+	if strings.HasPrefix(before, ":") && !strings.Contains(before, " ") {
+		var names []string
+		for _, c := range commands {
+			if strings.HasPrefix(c.name, before) {
+				names = append(names, c.name)
+			}
+		}
+		return names, len([]rune(before))
+	}
+	runes := []rune(before)
+	start := len(runes)
+	for start > 0 && (unicode.IsLetter(runes[start-1]) || unicode.IsDigit(runes[start-1]) || runes[start-1] == '_') {
+		start--
+	}
+	word := string(runes[start:])
+	if word == "" {
+		return nil, 0
+	}
+	names := r.session.Complete(word)
+	sort.Strings(names)
+	return names, len(runes) - start
+}
@@ -0,0 +1,233 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/funvibe/funxy/internal/modules"
+)
+
+// runREPL feeds input to a REPL on the given backend and returns its output.
+func runREPL(t *testing.T, treeWalk bool, input string) string {
+	t.Helper()
+	modules.InitVirtualPackages()
+	var out bytes.Buffer
+	New(strings.NewReader(input), &out, treeWalk).Run()
+	return out.String()
+}
+
+func backends(t *testing.T, test func(t *testing.T, treeWalk bool)) {
+	t.Run("vm", func(t *testing.T) { test(t, false) })
+	t.Run("tree", func(t *testing.T) { test(t, true) })
+}
+
+func expectOutput(t *testing.T, got string, want ...string) {
+	t.Helper()
+	if got != strings.Join(want, "\n")+"\n" {
+		t.Errorf("expected output:\n%s\ngot:\n%s", strings.Join(want, "\n"), got)
+	}
+}
+
+func TestDefinitionsPersist(t *testing.T) {
+	backends(t, func(t *testing.T, treeWalk bool) {
+		out := runREPL(t, treeWalk, `x = 1
+fun inc(n) { n + x }
+inc(41)
+x = 5
+inc(1)
+fun fact(n) { if n <= 1 { 1 } else { n * fact(n - 1) } }
+fact(10)
+`)
+		expectOutput(t, out, "42", "6", "3628800")
+	})
+}
+
+func TestTypesAndInstancesPersist(t *testing.T) {
+	backends(t, func(t *testing.T, treeWalk bool) {
+		out := runREPL(t, treeWalk, `type Shape = Circle(Float) | Square(Float)
+trait Area<t> { fun area(s: t) -> Float }
+instance Area Shape { fun area(s) { match s { Circle(r) -> r * r * 3.0, Square(a) -> a * a } } }
+area(Square(2.0))
+`)
+		expectOutput(t, out, "4")
+	})
+}
+
+func TestImportsPersist(t *testing.T) {
+	backends(t, func(t *testing.T, treeWalk bool) {
+		out := runREPL(t, treeWalk, `import "lib/list" (foldl)
+foldl((+), 0, [1, 2, 3])
+`)
+		expectOutput(t, out, "6")
+	})
+}
+
+func TestErrorsDoNotEndSession(t *testing.T) {
+	backends(t, func(t *testing.T, treeWalk bool) {
+		out := runREPL(t, treeWalk, `fun f() { undefinedName }
+fun f() { 2 }
+f()
+1 / 0
+f() + 1
+`)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if !strings.Contains(lines[0], "undefinedName") {
+			t.Errorf("expected an undefined name error, got %q", lines[0])
+		}
+		if len(lines) < 2 || lines[1] != "2" {
+			t.Errorf("expected a redefinition after the failed one to work, got:\n%s", out)
+		}
+		if !strings.Contains(out, "division by zero") {
+			t.Errorf("expected a runtime error, got:\n%s", out)
+		}
+		if lines[len(lines)-1] != "3" {
+			t.Errorf("expected the session to survive a runtime error, got:\n%s", out)
+		}
+	})
+}
+
+func TestMultiLineInput(t *testing.T) {
+	backends(t, func(t *testing.T, treeWalk bool) {
+		out := runREPL(t, treeWalk, `fun add(a, b) {
+    a + b
+}
+xs = [1,
+      2]
+add(xs[0],
+    xs[1])
+1 +
+2
+`)
+		expectOutput(t, out, "3", "3")
+	})
+}
+
+func TestTypeCommand(t *testing.T) {
+	out := runREPL(t, false, `fun inc(n: Int) -> Int { n + 1 }
+:type inc
+:t fun(a) { a }
+:type [1, 2]
+:type nope
+`)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	want := []string{"inc : (Int) -> Int", "fun(a) { a } : (a) -> a", "[1, 2] : (List Int)"}
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got:\n%s", out)
+	}
+	for i, w := range want {
+		if lines[i] != w {
+			t.Errorf("line %d: expected %q, got %q", i+1, w, lines[i])
+		}
+	}
+	if !strings.Contains(lines[3], "nope") {
+		t.Errorf("expected an error naming nope, got %q", lines[3])
+	}
+}
+
+func TestDocCommand(t *testing.T) {
+	out := runREPL(t, false, `:doc map
+:doc lib/json
+fun twice(n) { n * 2 }
+:doc twice
+:doc nothingLikeThis
+`)
+	for _, want := range []string{
+		"lib/list:\n  map : ",
+		"=== lib/json ===",
+		"  twice : (",
+		"No documentation for nothingLikeThis",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestLoadCommand(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "helper"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "helper", "helper.lang"), []byte("package helper (helper)\n\nfun helper() { 20 }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	main := filepath.Join(dir, "main.lang")
+	source := "import \"./helper\" (helper)\nfun answer() { helper() + 22 }\n"
+	if err := os.WriteFile(main, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	backends(t, func(t *testing.T, treeWalk bool) {
+		out := runREPL(t, treeWalk, ":load "+main+"\nanswer()\n:load missing.lang\n")
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != 3 || lines[0] != "Loaded "+main || lines[1] != "42" {
+			t.Fatalf("unexpected output:\n%s", out)
+		}
+		if !strings.Contains(lines[2], "missing.lang") {
+			t.Errorf("expected an error for the missing file, got %q", lines[2])
+		}
+	})
+}
+
+func TestResetAndQuit(t *testing.T) {
+	out := runREPL(t, false, `x = 1
+:reset
+x = "now a string"
+x
+:quit
+x
+`)
+	expectOutput(t, out, `"now a string"`)
+}
+
+func TestNeedsMore(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{"x = 1", false},
+		{"x =", true},
+		{"fun f() {", true},
+		{"fun f() {\n  1\n}", false},
+		{"[1, 2", true},
+		{"%{ \"a\" => 1", true},
+		{"1 +", true},
+		{"xs |>", true},
+		{"f(1,", true},
+		{"\\x ->", true},
+		{"x?", false},
+		{"`raw", true},
+		{"`raw\nstring`", false},
+		{"\"a {\"", false},
+	}
+	for _, tt := range tests {
+		if got := needsMore(tt.source); got != tt.want {
+			t.Errorf("needsMore(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	modules.InitVirtualPackages()
+	r := New(strings.NewReader(""), &bytes.Buffer{}, false)
+	r.session.Eval("fun myHelper() { 1 }\nmyValue = 2")
+
+	names, n := r.complete("print(myH")
+	if strings.Join(names, " ") != "myHelper" || n != 3 {
+		t.Errorf("expected [myHelper] for 3 runes, got %v for %d", names, n)
+	}
+	names, _ = r.complete("my")
+	if strings.Join(names, " ") != "myHelper myValue" {
+		t.Errorf("expected both definitions, got %v", names)
+	}
+	names, _ = r.complete("pri")
+	if len(names) == 0 || names[0] != "print" {
+		t.Errorf("expected builtins to complete, got %v", names)
+	}
+	names, n = r.complete(":t")
+	if strings.Join(names, " ") != ":type" || n != 2 {
+		t.Errorf("expected [:type] for 2 runes, got %v for %d", names, n)
+	}
+}
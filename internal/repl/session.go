@@ -0,0 +1,193 @@
+// Package repl implements the interactive read-eval-print loop. A Session
+// keeps the definitions, imports, types and trait instances of every input,
+// so later inputs can use them, on either execution backend.
+package repl
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/funvibe/funxy/internal/analyzer"
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/completion"
+	"github.com/funvibe/funxy/internal/diagnostics"
+	"github.com/funvibe/funxy/internal/evaluator"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/modules"
+	"github.com/funvibe/funxy/internal/parser"
+	"github.com/funvibe/funxy/internal/pipeline"
+	"github.com/funvibe/funxy/internal/symbols"
+	"github.com/funvibe/funxy/internal/typesystem"
+)
+
+// Session analyzes and runs a sequence of inputs in one environment. Each
+// input is analyzed against the symbol table left by the previous ones and
+// then run by a backend that keeps its globals between runs.
+type Session struct {
+	symbols  *symbols.SymbolTable
+	analyzer *analyzer.Analyzer
+	exec     executor
+}
+
+// executor runs analyzed programs, keeping the globals they define.
+type executor interface {
+	run(program *ast.Program, an *analyzer.Analyzer, table *symbols.SymbolTable, baseDir string) (evaluator.Object, error)
+}
+
+// NewSession creates a session that runs inputs on the tree-walk interpreter
+// when treeWalk is set and on the VM otherwise. Virtual packages must be
+// initialized before inputs that import them are evaluated.
+func NewSession(treeWalk bool) *Session {
+	table := symbols.NewSymbolTable()
+	analyzer.RegisterBuiltins(table)
+
+	loader := modules.NewLoader()
+	an := analyzer.New(table)
+	an.SetLoader(loader)
+	an.Incremental = true
+
+	s := &Session{symbols: table, analyzer: an}
+	if treeWalk {
+		s.exec = newTreeWalkExecutor(table, loader)
+	} else {
+		s.exec = newVMExecutor(loader)
+	}
+	return s
+}
+
+// Eval analyzes and runs one input. It returns the value of the input and
+// whether it should be shown: only inputs ending in an expression that is not
+// an assignment have a value worth printing.
+func (s *Session) Eval(source string) (evaluator.Object, bool, error) {
+	return s.eval(source, ".")
+}
+
+// Load evaluates the contents of a source file. Relative imports in the file
+// are resolved from its directory.
+func (s *Session) Load(path string) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.eval(string(source), filepath.Dir(path))
+	return err
+}
+
+func (s *Session) eval(source, baseDir string) (evaluator.Object, bool, error) {
+	program, err := parse(source)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(program.Statements) == 0 {
+		return nil, false, nil
+	}
+
+	// A failed input must not leave its definitions behind, or entering it
+	// again after a fix would be a redefinition.
+	defined := maps.Clone(s.symbols.All())
+	s.analyzer.BaseDir = baseDir
+	if errs := s.analyzer.Analyze(program, s.context(source)); len(errs) > 0 {
+		for name := range s.symbols.All() {
+			if _, ok := defined[name]; !ok {
+				s.symbols.Remove(name)
+			}
+		}
+		return nil, false, joinErrors(errs)
+	}
+
+	result, err := s.exec.run(program, s.analyzer, s.symbols, baseDir)
+	if err != nil {
+		return nil, false, err
+	}
+	show := hasValue(program) && result != nil && result.Type() != evaluator.NIL_OBJ
+	return result, show, nil
+}
+
+// TypeOf returns the type of an expression without running it.
+func (s *Session) TypeOf(source string) (typesystem.Type, error) {
+	program, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+	if len(program.Statements) != 1 || !hasValue(program) {
+		return nil, fmt.Errorf("expected a single expression")
+	}
+	expr := program.Statements[0].(*ast.ExpressionStatement).Expression
+	if errs := s.analyzer.Analyze(program, s.context(source)); len(errs) > 0 {
+		return nil, joinErrors(errs)
+	}
+	// A name has the type it was declared with, not an instance of it
+	if ident, ok := expr.(*ast.Identifier); ok {
+		if sym, ok := s.symbols.Find(ident.Value); ok && sym.Type != nil {
+			return sym.Type, nil
+		}
+	}
+	t, ok := s.analyzer.TypeMap[expr]
+	if !ok {
+		return nil, fmt.Errorf("could not infer a type")
+	}
+	return t, nil
+}
+
+// Lookup returns the symbol that an earlier input defined or imported.
+func (s *Session) Lookup(name string) (symbols.Symbol, bool) {
+	return s.symbols.Find(name)
+}
+
+// Complete returns the names known to the session that start with prefix.
+func (s *Session) Complete(prefix string) []string {
+	var names []string
+	for _, item := range completion.Items(nil, s.symbols, 0, 0) {
+		if strings.HasPrefix(item.Label, prefix) && !strings.HasPrefix(item.Label, "$") {
+			names = append(names, item.Label)
+		}
+	}
+	return names
+}
+
+func (s *Session) context(source string) *pipeline.PipelineContext {
+	ctx := pipeline.NewPipelineContext(source)
+	ctx.SymbolTable = s.symbols
+	return ctx
+}
+
+func parse(source string) (*ast.Program, error) {
+	ctx := pipeline.NewPipelineContext(source)
+	ctx = pipeline.New(&lexer.LexerProcessor{}, &parser.ParserProcessor{}).Run(ctx)
+	if len(ctx.Errors) > 0 {
+		return nil, joinErrors(ctx.Errors)
+	}
+	program, ok := ctx.AstRoot.(*ast.Program)
+	if !ok || program == nil {
+		return nil, fmt.Errorf("no program parsed")
+	}
+	return program, nil
+}
+
+// hasValue reports whether the program ends in an expression that is not an
+// assignment.
+func hasValue(program *ast.Program) bool {
+	if len(program.Statements) == 0 {
+		return false
+	}
+	stmt, ok := program.Statements[len(program.Statements)-1].(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	switch stmt.Expression.(type) {
+	case *ast.AssignExpression, *ast.PatternAssignExpression:
+		return false
+	}
+	return true
+}
+
+func joinErrors(errs []*diagnostics.DiagnosticError) error {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "\n"))
+}
@@ -0,0 +1,10 @@
+//go:build darwin || freebsd || openbsd
+
+package repl
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlGetTermios = unix.TIOCGETA
+	ioctlSetTermios = unix.TIOCSETA
+)
@@ -0,0 +1,13 @@
+//go:build !darwin && !linux && !freebsd && !openbsd
+
+package repl
+
+import "fmt"
+
+// lineEditing reports whether the terminal can be switched to raw mode. Here
+// it cannot, and the REPL reads plain lines instead.
+const lineEditing = false
+
+func makeRaw(fd int) (func(), error) {
+	return nil, fmt.Errorf("line editing is supported only on Linux, macOS, FreeBSD, and OpenBSD")
+}
@@ -23,6 +23,10 @@ type SymbolTable struct {
 	// Trait Functional Dependencies: TraitName -> [FunDeps]
 	traitFunctionalDependencies map[string][]ast.FunctionalDependency
 
+	// Associated types: qualified TypeName -> TraitName
+	// e.g. "Container.Elem" -> "Container" for trait Container<c> { type Elem }
+	associatedTypes map[string]string
+
 	// Trait type parameter kinds: TraitName -> ParamName -> Kind
 	traitTypeParamKinds map[string]map[string]typesystem.Kind
 
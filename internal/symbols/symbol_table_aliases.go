@@ -252,7 +252,7 @@ func (s *SymbolTable) resolveTypeAliasWithCycleCheck(t typesystem.Type, visited
 		for k, v := range ty.Fields {
 			resolvedFields[k] = s.resolveTypeAliasWithCycleCheck(v, visited)
 		}
-		return typesystem.TRecord{Fields: resolvedFields, IsOpen: ty.IsOpen}
+		return typesystem.TRecord{Fields: resolvedFields, IsOpen: ty.IsOpen, Row: ty.Row}
 	default:
 		return t
 	}
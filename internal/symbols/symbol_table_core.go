@@ -55,8 +55,9 @@ func (s Symbol) IsTypeAlias() bool {
 type InstanceDef struct {
 	TraitName       string
 	TargetTypes     []typesystem.Type
-	ConstructorName string                  // Name of the dictionary constructor or global instance
-	Requirements    []typesystem.Constraint // Constraints for generic instances
+	ConstructorName string                     // Name of the dictionary constructor or global instance
+	Requirements    []typesystem.Constraint    // Constraints for generic instances
+	AssociatedTypes map[string]typesystem.Type // Associated type definitions: Elem -> Int
 }
 
 type Constraint struct {
@@ -82,6 +83,8 @@ func getTypeConstructorName(t typesystem.Type) string {
 		return tt.Name
 	case typesystem.TApp:
 		return getTypeConstructorName(tt.Constructor)
+	case typesystem.TLambda:
+		return getTypeConstructorName(tt.Body)
 	default:
 		return ""
 	}
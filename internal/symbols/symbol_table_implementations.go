@@ -3,6 +3,8 @@ package symbols
 import (
 	"fmt"
 	"github.com/funvibe/funxy/internal/typesystem"
+	"reflect"
+	"strings"
 )
 
 // GetOptionalUnwrapReturnType returns the return type of unwrap for a specific type.
@@ -95,8 +97,7 @@ func (s *SymbolTable) RegisterImplementation(traitName string, args []typesystem
 		overlap := true
 		for i, arg := range args {
 			tRenamed := RenameTypeVars(arg, "new")
-			_, err := typesystem.Unify(existingDef.TargetTypes[i], tRenamed)
-			if err != nil {
+			if !headsOverlap(existingDef.TargetTypes[i], tRenamed) {
 				overlap = false
 				break
 			}
@@ -127,6 +128,27 @@ func (s *SymbolTable) RegisterImplementation(traitName string, args []typesystem
 	return nil
 }
 
+// headsOverlap reports whether two instance targets can match the same type.
+// Heads with holes are compared by what they produce when applied: Pair<_, Int>
+// and Pair<Int, _> overlap on Pair<Int, Int>, while Pair<_, Int> and
+// Pair<_, String> do not.
+func headsOverlap(a, b typesystem.Type) bool {
+	lam, ok := a.(typesystem.TLambda)
+	if !ok {
+		lam, ok = b.(typesystem.TLambda)
+	}
+	if ok {
+		args := make([]typesystem.Type, len(lam.Params))
+		for i, p := range lam.Params {
+			args[i] = typesystem.TVar{Name: "$overlap" + typesystem.HoleName(i), KindVal: p.Kind()}
+		}
+		a = typesystem.TApp{Constructor: a, Args: args}.Apply(typesystem.Subst{})
+		b = typesystem.TApp{Constructor: b, Args: args}.Apply(typesystem.Subst{})
+	}
+	_, err := typesystem.Unify(a, b)
+	return err == nil
+}
+
 // FindMatchingImplementation finds an instance definition that matches the given arguments.
 // It returns the InstanceDef and the substitution map derived from unification.
 func (s *SymbolTable) FindMatchingImplementation(traitName string, args []typesystem.Type) (*InstanceDef, typesystem.Subst, error) {
@@ -160,14 +182,16 @@ func (s *SymbolTable) FindMatchingImplementation(traitName string, args []typesy
 					argExpanded := s.ResolveTypeAlias(args[j])
 					implArgExpanded := s.ResolveTypeAlias(implArg)
 
-					// Try combinations
-					if argExpanded != args[j] {
+					// Try combinations (DeepEqual: TApp holds slices and is not comparable)
+					argChanged := !reflect.DeepEqual(argExpanded, args[j])
+					implArgChanged := !reflect.DeepEqual(implArgExpanded, implArg)
+					if argChanged {
 						subst, err = typesystem.Unify(implArg, argExpanded)
 					}
-					if err != nil && implArgExpanded != implArg {
+					if err != nil && implArgChanged {
 						subst, err = typesystem.Unify(implArgExpanded, args[j])
 					}
-					if err != nil && argExpanded != args[j] && implArgExpanded != implArg {
+					if err != nil && argChanged && implArgChanged {
 						subst, err = typesystem.Unify(implArgExpanded, argExpanded)
 					}
 				}
@@ -350,3 +374,129 @@ func (s *SymbolTable) GetAllImplementations() map[string][]InstanceDef {
 
 	return result
 }
+
+// RegisterInstanceAssociatedType stores the definition of the associated type
+// name in the implementation of traitName for args, which must already be
+// registered in this scope: type Elem = Int in instance Container IntBag.
+func (s *SymbolTable) RegisterInstanceAssociatedType(traitName string, args []typesystem.Type, name string, t typesystem.Type) {
+	impls := s.implementations[traitName]
+	for i := len(impls) - 1; i >= 0; i-- {
+		if !reflect.DeepEqual(impls[i].TargetTypes, args) {
+			continue
+		}
+		if impls[i].AssociatedTypes == nil {
+			impls[i].AssociatedTypes = make(map[string]typesystem.Type)
+		}
+		impls[i].AssociatedTypes[name] = t
+		return
+	}
+}
+
+// IsAssociatedType reports whether app applies an associated type (Elem<c>).
+func (s *SymbolTable) IsAssociatedType(app typesystem.TApp) bool {
+	con, ok := app.Constructor.(typesystem.TCon)
+	if !ok {
+		return false
+	}
+	_, ok = s.GetAssociatedTypeTrait(con.Name)
+	return ok
+}
+
+// DeferAssociatedType reports false: without an inference context an
+// equation on an undetermined associated type cannot be postponed.
+func (s *SymbolTable) DeferAssociatedType(expected, actual typesystem.Type) bool {
+	return false
+}
+
+// ReduceAssociatedType rewrites an associated type application such as
+// Elem<List<Int>> to the type the matching instance defines for it. It
+// reports false if app is not an associated type application or if no
+// instance determines it yet, as for Elem<t> with t still unknown.
+func (s *SymbolTable) ReduceAssociatedType(app typesystem.TApp) (typesystem.Type, bool) {
+	con, ok := app.Constructor.(typesystem.TCon)
+	if !ok {
+		return nil, false
+	}
+	traitName, ok := s.GetAssociatedTypeTrait(con.Name)
+	if !ok {
+		return nil, false
+	}
+	def, subst, err := s.FindMatchingImplementation(traitName, app.Args)
+	if err != nil {
+		return nil, false
+	}
+	// The arguments alone must select the instance: a match that binds
+	// one of their type variables is only a guess.
+	for name := range subst {
+		if !strings.HasSuffix(name, "_inst") {
+			return nil, false
+		}
+	}
+	t, ok := def.AssociatedTypes[strings.TrimPrefix(con.Name, traitName+".")]
+	if !ok {
+		return nil, false
+	}
+	return RenameTypeVars(t, "inst").Apply(subst), true
+}
+
+// ReduceAssociatedTypes replaces the associated type applications in t that
+// an instance determines with their definitions.
+func (s *SymbolTable) ReduceAssociatedTypes(t typesystem.Type) typesystem.Type {
+	if !s.ContainsAssociatedType(t) {
+		return t
+	}
+	return typesystem.ReplaceTApp(t, s.ReduceAssociatedType)
+}
+
+// ContainsAssociatedType reports whether t mentions an associated type.
+func (s *SymbolTable) ContainsAssociatedType(t typesystem.Type) bool {
+	return t != nil && s.hasAssociatedTypes() && s.containsAssociatedType(t)
+}
+
+func (s *SymbolTable) containsAssociatedType(t typesystem.Type) bool {
+	switch typ := t.(type) {
+	case typesystem.TCon:
+		_, ok := s.GetAssociatedTypeTrait(typ.Name)
+		return ok
+	case typesystem.TApp:
+		if s.containsAssociatedType(typ.Constructor) {
+			return true
+		}
+		for _, arg := range typ.Args {
+			if s.containsAssociatedType(arg) {
+				return true
+			}
+		}
+	case typesystem.TFunc:
+		for _, p := range typ.Params {
+			if s.containsAssociatedType(p) {
+				return true
+			}
+		}
+		return s.containsAssociatedType(typ.ReturnType)
+	case typesystem.TTuple:
+		for _, el := range typ.Elements {
+			if s.containsAssociatedType(el) {
+				return true
+			}
+		}
+	case typesystem.TRecord:
+		for _, field := range typ.Fields {
+			if s.containsAssociatedType(field) {
+				return true
+			}
+		}
+		if typ.Row != nil {
+			return s.containsAssociatedType(typ.Row)
+		}
+	case typesystem.TUnion:
+		for _, u := range typ.Types {
+			if s.containsAssociatedType(u) {
+				return true
+			}
+		}
+	case typesystem.TForall:
+		return s.containsAssociatedType(typ.Type)
+	}
+	return false
+}
@@ -15,6 +15,7 @@ func NewEmptySymbolTable() *SymbolTable {
 		traitTypeParamKinds:         make(map[string]map[string]typesystem.Kind),
 		traitSuperTraits:            make(map[string][]string),
 		traitFunctionalDependencies: make(map[string][]ast.FunctionalDependency),
+		associatedTypes:             make(map[string]string),
 		traitDefaultMethods:         make(map[string]map[string]bool),
 		traitAllMethods:             make(map[string][]string),
 		operatorTraits:              make(map[string]string),
@@ -104,6 +105,13 @@ func (s *SymbolTable) Define(name string, t typesystem.Type, origin string) {
 	s.store[name] = Symbol{Name: name, Type: t, Kind: VariableSymbol, IsConstant: false, OriginModule: origin}
 }
 
+// Remove deletes name from the current scope. The REPL uses it to drop the
+// definitions of an input that failed to analyze.
+func (s *SymbolTable) Remove(name string) {
+	delete(s.store, name)
+	delete(s.types, name)
+}
+
 // SetDefinitionNode updates the DefinitionNode for an existing symbol in the current scope
 func (s *SymbolTable) SetDefinitionNode(name string, node ast.Node) {
 	if sym, ok := s.store[name]; ok {
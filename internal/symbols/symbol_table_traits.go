@@ -3,6 +3,8 @@ package symbols
 import (
 	"github.com/funvibe/funxy/internal/ast"
 	"github.com/funvibe/funxy/internal/typesystem"
+	"sort"
+	"strings"
 )
 
 func (s *SymbolTable) RegisterTraitTypeParamKind(traitName, paramName string, k typesystem.Kind) {
@@ -252,3 +254,70 @@ func (s *SymbolTable) GetTraitFunctionalDependencies(traitName string) ([]ast.Fu
 	}
 	return deps, ok
 }
+
+// AssociatedTypeName returns the name an associated type is known by in
+// types: Container.Elem for type Elem in trait Container. Associated types
+// are scoped to their trait, so two traits may both declare an Elem.
+func AssociatedTypeName(traitName, typeName string) string {
+	return traitName + "." + typeName
+}
+
+// RegisterAssociatedType records that typeName is declared inside traitName,
+// as Elem in trait Container<c> { type Elem }.
+func (s *SymbolTable) RegisterAssociatedType(typeName, traitName string) {
+	s.associatedTypes[AssociatedTypeName(traitName, typeName)] = traitName
+}
+
+// GetAssociatedTypeTrait returns the trait that declares the associated type
+// with the qualified name (Container.Elem).
+func (s *SymbolTable) GetAssociatedTypeTrait(name string) (string, bool) {
+	traitName, ok := s.associatedTypes[name]
+	if !ok && s.outer != nil {
+		return s.outer.GetAssociatedTypeTrait(name)
+	}
+	return traitName, ok
+}
+
+// GetTraitAssociatedTypes returns the names of the associated types of traitName, sorted.
+func (s *SymbolTable) GetTraitAssociatedTypes(traitName string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for table := s; table != nil; table = table.outer {
+		for name, trait := range table.associatedTypes {
+			typeName := strings.TrimPrefix(name, trait+".")
+			if trait == traitName && !seen[typeName] {
+				seen[typeName] = true
+				names = append(names, typeName)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetAssociatedTypeTraits returns the traits in scope that declare an
+// associated type called typeName, sorted.
+func (s *SymbolTable) GetAssociatedTypeTraits(typeName string) []string {
+	var traits []string
+	seen := make(map[string]bool)
+	for table := s; table != nil; table = table.outer {
+		for name, trait := range table.associatedTypes {
+			if name == AssociatedTypeName(trait, typeName) && !seen[trait] {
+				seen[trait] = true
+				traits = append(traits, trait)
+			}
+		}
+	}
+	sort.Strings(traits)
+	return traits
+}
+
+// hasAssociatedTypes reports whether any trait in scope declares an associated type.
+func (s *SymbolTable) hasAssociatedTypes() bool {
+	for table := s; table != nil; table = table.outer {
+		if len(table.associatedTypes) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,9 @@
 package typesystem
 
-import "fmt"
+import (
+	"fmt"
+	"sync/atomic"
+)
 
 // KindSubst maps KVar names to Kinds
 type KindSubst map[string]Kind
@@ -70,6 +73,41 @@ func unifyKinds(s KindSubst, k1, k2 Kind) error {
 	return fmt.Errorf("kind mismatch: expected %s, got %s", k1, k2)
 }
 
+// KindsCompatible reports whether two kinds unify. Kind variables match any
+// kind, so a kind-polymorphic parameter accepts * as well as * -> *.
+func KindsCompatible(k1, k2 Kind) bool {
+	if k1.Equal(k2) {
+		return true
+	}
+	_, err := UnifyKinds(k1, k2)
+	return err == nil
+}
+
+var kindVarCounter int64
+
+// InstantiateKind renames the kind variables of a kind-polymorphic kind to
+// fresh ones, so that each use of a type such as Proxy: k -> * picks its own k.
+func InstantiateKind(k Kind) Kind {
+	fresh := make(KindSubst)
+	var walk func(Kind)
+	walk = func(k Kind) {
+		switch k := k.(type) {
+		case KVar:
+			if _, ok := fresh[k.Name]; !ok {
+				fresh[k.Name] = KVar{Name: fmt.Sprintf("k%d", atomic.AddInt64(&kindVarCounter, 1))}
+			}
+		case KArrow:
+			walk(k.Left)
+			walk(k.Right)
+		}
+	}
+	walk(k)
+	if len(fresh) == 0 {
+		return k
+	}
+	return ApplyKindSubst(fresh, k)
+}
+
 func bindKind(s KindSubst, name string, k Kind) error {
 	if v, ok := k.(KVar); ok && v.Name == name {
 		return nil
@@ -98,17 +136,47 @@ func kindOccurs(name string, k Kind) bool {
 type KindContext struct {
 	KindVars map[string]Kind
 	Counter  int
+	local    map[string]bool // kind variables introduced by this context
 }
 
 func NewKindContext() *KindContext {
 	return &KindContext{
 		KindVars: make(map[string]Kind),
+		local:    make(map[string]bool),
 	}
 }
 
+// FreshKVar returns a kind variable that is unique across contexts, since
+// kinds left polymorphic by one declaration are stored and reused by others.
 func (kc *KindContext) FreshKVar() KVar {
 	kc.Counter++
-	return KVar{Name: fmt.Sprintf("k%d", kc.Counter)}
+	kv := KVar{Name: fmt.Sprintf("k%d", atomic.AddInt64(&kindVarCounter, 1))}
+	kc.local[kv.Name] = true
+	return kv
+}
+
+// MarkLocal records the kind variables of k as belonging to this context,
+// e.g. those of an explicit kind annotation on a type parameter.
+func (kc *KindContext) MarkLocal(k Kind) {
+	switch k := k.(type) {
+	case KVar:
+		kc.local[k.Name] = true
+	case KArrow:
+		kc.MarkLocal(k.Left)
+		kc.MarkLocal(k.Right)
+	}
+}
+
+// hasLocalVars reports whether k mentions kind variables of this context,
+// as the kind of a type does inside its own declaration.
+func (kc *KindContext) hasLocalVars(k Kind) bool {
+	switch k := k.(type) {
+	case KVar:
+		return kc.local[k.Name]
+	case KArrow:
+		return kc.hasLocalVars(k.Left) || kc.hasLocalVars(k.Right)
+	}
+	return false
 }
 
 // InferKind infers the kind of a type and returns it along with a substitution.
@@ -122,6 +190,11 @@ func InferKind(t Type, ctx *KindContext) (Kind, KindSubst, error) {
 
 	switch typ := t.(type) {
 	case TCon:
+		// Each use of a kind-polymorphic type gets its own kind variables;
+		// a type referring to itself in its declaration does not.
+		if k := typ.Kind(); !ctx.hasLocalVars(k) {
+			return InstantiateKind(k), subst, nil
+		}
 		return typ.Kind(), subst, nil
 
 	case TVar:
@@ -217,6 +290,27 @@ func InferKind(t Type, ctx *KindContext) (Kind, KindSubst, error) {
 		}
 		return Star, subst, nil
 
+	case TLambda:
+		// \_0 .. _n. Body has kind k0 -> .. -> kn -> kind(Body)
+		kinds := make([]Kind, 0, len(typ.Params)+1)
+		for _, p := range typ.Params {
+			var k Kind = ctx.FreshKVar()
+			if p.KindVal != nil {
+				k = p.KindVal
+			}
+			ctx.KindVars[p.Name] = k
+			kinds = append(kinds, k)
+		}
+		kBody, s, err := InferKind(typ.Body, ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		subst = mergeKindSubst(subst, s)
+		for i := range kinds {
+			kinds[i] = ApplyKindSubst(subst, kinds[i])
+		}
+		return MakeArrow(append(kinds, ApplyKindSubst(subst, kBody))...), subst, nil
+
 	case TType:
 		// TType wraps a type representation. Its kind is * (it's a value).
 		// We verify the inner type is well-kinded.
@@ -155,3 +155,55 @@ func TestKindUnification(t *testing.T) {
 		})
 	}
 }
+
+func TestKindPolymorphism(t *testing.T) {
+	// Proxy: k -> *
+	proxyKind := MakeArrow(KVar{Name: "k"}, Star)
+
+	// Each use instantiates k separately
+	k1 := InstantiateKind(proxyKind).(KArrow)
+	k2 := InstantiateKind(proxyKind).(KArrow)
+	if k1.Left.Equal(k2.Left) {
+		t.Errorf("instantiations share kind variable %s", k1.Left)
+	}
+	if !KindsCompatible(k1.Left, Star) || !KindsCompatible(k2.Left, MakeArrow(Star, Star)) {
+		t.Errorf("kind variable should accept * and * -> *")
+	}
+
+	// A kind without variables is returned unchanged
+	if InstantiateKind(MakeArrow(Star, Star)).String() != "(* -> *)" {
+		t.Errorf("InstantiateKind changed a monomorphic kind")
+	}
+
+	if KindsCompatible(Star, MakeArrow(Star, Star)) {
+		t.Errorf("* should not be compatible with * -> *")
+	}
+
+	// Proxy<List> is well-kinded
+	proxy := TCon{Name: "Proxy", KindVal: proxyKind}
+	listCon := TCon{Name: "List", KindVal: MakeArrow(Star, Star)}
+	k, err := KindCheck(TApp{Constructor: proxy, Args: []Type{listCon}})
+	if err != nil {
+		t.Fatalf("Proxy<List>: %v", err)
+	}
+	if !k.Equal(Star) {
+		t.Errorf("Proxy<List> has kind %s, want *", k)
+	}
+}
+
+func TestTypeLambdaKind(t *testing.T) {
+	pairCon := TCon{Name: "Pair", KindVal: MakeArrow(Star, Star, Star)}
+	hole := TVar{Name: HoleName(0), KindVal: Star}
+	lam := TLambda{Params: []TVar{hole}, Body: TApp{Constructor: pairCon, Args: []Type{hole, TCon{Name: "String"}}}}
+
+	if !lam.Kind().Equal(MakeArrow(Star, Star)) {
+		t.Errorf("Pair<_, String> has kind %s, want (* -> *)", lam.Kind())
+	}
+	k, err := KindCheck(lam)
+	if err != nil {
+		t.Fatalf("KindCheck: %v", err)
+	}
+	if !k.Equal(MakeArrow(Star, Star)) {
+		t.Errorf("KindCheck(Pair<_, String>) = %s, want (* -> *)", k)
+	}
+}
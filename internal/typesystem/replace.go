@@ -47,7 +47,11 @@ func ReplaceTCon(t Type, name string, replacement Type) Type {
 		for k, v := range typ.Fields {
 			newFields[k] = ReplaceTCon(v, name, replacement)
 		}
-		return TRecord{Fields: newFields}
+		var newRow Type
+		if typ.Row != nil {
+			newRow = ReplaceTCon(typ.Row, name, replacement)
+		}
+		return TRecord{Fields: newFields, IsOpen: typ.IsOpen, Row: newRow}
 	case TForall:
 		newType := ReplaceTCon(typ.Type, name, replacement)
 		return TForall{
@@ -59,3 +63,78 @@ func ReplaceTCon(t Type, name string, replacement Type) Type {
 		return t
 	}
 }
+
+// maxReplaceDepth bounds how often ReplaceTApp rewrites a replacement, so
+// that a definition which mentions itself cannot loop forever.
+const maxReplaceDepth = 100
+
+// ReplaceTApp rewrites every type application in t for which f returns a
+// replacement, innermost first. A replacement is rewritten in turn, so a
+// chain such as Elem<Keys<m>> is followed to the end.
+func ReplaceTApp(t Type, f func(TApp) (Type, bool)) Type {
+	return replaceTApp(t, f, 0)
+}
+
+func replaceTApp(t Type, f func(TApp) (Type, bool), depth int) Type {
+	if t == nil || depth > maxReplaceDepth {
+		return t
+	}
+	switch typ := t.(type) {
+	case TApp:
+		newArgs := make([]Type, len(typ.Args))
+		for i, arg := range typ.Args {
+			newArgs[i] = replaceTApp(arg, f, depth)
+		}
+		app := TApp{
+			Constructor: replaceTApp(typ.Constructor, f, depth),
+			Args:        newArgs,
+			KindVal:     typ.KindVal,
+		}
+		if replacement, ok := f(app); ok {
+			return replaceTApp(replacement, f, depth+1)
+		}
+		return app
+	case TFunc:
+		newParams := make([]Type, len(typ.Params))
+		for i, p := range typ.Params {
+			newParams[i] = replaceTApp(p, f, depth)
+		}
+		return TFunc{
+			Params:       newParams,
+			ReturnType:   replaceTApp(typ.ReturnType, f, depth),
+			IsVariadic:   typ.IsVariadic,
+			DefaultCount: typ.DefaultCount,
+			Constraints:  typ.Constraints,
+		}
+	case TTuple:
+		newElements := make([]Type, len(typ.Elements))
+		for i, e := range typ.Elements {
+			newElements[i] = replaceTApp(e, f, depth)
+		}
+		return TTuple{Elements: newElements}
+	case TRecord:
+		newFields := make(map[string]Type)
+		for k, v := range typ.Fields {
+			newFields[k] = replaceTApp(v, f, depth)
+		}
+		var newRow Type
+		if typ.Row != nil {
+			newRow = replaceTApp(typ.Row, f, depth)
+		}
+		return TRecord{Fields: newFields, IsOpen: typ.IsOpen, Row: newRow}
+	case TUnion:
+		newTypes := make([]Type, len(typ.Types))
+		for i, u := range typ.Types {
+			newTypes[i] = replaceTApp(u, f, depth)
+		}
+		return TUnion{Types: newTypes}
+	case TForall:
+		return TForall{
+			Vars:        typ.Vars,
+			Constraints: typ.Constraints,
+			Type:        replaceTApp(typ.Type, f, depth),
+		}
+	default:
+		return t
+	}
+}
@@ -74,9 +74,12 @@ func ApplyWithCycleCheck(t Type, s Subst, visited map[string]bool) Type {
 		}
 
 		if replacement, ok := s[typ.Name]; ok {
-			// Check for direct self-reference
+			// Check for direct self-reference; it may still refine the kind
 			if tv, ok := replacement.(TVar); ok && tv.Name == typ.Name {
-				return typ
+				if tv.KindVal == nil {
+					return typ
+				}
+				return tv
 			}
 			// Mark as visited and recursively apply
 			newVisited := copyVisited(visited)
@@ -92,6 +95,11 @@ func ApplyWithCycleCheck(t Type, s Subst, visited map[string]bool) Type {
 		}
 		newCtor := ApplyWithCycleCheck(typ.Constructor, s, visited)
 
+		// Beta-reduce type-level functions: (\_0. Pair<_0, String>)<Int> is Pair<Int, String>
+		if lam, ok := newCtor.(TLambda); ok {
+			return lam.Instantiate(newArgs)
+		}
+
 		// Flatten nested TApp: if constructor is TApp, merge args
 		// e.g., (Result<String>)<B> becomes Result<String, B>
 		if ctorApp, ok := newCtor.(TApp); ok {
@@ -175,6 +183,17 @@ func ApplyWithCycleCheck(t Type, s Subst, visited map[string]bool) Type {
 		if typ.Row != nil {
 			newRow = ApplyWithCycleCheck(typ.Row, s, visited)
 		}
+		// A row bound to a record contributes its fields: { x, ...r } with
+		// r = { y, ...r2 } is { x, y, ...r2 }. Fields of the outer record win,
+		// as they do when a spread base is overridden.
+		if rowRec, ok := newRow.(TRecord); ok {
+			for k, v := range rowRec.Fields {
+				if _, exists := newFields[k]; !exists {
+					newFields[k] = v
+				}
+			}
+			return TRecord{Fields: newFields, Row: rowRec.Row, IsOpen: rowRec.IsOpen || rowRec.Row != nil}
+		}
 		return TRecord{Fields: newFields, Row: newRow, IsOpen: typ.IsOpen}
 
 	case TUnion:
@@ -222,6 +241,17 @@ func ApplyWithCycleCheck(t Type, s Subst, visited map[string]bool) Type {
 	case TType:
 		return TType{Type: ApplyWithCycleCheck(typ.Type, s, visited)}
 
+	case TLambda:
+		// Parameters are bound by the lambda and are not substituted
+		inner := make(Subst, len(s))
+		for k, v := range s {
+			inner[k] = v
+		}
+		for _, p := range typ.Params {
+			delete(inner, p.Name)
+		}
+		return TLambda{Params: typ.Params, Body: ApplyWithCycleCheck(typ.Body, inner, visited)}
+
 	default:
 		// Fallback for any other types
 		return t.Apply(s)
@@ -431,6 +461,10 @@ func (t TApp) String() string {
 	if len(args) == 0 {
 		return t.Constructor.String()
 	}
+	// Associated types (named Trait.Name) print as written: Container.Elem<c>
+	if tCon, ok := t.Constructor.(TCon); ok && tCon.Module == "" && strings.Contains(tCon.Name, ".") {
+		return fmt.Sprintf("%s<%s>", tCon.Name, strings.Join(args, ", "))
+	}
 	return fmt.Sprintf("(%s %s)", t.Constructor.String(), strings.Join(args, " "))
 }
 
@@ -447,6 +481,76 @@ func (t TApp) FreeTypeVariables() []TVar {
 	return uniqueTVars(vars)
 }
 
+// TLambda is a type-level function: a type constructor written by leaving
+// holes in an application, e.g. Pair<_, String> is \_0. Pair<_0, String>.
+// Applying it to arguments substitutes them for Params in Body.
+type TLambda struct {
+	Params []TVar
+	Body   Type
+}
+
+func (t TLambda) Kind() Kind {
+	kinds := make([]Kind, 0, len(t.Params)+1)
+	for _, p := range t.Params {
+		kinds = append(kinds, p.Kind())
+	}
+	return MakeArrow(append(kinds, t.Body.Kind())...)
+}
+
+func (t TLambda) String() string {
+	// Print the holes back as '_': (Pair _ String)
+	holes := make(Subst, len(t.Params))
+	for _, p := range t.Params {
+		holes[p.Name] = TCon{Name: "_"}
+	}
+	return t.Body.Apply(holes).String()
+}
+
+func (t TLambda) Apply(s Subst) Type {
+	return ApplyWithCycleCheck(t, s, make(map[string]bool))
+}
+
+func (t TLambda) FreeTypeVariables() []TVar {
+	bound := make(map[string]bool, len(t.Params))
+	for _, p := range t.Params {
+		bound[p.Name] = true
+	}
+	var free []TVar
+	for _, v := range t.Body.FreeTypeVariables() {
+		if !bound[v.Name] {
+			free = append(free, v)
+		}
+	}
+	return uniqueTVars(free)
+}
+
+// Instantiate substitutes args for the lambda's parameters. Extra arguments
+// are applied to the result; missing ones leave a smaller lambda.
+func (t TLambda) Instantiate(args []Type) Type {
+	n := len(t.Params)
+	if len(args) < n {
+		n = len(args)
+	}
+	s := make(Subst, n)
+	for i := 0; i < n; i++ {
+		s[t.Params[i].Name] = args[i]
+	}
+	body := ApplyWithCycleCheck(t.Body, s, make(map[string]bool))
+	if n < len(t.Params) {
+		return TLambda{Params: t.Params[n:], Body: body}
+	}
+	if n < len(args) {
+		return ApplyWithCycleCheck(TApp{Constructor: body, Args: args[n:]}, Subst{}, make(map[string]bool))
+	}
+	return body
+}
+
+// HoleName is the name of the i-th parameter of a TLambda built from holes.
+// '_' cannot start a type variable, so hole names never capture user variables.
+func HoleName(i int) string {
+	return "_" + strconv.Itoa(i)
+}
+
 // TTuple represents a tuple type (e.g. (Int, Bool)).
 type TTuple struct {
 	Elements []Type
@@ -498,17 +602,14 @@ func (t TRecord) String() string {
 
 	suffix := ""
 	if t.Row != nil {
-		suffix = " | " + t.Row.String()
+		if len(fields) == 0 {
+			return fmt.Sprintf("{ ...%s }", t.Row.String())
+		}
+		suffix = ", ..." + t.Row.String()
 	} else if t.IsOpen {
 		suffix = ", ..."
 	}
 
-	if len(fields) == 0 && suffix != "" && strings.HasPrefix(suffix, " | ") {
-		// Just row: { | r } -> { r }? No, usually { | r } or { r } syntax?
-		// Funxy doesn't have syntax yet, so format as { | r }
-		return fmt.Sprintf("{ %s }", suffix[3:])
-	}
-
 	return fmt.Sprintf("{ %s%s }", strings.Join(fields, ", "), suffix)
 }
 
@@ -147,3 +147,131 @@ func TestUnifyAllowExtra(t *testing.T) {
 		})
 	}
 }
+
+func TestTypeLambda(t *testing.T) {
+	intType := TCon{Name: "Int"}
+	stringType := TCon{Name: "String"}
+	pairCon := TCon{Name: "Pair", KindVal: MakeArrow(Star, Star, Star)}
+	pair := func(l, r Type) TApp { return TApp{Constructor: pairCon, Args: []Type{l, r}} }
+
+	hole := TVar{Name: HoleName(0), KindVal: Star}
+	pairLeft := TLambda{Params: []TVar{hole}, Body: pair(hole, stringType)}
+
+	if got := pairLeft.String(); got != "(Pair _ String)" {
+		t.Errorf("String() = %s, want (Pair _ String)", got)
+	}
+
+	// f<Int> with f = Pair<_, String> reduces to Pair<Int, String>
+	f := TVar{Name: "f", KindVal: MakeArrow(Star, Star)}
+	applied := TApp{Constructor: f, Args: []Type{intType}}.Apply(Subst{"f": pairLeft})
+	if !reflect.DeepEqual(applied, pair(intType, stringType)) {
+		t.Errorf("beta reduction = %s, want (Pair Int String)", applied)
+	}
+
+	// The parameter is bound: substitutions do not reach it
+	if got := pairLeft.Apply(Subst{HoleName(0): intType}); !reflect.DeepEqual(got, pairLeft) {
+		t.Errorf("substitution replaced a bound parameter: %s", got)
+	}
+	if len(pairLeft.FreeTypeVariables()) != 0 {
+		t.Errorf("FreeTypeVariables() = %v, want none", pairLeft.FreeTypeVariables())
+	}
+}
+
+func TestUnifyHigherKindedPositions(t *testing.T) {
+	intType := TCon{Name: "Int"}
+	stringType := TCon{Name: "String"}
+	pairCon := TCon{Name: "Pair", KindVal: MakeArrow(Star, Star, Star)}
+	pair := func(l, r Type) TApp { return TApp{Constructor: pairCon, Args: []Type{l, r}} }
+	f := TVar{Name: "f", KindVal: MakeArrow(Star, Star)}
+	fOf := func(arg Type) TApp { return TApp{Constructor: f, Args: []Type{arg}} }
+
+	// The last position is preferred: f = Pair<Int>
+	s, err := Unify(fOf(intType), pair(intType, intType))
+	if err != nil {
+		t.Fatalf("f<Int> ~ Pair<Int, Int>: %v", err)
+	}
+	if want := (TApp{Constructor: pairCon, Args: []Type{intType}}); !reflect.DeepEqual(s["f"], want) {
+		t.Errorf("f = %s, want %s", s["f"], want)
+	}
+
+	// When the last position does not fit, f abstracts over the first one
+	s, err = Unify(fOf(intType), pair(intType, stringType))
+	if err != nil {
+		t.Fatalf("f<Int> ~ Pair<Int, String>: %v", err)
+	}
+	if got := fOf(TCon{Name: "Bool"}).Apply(s); !reflect.DeepEqual(got, pair(TCon{Name: "Bool"}, stringType)) {
+		t.Errorf("f<Bool> = %s, want (Pair Bool String)", got)
+	}
+
+	// Same on the right-hand side
+	if _, err := Unify(pair(intType, stringType), fOf(intType)); err != nil {
+		t.Errorf("Pair<Int, String> ~ f<Int>: %v", err)
+	}
+
+	// No position fits
+	if _, err := Unify(fOf(TCon{Name: "Bool"}), pair(intType, stringType)); err == nil {
+		t.Errorf("f<Bool> ~ Pair<Int, String> should fail")
+	}
+
+	// Type-level functions unify up to renaming of their parameters
+	h0 := TVar{Name: HoleName(0), KindVal: Star}
+	other := TVar{Name: "x", KindVal: Star}
+	lam1 := TLambda{Params: []TVar{h0}, Body: pair(h0, stringType)}
+	lam2 := TLambda{Params: []TVar{other}, Body: pair(other, stringType)}
+	if _, err := Unify(lam1, lam2); err != nil {
+		t.Errorf("alpha-equivalent lambdas: %v", err)
+	}
+	lam3 := TLambda{Params: []TVar{h0}, Body: pair(stringType, h0)}
+	if _, err := Unify(lam1, lam3); err == nil {
+		t.Errorf("Pair<_, String> ~ Pair<String, _> should fail")
+	}
+}
+
+// headResolver offers fixed instance heads for every type variable
+type headResolver struct{ heads []Type }
+
+func (r headResolver) ResolveTypeAlias(t Type) Type         { return t }
+func (r headResolver) ResolveTCon(name string) (TCon, bool) { return TCon{}, false }
+func (r headResolver) IsStrictMode() bool                   { return false }
+func (r headResolver) InstanceHeads(v TVar) []Type          { return r.heads }
+
+func TestUnifyInstanceHeads(t *testing.T) {
+	intType := TCon{Name: "Int"}
+	stringType := TCon{Name: "String"}
+	pairCon := TCon{Name: "Pair", KindVal: MakeArrow(Star, Star, Star)}
+	pair := func(l, r Type) TApp { return TApp{Constructor: pairCon, Args: []Type{l, r}} }
+	f := TVar{Name: "f", KindVal: MakeArrow(Star, Star)}
+	fOf := func(arg Type) TApp { return TApp{Constructor: f, Args: []Type{arg}} }
+	h0 := TVar{Name: HoleName(0), KindVal: Star}
+	pairLeft := func(r Type) TLambda { return TLambda{Params: []TVar{h0}, Body: pair(h0, r)} }
+
+	// The declared head wins over the last position
+	resolver := headResolver{heads: []Type{pairLeft(intType)}}
+	s, err := UnifyWithResolver(fOf(intType), pair(intType, intType), resolver)
+	if err != nil {
+		t.Fatalf("f<Int> ~ Pair<Int, Int>: %v", err)
+	}
+	if !reflect.DeepEqual(s["f"], pairLeft(intType)) {
+		t.Errorf("f = %s, want (Pair _ Int)", s["f"])
+	}
+
+	// Only the head whose fixed argument fits matches
+	resolver = headResolver{heads: []Type{pairLeft(intType), pairLeft(stringType)}}
+	s, err = UnifyWithResolver(pair(intType, stringType), fOf(intType), resolver)
+	if err != nil {
+		t.Fatalf("Pair<Int, String> ~ f<Int>: %v", err)
+	}
+	if !reflect.DeepEqual(s["f"], pairLeft(stringType)) {
+		t.Errorf("f = %s, want (Pair _ String)", s["f"])
+	}
+
+	// Without a matching head, the positions are searched as before
+	resolver = headResolver{heads: []Type{pairLeft(stringType)}}
+	s, err = UnifyWithResolver(fOf(intType), pair(intType, intType), resolver)
+	if err != nil {
+		t.Fatalf("f<Int> ~ Pair<Int, Int>: %v", err)
+	}
+	if want := (TApp{Constructor: pairCon, Args: []Type{intType}}); !reflect.DeepEqual(s["f"], want) {
+		t.Errorf("f = %s, want %s", s["f"], want)
+	}
+}
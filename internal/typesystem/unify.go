@@ -21,6 +21,38 @@ type VariableGenerator interface {
 	FreshTVar() TVar
 }
 
+// AssociatedTypeResolver is implemented by resolvers that know the associated
+// types declared in traits (Elem in trait Container<c> { type Elem }).
+// ReduceAssociatedType rewrites an application such as Elem<IntBag> to the
+// type its instance defines. An equation with an application that cannot
+// be reduced yet, because its arguments are still unknown, is handed to
+// DeferAssociatedType, which reports whether it was recorded to be solved
+// later.
+type AssociatedTypeResolver interface {
+	IsAssociatedType(app TApp) bool
+	ReduceAssociatedType(app TApp) (Type, bool)
+	DeferAssociatedType(expected, actual Type) bool
+}
+
+// DeferredEquationLog is implemented by AssociatedTypeResolvers that keep the
+// equations passed to DeferAssociatedType. Unification marks the log before
+// each step and rolls it back when the step fails, so an attempt that is
+// abandoned (a union member that does not match, a higher-kinded guess) leaves
+// no equations behind.
+type DeferredEquationLog interface {
+	DeferredMark() int
+	RollbackDeferred(mark int)
+}
+
+// InstanceHeadResolver is implemented by resolvers that know which trait
+// instances a higher-kinded type variable must have, such as the f of a call
+// to a method of Functor<f>. InstanceHeads returns the heads of those
+// instances (List, Result<e>, Pair<_, Int>) with fresh type variables, and
+// nothing for an unconstrained variable.
+type InstanceHeadResolver interface {
+	InstanceHeads(v TVar) []Type
+}
+
 // Unify attempts to find a substitution that makes t1 and t2 equal.
 // It enforces strict equality (invariant).
 func Unify(t1, t2 Type) (Subst, error) {
@@ -52,11 +84,20 @@ type typePair struct {
 
 const maxUnificationDepth = 500
 
-func unifyInternal(t1, t2 Type, allowExtra bool, visited []typePair, resolver Resolver, depth int) (Subst, error) {
+func unifyInternal(t1, t2 Type, allowExtra bool, visited []typePair, resolver Resolver, depth int) (_ Subst, err error) {
 	if depth > maxUnificationDepth {
 		return nil, fmt.Errorf("unification depth limit exceeded")
 	}
 
+	if log, ok := resolver.(DeferredEquationLog); ok {
+		mark := log.DeferredMark()
+		defer func() {
+			if err != nil {
+				log.RollbackDeferred(mark)
+			}
+		}()
+	}
+
 	// Co-induction step: Check if we are already comparing these two types in the current stack
 	for _, p := range visited {
 		// Use reflect.DeepEqual for robust comparison including TCons
@@ -74,6 +115,26 @@ func unifyInternal(t1, t2 Type, allowExtra bool, visited []typePair, resolver Re
 		return Subst{}, nil
 	}
 
+	// Associated types: Elem<IntBag> stands for the type its instance defines
+	if atr, ok := resolver.(AssociatedTypeResolver); ok {
+		if app, ok := t1.(TApp); ok && atr.IsAssociatedType(app) {
+			if reduced, ok := atr.ReduceAssociatedType(app); ok {
+				return unifyInternal(reduced, t2, allowExtra, visited, resolver, depth+1)
+			}
+			if _, isVar := t2.(TVar); !isVar && atr.DeferAssociatedType(t1, t2) {
+				return Subst{}, nil
+			}
+		}
+		if app, ok := t2.(TApp); ok && atr.IsAssociatedType(app) {
+			if reduced, ok := atr.ReduceAssociatedType(app); ok {
+				return unifyInternal(t1, reduced, allowExtra, visited, resolver, depth+1)
+			}
+			if _, isVar := t1.(TVar); !isVar && atr.DeferAssociatedType(t1, t2) {
+				return Subst{}, nil
+			}
+		}
+	}
+
 	// Unify directionality fix: If t2 is a TCon (alias) and t1 is a structural type (Record, Func, etc.),
 	// we need to unwrap t2 to see if it matches t1.
 	// We skip this if t1 is TCon (handled in switch) or TVar (handled in switch).
@@ -200,6 +261,16 @@ func unifyInternal(t1, t2 Type, allowExtra bool, visited []typePair, resolver Re
 	case TVar:
 		return Bind(t1, t2)
 	case TApp:
+		// Beta-reduce applications of type-level functions first
+		if lam, ok := t1.Constructor.(TLambda); ok {
+			return unifyInternal(lam.Instantiate(t1.Args), t2, allowExtra, visited, resolver, depth+1)
+		}
+		if t2App, ok := t2.(TApp); ok {
+			if lam, ok := t2App.Constructor.(TLambda); ok {
+				return unifyInternal(t1, lam.Instantiate(t2App.Args), allowExtra, visited, resolver, depth+1)
+			}
+		}
+
 		// Try to expand type aliases before unification
 		// e.g., StringResult<Int> -> Result<String, Int>
 		expanded1 := ExpandTypeAlias(t1)
@@ -238,80 +309,13 @@ func unifyInternal(t1, t2 Type, allowExtra bool, visited []typePair, resolver Re
 			// HKT: Handle higher-kinded type unification
 			// Case 1: F<A> (TVar constructor) unified with Result<E, String> (concrete)
 			// We need to bind F to a partially applied type
-			if t1Var, ok := t1.Constructor.(TVar); ok {
-				// t1 = F<A1, A2, ...Am>  (m args)
-				// t2 = C<B1, B2, ...Bn>  (n args)
-				// If m <= n, we can unify by:
-				//   F = C<B1, ..., B(n-m)>  (partial application)
-				//   A1 = B(n-m+1), ..., Am = Bn
-				if len(t1.Args) <= len(t2.Args) {
-					numExtra := len(t2.Args) - len(t1.Args)
-
-					// Build the partial type for F
-					var partialType Type
-					if numExtra == 0 {
-						// Same arity - F binds directly to constructor
-						partialType = t2.Constructor
-					} else {
-						// F binds to partially applied type: C<B1, ..., B(n-m)>
-						partialType = TApp{
-							Constructor: t2.Constructor,
-							Args:        t2.Args[:numExtra],
-						}
-					}
-
-					// Bind F to the partial type
-					s1, err := Bind(t1Var, partialType)
-					if err != nil {
-						return nil, err
-					}
-
-					// Unify remaining arguments: A1..Am with B(n-m+1)..Bn
-					for i := 0; i < len(t1.Args); i++ {
-						arg1 := t1.Args[i].Apply(s1)
-						arg2 := t2.Args[numExtra+i].Apply(s1)
-						s2, err := unifyInternal(arg1, arg2, false, visited, resolver, depth+1)
-						if err != nil {
-							return nil, err
-						}
-						s1 = s1.Compose(s2)
-					}
-					return s1, nil
-				}
+			if t1Var, ok := t1.Constructor.(TVar); ok && len(t1.Args) <= len(t2.Args) {
+				return unifyHigherKinded(t1Var, t1.Args, t2, false, visited, resolver, depth)
 			}
 
 			// Case 2: Concrete<A> unified with F<B> (TVar constructor in t2)
-			if t2Var, ok := t2.Constructor.(TVar); ok {
-				if len(t2.Args) <= len(t1.Args) {
-					numExtra := len(t1.Args) - len(t2.Args)
-
-					var partialType Type
-					if numExtra == 0 {
-						partialType = t1.Constructor
-					} else {
-						partialType = TApp{
-							Constructor: t1.Constructor,
-							Args:        t1.Args[:numExtra],
-						}
-					}
-
-					// Bind F to the partial type
-					s1, err := Bind(t2Var, partialType)
-					if err != nil {
-						return nil, err
-					}
-
-					for i := 0; i < len(t2.Args); i++ {
-						arg1 := t1.Args[numExtra+i].Apply(s1)
-						arg2 := t2.Args[i].Apply(s1)
-						s2, err := unifyInternal(arg1, arg2, false, visited, resolver, depth+1)
-						if err != nil {
-							return nil, err
-						}
-						s1 = s1.Compose(s2)
-					}
-					return s1, nil
-				}
+			if t2Var, ok := t2.Constructor.(TVar); ok && len(t2.Args) <= len(t1.Args) {
+				return unifyHigherKinded(t2Var, t2.Args, t1, true, visited, resolver, depth)
 			}
 
 			// Case 3: Standard unification (same constructor, same arity)
@@ -608,6 +612,18 @@ func unifyInternal(t1, t2 Type, allowExtra bool, visited []typePair, resolver Re
 						return nil, err
 					}
 					s1 = s1.Compose(s2)
+				} else if t1.Row != nil && !t2.IsOpen {
+					// A closed record has no other fields. A row variable is left
+					// open (it may still take fields from another use), but a rigid
+					// row, the type parameter of an annotated function, cannot be empty.
+					row := t1.Row.Apply(s1)
+					if _, isVar := row.(TVar); !isVar {
+						s2, err := unifyInternal(row, TRecord{Fields: map[string]Type{}}, allowExtra, visited, resolver, depth+1)
+						if err != nil {
+							return nil, errUnifyContext("record row", err)
+						}
+						s1 = s1.Compose(s2)
+					}
 				}
 			}
 
@@ -721,6 +737,27 @@ func unifyInternal(t1, t2 Type, allowExtra bool, visited []typePair, resolver Re
 		default:
 			return nil, errUnifyMsg(t1, t2, "cannot unify polytype with monotype")
 		}
+	case TLambda:
+		switch t2 := t2.(type) {
+		case TVar:
+			return Bind(t2, t1)
+		case TLambda:
+			// Alpha-equivalence: replace both parameter lists with the same
+			// rigid placeholders and compare the bodies.
+			if len(t1.Params) != len(t2.Params) {
+				return nil, errMismatch("type function parameter count mismatch")
+			}
+			subst1 := make(Subst, len(t1.Params))
+			subst2 := make(Subst, len(t2.Params))
+			for i := range t1.Params {
+				hole := TCon{Name: "$" + HoleName(i), KindVal: t1.Params[i].Kind()}
+				subst1[t1.Params[i].Name] = hole
+				subst2[t2.Params[i].Name] = hole
+			}
+			return unifyInternal(t1.Body.Apply(subst1), t2.Body.Apply(subst2), false, visited, resolver, depth+1)
+		default:
+			return nil, errUnify(t1, t2)
+		}
 	case TType:
 		switch t2 := t2.(type) {
 		case TVar:
@@ -736,6 +773,147 @@ func unifyInternal(t1, t2 Type, allowExtra bool, visited []typePair, resolver Re
 	}
 }
 
+// unifyHigherKinded unifies F<A1..Am> with C<B1..Bn> (m <= n), where F is a
+// type variable. When F must have trait instances and exactly one instance
+// head matches, F is that head. Otherwise F abstracts over the last m
+// arguments of C when they unify (F = C<B1..B(n-m)>, as in Result<E> for
+// Result<E, _>); otherwise over the first choice of m positions, right to
+// left, whose arguments unify, as a type-level function such as
+// Pair<_, String>. When flipped is set the concrete type is the left-hand
+// side of the original unification.
+func unifyHigherKinded(fVar TVar, fArgs []Type, concrete TApp, flipped bool, visited []typePair, resolver Resolver, depth int) (Subst, error) {
+	if heads, ok := resolver.(InstanceHeadResolver); ok {
+		if s, ok := unifyInstanceHead(fVar, heads.InstanceHeads(fVar), fArgs, concrete, flipped, visited, resolver, depth); ok {
+			return s, nil
+		}
+	}
+
+	m, n := len(fArgs), len(concrete.Args)
+	var firstErr error
+	for _, positions := range argumentPositions(n, m) {
+		var partialType Type
+		if positions[0] == n-m {
+			// Trailing arguments: a plain partial application
+			if n == m {
+				partialType = concrete.Constructor
+			} else {
+				partialType = TApp{Constructor: concrete.Constructor, Args: concrete.Args[:n-m]}
+			}
+		} else {
+			partialType = abstractArguments(concrete, positions)
+		}
+
+		s, err := Bind(fVar, partialType)
+		if err == nil {
+			for i, pos := range positions {
+				arg1 := fArgs[i].Apply(s)
+				arg2 := concrete.Args[pos].Apply(s)
+				if flipped {
+					arg1, arg2 = arg2, arg1
+				}
+				var s2 Subst
+				s2, err = unifyInternal(arg1, arg2, false, visited, resolver, depth+1)
+				if err != nil {
+					break
+				}
+				s = s.Compose(s2)
+			}
+		}
+		if err == nil {
+			return s, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// unifyInstanceHead binds F to the one instance head H for which H<A1..Am>
+// unifies with the concrete type. Pair<1, 2> is matched by Pair<_, Int> but
+// not by Pair<_, String>, whatever position a guess would abstract. It
+// reports false when no head or more than one head matches.
+func unifyInstanceHead(fVar TVar, heads []Type, fArgs []Type, concrete TApp, flipped bool, visited []typePair, resolver Resolver, depth int) (Subst, bool) {
+	log, hasLog := resolver.(DeferredEquationLog)
+	mark := 0
+	if hasLog {
+		mark = log.DeferredMark()
+	}
+
+	var match Subst
+	matches := 0
+	for _, head := range heads {
+		s, err := Bind(fVar, head)
+		if err != nil {
+			continue
+		}
+		applied := TApp{Constructor: head, Args: fArgs}.Apply(s)
+		var other Type = concrete.Apply(s)
+		if flipped {
+			applied, other = other, applied
+		}
+		s2, err := unifyInternal(applied, other, false, visited, resolver, depth+1)
+		if err != nil {
+			continue
+		}
+		match = s.Compose(s2)
+		matches++
+	}
+
+	if matches != 1 {
+		if hasLog {
+			log.RollbackDeferred(mark)
+		}
+		return nil, false
+	}
+	return match, true
+}
+
+// argumentPositions lists the ways to pick m of n argument positions, in
+// increasing order within each choice, starting with the last m positions and
+// moving leftwards.
+func argumentPositions(n, m int) [][]int {
+	var result [][]int
+	var pick func(end, remaining int, acc []int)
+	pick = func(end, remaining int, acc []int) {
+		if remaining == 0 {
+			choice := make([]int, len(acc))
+			for i, p := range acc {
+				choice[len(acc)-1-i] = p
+			}
+			result = append(result, choice)
+			return
+		}
+		for p := end - 1; p >= remaining-1; p-- {
+			pick(p, remaining-1, append(acc, p))
+		}
+	}
+	pick(n, m, nil)
+	return result
+}
+
+// abstractArguments builds the type-level function that replaces the
+// arguments of app at the given positions with holes.
+func abstractArguments(app TApp, positions []int) TLambda {
+	args := append([]Type{}, app.Args...)
+	params := make([]TVar, len(positions))
+	ctorKind := app.Constructor.Kind()
+	argKinds := make([]Kind, len(app.Args))
+	for i := range argKinds {
+		if arrow, ok := ctorKind.(KArrow); ok {
+			argKinds[i] = arrow.Left
+			ctorKind = arrow.Right
+		} else {
+			argKinds[i] = Star
+		}
+	}
+	for i, pos := range positions {
+		params[i] = TVar{Name: HoleName(i), KindVal: argKinds[pos]}
+		args[pos] = params[i]
+	}
+	return TLambda{Params: params, Body: TApp{Constructor: app.Constructor, Args: args, KindVal: app.KindVal}}
+}
+
 // Bind binds a type variable to a type, performing the occurs check.
 func Bind(tv TVar, t Type) (Subst, error) {
 	// If t is the same variable, return empty substitution
@@ -745,7 +923,7 @@ func Bind(tv TVar, t Type) (Subst, error) {
 
 	// Kind Check: ensure tv and t have the same Kind
 	// This is crucial for Higher-Order Unification to avoid binding * -> * variable to * type
-	if !tv.Kind().Equal(t.Kind()) {
+	if !KindsCompatible(tv.Kind(), t.Kind()) {
 		return nil, errMismatch(fmt.Sprintf("kind mismatch: variable %s has kind %s, but type %s has kind %s",
 			tv.Name, tv.Kind(), t, t.Kind()))
 	}
@@ -842,6 +1020,10 @@ func CheckSkolemEscape(tv TVar, t Type) error {
 			if err := visit(typ.Type); err != nil {
 				return err
 			}
+		case TLambda:
+			if err := visit(typ.Body); err != nil {
+				return err
+			}
 		}
 		return nil
 	}
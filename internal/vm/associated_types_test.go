@@ -0,0 +1,119 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/funvibe/funxy/internal/analyzer"
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/parser"
+	"github.com/funvibe/funxy/internal/pipeline"
+)
+
+const associatedTypesProgram = `
+type IntBag = IntBag(List<Int>)
+
+trait Container<c> {
+    type Elem
+    fun cempty() -> c
+    fun cinsert(x: Elem<c>, coll: c) -> c
+    fun cfirst(coll: c) -> Elem<c>
+}
+
+instance Container IntBag {
+    type Elem = Int
+    fun cempty() -> IntBag { IntBag([]) }
+    fun cinsert(x: Int, coll: IntBag) -> IntBag { match coll { IntBag(xs) -> IntBag([x] ++ xs) } }
+    fun cfirst(coll: IntBag) -> Int { match coll { IntBag(xs) -> xs[0] } }
+}
+
+instance Container List<t> {
+    type Elem = t
+    fun cempty() -> List<t> { [] }
+    fun cinsert(x: t, coll: List<t>) -> List<t> { [x] ++ coll }
+    fun cfirst(coll: List<t>) -> t { coll[0] }
+}
+
+trait Fallback<a> {
+    fun fallback() -> a
+}
+
+instance Fallback Int { fun fallback() -> Int { 40 } }
+instance Fallback String { fun fallback() -> String { "s" } }
+
+fun same<a>(x: a) -> a { x }
+
+fun fromList<c: Container>(xs: List<Elem<c>>) -> c {
+    match xs {
+        [] -> cempty()
+        [x, ...rest] -> cinsert(x, fromList(rest))
+    }
+}
+
+fun firstOf<c: Container>(coll: c) -> Elem<c> { same(cfirst(coll)) }
+
+bag: IntBag = fromList([1, 2])
+strs: List<String> = fromList(["a"])
+n: Elem<IntBag> = fallback()
+s: Elem<List<String>> = fallback()
+firstOf(bag) + n + len(firstOf(strs) ++ s)
+`
+
+// compileAnalyzed compiles input with the analyzer results the backends pass
+// to the compiler.
+func compileAnalyzed(t *testing.T, input string) *Chunk {
+	ctx := pipeline.NewPipelineContext(input)
+	ctx = (&lexer.LexerProcessor{}).Process(ctx)
+	ctx = (&parser.ParserProcessor{}).Process(ctx)
+	ctx = (&analyzer.SemanticAnalyzerProcessor{}).Process(ctx)
+	if len(ctx.Errors) > 0 {
+		t.Fatalf("analyzer error: %s", ctx.Errors[0].Error())
+	}
+
+	compiler := NewCompiler()
+	compiler.SetTypeMap(ctx.TypeMap)
+	compiler.SetSymbolTable(ctx.SymbolTable)
+	compiler.SetResolutionMap(ctx.ResolutionMap)
+	chunk, err := compiler.Compile(ctx.AstRoot.(*ast.Program))
+	if err != nil {
+		t.Fatalf("compilation error: %s", err)
+	}
+	return chunk
+}
+
+func TestAssociatedTypes_DictionaryPassing(t *testing.T) {
+	chunk := compileAnalyzed(t, associatedTypesProgram)
+
+	// Specialized bodies hint the calls returning Elem<c> with the
+	// instance's definition
+	hints := map[string]string{"firstOf$IntBag": "Int", "firstOf$(ListString)": "String"}
+	var visit func(chunk *Chunk)
+	visit = func(chunk *Chunk) {
+		for _, constant := range chunk.Constants {
+			fn, ok := constant.(*CompiledFunction)
+			if !ok {
+				continue
+			}
+			if want, ok := hints[fn.Name]; ok {
+				for _, c := range fn.Chunk.Constants {
+					if s, ok := c.(*stringConstant); ok && s.Value == want {
+						delete(hints, fn.Name)
+					}
+				}
+			}
+			visit(fn.Chunk)
+		}
+	}
+	visit(chunk)
+	for name, want := range hints {
+		t.Errorf("%s does not hint its calls with %s", name, want)
+	}
+
+	machine := New()
+	machine.RegisterBuiltins()
+	result, err := machine.Run(chunk)
+	if err != nil {
+		t.Fatalf("runtime error: %s", err)
+	}
+	testIntegerObject(t, result, 1+40+2)
+}
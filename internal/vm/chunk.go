@@ -57,6 +57,7 @@ func init() {
 	gob.Register(typesystem.TType{})
 	gob.Register(typesystem.Constraint{})
 	gob.Register(typesystem.TForall{})
+	gob.Register(typesystem.TLambda{})
 
 	// Register Kind interface implementations (used in TVar.KindVal, TCon.KindVal, TApp.KindVal)
 	gob.Register(typesystem.KStar{})
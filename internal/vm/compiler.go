@@ -141,6 +141,18 @@ func (c *Compiler) SetSymbolTable(st *symbols.SymbolTable) {
 	c.symbolTable = st
 }
 
+// applySubst applies the substitution of the function being specialized to t
+// and reduces the associated types this makes concrete, as Elem<IntBag> to Int
+func (c *Compiler) applySubst(t typesystem.Type) typesystem.Type {
+	if c.subst != nil {
+		t = t.Apply(c.subst)
+	}
+	if c.symbolTable != nil {
+		t = c.symbolTable.ReduceAssociatedTypes(t)
+	}
+	return t
+}
+
 // SetResolutionMap sets the resolution map from analyzer
 func (c *Compiler) SetResolutionMap(resMap map[ast.Node]symbols.Symbol) {
 	c.resolutionMap = resMap
@@ -170,6 +182,23 @@ func extractTypeConstructorName(t typesystem.Type) string {
 	}
 }
 
+// DeclareGlobals marks names as globals defined by code compiled earlier for
+// the same VM, so that assignments to them update the global.
+func (c *Compiler) DeclareGlobals(names []string) {
+	for _, name := range names {
+		c.globals[name] = true
+	}
+}
+
+// GetGlobals returns the names of the globals known to the script.
+func (c *Compiler) GetGlobals() []string {
+	names := make([]string, 0, len(c.globals))
+	for name := range c.globals {
+		names = append(names, name)
+	}
+	return names
+}
+
 // SetBaseDir sets the base directory for resolving imports
 func (c *Compiler) SetBaseDir(dir string) {
 	c.baseDir = dir
@@ -202,6 +231,9 @@ func (c *Compiler) astTypeToTypesystemType(t ast.Type) typesystem.Type {
 		for name, fieldType := range node.Fields {
 			fields[name] = c.astTypeToTypesystemType(fieldType)
 		}
+		if node.Row != nil {
+			return typesystem.TRecord{Fields: fields, Row: c.astTypeToTypesystemType(node.Row), IsOpen: true}
+		}
 		return typesystem.TRecord{Fields: fields}
 	case *ast.FunctionType:
 		var params []typesystem.Type
@@ -756,26 +788,11 @@ func (c *Compiler) bindPattern(pat ast.Pattern, line int) error {
 		recordSlot := c.slotCount - 1
 		slotsBeforeBindings := c.slotCount
 
-		// Sort keys for deterministic compilation
-		keys := make([]string, 0, len(p.Fields))
-		for k := range p.Fields {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-
-		for _, fieldName := range keys {
-			fieldPattern := p.Fields[fieldName]
+		for _, part := range recordPatternParts(p) {
+			fieldPattern := part.pattern
 
-			// Get record from its slot
-			c.emit(OP_GET_LOCAL, line)
-			c.currentChunk().Write(byte(recordSlot), line)
-			c.slotCount++
-
-			// Get field
-			nameIdx := c.currentChunk().AddConstant(&stringConstant{Value: fieldName})
-			c.emit(OP_GET_FIELD, line)
-			c.currentChunk().Write(byte(nameIdx>>8), line)
-			c.currentChunk().Write(byte(nameIdx), line)
+			// Get field (or rest) from the record in its slot
+			c.emitRecordPatternPart(p, part, recordSlot, line)
 
 			// Now we have element on stack, bind it
 			if err := c.bindPatternElement(fieldPattern, line); err != nil {
@@ -908,23 +925,10 @@ func (c *Compiler) bindPatternElement(pat ast.Pattern, line int) error {
 		recordSlot := c.slotCount - 1
 		bindingsStart := c.slotCount
 
-		keys := make([]string, 0, len(p.Fields))
-		for k := range p.Fields {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
+		for _, part := range recordPatternParts(p) {
+			fieldPattern := part.pattern
 
-		for _, fieldName := range keys {
-			fieldPattern := p.Fields[fieldName]
-
-			c.emit(OP_GET_LOCAL, line)
-			c.currentChunk().Write(byte(recordSlot), line)
-			c.slotCount++
-
-			nameIdx := c.currentChunk().AddConstant(&stringConstant{Value: fieldName})
-			c.emit(OP_GET_FIELD, line)
-			c.currentChunk().Write(byte(nameIdx>>8), line)
-			c.currentChunk().Write(byte(nameIdx), line)
+			c.emitRecordPatternPart(p, part, recordSlot, line)
 
 			if err := c.bindPatternElement(fieldPattern, line); err != nil {
 				return err
@@ -1447,6 +1451,11 @@ func (c *Compiler) specialize(name string, instantiation map[string]typesystem.T
 
 func mangleTypeName(t typesystem.Type) string {
 	s := t.String()
+	if lam, ok := t.(typesystem.TLambda); ok {
+		// Keep the hole's position: Pair<_, String> and Pair<String, _> are
+		// different instance heads and need different specializations.
+		s = analyzer.GetInstanceHeadName(lam)
+	}
 	s = strings.ReplaceAll(s, " ", "")
 	s = strings.ReplaceAll(s, "<", "$")
 	s = strings.ReplaceAll(s, ">", "$")
@@ -6,7 +6,6 @@ import (
 	"github.com/funvibe/funxy/internal/ast"
 	"github.com/funvibe/funxy/internal/evaluator"
 	"github.com/funvibe/funxy/internal/typesystem"
-	"sort"
 	"strings"
 )
 
@@ -267,6 +266,42 @@ func (c *Compiler) compileRangeExpression(expr *ast.RangeExpression) error {
 	return nil
 }
 
+// headDictionary returns the dictionary of the instance that a trait method
+// call resolves to when the instance's head has holes (Pair<_, Int>). Values
+// only carry their constructor, which such heads share, so these instances
+// are reached through their dictionary rather than the argument's type.
+func (c *Compiler) headDictionary(call *ast.CallExpression, ident *ast.Identifier) string {
+	if c.symbolTable == nil || len(call.Instantiation) == 0 {
+		return ""
+	}
+	if c.resolutionMap != nil {
+		if sym, ok := c.resolutionMap[ident]; ok && !sym.IsTraitMethod {
+			return ""
+		}
+	}
+	traitName, ok := c.symbolTable.GetTraitForMethod(ident.Value)
+	if !ok {
+		return ""
+	}
+	params, ok := c.symbolTable.GetTraitTypeParams(traitName)
+	if !ok || len(params) != 1 {
+		return ""
+	}
+	head, ok := call.Instantiation[params[0]]
+	if !ok {
+		return ""
+	}
+	lam, ok := c.applySubst(head).(typesystem.TLambda)
+	if !ok {
+		return ""
+	}
+	impl, _, err := c.symbolTable.FindMatchingImplementation(traitName, []typesystem.Type{lam})
+	if err != nil || len(impl.Requirements) > 0 {
+		return ""
+	}
+	return impl.ConstructorName
+}
+
 // Compile function call
 func (c *Compiler) compileCallExpression(call *ast.CallExpression) error {
 	line := call.Token.Line
@@ -276,9 +311,7 @@ func (c *Compiler) compileCallExpression(call *ast.CallExpression) error {
 	var typeContextName string
 	if c.typeMap != nil {
 		if t, ok := c.typeMap[call]; ok {
-			if c.subst != nil {
-				t = t.Apply(c.subst)
-			}
+			t = c.applySubst(t)
 			typeContextName = evaluator.ExtractTypeConstructorName(t)
 
 			// Special case: List<Char> is String
@@ -390,11 +423,7 @@ func (c *Compiler) compileCallExpression(call *ast.CallExpression) error {
 		// Resolve types in instantiation using current substitution (recursive specialization)
 		finalInstantiation := make(map[string]typesystem.Type)
 		for k, v := range call.Instantiation {
-			if c.subst != nil {
-				finalInstantiation[k] = v.Apply(c.subst)
-			} else {
-				finalInstantiation[k] = v
-			}
+			finalInstantiation[k] = c.applySubst(v)
 		}
 
 		name, err := c.specialize(ident.Value, finalInstantiation)
@@ -425,6 +454,19 @@ func (c *Compiler) compileCallExpression(call *ast.CallExpression) error {
 	// Compile arguments (also not in tail position)
 	argCount := 0
 
+	// A trait method resolved to an instance for a head with holes gets the
+	// instance's dictionary ahead of its arguments
+	if isIdent {
+		if dictName := c.headDictionary(call, ident); dictName != "" {
+			nameIdx := c.currentChunk().AddConstant(&stringConstant{Value: dictName})
+			c.emit(OP_GET_GLOBAL, line)
+			c.currentChunk().Write(byte(nameIdx>>8), line)
+			c.currentChunk().Write(byte(nameIdx), line)
+			c.slotCount++
+			argCount++
+		}
+	}
+
 	// Handle TypeArgs for data constructors (Reified Generics)
 	// If this call has TypeArgs, prepend them as TypeObject arguments
 	if call.TypeArgs != nil {
@@ -535,9 +577,7 @@ func (c *Compiler) compileCallExpression(call *ast.CallExpression) error {
 			}
 
 			if typeInfo != nil {
-				if c.subst != nil {
-					typeInfo = typeInfo.Apply(c.subst)
-				}
+				typeInfo = c.applySubst(typeInfo)
 				// Emit TypeObject with full type info
 				typeObj := &evaluator.TypeObject{TypeVal: typeInfo}
 				c.emitConstant(typeObj, line)
@@ -1158,26 +1198,11 @@ func (c *Compiler) compilePatternBinding(pattern ast.Pattern, line int) error {
 		// Value to match is on stack at matchValSlot
 		matchValSlot := c.slotCount - 1
 
-		// Sort keys for deterministic compilation
-		keys := make([]string, 0, len(p.Fields))
-		for k := range p.Fields {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-
-		for _, fieldName := range keys {
-			fieldPattern := p.Fields[fieldName]
+		for _, part := range recordPatternParts(p) {
+			fieldPattern := part.pattern
 
-			// Get match value (Record)
-			c.emit(OP_GET_LOCAL, line)
-			c.currentChunk().Write(byte(matchValSlot), line)
-			c.slotCount++
-
-			// Get field
-			nameIdx := c.currentChunk().AddConstant(&stringConstant{Value: fieldName})
-			c.emit(OP_GET_FIELD, line)
-			c.currentChunk().Write(byte(nameIdx>>8), line)
-			c.currentChunk().Write(byte(nameIdx), line)
+			// Get field (or rest) from match value (Record)
+			c.emitRecordPatternPart(p, part, matchValSlot, line)
 			// Stack: [..., record, fieldValue]
 
 			// Bind fieldValue
@@ -725,8 +725,8 @@ func (c *Compiler) allocatePatternSlots(pattern ast.Pattern, slots map[string]in
 			c.allocatePatternSlots(elem, slots, line)
 		}
 	case *ast.RecordPattern:
-		for _, fieldPattern := range p.Fields {
-			c.allocatePatternSlots(fieldPattern, slots, line)
+		for _, part := range recordPatternParts(p) {
+			c.allocatePatternSlots(part.pattern, slots, line)
 		}
 	case *ast.ListPattern:
 		for _, elem := range p.Elements {
@@ -786,17 +786,11 @@ func (c *Compiler) extractPatternValues(pattern ast.Pattern, sourceSlot int, slo
 		return nil
 
 	case *ast.RecordPattern:
-		for fieldName, fieldPattern := range p.Fields {
-			// Get source record
-			c.emit(OP_GET_LOCAL, line)
-			c.currentChunk().Write(byte(sourceSlot), line)
-			c.slotCount++
+		for _, part := range recordPatternParts(p) {
+			fieldPattern := part.pattern
 
-			// Get field from record
-			nameIdx := c.currentChunk().AddConstant(&stringConstant{Value: fieldName})
-			c.emit(OP_GET_FIELD, line)
-			c.currentChunk().Write(byte(nameIdx>>8), line)
-			c.currentChunk().Write(byte(nameIdx), line)
+			// Get field (or rest) from source record
+			c.emitRecordPatternPart(p, part, sourceSlot, line)
 
 			if ident, ok := fieldPattern.(*ast.IdentifierPattern); ok {
 				if ident.Value != "_" {
@@ -448,32 +448,17 @@ func (c *Compiler) compileRecordPattern(p *ast.RecordPattern, line int) (int, er
 
 	bindingsStart := c.slotCount
 
-	// Sort keys for deterministic compilation
-	keys := make([]string, 0, len(p.Fields))
-	for k := range p.Fields {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
 	type fieldInfo struct {
 		failJump     int
 		slotsAfterOk int
 	}
 	var fieldInfos []fieldInfo
 
-	for _, fieldName := range keys {
-		fieldPattern := p.Fields[fieldName]
-		// Get record from its slot (not DUP from stack top which may be wrong)
-		c.emit(OP_GET_LOCAL, line)
-		c.currentChunk().Write(byte(recordSlot), line)
-		c.slotCount++
-
-		// Get field from record
-		nameIdx := c.currentChunk().AddConstant(&stringConstant{Value: fieldName})
-		c.emit(OP_GET_FIELD, line)
-		c.currentChunk().Write(byte(nameIdx>>8), line)
-		c.currentChunk().Write(byte(nameIdx), line)
-		// GET_FIELD pops record, pushes field value, net 0
+	for _, part := range recordPatternParts(p) {
+		fieldPattern := part.pattern
+		// Get the field (or the rest) from the record in its slot
+		// (not DUP from stack top which may be wrong)
+		c.emitRecordPatternPart(p, part, recordSlot, line)
 
 		slotsBeforeCheck := c.slotCount
 		fieldJump, err := c.compilePatternCheck(fieldPattern, line)
@@ -811,3 +796,55 @@ func (c *Compiler) compilePinPattern(p *ast.PinPattern, line int) (int, error) {
 	c.patchJump(successJump)
 	return failJump, nil
 }
+
+// recordPatternPart is one value a record pattern takes apart: a field, or
+// the rest of the record without the pattern's fields
+type recordPatternPart struct {
+	field   string
+	pattern ast.Pattern
+	rest    bool
+}
+
+// recordPatternParts lists the fields of p in sorted order, then its rest
+func recordPatternParts(p *ast.RecordPattern) []recordPatternPart {
+	keys := make([]string, 0, len(p.Fields))
+	for k := range p.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]recordPatternPart, 0, len(keys)+1)
+	for _, k := range keys {
+		parts = append(parts, recordPatternPart{field: k, pattern: p.Fields[k]})
+	}
+	if p.Rest != nil {
+		parts = append(parts, recordPatternPart{pattern: p.Rest, rest: true})
+	}
+	return parts
+}
+
+// emitRecordPatternPart pushes the value of part, read from the record in
+// recordSlot
+func (c *Compiler) emitRecordPatternPart(p *ast.RecordPattern, part recordPatternPart, recordSlot int, line int) {
+	c.emit(OP_GET_LOCAL, line)
+	c.currentChunk().Write(byte(recordSlot), line)
+	c.slotCount++
+
+	if part.rest {
+		parts := recordPatternParts(p)
+		c.emit(OP_RECORD_WITHOUT, line)
+		c.currentChunk().Write(byte(len(parts)-1), line)
+		for _, field := range parts[:len(parts)-1] {
+			nameIdx := c.currentChunk().AddConstant(&stringConstant{Value: field.field})
+			c.currentChunk().Write(byte(nameIdx>>8), line)
+			c.currentChunk().Write(byte(nameIdx), line)
+		}
+		return
+	}
+
+	// GET_FIELD pops record, pushes field value, net 0
+	nameIdx := c.currentChunk().AddConstant(&stringConstant{Value: part.field})
+	c.emit(OP_GET_FIELD, line)
+	c.currentChunk().Write(byte(nameIdx>>8), line)
+	c.currentChunk().Write(byte(nameIdx), line)
+}
@@ -301,7 +301,7 @@ func (c *Compiler) compileTypeDeclaration(stmt *ast.TypeDeclarationStatement) er
 				c.slotCount-- // consumes typeObj
 			}
 		}
-		return nil
+		return c.compileDerivedInstances(stmt)
 	}
 
 	for _, ctor := range stmt.Constructors {
@@ -331,6 +331,19 @@ func (c *Compiler) compileTypeDeclaration(stmt *ast.TypeDeclarationStatement) er
 		c.slotCount--
 	}
 
+	return c.compileDerivedInstances(stmt)
+}
+
+// compileDerivedInstances compiles the instances the analyzer derived for
+// the type's deriving clause
+func (c *Compiler) compileDerivedInstances(stmt *ast.TypeDeclarationStatement) error {
+	for _, inst := range stmt.Derived {
+		if err := c.compileInstanceDeclaration(inst); err != nil {
+			return err
+		}
+		c.emit(OP_POP, stmt.Token.Line)
+		c.slotCount--
+	}
 	return nil
 }
 
@@ -382,11 +395,16 @@ func (c *Compiler) compileInstanceDeclaration(stmt *ast.InstanceDeclaration) err
 	}
 
 	var typeNames []string
-	for _, arg := range stmt.Args {
+	for i, arg := range stmt.Args {
 		var tName string
 		switch t := arg.(type) {
 		case *ast.NamedType:
 			tName = t.Name.Value
+			// A head with holes keeps them (Pair<_, Int>): calls reach it
+			// through its dictionary, see headDictionary
+			if i == 0 && stmt.AnalyzedHeadName != "" {
+				tName = stmt.AnalyzedHeadName
+			}
 		case *ast.FunctionType:
 			tName = "Function"
 		case *ast.TupleType:
@@ -421,6 +439,8 @@ func (c *Compiler) compileInstanceDeclaration(stmt *ast.InstanceDeclaration) err
 		fn := funcCompiler.function
 		fn.LocalCount = funcCompiler.localCount
 		fn.UpvalueCount = funcCompiler.upvalueCount
+		// Records returned by methods such as getDefault take the declared type's name
+		fn.TypeInfo = buildFunctionTypeFromStatement(method)
 
 		fnIdx := c.currentChunk().AddConstant(fn)
 		c.emit(OP_CLOSURE, line)
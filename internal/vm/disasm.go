@@ -220,6 +220,18 @@ func disassembleInstruction(sb *strings.Builder, chunk *Chunk, offset int) int {
 		return simpleInstruction(sb, "HALT", offset)
 	case OP_POP_BELOW:
 		return byteInstruction(sb, "POP_BELOW", chunk, offset)
+	case OP_RECORD_WITHOUT:
+		// n, then n constant indices of field names
+		count := int(chunk.Code[offset+1])
+		names := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			idx := int(chunk.Code[offset+2+2*i])<<8 | int(chunk.Code[offset+3+2*i])
+			if idx < len(chunk.Constants) {
+				names = append(names, chunk.Constants[idx].Inspect())
+			}
+		}
+		sb.WriteString(fmt.Sprintf("%-16s %4d %s\n", "RECORD_WITHOUT", count, strings.Join(names, " ")))
+		return offset + 2 + 2*count
 	default:
 		sb.WriteString(fmt.Sprintf("Unknown opcode %d\n", op))
 		return offset + 1
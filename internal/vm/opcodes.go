@@ -145,6 +145,8 @@ const (
 	OP_BUILD_LIST_TRANSIENT  // Creates a temporary ListBuilder on stack
 	OP_LIST_TRANSIENT_APPEND // Pops value and appends to ListBuilder
 	OP_FREEZE_LIST           // Converts ListBuilder to List
+
+	OP_RECORD_WITHOUT // Record rest pattern: [record] n idx... -> [record without the n named fields]
 )
 
 // OpcodeNames maps opcodes to their string names (for debugging)
@@ -266,4 +268,6 @@ var OpcodeNames = map[Opcode]string{
 	OP_BUILD_LIST_TRANSIENT:  "BUILD_LIST_TRANSIENT",
 	OP_LIST_TRANSIENT_APPEND: "LIST_TRANSIENT_APPEND",
 	OP_FREEZE_LIST:           "FREEZE_LIST",
+
+	OP_RECORD_WITHOUT: "RECORD_WITHOUT",
 }
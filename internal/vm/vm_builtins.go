@@ -191,6 +191,24 @@ func (vm *VM) registerBuiltinTraitImpls() {
 		return evaluator.StringToList(args[0].Inspect())
 	})
 
+	// Hash and ToJson for built-in types
+	for _, typeName := range []string{
+		"Int", "Float", "Bool", "Char", "String", "BigInt", "Rational", "Bytes", "Bits", "Nil",
+		"List", "Map", "Option", "Result", "Tuple", "Record",
+	} {
+		vm.registerBuiltinTraitMethod("Hash", typeName, "hash", func(args []evaluator.Object) evaluator.Object {
+			return evaluator.HashValue(args[0])
+		})
+	}
+	for _, typeName := range []string{
+		"Int", "Float", "Bool", "Char", "String", "BigInt", "Rational", "Nil",
+		"List", "Option", "Tuple", "Record",
+	} {
+		vm.registerBuiltinTraitMethod("ToJson", typeName, "toJson", func(args []evaluator.Object) evaluator.Object {
+			return evaluator.ToJsonValue(args[0])
+		})
+	}
+
 	// Semigroup for List
 	vm.registerBuiltinTraitMethod("Semigroup", "List", "(<>)", func(args []evaluator.Object) evaluator.Object {
 		a, ok1 := args[0].(*evaluator.List)
@@ -921,8 +921,8 @@ func (vm *VM) callClassMethod(cm *evaluator.ClassMethod, argCount int) error {
 		return nil
 	}
 
-	// First try to find method by argument types
-	for i := 0; i < argCount; i++ {
+	// First try to find method by argument types (unless a dictionary provided it)
+	for i := 0; method == nil && i < argCount; i++ {
 		arg := vm.peek(argCount - 1 - i)
 		typeName := vm.getTypeName(arg)
 
@@ -935,8 +935,9 @@ func (vm *VM) callClassMethod(cm *evaluator.ClassMethod, argCount int) error {
 	}
 
 	// Try Fuzzy MPTC lookup using all available arguments + context
-	// This is now the preferred path for complex dispatch
-	if argCount > 0 {
+	// This is now the preferred path for complex dispatch, but a method taken
+	// from a witness dictionary (resolvedType unset) is kept
+	if argCount > 0 && (method == nil || resolvedType != "") {
 		// Collect arguments
 		args := make([]evaluator.Object, argCount)
 		for i := 0; i < argCount; i++ {
@@ -261,6 +261,12 @@ func (vm *VM) executeOneOp(op Opcode) error {
 			case *evaluator.Tuple:
 				vm.push(val)
 				vm.push(IntVal(int64(len(v.Elements))))
+			case evaluator.Iterator:
+				// Host iterator (e.g. a gRPC stream) - lazy iteration
+				vm.push(ObjVal(&BuiltinClosure{Name: "next", Fn: func([]evaluator.Object) evaluator.Object {
+					return v.Next()
+				}}))
+				vm.push(IntVal(-1))
 			case *ObjRange:
 				// Range iterator
 				// Check for Int/Char optimization
@@ -936,6 +942,25 @@ func (vm *VM) executeOneOp(op Opcode) error {
 		}
 		vm.push(result)
 
+	case OP_RECORD_WITHOUT:
+		count := int(vm.readByte())
+		removed := make(map[string]bool, count)
+		for i := 0; i < count; i++ {
+			if strConst, ok := vm.readConstant().(*stringConstant); ok {
+				removed[strConst.Value] = true
+			}
+		}
+		obj := vm.pop().AsObject()
+		rec, ok := obj.(*evaluator.RecordInstance)
+		if !ok {
+			return vm.runtimeError("record rest pattern expects a record, got %s", obj.Type())
+		}
+		rest := rec.Without(removed)
+		if err := vm.AddAllocatedBytes(uint64(len(rest.Fields)*32 + 64)); err != nil {
+			return err
+		}
+		vm.push(ObjVal(rest))
+
 	case OP_GET_FIELD:
 		// Inspect is safe, but let's check if it's a string constant for correctness
 		constVal := vm.readConstant()
@@ -1109,16 +1134,12 @@ func (vm *VM) executeOneOp(op Opcode) error {
 		if !ok {
 			return fmt.Errorf("expected List, got %s", val.RuntimeType().String())
 		}
-		// Create new list with elements from fromIdx onwards
+		// Slice shares the storage of Cons lists and packed strings
 		listLen := list.Len()
 		if fromIdx >= listLen {
 			vm.push(ObjVal(evaluator.NewList([]evaluator.Object{})))
 		} else {
-			restElems := make([]evaluator.Object, listLen-fromIdx)
-			for i := fromIdx; i < listLen; i++ {
-				restElems[i-fromIdx] = list.Get(i)
-			}
-			vm.push(ObjVal(evaluator.NewList(restElems)))
+			vm.push(ObjVal(list.Slice(fromIdx, listLen)))
 		}
 
 	case OP_CHECK_TYPE:
@@ -710,6 +710,23 @@ func (vm *VM) importVirtualModule(imp PendingImport) error {
 		})
 	} else if len(imp.Symbols) > 0 {
 		for _, sym := range imp.Symbols {
+			// A trait has no value of its own; importing it imports its methods
+			if pkg := modules.GetVirtualPackage("lib/" + pkgName); pkg != nil {
+				if trait, ok := pkg.Traits[sym]; ok {
+					for methodName := range trait.Methods {
+						if method := builtins.Get(methodName); method != nil {
+							vm.evalMu.Lock()
+							vm.globals.Globals = vm.globals.Globals.Put(methodName, method)
+							if vm.eval != nil && vm.eval.GlobalEnv != nil {
+								vm.eval.GlobalEnv.Set(methodName, method)
+							}
+							vm.evalMu.Unlock()
+						}
+					}
+					continue
+				}
+			}
+
 			if fn := builtins.Get(sym); fn != nil {
 				vm.evalMu.Lock()
 				vm.globals.Globals = vm.globals.Globals.Put(sym, fn)
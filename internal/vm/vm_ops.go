@@ -799,6 +799,9 @@ func (vm *VM) comparisonOp(op Opcode) error {
 
 // compareLists returns -1, 0, or 1 for lexicographic comparison
 func (vm *VM) compareLists(a, b *evaluator.List) int {
+	if cmp, ok := evaluator.CompareStrings(a, b); ok {
+		return cmp
+	}
 	aElems := a.ToSlice()
 	bElems := b.ToSlice()
 	minLen := len(aElems)
@@ -13,6 +13,7 @@ import (
 	"github.com/funvibe/funxy/internal/evaluator"
 	"github.com/funvibe/funxy/internal/ext"
 	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/lint"
 	"github.com/funvibe/funxy/internal/modules"
 	"github.com/funvibe/funxy/internal/parser"
 	"github.com/funvibe/funxy/internal/pipeline"
@@ -2100,12 +2101,16 @@ func runPipeline(sourceCode string, filePath string, useTreeWalk bool, isTestMod
 	}
 
 	// 3. Create and configure the processing pipeline
-	processingPipeline := pipeline.New(
+	processors := []pipeline.Processor{
 		&lexer.LexerProcessor{},
 		&parser.ParserProcessor{},
 		&analyzer.SemanticAnalyzerProcessor{},
-		backend.NewExecutionProcessor(execBackend),
-	)
+	}
+	if lintConfig != nil {
+		processors = append(processors, &lint.Processor{Config: lintConfig}, warningReporter{})
+	}
+	processors = append(processors, backend.NewExecutionProcessor(execBackend))
+	processingPipeline := pipeline.New(processors...)
 
 	// 4. Run the pipeline
 	finalContext := processingPipeline.Run(initialContext)
@@ -2200,6 +2205,21 @@ func Run() {
 		return
 	}
 
+	// Handle lint command (funxy lint [-W...] [paths...])
+	if handleLint() {
+		return
+	}
+
+	// Handle fmt command (funxy fmt [-w] [-l] [--check] [paths...])
+	if handleFmt() {
+		return
+	}
+
+	// Handle repl command (funxy repl, or funxy alone on a terminal)
+	if handleRepl() {
+		return
+	}
+
 	// Handle build command (funxy build <source> [-o <output>])
 	if handleBuild() {
 		return
@@ -2224,7 +2244,7 @@ func Run() {
 
 	// Restore args for the script:
 	// - keep all script flags/args
-	// - remove host-only flags (debug)
+	// - remove host-only flags (debug, -W warning flags before the file)
 	// - ensure the file path is at argv[1]
 	var fileArg string
 	var restArgs []string
@@ -2232,6 +2252,16 @@ func Run() {
 		if arg == "-debug" || arg == "--debug" {
 			continue
 		}
+		if fileArg == "" && lint.IsFlag(arg) {
+			if lintConfig == nil {
+				lintConfig = lint.DefaultConfig()
+			}
+			if err := lintConfig.ApplyFlag(arg); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+			continue
+		}
 		if fileArg == "" && !strings.HasPrefix(arg, "-") {
 			fileArg = arg
 			continue
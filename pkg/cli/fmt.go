@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/funvibe/funxy/internal/prettyprinter"
+)
+
+// handleFmt handles `funxy fmt [-w] [-l] [--check] [paths...]`.
+// Directories are searched recursively. Without flags the formatted source is
+// printed to stdout; -w rewrites the files, -l lists the files whose
+// formatting differs and --check does the same but exits with status 1 if
+// there are any. Files that cannot be formatted are reported on stderr and
+// also make the command fail.
+func handleFmt() bool {
+	if len(os.Args) < 2 || os.Args[1] != "fmt" {
+		return false
+	}
+
+	var write, list, check bool
+	var paths []string
+	for _, arg := range os.Args[2:] {
+		switch arg {
+		case "-w":
+			write = true
+		case "-l":
+			list = true
+		case "--check":
+			check = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				fmt.Fprintf(os.Stderr, "Unknown fmt flag: %s\n", arg)
+				os.Exit(1)
+			}
+			paths = append(paths, arg)
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := collectSourceFiles(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	failed := false
+	for _, file := range files {
+		changed, err := formatFile(file, write, list || check)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", file, err)
+			failed = true
+			continue
+		}
+		if changed && check {
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+	return true
+}
+
+// formatFile formats one file and reports whether its formatting changed.
+// The result is written back with write, the file name is printed with list,
+// and the formatted source goes to stdout when neither is set.
+func formatFile(path string, write, list bool) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	formatted, err := prettyprinter.Format(string(source))
+	if err != nil {
+		return false, err
+	}
+
+	changed := formatted != string(source)
+	if changed && list {
+		fmt.Println(path)
+	}
+	if changed && write {
+		if err := os.WriteFile(path, []byte(formatted), info.Mode().Perm()); err != nil {
+			return false, err
+		}
+	}
+	if !write && !list {
+		fmt.Print(formatted)
+	}
+	return changed, nil
+}
@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/funvibe/funxy/internal/analyzer"
+	"github.com/funvibe/funxy/internal/ast"
+	"github.com/funvibe/funxy/internal/diagnostics"
+	"github.com/funvibe/funxy/internal/lexer"
+	"github.com/funvibe/funxy/internal/lint"
+	"github.com/funvibe/funxy/internal/modules"
+	"github.com/funvibe/funxy/internal/parser"
+	"github.com/funvibe/funxy/internal/pipeline"
+)
+
+// lintConfig enables the lint pass when running a script. It is set by -W
+// flags given before the script path and stays nil otherwise.
+var lintConfig *lint.Config
+
+// handleLint handles `funxy lint [-W...] [paths...]`.
+// Directories are searched recursively; every package is linted once.
+// Exits with status 1 if any diagnostic is reported as an error.
+func handleLint() bool {
+	if len(os.Args) < 2 || os.Args[1] != "lint" {
+		return false
+	}
+
+	cfg := lint.DefaultConfig()
+	var paths []string
+	for _, arg := range os.Args[2:] {
+		if lint.IsFlag(arg) {
+			if err := cfg.ApplyFlag(arg); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+			continue
+		}
+		if strings.HasPrefix(arg, "-") {
+			fmt.Fprintf(os.Stderr, "Unknown lint flag: %s\n", arg)
+			os.Exit(1)
+		}
+		paths = append(paths, arg)
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := collectSourceFiles(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	failed := false
+	lintedPackages := make(map[string]bool)
+	for _, file := range files {
+		diags, pkgDir := lintScript(file, cfg)
+		if pkgDir != "" {
+			if lintedPackages[pkgDir] {
+				continue
+			}
+			lintedPackages[pkgDir] = true
+			diags = lintPackage(pkgDir, cfg)
+		}
+		for _, diag := range diags {
+			if diag.File == "" {
+				diag.File = file
+			}
+			if diag.Severity == diagnostics.SeverityError {
+				failed = true
+			}
+			fmt.Println(diag.Error())
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+	return true
+}
+
+// collectSourceFiles expands directories into the source files below them,
+// skipping hidden directories.
+func collectSourceFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if p != path && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if isSourceFile(p) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// lintScript analyzes and lints a single script. Files that belong to a
+// package are not analyzed on their own: their directory is returned instead.
+func lintScript(path string, cfg *lint.Config) ([]*diagnostics.DiagnosticError, string) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading file: %s\n", err)
+		os.Exit(1)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	ctx := pipeline.NewPipelineContext(string(source))
+	ctx.FilePath = absPath
+	ctx = pipeline.New(&lexer.LexerProcessor{}, &parser.ParserProcessor{}).Run(ctx)
+	if len(ctx.Errors) > 0 {
+		return ctx.Errors, ""
+	}
+	if program, ok := ctx.AstRoot.(*ast.Program); ok && declaresPackage(program) {
+		return nil, filepath.Dir(absPath)
+	}
+
+	ctx = pipeline.New(&analyzer.SemanticAnalyzerProcessor{}, &lint.Processor{Config: cfg}).Run(ctx)
+	return append(ctx.Errors, ctx.Warnings...), ""
+}
+
+// declaresPackage reports whether the program starts with a package
+// declaration. The parser keeps it among the statements.
+func declaresPackage(program *ast.Program) bool {
+	for _, stmt := range program.Statements {
+		if _, ok := stmt.(*ast.PackageDeclaration); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// lintPackage analyzes all files of the package in dir and lints them together.
+func lintPackage(dir string, cfg *lint.Config) []*diagnostics.DiagnosticError {
+	loader := modules.NewLoader()
+	mod, err := loader.Load(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading module: %s\n", err)
+		os.Exit(1)
+	}
+
+	a := analyzer.New(mod.SymbolTable)
+	a.SetLoader(loader)
+	a.BaseDir = mod.Dir
+	a.RegisterBuiltins()
+
+	var errors []*diagnostics.DiagnosticError
+	ctx := pipeline.NewPipelineContext("")
+	for _, fileAST := range mod.OrderedFiles() {
+		errors = append(errors, a.AnalyzeNaming(fileAST, ctx)...)
+	}
+	errors = append(errors, a.AnalyzePackageHeaders(mod.OrderedFiles(), ctx)...)
+	for _, fileAST := range mod.OrderedFiles() {
+		errors = append(errors, a.AnalyzeInstances(fileAST, ctx)...)
+	}
+	for _, fileAST := range mod.OrderedFiles() {
+		errors = append(errors, a.AnalyzeBodies(fileAST, ctx)...)
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+
+	mod.SetTypeMap(a.TypeMap)
+	return lint.Check(lint.ModuleUnits(mod, "", ""), cfg)
+}
+
+// warningReporter prints the warnings collected so far to stderr, so that
+// they appear before the program's own output.
+type warningReporter struct{}
+
+func (warningReporter) Process(ctx *pipeline.PipelineContext) *pipeline.PipelineContext {
+	for _, warning := range ctx.Warnings {
+		if warning.File == "" {
+			warning.File = ctx.FilePath
+		}
+		fmt.Fprintln(os.Stderr, warning.Error())
+	}
+	return ctx
+}
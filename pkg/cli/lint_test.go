@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/funvibe/funxy/internal/diagnostics"
+	"github.com/funvibe/funxy/internal/lint"
+	"github.com/funvibe/funxy/internal/modules"
+)
+
+// TestLintPackage_SiblingFiles verifies that the files of a package are
+// analyzed together, so symbols and types of sibling files resolve.
+func TestLintPackage_SiblingFiles(t *testing.T) {
+	modules.InitVirtualPackages()
+
+	dir := filepath.Join(t.TempDir(), "shapes")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"types.lang": "package shapes (*)\n\n" +
+			"type alias Point = { x: Int, y: Int }\n",
+		"make.lang": "package shapes (*)\n\n" +
+			"fun origin() -> Point { { x: 0, y: 0 } }\n",
+		"ops.lang": "package shapes (*)\n\n" +
+			"fun shift(p: Point, dx: Int) -> Point { { x: p.x + dx, y: p.y } }\n\n" +
+			"fun shiftedOrigin(dx: Int) -> Point { shift(origin(), dx) }\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := lint.DefaultConfig()
+	diags, pkgDir := lintScript(filepath.Join(dir, "ops.lang"), cfg)
+	if pkgDir == "" {
+		t.Fatalf("package file was linted on its own: %v", diags)
+	}
+	for _, diag := range lintPackage(pkgDir, cfg) {
+		if diag.Severity == diagnostics.SeverityError {
+			t.Errorf("unexpected error: %s", diag.Error())
+		}
+	}
+}
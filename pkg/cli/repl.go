@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/funvibe/funxy/internal/repl"
+	"github.com/mattn/go-isatty"
+)
+
+// handleRepl handles `funxy repl`, and `funxy` without arguments when stdin
+// is a terminal: an interactive session on the configured backend.
+func handleRepl() bool {
+	switch {
+	case len(os.Args) >= 2 && os.Args[1] == "repl":
+		if len(os.Args) > 2 {
+			fmt.Fprintf(os.Stderr, "Usage: %s repl\n", os.Args[0])
+			os.Exit(1)
+		}
+	case len(os.Args) == 1 && isatty.IsTerminal(os.Stdin.Fd()):
+	default:
+		return false
+	}
+
+	repl.New(os.Stdin, os.Stdout, isTreeWalkMode()).Run()
+	return true
+}
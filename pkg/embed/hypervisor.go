@@ -1161,11 +1161,7 @@ func (h *Hypervisor) SpawnVM(path string, config map[string]interface{}) (string
 				newCtx, newCancel := context.WithTimeout(context.Background(), 5*time.Second)
 				vmInstance.machine.SetContext(newCtx)
 
-				chars := make([]evaluator.Object, 0, len(errStr))
-				for _, r := range errStr {
-					chars = append(chars, &evaluator.Char{Value: int64(r)})
-				}
-				strObj := evaluator.NewListWithType(chars, "Char")
+				strObj := evaluator.StringToList(errStr)
 
 				_, _ = vmInstance.machine.CallFunction(fnObj, []evaluator.Object{strObj})
 
@@ -1272,11 +1268,7 @@ func (h *Hypervisor) terminateVM(id string, saveState bool, timeoutMs int, stopR
 					entry.VM.machine.SetContext(newCtx)
 
 					errStr := "killed by supervisor"
-					chars := make([]evaluator.Object, 0, len(errStr))
-					for _, r := range errStr {
-						chars = append(chars, &evaluator.Char{Value: int64(r)})
-					}
-					strObj := evaluator.NewListWithType(chars, "Char")
+					strObj := evaluator.StringToList(errStr)
 
 					_, _ = entry.VM.machine.CallFunction(fnObj, []evaluator.Object{strObj})
 